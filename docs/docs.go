@@ -21,7 +21,9 @@ const docTemplate = `{
             "get": {
                 "description": "Returns server status (success code)",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
                 ],
                 "tags": [
                     "status"
@@ -42,17 +44,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/leaderboard/DeleteScore": {
-            "put": {
-                "description": "Removes user data from a database",
+        "/admin/ApproveQuarantined": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Applies a submission held for review to the board as a regular score and removes it from the quarantine",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
                 "parameters": [
                     {
@@ -61,7 +72,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.DeleteScoreParams"
+                            "$ref": "#/definitions/controllers.ApproveQuarantinedParams"
                         }
                     }
                 ],
@@ -78,6 +89,36 @@ const docTemplate = `{
                             "$ref": "#/definitions/controllers.ResultError"
                         }
                     },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied, banned user)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response (submission not found)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "413": {
+                        "description": "Error response (request body is too large)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit or quota exceeded, code - quota name, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
                     "500": {
                         "description": "Error response",
                         "schema": {
@@ -87,17 +128,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/leaderboard/GetScore": {
-            "put": {
-                "description": "Gets user data from a database",
+        "/admin/GetAudit": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns entries of the audit log of destructive and moderation operations of the tenant of the key and checks their hash chain",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
                 "parameters": [
                     {
@@ -106,7 +156,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.GetScoreParams"
+                            "$ref": "#/definitions/controllers.GetAuditParams"
                         }
                     }
                 ],
@@ -114,7 +164,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successful response",
                         "schema": {
-                            "$ref": "#/definitions/controllers.GetScoreResultSuccess-dbprovider_UserProperties"
+                            "$ref": "#/definitions/controllers.GetAuditResultSuccess"
                         }
                     },
                     "400": {
@@ -123,6 +173,36 @@ const docTemplate = `{
                             "$ref": "#/definitions/controllers.ResultError"
                         }
                     },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied, key limited to some games)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response (audit is disabled)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "413": {
+                        "description": "Error response (request body is too large)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
                     "500": {
                         "description": "Error response",
                         "schema": {
@@ -132,17 +212,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/leaderboard/GetTop": {
-            "put": {
-                "description": "Returns data of users with maximum registered scores sorted in descending order of score, maximum nTop number of elements for a specific gameId",
+        "/admin/GetDeadLetters": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns webhooks of the endpoint and the tenant of the key that failed all delivery attempts",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
                 ],
                 "tags": [
-                    "top"
+                    "admin"
                 ],
                 "parameters": [
                     {
@@ -151,7 +240,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.GetTopParams"
+                            "$ref": "#/definitions/controllers.GetDeadLettersParams"
                         }
                     }
                 ],
@@ -159,7 +248,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successful response",
                         "schema": {
-                            "$ref": "#/definitions/controllers.GetTopResultSuccess"
+                            "$ref": "#/definitions/controllers.GetDeadLettersResultSuccess"
                         }
                     },
                     "400": {
@@ -168,6 +257,36 @@ const docTemplate = `{
                             "$ref": "#/definitions/controllers.ResultError"
                         }
                     },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied, key limited to some games)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response (webhooks are disabled, endpoint not found)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "413": {
+                        "description": "Error response (request body is too large)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
                     "500": {
                         "description": "Error response",
                         "schema": {
@@ -177,17 +296,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/leaderboard/SendScore": {
-            "put": {
-                "description": "Stores user data in a database",
+        "/admin/GetQuarantine": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns submissions held for review by anti-cheat rules of a specific gameId, the oldest first",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
                 "parameters": [
                     {
@@ -196,7 +324,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.SendScoreParams"
+                            "$ref": "#/definitions/controllers.GetQuarantineParams"
                         }
                     }
                 ],
@@ -204,7 +332,7 @@ const docTemplate = `{
                     "200": {
                         "description": "Successful response",
                         "schema": {
-                            "$ref": "#/definitions/controllers.ResultSuccess"
+                            "$ref": "#/definitions/controllers.GetQuarantineResultSuccess"
                         }
                     },
                     "400": {
@@ -213,6 +341,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/controllers.ResultError"
                         }
                     },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "413": {
+                        "description": "Error response (request body is too large)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
                     "500": {
                         "description": "Error response",
                         "schema": {
@@ -221,115 +373,2732 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controllers.DeleteScoreParams": {
-            "type": "object",
-            "required": [
-                "gameId",
-                "userId"
-            ],
-            "properties": {
-                "gameId": {
-                    "description": "Id of game (alphanumeric values)",
-                    "type": "string",
-                    "maxLength": 50,
-                    "x-order": "0",
-                    "example": "game1"
-                },
-                "userId": {
-                    "description": "Id of user (alphanumeric values)",
-                    "type": "string",
-                    "maxLength": 50,
-                    "x-order": "1",
-                    "example": "user1"
-                }
-            }
-        },
-        "controllers.GetScoreParams": {
-            "type": "object",
-            "required": [
-                "gameId",
-                "userId"
-            ],
-            "properties": {
-                "gameId": {
-                    "description": "Id of game (alphanumeric values)",
-                    "type": "string",
-                    "maxLength": 50,
-                    "x-order": "0",
-                    "example": "game1"
-                },
-                "userId": {
-                    "description": "Id of user (alphanumeric values)",
-                    "type": "string",
-                    "maxLength": 50,
-                    "x-order": "1",
-                    "example": "user1"
-                }
-            }
         },
-        "controllers.GetScoreResultSuccess-dbprovider_UserProperties": {
-            "type": "object",
-            "required": [
-                "result"
-            ],
-            "properties": {
-                "result": {
-                    "description": "(Empty object if no data)",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/dbprovider.UserProperties"
+        "/admin/GetUsage": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns today's writes and stored entries of a tenant and its games along with their quotas",
+                "consumes": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "description": "Body data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetUsageParams"
                         }
-                    ]
-                }
-            }
-        },
-        "controllers.GetTopParams": {
-            "type": "object",
-            "required": [
-                "gameId",
-                "nTop"
-            ],
-            "properties": {
-                "gameId": {
-                    "description": "Id of game (alphanumeric values)",
-                    "type": "string",
-                    "maxLength": 50,
-                    "x-order": "0",
-                    "example": "game1"
-                },
-                "nTop": {
-                    "description": "Number of users in top",
-                    "type": "integer",
-                    "maximum": 100,
-                    "minimum": 1,
-                    "x-order": "1",
-                    "example": 100
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetUsageResultSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied, key limited to some games or of another tenant)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response (quotas are disabled)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "413": {
+                        "description": "Error response (request body is too large)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
                 }
             }
         },
-        "controllers.GetTopResultSuccess": {
-            "type": "object",
-            "required": [
-                "result"
-            ],
-            "properties": {
-                "result": {
-                    "type": "array",
-                    "items": {
+        "/admin/GetUserState": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets visibility state of user",
+                "consumes": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "description": "Body data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetUserStateParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetUserStateResultSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "413": {
+                        "description": "Error response (request body is too large)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/RejectQuarantined": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes a submission held for review without applying it",
+                "consumes": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "description": "Body data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RejectQuarantinedParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response (submission not found)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "413": {
+                        "description": "Error response (request body is too large)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/ReplayDeadLetters": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Queues dead letters of the endpoint and the tenant of the key for delivery again and removes them from dead letters.\nWithout ids the oldest dead letters that fit into the queue of the endpoint are replayed, unknown ids are skipped",
+                "consumes": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "description": "Body data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReplayDeadLettersParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReplayDeadLettersResultSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied, key limited to some games)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response (webhooks are disabled, endpoint not found)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "413": {
+                        "description": "Error response (request body is too large)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "503": {
+                        "description": "Error response (queue of the endpoint is full, the rest of dead letters is kept)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/SetUserState": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets visibility state of user. Not visible users are excluded from tops, but still get their own data",
+                "consumes": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "description": "Body data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SetUserStateParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "413": {
+                        "description": "Error response (request body is too large)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboard/DeleteScore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes user data from a database (all runs of the user for runs boards)",
+                "consumes": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "Body data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.DeleteScoreParams"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key of the request, up to 255 characters (repeated requests return the stored response)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key or token)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "409": {
+                        "description": "Error response (request with the same idempotency key is in progress)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "413": {
+                        "description": "Error response (request body is too large)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "422": {
+                        "description": "Error response (idempotency key reused)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboard/GetChanges": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns changes of the board (puts and deletes of scores and runs) after the known version in the order of versions.\nIf there are no changes, the request waits for them up to the wait time. Pass the returned version as since of the next request.\nIf the changes after the version are not retained anymore, responds with 410 and the current version in the X-Changes-Version header: the client has to reload the board and continue from that version",
+                "consumes": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "changes"
+                ],
+                "parameters": [
+                    {
+                        "description": "Body data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetChangesParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetChangesResultSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response (change feed is disabled)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "410": {
+                        "description": "Error response (changes are too old, resync is required, see X-Changes-Version header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "413": {
+                        "description": "Error response (request body is too large)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboard/GetScore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Gets user data from a database (runs of the user sorted in descending order of score for runs boards)",
+                "consumes": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "Body data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetScoreParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetScoreResultSuccess-dbprovider_UserProperties"
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "private, max-age=0"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Weak hash of the result"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time of the last score submission"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key or token)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "413": {
+                        "description": "Error response (request body is too large)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboard/GetTop": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns data of users with maximum registered scores sorted in descending order of score, maximum nTop number of elements for a specific gameId (runs with maximum scores for runs boards, the same user may appear several times)",
+                "consumes": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "top"
+                ],
+                "parameters": [
+                    {
+                        "description": "Body data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetTopParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetTopResultSuccess"
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "public, max-age - remaining lifetime of the cached top (s)"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Weak hash of the result"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time the top was read from the database"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "413": {
+                        "description": "Error response (request body is too large)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboard/SendScore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stores user data in a database (a new run of the user for runs boards)",
+                "consumes": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "Body data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SendScoreParams"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time of signing, unix ms (boards with secrets only)",
+                        "name": "X-Signature-Timestamp",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique value of the request, up to 64 characters (boards with secrets only)",
+                        "name": "X-Signature-Nonce",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "HMAC-SHA256 (hex) of timestamp, nonce and canonical body (boards with secrets only)",
+                        "name": "X-Signature",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key of the request, up to 255 characters (repeated requests return the stored response)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultSuccess"
+                        }
+                    },
+                    "202": {
+                        "description": "Score is quarantined by anti-cheat rules",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key, token or signature)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied, banned user)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "409": {
+                        "description": "Error response (request with the same idempotency key is in progress)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "413": {
+                        "description": "Error response (request body is too large)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "415": {
+                        "description": "Error response (signed request body is not JSON)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "422": {
+                        "description": "Error response (score rejected by anti-cheat rules, code - rule name; idempotency key reused)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit or quota exceeded, code - quota name, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/audit": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns entries of the audit log of destructive and moderation operations of the tenant of the key and checks their hash chain",
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Sequence number of the first entry (0 - from the beginning)",
+                        "name": "fromSeq",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of entries (1-100)",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetAuditResultSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied, key limited to some games)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response (audit is disabled)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/games/{gameId}/changes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns changes of the board (puts and deletes of scores and runs) after the known version in the order of versions.\nIf there are no changes, the request waits for them up to the wait time. Pass the returned version as since of the next request.\nIf the changes after the version are not retained anymore, responds with 410 and the current version in the X-Changes-Version header: the client has to reload the board and continue from that version",
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "changes"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of game (alphanumeric values)",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version of the board known to the client (0 - from the beginning)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of changes (1-1000)",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum time to wait for changes if there are none (ms, limited by the server)",
+                        "name": "wait",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetChangesResultSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response (change feed is disabled)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "410": {
+                        "description": "Error response (changes are too old, resync is required, see X-Changes-Version header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/games/{gameId}/quarantine": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns submissions held for review by anti-cheat rules of a specific gameId, the oldest first",
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of game (alphanumeric values)",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of submissions (1-100)",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetQuarantineResultSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/games/{gameId}/quarantine/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes a submission held for review without applying it",
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of game (alphanumeric values)",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of quarantined submission",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successful response"
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response (submission not found)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/games/{gameId}/quarantine/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Applies a submission held for review to the board as a regular score and removes it from the quarantine",
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of game (alphanumeric values)",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of quarantined submission",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successful response"
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied, banned user)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response (submission not found)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit or quota exceeded, code - quota name, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/games/{gameId}/top": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns data of users with maximum registered scores sorted in descending order of score (runs with maximum scores for runs boards, the same user may appear several times)",
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "top"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of game (alphanumeric values)",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of users in top (1-100)",
+                        "name": "n",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fields of entries, comma separated (userId, score, name, params, runId, ts; empty - all fields)",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetTopResultSuccess"
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "public, max-age - remaining lifetime of the cached top (s)"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Weak hash of the result"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time the top was read from the database"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified (the ETag matches If-None-Match)"
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/games/{gameId}/top/subscribe": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Streams updates of the top of the game as server-sent events. The first \"snapshot\" event contains the whole top,\nthen an event is sent on every change of the top: \"snapshot\" with the whole top or \"diff\" with new and changed entries and keys of removed ones (mode=diff).\n\"heartbeat\" events are sent to idle streams. Subscribers that don't keep up with updates get an \"error\" event and are disconnected.\nOnly changes made through this server instance are sent",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "top"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of game (alphanumeric values)",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of users in top (1-100)",
+                        "name": "n",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Updates after the first snapshot: snapshot (default) or diff",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of updates",
+                        "schema": {
+                            "$ref": "#/definitions/services.TopUpdate"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "503": {
+                        "description": "Error response (too many subscriptions)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/games/{gameId}/users/{userId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Gets user data from a database (runs of the user sorted in descending order of score for runs boards)",
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of game (alphanumeric values)",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of user (alphanumeric values)",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetScoreResultSuccess-dbprovider_UserProperties"
+                        },
+                        "headers": {
+                            "Cache-Control": {
+                                "type": "string",
+                                "description": "private, max-age=0"
+                            },
+                            "ETag": {
+                                "type": "string",
+                                "description": "Weak hash of the result"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time of the last score submission"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified (the ETag matches If-None-Match)"
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key or token)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response (user has no data)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stores user data in a database (a new run of the user for runs boards)",
+                "consumes": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of game (alphanumeric values)",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of user (alphanumeric values)",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Body data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ScoreData"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time of signing, unix ms (boards with secrets only)",
+                        "name": "X-Signature-Timestamp",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique value of the request, up to 64 characters (boards with secrets only)",
+                        "name": "X-Signature-Nonce",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "HMAC-SHA256 (hex) of timestamp, nonce and canonical body with gameId and userId of the path (boards with secrets only)",
+                        "name": "X-Signature",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key of the request, up to 255 characters (repeated requests return the stored response)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User data (a run for runs boards) is created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultSuccess"
+                        }
+                    },
+                    "202": {
+                        "description": "Score is quarantined by anti-cheat rules",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultSuccess"
+                        }
+                    },
+                    "204": {
+                        "description": "User data is updated"
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key, token or signature)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied, banned user)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "409": {
+                        "description": "Error response (request with the same idempotency key is in progress)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "413": {
+                        "description": "Error response (request body is too large)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "415": {
+                        "description": "Error response (signed request body is not JSON)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "422": {
+                        "description": "Error response (score rejected by anti-cheat rules, code - rule name; idempotency key reused)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit or quota exceeded, code - quota name, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes user data from a database (all runs of the user for runs boards)",
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of game (alphanumeric values)",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of user (alphanumeric values)",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key of the request, up to 255 characters (repeated requests return the stored response)",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successful response"
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key or token)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response (user has no data)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "409": {
+                        "description": "Error response (request with the same idempotency key is in progress)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "422": {
+                        "description": "Error response (idempotency key reused)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/games/{gameId}/users/{userId}/state": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets visibility state of user",
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of game (alphanumeric values)",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of user (alphanumeric values)",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetUserStateResultSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets visibility state of user. Not visible users are excluded from tops, but still get their own data",
+                "consumes": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of game (alphanumeric values)",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Id of user (alphanumeric values)",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Body data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UserStateData"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Successful response"
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "413": {
+                        "description": "Error response (request body is too large)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/usage": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns today's writes and stored entries of a tenant and its games along with their quotas",
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of tenant (empty - tenant of the api key, other tenants are available to operator keys only)",
+                        "name": "tenant",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetUsageResultSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied, key limited to some games or of another tenant)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response (quotas are disabled)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/webhooks/{endpoint}/deadletters": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns webhooks of the endpoint and the tenant of the key that failed all delivery attempts",
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of webhook endpoint",
+                        "name": "endpoint",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of dead letters (1-100)",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.GetDeadLettersResultSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied, key limited to some games)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response (webhooks are disabled, endpoint not found)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/webhooks/{endpoint}/deadletters/replay": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Queues dead letters of the endpoint and the tenant of the key for delivery again and removes them from dead letters.\nWithout ids the oldest dead letters that fit into the queue of the endpoint are replayed, unknown ids are skipped",
+                "consumes": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/msgpack",
+                    "application/x-protobuf"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Id of webhook endpoint",
+                        "name": "endpoint",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Body data (ids only)",
+                        "name": "data",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReplayDeadLettersParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReplayDeadLettersResultSuccess"
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "401": {
+                        "description": "Error response (missing or wrong api key)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "403": {
+                        "description": "Error response (access denied, key limited to some games)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "404": {
+                        "description": "Error response (webhooks are disabled, endpoint not found)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "413": {
+                        "description": "Error response (request body is too large)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "429": {
+                        "description": "Error response (rate limit exceeded, see Retry-After header)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "500": {
+                        "description": "Error response",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    },
+                    "503": {
+                        "description": "Error response (queue of the endpoint is full, the rest of dead letters is kept)",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResultError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controllers.ApproveQuarantinedParams": {
+            "type": "object",
+            "required": [
+                "gameId",
+                "id"
+            ],
+            "properties": {
+                "gameId": {
+                    "description": "Id of game (alphanumeric values)",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "0",
+                    "example": "game1"
+                },
+                "id": {
+                    "description": "Id of quarantined submission",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "1",
+                    "example": "00010000000003c1f9a2b7d4e6f8a0"
+                }
+            }
+        },
+        "controllers.AuditResult": {
+            "type": "object",
+            "required": [
+                "entries",
+                "valid"
+            ],
+            "properties": {
+                "entries": {
+                    "description": "Entries in ascending order of sequence numbers",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dbprovider.AuditEntry"
+                    }
+                },
+                "valid": {
+                    "description": "Whether the hash chain of the entries (and the link to the preceding entry) is intact",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "controllers.DeleteScoreParams": {
+            "type": "object",
+            "required": [
+                "gameId",
+                "userId"
+            ],
+            "properties": {
+                "gameId": {
+                    "description": "Id of game (alphanumeric values)",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "0",
+                    "example": "game1"
+                },
+                "userId": {
+                    "description": "Id of user (alphanumeric values)",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "1",
+                    "example": "user1"
+                }
+            }
+        },
+        "controllers.GameUsageResult": {
+            "type": "object",
+            "required": [
+                "entries",
+                "gameId",
+                "maxEntries",
+                "writes",
+                "writesPerDay"
+            ],
+            "properties": {
+                "entries": {
+                    "description": "Stored entries (runs for runs boards)",
+                    "type": "integer",
+                    "example": 35
+                },
+                "gameId": {
+                    "description": "Id of game",
+                    "type": "string",
+                    "example": "game1"
+                },
+                "maxEntries": {
+                    "description": "Quota of stored entries (0 - unlimited)",
+                    "type": "integer",
+                    "example": 500
+                },
+                "writes": {
+                    "description": "Score submissions made today (UTC)",
+                    "type": "integer",
+                    "example": 120
+                },
+                "writesPerDay": {
+                    "description": "Quota of writes per day (0 - unlimited)",
+                    "type": "integer",
+                    "example": 1000
+                }
+            }
+        },
+        "controllers.GetAuditParams": {
+            "type": "object",
+            "required": [
+                "limit"
+            ],
+            "properties": {
+                "fromSeq": {
+                    "description": "Sequence number of the first entry (0 - from the beginning)",
+                    "type": "integer",
+                    "x-order": "0",
+                    "example": 1
+                },
+                "limit": {
+                    "description": "Maximum number of entries",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "x-order": "1",
+                    "example": 100
+                }
+            }
+        },
+        "controllers.GetAuditResultSuccess": {
+            "type": "object",
+            "required": [
+                "result"
+            ],
+            "properties": {
+                "result": {
+                    "$ref": "#/definitions/controllers.AuditResult"
+                }
+            }
+        },
+        "controllers.GetChangesParams": {
+            "type": "object",
+            "required": [
+                "gameId",
+                "limit"
+            ],
+            "properties": {
+                "gameId": {
+                    "description": "Id of game (alphanumeric values)",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "0",
+                    "example": "game1"
+                },
+                "since": {
+                    "description": "Version of the board known to the client (0 - from the beginning)",
+                    "type": "integer",
+                    "x-order": "1",
+                    "example": 41
+                },
+                "limit": {
+                    "description": "Maximum number of changes",
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1,
+                    "x-order": "2",
+                    "example": 100
+                },
+                "wait": {
+                    "description": "Maximum time to wait for changes if there are none (ms, limited by the server)",
+                    "type": "integer",
+                    "x-order": "3",
+                    "example": 30000
+                }
+            }
+        },
+        "controllers.GetChangesResultSuccess": {
+            "type": "object",
+            "required": [
+                "result"
+            ],
+            "properties": {
+                "result": {
+                    "$ref": "#/definitions/services.ChangeList"
+                }
+            }
+        },
+        "controllers.GetDeadLettersParams": {
+            "type": "object",
+            "required": [
+                "endpoint",
+                "limit"
+            ],
+            "properties": {
+                "endpoint": {
+                    "description": "Id of webhook endpoint",
+                    "type": "string",
+                    "maxLength": 32,
+                    "x-order": "0",
+                    "example": "rewards"
+                },
+                "limit": {
+                    "description": "Maximum number of dead letters",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "x-order": "1",
+                    "example": 100
+                }
+            }
+        },
+        "controllers.GetDeadLettersResultSuccess": {
+            "type": "object",
+            "required": [
+                "result"
+            ],
+            "properties": {
+                "result": {
+                    "description": "Dead letters in ascending order of time",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/deadletterprovider.DeadLetter"
+                    }
+                }
+            }
+        },
+        "controllers.GetQuarantineParams": {
+            "type": "object",
+            "required": [
+                "gameId",
+                "limit"
+            ],
+            "properties": {
+                "gameId": {
+                    "description": "Id of game (alphanumeric values)",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "0",
+                    "example": "game1"
+                },
+                "limit": {
+                    "description": "Maximum number of submissions",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "x-order": "1",
+                    "example": 100
+                }
+            }
+        },
+        "controllers.GetQuarantineResultSuccess": {
+            "type": "object",
+            "required": [
+                "result"
+            ],
+            "properties": {
+                "result": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dbprovider.QuarantineItem"
+                    }
+                }
+            }
+        },
+        "controllers.GetScoreParams": {
+            "type": "object",
+            "required": [
+                "gameId",
+                "userId"
+            ],
+            "properties": {
+                "gameId": {
+                    "description": "Id of game (alphanumeric values)",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "0",
+                    "example": "game1"
+                },
+                "userId": {
+                    "description": "Id of user (alphanumeric values)",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "1",
+                    "example": "user1"
+                }
+            }
+        },
+        "controllers.GetScoreResultSuccess-dbprovider_UserProperties": {
+            "type": "object",
+            "required": [
+                "result"
+            ],
+            "properties": {
+                "result": {
+                    "description": "(Empty object if no data)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dbprovider.UserProperties"
+                        }
+                    ]
+                }
+            }
+        },
+        "controllers.GetTopParams": {
+            "type": "object",
+            "required": [
+                "gameId",
+                "nTop"
+            ],
+            "properties": {
+                "gameId": {
+                    "description": "Id of game (alphanumeric values)",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "0",
+                    "example": "game1"
+                },
+                "nTop": {
+                    "description": "Number of users in top",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "x-order": "1",
+                    "example": 100
+                },
+                "fields": {
+                    "description": "Fields of entries, comma separated (userId, score, name, params, runId, ts; empty - all fields)",
+                    "type": "string",
+                    "maxLength": 100,
+                    "x-order": "2",
+                    "example": "userId,score"
+                }
+            }
+        },
+        "controllers.GetTopResultSuccess": {
+            "type": "object",
+            "required": [
+                "result"
+            ],
+            "properties": {
+                "result": {
+                    "type": "array",
+                    "items": {
                         "$ref": "#/definitions/dbprovider.UserData"
                     }
                 }
             }
         },
+        "controllers.GetUsageParams": {
+            "type": "object",
+            "properties": {
+                "tenant": {
+                    "description": "Id of tenant (empty - tenant of the api key, other tenants are available to operator keys only)",
+                    "type": "string",
+                    "maxLength": 32,
+                    "x-order": "0",
+                    "example": "studio1"
+                }
+            }
+        },
+        "controllers.GetUsageResultSuccess": {
+            "type": "object",
+            "required": [
+                "result"
+            ],
+            "properties": {
+                "result": {
+                    "$ref": "#/definitions/controllers.UsageResult"
+                }
+            }
+        },
+        "controllers.GetUserStateParams": {
+            "type": "object",
+            "required": [
+                "gameId",
+                "userId"
+            ],
+            "properties": {
+                "gameId": {
+                    "description": "Id of game (alphanumeric values)",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "0",
+                    "example": "game1"
+                },
+                "userId": {
+                    "description": "Id of user (alphanumeric values)",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "1",
+                    "example": "user1"
+                }
+            }
+        },
+        "controllers.GetUserStateResultSuccess": {
+            "type": "object",
+            "required": [
+                "result"
+            ],
+            "properties": {
+                "result": {
+                    "$ref": "#/definitions/controllers.UserStateResult"
+                }
+            }
+        },
+        "controllers.RejectQuarantinedParams": {
+            "type": "object",
+            "required": [
+                "gameId",
+                "id"
+            ],
+            "properties": {
+                "gameId": {
+                    "description": "Id of game (alphanumeric values)",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "0",
+                    "example": "game1"
+                },
+                "id": {
+                    "description": "Id of quarantined submission",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "1",
+                    "example": "00010000000003c1f9a2b7d4e6f8a0"
+                }
+            }
+        },
+        "controllers.ReplayDeadLettersParams": {
+            "type": "object",
+            "required": [
+                "endpoint"
+            ],
+            "properties": {
+                "endpoint": {
+                    "description": "Id of webhook endpoint",
+                    "type": "string",
+                    "maxLength": 32,
+                    "x-order": "0",
+                    "example": "rewards"
+                },
+                "ids": {
+                    "description": "Ids of dead letters (empty - the oldest ones)",
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    },
+                    "x-order": "1",
+                    "example": [
+                        "5f2b8c1d9e7a4b6c"
+                    ]
+                }
+            }
+        },
+        "controllers.ReplayDeadLettersResultSuccess": {
+            "type": "object",
+            "required": [
+                "result"
+            ],
+            "properties": {
+                "result": {
+                    "$ref": "#/definitions/controllers.ReplayResult"
+                }
+            }
+        },
+        "controllers.ReplayResult": {
+            "type": "object",
+            "required": [
+                "replayed"
+            ],
+            "properties": {
+                "replayed": {
+                    "description": "Number of dead letters queued for delivery",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
         "controllers.ResultError": {
             "type": "object",
             "required": [
                 "error"
             ],
             "properties": {
+                "code": {
+                    "description": "Machine-readable reason of the error (if any)",
+                    "type": "string",
+                    "example": "score_range"
+                },
                 "error": {
                     "type": "string",
                     "example": "Some server error"
@@ -348,6 +3117,48 @@ const docTemplate = `{
                 }
             }
         },
+        "controllers.ScoreData": {
+            "type": "object",
+            "required": [
+                "score"
+            ],
+            "properties": {
+                "score": {
+                    "description": "User score",
+                    "type": "number",
+                    "minimum": 0,
+                    "x-order": "2",
+                    "example": 1500
+                },
+                "name": {
+                    "description": "User name",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "3",
+                    "example": "John"
+                },
+                "params": {
+                    "description": "Additional payload",
+                    "type": "string",
+                    "maxLength": 255,
+                    "x-order": "4",
+                    "example": "some additional payload"
+                },
+                "runId": {
+                    "description": "Id of run (runs boards only, generated if empty)",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "5",
+                    "example": "run1"
+                },
+                "duration": {
+                    "description": "Match duration (ms), checked by anti-cheat rules of the board",
+                    "type": "integer",
+                    "x-order": "6",
+                    "example": 90000
+                }
+            }
+        },
         "controllers.SendScoreParams": {
             "type": "object",
             "required": [
@@ -390,6 +3201,263 @@ const docTemplate = `{
                     "maxLength": 255,
                     "x-order": "4",
                     "example": "some additional payload"
+                },
+                "runId": {
+                    "description": "Id of run (runs boards only, generated if empty)",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "5",
+                    "example": "run1"
+                },
+                "duration": {
+                    "description": "Match duration (ms), checked by anti-cheat rules of the board",
+                    "type": "integer",
+                    "x-order": "6",
+                    "example": 90000
+                }
+            }
+        },
+        "controllers.SetUserStateParams": {
+            "type": "object",
+            "required": [
+                "gameId",
+                "state",
+                "userId"
+            ],
+            "properties": {
+                "gameId": {
+                    "description": "Id of game (alphanumeric values)",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "0",
+                    "example": "game1"
+                },
+                "userId": {
+                    "description": "Id of user (alphanumeric values)",
+                    "type": "string",
+                    "maxLength": 50,
+                    "x-order": "1",
+                    "example": "user1"
+                },
+                "state": {
+                    "description": "Visibility state (visible - shown to everyone, shadowbanned - hidden from everyone except the user, banned - hidden and new scores are rejected)",
+                    "type": "string",
+                    "enum": [
+                        "visible",
+                        "shadowbanned",
+                        "banned"
+                    ],
+                    "x-order": "2",
+                    "example": "shadowbanned"
+                }
+            }
+        },
+        "controllers.UsageResult": {
+            "type": "object",
+            "required": [
+                "entries",
+                "games",
+                "maxEntries",
+                "writes",
+                "writesPerDay"
+            ],
+            "properties": {
+                "entries": {
+                    "description": "Stored entries (runs for runs boards)",
+                    "type": "integer",
+                    "example": 35
+                },
+                "games": {
+                    "description": "Usage of games of the tenant sorted by gameId",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.GameUsageResult"
+                    }
+                },
+                "maxEntries": {
+                    "description": "Quota of stored entries (0 - unlimited)",
+                    "type": "integer",
+                    "example": 500
+                },
+                "tenant": {
+                    "description": "Id of tenant (empty - default tenant)",
+                    "type": "string",
+                    "example": "studio1"
+                },
+                "writes": {
+                    "description": "Score submissions made today (UTC)",
+                    "type": "integer",
+                    "example": 120
+                },
+                "writesPerDay": {
+                    "description": "Quota of writes per day (0 - unlimited)",
+                    "type": "integer",
+                    "example": 1000
+                }
+            }
+        },
+        "controllers.UserStateData": {
+            "type": "object",
+            "required": [
+                "state"
+            ],
+            "properties": {
+                "state": {
+                    "description": "Visibility state (visible - shown to everyone, shadowbanned - hidden from everyone except the user, banned - hidden and new scores are rejected)",
+                    "type": "string",
+                    "enum": [
+                        "visible",
+                        "shadowbanned",
+                        "banned"
+                    ],
+                    "x-order": "2",
+                    "example": "shadowbanned"
+                }
+            }
+        },
+        "controllers.UserStateResult": {
+            "type": "object",
+            "required": [
+                "state"
+            ],
+            "properties": {
+                "state": {
+                    "description": "Visibility state (visible, shadowbanned, banned)",
+                    "type": "string",
+                    "example": "visible"
+                }
+            }
+        },
+        "dbprovider.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Operation",
+                    "type": "string"
+                },
+                "actor": {
+                    "description": "Who made the operation (API key id or token subject)",
+                    "type": "string"
+                },
+                "after": {
+                    "description": "Affected data after the operation (JSON)",
+                    "type": "string"
+                },
+                "before": {
+                    "description": "Affected data before the operation (JSON)",
+                    "type": "string"
+                },
+                "gameId": {
+                    "description": "Id of game",
+                    "type": "string"
+                },
+                "hash": {
+                    "description": "HMAC-SHA256 of the previous hash and the other fields with the audit key (hex)",
+                    "type": "string"
+                },
+                "prevHash": {
+                    "description": "Hash of the previous entry (empty for the first one)",
+                    "type": "string"
+                },
+                "requestId": {
+                    "description": "Id of the request",
+                    "type": "string"
+                },
+                "seq": {
+                    "description": "Sequence number of the entry (starts from 1)",
+                    "type": "integer"
+                },
+                "tenant": {
+                    "description": "Id of tenant of the game (empty - default tenant)",
+                    "type": "string"
+                },
+                "ts": {
+                    "description": "Time of the operation (unix ms)",
+                    "type": "integer"
+                },
+                "userId": {
+                    "description": "Id of user",
+                    "type": "string"
+                }
+            }
+        },
+        "dbprovider.ChangeEntry": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "op": {
+                    "description": "CHANGEOP_*",
+                    "type": "string"
+                },
+                "params": {
+                    "type": "string"
+                },
+                "runId": {
+                    "description": "Id of the stored run (runs boards only)",
+                    "type": "string"
+                },
+                "score": {
+                    "description": "Stored score (0 for deletes)",
+                    "type": "number"
+                },
+                "ts": {
+                    "description": "Time of the change (unix ms)",
+                    "type": "integer"
+                },
+                "userId": {
+                    "description": "Id of user",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version of the board after the change (starts from 1)",
+                    "type": "integer"
+                }
+            }
+        },
+        "dbprovider.QuarantineItem": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "description": "Submitted match duration (ms)",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "Id of the item (ids are ordered by creation time)",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Submitted user name",
+                    "type": "string"
+                },
+                "params": {
+                    "description": "Submitted payload",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Reason of the violation",
+                    "type": "string"
+                },
+                "rule": {
+                    "description": "Name of the violated rule",
+                    "type": "string"
+                },
+                "runId": {
+                    "description": "Id of run (runs boards only)",
+                    "type": "string"
+                },
+                "score": {
+                    "description": "Submitted score",
+                    "type": "number"
+                },
+                "ts": {
+                    "description": "Time of the submission (unix ms)",
+                    "type": "integer"
+                },
+                "userId": {
+                    "description": "Id of user",
+                    "type": "string"
                 }
             }
         },
@@ -426,6 +3494,133 @@ const docTemplate = `{
                     "type": "number"
                 }
             }
+        },
+        "deadletterprovider.DeadLetter": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Number of failed attempts",
+                    "type": "integer",
+                    "example": 8
+                },
+                "error": {
+                    "description": "Error of the last attempt",
+                    "type": "string",
+                    "example": "unexpected status 500"
+                },
+                "id": {
+                    "description": "Id of the event",
+                    "type": "string",
+                    "example": "5f2b8c1d9e7a4b6c8d0e1f2a3b4c5d6e"
+                },
+                "payload": {
+                    "description": "Body of the webhook (JSON)",
+                    "type": "string",
+                    "example": "{\"type\":\"took_first\",\"userId\":\"user1\"}"
+                },
+                "tenant": {
+                    "description": "Id of tenant of the event (empty - default tenant)",
+                    "type": "string",
+                    "example": "studio1"
+                },
+                "ts": {
+                    "description": "Time of the last attempt (unix ms)",
+                    "type": "integer",
+                    "example": 1700000000000
+                }
+            }
+        },
+        "services.ChangeList": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "description": "Changes in the order of versions",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dbprovider.ChangeEntry"
+                    }
+                },
+                "more": {
+                    "description": "More changes are available right away",
+                    "type": "boolean",
+                    "example": false
+                },
+                "version": {
+                    "description": "Version of the last returned change (the requested one if there are no changes)",
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "services.TopEntry": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "params": {
+                    "type": "string"
+                },
+                "rank": {
+                    "description": "1-based position in the top",
+                    "type": "integer"
+                },
+                "runId": {
+                    "description": "runs boards only",
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "ts": {
+                    "description": "Time of the run submission (runs boards only)",
+                    "type": "integer"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "services.TopUpdate": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "description": "Snapshot - the whole top, diff - new entries and entries with changed rank or data",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/services.TopEntry"
+                    }
+                },
+                "removed": {
+                    "description": "Diff - keys of entries that left the top",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "seq": {
+                    "description": "Number of the update within the subscription, starting from 1",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "TOPUPDATE_*",
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key (required only if authentication is enabled)",
+            "type": "apiKey",
+            "name": "X-Api-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "Bearer token of a player: \"Bearer {token}\" (required only if JWT authentication is enabled)",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     },
     "externalDocs": {
@@ -0,0 +1,30 @@
+package auditsink
+
+import (
+	"context"
+	dbprovider "go-leaderboard-server/internal/db"
+)
+
+type AuditSinkBaseConfig struct {
+	IsDebug bool // Debug flag
+}
+
+func (c *AuditSinkBaseConfig) GetBaseConfig() *AuditSinkBaseConfig {
+	return c
+}
+
+type IAuditSinkConfig interface {
+	GetBaseConfig() *AuditSinkBaseConfig
+}
+
+// Storage of the audit log. Entries are only appended, never changed or removed
+type IAuditSink interface {
+	Initialize(ctx context.Context, config IAuditSinkConfig) error
+	// Appends the entry, returns dbprovider.ErrAuditConflict if an entry with the same sequence number exists
+	Append(ctx context.Context, entry dbprovider.AuditEntry) error
+	// Returns up to limit entries with sequence numbers starting from fromSeq in ascending order
+	List(ctx context.Context, fromSeq uint64, limit uint32) ([]dbprovider.AuditEntry, error)
+	// Returns the entry with the highest sequence number (nil - the log is empty)
+	Last(ctx context.Context) (*dbprovider.AuditEntry, error)
+	Shutdown(ctx context.Context) error
+}
@@ -0,0 +1,61 @@
+package audit_db_sink
+
+import (
+	"context"
+	"errors"
+	auditsink "go-leaderboard-server/internal/audit"
+	dbprovider "go-leaderboard-server/internal/db"
+	log "go-leaderboard-server/internal/logger"
+)
+
+var logger = log.GetLogger()
+
+type AuditDbSinkConfig struct {
+	auditsink.AuditSinkBaseConfig
+}
+
+// Keeps the log in the leaderboard database, so it is shared by all server instances
+type AuditDbSink struct {
+	dbprovider dbprovider.IDbProvider
+}
+
+func NewAuditDbSink(dbProvider dbprovider.IDbProvider) *AuditDbSink {
+	return &AuditDbSink{
+		dbprovider: dbProvider,
+	}
+}
+
+func (s *AuditDbSink) Initialize(ctx context.Context, config auditsink.IAuditSinkConfig) error {
+	logger.Debug("Audit sink initialization")
+
+	_, ok := config.(*AuditDbSinkConfig)
+	if !ok {
+		return errors.New("wrong config")
+	}
+
+	if s.dbprovider == nil {
+		return errors.New("uninitialized DB provider")
+	}
+
+	return nil
+}
+
+func (s *AuditDbSink) Append(ctx context.Context, entry dbprovider.AuditEntry) error {
+	return s.dbprovider.PutAuditEntry(ctx, entry)
+}
+
+func (s *AuditDbSink) List(ctx context.Context, fromSeq uint64, limit uint32) ([]dbprovider.AuditEntry, error) {
+	return s.dbprovider.ListAuditEntries(ctx, fromSeq, limit)
+}
+
+func (s *AuditDbSink) Last(ctx context.Context) (*dbprovider.AuditEntry, error) {
+	return s.dbprovider.LastAuditEntry(ctx)
+}
+
+func (s *AuditDbSink) Shutdown(ctx context.Context) error {
+	logger.Debug("Audit sink shutdown")
+
+	/* the DB provider is shut down by the leaderboard service */
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package audit_file_sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	auditsink "go-leaderboard-server/internal/audit"
+	dbprovider "go-leaderboard-server/internal/db"
+	log "go-leaderboard-server/internal/logger"
+	"os"
+	"sync"
+)
+
+var logger = log.GetLogger()
+
+const maxLineSize = 1024 * 1024
+
+type AuditFileSinkConfig struct {
+	auditsink.AuditSinkBaseConfig
+	Path string // Path to the log file (created if missing)
+}
+
+// Appends entries to a local file as JSON lines, so the log is kept per server instance.
+// Reads scan the file from the beginning
+type AuditFileSink struct {
+	path  string
+	file  *os.File
+	last  *dbprovider.AuditEntry
+	mutex sync.Mutex
+}
+
+func NewAuditFileSink() *AuditFileSink {
+	return &AuditFileSink{}
+}
+
+func (s *AuditFileSink) Initialize(ctx context.Context, config auditsink.IAuditSinkConfig) error {
+	logger.Debug("Audit sink initialization")
+
+	conf, ok := config.(*AuditFileSinkConfig)
+	if !ok {
+		return errors.New("wrong config")
+	}
+
+	if conf.Path == "" {
+		return errors.New("audit file path is not set")
+	}
+
+	file, err := os.OpenFile(conf.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	s.path = conf.Path
+	s.file = file
+
+	err = s.scan(func(entry *dbprovider.AuditEntry) bool {
+		s.last = entry
+		return true
+	})
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return nil
+}
+
+func (s *AuditFileSink) Append(ctx context.Context, entry dbprovider.AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.last != nil && entry.Seq <= s.last.Seq {
+		return dbprovider.ErrAuditConflict
+	}
+
+	_, err = s.file.Write(append(line, '\n'))
+	if err != nil {
+		return err
+	}
+	err = s.file.Sync()
+	if err != nil {
+		return err
+	}
+
+	s.last = &entry
+
+	return nil
+}
+
+func (s *AuditFileSink) List(ctx context.Context, fromSeq uint64, limit uint32) ([]dbprovider.AuditEntry, error) {
+	entries := []dbprovider.AuditEntry{}
+
+	err := s.scan(func(entry *dbprovider.AuditEntry) bool {
+		if entry.Seq >= fromSeq {
+			entries = append(entries, *entry)
+		}
+		return uint32(len(entries)) < limit
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (s *AuditFileSink) Last(ctx context.Context) (*dbprovider.AuditEntry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.last == nil {
+		return nil, nil
+	}
+	entry := *s.last
+	return &entry, nil
+}
+
+func (s *AuditFileSink) Shutdown(ctx context.Context) error {
+	logger.Debug("Audit sink shutdown")
+
+	if s.file == nil {
+		return nil
+	}
+
+	return s.file.Close()
+}
+
+// Calls fn for every entry of the file until it returns false
+func (s *AuditFileSink) scan(fn func(entry *dbprovider.AuditEntry) bool) error {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		var entry dbprovider.AuditEntry
+		err = json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			return fmt.Errorf("wrong audit entry at line %d: %w", line, err)
+		}
+		if !fn(&entry) {
+			return nil
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,81 @@
+package audit_file_sink
+
+import (
+	"context"
+	auditsink "go-leaderboard-server/internal/audit"
+	dbprovider "go-leaderboard-server/internal/db"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newSink(t *testing.T, path string) *AuditFileSink {
+	sink := NewAuditFileSink()
+	err := sink.Initialize(context.Background(), &AuditFileSinkConfig{
+		AuditSinkBaseConfig: auditsink.AuditSinkBaseConfig{
+			IsDebug: true,
+		},
+		Path: path,
+	})
+	require.NoError(t, err)
+	return sink
+}
+
+func TestAuditFileSink(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("append and list entries", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.log")
+		sink := newSink(t, path)
+
+		entry, err := sink.Last(ctx)
+		require.NoError(t, err)
+		require.Nil(t, entry)
+
+		entry1 := dbprovider.AuditEntry{Seq: 1, Ts: 1000, Actor: "key:0123456789ab", Action: "delete_score", GameId: "game1", UserId: "user1", Hash: "hash1"}
+		entry2 := dbprovider.AuditEntry{Seq: 2, Ts: 2000, Actor: "sub:admin1", Action: "set_user_state", GameId: "game1", UserId: "user2", PrevHash: "hash1", Hash: "hash2"}
+
+		require.NoError(t, sink.Append(ctx, entry1))
+		require.NoError(t, sink.Append(ctx, entry2))
+		require.ErrorIs(t, sink.Append(ctx, dbprovider.AuditEntry{Seq: 2, Hash: "other"}), dbprovider.ErrAuditConflict)
+
+		entry, err = sink.Last(ctx)
+		require.NoError(t, err)
+		require.Equal(t, entry2, *entry)
+
+		entries, err := sink.List(ctx, 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.AuditEntry{entry1, entry2}, entries)
+		entries, err = sink.List(ctx, 2, 10)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.AuditEntry{entry2}, entries)
+		entries, err = sink.List(ctx, 1, 1)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.AuditEntry{entry1}, entries)
+		entries, err = sink.List(ctx, 3, 10)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+
+		require.NoError(t, sink.Shutdown(ctx))
+
+		// the log is continued after restart
+		sink = newSink(t, path)
+		defer sink.Shutdown(ctx)
+
+		entry, err = sink.Last(ctx)
+		require.NoError(t, err)
+		require.Equal(t, entry2, *entry)
+		require.ErrorIs(t, sink.Append(ctx, dbprovider.AuditEntry{Seq: 1}), dbprovider.ErrAuditConflict)
+	})
+
+	t.Run("reject corrupted file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.log")
+		require.NoError(t, os.WriteFile(path, []byte("{\"seq\":1}\nnot json\n"), 0600))
+
+		sink := NewAuditFileSink()
+		err := sink.Initialize(ctx, &AuditFileSinkConfig{Path: path})
+		require.ErrorContains(t, err, "line 2")
+	})
+}
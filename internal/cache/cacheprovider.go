@@ -19,13 +19,19 @@ type ICacheProviderConfig interface {
 }
 
 type CacheData struct {
-	Exp  int64
-	Cnt  uint32
-	Data dbprovider.TopData
+	Ts         int64 // Time the data was read from the DB (unix ms)
+	Exp        int64 // Time the data expires (unix ms)
+	Cnt        uint32
+	OmitFields dbprovider.TopFields // Fields that were not read
+	Data       dbprovider.TopData
 }
 
 type ICacheProvider interface {
 	Initialize(ctx context.Context, config ICacheProviderConfig, dbProvider dbprovider.IDbProvider) error
-	Top(gctx context.Context, ameId string, nTop uint32) (dbprovider.TopData, error)
+	Top(ctx context.Context, gameId string, nTop uint32, opts dbprovider.TopOptions) (dbprovider.TopData, error)
+	// Same as Top, but also returns when the data was read and when it expires (Cnt is the number of requested entries)
+	CachedTop(ctx context.Context, gameId string, nTop uint32, opts dbprovider.TopOptions) (*CacheData, error)
+	// Drops cached data of the game (data requested before the call is not cached anymore)
+	Invalidate(ctx context.Context, gameId string) error
 	Shutdown(ctx context.Context) error
 }
@@ -3,6 +3,7 @@ package cache_simple_provider
 import (
 	"context"
 	"errors"
+	"fmt"
 	cacheprovider "go-leaderboard-server/internal/cache"
 	dbprovider "go-leaderboard-server/internal/db"
 	log "go-leaderboard-server/internal/logger"
@@ -19,7 +20,8 @@ type CacheSimpleProviderConfig struct {
 }
 
 type CacheSimpleProvider struct {
-	cache      map[string]*cacheprovider.CacheData
+	cache      map[string]map[dbprovider.TopFields]*cacheprovider.CacheData // key - gameId, omitted fields
+	versions   map[string]uint64                                            // incremented on invalidation
 	dbprovider dbprovider.IDbProvider
 	ttl        uint32
 	mutex      sync.RWMutex
@@ -29,8 +31,9 @@ type CacheSimpleProvider struct {
 
 func NewCacheSimpleProvider(clock *utils.IClock) *CacheSimpleProvider {
 	return &CacheSimpleProvider{
-		cache: make(map[string]*cacheprovider.CacheData),
-		clock: clock,
+		cache:    make(map[string]map[dbprovider.TopFields]*cacheprovider.CacheData),
+		versions: make(map[string]uint64),
+		clock:    clock,
 	}
 }
 
@@ -48,7 +51,15 @@ func (p *CacheSimpleProvider) Initialize(ctx context.Context, config cacheprovid
 	return nil
 }
 
-func (p *CacheSimpleProvider) Top(ctx context.Context, gameId string, nTop uint32) (dbprovider.TopData, error) {
+func (p *CacheSimpleProvider) Top(ctx context.Context, gameId string, nTop uint32, opts dbprovider.TopOptions) (dbprovider.TopData, error) {
+	cacheData, err := p.CachedTop(ctx, gameId, nTop, opts)
+	if err != nil {
+		return nil, err
+	}
+	return cacheData.Data, nil
+}
+
+func (p *CacheSimpleProvider) CachedTop(ctx context.Context, gameId string, nTop uint32, opts dbprovider.TopOptions) (*cacheprovider.CacheData, error) {
 	if p.dbprovider == nil {
 		return nil, errors.New("uninitialized")
 	}
@@ -56,32 +67,110 @@ func (p *CacheSimpleProvider) Top(ctx context.Context, gameId string, nTop uint3
 	now := (*(p.clock)).Now().UnixMilli()
 
 	p.mutex.RLock()
-	cacheData, ok := p.cache[gameId]
-	if ok && cacheData.Exp > now && cacheData.Cnt >= nTop {
-		topData := cacheData.Data
-		p.mutex.RUnlock()
-		if len(topData) > int(nTop) {
-			topData = topData[:int(nTop)]
+	cacheData := p.findCached(gameId, nTop, opts.OmitFields, now)
+	version := p.versions[gameId]
+	p.mutex.RUnlock()
+	if cacheData != nil {
+		result := sliceTop(cacheData, nTop, opts.OmitFields)
+		if !hasExpired(result.Data, opts) {
+			return result, nil
 		}
-		return topData, nil
 	}
-	p.mutex.RUnlock()
 
-	topData, err, _ := p.sfg.Do(gameId, func() (any, error) {
-		data, err := p.dbprovider.Top(ctx, gameId, nTop)
-		if err == nil {
-			p.mutex.Lock()
-			p.cache[gameId] = &cacheprovider.CacheData{
-				Exp:  now + int64(p.ttl),
-				Cnt:  nTop,
-				Data: data,
+	// concurrent calls share the data only if they request the same number of entries, shorter data can't serve longer tops
+	result, err, _ := p.sfg.Do(fmt.Sprintf("%s:%d:%d", gameId, nTop, opts.OmitFields), func() (any, error) {
+		data, err := p.dbprovider.Top(ctx, gameId, nTop, opts)
+		if err != nil {
+			return nil, err
+		}
+		cacheData := &cacheprovider.CacheData{
+			Ts:         now,
+			Exp:        now, // not cached
+			Cnt:        nTop,
+			OmitFields: opts.OmitFields,
+			Data:       data,
+		}
+		p.mutex.Lock()
+		if p.versions[gameId] == version { // skip data requested before invalidation
+			cacheData.Exp = now + int64(p.ttl)
+			if p.cache[gameId] == nil {
+				p.cache[gameId] = make(map[dbprovider.TopFields]*cacheprovider.CacheData)
 			}
-			p.mutex.Unlock()
+			p.cache[gameId][opts.OmitFields] = cacheData
 		}
-		return data, err
+		p.mutex.Unlock()
+		return cacheData, nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return topData.(dbprovider.TopData), err
+	// the shared data isn't changed by callers
+	return sliceTop(result.(*cacheprovider.CacheData), nTop, opts.OmitFields), nil
+}
+
+// Returns cached data of the game with enough entries and all requested fields (nil - not found).
+// Data with exactly the requested fields is preferred, so it doesn't have to be projected
+func (p *CacheSimpleProvider) findCached(gameId string, nTop uint32, omitFields dbprovider.TopFields, now int64) *cacheprovider.CacheData {
+	var found *cacheprovider.CacheData
+	for fields, cacheData := range p.cache[gameId] {
+		if fields&^omitFields != 0 || cacheData.Exp <= now || cacheData.Cnt < nTop {
+			continue
+		}
+		if fields == omitFields {
+			return cacheData
+		}
+		found = cacheData
+	}
+	return found
+}
+
+// Returns the first nTop entries of the data without the omitted fields
+func sliceTop(cacheData *cacheprovider.CacheData, nTop uint32, omitFields dbprovider.TopFields) *cacheprovider.CacheData {
+	topData := cacheData.Data
+	if len(topData) > int(nTop) {
+		topData = topData[:int(nTop)]
+	}
+	if omitFields != cacheData.OmitFields {
+		projected := make(dbprovider.TopData, len(topData))
+		for i, udata := range topData {
+			if omitFields&dbprovider.TOPFIELD_NAME != 0 {
+				udata.Name = ""
+			}
+			if omitFields&dbprovider.TOPFIELD_PARAMS != 0 {
+				udata.Params = ""
+			}
+			projected[i] = udata
+		}
+		topData = projected
+	}
+	return &cacheprovider.CacheData{Ts: cacheData.Ts, Exp: cacheData.Exp, Cnt: nTop, OmitFields: omitFields, Data: topData}
+}
+
+// Checks whether cached data contains entries that must be filtered out at the moment
+func hasExpired(topData dbprovider.TopData, opts dbprovider.TopOptions) bool {
+	if opts.MinTs == 0 {
+		return false
+	}
+	for _, udata := range topData {
+		if udata.Ts < opts.MinTs {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *CacheSimpleProvider) Invalidate(ctx context.Context, gameId string) error {
+	p.mutex.Lock()
+	delete(p.cache, gameId)
+	p.versions[gameId]++
+	p.mutex.Unlock()
+
+	for fields := dbprovider.TopFields(0); fields <= dbprovider.TOPFIELDS_OPTIONAL; fields++ {
+		p.sfg.Forget(fmt.Sprintf("%s:%d", gameId, fields))
+	}
+
+	return nil
 }
 
 func (p *CacheSimpleProvider) Shutdown(ctx context.Context) error {
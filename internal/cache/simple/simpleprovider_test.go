@@ -21,13 +21,13 @@ func (m *MockDbProvider) Initialize(ctx context.Context, config dbprovider.IDBPr
 	return args.Error(0)
 }
 
-func (m *MockDbProvider) Put(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties) error {
-	args := m.Called(gameId, userId, userProp)
+func (m *MockDbProvider) Put(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties, rec dbprovider.WriteRecords) error {
+	args := m.Called(gameId, userId, userProp, rec)
 	return args.Error(0)
 }
 
-func (m *MockDbProvider) Delete(ctx context.Context, gameId string, userId string) error {
-	args := m.Called(gameId, userId)
+func (m *MockDbProvider) Delete(ctx context.Context, gameId string, userId string, rec dbprovider.WriteRecords) error {
+	args := m.Called(gameId, userId, rec)
 	return args.Error(0)
 }
 
@@ -36,11 +36,136 @@ func (m *MockDbProvider) Get(ctx context.Context, gameId string, userId string)
 	return args.Get(0).(*dbprovider.UserProperties), args.Error(1)
 }
 
-func (m *MockDbProvider) Top(ctx context.Context, gameId string, nTop uint32) (dbprovider.TopData, error) {
+func (m *MockDbProvider) Top(ctx context.Context, gameId string, nTop uint32, opts dbprovider.TopOptions) (dbprovider.TopData, error) {
 	args := m.Called(gameId, nTop)
 	return args.Get(0).(dbprovider.TopData), args.Error(1)
 }
 
+func (m *MockDbProvider) Inactive(ctx context.Context, gameId string, before int64, above dbprovider.UScoreType, fn func(dbprovider.UserData) error) error {
+	args := m.Called(gameId, before)
+	return args.Error(0)
+}
+
+func (m *MockDbProvider) SetScore(ctx context.Context, gameId string, userId string, score dbprovider.UScoreType, ts int64, rec dbprovider.WriteRecords) (bool, error) {
+	args := m.Called(gameId, userId, score, ts, rec)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDbProvider) Expire(ctx context.Context, gameId string, before int64, rec dbprovider.WriteRecords) (uint32, error) {
+	args := m.Called(gameId, before, rec)
+	return args.Get(0).(uint32), args.Error(1)
+}
+
+func (m *MockDbProvider) Trim(ctx context.Context, gameId string, maxEntries uint32, rec dbprovider.WriteRecords) (uint32, error) {
+	args := m.Called(gameId, maxEntries, rec)
+	return args.Get(0).(uint32), args.Error(1)
+}
+
+func (m *MockDbProvider) Count(ctx context.Context, gameId string) (uint64, error) {
+	args := m.Called(gameId)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *MockDbProvider) PutRun(ctx context.Context, gameId string, userId string, run dbprovider.RunProperties, maxRuns uint32, rec dbprovider.WriteRecords) (uint32, error) {
+	args := m.Called(gameId, userId, run, maxRuns, rec)
+	return args.Get(0).(uint32), args.Error(1)
+}
+
+func (m *MockDbProvider) DeleteRuns(ctx context.Context, gameId string, userId string, rec dbprovider.WriteRecords) error {
+	args := m.Called(gameId, userId, rec)
+	return args.Error(0)
+}
+
+func (m *MockDbProvider) GetRuns(ctx context.Context, gameId string, userId string) ([]dbprovider.RunProperties, error) {
+	args := m.Called(gameId, userId)
+	return args.Get(0).([]dbprovider.RunProperties), args.Error(1)
+}
+
+func (m *MockDbProvider) TopRuns(ctx context.Context, gameId string, nTop uint32) (dbprovider.RunTopData, error) {
+	args := m.Called(gameId, nTop)
+	return args.Get(0).(dbprovider.RunTopData), args.Error(1)
+}
+
+func (m *MockDbProvider) CountRuns(ctx context.Context, gameId string) (uint64, error) {
+	args := m.Called(gameId)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *MockDbProvider) SetUserState(ctx context.Context, gameId string, userId string, state dbprovider.UserState, rec dbprovider.WriteRecords) error {
+	args := m.Called(gameId, userId, state, rec)
+	return args.Error(0)
+}
+
+func (m *MockDbProvider) GetUserState(ctx context.Context, gameId string, userId string) (dbprovider.UserState, error) {
+	args := m.Called(gameId, userId)
+	return args.Get(0).(dbprovider.UserState), args.Error(1)
+}
+
+func (m *MockDbProvider) PutQuarantined(ctx context.Context, gameId string, item dbprovider.QuarantineItem) error {
+	args := m.Called(gameId, item)
+	return args.Error(0)
+}
+
+func (m *MockDbProvider) GetQuarantined(ctx context.Context, gameId string, id string) (*dbprovider.QuarantineItem, error) {
+	args := m.Called(gameId, id)
+	return args.Get(0).(*dbprovider.QuarantineItem), args.Error(1)
+}
+
+func (m *MockDbProvider) ListQuarantined(ctx context.Context, gameId string, limit uint32) ([]dbprovider.QuarantineItem, error) {
+	args := m.Called(gameId, limit)
+	return args.Get(0).([]dbprovider.QuarantineItem), args.Error(1)
+}
+
+func (m *MockDbProvider) DeleteQuarantined(ctx context.Context, gameId string, id string) (*dbprovider.QuarantineItem, error) {
+	args := m.Called(gameId, id)
+	return args.Get(0).(*dbprovider.QuarantineItem), args.Error(1)
+}
+
+func (m *MockDbProvider) PutAuditEntry(ctx context.Context, entry dbprovider.AuditEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockDbProvider) ListAuditEntries(ctx context.Context, fromSeq uint64, limit uint32) ([]dbprovider.AuditEntry, error) {
+	args := m.Called(fromSeq, limit)
+	return args.Get(0).([]dbprovider.AuditEntry), args.Error(1)
+}
+
+func (m *MockDbProvider) LastAuditEntry(ctx context.Context) (*dbprovider.AuditEntry, error) {
+	args := m.Called()
+	return args.Get(0).(*dbprovider.AuditEntry), args.Error(1)
+}
+
+func (m *MockDbProvider) ListChanges(ctx context.Context, gameId string, fromVersion uint64, limit uint32) ([]dbprovider.ChangeEntry, error) {
+	args := m.Called(gameId, fromVersion, limit)
+	return args.Get(0).([]dbprovider.ChangeEntry), args.Error(1)
+}
+
+func (m *MockDbProvider) LastChange(ctx context.Context, gameId string) (*dbprovider.ChangeEntry, error) {
+	args := m.Called(gameId)
+	return args.Get(0).(*dbprovider.ChangeEntry), args.Error(1)
+}
+
+func (m *MockDbProvider) TrimChanges(ctx context.Context, gameId string, beforeVersion uint64) error {
+	args := m.Called(gameId, beforeVersion)
+	return args.Error(0)
+}
+
+func (m *MockDbProvider) PutOutboxEvent(ctx context.Context, event dbprovider.ScoreEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+func (m *MockDbProvider) ListOutboxEvents(ctx context.Context, limit uint32) ([]dbprovider.ScoreEvent, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]dbprovider.ScoreEvent), args.Error(1)
+}
+
+func (m *MockDbProvider) DeleteOutboxEvent(ctx context.Context, id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
 func (m *MockDbProvider) Shutdown(ctx context.Context) error {
 	args := m.Called()
 	return args.Error(0)
@@ -83,7 +208,7 @@ func TestCacheSimpleProvider(t *testing.T) {
 			c := make(chan dbprovider.TopData, 1)
 			e := make(chan error, 1)
 			go func() {
-				top, err := cacheProvider.Top(context.Background(), gameId, nTop)
+				top, err := cacheProvider.Top(context.Background(), gameId, nTop, dbprovider.TopOptions{})
 				c <- top
 				e <- err
 			}()
@@ -93,14 +218,14 @@ func TestCacheSimpleProvider(t *testing.T) {
 		mockClock.SetTime(time.UnixMilli(0))
 
 		mockDbProvider.On("Top", gameId1, uint32(10)).Return(dbprovider.TopData{}, nil)
-		top, err = cacheProvider.Top(context.Background(), gameId1, 10)
+		top, err = cacheProvider.Top(context.Background(), gameId1, 10, dbprovider.TopOptions{})
 		require.NoError(t, err)
 		mockDbProvider.AssertNumberOfCalls(t, "Top", 1)
 		require.Equal(t, dbprovider.TopData{}, top)
 
 		mockDbProvider.On("Top", gameId2, uint32(10)).Return(dbprovider.TopData{}, nil)
 		mockDbProvider.On("Top", gameId2, uint32(8)).Return(dbprovider.TopData{}, nil)
-		_, _ = cacheProvider.Top(context.Background(), gameId2, 10)
+		_, _ = cacheProvider.Top(context.Background(), gameId2, 10, dbprovider.TopOptions{})
 		topCh1, errCh1 := getTopAsync(gameId2, 10)
 		topCh2, errCh2 := getTopAsync(gameId2, 8)
 		require.NoError(t, <-errCh1)
@@ -111,8 +236,8 @@ func TestCacheSimpleProvider(t *testing.T) {
 
 		mockDbProvider.On("Top", gameId2, uint32(20)).Return(dbprovider.TopData{}, nil)
 		mockDbProvider.On("Top", gameId2, uint32(100)).Return(dbprovider.TopData{}, nil)
-		top1, err1 := cacheProvider.Top(context.Background(), gameId2, 20)
-		top2, err2 := cacheProvider.Top(context.Background(), gameId2, 100)
+		top1, err1 := cacheProvider.Top(context.Background(), gameId2, 20, dbprovider.TopOptions{})
+		top2, err2 := cacheProvider.Top(context.Background(), gameId2, 100, dbprovider.TopOptions{})
 		require.NoError(t, err1)
 		require.NoError(t, err2)
 		require.Equal(t, dbprovider.TopData{}, top1)
@@ -158,13 +283,13 @@ func TestCacheSimpleProvider(t *testing.T) {
 		mockClock.SetTime(now)
 
 		mockCall = mockDbProvider.On("Top", gameId1, uint32(10)).Return(dbprovider.TopData{userData1, userData2, userData3}, nil)
-		top, err = cacheProvider.Top(context.Background(), gameId1, 10)
+		top, err = cacheProvider.Top(context.Background(), gameId1, 10, dbprovider.TopOptions{})
 		require.NoError(t, err)
 		require.Equal(t, dbprovider.TopData{userData1, userData2, userData3}, top)
 		mockDbProvider.AssertNumberOfCalls(t, "Top", 1)
 
 		mockDbProvider.On("Top", gameId1, uint32(2)).Return(dbprovider.TopData{userData1, userData2}, nil)
-		top, err = cacheProvider.Top(context.Background(), gameId1, 2)
+		top, err = cacheProvider.Top(context.Background(), gameId1, 2, dbprovider.TopOptions{})
 		require.NoError(t, err)
 		require.Equal(t, dbprovider.TopData{userData1, userData2}, top)
 		mockDbProvider.AssertNumberOfCalls(t, "Top", 1)
@@ -174,16 +299,252 @@ func TestCacheSimpleProvider(t *testing.T) {
 		user3Mod.Score = 150
 		mockCall.Unset()
 		mockDbProvider.On("Top", gameId1, uint32(10)).Return(dbprovider.TopData{userData1, userData2, user3Mod}, nil)
-		top, err = cacheProvider.Top(context.Background(), gameId1, 10)
+		top, err = cacheProvider.Top(context.Background(), gameId1, 10, dbprovider.TopOptions{})
 		require.NoError(t, err)
 		require.Equal(t, dbprovider.TopData{userData1, userData2, userData3}, top)
 		mockDbProvider.AssertNumberOfCalls(t, "Top", 1)
 
 		mockClock.SetTime(now.Add(time.Duration(CACHE_TTL) * time.Millisecond))
-		top, err = cacheProvider.Top(context.Background(), gameId1, 10)
+		top, err = cacheProvider.Top(context.Background(), gameId1, 10, dbprovider.TopOptions{})
 		require.NoError(t, err)
 		require.Equal(t, dbprovider.TopData{userData1, userData2, user3Mod}, top)
 		mockDbProvider.AssertNumberOfCalls(t, "Top", 2)
 	})
 
+	runTest(t, "skip cached data with expired entries", func(t *testing.T, cacheProvider *CacheSimpleProvider) {
+		var (
+			top            dbprovider.TopData
+			err            error
+			mockClock      = (*cacheProvider.clock).(*utils.MockClock)
+			mockDbProvider = cacheProvider.dbprovider.(*MockDbProvider)
+		)
+
+		userData1 := dbprovider.UserData{
+			UserId:         "user1",
+			UserProperties: dbprovider.UserProperties{Score: 84, Ts: 1000},
+		}
+
+		userData2 := dbprovider.UserData{
+			UserId:         "user2",
+			UserProperties: dbprovider.UserProperties{Score: 52, Ts: 2000},
+		}
+
+		mockClock.SetTime(time.Now())
+
+		mockCall := mockDbProvider.On("Top", gameId1, uint32(10)).Return(dbprovider.TopData{userData1, userData2}, nil)
+		top, err = cacheProvider.Top(context.Background(), gameId1, 10, dbprovider.TopOptions{MinTs: 1000})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{userData1, userData2}, top)
+		mockDbProvider.AssertNumberOfCalls(t, "Top", 1)
+
+		mockCall.Unset()
+		mockDbProvider.On("Top", gameId1, uint32(10)).Return(dbprovider.TopData{userData2}, nil)
+		top, err = cacheProvider.Top(context.Background(), gameId1, 10, dbprovider.TopOptions{MinTs: 1500})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{userData2}, top)
+		mockDbProvider.AssertNumberOfCalls(t, "Top", 2)
+
+		top, err = cacheProvider.Top(context.Background(), gameId1, 10, dbprovider.TopOptions{MinTs: 1500})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{userData2}, top)
+		mockDbProvider.AssertNumberOfCalls(t, "Top", 2)
+	})
+
+	runTest(t, "invalidate cached data", func(t *testing.T, cacheProvider *CacheSimpleProvider) {
+		var (
+			top            dbprovider.TopData
+			err            error
+			mockClock      = (*cacheProvider.clock).(*utils.MockClock)
+			mockDbProvider = cacheProvider.dbprovider.(*MockDbProvider)
+		)
+
+		userData1 := dbprovider.UserData{
+			UserId:         "user1",
+			UserProperties: dbprovider.UserProperties{Score: 84},
+		}
+
+		userData2 := dbprovider.UserData{
+			UserId:         "user2",
+			UserProperties: dbprovider.UserProperties{Score: 52},
+		}
+
+		mockClock.SetTime(time.Now())
+
+		mockCall := mockDbProvider.On("Top", gameId1, uint32(10)).Return(dbprovider.TopData{userData1, userData2}, nil)
+		top, err = cacheProvider.Top(context.Background(), gameId1, 10, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{userData1, userData2}, top)
+		mockDbProvider.AssertNumberOfCalls(t, "Top", 1)
+
+		err = cacheProvider.Invalidate(context.Background(), gameId1)
+		require.NoError(t, err)
+
+		mockCall.Unset()
+		mockDbProvider.On("Top", gameId1, uint32(10)).Return(dbprovider.TopData{userData2}, nil)
+		top, err = cacheProvider.Top(context.Background(), gameId1, 10, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{userData2}, top)
+		mockDbProvider.AssertNumberOfCalls(t, "Top", 2)
+	})
+
+	runTest(t, "get times of cached data", func(t *testing.T, cacheProvider *CacheSimpleProvider) {
+		var (
+			mockClock      = (*cacheProvider.clock).(*utils.MockClock)
+			mockDbProvider = cacheProvider.dbprovider.(*MockDbProvider)
+		)
+
+		userData1 := dbprovider.UserData{
+			UserId:         "user1",
+			UserProperties: dbprovider.UserProperties{Score: 84},
+		}
+
+		userData2 := dbprovider.UserData{
+			UserId:         "user2",
+			UserProperties: dbprovider.UserProperties{Score: 52},
+		}
+
+		ttl := int64(CACHE_TTL)
+		now := time.Now()
+		mockClock.SetTime(now)
+
+		mockDbProvider.On("Top", gameId1, uint32(10)).Return(dbprovider.TopData{userData1, userData2}, nil)
+		cacheData, err := cacheProvider.CachedTop(context.Background(), gameId1, 10, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Equal(t, &cacheprovider.CacheData{
+			Ts: now.UnixMilli(), Exp: now.UnixMilli() + ttl, Cnt: 10, Data: dbprovider.TopData{userData1, userData2},
+		}, cacheData)
+
+		// cached data keeps its times
+		mockClock.SetTime(now.Add(time.Duration(ttl/2) * time.Millisecond))
+		cacheData, err = cacheProvider.CachedTop(context.Background(), gameId1, 1, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Equal(t, &cacheprovider.CacheData{
+			Ts: now.UnixMilli(), Exp: now.UnixMilli() + ttl, Cnt: 1, Data: dbprovider.TopData{userData1},
+		}, cacheData)
+		mockDbProvider.AssertNumberOfCalls(t, "Top", 1)
+
+		// expired data is read again
+		later := now.Add(time.Duration(ttl) * time.Millisecond)
+		mockClock.SetTime(later)
+		cacheData, err = cacheProvider.CachedTop(context.Background(), gameId1, 10, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Equal(t, later.UnixMilli(), cacheData.Ts)
+		require.Equal(t, later.UnixMilli()+ttl, cacheData.Exp)
+		mockDbProvider.AssertNumberOfCalls(t, "Top", 2)
+	})
+
+	runTest(t, "key cached data on omitted fields", func(t *testing.T, cacheProvider *CacheSimpleProvider) {
+		var (
+			top            dbprovider.TopData
+			err            error
+			mockClock      = (*cacheProvider.clock).(*utils.MockClock)
+			mockDbProvider = cacheProvider.dbprovider.(*MockDbProvider)
+			mockCall       *mock.Call
+		)
+
+		userData1 := dbprovider.UserData{
+			UserId:         "user1",
+			UserProperties: dbprovider.UserProperties{Score: 84, Name: "Jack", Params: "some_payload_1"},
+		}
+
+		userData2 := dbprovider.UserData{
+			UserId:         "user2",
+			UserProperties: dbprovider.UserProperties{Score: 52, Name: "Tom", Params: "some_payload_2"},
+		}
+
+		mockClock.SetTime(time.Now())
+
+		// data without optional fields can't be used for requests with them
+		mockCall = mockDbProvider.On("Top", gameId1, uint32(10)).Return(dbprovider.TopData{
+			{UserId: "user1", UserProperties: dbprovider.UserProperties{Score: 84}},
+			{UserId: "user2", UserProperties: dbprovider.UserProperties{Score: 52}},
+		}, nil)
+		top, err = cacheProvider.Top(context.Background(), gameId1, 10, dbprovider.TopOptions{OmitFields: dbprovider.TOPFIELDS_OPTIONAL})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{
+			{UserId: "user1", UserProperties: dbprovider.UserProperties{Score: 84}},
+			{UserId: "user2", UserProperties: dbprovider.UserProperties{Score: 52}},
+		}, top)
+		mockDbProvider.AssertNumberOfCalls(t, "Top", 1)
+
+		mockCall.Unset()
+		mockDbProvider.On("Top", gameId1, uint32(10)).Return(dbprovider.TopData{userData1, userData2}, nil)
+		top, err = cacheProvider.Top(context.Background(), gameId1, 10, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{userData1, userData2}, top)
+		mockDbProvider.AssertNumberOfCalls(t, "Top", 2)
+
+		// data with all fields is used for requests with omitted fields
+		top, err = cacheProvider.Top(context.Background(), gameId1, 1, dbprovider.TopOptions{OmitFields: dbprovider.TOPFIELD_PARAMS})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{
+			{UserId: "user1", UserProperties: dbprovider.UserProperties{Score: 84, Name: "Jack"}},
+		}, top)
+		top, err = cacheProvider.Top(context.Background(), gameId1, 10, dbprovider.TopOptions{OmitFields: dbprovider.TOPFIELDS_OPTIONAL})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{
+			{UserId: "user1", UserProperties: dbprovider.UserProperties{Score: 84}},
+			{UserId: "user2", UserProperties: dbprovider.UserProperties{Score: 52}},
+		}, top)
+		mockDbProvider.AssertNumberOfCalls(t, "Top", 2)
+
+		// the cached data isn't changed by projections
+		top, err = cacheProvider.Top(context.Background(), gameId1, 10, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{userData1, userData2}, top)
+
+		err = cacheProvider.Invalidate(context.Background(), gameId1)
+		require.NoError(t, err)
+		_, err = cacheProvider.Top(context.Background(), gameId1, 10, dbprovider.TopOptions{OmitFields: dbprovider.TOPFIELDS_OPTIONAL})
+		require.NoError(t, err)
+		mockDbProvider.AssertNumberOfCalls(t, "Top", 3)
+	})
+
+	runTest(t, "share data of concurrent calls with the same number of entries", func(t *testing.T, cacheProvider *CacheSimpleProvider) {
+		var (
+			mockClock      = (*cacheProvider.clock).(*utils.MockClock)
+			mockDbProvider = cacheProvider.dbprovider.(*MockDbProvider)
+			entered        = make(chan struct{})
+			release        = make(chan struct{})
+		)
+
+		userData1 := dbprovider.UserData{UserId: "user1", UserProperties: dbprovider.UserProperties{Score: 84}}
+		userData2 := dbprovider.UserData{UserId: "user2", UserProperties: dbprovider.UserProperties{Score: 52}}
+
+		mockClock.SetTime(time.Now())
+
+		mockDbProvider.On("Top", gameId1, uint32(1)).Run(func(args mock.Arguments) {
+			close(entered)
+			<-release
+		}).Return(dbprovider.TopData{userData1}, nil).Once()
+		mockDbProvider.On("Top", gameId1, uint32(10)).Return(dbprovider.TopData{userData1, userData2}, nil)
+
+		done := make(chan dbprovider.TopData)
+		go func() {
+			top, err := cacheProvider.Top(context.Background(), gameId1, 1, dbprovider.TopOptions{})
+			require.NoError(t, err)
+			done <- top
+		}()
+		<-entered
+
+		// the longer top isn't served with the shorter data requested in the meantime
+		longDone := make(chan dbprovider.TopData)
+		go func() {
+			top, err := cacheProvider.Top(context.Background(), gameId1, 10, dbprovider.TopOptions{})
+			require.NoError(t, err)
+			longDone <- top
+		}()
+
+		var top dbprovider.TopData
+		select {
+		case top = <-longDone:
+			close(release)
+		case <-time.After(time.Second): // waits for the shorter top
+			close(release)
+			top = <-longDone
+		}
+		require.Equal(t, dbprovider.TopData{userData1, userData2}, top)
+		require.Equal(t, dbprovider.TopData{userData1}, <-done)
+		mockDbProvider.AssertNumberOfCalls(t, "Top", 2)
+	})
 }
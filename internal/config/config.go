@@ -2,23 +2,49 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	auditsink "go-leaderboard-server/internal/audit"
 	cacheprovider "go-leaderboard-server/internal/cache"
 	dbprovider "go-leaderboard-server/internal/db"
+	deadletterprovider "go-leaderboard-server/internal/deadletter"
+	eventsink "go-leaderboard-server/internal/eventsink"
+	idempotencyprovider "go-leaderboard-server/internal/idempotency"
+	quotaprovider "go-leaderboard-server/internal/quota"
+	ratelimitprovider "go-leaderboard-server/internal/ratelimit"
 	"go-leaderboard-server/internal/utils"
+	"net"
+	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
-	IsDebug                 bool        // Debug environment flag
-	IsTest                  bool        // Test environment flag
-	Host                    string      `default:"0.0.0.0"` // Server host
-	Port                    string      `default:"8415"`    // Server port
-	Db                      DbConfig    // DB Provider Configuration
-	Cache                   CacheConfig // Cache Provider Configuration
-	TimeoutServicesInit     uint32      // Server initialization timeout (ms)
-	TimeoutServerClose      uint32      // Server shutdown timeout (ms)
-	TimeoutServicesShutdown uint32      // Services shutdown timeout (ms)
-	ApiUI                   bool        // Swagger UI startup flag
+	IsDebug                 bool                   // Debug environment flag
+	IsTest                  bool                   // Test environment flag
+	Host                    string                 `default:"0.0.0.0"` // Server host
+	Port                    string                 `default:"8415"`    // Server port
+	Db                      DbConfig               // DB Provider Configuration
+	Cache                   CacheConfig            // Cache Provider Configuration
+	Boards                  map[string]BoardConfig // Per-game leaderboard settings (key - gameId, or tenant/gameId for games of tenants)
+	MaintenanceInterval     uint32                 `default:"60000"`  // Interval of leaderboard background maintenance (ms)
+	SignatureWindow         uint32                 `default:"300000"` // Maximum clock difference accepted for signed requests (ms)
+	Auth                    *AuthConfig            // API key authentication (nil - disabled)
+	Jwt                     *JwtConfig             // JWT bearer authentication of players (nil - disabled)
+	RateLimit               *RateLimitConfig       // Request rate limiting (nil - disabled)
+	Idempotency             *IdempotencyConfig     // Idempotency keys of score submission and deletion (nil - disabled)
+	Audit                   *AuditConfig           // Audit log of destructive and moderation operations (nil - disabled)
+	Quota                   *QuotaConfig           // Usage quotas of games and tenants (nil - disabled)
+	Grpc                    *GrpcConfig            // gRPC API (nil - disabled)
+	Subscriptions           SubscriptionsConfig    // Real-time subscriptions to tops
+	Changes                 *ChangesConfig         // Change feed of boards for incremental sync (nil - disabled)
+	Webhooks                *WebhooksConfig        // Webhooks on leaderboard events (nil - disabled)
+	Events                  *EventsConfig          // Score submission events published to an event sink (nil - disabled)
+	Http                    HttpConfig             // Compression and request limits of the HTTP API
+	TimeoutServicesInit     uint32                 // Server initialization timeout (ms)
+	TimeoutServerClose      uint32                 // Server shutdown timeout (ms)
+	TimeoutServicesShutdown uint32                 // Services shutdown timeout (ms)
+	ApiUI                   bool                   // Swagger UI startup flag
 }
 
 const (
@@ -44,6 +70,286 @@ type CacheConfig struct {
 	Config cacheprovider.ICacheProviderConfig
 }
 
+const (
+	RATELIMITTYPE_MEMORY = iota
+	RATELIMITTYPE_REDIS
+)
+
+const (
+	ROUTEGROUP_LEADERBOARD = "leaderboard"
+	ROUTEGROUP_ADMIN       = "admin"
+)
+
+type RateLimitConfig struct {
+	Type   int
+	Config ratelimitprovider.IRateLimitProviderConfig
+	Groups map[string]RateLimitGroupConfig // Limits per route group (key - ROUTEGROUP_*)
+}
+
+// Limits of every identity are counted separately (nil - not limited)
+type RateLimitGroupConfig struct {
+	Ip     *ratelimitprovider.Limit // Per client IP
+	ApiKey *ratelimitprovider.Limit // Per API key
+	User   *ratelimitprovider.Limit // Per userId of the request body
+	Tenant *ratelimitprovider.Limit // Per tenant of the API key, shared by all keys of the tenant
+}
+
+const (
+	IDEMPOTENCYTYPE_MEMORY = iota
+	IDEMPOTENCYTYPE_REDIS
+)
+
+type IdempotencyConfig struct {
+	Type        int
+	Config      idempotencyprovider.IIdempotencyProviderConfig
+	Window      uint32 `default:"86400000"` // Lifetime of stored responses (ms)
+	LockTimeout uint32 `default:"10000"`    // Maximum time a request holds its key while in progress (ms)
+}
+
+const (
+	AUDITSINKTYPE_FILE = iota
+	AUDITSINKTYPE_DB   // Leaderboard DB provider
+)
+
+type AuditConfig struct {
+	Type   int
+	Config auditsink.IAuditSinkConfig
+	Key    string // Secret of HMAC-SHA256 of the entries (at least 32 characters), so the chain can't be rebuilt without it
+}
+
+const (
+	QUOTATYPE_MEMORY = iota
+	QUOTATYPE_REDIS
+)
+
+type QuotaConfig struct {
+	Type    int
+	Config  quotaprovider.IQuotaProviderConfig
+	Games   map[string]QuotaLimits // Limits per game (key - gameId, or tenant/gameId for games of tenants)
+	Tenants map[string]QuotaLimits // Limits per tenant, shared by all games of the tenant (key - tenant id, empty - default tenant)
+}
+
+// Usage is counted for all games, the limits are checked only if they are set (0 - unlimited)
+type QuotaLimits struct {
+	WritesPerDay uint32 // Maximum number of stored scores and runs per day (UTC), deletions are not limited
+	MaxEntries   uint32 // Maximum number of stored entries (runs for runs boards), new entries are rejected
+}
+
+type GrpcConfig struct {
+	Port string `default:"8416"` // gRPC server port (host is shared with the HTTP server)
+}
+
+type SubscriptionsConfig struct {
+	Interval       uint32 `default:"100"`   // Minimum interval between updates of a board, changes within it are combined (ms)
+	Heartbeat      uint32 `default:"15000"` // Interval of heartbeats of idle subscriptions (ms)
+	BufferSize     uint32 `default:"16"`    // Updates queued for a subscriber, slower subscribers are disconnected
+	MaxSubscribers uint32 `default:"10000"` // Maximum number of subscriptions of the server instance
+}
+
+// Routes are identified by the method and the path as registered, e.g. "POST /leaderboard/SendScore" or "PUT /v2/games/:gameId/users/:userId"
+type HttpConfig struct {
+	Compression    *CompressionConfig // Compression of responses (nil - disabled)
+	MaxBodySize    uint32             `default:"65536"` // Maximum size of request bodies (bytes)
+	MaxBodySizes   map[string]uint32  // Maximum size of request bodies per route, overrides MaxBodySize (key - method and route)
+	TrustedProxies []string           // IPs and CIDRs of proxies allowed to set the client IP by X-Forwarded-For and X-Real-IP (empty - none)
+}
+
+// Responses are compressed with brotli or gzip, as preferred by the Accept-Encoding header (brotli on a tie)
+type CompressionConfig struct {
+	MinSize uint32 `default:"1024"` // Minimum size of compressed responses, smaller responses are sent as is (bytes)
+}
+
+type ChangesConfig struct {
+	Retention    uint32 `default:"10000"` // Number of latest changes kept per game, older versions require a resync
+	MaxWait      uint32 `default:"30000"` // Maximum wait of a long-polling request for changes (ms)
+	PollInterval uint32 `default:"1000"`  // Interval of checking changes made by other server instances while waiting (ms)
+}
+
+const (
+	WEBHOOKEVENT_ENTERED_TOP = "entered_top" // User enters the top
+	WEBHOOKEVENT_TOOK_FIRST  = "took_first"  // User takes the first place
+	WEBHOOKEVENT_OVERTAKEN   = "overtaken"   // User is overtaken within the top by the user who submitted a score
+)
+
+const (
+	DEADLETTERTYPE_MEMORY = iota
+	DEADLETTERTYPE_REDIS
+)
+
+type WebhooksConfig struct {
+	Endpoints    []WebhookEndpointConfig
+	TopSize      uint32 `default:"10"`     // Size of the top watched for events
+	MaxAttempts  uint32 `default:"8"`      // Attempts to deliver an event, failed events are moved to dead letters
+	RetryInitial uint32 `default:"1000"`   // Delay before the first retry, doubled for every next one (ms)
+	RetryMax     uint32 `default:"300000"` // Maximum delay between retries (ms)
+	Timeout      uint32 `default:"5000"`   // Timeout of a delivery attempt (ms)
+	QueueSize    uint32 `default:"1000"`   // Events queued per endpoint, events over it are moved to dead letters
+	DeadLetter   DeadLetterConfig
+}
+
+type WebhookEndpointConfig struct {
+	Id     string   // Id of the endpoint (alphanumeric), used by the dead letters API
+	Url    string   // Receives events as POST requests with JSON bodies
+	Secret string   // HMAC key of signatures of requests
+	Events []string // Events sent to the endpoint (WEBHOOKEVENT_*, empty - all)
+	Games  []string // Games of the events (gameId, or tenant/gameId for games of tenants, empty - all)
+}
+
+type DeadLetterConfig struct {
+	Type   int
+	Config deadletterprovider.IDeadLetterProviderConfig
+}
+
+const (
+	EVENTSINKTYPE_FILE = iota // NDJSON file or stdout
+	EVENTSINKTYPE_NATS
+)
+
+// Events are stored to the outbox of the leaderboard DB with the submission and published to the sink by a relay
+type EventsConfig struct {
+	Type          int
+	Config        eventsink.IEventSinkConfig
+	RankDepth     uint32 `default:"100"`  // Depth of the top in which ranks of users are looked up, users below it have rank 0
+	RelayInterval uint32 `default:"1000"` // Interval of publishing events left in the outbox, e.g. while the sink is down (ms)
+	BatchSize     uint32 `default:"100"`  // Maximum number of events read from the outbox at once
+}
+
+const (
+	ROLE_CLIENT = "client" // Reads data and submits scores of its own user
+	ROLE_SERVER = "server" // Reads data and submits scores of any user
+	ROLE_ADMIN  = "admin"  // Full access, including deletion and moderation
+)
+
+type ApiKeyConfig struct {
+	Key      string   `json:"key"`      // Value sent in the X-Api-Key header
+	Role     string   `json:"role"`     // Role of the key (ROLE_*)
+	UserId   string   `json:"userId"`   // User the key belongs to (client keys only)
+	Games    []string `json:"games"`    // Allowed gameIds ("*" - all games of the tenant)
+	Tenant   string   `json:"tenant"`   // Tenant the key belongs to (empty - default tenant)
+	Operator bool     `json:"operator"` // Reads audit entries, usage and dead letters of all tenants (admin keys of the default tenant only)
+}
+
+var tenantPattern = regexp.MustCompile(`^[A-Za-z0-9]{1,32}$`)
+
+var endpointIdPattern = regexp.MustCompile(`^[A-Za-z0-9]{1,32}$`)
+
+// Tenant ids are alphanumeric, up to 32 characters
+func IsValidTenant(tenant string) bool {
+	return tenantPattern.MatchString(tenant)
+}
+
+// Checks the endpoint settings
+func (e *WebhookEndpointConfig) Validate() error {
+	var err error
+	if !endpointIdPattern.MatchString(e.Id) {
+		err = errors.Join(err, errors.New("wrong id"))
+	}
+	u, e1 := url.Parse(e.Url)
+	if e1 != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		err = errors.Join(err, errors.New("wrong url"))
+	}
+	if e.Secret == "" {
+		err = errors.Join(err, errors.New("no secret"))
+	}
+	for _, event := range e.Events {
+		switch event {
+		case WEBHOOKEVENT_ENTERED_TOP, WEBHOOKEVENT_TOOK_FIRST, WEBHOOKEVENT_OVERTAKEN:
+		default:
+			err = errors.Join(err, fmt.Errorf("wrong event (%s)", event))
+		}
+	}
+	return err
+}
+
+// Checks the key settings, used for keys from both the config and the keys file
+func (k *ApiKeyConfig) Validate() error {
+	var err error
+	if len(k.Key) < 16 {
+		err = errors.Join(err, errors.New("api key is too short"))
+	}
+	switch k.Role {
+	case ROLE_CLIENT:
+		if k.UserId == "" {
+			err = errors.Join(err, errors.New("client api key requires user id"))
+		}
+	case ROLE_SERVER, ROLE_ADMIN:
+	default:
+		err = errors.Join(err, errors.New("wrong api key role"))
+	}
+	if len(k.Games) == 0 {
+		err = errors.Join(err, errors.New("api key requires allowed games"))
+	}
+	if k.Tenant != "" && !IsValidTenant(k.Tenant) {
+		err = errors.Join(err, errors.New("wrong api key tenant"))
+	}
+	if k.Operator && (k.Role != ROLE_ADMIN || k.Tenant != "") {
+		err = errors.Join(err, errors.New("operator api key must be an admin key of the default tenant"))
+	}
+	return err
+}
+
+type AuthConfig struct {
+	Keys           []ApiKeyConfig // API keys
+	KeysFile       string         // Path to JSON file with an array of additional keys, reloaded when changed (empty - not used)
+	ReloadInterval uint32         `default:"10000"` // Interval of the keys file change check (ms)
+}
+
+// Tokens with "server" or "admin" in the space-separated "scope" claim can act on behalf of any user.
+// The "tenant" claim sets the tenant of the player (must match the tenant of the API key if both are sent)
+type JwtConfig struct {
+	Secret   string // Secret of HS256 tokens (empty - HS256 is not accepted)
+	JwksFile string // Path to JWKS file with public keys of RS256 tokens (empty - RS256 is not accepted)
+	Issuer   string // Expected "iss" claim (empty - not checked)
+	Audience string // Expected "aud" claim (empty - not checked)
+	Leeway   uint32 `default:"30000"` // Allowed clock difference for "exp" and "nbf" claims (ms)
+}
+
+const (
+	DECAYTYPE_LINEAR      = iota // Score loses Rate of the submitted score per period
+	DECAYTYPE_EXPONENTIAL        // Score loses Rate of its remaining part above Floor per period
+)
+
+type DecayConfig struct {
+	Type   int     // Decay type (DECAYTYPE_*)
+	Rate   float64 // Fraction of the score lost per period (0-1)
+	Period uint32  // Decay period (ms)
+	Delay  uint32  // Inactivity time before the decay starts (ms)
+	Floor  float64 // Minimum score the decay can lead to
+}
+
+const (
+	RULEACTION_REJECT     = iota // Violating submissions are rejected
+	RULEACTION_QUARANTINE        // Violating submissions are held and don't affect the board
+)
+
+type RulesConfig struct {
+	MinScore       *float64 // Minimum allowed score (nil - no limit)
+	MaxScore       *float64 // Maximum allowed score (nil - no limit)
+	MaxDelta       *float64 // Maximum difference from the previous score of the user (nil - no limit)
+	MaxSubmissions uint32   // Maximum number of submissions per user per minute (0 - unlimited)
+	MinDuration    uint32   // Minimum match duration reported with the submission (ms, 0 - not required)
+	Action         int      // Action applied to violating submissions (RULEACTION_*)
+}
+
+const (
+	BOARDTYPE_UNIQUE = iota // One entry per user
+	BOARDTYPE_RUNS          // Several best runs per user
+)
+
+type BoardConfig struct {
+	Type        int          // Board type (BOARDTYPE_*)
+	RunsPerUser uint32       // Number of best runs kept per user (BOARDTYPE_RUNS only)
+	Decay       *DecayConfig // Score decay for inactive users (nil - disabled)
+	Ttl         uint64       // Lifetime of entries without new submissions (ms, 0 - unlimited)
+	MaxEntries  uint32       // Maximum number of stored entries, the lowest ranked ones are evicted (0 - unlimited)
+	Rules       *RulesConfig // Anti-cheat rules checked on score submission (nil - disabled)
+	Secrets     []string     // HMAC keys of signed score submissions, two keys can be active during rotation (empty - signature not required)
+}
+
+func (c *Config) GetBoardConfig(gameId string) BoardConfig {
+	return c.Boards[gameId]
+}
+
 var config Config
 var configPtr *Config
 
@@ -61,6 +367,160 @@ func configApplyAndValidate(c *Config) {
 		err = errors.Join(err, errors.New("wrong port value"))
 	}
 
+	if c.Grpc != nil {
+		_, e := strconv.ParseUint(c.Grpc.Port, 10, 16)
+		if e != nil || c.Grpc.Port == port {
+			err = errors.Join(err, errors.New("wrong grpc port value"))
+		}
+	}
+
+	for route := range c.Http.MaxBodySizes {
+		if method, path, found := strings.Cut(route, " "); !found || method == "" || !strings.HasPrefix(path, "/") {
+			err = errors.Join(err, fmt.Errorf("wrong max body size route (%s)", route))
+		}
+	}
+
+	for _, proxy := range c.Http.TrustedProxies {
+		_, _, e := net.ParseCIDR(proxy)
+		if e != nil && net.ParseIP(proxy) == nil {
+			err = errors.Join(err, fmt.Errorf("wrong trusted proxy (%s)", proxy))
+		}
+	}
+
+	if c.Subscriptions.Heartbeat == 0 || c.Subscriptions.BufferSize == 0 {
+		err = errors.Join(err, errors.New("wrong subscriptions config"))
+	}
+
+	if c.Changes != nil && c.Changes.MaxWait < c.Changes.PollInterval {
+		err = errors.Join(err, errors.New("wrong changes config"))
+	}
+
+	if c.Webhooks != nil {
+		if c.Webhooks.TopSize > 1000 {
+			err = errors.Join(err, errors.New("wrong webhooks top size"))
+		}
+		ids := make(map[string]bool)
+		for i := range c.Webhooks.Endpoints {
+			e := c.Webhooks.Endpoints[i].Validate()
+			if e == nil && ids[c.Webhooks.Endpoints[i].Id] {
+				e = errors.New("duplicate id")
+			}
+			if e != nil {
+				err = errors.Join(err, fmt.Errorf("wrong webhook endpoint #%d: %w", i, e))
+			}
+			ids[c.Webhooks.Endpoints[i].Id] = true
+		}
+	}
+
+	if c.Events != nil && c.Events.RankDepth > 10000 {
+		err = errors.Join(err, errors.New("wrong events rank depth"))
+	}
+
+	if c.Auth != nil {
+		if len(c.Auth.Keys) == 0 && c.Auth.KeysFile == "" {
+			err = errors.Join(err, errors.New("no api keys are configured"))
+		}
+		for i := range c.Auth.Keys {
+			e := c.Auth.Keys[i].Validate()
+			if e != nil {
+				err = errors.Join(err, fmt.Errorf("wrong api key #%d: %w", i, e))
+			}
+		}
+	}
+
+	if c.Jwt != nil {
+		if c.Jwt.Secret == "" && c.Jwt.JwksFile == "" {
+			err = errors.Join(err, errors.New("no jwt secret or jwks file is configured"))
+		}
+		if c.Jwt.Secret != "" && len(c.Jwt.Secret) < 32 {
+			err = errors.Join(err, errors.New("jwt secret is too short"))
+		}
+	}
+
+	if c.Audit != nil && len(c.Audit.Key) < 32 {
+		err = errors.Join(err, errors.New("audit key is too short"))
+	}
+
+	if c.RateLimit != nil {
+		for group, groupConf := range c.RateLimit.Groups {
+			for _, limit := range []*ratelimitprovider.Limit{groupConf.Ip, groupConf.ApiKey, groupConf.User, groupConf.Tenant} {
+				if limit != nil && (limit.Burst == 0 || limit.Rate <= 0) {
+					err = errors.Join(err, fmt.Errorf("wrong rate limit (%s)", group))
+				}
+			}
+		}
+	}
+
+	if c.Quota != nil {
+		for gameId := range c.Quota.Games {
+			if tenant, _, found := strings.Cut(gameId, dbprovider.TENANT_SEPARATOR); found && !IsValidTenant(tenant) {
+				err = errors.Join(err, fmt.Errorf("wrong quota tenant (%s)", gameId))
+			}
+		}
+		for tenant := range c.Quota.Tenants {
+			if tenant != "" && !IsValidTenant(tenant) {
+				err = errors.Join(err, fmt.Errorf("wrong quota tenant (%s)", tenant))
+			}
+		}
+	}
+
+	for gameId, board := range c.Boards {
+		if tenant, _, found := strings.Cut(gameId, dbprovider.TENANT_SEPARATOR); found && !IsValidTenant(tenant) {
+			err = errors.Join(err, fmt.Errorf("wrong board tenant (%s)", gameId))
+		}
+
+		switch board.Type {
+		case BOARDTYPE_UNIQUE:
+		case BOARDTYPE_RUNS:
+			if board.RunsPerUser == 0 {
+				err = errors.Join(err, fmt.Errorf("wrong runs per user value (%s)", gameId))
+			}
+			if board.Decay != nil || board.Ttl != 0 || board.MaxEntries != 0 {
+				err = errors.Join(err, fmt.Errorf("decay, ttl and max entries are not supported by runs boards (%s)", gameId))
+			}
+		default:
+			err = errors.Join(err, fmt.Errorf("wrong board type (%s)", gameId))
+		}
+
+		if len(board.Secrets) > 2 {
+			err = errors.Join(err, fmt.Errorf("no more than two secrets can be active (%s)", gameId))
+		}
+		for _, secret := range board.Secrets {
+			if len(secret) < 16 {
+				err = errors.Join(err, fmt.Errorf("secret is too short (%s)", gameId))
+			}
+		}
+
+		if board.Rules != nil {
+			rules := board.Rules
+			if rules.Action != RULEACTION_REJECT && rules.Action != RULEACTION_QUARANTINE {
+				err = errors.Join(err, fmt.Errorf("wrong rules action (%s)", gameId))
+			}
+			if rules.MinScore != nil && rules.MaxScore != nil && *rules.MinScore > *rules.MaxScore {
+				err = errors.Join(err, fmt.Errorf("wrong rules score range (%s)", gameId))
+			}
+			if rules.MaxDelta != nil && *rules.MaxDelta < 0 {
+				err = errors.Join(err, fmt.Errorf("wrong rules max delta value (%s)", gameId))
+			}
+		}
+
+		if board.Decay != nil {
+			decay := board.Decay
+			if decay.Type != DECAYTYPE_LINEAR && decay.Type != DECAYTYPE_EXPONENTIAL {
+				err = errors.Join(err, fmt.Errorf("wrong decay type (%s)", gameId))
+			}
+			if decay.Rate < 0 || decay.Rate > 1 {
+				err = errors.Join(err, fmt.Errorf("wrong decay rate value (%s)", gameId))
+			}
+			if decay.Period == 0 {
+				err = errors.Join(err, fmt.Errorf("wrong decay period value (%s)", gameId))
+			}
+			if decay.Floor < 0 {
+				err = errors.Join(err, fmt.Errorf("wrong decay floor value (%s)", gameId))
+			}
+		}
+	}
+
 	if err != nil {
 		panic(err)
 	}
@@ -34,6 +34,9 @@ func init() {
 				},
 			},
 		},
+		Boards: map[string]BoardConfig{
+			"signedgame": {Secrets: []string{"test-secret-0123456789"}},
+		},
 		ApiUI: false,
 	}
 }
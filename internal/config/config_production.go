@@ -96,6 +96,9 @@ func init() {
 				},
 			},
 		},
+		Http: HttpConfig{
+			Compression: &CompressionConfig{},
+		},
 		TimeoutServicesInit:     5000,
 		TimeoutServerClose:      10000,
 		TimeoutServicesShutdown: 5000,
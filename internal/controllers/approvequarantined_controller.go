@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"errors"
+	ac "go-leaderboard-server/internal/appcontext"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ApproveQuarantinedParams struct {
+	GameId string `json:"gameId" uri:"gameId" binding:"required,max=50,alphanum" example:"game1" extensions:"x-order=0"`                  // Id of game (alphanumeric values)
+	Id     string `json:"id" uri:"id" binding:"required,max=50,alphanum" example:"00010000000003c1f9a2b7d4e6f8a0" extensions:"x-order=1"` // Id of quarantined submission
+}
+
+// @Description Applies a submission held for review to the board as a regular score and removes it from the quarantine
+// @Tags admin
+// @Accept json,application/msgpack,application/x-protobuf
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param data body ApproveQuarantinedParams true "Body data"
+// @Success 200 {object} ResultSuccess "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied, banned user)"
+// @Failure 404 {object} ResultError "Error response (submission not found)"
+// @Failure 413 {object} ResultError "Error response (request body is too large)"
+// @Failure 429 {object} ResultError "Error response (rate limit or quota exceeded, code - quota name, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Router /admin/ApproveQuarantined [post]
+func ApproveQuarantinedHandler(c *gin.Context) {
+	var (
+		params ApproveQuarantinedParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = c.ShouldBindJSON(&params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	if !approveQuarantined(c, params) {
+		return
+	}
+
+	c.JSON(http.StatusOK, &ResultSuccess{Result: "success"})
+}
+
+// @Description Applies a submission held for review to the board as a regular score and removes it from the quarantine
+// @Tags admin
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param gameId path string true "Id of game (alphanumeric values)"
+// @Param id path string true "Id of quarantined submission"
+// @Success 204 "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied, banned user)"
+// @Failure 404 {object} ResultError "Error response (submission not found)"
+// @Failure 429 {object} ResultError "Error response (rate limit or quota exceeded, code - quota name, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Router /v2/games/{gameId}/quarantine/{id}/approve [post]
+func ApproveQuarantinedV2Handler(c *gin.Context) {
+	var (
+		params ApproveQuarantinedParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = bindV2Params(c, &params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	if !approveQuarantined(c, params) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Approves the quarantined submission, aborts the request on failure
+func approveQuarantined(c *gin.Context, params ApproveQuarantinedParams) bool {
+	var (
+		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = checkAccess(c, params.GameId, "")
+	if err != nil {
+		logger.Warn("Access denied", log.LogParams{"gameId": params.GameId, "path": c.FullPath()})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return false
+	}
+
+	params.GameId = getTenantGameId(c, params.GameId)
+
+	item, err := ac.LeaderboardService.ApproveQuarantined(c, params.GameId, params.Id)
+	if errors.Is(err, services.ErrQuarantinedNotFound) {
+		_ = c.AbortWithError(http.StatusNotFound, err)
+		return false
+	}
+	if abortIfQuotaExceeded(c, params.GameId, err) {
+		return false
+	}
+	if errors.Is(err, services.ErrUserBanned) {
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return false
+	}
+	if err != nil {
+		logger.Error("Failed to approve quarantined submission", log.LogParams{"error": err, "gameId": params.GameId, "id": params.Id})
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return false
+	}
+
+	logger.Info("Quarantined submission approved", log.LogParams{"gameId": params.GameId, "id": params.Id})
+
+	audit(c, services.AuditRecord{Action: services.AUDITACTION_APPROVE_QUARANTINED, GameId: params.GameId, UserId: item.UserId, Before: item})
+
+	return true
+}
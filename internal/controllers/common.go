@@ -1,9 +1,242 @@
 package controllers
 
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	ac "go-leaderboard-server/internal/appcontext"
+	dbprovider "go-leaderboard-server/internal/db"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
 type ResultSuccess struct {
 	Result string `json:"result" binding:"required" example:"success"`
 }
 
 type ResultError struct {
 	Error string `json:"error" binding:"required" example:"Some server error"`
+	Code  string `json:"code,omitempty" example:"score_range"` // Machine-readable reason of the error (if any)
+}
+
+// Freshness of the data of a read response
+type cacheInfo struct {
+	LastModified int64 // Time the data was read or last changed (unix ms, 0 - unknown)
+	MaxAge       int64 // Time the response can be reused for (ms)
+	Public       bool  // Response isn't specific to the user and can be stored by shared caches
+}
+
+// Responds to a read with the result and the caching headers: a weak ETag (hash of the result), Cache-Control and Last-Modified.
+// GET requests with a matching If-None-Match header get 304 without the body
+func respondCached(c *gin.Context, result any, info cacheInfo) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	sum := sha256.Sum256(data)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	scope := "private"
+	if info.Public {
+		scope = "public"
+	}
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", fmt.Sprintf("%s, max-age=%d", scope, info.MaxAge/1000))
+	c.Writer.Header().Add("Vary", "Accept, X-Api-Key, Authorization") // responses depend on the encoding, the tenant and the token
+	if info.LastModified > 0 {
+		c.Header("Last-Modified", time.UnixMilli(info.LastModified).UTC().Format(http.TimeFormat))
+	}
+
+	if (c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead) && matchesETag(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Checks whether the If-None-Match header matches the ETag (weak comparison)
+func matchesETag(ifNoneMatch string, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Binds the JSON body (if any), the path and the query of a v2 request to the params and validates them.
+// Params are mapped by the uri tags from the path and by the form tags from the query, the path takes precedence over the query and the body
+func bindV2Params(c *gin.Context, params any) error {
+	if c.Request.Body != nil {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(body)) > 0 {
+			err = json.Unmarshal(body, params)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err := binding.MapFormWithTag(params, c.Request.URL.Query(), "form")
+	if err != nil {
+		return err
+	}
+
+	path := make(map[string][]string)
+	for _, param := range c.Params {
+		path[param.Key] = []string{param.Value}
+	}
+	err = binding.MapFormWithTag(params, path, "uri")
+	if err != nil {
+		return err
+	}
+
+	return binding.Validator.ValidateStruct(params)
+}
+
+// Checks that the API key of the request allows access to the game and, if submitUserId is set,
+// submission of scores of this user (always allowed if authentication is disabled)
+func checkAccess(c *gin.Context, gameId string, submitUserId string) error {
+	value, ok := c.Get("apikey")
+	if !ok {
+		return nil
+	}
+
+	apiKey := value.(*services.ApiKey)
+	if !apiKey.CanAccessGame(gameId) || (submitUserId != "" && !apiKey.CanSubmitFor(submitUserId)) {
+		return services.ErrAccessDenied
+	}
+
+	return nil
+}
+
+// Checks that the API key of the request allows access to all games of its tenant and returns the tenant
+// whose data the request can read (ALL_TENANTS for operator keys)
+func checkTenantAccess(c *gin.Context) (string, error) {
+	err := checkAccess(c, "", "")
+	if err != nil {
+		return "", err
+	}
+
+	if canAccessAllTenants(c) {
+		return dbprovider.ALL_TENANTS, nil
+	}
+	return c.GetString("tenant"), nil
+}
+
+// Checks whether the request can read data of all tenants: requests with operator API keys, or without a key
+// and a tenant if authentication is disabled
+func canAccessAllTenants(c *gin.Context) bool {
+	value, ok := c.Get("apikey")
+	if ok {
+		return value.(*services.ApiKey).Operator
+	}
+	return c.GetString("tenant") == ""
+}
+
+// Checks whether the caller of the request can see entries of the user while it's hidden
+func canSeeHidden(c *gin.Context, userId string) bool {
+	var (
+		apiKey *services.ApiKey
+		claims *services.JwtClaims
+	)
+	if value, ok := c.Get("apikey"); ok {
+		apiKey = value.(*services.ApiKey)
+	}
+	if value, ok := c.Get("jwtclaims"); ok {
+		claims = value.(*services.JwtClaims)
+	}
+	return services.CanSeeHidden(apiKey, claims, userId)
+}
+
+// Checks that the bearer token of the request was issued to the user or has a server or admin scope
+// (always allowed if the request has no token)
+func checkSubject(c *gin.Context, userId string) error {
+	value, ok := c.Get("jwtclaims")
+	if !ok {
+		return nil
+	}
+
+	if !value.(*services.JwtClaims).CanActFor(userId) {
+		return services.ErrAccessDenied
+	}
+
+	return nil
+}
+
+// Returns the id under which the game is stored for the tenant of the request
+func getTenantGameId(c *gin.Context, gameId string) string {
+	return dbprovider.TenantGameId(c.GetString("tenant"), gameId)
+}
+
+// Returns who made the request: the subject of the bearer token, the id of the API key or "anonymous"
+func getActor(c *gin.Context) string {
+	if value, ok := c.Get("jwtclaims"); ok {
+		return "sub:" + value.(*services.JwtClaims).Subject
+	}
+	if value, ok := c.Get("apikey"); ok {
+		return "key:" + value.(*services.ApiKey).Id
+	}
+	return "anonymous"
+}
+
+// Records the operation made by the request to the audit log.
+// The operation is already done, so failures are logged, but don't fail the request
+func audit(c *gin.Context, record services.AuditRecord) {
+	var (
+		appContext ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+		logger                   = log.GetLogger()
+	)
+
+	if !appContext.AuditService.IsEnabled() {
+		return
+	}
+
+	record.Actor = getActor(c)
+	record.Tenant, record.GameId = dbprovider.SplitTenantGameId(record.GameId)
+	record.RequestId = c.GetString("requestid")
+
+	_, err := appContext.AuditService.Record(c, record)
+	if err != nil {
+		logger.Error("Failed to record audit entry", log.LogParams{"error": err, "action": record.Action, "actor": record.Actor,
+			"gameId": record.GameId, "userId": record.UserId, "requestId": record.RequestId})
+	}
+}
+
+// Rejects the request if the write exceeds a quota of the game or its tenant, daily quotas set the Retry-After header.
+// Returns true if the request is aborted
+func abortIfQuotaExceeded(c *gin.Context, gameId string, err error) bool {
+	var (
+		quotaErr *services.QuotaExceededError
+		logger   = log.GetLogger()
+	)
+
+	if !errors.As(err, &quotaErr) {
+		return false
+	}
+
+	logger.Warn("Quota exceeded", log.LogParams{"gameId": gameId, "quota": quotaErr.Quota, "scope": quotaErr.Scope})
+	if quotaErr.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.FormatInt((quotaErr.RetryAfter+999)/1000, 10))
+	}
+	_ = c.AbortWithError(http.StatusTooManyRequests, err)
+
+	return true
 }
@@ -2,29 +2,40 @@ package controllers
 
 import (
 	ac "go-leaderboard-server/internal/appcontext"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
 	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 type DeleteScoreParams struct {
-	GameId string `json:"gameId" binding:"required,max=50,alphanum" example:"game1" extensions:"x-order=0"` // Id of game (alphanumeric values)
-	UserId string `json:"userId" binding:"required,max=50,alphanum" example:"user1" extensions:"x-order=1"` // Id of user (alphanumeric values)
+	GameId string `json:"gameId" uri:"gameId" binding:"required,max=50,alphanum" example:"game1" extensions:"x-order=0"` // Id of game (alphanumeric values)
+	UserId string `json:"userId" uri:"userId" binding:"required,max=50,alphanum" example:"user1" extensions:"x-order=1"` // Id of user (alphanumeric values)
 }
 
-// @Description Removes user data from a database
+// @Description Removes user data from a database (all runs of the user for runs boards)
 // @Tags user
-// @Accept json
-// @Produce json
+// @Accept json,application/msgpack,application/x-protobuf
+// @Produce json,application/msgpack,application/x-protobuf
 // @Param data body DeleteScoreParams true "Body data"
+// @Param Idempotency-Key header string false "Unique key of the request, up to 255 characters (repeated requests return the stored response)"
 // @Success 200 {object} ResultSuccess "Successful response"
 // @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key or token)"
+// @Failure 403 {object} ResultError "Error response (access denied)"
+// @Failure 409 {object} ResultError "Error response (request with the same idempotency key is in progress)"
+// @Failure 413 {object} ResultError "Error response (request body is too large)"
+// @Failure 422 {object} ResultError "Error response (idempotency key reused)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
 // @Failure 500 {object} ResultError "Error response"
-// @Router /leaderboard/DeleteScore [put]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /leaderboard/DeleteScore [post]
 func DeleteScoreHandler(c *gin.Context) {
 	var (
-		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
 		params DeleteScoreParams
 		err    error
 		logger = log.GetLogger()
@@ -37,12 +48,118 @@ func DeleteScoreHandler(c *gin.Context) {
 		return
 	}
 
-	err = ac.LeaderboardService.DeleteUserScore(c, params.GameId, params.UserId)
+	if _, ok := deleteScore(c, params, false); !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, &ResultSuccess{Result: "success"})
+}
+
+// @Description Removes user data from a database (all runs of the user for runs boards)
+// @Tags user
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param gameId path string true "Id of game (alphanumeric values)"
+// @Param userId path string true "Id of user (alphanumeric values)"
+// @Param Idempotency-Key header string false "Unique key of the request, up to 255 characters (repeated requests return the stored response)"
+// @Success 204 "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key or token)"
+// @Failure 403 {object} ResultError "Error response (access denied)"
+// @Failure 404 {object} ResultError "Error response (user has no data)"
+// @Failure 409 {object} ResultError "Error response (request with the same idempotency key is in progress)"
+// @Failure 422 {object} ResultError "Error response (idempotency key reused)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v2/games/{gameId}/users/{userId} [delete]
+func DeleteScoreV2Handler(c *gin.Context) {
+	var (
+		params DeleteScoreParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = bindV2Params(c, &params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	found, ok := deleteScore(c, params, true)
+	if !ok {
+		return
+	}
+	if !found {
+		_ = c.AbortWithError(http.StatusNotFound, services.ErrUserNotFound)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Removes user data, returns whether the user had data (always true if checkFound is not set),
+// aborts the request on failure
+func deleteScore(c *gin.Context, params DeleteScoreParams, checkFound bool) (bool, bool) {
+	var (
+		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = checkAccess(c, params.GameId, "")
+	if err != nil {
+		logger.Warn("Access denied", log.LogParams{"gameId": params.GameId, "path": c.FullPath()})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return false, false
+	}
+
+	err = checkSubject(c, params.UserId)
+	if err != nil {
+		logger.Warn("Access denied", log.LogParams{"gameId": params.GameId, "userId": params.UserId, "path": c.FullPath()})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return false, false
+	}
+
+	params.GameId = getTenantGameId(c, params.GameId)
+
+	isRuns := ac.AppConfig.GetBoardConfig(params.GameId).Type == config.BOARDTYPE_RUNS
+
+	var before any
+	found := true
+	if checkFound || ac.AuditService.IsEnabled() {
+		if isRuns {
+			var runs []dbprovider.RunProperties
+			runs, err = ac.LeaderboardService.GetUserRuns(c, params.GameId, params.UserId)
+			before, found = runs, len(runs) > 0
+		} else {
+			var userProp *dbprovider.UserProperties
+			userProp, err = ac.LeaderboardService.GetUserScore(c, params.GameId, params.UserId)
+			before, found = userProp, userProp != nil
+		}
+		if err != nil {
+			logger.Error("Failed to get user score", log.LogParams{"error": err, "gameId": params.GameId, "userId": params.UserId})
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return false, false
+		}
+	}
+	if checkFound && !found {
+		return false, true
+	}
+
+	if isRuns {
+		err = ac.LeaderboardService.DeleteUserRuns(c, params.GameId, params.UserId)
+	} else {
+		err = ac.LeaderboardService.DeleteUserScore(c, params.GameId, params.UserId)
+	}
 	if err != nil {
 		logger.Error("Failed to delete user score", log.LogParams{"error": err, "gameId": params.GameId, "userId": params.UserId})
 		_ = c.AbortWithError(http.StatusInternalServerError, err)
-		return
+		return false, false
 	}
 
-	c.JSON(http.StatusOK, &ResultSuccess{Result: "success"})
+	audit(c, services.AuditRecord{Action: services.AUDITACTION_DELETE_SCORE, GameId: params.GameId, UserId: params.UserId, Before: before})
+
+	return true, true
 }
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	ac "go-leaderboard-server/internal/appcontext"
+	dbprovider "go-leaderboard-server/internal/db"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetAuditParams struct {
+	FromSeq uint64 `json:"fromSeq" form:"fromSeq" binding:"omitempty" example:"1" extensions:"x-order=0"`            // Sequence number of the first entry (0 - from the beginning)
+	Limit   uint32 `json:"limit" form:"limit" binding:"required,min=1,max=100" example:"100" extensions:"x-order=1"` // Maximum number of entries
+}
+
+type AuditResult struct {
+	Entries []dbprovider.AuditEntry `json:"entries" binding:"required"`              // Entries in ascending order of sequence numbers
+	Valid   bool                    `json:"valid" binding:"required" example:"true"` // Whether the hash chain of the entries (and the link to the preceding entry) is intact
+}
+
+type GetAuditResultSuccess struct {
+	Result AuditResult `json:"result" binding:"required"`
+}
+
+// @Description Returns entries of the audit log of destructive and moderation operations of the tenant of the key and checks their hash chain
+// @Tags admin
+// @Accept json,application/msgpack,application/x-protobuf
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param data body GetAuditParams true "Body data"
+// @Success 200 {object} GetAuditResultSuccess "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied, key limited to some games)"
+// @Failure 404 {object} ResultError "Error response (audit is disabled)"
+// @Failure 413 {object} ResultError "Error response (request body is too large)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Router /admin/GetAudit [post]
+func GetAuditHandler(c *gin.Context) {
+	var (
+		params GetAuditParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = c.ShouldBindJSON(&params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	getAudit(c, params)
+}
+
+// @Description Returns entries of the audit log of destructive and moderation operations of the tenant of the key and checks their hash chain
+// @Tags admin
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param fromSeq query int false "Sequence number of the first entry (0 - from the beginning)"
+// @Param limit query int true "Maximum number of entries (1-100)"
+// @Success 200 {object} GetAuditResultSuccess "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied, key limited to some games)"
+// @Failure 404 {object} ResultError "Error response (audit is disabled)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Router /v2/audit [get]
+func GetAuditV2Handler(c *gin.Context) {
+	var (
+		params GetAuditParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = bindV2Params(c, &params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	getAudit(c, params)
+}
+
+// Responds with entries of the audit log
+func getAudit(c *gin.Context, params GetAuditParams) {
+	var (
+		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+		err    error
+		logger = log.GetLogger()
+	)
+
+	if !ac.AuditService.IsEnabled() {
+		_ = c.AbortWithError(http.StatusNotFound, services.ErrAuditDisabled)
+		return
+	}
+
+	// entries of all games of the tenant are returned
+	tenant, err := checkTenantAccess(c)
+	if err != nil {
+		logger.Warn("Access denied", log.LogParams{"path": c.FullPath()})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return
+	}
+
+	entries, valid, err := ac.AuditService.List(c, tenant, params.FromSeq, params.Limit)
+	if err != nil {
+		logger.Error("Failed to get audit entries", log.LogParams{"error": err, "fromSeq": params.FromSeq})
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if !valid {
+		logger.Error("Audit log hash chain is broken", log.LogParams{"fromSeq": params.FromSeq})
+	}
+
+	c.JSON(http.StatusOK, &GetAuditResultSuccess{Result: AuditResult{Entries: entries, Valid: valid}})
+}
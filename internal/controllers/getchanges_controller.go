@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"errors"
+	ac "go-leaderboard-server/internal/appcontext"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetChangesParams struct {
+	GameId string `json:"gameId" uri:"gameId" binding:"required,max=50,alphanum" example:"game1" extensions:"x-order=0"` // Id of game (alphanumeric values)
+	Since  uint64 `json:"since" form:"since" example:"41" extensions:"x-order=1"`                                        // Version of the board known to the client (0 - from the beginning)
+	Limit  uint32 `json:"limit" form:"limit" binding:"required,min=1,max=1000" example:"100" extensions:"x-order=2"`     // Maximum number of changes
+	Wait   uint32 `json:"wait" form:"wait" example:"30000" extensions:"x-order=3"`                                       // Maximum time to wait for changes if there are none (ms, limited by the server)
+}
+
+type GetChangesResultSuccess struct {
+	Result services.ChangeList `json:"result" binding:"required"`
+}
+
+// @Description Returns changes of the board (puts and deletes of scores and runs) after the known version in the order of versions.
+// @Description If there are no changes, the request waits for them up to the wait time. Pass the returned version as since of the next request.
+// @Description If the changes after the version are not retained anymore, responds with 410 and the current version in the X-Changes-Version header: the client has to reload the board and continue from that version
+// @Tags changes
+// @Accept json,application/msgpack,application/x-protobuf
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param data body GetChangesParams true "Body data"
+// @Success 200 {object} GetChangesResultSuccess "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied)"
+// @Failure 404 {object} ResultError "Error response (change feed is disabled)"
+// @Failure 410 {object} ResultError "Error response (changes are too old, resync is required, see X-Changes-Version header)"
+// @Failure 413 {object} ResultError "Error response (request body is too large)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Router /leaderboard/GetChanges [post]
+func GetChangesHandler(c *gin.Context) {
+	var (
+		params GetChangesParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = c.ShouldBindJSON(&params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	getChanges(c, params)
+}
+
+// @Description Returns changes of the board (puts and deletes of scores and runs) after the known version in the order of versions.
+// @Description If there are no changes, the request waits for them up to the wait time. Pass the returned version as since of the next request.
+// @Description If the changes after the version are not retained anymore, responds with 410 and the current version in the X-Changes-Version header: the client has to reload the board and continue from that version
+// @Tags changes
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param gameId path string true "Id of game (alphanumeric values)"
+// @Param since query int false "Version of the board known to the client (0 - from the beginning)"
+// @Param limit query int true "Maximum number of changes (1-1000)"
+// @Param wait query int false "Maximum time to wait for changes if there are none (ms, limited by the server)"
+// @Success 200 {object} GetChangesResultSuccess "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied)"
+// @Failure 404 {object} ResultError "Error response (change feed is disabled)"
+// @Failure 410 {object} ResultError "Error response (changes are too old, resync is required, see X-Changes-Version header)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Router /v2/games/{gameId}/changes [get]
+func GetChangesV2Handler(c *gin.Context) {
+	var (
+		params GetChangesParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = bindV2Params(c, &params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	getChanges(c, params)
+}
+
+// Responds with changes of the game after the version
+func getChanges(c *gin.Context, params GetChangesParams) {
+	var (
+		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+		err    error
+		logger = log.GetLogger()
+	)
+
+	if !ac.ChangeService.IsEnabled() {
+		_ = c.AbortWithError(http.StatusNotFound, services.ErrChangesDisabled)
+		return
+	}
+
+	err = checkAccess(c, params.GameId, "")
+	if err != nil {
+		logger.Warn("Access denied", log.LogParams{"gameId": params.GameId, "path": c.FullPath()})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return
+	}
+
+	params.GameId = getTenantGameId(c, params.GameId)
+
+	list, err := ac.ChangeService.GetChanges(c, params.GameId, params.Since, params.Limit, time.Duration(params.Wait)*time.Millisecond)
+	if err != nil {
+		var resyncErr *services.ChangesResyncError
+		if errors.As(err, &resyncErr) {
+			logger.Info("Changes resync is required", log.LogParams{"gameId": params.GameId, "since": strconv.FormatUint(params.Since, 10)})
+			c.Header("X-Changes-Version", strconv.FormatUint(resyncErr.Version, 10))
+			_ = c.AbortWithError(http.StatusGone, err)
+			return
+		}
+		logger.Error("Failed to get changes", log.LogParams{"error": err, "gameId": params.GameId})
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &GetChangesResultSuccess{Result: *list})
+}
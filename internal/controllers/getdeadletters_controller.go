@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"errors"
+	ac "go-leaderboard-server/internal/appcontext"
+	deadletterprovider "go-leaderboard-server/internal/deadletter"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetDeadLettersParams struct {
+	Endpoint string `json:"endpoint" uri:"endpoint" binding:"required,max=32,alphanum" example:"rewards" extensions:"x-order=0"` // Id of webhook endpoint
+	Limit    uint32 `json:"limit" form:"limit" binding:"required,min=1,max=100" example:"100" extensions:"x-order=1"`            // Maximum number of dead letters
+}
+
+type GetDeadLettersResultSuccess struct {
+	Result []deadletterprovider.DeadLetter `json:"result" binding:"required"` // Dead letters in ascending order of time
+}
+
+// @Description Returns webhooks of the endpoint and the tenant of the key that failed all delivery attempts
+// @Tags admin
+// @Accept json,application/msgpack,application/x-protobuf
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param data body GetDeadLettersParams true "Body data"
+// @Success 200 {object} GetDeadLettersResultSuccess "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied, key limited to some games)"
+// @Failure 404 {object} ResultError "Error response (webhooks are disabled, endpoint not found)"
+// @Failure 413 {object} ResultError "Error response (request body is too large)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Router /admin/GetDeadLetters [post]
+func GetDeadLettersHandler(c *gin.Context) {
+	var (
+		params GetDeadLettersParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = c.ShouldBindJSON(&params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	getDeadLetters(c, params)
+}
+
+// @Description Returns webhooks of the endpoint and the tenant of the key that failed all delivery attempts
+// @Tags admin
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param endpoint path string true "Id of webhook endpoint"
+// @Param limit query int true "Maximum number of dead letters (1-100)"
+// @Success 200 {object} GetDeadLettersResultSuccess "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied, key limited to some games)"
+// @Failure 404 {object} ResultError "Error response (webhooks are disabled, endpoint not found)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Router /v2/webhooks/{endpoint}/deadletters [get]
+func GetDeadLettersV2Handler(c *gin.Context) {
+	var (
+		params GetDeadLettersParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = bindV2Params(c, &params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	getDeadLetters(c, params)
+}
+
+// Responds with dead letters of the endpoint
+func getDeadLetters(c *gin.Context, params GetDeadLettersParams) {
+	var (
+		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+		err    error
+		logger = log.GetLogger()
+	)
+
+	// endpoints receive events of all games, letters of the tenant of the key are available
+	tenant, err := checkTenantAccess(c)
+	if err != nil {
+		logger.Warn("Access denied", log.LogParams{"path": c.FullPath()})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return
+	}
+
+	letters, err := ac.WebhookService.ListDeadLetters(c, params.Endpoint, tenant, params.Limit)
+	if errors.Is(err, services.ErrWebhooksDisabled) || errors.Is(err, services.ErrWebhookEndpointNotFound) {
+		_ = c.AbortWithError(http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to get dead letters", log.LogParams{"error": err, "endpoint": params.Endpoint})
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &GetDeadLettersResultSuccess{Result: letters})
+}
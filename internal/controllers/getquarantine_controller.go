@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	ac "go-leaderboard-server/internal/appcontext"
+	dbprovider "go-leaderboard-server/internal/db"
+	log "go-leaderboard-server/internal/logger"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetQuarantineParams struct {
+	GameId string `json:"gameId" uri:"gameId" binding:"required,max=50,alphanum" example:"game1" extensions:"x-order=0"` // Id of game (alphanumeric values)
+	Limit  uint32 `json:"limit" form:"limit" binding:"required,min=1,max=100" example:"100" extensions:"x-order=1"`      // Maximum number of submissions
+}
+
+type GetQuarantineResultSuccess struct {
+	Result []dbprovider.QuarantineItem `json:"result" binding:"required"`
+}
+
+// @Description Returns submissions held for review by anti-cheat rules of a specific gameId, the oldest first
+// @Tags admin
+// @Accept json,application/msgpack,application/x-protobuf
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param data body GetQuarantineParams true "Body data"
+// @Success 200 {object} GetQuarantineResultSuccess "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied)"
+// @Failure 413 {object} ResultError "Error response (request body is too large)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Router /admin/GetQuarantine [post]
+func GetQuarantineHandler(c *gin.Context) {
+	var (
+		params GetQuarantineParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = c.ShouldBindJSON(&params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	getQuarantine(c, params)
+}
+
+// @Description Returns submissions held for review by anti-cheat rules of a specific gameId, the oldest first
+// @Tags admin
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param gameId path string true "Id of game (alphanumeric values)"
+// @Param limit query int true "Maximum number of submissions (1-100)"
+// @Success 200 {object} GetQuarantineResultSuccess "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Router /v2/games/{gameId}/quarantine [get]
+func GetQuarantineV2Handler(c *gin.Context) {
+	var (
+		params GetQuarantineParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = bindV2Params(c, &params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	getQuarantine(c, params)
+}
+
+// Responds with quarantined submissions of the game
+func getQuarantine(c *gin.Context, params GetQuarantineParams) {
+	var (
+		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = checkAccess(c, params.GameId, "")
+	if err != nil {
+		logger.Warn("Access denied", log.LogParams{"gameId": params.GameId, "path": c.FullPath()})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return
+	}
+
+	params.GameId = getTenantGameId(c, params.GameId)
+
+	items, err := ac.LeaderboardService.ListQuarantined(c, params.GameId, params.Limit)
+	if err != nil {
+		logger.Error("Failed to get quarantined submissions", log.LogParams{"error": err, "gameId": params.GameId})
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &GetQuarantineResultSuccess{Result: items})
+}
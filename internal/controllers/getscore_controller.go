@@ -2,34 +2,44 @@ package controllers
 
 import (
 	ac "go-leaderboard-server/internal/appcontext"
+	"go-leaderboard-server/internal/config"
 	dbprovider "go-leaderboard-server/internal/db"
 	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 type GetScoreParams struct {
-	GameId string `json:"gameId" binding:"required,max=50,alphanum" example:"game1" extensions:"x-order=0"` // Id of game (alphanumeric values)
-	UserId string `json:"userId" binding:"required,max=50,alphanum" example:"user1" extensions:"x-order=1"` // Id of user (alphanumeric values)
+	GameId string `json:"gameId" uri:"gameId" binding:"required,max=50,alphanum" example:"game1" extensions:"x-order=0"` // Id of game (alphanumeric values)
+	UserId string `json:"userId" uri:"userId" binding:"required,max=50,alphanum" example:"user1" extensions:"x-order=1"` // Id of user (alphanumeric values)
 }
 
 type GetScoreResultSuccess[T any] struct {
 	Result T `json:"result" binding:"required"` // (Empty object if no data)
 }
 
-// @Description Gets user data from a database
+// @Description Gets user data from a database (runs of the user sorted in descending order of score for runs boards)
 // @Tags user
-// @Accept json
-// @Produce json
+// @Accept json,application/msgpack,application/x-protobuf
+// @Produce json,application/msgpack,application/x-protobuf
 // @Param data body GetScoreParams true "Body data"
 // @Success 200 {object} GetScoreResultSuccess[dbprovider.UserProperties] "Successful response"
+// @Header 200 {string} ETag "Weak hash of the result"
+// @Header 200 {string} Cache-Control "private, max-age=0"
+// @Header 200 {string} Last-Modified "Time of the last score submission"
 // @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key or token)"
+// @Failure 403 {object} ResultError "Error response (access denied)"
+// @Failure 413 {object} ResultError "Error response (request body is too large)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
 // @Failure 500 {object} ResultError "Error response"
-// @Router /leaderboard/GetScore [put]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /leaderboard/GetScore [post]
 func GetScoreHandler(c *gin.Context) {
 	var (
-		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
 		params GetScoreParams
 		err    error
 		logger = log.GetLogger()
@@ -42,17 +52,151 @@ func GetScoreHandler(c *gin.Context) {
 		return
 	}
 
+	data, ok := getScore(c, params)
+	if !ok {
+		return
+	}
+
+	switch data := data.(type) {
+	case []dbprovider.RunProperties:
+		respondCached(c, &GetScoreResultSuccess[[]dbprovider.RunProperties]{Result: data}, scoreCacheInfo(data))
+	case *dbprovider.UserProperties:
+		if data == nil {
+			respondCached(c, &GetScoreResultSuccess[struct{}]{}, cacheInfo{})
+			return
+		}
+		respondCached(c, &GetScoreResultSuccess[dbprovider.UserProperties]{Result: *data}, cacheInfo{LastModified: data.Ts})
+	}
+}
+
+// @Description Gets user data from a database (runs of the user sorted in descending order of score for runs boards)
+// @Tags user
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param gameId path string true "Id of game (alphanumeric values)"
+// @Param userId path string true "Id of user (alphanumeric values)"
+// @Param If-None-Match header string false "ETag of a previous response"
+// @Success 200 {object} GetScoreResultSuccess[dbprovider.UserProperties] "Successful response"
+// @Header 200 {string} ETag "Weak hash of the result"
+// @Header 200 {string} Cache-Control "private, max-age=0"
+// @Header 200 {string} Last-Modified "Time of the last score submission"
+// @Success 304 "Not modified (the ETag matches If-None-Match)"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key or token)"
+// @Failure 403 {object} ResultError "Error response (access denied)"
+// @Failure 404 {object} ResultError "Error response (user has no data)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v2/games/{gameId}/users/{userId} [get]
+func GetScoreV2Handler(c *gin.Context) {
+	var (
+		params GetScoreParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = bindV2Params(c, &params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	data, ok := getScore(c, params)
+	if !ok {
+		return
+	}
+
+	switch data := data.(type) {
+	case []dbprovider.RunProperties:
+		if len(data) == 0 {
+			_ = c.AbortWithError(http.StatusNotFound, services.ErrUserNotFound)
+			return
+		}
+		respondCached(c, &GetScoreResultSuccess[[]dbprovider.RunProperties]{Result: data}, scoreCacheInfo(data))
+	case *dbprovider.UserProperties:
+		if data == nil {
+			_ = c.AbortWithError(http.StatusNotFound, services.ErrUserNotFound)
+			return
+		}
+		respondCached(c, &GetScoreResultSuccess[dbprovider.UserProperties]{Result: *data}, cacheInfo{LastModified: data.Ts})
+	}
+}
+
+// Returns the freshness of runs of the user, scores are read from the DB on each request
+func scoreCacheInfo(runs []dbprovider.RunProperties) cacheInfo {
+	var info cacheInfo
+	for _, run := range runs {
+		info.LastModified = max(info.LastModified, run.Ts)
+	}
+	return info
+}
+
+// Returns runs of the user for runs boards or the user data (nil - no data), aborts the request on failure.
+// Entries of hidden users are returned only to the user itself and admins
+func getScore(c *gin.Context, params GetScoreParams) (any, bool) {
+	var (
+		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = checkAccess(c, params.GameId, "")
+	if err != nil {
+		logger.Warn("Access denied", log.LogParams{"gameId": params.GameId, "path": c.FullPath()})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return nil, false
+	}
+
+	err = checkSubject(c, params.UserId)
+	if err != nil {
+		logger.Warn("Access denied", log.LogParams{"gameId": params.GameId, "userId": params.UserId, "path": c.FullPath()})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return nil, false
+	}
+
+	params.GameId = getTenantGameId(c, params.GameId)
+
+	if ac.AppConfig.GetBoardConfig(params.GameId).Type == config.BOARDTYPE_RUNS {
+		runs, err := ac.LeaderboardService.GetUserRuns(c, params.GameId, params.UserId)
+		if err != nil {
+			logger.Error("Failed to get user runs", log.LogParams{"error": err, "gameId": params.GameId, "userId": params.UserId})
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return nil, false
+		}
+
+		if len(runs) > 0 && !isShown(c, params) {
+			return []dbprovider.RunProperties{}, !c.IsAborted()
+		}
+		return runs, true
+	}
+
 	userProp, err := ac.LeaderboardService.GetUserScore(c, params.GameId, params.UserId)
 	if err != nil {
 		logger.Error("Failed to get user score", log.LogParams{"error": err, "gameId": params.GameId, "userId": params.UserId})
 		_ = c.AbortWithError(http.StatusInternalServerError, err)
-		return
+		return nil, false
 	}
 
-	if userProp == nil {
-		c.JSON(http.StatusOK, &GetScoreResultSuccess[struct{}]{})
-		return
+	if userProp != nil && !isShown(c, params) {
+		return (*dbprovider.UserProperties)(nil), !c.IsAborted()
 	}
+	return userProp, true
+}
 
-	c.JSON(http.StatusOK, &GetScoreResultSuccess[dbprovider.UserProperties]{Result: *userProp})
+// Checks whether entries of the user are shown to the caller of the request, aborts the request on failure
+func isShown(c *gin.Context, params GetScoreParams) bool {
+	var (
+		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+		logger               = log.GetLogger()
+	)
+
+	shown, err := ac.LeaderboardService.IsShown(c, params.GameId, params.UserId, canSeeHidden(c, params.UserId))
+	if err != nil {
+		logger.Error("Failed to get user state", log.LogParams{"error": err, "gameId": params.GameId, "userId": params.UserId})
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return false
+	}
+	return shown
 }
@@ -1,35 +1,74 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	ac "go-leaderboard-server/internal/appcontext"
+	"go-leaderboard-server/internal/config"
 	dbprovider "go-leaderboard-server/internal/db"
 	log "go-leaderboard-server/internal/logger"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 type GetTopParams struct {
-	GameId string `json:"gameId" binding:"required,max=50,alphanum" example:"game1" extensions:"x-order=0"` // Id of game (alphanumeric values)
-	NTop   uint32 `json:"nTop" binding:"required,min=1,max=100" example:"100" extensions:"x-order=1"`       // Number of users in top
+	GameId string `json:"gameId" uri:"gameId" binding:"required,max=50,alphanum" example:"game1" extensions:"x-order=0"` // Id of game (alphanumeric values)
+	NTop   uint32 `json:"nTop" form:"n" binding:"required,min=1,max=100" example:"100" extensions:"x-order=1"`           // Number of users in top
+	Fields string `json:"fields" form:"fields" binding:"max=100" example:"userId,score" extensions:"x-order=2"`          // Fields of entries, comma separated (userId, score, name, params, runId, ts; empty - all fields)
+}
+
+var ErrUnknownField = errors.New("unknown field")
+
+// Returns the fields of top entries that are not requested. Only name and params can be omitted, other fields are always returned
+func parseTopFields(fields string) (dbprovider.TopFields, error) {
+	if fields == "" {
+		return 0, nil
+	}
+
+	omitFields := dbprovider.TOPFIELDS_OPTIONAL
+	for _, field := range strings.Split(fields, ",") {
+		switch strings.TrimSpace(field) {
+		case "name":
+			omitFields &^= dbprovider.TOPFIELD_NAME
+		case "params":
+			omitFields &^= dbprovider.TOPFIELD_PARAMS
+		case "userId", "score", "runId", "ts":
+		default:
+			return 0, fmt.Errorf("%w: %q", ErrUnknownField, field)
+		}
+	}
+	return omitFields, nil
 }
 
 type GetTopResultSuccess struct {
 	Result dbprovider.TopData `json:"result" binding:"required"`
 }
 
-// @Description Returns data of users with maximum registered scores sorted in descending order of score, maximum nTop number of elements for a specific gameId
+type GetTopRunsResultSuccess struct {
+	Result dbprovider.RunTopData `json:"result" binding:"required"`
+}
+
+// @Description Returns data of users with maximum registered scores sorted in descending order of score, maximum nTop number of elements for a specific gameId (runs with maximum scores for runs boards, the same user may appear several times)
 // @Tags top
-// @Accept json
-// @Produce json
+// @Accept json,application/msgpack,application/x-protobuf
+// @Produce json,application/msgpack,application/x-protobuf
 // @Param data body GetTopParams true "Body data"
 // @Success 200 {object} GetTopResultSuccess "Successful response"
+// @Header 200 {string} ETag "Weak hash of the result"
+// @Header 200 {string} Cache-Control "public, max-age - remaining lifetime of the cached top (s)"
+// @Header 200 {string} Last-Modified "Time the top was read from the database"
 // @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied)"
+// @Failure 413 {object} ResultError "Error response (request body is too large)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
 // @Failure 500 {object} ResultError "Error response"
-// @Router /leaderboard/GetTop [put]
+// @Security ApiKeyAuth
+// @Router /leaderboard/GetTop [post]
 func GetTopHandler(c *gin.Context) {
 	var (
-		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
 		params GetTopParams
 		err    error
 		logger = log.GetLogger()
@@ -42,12 +81,98 @@ func GetTopHandler(c *gin.Context) {
 		return
 	}
 
-	top, err := ac.LeaderboardService.GetTop(c, params.GameId, params.NTop)
+	getTop(c, params)
+}
+
+// @Description Returns data of users with maximum registered scores sorted in descending order of score (runs with maximum scores for runs boards, the same user may appear several times)
+// @Tags top
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param gameId path string true "Id of game (alphanumeric values)"
+// @Param n query int true "Number of users in top (1-100)"
+// @Param fields query string false "Fields of entries, comma separated (userId, score, name, params, runId, ts; empty - all fields)"
+// @Param If-None-Match header string false "ETag of a previous response"
+// @Success 200 {object} GetTopResultSuccess "Successful response"
+// @Header 200 {string} ETag "Weak hash of the result"
+// @Header 200 {string} Cache-Control "public, max-age - remaining lifetime of the cached top (s)"
+// @Header 200 {string} Last-Modified "Time the top was read from the database"
+// @Success 304 "Not modified (the ETag matches If-None-Match)"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Router /v2/games/{gameId}/top [get]
+func GetTopV2Handler(c *gin.Context) {
+	var (
+		params GetTopParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = bindV2Params(c, &params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	getTop(c, params)
+}
+
+// Responds with the top of the game
+func getTop(c *gin.Context, params GetTopParams) {
+	var (
+		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = checkAccess(c, params.GameId, "")
+	if err != nil {
+		logger.Warn("Access denied", log.LogParams{"gameId": params.GameId, "path": c.FullPath()})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return
+	}
+
+	omitFields, err := parseTopFields(params.Fields)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	params.GameId = getTenantGameId(c, params.GameId)
+
+	if ac.AppConfig.GetBoardConfig(params.GameId).Type == config.BOARDTYPE_RUNS {
+		top, err := ac.LeaderboardService.GetTopRuns(c, params.GameId, params.NTop)
+		if err != nil {
+			logger.Error("Failed to get top runs", log.LogParams{"error": err, "gameId": params.GameId, "nTop": params.NTop})
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		// tops of runs boards are read from the DB on each request, omitted fields are dropped from the response
+		var lastModified int64
+		for i, run := range top {
+			lastModified = max(lastModified, run.Ts)
+			if omitFields&dbprovider.TOPFIELD_NAME != 0 {
+				top[i].Name = ""
+			}
+			if omitFields&dbprovider.TOPFIELD_PARAMS != 0 {
+				top[i].Params = ""
+			}
+		}
+		respondCached(c, &GetTopRunsResultSuccess{Result: top}, cacheInfo{LastModified: lastModified, Public: true})
+		return
+	}
+
+	top, err := ac.LeaderboardService.GetCachedTop(c, params.GameId, params.NTop, omitFields)
 	if err != nil {
 		logger.Error("Failed to get top", log.LogParams{"error": err, "gameId": params.GameId, "nTop": params.NTop})
 		_ = c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, &GetTopResultSuccess{Result: top})
+	respondCached(c, &GetTopResultSuccess{Result: top.Data}, cacheInfo{LastModified: top.Ts, MaxAge: top.MaxAge, Public: true})
 }
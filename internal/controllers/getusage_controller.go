@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	ac "go-leaderboard-server/internal/appcontext"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetUsageParams struct {
+	Tenant string `json:"tenant" form:"tenant" binding:"omitempty,max=32,alphanum" example:"studio1" extensions:"x-order=0"` // Id of tenant (empty - tenant of the api key, other tenants are available to operator keys only)
+}
+
+type QuotaUsageResult struct {
+	Writes       int64  `json:"writes" binding:"required" example:"120"`        // Score submissions made today (UTC)
+	Entries      uint64 `json:"entries" binding:"required" example:"35"`        // Stored entries (runs for runs boards)
+	WritesPerDay uint32 `json:"writesPerDay" binding:"required" example:"1000"` // Quota of writes per day (0 - unlimited)
+	MaxEntries   uint32 `json:"maxEntries" binding:"required" example:"500"`    // Quota of stored entries (0 - unlimited)
+}
+
+type GameUsageResult struct {
+	GameId string `json:"gameId" binding:"required" example:"game1"` // Id of game
+	QuotaUsageResult
+}
+
+type UsageResult struct {
+	Tenant string `json:"tenant" example:"studio1"` // Id of tenant (empty - default tenant)
+	QuotaUsageResult
+	Games []GameUsageResult `json:"games" binding:"required"` // Usage of games of the tenant sorted by gameId
+}
+
+type GetUsageResultSuccess struct {
+	Result UsageResult `json:"result" binding:"required"`
+}
+
+// @Description Returns today's writes and stored entries of a tenant and its games along with their quotas
+// @Tags admin
+// @Accept json,application/msgpack,application/x-protobuf
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param data body GetUsageParams true "Body data"
+// @Success 200 {object} GetUsageResultSuccess "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied, key limited to some games or of another tenant)"
+// @Failure 404 {object} ResultError "Error response (quotas are disabled)"
+// @Failure 413 {object} ResultError "Error response (request body is too large)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Router /admin/GetUsage [post]
+func GetUsageHandler(c *gin.Context) {
+	var (
+		params GetUsageParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = c.ShouldBindJSON(&params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	getUsage(c, params)
+}
+
+// @Description Returns today's writes and stored entries of a tenant and its games along with their quotas
+// @Tags admin
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param tenant query string false "Id of tenant (empty - tenant of the api key, other tenants are available to operator keys only)"
+// @Success 200 {object} GetUsageResultSuccess "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied, key limited to some games or of another tenant)"
+// @Failure 404 {object} ResultError "Error response (quotas are disabled)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Router /v2/usage [get]
+func GetUsageV2Handler(c *gin.Context) {
+	var (
+		params GetUsageParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = bindV2Params(c, &params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	getUsage(c, params)
+}
+
+// Responds with usage of the tenant
+func getUsage(c *gin.Context, params GetUsageParams) {
+	var (
+		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+		err    error
+		logger = log.GetLogger()
+	)
+
+	if !ac.QuotaService.IsEnabled() {
+		_ = c.AbortWithError(http.StatusNotFound, services.ErrQuotaDisabled)
+		return
+	}
+
+	// usage of all games of the tenant is returned, so keys limited to some games are denied
+	err = checkAccess(c, "", "")
+	tenant := c.GetString("tenant")
+	if params.Tenant != "" && params.Tenant != tenant {
+		if err == nil && !canAccessAllTenants(c) {
+			err = services.ErrAccessDenied
+		}
+		tenant = params.Tenant
+	}
+	if err != nil {
+		logger.Warn("Access denied", log.LogParams{"tenant": params.Tenant, "path": c.FullPath()})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return
+	}
+
+	usage, err := ac.QuotaService.GetUsage(c, tenant)
+	if err != nil {
+		logger.Error("Failed to get usage", log.LogParams{"error": err, "tenant": tenant})
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	result := UsageResult{
+		Tenant:           usage.Tenant,
+		QuotaUsageResult: QuotaUsageResult(usage.QuotaUsage),
+		Games:            make([]GameUsageResult, 0, len(usage.Games)),
+	}
+	for _, game := range usage.Games {
+		result.Games = append(result.Games, GameUsageResult{GameId: game.GameId, QuotaUsageResult: QuotaUsageResult(game.QuotaUsage)})
+	}
+
+	c.JSON(http.StatusOK, &GetUsageResultSuccess{Result: result})
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	ac "go-leaderboard-server/internal/appcontext"
+	log "go-leaderboard-server/internal/logger"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetUserStateParams struct {
+	GameId string `json:"gameId" uri:"gameId" binding:"required,max=50,alphanum" example:"game1" extensions:"x-order=0"` // Id of game (alphanumeric values)
+	UserId string `json:"userId" uri:"userId" binding:"required,max=50,alphanum" example:"user1" extensions:"x-order=1"` // Id of user (alphanumeric values)
+}
+
+type UserStateResult struct {
+	State string `json:"state" binding:"required" example:"visible"` // Visibility state (visible, shadowbanned, banned)
+}
+
+type GetUserStateResultSuccess struct {
+	Result UserStateResult `json:"result" binding:"required"`
+}
+
+// @Description Gets visibility state of user
+// @Tags admin
+// @Accept json,application/msgpack,application/x-protobuf
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param data body GetUserStateParams true "Body data"
+// @Success 200 {object} GetUserStateResultSuccess "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied)"
+// @Failure 413 {object} ResultError "Error response (request body is too large)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Router /admin/GetUserState [post]
+func GetUserStateHandler(c *gin.Context) {
+	var (
+		params GetUserStateParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = c.ShouldBindJSON(&params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	getUserState(c, params)
+}
+
+// @Description Gets visibility state of user
+// @Tags admin
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param gameId path string true "Id of game (alphanumeric values)"
+// @Param userId path string true "Id of user (alphanumeric values)"
+// @Success 200 {object} GetUserStateResultSuccess "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Router /v2/games/{gameId}/users/{userId}/state [get]
+func GetUserStateV2Handler(c *gin.Context) {
+	var (
+		params GetUserStateParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = bindV2Params(c, &params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	getUserState(c, params)
+}
+
+// Responds with the state of the user
+func getUserState(c *gin.Context, params GetUserStateParams) {
+	var (
+		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = checkAccess(c, params.GameId, "")
+	if err != nil {
+		logger.Warn("Access denied", log.LogParams{"gameId": params.GameId, "path": c.FullPath()})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return
+	}
+
+	params.GameId = getTenantGameId(c, params.GameId)
+
+	state, err := ac.LeaderboardService.GetUserState(c, params.GameId, params.UserId)
+	if err != nil {
+		logger.Error("Failed to get user state", log.LogParams{"error": err, "gameId": params.GameId, "userId": params.UserId})
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &GetUserStateResultSuccess{Result: UserStateResult{State: userStateName(state)}})
+}
@@ -0,0 +1,3394 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: internal/controllers/pb/dto.proto
+
+package dtopb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ResultSuccess struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Result string `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+}
+
+func (x *ResultSuccess) Reset() {
+	*x = ResultSuccess{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResultSuccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultSuccess) ProtoMessage() {}
+
+func (x *ResultSuccess) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultSuccess.ProtoReflect.Descriptor instead.
+func (*ResultSuccess) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ResultSuccess) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+type ResultError struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Error string `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	Code  string `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"` // Machine-readable reason of the error (if any)
+}
+
+func (x *ResultError) Reset() {
+	*x = ResultError{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResultError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultError) ProtoMessage() {}
+
+func (x *ResultError) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultError.ProtoReflect.Descriptor instead.
+func (*ResultError) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ResultError) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ResultError) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type SendScoreParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId   string  `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"` // Id of game (alphanumeric values)
+	UserId   string  `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // Id of user (alphanumeric values)
+	Score    float64 `protobuf:"fixed64,3,opt,name=score,proto3" json:"score,omitempty"`               // User score
+	Name     string  `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`                   // User name
+	Params   string  `protobuf:"bytes,5,opt,name=params,proto3" json:"params,omitempty"`               // Additional payload
+	RunId    string  `protobuf:"bytes,6,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`    // Id of run (runs boards only, generated if empty)
+	Duration uint32  `protobuf:"varint,7,opt,name=duration,proto3" json:"duration,omitempty"`          // Match duration (ms), checked by anti-cheat rules of the board
+}
+
+func (x *SendScoreParams) Reset() {
+	*x = SendScoreParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SendScoreParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendScoreParams) ProtoMessage() {}
+
+func (x *SendScoreParams) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendScoreParams.ProtoReflect.Descriptor instead.
+func (*SendScoreParams) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SendScoreParams) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *SendScoreParams) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SendScoreParams) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *SendScoreParams) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SendScoreParams) GetParams() string {
+	if x != nil {
+		return x.Params
+	}
+	return ""
+}
+
+func (x *SendScoreParams) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *SendScoreParams) GetDuration() uint32 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+type DeleteScoreParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId string `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"` // Id of game (alphanumeric values)
+	UserId string `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // Id of user (alphanumeric values)
+}
+
+func (x *DeleteScoreParams) Reset() {
+	*x = DeleteScoreParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteScoreParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteScoreParams) ProtoMessage() {}
+
+func (x *DeleteScoreParams) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteScoreParams.ProtoReflect.Descriptor instead.
+func (*DeleteScoreParams) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DeleteScoreParams) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *DeleteScoreParams) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type GetScoreParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId string `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"` // Id of game (alphanumeric values)
+	UserId string `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // Id of user (alphanumeric values)
+}
+
+func (x *GetScoreParams) Reset() {
+	*x = GetScoreParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetScoreParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetScoreParams) ProtoMessage() {}
+
+func (x *GetScoreParams) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetScoreParams.ProtoReflect.Descriptor instead.
+func (*GetScoreParams) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetScoreParams) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *GetScoreParams) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type UserProperties struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Score  float64 `protobuf:"fixed64,1,opt,name=score,proto3" json:"score,omitempty"`
+	Name   string  `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Params string  `protobuf:"bytes,3,opt,name=params,proto3" json:"params,omitempty"`
+}
+
+func (x *UserProperties) Reset() {
+	*x = UserProperties{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UserProperties) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserProperties) ProtoMessage() {}
+
+func (x *UserProperties) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserProperties.ProtoReflect.Descriptor instead.
+func (*UserProperties) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UserProperties) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *UserProperties) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UserProperties) GetParams() string {
+	if x != nil {
+		return x.Params
+	}
+	return ""
+}
+
+type RunProperties struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RunId  string  `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	Score  float64 `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	Name   string  `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Params string  `protobuf:"bytes,4,opt,name=params,proto3" json:"params,omitempty"`
+	Ts     int64   `protobuf:"varint,5,opt,name=ts,proto3" json:"ts,omitempty"` // Time of the run submission (unix ms)
+}
+
+func (x *RunProperties) Reset() {
+	*x = RunProperties{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RunProperties) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunProperties) ProtoMessage() {}
+
+func (x *RunProperties) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunProperties.ProtoReflect.Descriptor instead.
+func (*RunProperties) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RunProperties) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *RunProperties) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *RunProperties) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RunProperties) GetParams() string {
+	if x != nil {
+		return x.Params
+	}
+	return ""
+}
+
+func (x *RunProperties) GetTs() int64 {
+	if x != nil {
+		return x.Ts
+	}
+	return 0
+}
+
+type GetScoreResultSuccess struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Result *UserProperties `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"` // (Empty if no data)
+}
+
+func (x *GetScoreResultSuccess) Reset() {
+	*x = GetScoreResultSuccess{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetScoreResultSuccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetScoreResultSuccess) ProtoMessage() {}
+
+func (x *GetScoreResultSuccess) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetScoreResultSuccess.ProtoReflect.Descriptor instead.
+func (*GetScoreResultSuccess) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetScoreResultSuccess) GetResult() *UserProperties {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type GetScoreRunsResultSuccess struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Result []*RunProperties `protobuf:"bytes,1,rep,name=result,proto3" json:"result,omitempty"` // Runs of the user sorted in descending order of score
+}
+
+func (x *GetScoreRunsResultSuccess) Reset() {
+	*x = GetScoreRunsResultSuccess{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetScoreRunsResultSuccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetScoreRunsResultSuccess) ProtoMessage() {}
+
+func (x *GetScoreRunsResultSuccess) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetScoreRunsResultSuccess.ProtoReflect.Descriptor instead.
+func (*GetScoreRunsResultSuccess) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetScoreRunsResultSuccess) GetResult() []*RunProperties {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type GetTopParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId string `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"` // Id of game (alphanumeric values)
+	NTop   uint32 `protobuf:"varint,2,opt,name=n_top,json=nTop,proto3" json:"n_top,omitempty"`      // Number of users in top
+	Fields string `protobuf:"bytes,3,opt,name=fields,proto3" json:"fields,omitempty"`               // Fields of entries, comma separated (empty - all fields)
+}
+
+func (x *GetTopParams) Reset() {
+	*x = GetTopParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetTopParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTopParams) ProtoMessage() {}
+
+func (x *GetTopParams) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTopParams.ProtoReflect.Descriptor instead.
+func (*GetTopParams) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetTopParams) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *GetTopParams) GetNTop() uint32 {
+	if x != nil {
+		return x.NTop
+	}
+	return 0
+}
+
+func (x *GetTopParams) GetFields() string {
+	if x != nil {
+		return x.Fields
+	}
+	return ""
+}
+
+type SubscribeTopParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId string `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"` // Id of game (alphanumeric values)
+	NTop   uint32 `protobuf:"varint,2,opt,name=n_top,json=nTop,proto3" json:"n_top,omitempty"`      // Number of users in top
+	Mode   string `protobuf:"bytes,3,opt,name=mode,proto3" json:"mode,omitempty"`                   // Updates after the first snapshot: snapshot (default) or diff
+}
+
+func (x *SubscribeTopParams) Reset() {
+	*x = SubscribeTopParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscribeTopParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeTopParams) ProtoMessage() {}
+
+func (x *SubscribeTopParams) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeTopParams.ProtoReflect.Descriptor instead.
+func (*SubscribeTopParams) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SubscribeTopParams) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *SubscribeTopParams) GetNTop() uint32 {
+	if x != nil {
+		return x.NTop
+	}
+	return 0
+}
+
+func (x *SubscribeTopParams) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+type TopEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UserId string  `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Score  float64 `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	Name   string  `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Params string  `protobuf:"bytes,4,opt,name=params,proto3" json:"params,omitempty"`
+	RunId  string  `protobuf:"bytes,5,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"` // Id of run (runs boards only)
+	Ts     int64   `protobuf:"varint,6,opt,name=ts,proto3" json:"ts,omitempty"`                   // Time of the run submission (runs boards only, unix ms)
+}
+
+func (x *TopEntry) Reset() {
+	*x = TopEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TopEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopEntry) ProtoMessage() {}
+
+func (x *TopEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopEntry.ProtoReflect.Descriptor instead.
+func (*TopEntry) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TopEntry) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *TopEntry) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *TopEntry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TopEntry) GetParams() string {
+	if x != nil {
+		return x.Params
+	}
+	return ""
+}
+
+func (x *TopEntry) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *TopEntry) GetTs() int64 {
+	if x != nil {
+		return x.Ts
+	}
+	return 0
+}
+
+type GetTopResultSuccess struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Result []*TopEntry `protobuf:"bytes,1,rep,name=result,proto3" json:"result,omitempty"`
+}
+
+func (x *GetTopResultSuccess) Reset() {
+	*x = GetTopResultSuccess{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetTopResultSuccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTopResultSuccess) ProtoMessage() {}
+
+func (x *GetTopResultSuccess) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTopResultSuccess.ProtoReflect.Descriptor instead.
+func (*GetTopResultSuccess) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetTopResultSuccess) GetResult() []*TopEntry {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type GetChangesParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId string `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"` // Id of game (alphanumeric values)
+	Since  uint64 `protobuf:"varint,2,opt,name=since,proto3" json:"since,omitempty"`                // Version of the board known to the client (0 - from the beginning)
+	Limit  uint32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`                // Maximum number of changes
+	Wait   uint32 `protobuf:"varint,4,opt,name=wait,proto3" json:"wait,omitempty"`                  // Maximum time to wait for changes if there are none (ms)
+}
+
+func (x *GetChangesParams) Reset() {
+	*x = GetChangesParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetChangesParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChangesParams) ProtoMessage() {}
+
+func (x *GetChangesParams) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetChangesParams.ProtoReflect.Descriptor instead.
+func (*GetChangesParams) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetChangesParams) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *GetChangesParams) GetSince() uint64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *GetChangesParams) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetChangesParams) GetWait() uint32 {
+	if x != nil {
+		return x.Wait
+	}
+	return 0
+}
+
+type ChangeEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Version uint64  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"` // Version of the board after the change
+	Op      string  `protobuf:"bytes,2,opt,name=op,proto3" json:"op,omitempty"`            // put or delete
+	UserId  string  `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	RunId   string  `protobuf:"bytes,4,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"` // Id of the stored run (runs boards only)
+	Score   float64 `protobuf:"fixed64,5,opt,name=score,proto3" json:"score,omitempty"`            // Stored score (0 for deletes)
+	Name    string  `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
+	Params  string  `protobuf:"bytes,7,opt,name=params,proto3" json:"params,omitempty"`
+	Ts      int64   `protobuf:"varint,8,opt,name=ts,proto3" json:"ts,omitempty"` // Time of the change (unix ms)
+}
+
+func (x *ChangeEntry) Reset() {
+	*x = ChangeEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChangeEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeEntry) ProtoMessage() {}
+
+func (x *ChangeEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeEntry.ProtoReflect.Descriptor instead.
+func (*ChangeEntry) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ChangeEntry) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ChangeEntry) GetOp() string {
+	if x != nil {
+		return x.Op
+	}
+	return ""
+}
+
+func (x *ChangeEntry) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ChangeEntry) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *ChangeEntry) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *ChangeEntry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ChangeEntry) GetParams() string {
+	if x != nil {
+		return x.Params
+	}
+	return ""
+}
+
+func (x *ChangeEntry) GetTs() int64 {
+	if x != nil {
+		return x.Ts
+	}
+	return 0
+}
+
+type ChangeList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Version uint64         `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"` // Version of the last returned change
+	More    bool           `protobuf:"varint,2,opt,name=more,proto3" json:"more,omitempty"`       // More changes are available right away
+	Changes []*ChangeEntry `protobuf:"bytes,3,rep,name=changes,proto3" json:"changes,omitempty"`
+}
+
+func (x *ChangeList) Reset() {
+	*x = ChangeList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChangeList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeList) ProtoMessage() {}
+
+func (x *ChangeList) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeList.ProtoReflect.Descriptor instead.
+func (*ChangeList) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ChangeList) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ChangeList) GetMore() bool {
+	if x != nil {
+		return x.More
+	}
+	return false
+}
+
+func (x *ChangeList) GetChanges() []*ChangeEntry {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type GetChangesResultSuccess struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Result *ChangeList `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+}
+
+func (x *GetChangesResultSuccess) Reset() {
+	*x = GetChangesResultSuccess{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetChangesResultSuccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChangesResultSuccess) ProtoMessage() {}
+
+func (x *GetChangesResultSuccess) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetChangesResultSuccess.ProtoReflect.Descriptor instead.
+func (*GetChangesResultSuccess) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetChangesResultSuccess) GetResult() *ChangeList {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type SetUserStateParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId string `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"` // Id of game (alphanumeric values)
+	UserId string `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // Id of user (alphanumeric values)
+	State  string `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`                 // Visibility state (visible, shadowbanned, banned)
+}
+
+func (x *SetUserStateParams) Reset() {
+	*x = SetUserStateParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetUserStateParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetUserStateParams) ProtoMessage() {}
+
+func (x *SetUserStateParams) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetUserStateParams.ProtoReflect.Descriptor instead.
+func (*SetUserStateParams) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SetUserStateParams) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *SetUserStateParams) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SetUserStateParams) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+type GetUserStateParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId string `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"` // Id of game (alphanumeric values)
+	UserId string `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // Id of user (alphanumeric values)
+}
+
+func (x *GetUserStateParams) Reset() {
+	*x = GetUserStateParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetUserStateParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserStateParams) ProtoMessage() {}
+
+func (x *GetUserStateParams) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserStateParams.ProtoReflect.Descriptor instead.
+func (*GetUserStateParams) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetUserStateParams) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *GetUserStateParams) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type UserStateResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	State string `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"` // Visibility state (visible, shadowbanned, banned)
+}
+
+func (x *UserStateResult) Reset() {
+	*x = UserStateResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UserStateResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserStateResult) ProtoMessage() {}
+
+func (x *UserStateResult) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserStateResult.ProtoReflect.Descriptor instead.
+func (*UserStateResult) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *UserStateResult) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+type GetUserStateResultSuccess struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Result *UserStateResult `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+}
+
+func (x *GetUserStateResultSuccess) Reset() {
+	*x = GetUserStateResultSuccess{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetUserStateResultSuccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserStateResultSuccess) ProtoMessage() {}
+
+func (x *GetUserStateResultSuccess) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserStateResultSuccess.ProtoReflect.Descriptor instead.
+func (*GetUserStateResultSuccess) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetUserStateResultSuccess) GetResult() *UserStateResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type GetQuarantineParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId string `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"` // Id of game (alphanumeric values)
+	Limit  uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`                // Maximum number of submissions
+}
+
+func (x *GetQuarantineParams) Reset() {
+	*x = GetQuarantineParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetQuarantineParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuarantineParams) ProtoMessage() {}
+
+func (x *GetQuarantineParams) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuarantineParams.ProtoReflect.Descriptor instead.
+func (*GetQuarantineParams) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetQuarantineParams) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *GetQuarantineParams) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type QuarantineItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id       string  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // Id of the item
+	UserId   string  `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Rule     string  `protobuf:"bytes,3,opt,name=rule,proto3" json:"rule,omitempty"`     // Name of the violated rule
+	Reason   string  `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"` // Reason of the violation
+	Score    float64 `protobuf:"fixed64,5,opt,name=score,proto3" json:"score,omitempty"`
+	Name     string  `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
+	Params   string  `protobuf:"bytes,7,opt,name=params,proto3" json:"params,omitempty"`
+	RunId    string  `protobuf:"bytes,8,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"` // Id of run (runs boards only)
+	Duration uint32  `protobuf:"varint,9,opt,name=duration,proto3" json:"duration,omitempty"`       // Submitted match duration (ms)
+	Ts       int64   `protobuf:"varint,10,opt,name=ts,proto3" json:"ts,omitempty"`                  // Time of the submission (unix ms)
+}
+
+func (x *QuarantineItem) Reset() {
+	*x = QuarantineItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QuarantineItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuarantineItem) ProtoMessage() {}
+
+func (x *QuarantineItem) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuarantineItem.ProtoReflect.Descriptor instead.
+func (*QuarantineItem) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *QuarantineItem) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *QuarantineItem) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *QuarantineItem) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *QuarantineItem) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *QuarantineItem) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *QuarantineItem) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *QuarantineItem) GetParams() string {
+	if x != nil {
+		return x.Params
+	}
+	return ""
+}
+
+func (x *QuarantineItem) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *QuarantineItem) GetDuration() uint32 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *QuarantineItem) GetTs() int64 {
+	if x != nil {
+		return x.Ts
+	}
+	return 0
+}
+
+type GetQuarantineResultSuccess struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Result []*QuarantineItem `protobuf:"bytes,1,rep,name=result,proto3" json:"result,omitempty"`
+}
+
+func (x *GetQuarantineResultSuccess) Reset() {
+	*x = GetQuarantineResultSuccess{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetQuarantineResultSuccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuarantineResultSuccess) ProtoMessage() {}
+
+func (x *GetQuarantineResultSuccess) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuarantineResultSuccess.ProtoReflect.Descriptor instead.
+func (*GetQuarantineResultSuccess) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetQuarantineResultSuccess) GetResult() []*QuarantineItem {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type ApproveQuarantinedParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId string `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"` // Id of game (alphanumeric values)
+	Id     string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`                       // Id of quarantined submission
+}
+
+func (x *ApproveQuarantinedParams) Reset() {
+	*x = ApproveQuarantinedParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApproveQuarantinedParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveQuarantinedParams) ProtoMessage() {}
+
+func (x *ApproveQuarantinedParams) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveQuarantinedParams.ProtoReflect.Descriptor instead.
+func (*ApproveQuarantinedParams) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ApproveQuarantinedParams) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *ApproveQuarantinedParams) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RejectQuarantinedParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId string `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"` // Id of game (alphanumeric values)
+	Id     string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`                       // Id of quarantined submission
+}
+
+func (x *RejectQuarantinedParams) Reset() {
+	*x = RejectQuarantinedParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RejectQuarantinedParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RejectQuarantinedParams) ProtoMessage() {}
+
+func (x *RejectQuarantinedParams) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RejectQuarantinedParams.ProtoReflect.Descriptor instead.
+func (*RejectQuarantinedParams) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *RejectQuarantinedParams) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *RejectQuarantinedParams) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetAuditParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FromSeq uint64 `protobuf:"varint,1,opt,name=from_seq,json=fromSeq,proto3" json:"from_seq,omitempty"` // Sequence number of the first entry (0 - from the beginning)
+	Limit   uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`                    // Maximum number of entries
+}
+
+func (x *GetAuditParams) Reset() {
+	*x = GetAuditParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetAuditParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAuditParams) ProtoMessage() {}
+
+func (x *GetAuditParams) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAuditParams.ProtoReflect.Descriptor instead.
+func (*GetAuditParams) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *GetAuditParams) GetFromSeq() uint64 {
+	if x != nil {
+		return x.FromSeq
+	}
+	return 0
+}
+
+func (x *GetAuditParams) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type AuditEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Seq       uint64 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`    // Sequence number of the entry
+	Ts        int64  `protobuf:"varint,2,opt,name=ts,proto3" json:"ts,omitempty"`      // Time of the operation (unix ms)
+	Actor     string `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"` // API key id or token subject
+	Action    string `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"`
+	GameId    string `protobuf:"bytes,5,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	UserId    string `protobuf:"bytes,6,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Before    string `protobuf:"bytes,7,opt,name=before,proto3" json:"before,omitempty"` // Affected data before the operation (JSON)
+	After     string `protobuf:"bytes,8,opt,name=after,proto3" json:"after,omitempty"`   // Affected data after the operation (JSON)
+	RequestId string `protobuf:"bytes,9,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	PrevHash  string `protobuf:"bytes,10,opt,name=prev_hash,json=prevHash,proto3" json:"prev_hash,omitempty"` // Hash of the previous entry
+	Hash      string `protobuf:"bytes,11,opt,name=hash,proto3" json:"hash,omitempty"`
+	Tenant    string `protobuf:"bytes,12,opt,name=tenant,proto3" json:"tenant,omitempty"` // Id of tenant of the game (empty - default tenant)
+}
+
+func (x *AuditEntry) Reset() {
+	*x = AuditEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AuditEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEntry) ProtoMessage() {}
+
+func (x *AuditEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEntry.ProtoReflect.Descriptor instead.
+func (*AuditEntry) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *AuditEntry) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *AuditEntry) GetTs() int64 {
+	if x != nil {
+		return x.Ts
+	}
+	return 0
+}
+
+func (x *AuditEntry) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetBefore() string {
+	if x != nil {
+		return x.Before
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetAfter() string {
+	if x != nil {
+		return x.After
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetPrevHash() string {
+	if x != nil {
+		return x.PrevHash
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+type AuditResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Entries []*AuditEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	Valid   bool          `protobuf:"varint,2,opt,name=valid,proto3" json:"valid,omitempty"` // Whether the hash chain of the entries is intact
+}
+
+func (x *AuditResult) Reset() {
+	*x = AuditResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AuditResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditResult) ProtoMessage() {}
+
+func (x *AuditResult) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditResult.ProtoReflect.Descriptor instead.
+func (*AuditResult) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *AuditResult) GetEntries() []*AuditEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *AuditResult) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+type GetAuditResultSuccess struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Result *AuditResult `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+}
+
+func (x *GetAuditResultSuccess) Reset() {
+	*x = GetAuditResultSuccess{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetAuditResultSuccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAuditResultSuccess) ProtoMessage() {}
+
+func (x *GetAuditResultSuccess) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAuditResultSuccess.ProtoReflect.Descriptor instead.
+func (*GetAuditResultSuccess) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetAuditResultSuccess) GetResult() *AuditResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type GetUsageParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tenant string `protobuf:"bytes,1,opt,name=tenant,proto3" json:"tenant,omitempty"` // Id of tenant (empty - tenant of the api key)
+}
+
+func (x *GetUsageParams) Reset() {
+	*x = GetUsageParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetUsageParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageParams) ProtoMessage() {}
+
+func (x *GetUsageParams) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsageParams.ProtoReflect.Descriptor instead.
+func (*GetUsageParams) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetUsageParams) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+type GameUsageResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GameId       string `protobuf:"bytes,1,opt,name=game_id,json=gameId,proto3" json:"game_id,omitempty"`
+	Writes       int64  `protobuf:"varint,2,opt,name=writes,proto3" json:"writes,omitempty"`                                   // Score submissions made today (UTC)
+	Entries      uint64 `protobuf:"varint,3,opt,name=entries,proto3" json:"entries,omitempty"`                                 // Stored entries
+	WritesPerDay uint32 `protobuf:"varint,4,opt,name=writes_per_day,json=writesPerDay,proto3" json:"writes_per_day,omitempty"` // Quota of writes per day (0 - unlimited)
+	MaxEntries   uint32 `protobuf:"varint,5,opt,name=max_entries,json=maxEntries,proto3" json:"max_entries,omitempty"`         // Quota of stored entries (0 - unlimited)
+}
+
+func (x *GameUsageResult) Reset() {
+	*x = GameUsageResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GameUsageResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GameUsageResult) ProtoMessage() {}
+
+func (x *GameUsageResult) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GameUsageResult.ProtoReflect.Descriptor instead.
+func (*GameUsageResult) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *GameUsageResult) GetGameId() string {
+	if x != nil {
+		return x.GameId
+	}
+	return ""
+}
+
+func (x *GameUsageResult) GetWrites() int64 {
+	if x != nil {
+		return x.Writes
+	}
+	return 0
+}
+
+func (x *GameUsageResult) GetEntries() uint64 {
+	if x != nil {
+		return x.Entries
+	}
+	return 0
+}
+
+func (x *GameUsageResult) GetWritesPerDay() uint32 {
+	if x != nil {
+		return x.WritesPerDay
+	}
+	return 0
+}
+
+func (x *GameUsageResult) GetMaxEntries() uint32 {
+	if x != nil {
+		return x.MaxEntries
+	}
+	return 0
+}
+
+type UsageResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tenant       string             `protobuf:"bytes,1,opt,name=tenant,proto3" json:"tenant,omitempty"` // Id of tenant (empty - default tenant)
+	Writes       int64              `protobuf:"varint,2,opt,name=writes,proto3" json:"writes,omitempty"`
+	Entries      uint64             `protobuf:"varint,3,opt,name=entries,proto3" json:"entries,omitempty"`
+	WritesPerDay uint32             `protobuf:"varint,4,opt,name=writes_per_day,json=writesPerDay,proto3" json:"writes_per_day,omitempty"`
+	MaxEntries   uint32             `protobuf:"varint,5,opt,name=max_entries,json=maxEntries,proto3" json:"max_entries,omitempty"`
+	Games        []*GameUsageResult `protobuf:"bytes,6,rep,name=games,proto3" json:"games,omitempty"`
+}
+
+func (x *UsageResult) Reset() {
+	*x = UsageResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UsageResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageResult) ProtoMessage() {}
+
+func (x *UsageResult) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageResult.ProtoReflect.Descriptor instead.
+func (*UsageResult) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *UsageResult) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+func (x *UsageResult) GetWrites() int64 {
+	if x != nil {
+		return x.Writes
+	}
+	return 0
+}
+
+func (x *UsageResult) GetEntries() uint64 {
+	if x != nil {
+		return x.Entries
+	}
+	return 0
+}
+
+func (x *UsageResult) GetWritesPerDay() uint32 {
+	if x != nil {
+		return x.WritesPerDay
+	}
+	return 0
+}
+
+func (x *UsageResult) GetMaxEntries() uint32 {
+	if x != nil {
+		return x.MaxEntries
+	}
+	return 0
+}
+
+func (x *UsageResult) GetGames() []*GameUsageResult {
+	if x != nil {
+		return x.Games
+	}
+	return nil
+}
+
+type GetUsageResultSuccess struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Result *UsageResult `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+}
+
+func (x *GetUsageResultSuccess) Reset() {
+	*x = GetUsageResultSuccess{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetUsageResultSuccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageResultSuccess) ProtoMessage() {}
+
+func (x *GetUsageResultSuccess) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsageResultSuccess.ProtoReflect.Descriptor instead.
+func (*GetUsageResultSuccess) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *GetUsageResultSuccess) GetResult() *UsageResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type GetDeadLettersParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Endpoint string `protobuf:"bytes,1,opt,name=endpoint,proto3" json:"endpoint,omitempty"` // Id of webhook endpoint
+	Limit    uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`      // Maximum number of dead letters
+}
+
+func (x *GetDeadLettersParams) Reset() {
+	*x = GetDeadLettersParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetDeadLettersParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeadLettersParams) ProtoMessage() {}
+
+func (x *GetDeadLettersParams) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeadLettersParams.ProtoReflect.Descriptor instead.
+func (*GetDeadLettersParams) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *GetDeadLettersParams) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *GetDeadLettersParams) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type DeadLetter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id       string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`              // Id of the event
+	Payload  string `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`    // Body of the webhook (JSON)
+	Attempts uint32 `protobuf:"varint,3,opt,name=attempts,proto3" json:"attempts,omitempty"` // Number of failed attempts
+	Error    string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`        // Error of the last attempt
+	Ts       int64  `protobuf:"varint,5,opt,name=ts,proto3" json:"ts,omitempty"`             // Time of the last attempt (unix ms)
+	Tenant   string `protobuf:"bytes,6,opt,name=tenant,proto3" json:"tenant,omitempty"`      // Id of tenant of the event (empty - default tenant)
+}
+
+func (x *DeadLetter) Reset() {
+	*x = DeadLetter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeadLetter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeadLetter) ProtoMessage() {}
+
+func (x *DeadLetter) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeadLetter.ProtoReflect.Descriptor instead.
+func (*DeadLetter) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *DeadLetter) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetPayload() string {
+	if x != nil {
+		return x.Payload
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetAttempts() uint32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *DeadLetter) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetTs() int64 {
+	if x != nil {
+		return x.Ts
+	}
+	return 0
+}
+
+func (x *DeadLetter) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+type GetDeadLettersResultSuccess struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Result []*DeadLetter `protobuf:"bytes,1,rep,name=result,proto3" json:"result,omitempty"`
+}
+
+func (x *GetDeadLettersResultSuccess) Reset() {
+	*x = GetDeadLettersResultSuccess{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetDeadLettersResultSuccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeadLettersResultSuccess) ProtoMessage() {}
+
+func (x *GetDeadLettersResultSuccess) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeadLettersResultSuccess.ProtoReflect.Descriptor instead.
+func (*GetDeadLettersResultSuccess) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *GetDeadLettersResultSuccess) GetResult() []*DeadLetter {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type ReplayDeadLettersParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Endpoint string   `protobuf:"bytes,1,opt,name=endpoint,proto3" json:"endpoint,omitempty"` // Id of webhook endpoint
+	Ids      []string `protobuf:"bytes,2,rep,name=ids,proto3" json:"ids,omitempty"`           // Ids of dead letters (empty - the oldest ones)
+}
+
+func (x *ReplayDeadLettersParams) Reset() {
+	*x = ReplayDeadLettersParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReplayDeadLettersParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayDeadLettersParams) ProtoMessage() {}
+
+func (x *ReplayDeadLettersParams) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayDeadLettersParams.ProtoReflect.Descriptor instead.
+func (*ReplayDeadLettersParams) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ReplayDeadLettersParams) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *ReplayDeadLettersParams) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type ReplayResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Replayed int64 `protobuf:"varint,1,opt,name=replayed,proto3" json:"replayed,omitempty"` // Number of dead letters queued for delivery
+}
+
+func (x *ReplayResult) Reset() {
+	*x = ReplayResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReplayResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayResult) ProtoMessage() {}
+
+func (x *ReplayResult) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayResult.ProtoReflect.Descriptor instead.
+func (*ReplayResult) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ReplayResult) GetReplayed() int64 {
+	if x != nil {
+		return x.Replayed
+	}
+	return 0
+}
+
+type ReplayDeadLettersResultSuccess struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Result *ReplayResult `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+}
+
+func (x *ReplayDeadLettersResultSuccess) Reset() {
+	*x = ReplayDeadLettersResultSuccess{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_controllers_pb_dto_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReplayDeadLettersResultSuccess) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayDeadLettersResultSuccess) ProtoMessage() {}
+
+func (x *ReplayDeadLettersResultSuccess) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_controllers_pb_dto_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayDeadLettersResultSuccess.ProtoReflect.Descriptor instead.
+func (*ReplayDeadLettersResultSuccess) Descriptor() ([]byte, []int) {
+	return file_internal_controllers_pb_dto_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ReplayDeadLettersResultSuccess) GetResult() *ReplayResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+var File_internal_controllers_pb_dto_proto protoreflect.FileDescriptor
+
+var file_internal_controllers_pb_dto_proto_rawDesc = []byte{
+	0x0a, 0x21, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x63, 0x6f, 0x6e, 0x74, 0x72,
+	0x6f, 0x6c, 0x6c, 0x65, 0x72, 0x73, 0x2f, 0x70, 0x62, 0x2f, 0x64, 0x74, 0x6f, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x13, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61, 0x72, 0x64,
+	0x2e, 0x68, 0x74, 0x74, 0x70, 0x2e, 0x76, 0x31, 0x22, 0x27, 0x0a, 0x0d, 0x52, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x22, 0x37, 0x0a, 0x0b, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72,
+	0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x22, 0xb8, 0x01, 0x0a, 0x0f, 0x53,
+	0x65, 0x6e, 0x64, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x17,
+	0x0a, 0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64,
+	0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61,
+	0x72, 0x61, 0x6d, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61,
+	0x6d, 0x73, 0x12, 0x15, 0x0a, 0x06, 0x72, 0x75, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x72, 0x75, 0x6e, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x64, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x45, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x53,
+	0x63, 0x6f, 0x72, 0x65, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x67, 0x61,
+	0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x67, 0x61, 0x6d,
+	0x65, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x22, 0x42, 0x0a, 0x0e,
+	0x47, 0x65, 0x74, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x17,
+	0x0a, 0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64,
+	0x22, 0x52, 0x0a, 0x0e, 0x55, 0x73, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x69,
+	0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x61,
+	0x72, 0x61, 0x6d, 0x73, 0x22, 0x78, 0x0a, 0x0d, 0x52, 0x75, 0x6e, 0x50, 0x72, 0x6f, 0x70, 0x65,
+	0x72, 0x74, 0x69, 0x65, 0x73, 0x12, 0x15, 0x0a, 0x06, 0x72, 0x75, 0x6e, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x72, 0x75, 0x6e, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x63, 0x6f,
+	0x72, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x0e,
+	0x0a, 0x02, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x74, 0x73, 0x22, 0x54,
+	0x0a, 0x15, 0x47, 0x65, 0x74, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x3b, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x62, 0x6f, 0x61, 0x72, 0x64, 0x2e, 0x68, 0x74, 0x74, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73,
+	0x65, 0x72, 0x50, 0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x69, 0x65, 0x73, 0x52, 0x06, 0x72, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x22, 0x57, 0x0a, 0x19, 0x47, 0x65, 0x74, 0x53, 0x63, 0x6f, 0x72, 0x65,
+	0x52, 0x75, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x12, 0x3a, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x22, 0x2e, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x2e,
+	0x68, 0x74, 0x74, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x75, 0x6e, 0x50, 0x72, 0x6f, 0x70, 0x65,
+	0x72, 0x74, 0x69, 0x65, 0x73, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x54, 0x0a,
+	0x0c, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x70, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x17, 0x0a,
+	0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x13, 0x0a, 0x05, 0x6e, 0x5f, 0x74, 0x6f, 0x70, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x6e, 0x54, 0x6f, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x66,
+	0x69, 0x65, 0x6c, 0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x69, 0x65,
+	0x6c, 0x64, 0x73, 0x22, 0x56, 0x0a, 0x12, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65,
+	0x54, 0x6f, 0x70, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x67, 0x61, 0x6d,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x67, 0x61, 0x6d, 0x65,
+	0x49, 0x64, 0x12, 0x13, 0x0a, 0x05, 0x6e, 0x5f, 0x74, 0x6f, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x04, 0x6e, 0x54, 0x6f, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x22, 0x8c, 0x01, 0x0a, 0x08,
+	0x54, 0x6f, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49,
+	0x64, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70,
+	0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x61, 0x72,
+	0x61, 0x6d, 0x73, 0x12, 0x15, 0x0a, 0x06, 0x72, 0x75, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x72, 0x75, 0x6e, 0x49, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x73,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x74, 0x73, 0x22, 0x4c, 0x0a, 0x13, 0x47, 0x65,
+	0x74, 0x54, 0x6f, 0x70, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x12, 0x35, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x1d, 0x2e, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x2e,
+	0x68, 0x74, 0x74, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x6f, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x6b, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x43,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x17, 0x0a, 0x07,
+	0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x67,
+	0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c,
+	0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x77, 0x61, 0x69, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x04, 0x77, 0x61, 0x69, 0x74, 0x22, 0xb9, 0x01, 0x0a, 0x0b, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x0e, 0x0a, 0x02, 0x6f, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x6f, 0x70, 0x12,
+	0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x15, 0x0a, 0x06, 0x72, 0x75, 0x6e, 0x5f,
+	0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x72, 0x75, 0x6e, 0x49, 0x64, 0x12,
+	0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05,
+	0x73, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x72,
+	0x61, 0x6d, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d,
+	0x73, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x74,
+	0x73, 0x22, 0x76, 0x0a, 0x0a, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12,
+	0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6d, 0x6f, 0x72,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x6d, 0x6f, 0x72, 0x65, 0x12, 0x3a, 0x0a,
+	0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20,
+	0x2e, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x2e, 0x68, 0x74, 0x74,
+	0x70, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x22, 0x52, 0x0a, 0x17, 0x47, 0x65, 0x74,
+	0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x53, 0x75, 0x63,
+	0x63, 0x65, 0x73, 0x73, 0x12, 0x37, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61,
+	0x72, 0x64, 0x2e, 0x68, 0x74, 0x74, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x5c, 0x0a,
+	0x12, 0x53, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x50, 0x61, 0x72,
+	0x61, 0x6d, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07,
+	0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75,
+	0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x22, 0x46, 0x0a, 0x12, 0x47,
+	0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x50, 0x61, 0x72, 0x61, 0x6d,
+	0x73, 0x12, 0x17, 0x0a, 0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73,
+	0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75, 0x73, 0x65,
+	0x72, 0x49, 0x64, 0x22, 0x27, 0x0a, 0x0f, 0x55, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x22, 0x59, 0x0a, 0x19,
+	0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x3c, 0x0a, 0x06, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x6c, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x2e, 0x68, 0x74, 0x74, 0x70, 0x2e, 0x76, 0x31, 0x2e,
+	0x55, 0x73, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52,
+	0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x44, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x51, 0x75,
+	0x61, 0x72, 0x61, 0x6e, 0x74, 0x69, 0x6e, 0x65, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x17,
+	0x0a, 0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0xea, 0x01,
+	0x0a, 0x0e, 0x51, 0x75, 0x61, 0x72, 0x61, 0x6e, 0x74, 0x69, 0x6e, 0x65, 0x49, 0x74, 0x65, 0x6d,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x75, 0x6c,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x75, 0x6c, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x15, 0x0a, 0x06, 0x72, 0x75, 0x6e, 0x5f, 0x69,
+	0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x72, 0x75, 0x6e, 0x49, 0x64, 0x12, 0x1a,
+	0x0a, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x08, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x73,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x74, 0x73, 0x22, 0x59, 0x0a, 0x1a, 0x47, 0x65,
+	0x74, 0x51, 0x75, 0x61, 0x72, 0x61, 0x6e, 0x74, 0x69, 0x6e, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x3b, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x6c, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x2e, 0x68, 0x74, 0x74, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x51,
+	0x75, 0x61, 0x72, 0x61, 0x6e, 0x74, 0x69, 0x6e, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x06, 0x72,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x43, 0x0a, 0x18, 0x41, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x65,
+	0x51, 0x75, 0x61, 0x72, 0x61, 0x6e, 0x74, 0x69, 0x6e, 0x65, 0x64, 0x50, 0x61, 0x72, 0x61, 0x6d,
+	0x73, 0x12, 0x17, 0x0a, 0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x42, 0x0a, 0x17, 0x52, 0x65,
+	0x6a, 0x65, 0x63, 0x74, 0x51, 0x75, 0x61, 0x72, 0x61, 0x6e, 0x74, 0x69, 0x6e, 0x65, 0x64, 0x50,
+	0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x41,
+	0x0a, 0x0e, 0x47, 0x65, 0x74, 0x41, 0x75, 0x64, 0x69, 0x74, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73,
+	0x12, 0x19, 0x0a, 0x08, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x73, 0x65, 0x71, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x07, 0x66, 0x72, 0x6f, 0x6d, 0x53, 0x65, 0x71, 0x12, 0x14, 0x0a, 0x05, 0x6c,
+	0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69,
+	0x74, 0x22, 0xa4, 0x02, 0x0a, 0x0a, 0x41, 0x75, 0x64, 0x69, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x71, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x73,
+	0x65, 0x71, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02,
+	0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x17, 0x0a, 0x07, 0x67, 0x61, 0x6d, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x67, 0x61, 0x6d, 0x65, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65,
+	0x72, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72,
+	0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x66,
+	0x74, 0x65, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72,
+	0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12,
+	0x1b, 0x0a, 0x09, 0x70, 0x72, 0x65, 0x76, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x65, 0x76, 0x48, 0x61, 0x73, 0x68, 0x12, 0x12, 0x0a, 0x04,
+	0x68, 0x61, 0x73, 0x68, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68,
+	0x12, 0x16, 0x0a, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x22, 0x5e, 0x0a, 0x0b, 0x41, 0x75, 0x64, 0x69,
+	0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x39, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69,
+	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x6c, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x2e, 0x68, 0x74, 0x74, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x41,
+	0x75, 0x64, 0x69, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69,
+	0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x22, 0x51, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x41,
+	0x75, 0x64, 0x69, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x12, 0x38, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x20, 0x2e, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x2e,
+	0x68, 0x74, 0x74, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x75, 0x64, 0x69, 0x74, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x28, 0x0a, 0x0e, 0x47,
+	0x65, 0x74, 0x55, 0x73, 0x61, 0x67, 0x65, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x16, 0x0a,
+	0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74,
+	0x65, 0x6e, 0x61, 0x6e, 0x74, 0x22, 0xa3, 0x01, 0x0a, 0x0f, 0x47, 0x61, 0x6d, 0x65, 0x55, 0x73,
+	0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x67, 0x61, 0x6d,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x67, 0x61, 0x6d, 0x65,
+	0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x72, 0x69, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x06, 0x77, 0x72, 0x69, 0x74, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e,
+	0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x65, 0x6e, 0x74,
+	0x72, 0x69, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x77, 0x72, 0x69, 0x74, 0x65, 0x73, 0x5f, 0x70,
+	0x65, 0x72, 0x5f, 0x64, 0x61, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x77, 0x72,
+	0x69, 0x74, 0x65, 0x73, 0x50, 0x65, 0x72, 0x44, 0x61, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x61,
+	0x78, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0a, 0x6d, 0x61, 0x78, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0xda, 0x01, 0x0a, 0x0b,
+	0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x74,
+	0x65, 0x6e, 0x61, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x65, 0x6e,
+	0x61, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x72, 0x69, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x06, 0x77, 0x72, 0x69, 0x74, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x65,
+	0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x65, 0x6e,
+	0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x77, 0x72, 0x69, 0x74, 0x65, 0x73, 0x5f,
+	0x70, 0x65, 0x72, 0x5f, 0x64, 0x61, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x77,
+	0x72, 0x69, 0x74, 0x65, 0x73, 0x50, 0x65, 0x72, 0x44, 0x61, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x6d,
+	0x61, 0x78, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0a, 0x6d, 0x61, 0x78, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x3a, 0x0a, 0x05,
+	0x67, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x6c, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x2e, 0x68, 0x74, 0x74, 0x70, 0x2e, 0x76,
+	0x31, 0x2e, 0x47, 0x61, 0x6d, 0x65, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x52, 0x05, 0x67, 0x61, 0x6d, 0x65, 0x73, 0x22, 0x51, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x55,
+	0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x12, 0x38, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x20, 0x2e, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x2e,
+	0x68, 0x74, 0x74, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x48, 0x0a, 0x14, 0x47,
+	0x65, 0x74, 0x44, 0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x73, 0x50, 0x61, 0x72,
+	0x61, 0x6d, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x90, 0x01, 0x0a, 0x0a, 0x44, 0x65, 0x61, 0x64, 0x4c, 0x65,
+	0x74, 0x74, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x1a,
+	0x0a, 0x08, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x08, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x12, 0x0e, 0x0a, 0x02, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x74, 0x73,
+	0x12, 0x16, 0x0a, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x22, 0x56, 0x0a, 0x1b, 0x47, 0x65, 0x74, 0x44,
+	0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x37, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x62, 0x6f, 0x61, 0x72, 0x64, 0x2e, 0x68, 0x74, 0x74, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65,
+	0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x22, 0x47, 0x0a, 0x17, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x44, 0x65, 0x61, 0x64, 0x4c, 0x65,
+	0x74, 0x74, 0x65, 0x72, 0x73, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x65,
+	0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65,
+	0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x64, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x03, 0x69, 0x64, 0x73, 0x22, 0x2a, 0x0a, 0x0c, 0x52, 0x65, 0x70,
+	0x6c, 0x61, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x70,
+	0x6c, 0x61, 0x79, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x70,
+	0x6c, 0x61, 0x79, 0x65, 0x64, 0x22, 0x5b, 0x0a, 0x1e, 0x52, 0x65, 0x70, 0x6c, 0x61, 0x79, 0x44,
+	0x65, 0x61, 0x64, 0x4c, 0x65, 0x74, 0x74, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x39, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x62, 0x6f, 0x61, 0x72, 0x64, 0x2e, 0x68, 0x74, 0x74, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65,
+	0x70, 0x6c, 0x61, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x42, 0x35, 0x5a, 0x33, 0x67, 0x6f, 0x2d, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x62,
+	0x6f, 0x61, 0x72, 0x64, 0x2d, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2f, 0x69, 0x6e, 0x74, 0x65,
+	0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x6c, 0x65, 0x72, 0x73,
+	0x2f, 0x70, 0x62, 0x3b, 0x64, 0x74, 0x6f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
+}
+
+var (
+	file_internal_controllers_pb_dto_proto_rawDescOnce sync.Once
+	file_internal_controllers_pb_dto_proto_rawDescData = file_internal_controllers_pb_dto_proto_rawDesc
+)
+
+func file_internal_controllers_pb_dto_proto_rawDescGZIP() []byte {
+	file_internal_controllers_pb_dto_proto_rawDescOnce.Do(func() {
+		file_internal_controllers_pb_dto_proto_rawDescData = protoimpl.X.CompressGZIP(file_internal_controllers_pb_dto_proto_rawDescData)
+	})
+	return file_internal_controllers_pb_dto_proto_rawDescData
+}
+
+var file_internal_controllers_pb_dto_proto_msgTypes = make([]protoimpl.MessageInfo, 40)
+var file_internal_controllers_pb_dto_proto_goTypes = []any{
+	(*ResultSuccess)(nil),                  // 0: leaderboard.http.v1.ResultSuccess
+	(*ResultError)(nil),                    // 1: leaderboard.http.v1.ResultError
+	(*SendScoreParams)(nil),                // 2: leaderboard.http.v1.SendScoreParams
+	(*DeleteScoreParams)(nil),              // 3: leaderboard.http.v1.DeleteScoreParams
+	(*GetScoreParams)(nil),                 // 4: leaderboard.http.v1.GetScoreParams
+	(*UserProperties)(nil),                 // 5: leaderboard.http.v1.UserProperties
+	(*RunProperties)(nil),                  // 6: leaderboard.http.v1.RunProperties
+	(*GetScoreResultSuccess)(nil),          // 7: leaderboard.http.v1.GetScoreResultSuccess
+	(*GetScoreRunsResultSuccess)(nil),      // 8: leaderboard.http.v1.GetScoreRunsResultSuccess
+	(*GetTopParams)(nil),                   // 9: leaderboard.http.v1.GetTopParams
+	(*SubscribeTopParams)(nil),             // 10: leaderboard.http.v1.SubscribeTopParams
+	(*TopEntry)(nil),                       // 11: leaderboard.http.v1.TopEntry
+	(*GetTopResultSuccess)(nil),            // 12: leaderboard.http.v1.GetTopResultSuccess
+	(*GetChangesParams)(nil),               // 13: leaderboard.http.v1.GetChangesParams
+	(*ChangeEntry)(nil),                    // 14: leaderboard.http.v1.ChangeEntry
+	(*ChangeList)(nil),                     // 15: leaderboard.http.v1.ChangeList
+	(*GetChangesResultSuccess)(nil),        // 16: leaderboard.http.v1.GetChangesResultSuccess
+	(*SetUserStateParams)(nil),             // 17: leaderboard.http.v1.SetUserStateParams
+	(*GetUserStateParams)(nil),             // 18: leaderboard.http.v1.GetUserStateParams
+	(*UserStateResult)(nil),                // 19: leaderboard.http.v1.UserStateResult
+	(*GetUserStateResultSuccess)(nil),      // 20: leaderboard.http.v1.GetUserStateResultSuccess
+	(*GetQuarantineParams)(nil),            // 21: leaderboard.http.v1.GetQuarantineParams
+	(*QuarantineItem)(nil),                 // 22: leaderboard.http.v1.QuarantineItem
+	(*GetQuarantineResultSuccess)(nil),     // 23: leaderboard.http.v1.GetQuarantineResultSuccess
+	(*ApproveQuarantinedParams)(nil),       // 24: leaderboard.http.v1.ApproveQuarantinedParams
+	(*RejectQuarantinedParams)(nil),        // 25: leaderboard.http.v1.RejectQuarantinedParams
+	(*GetAuditParams)(nil),                 // 26: leaderboard.http.v1.GetAuditParams
+	(*AuditEntry)(nil),                     // 27: leaderboard.http.v1.AuditEntry
+	(*AuditResult)(nil),                    // 28: leaderboard.http.v1.AuditResult
+	(*GetAuditResultSuccess)(nil),          // 29: leaderboard.http.v1.GetAuditResultSuccess
+	(*GetUsageParams)(nil),                 // 30: leaderboard.http.v1.GetUsageParams
+	(*GameUsageResult)(nil),                // 31: leaderboard.http.v1.GameUsageResult
+	(*UsageResult)(nil),                    // 32: leaderboard.http.v1.UsageResult
+	(*GetUsageResultSuccess)(nil),          // 33: leaderboard.http.v1.GetUsageResultSuccess
+	(*GetDeadLettersParams)(nil),           // 34: leaderboard.http.v1.GetDeadLettersParams
+	(*DeadLetter)(nil),                     // 35: leaderboard.http.v1.DeadLetter
+	(*GetDeadLettersResultSuccess)(nil),    // 36: leaderboard.http.v1.GetDeadLettersResultSuccess
+	(*ReplayDeadLettersParams)(nil),        // 37: leaderboard.http.v1.ReplayDeadLettersParams
+	(*ReplayResult)(nil),                   // 38: leaderboard.http.v1.ReplayResult
+	(*ReplayDeadLettersResultSuccess)(nil), // 39: leaderboard.http.v1.ReplayDeadLettersResultSuccess
+}
+var file_internal_controllers_pb_dto_proto_depIdxs = []int32{
+	5,  // 0: leaderboard.http.v1.GetScoreResultSuccess.result:type_name -> leaderboard.http.v1.UserProperties
+	6,  // 1: leaderboard.http.v1.GetScoreRunsResultSuccess.result:type_name -> leaderboard.http.v1.RunProperties
+	11, // 2: leaderboard.http.v1.GetTopResultSuccess.result:type_name -> leaderboard.http.v1.TopEntry
+	14, // 3: leaderboard.http.v1.ChangeList.changes:type_name -> leaderboard.http.v1.ChangeEntry
+	15, // 4: leaderboard.http.v1.GetChangesResultSuccess.result:type_name -> leaderboard.http.v1.ChangeList
+	19, // 5: leaderboard.http.v1.GetUserStateResultSuccess.result:type_name -> leaderboard.http.v1.UserStateResult
+	22, // 6: leaderboard.http.v1.GetQuarantineResultSuccess.result:type_name -> leaderboard.http.v1.QuarantineItem
+	27, // 7: leaderboard.http.v1.AuditResult.entries:type_name -> leaderboard.http.v1.AuditEntry
+	28, // 8: leaderboard.http.v1.GetAuditResultSuccess.result:type_name -> leaderboard.http.v1.AuditResult
+	31, // 9: leaderboard.http.v1.UsageResult.games:type_name -> leaderboard.http.v1.GameUsageResult
+	32, // 10: leaderboard.http.v1.GetUsageResultSuccess.result:type_name -> leaderboard.http.v1.UsageResult
+	35, // 11: leaderboard.http.v1.GetDeadLettersResultSuccess.result:type_name -> leaderboard.http.v1.DeadLetter
+	38, // 12: leaderboard.http.v1.ReplayDeadLettersResultSuccess.result:type_name -> leaderboard.http.v1.ReplayResult
+	13, // [13:13] is the sub-list for method output_type
+	13, // [13:13] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_internal_controllers_pb_dto_proto_init() }
+func file_internal_controllers_pb_dto_proto_init() {
+	if File_internal_controllers_pb_dto_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_internal_controllers_pb_dto_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*ResultSuccess); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*ResultError); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*SendScoreParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*DeleteScoreParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*GetScoreParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*UserProperties); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*RunProperties); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*GetScoreResultSuccess); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*GetScoreRunsResultSuccess); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*GetTopParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*SubscribeTopParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*TopEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*GetTopResultSuccess); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*GetChangesParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[14].Exporter = func(v any, i int) any {
+			switch v := v.(*ChangeEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[15].Exporter = func(v any, i int) any {
+			switch v := v.(*ChangeList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[16].Exporter = func(v any, i int) any {
+			switch v := v.(*GetChangesResultSuccess); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[17].Exporter = func(v any, i int) any {
+			switch v := v.(*SetUserStateParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[18].Exporter = func(v any, i int) any {
+			switch v := v.(*GetUserStateParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[19].Exporter = func(v any, i int) any {
+			switch v := v.(*UserStateResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[20].Exporter = func(v any, i int) any {
+			switch v := v.(*GetUserStateResultSuccess); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[21].Exporter = func(v any, i int) any {
+			switch v := v.(*GetQuarantineParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[22].Exporter = func(v any, i int) any {
+			switch v := v.(*QuarantineItem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[23].Exporter = func(v any, i int) any {
+			switch v := v.(*GetQuarantineResultSuccess); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[24].Exporter = func(v any, i int) any {
+			switch v := v.(*ApproveQuarantinedParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[25].Exporter = func(v any, i int) any {
+			switch v := v.(*RejectQuarantinedParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[26].Exporter = func(v any, i int) any {
+			switch v := v.(*GetAuditParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[27].Exporter = func(v any, i int) any {
+			switch v := v.(*AuditEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[28].Exporter = func(v any, i int) any {
+			switch v := v.(*AuditResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[29].Exporter = func(v any, i int) any {
+			switch v := v.(*GetAuditResultSuccess); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[30].Exporter = func(v any, i int) any {
+			switch v := v.(*GetUsageParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[31].Exporter = func(v any, i int) any {
+			switch v := v.(*GameUsageResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[32].Exporter = func(v any, i int) any {
+			switch v := v.(*UsageResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[33].Exporter = func(v any, i int) any {
+			switch v := v.(*GetUsageResultSuccess); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[34].Exporter = func(v any, i int) any {
+			switch v := v.(*GetDeadLettersParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[35].Exporter = func(v any, i int) any {
+			switch v := v.(*DeadLetter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[36].Exporter = func(v any, i int) any {
+			switch v := v.(*GetDeadLettersResultSuccess); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[37].Exporter = func(v any, i int) any {
+			switch v := v.(*ReplayDeadLettersParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[38].Exporter = func(v any, i int) any {
+			switch v := v.(*ReplayResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_controllers_pb_dto_proto_msgTypes[39].Exporter = func(v any, i int) any {
+			switch v := v.(*ReplayDeadLettersResultSuccess); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_internal_controllers_pb_dto_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   40,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_internal_controllers_pb_dto_proto_goTypes,
+		DependencyIndexes: file_internal_controllers_pb_dto_proto_depIdxs,
+		MessageInfos:      file_internal_controllers_pb_dto_proto_msgTypes,
+	}.Build()
+	File_internal_controllers_pb_dto_proto = out.File
+	file_internal_controllers_pb_dto_proto_rawDesc = nil
+	file_internal_controllers_pb_dto_proto_goTypes = nil
+	file_internal_controllers_pb_dto_proto_depIdxs = nil
+}
@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"errors"
+	ac "go-leaderboard-server/internal/appcontext"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReplayDeadLettersParams struct {
+	Endpoint string   `json:"endpoint" uri:"endpoint" binding:"required,max=32,alphanum" example:"rewards" extensions:"x-order=0"`    // Id of webhook endpoint
+	Ids      []string `json:"ids" binding:"omitempty,max=100,dive,max=50,alphanum" example:"5f2b8c1d9e7a4b6c" extensions:"x-order=1"` // Ids of dead letters (empty - the oldest ones)
+}
+
+type ReplayResult struct {
+	Replayed int `json:"replayed" binding:"required" example:"10"` // Number of dead letters queued for delivery
+}
+
+type ReplayDeadLettersResultSuccess struct {
+	Result ReplayResult `json:"result" binding:"required"`
+}
+
+// @Description Queues dead letters of the endpoint and the tenant of the key for delivery again and removes them from dead letters.
+// @Description Without ids the oldest dead letters that fit into the queue of the endpoint are replayed, unknown ids are skipped
+// @Tags admin
+// @Accept json,application/msgpack,application/x-protobuf
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param data body ReplayDeadLettersParams true "Body data"
+// @Success 200 {object} ReplayDeadLettersResultSuccess "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied, key limited to some games)"
+// @Failure 404 {object} ResultError "Error response (webhooks are disabled, endpoint not found)"
+// @Failure 413 {object} ResultError "Error response (request body is too large)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Failure 503 {object} ResultError "Error response (queue of the endpoint is full, the rest of dead letters is kept)"
+// @Security ApiKeyAuth
+// @Router /admin/ReplayDeadLetters [post]
+func ReplayDeadLettersHandler(c *gin.Context) {
+	var (
+		params ReplayDeadLettersParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = c.ShouldBindJSON(&params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	replayDeadLetters(c, params)
+}
+
+// @Description Queues dead letters of the endpoint and the tenant of the key for delivery again and removes them from dead letters.
+// @Description Without ids the oldest dead letters that fit into the queue of the endpoint are replayed, unknown ids are skipped
+// @Tags admin
+// @Accept json,application/msgpack,application/x-protobuf
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param endpoint path string true "Id of webhook endpoint"
+// @Param data body ReplayDeadLettersParams false "Body data (ids only)"
+// @Success 200 {object} ReplayDeadLettersResultSuccess "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied, key limited to some games)"
+// @Failure 404 {object} ResultError "Error response (webhooks are disabled, endpoint not found)"
+// @Failure 413 {object} ResultError "Error response (request body is too large)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Failure 503 {object} ResultError "Error response (queue of the endpoint is full, the rest of dead letters is kept)"
+// @Security ApiKeyAuth
+// @Router /v2/webhooks/{endpoint}/deadletters/replay [post]
+func ReplayDeadLettersV2Handler(c *gin.Context) {
+	var (
+		params ReplayDeadLettersParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = bindV2Params(c, &params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	replayDeadLetters(c, params)
+}
+
+// Queues dead letters of the endpoint and responds with their number
+func replayDeadLetters(c *gin.Context, params ReplayDeadLettersParams) {
+	var (
+		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+		err    error
+		logger = log.GetLogger()
+	)
+
+	// endpoints receive events of all games, letters of the tenant of the key are available
+	tenant, err := checkTenantAccess(c)
+	if err != nil {
+		logger.Warn("Access denied", log.LogParams{"path": c.FullPath()})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return
+	}
+
+	replayed, err := ac.WebhookService.ReplayDeadLetters(c, params.Endpoint, tenant, params.Ids)
+	if errors.Is(err, services.ErrWebhooksDisabled) || errors.Is(err, services.ErrWebhookEndpointNotFound) {
+		_ = c.AbortWithError(http.StatusNotFound, err)
+		return
+	}
+	if errors.Is(err, services.ErrWebhookQueueFull) {
+		logger.Warn("Dead letters are replayed partially", log.LogParams{"endpoint": params.Endpoint, "replayed": replayed})
+		_ = c.AbortWithError(http.StatusServiceUnavailable, err)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to replay dead letters", log.LogParams{"error": err, "endpoint": params.Endpoint, "replayed": replayed})
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	logger.Info("Dead letters replayed", log.LogParams{"endpoint": params.Endpoint, "replayed": replayed})
+
+	c.JSON(http.StatusOK, &ReplayDeadLettersResultSuccess{Result: ReplayResult{Replayed: replayed}})
+}
@@ -1,34 +1,56 @@
 package controllers
 
 import (
+	"errors"
 	ac "go-leaderboard-server/internal/appcontext"
+	"go-leaderboard-server/internal/config"
 	dbprovider "go-leaderboard-server/internal/db"
 	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
+type ScoreData struct {
+	Score    float64 `json:"score" binding:"required,min=0" example:"1500" extensions:"x-order=2"`                        // User score
+	Name     string  `json:"name,omitempty" binding:"max=50" example:"John" extensions:"x-order=3"`                       // User name
+	Params   string  `json:"params,omitempty" binding:"max=255" example:"some additional payload" extensions:"x-order=4"` // Additional payload
+	RunId    string  `json:"runId,omitempty" binding:"omitempty,max=50,alphanum" example:"run1" extensions:"x-order=5"`   // Id of run (runs boards only, generated if empty)
+	Duration uint32  `json:"duration,omitempty" example:"90000" extensions:"x-order=6"`                                   // Match duration (ms), checked by anti-cheat rules of the board
+}
+
 type SendScoreParams struct {
-	GameId string  `json:"gameId" binding:"required,max=50,alphanum" example:"game1" extensions:"x-order=0"`            // Id of game (alphanumeric values)
-	UserId string  `json:"userId" binding:"required,max=50,alphanum" example:"user1" extensions:"x-order=1"`            // Id of user (alphanumeric values)
-	Score  float64 `json:"score" binding:"required,min=0" example:"1500" extensions:"x-order=2"`                        // User score
-	Name   string  `json:"name,omitempty" binding:"max=50" example:"John" extensions:"x-order=3"`                       // User name
-	Params string  `json:"params,omitempty" binding:"max=255" example:"some additional payload" extensions:"x-order=4"` // Additional payload
+	GameId string `json:"gameId" uri:"gameId" binding:"required,max=50,alphanum" example:"game1" extensions:"x-order=0"` // Id of game (alphanumeric values)
+	UserId string `json:"userId" uri:"userId" binding:"required,max=50,alphanum" example:"user1" extensions:"x-order=1"` // Id of user (alphanumeric values)
+	ScoreData
 }
 
-// @Description Stores user data in a database
+// @Description Stores user data in a database (a new run of the user for runs boards)
 // @Tags user
-// @Accept json
-// @Produce json
+// @Accept json,application/msgpack,application/x-protobuf
+// @Produce json,application/msgpack,application/x-protobuf
 // @Param data body SendScoreParams true "Body data"
+// @Param X-Signature-Timestamp header string false "Time of signing, unix ms (boards with secrets only)"
+// @Param X-Signature-Nonce header string false "Unique value of the request, up to 64 characters (boards with secrets only)"
+// @Param X-Signature header string false "HMAC-SHA256 (hex) of timestamp, nonce and canonical body (boards with secrets only)"
+// @Param Idempotency-Key header string false "Unique key of the request, up to 255 characters (repeated requests return the stored response)"
 // @Success 200 {object} ResultSuccess "Successful response"
+// @Success 202 {object} ResultSuccess "Score is quarantined by anti-cheat rules"
 // @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key, token or signature)"
+// @Failure 403 {object} ResultError "Error response (access denied, banned user)"
+// @Failure 409 {object} ResultError "Error response (request with the same idempotency key is in progress)"
+// @Failure 413 {object} ResultError "Error response (request body is too large)"
+// @Failure 415 {object} ResultError "Error response (signed request body is not JSON)"
+// @Failure 422 {object} ResultError "Error response (score rejected by anti-cheat rules, code - rule name; idempotency key reused)"
+// @Failure 429 {object} ResultError "Error response (rate limit or quota exceeded, code - quota name, see Retry-After header)"
 // @Failure 500 {object} ResultError "Error response"
-// @Router /leaderboard/SendScore [put]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /leaderboard/SendScore [post]
 func SendScoreHandler(c *gin.Context) {
 	var (
-		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
 		params SendScoreParams
 		err    error
 		logger = log.GetLogger()
@@ -41,16 +63,149 @@ func SendScoreHandler(c *gin.Context) {
 		return
 	}
 
-	err = ac.LeaderboardService.PutUserScore(c, params.GameId, params.UserId, dbprovider.UserProperties{
-		Score:  dbprovider.UScoreType(params.Score),
-		Name:   params.Name,
-		Params: params.Params,
-	})
+	status, ok := sendScore(c, params, false)
+	if !ok {
+		return
+	}
+
+	if status == http.StatusAccepted {
+		c.JSON(http.StatusAccepted, &ResultSuccess{Result: "quarantined"})
+		return
+	}
+
+	c.JSON(http.StatusOK, &ResultSuccess{Result: "success"})
+}
+
+// @Description Stores user data in a database (a new run of the user for runs boards)
+// @Tags user
+// @Accept json,application/msgpack,application/x-protobuf
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param gameId path string true "Id of game (alphanumeric values)"
+// @Param userId path string true "Id of user (alphanumeric values)"
+// @Param data body ScoreData true "Body data"
+// @Param X-Signature-Timestamp header string false "Time of signing, unix ms (boards with secrets only)"
+// @Param X-Signature-Nonce header string false "Unique value of the request, up to 64 characters (boards with secrets only)"
+// @Param X-Signature header string false "HMAC-SHA256 (hex) of timestamp, nonce and canonical body with gameId and userId of the path (boards with secrets only)"
+// @Param Idempotency-Key header string false "Unique key of the request, up to 255 characters (repeated requests return the stored response)"
+// @Success 201 {object} ResultSuccess "User data (a run for runs boards) is created"
+// @Success 202 {object} ResultSuccess "Score is quarantined by anti-cheat rules"
+// @Success 204 "User data is updated"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key, token or signature)"
+// @Failure 403 {object} ResultError "Error response (access denied, banned user)"
+// @Failure 409 {object} ResultError "Error response (request with the same idempotency key is in progress)"
+// @Failure 413 {object} ResultError "Error response (request body is too large)"
+// @Failure 415 {object} ResultError "Error response (signed request body is not JSON)"
+// @Failure 422 {object} ResultError "Error response (score rejected by anti-cheat rules, code - rule name; idempotency key reused)"
+// @Failure 429 {object} ResultError "Error response (rate limit or quota exceeded, code - quota name, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /v2/games/{gameId}/users/{userId} [put]
+func SendScoreV2Handler(c *gin.Context) {
+	var (
+		params SendScoreParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = bindV2Params(c, &params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	status, ok := sendScore(c, params, true)
+	if !ok {
+		return
+	}
+
+	switch status {
+	case http.StatusAccepted:
+		c.JSON(http.StatusAccepted, &ResultSuccess{Result: "quarantined"})
+	case http.StatusCreated:
+		c.JSON(http.StatusCreated, &ResultSuccess{Result: "success"})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// Stores the score, returns http.StatusAccepted for quarantined scores, http.StatusCreated for new entries
+// (if checkNew is set) and http.StatusOK otherwise, aborts the request on failure
+func sendScore(c *gin.Context, params SendScoreParams, checkNew bool) (int, bool) {
+	var (
+		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = checkAccess(c, params.GameId, params.UserId)
+	if err != nil {
+		logger.Warn("Access denied", log.LogParams{"gameId": params.GameId, "path": c.FullPath()})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return 0, false
+	}
+
+	err = checkSubject(c, params.UserId)
+	if err != nil {
+		logger.Warn("Access denied", log.LogParams{"gameId": params.GameId, "userId": params.UserId, "path": c.FullPath()})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return 0, false
+	}
+
+	params.GameId = getTenantGameId(c, params.GameId)
+
+	// every accepted run is a new entry
+	isNew := true
+	if ac.AppConfig.GetBoardConfig(params.GameId).Type == config.BOARDTYPE_RUNS {
+		err = ac.LeaderboardService.SubmitUserRun(c, params.GameId, params.UserId, dbprovider.RunProperties{
+			RunId:  params.RunId,
+			Score:  dbprovider.UScoreType(params.Score),
+			Name:   params.Name,
+			Params: params.Params,
+		}, params.Duration)
+	} else {
+		if checkNew {
+			userProp, err := ac.LeaderboardService.GetUserScore(c, params.GameId, params.UserId)
+			if err != nil {
+				logger.Error("Failed to get user score", log.LogParams{"error": err, "gameId": params.GameId, "userId": params.UserId})
+				_ = c.AbortWithError(http.StatusInternalServerError, err)
+				return 0, false
+			}
+			isNew = userProp == nil
+		}
+
+		err = ac.LeaderboardService.SubmitUserScore(c, params.GameId, params.UserId, dbprovider.UserProperties{
+			Score:  dbprovider.UScoreType(params.Score),
+			Name:   params.Name,
+			Params: params.Params,
+		}, params.Duration)
+	}
+	var ruleErr *services.RuleViolationError
+	if errors.As(err, &ruleErr) {
+		if ruleErr.Quarantine {
+			return http.StatusAccepted, true
+		}
+		_ = c.AbortWithError(http.StatusUnprocessableEntity, err)
+		return 0, false
+	}
+	if abortIfQuotaExceeded(c, params.GameId, err) {
+		return 0, false
+	}
+	if errors.Is(err, services.ErrUserBanned) {
+		logger.Info("Score of banned user rejected", log.LogParams{"gameId": params.GameId, "userId": params.UserId})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return 0, false
+	}
 	if err != nil {
 		logger.Error("Failed to put user score", log.LogParams{"error": err, "gameId": params.GameId, "userId": params.UserId})
 		_ = c.AbortWithError(http.StatusInternalServerError, err)
-		return
+		return 0, false
 	}
 
-	c.JSON(http.StatusOK, &ResultSuccess{Result: "success"})
+	if checkNew && isNew {
+		return http.StatusCreated, true
+	}
+	return http.StatusOK, true
 }
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	ac "go-leaderboard-server/internal/appcontext"
+	dbprovider "go-leaderboard-server/internal/db"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var userStates = map[string]dbprovider.UserState{
+	"visible":      dbprovider.USERSTATE_VISIBLE,
+	"shadowbanned": dbprovider.USERSTATE_SHADOWBANNED,
+	"banned":       dbprovider.USERSTATE_BANNED,
+}
+
+type UserStateData struct {
+	State string `json:"state" binding:"required,oneof=visible shadowbanned banned" example:"shadowbanned" extensions:"x-order=2"` // Visibility state (visible - shown to everyone, shadowbanned - hidden from everyone except the user, banned - hidden and new scores are rejected)
+}
+
+type SetUserStateParams struct {
+	GameId string `json:"gameId" uri:"gameId" binding:"required,max=50,alphanum" example:"game1" extensions:"x-order=0"` // Id of game (alphanumeric values)
+	UserId string `json:"userId" uri:"userId" binding:"required,max=50,alphanum" example:"user1" extensions:"x-order=1"` // Id of user (alphanumeric values)
+	UserStateData
+}
+
+// @Description Sets visibility state of user. Not visible users are excluded from tops, but still get their own data
+// @Tags admin
+// @Accept json,application/msgpack,application/x-protobuf
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param data body SetUserStateParams true "Body data"
+// @Success 200 {object} ResultSuccess "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied)"
+// @Failure 413 {object} ResultError "Error response (request body is too large)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Router /admin/SetUserState [post]
+func SetUserStateHandler(c *gin.Context) {
+	var (
+		params SetUserStateParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = c.ShouldBindJSON(&params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	if !setUserState(c, params) {
+		return
+	}
+
+	c.JSON(http.StatusOK, &ResultSuccess{Result: "success"})
+}
+
+// @Description Sets visibility state of user. Not visible users are excluded from tops, but still get their own data
+// @Tags admin
+// @Accept json,application/msgpack,application/x-protobuf
+// @Produce json,application/msgpack,application/x-protobuf
+// @Param gameId path string true "Id of game (alphanumeric values)"
+// @Param userId path string true "Id of user (alphanumeric values)"
+// @Param data body UserStateData true "Body data"
+// @Success 204 "Successful response"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied)"
+// @Failure 413 {object} ResultError "Error response (request body is too large)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 500 {object} ResultError "Error response"
+// @Security ApiKeyAuth
+// @Router /v2/games/{gameId}/users/{userId}/state [put]
+func SetUserStateV2Handler(c *gin.Context) {
+	var (
+		params SetUserStateParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = bindV2Params(c, &params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	if !setUserState(c, params) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Sets the state of the user, aborts the request on failure
+func setUserState(c *gin.Context, params SetUserStateParams) bool {
+	var (
+		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = checkAccess(c, params.GameId, "")
+	if err != nil {
+		logger.Warn("Access denied", log.LogParams{"gameId": params.GameId, "path": c.FullPath()})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return false
+	}
+
+	params.GameId = getTenantGameId(c, params.GameId)
+
+	var before dbprovider.UserState
+	if ac.AuditService.IsEnabled() {
+		before, err = ac.LeaderboardService.GetUserState(c, params.GameId, params.UserId)
+		if err != nil {
+			logger.Error("Failed to get user state", log.LogParams{"error": err, "gameId": params.GameId, "userId": params.UserId})
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return false
+		}
+	}
+
+	err = ac.LeaderboardService.SetUserState(c, params.GameId, params.UserId, userStates[params.State])
+	if err != nil {
+		logger.Error("Failed to set user state", log.LogParams{"error": err, "gameId": params.GameId, "userId": params.UserId})
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return false
+	}
+
+	logger.Info("User state changed", log.LogParams{"gameId": params.GameId, "userId": params.UserId, "state": params.State})
+
+	audit(c, services.AuditRecord{Action: services.AUDITACTION_SET_USER_STATE, GameId: params.GameId, UserId: params.UserId,
+		Before: UserStateResult{State: userStateName(before)}, After: UserStateResult{State: params.State}})
+
+	return true
+}
+
+func userStateName(state dbprovider.UserState) string {
+	for name, s := range userStates {
+		if s == state {
+			return name
+		}
+	}
+	return ""
+}
@@ -8,7 +8,7 @@ import (
 
 // @Description Returns server status (success code)
 // @Tags status
-// @Produce json
+// @Produce json,application/msgpack,application/x-protobuf
 // @Success 200 {object} ResultSuccess "Successful response"
 // @Failure 500 {object} ResultError "Error response"
 // @Router /Status [get]
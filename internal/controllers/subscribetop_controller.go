@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"errors"
+	ac "go-leaderboard-server/internal/appcontext"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SubscribeTopParams struct {
+	GameId string `json:"gameId" uri:"gameId" binding:"required,max=50,alphanum" example:"game1"`  // Id of game (alphanumeric values)
+	NTop   uint32 `json:"nTop" form:"n" binding:"required,min=1,max=100" example:"100"`            // Number of users in top
+	Mode   string `json:"mode" form:"mode" binding:"omitempty,oneof=snapshot diff" example:"diff"` // Updates after the first snapshot: snapshot (default) or diff
+}
+
+// Event sent when the subscription is ended by the server
+type SubscriptionError struct {
+	Error string `json:"error" example:"subscriber doesn't keep up with updates"`
+}
+
+// @Description Streams updates of the top of the game as server-sent events. The first "snapshot" event contains the whole top,
+// @Description then an event is sent on every change of the top: "snapshot" with the whole top or "diff" with new and changed entries and keys of removed ones (mode=diff).
+// @Description "heartbeat" events are sent to idle streams. Subscribers that don't keep up with updates get an "error" event and are disconnected.
+// @Description Only changes made through this server instance are sent
+// @Tags top
+// @Produce text/event-stream
+// @Param gameId path string true "Id of game (alphanumeric values)"
+// @Param n query int true "Number of users in top (1-100)"
+// @Param mode query string false "Updates after the first snapshot: snapshot (default) or diff"
+// @Success 200 {object} services.TopUpdate "Stream of updates"
+// @Failure 400 {object} ResultError "Error response"
+// @Failure 401 {object} ResultError "Error response (missing or wrong api key)"
+// @Failure 403 {object} ResultError "Error response (access denied)"
+// @Failure 429 {object} ResultError "Error response (rate limit exceeded, see Retry-After header)"
+// @Failure 503 {object} ResultError "Error response (too many subscriptions)"
+// @Security ApiKeyAuth
+// @Router /v2/games/{gameId}/top/subscribe [get]
+func SubscribeTopV2Handler(c *gin.Context) {
+	var (
+		ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+		params SubscribeTopParams
+		err    error
+		logger = log.GetLogger()
+	)
+
+	err = bindV2Params(c, &params)
+	if err != nil {
+		logger.Error("Wrong params", log.LogParams{"error": err})
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	err = checkAccess(c, params.GameId, "")
+	if err != nil {
+		logger.Warn("Access denied", log.LogParams{"gameId": params.GameId, "path": c.FullPath()})
+		_ = c.AbortWithError(http.StatusForbidden, err)
+		return
+	}
+
+	params.GameId = getTenantGameId(c, params.GameId)
+
+	sub, err := ac.SubscriptionService.Subscribe(params.GameId, params.NTop, params.Mode == services.TOPUPDATE_DIFF)
+	if err != nil {
+		if errors.Is(err, services.ErrTooManySubscriptions) || errors.Is(err, services.ErrSubscriptionsClosed) {
+			logger.Warn("Subscription rejected", log.LogParams{"error": err, "gameId": params.GameId})
+			_ = c.AbortWithError(http.StatusServiceUnavailable, err)
+			return
+		}
+		logger.Error("Failed to subscribe to top", log.LogParams{"error": err, "gameId": params.GameId})
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // disables buffering of proxies
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(time.Duration(ac.AppConfig.Subscriptions.Heartbeat) * time.Millisecond)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			c.SSEvent("heartbeat", "")
+		case update, ok := <-sub.Updates:
+			if !ok {
+				err = sub.Err()
+				if err == nil {
+					err = services.ErrSubscriptionsClosed
+				}
+				logger.Info("Subscription ended", log.LogParams{"error": err, "gameId": params.GameId})
+				c.SSEvent("error", &SubscriptionError{Error: err.Error()})
+				c.Writer.Flush()
+				return
+			}
+			c.SSEvent(update.Type, &update)
+			heartbeat.Reset(time.Duration(ac.AppConfig.Subscriptions.Heartbeat) * time.Millisecond)
+		}
+		c.Writer.Flush()
+	}
+}
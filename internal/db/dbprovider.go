@@ -1,6 +1,15 @@
 package dbprovider
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var ErrAuditConflict = errors.New("audit entry with the same sequence number already exists")
+var ErrChangeConflict = errors.New("change with the same version already exists")
 
 type UScoreType float64
 
@@ -8,6 +17,9 @@ type UserProperties struct {
 	Score  UScoreType `json:"score" bson:"sc" dynamodbav:"sc"`
 	Name   string     `json:"name,omitempty" bson:"nm,omitempty" dynamodbav:"nm"`
 	Params string     `json:"params,omitempty" bson:"pl,omitempty" dynamodbav:"pl"`
+	Base   UScoreType `json:"-" bson:"bs" dynamodbav:"bs"` // Submitted score (before decay)
+	Ts     int64      `json:"-" bson:"ts" dynamodbav:"ts"` // Time of the last score submission (unix ms)
+	Exp    int64      `json:"-" bson:"-" dynamodbav:"-"`   // Expiration time of the entry (unix ms, 0 - never), not returned by reads
 }
 
 type UserData struct {
@@ -17,6 +29,103 @@ type UserData struct {
 
 type TopData []UserData
 
+type TopFields uint8
+
+const (
+	TOPFIELD_NAME   TopFields = 1 << iota // Name of the user
+	TOPFIELD_PARAMS                       // Params of the entry
+
+	TOPFIELDS_OPTIONAL = TOPFIELD_NAME | TOPFIELD_PARAMS // Fields that can be omitted
+)
+
+type TopOptions struct {
+	MinTs      int64     // Entries with the last submission time earlier than this are skipped (unix ms, 0 - no filter)
+	OmitFields TopFields // Fields that are not read and left empty (0 - all fields are read), only userId, score and ts are read for sure otherwise
+}
+
+type RunProperties struct {
+	RunId  string     `json:"runId" bson:"-" dynamodbav:"rId"`
+	Score  UScoreType `json:"score" bson:"sc" dynamodbav:"sc"`
+	Name   string     `json:"name,omitempty" bson:"nm,omitempty" dynamodbav:"nm"`
+	Params string     `json:"params,omitempty" bson:"pl,omitempty" dynamodbav:"pl"`
+	Ts     int64      `json:"ts" bson:"ts" dynamodbav:"ts"` // Time of the run submission (unix ms)
+}
+
+type RunData struct {
+	UserId        string `json:"userId" bson:"uId" dynamodbav:"uId" binding:"required"`
+	RunProperties `bson:",inline"`
+}
+
+type RunTopData []RunData
+
+type UserState int
+
+const (
+	USERSTATE_VISIBLE      UserState = iota // Entries are shown to everyone
+	USERSTATE_SHADOWBANNED                  // Entries are hidden from everyone except the user
+	USERSTATE_BANNED                        // Entries are hidden and new submissions are rejected
+)
+
+// Score submission held for review by a moderator
+type QuarantineItem struct {
+	Id       string     `json:"id" bson:"-" dynamodbav:"qId"`                           // Id of the item (ids are ordered by creation time)
+	UserId   string     `json:"userId" bson:"uId" dynamodbav:"uId"`                     // Id of user
+	Rule     string     `json:"rule" bson:"rl" dynamodbav:"rl"`                         // Name of the violated rule
+	Reason   string     `json:"reason" bson:"rs" dynamodbav:"rs"`                       // Reason of the violation
+	Score    UScoreType `json:"score" bson:"sc" dynamodbav:"sc"`                        // Submitted score
+	Name     string     `json:"name,omitempty" bson:"nm,omitempty" dynamodbav:"nm"`     // Submitted user name
+	Params   string     `json:"params,omitempty" bson:"pl,omitempty" dynamodbav:"pl"`   // Submitted payload
+	RunId    string     `json:"runId,omitempty" bson:"rId,omitempty" dynamodbav:"rId"`  // Id of run (runs boards only)
+	Duration uint32     `json:"duration,omitempty" bson:"du,omitempty" dynamodbav:"du"` // Submitted match duration (ms)
+	Ts       int64      `json:"ts" bson:"ts" dynamodbav:"ts"`                           // Time of the submission (unix ms)
+}
+
+// Entry of the audit log, every entry contains the hash of the previous one
+type AuditEntry struct {
+	Seq       uint64 `json:"seq" bson:"_id" dynamodbav:"seq"`                   // Sequence number of the entry (starts from 1)
+	Ts        int64  `json:"ts" bson:"ts" dynamodbav:"ts"`                      // Time of the operation (unix ms)
+	Actor     string `json:"actor" bson:"ac" dynamodbav:"ac"`                   // Who made the operation (API key id or token subject)
+	Action    string `json:"action" bson:"an" dynamodbav:"an"`                  // Operation
+	Tenant    string `json:"tenant,omitempty" bson:"tn" dynamodbav:"tn"`        // Id of tenant of the game (empty - default tenant)
+	GameId    string `json:"gameId" bson:"gId" dynamodbav:"gId"`                // Id of game
+	UserId    string `json:"userId,omitempty" bson:"uId" dynamodbav:"uId"`      // Id of user
+	Before    string `json:"before,omitempty" bson:"bf" dynamodbav:"bf"`        // Affected data before the operation (JSON)
+	After     string `json:"after,omitempty" bson:"af" dynamodbav:"af"`         // Affected data after the operation (JSON)
+	RequestId string `json:"requestId,omitempty" bson:"rqId" dynamodbav:"rqId"` // Id of the request
+	PrevHash  string `json:"prevHash" bson:"ph" dynamodbav:"ph"`                // Hash of the previous entry (empty for the first one)
+	Hash      string `json:"hash" bson:"hs" dynamodbav:"hs"`                    // HMAC-SHA256 of the previous hash and the other fields with the audit key (hex)
+}
+
+const (
+	CHANGEOP_PUT    = "put"    // Score of the user or a run is stored
+	CHANGEOP_DELETE = "delete" // Score of the user or all runs of the user are deleted
+)
+
+// Change of a board recorded to its change feed
+type ChangeEntry struct {
+	Version uint64     `json:"version" bson:"-" dynamodbav:"v"`                       // Version of the board after the change (starts from 1)
+	Op      string     `json:"op" bson:"op" dynamodbav:"op"`                          // CHANGEOP_*
+	UserId  string     `json:"userId" bson:"uId" dynamodbav:"uId"`                    // Id of user
+	RunId   string     `json:"runId,omitempty" bson:"rId,omitempty" dynamodbav:"rId"` // Id of the stored run (runs boards only)
+	Score   UScoreType `json:"score" bson:"sc" dynamodbav:"sc"`                       // Stored score (0 for deletes)
+	Name    string     `json:"name,omitempty" bson:"nm,omitempty" dynamodbav:"nm"`
+	Params  string     `json:"params,omitempty" bson:"pl,omitempty" dynamodbav:"pl"`
+	Ts      int64      `json:"ts" bson:"ts" dynamodbav:"ts"` // Time of the change (unix ms)
+}
+
+// Score submission event kept in the outbox until it is published to the event sink
+type ScoreEvent struct {
+	Id       string     `json:"id" bson:"_id" dynamodbav:"eId"`                        // Id of the event assigned by the outbox (ids are ordered by storing)
+	GameId   string     `json:"gameId" bson:"gId" dynamodbav:"gId"`                    // Id of game
+	UserId   string     `json:"userId" bson:"uId" dynamodbav:"uId"`                    // Id of user
+	RunId    string     `json:"runId,omitempty" bson:"rId,omitempty" dynamodbav:"rId"` // Id of the submitted run (runs boards only)
+	OldScore UScoreType `json:"oldScore" bson:"os" dynamodbav:"os"`                    // Score before the submission (0 - no score)
+	NewScore UScoreType `json:"newScore" bson:"ns" dynamodbav:"ns"`                    // Score after the submission (the best run for runs boards)
+	OldRank  int        `json:"oldRank" bson:"or" dynamodbav:"or"`                     // Rank before the submission (0 - not ranked or below the tracked depth)
+	NewRank  int        `json:"newRank" bson:"nr" dynamodbav:"nr"`                     // Rank after the submission (0 - below the tracked depth)
+	Ts       int64      `json:"ts" bson:"ts" dynamodbav:"ts"`                          // Time of the submission (unix ms)
+}
+
 type DBProviderBaseConfig struct {
 	IsDebug bool // Debug flag
 }
@@ -29,11 +138,115 @@ type IDBProviderConfig interface {
 	GetBaseConfig() *DBProviderBaseConfig
 }
 
+// Changes of a board recorded to its change feed and the event of a submission stored to the outbox
+// in the same transaction as the write that makes them
+type WriteRecords struct {
+	Version uint64        // Version of the first recorded change (0 - changes aren't recorded)
+	Changes []ChangeEntry // Changes made by the write, they get versions in order starting from Version
+	Event   *ScoreEvent   // Event stored to the outbox, its id is assigned from the sequence of the outbox (nil - none)
+	Ts      int64         // Time of the changes (unix ms)
+}
+
+// Returns the id of the outbox event with the sequence number (ids of the sequence are ordered as strings)
+func OutboxEventId(seq uint64) string {
+	return fmt.Sprintf("%020d", seq)
+}
+
+// Returns the changes of the records followed by the changes the write made itself (deletes of removed entries),
+// with their versions and time assigned (nil - changes aren't recorded)
+func (rec WriteRecords) Entries(made ...ChangeEntry) []ChangeEntry {
+	if rec.Version == 0 {
+		return nil
+	}
+	changes := append(slices.Clone(rec.Changes), made...)
+	for i := range changes {
+		changes[i].Version = rec.Version + uint64(i)
+		changes[i].Ts = rec.Ts
+	}
+	return changes
+}
+
+// Writes of boards record rec in the same transaction as the write and fail with ErrChangeConflict if rec.Version
+// is taken already (the board was changed after the version was read), nothing is written then
 type IDbProvider interface {
 	Initialize(ctx context.Context, config IDBProviderConfig) error
-	Put(ctx context.Context, gameId string, userId string, userProp UserProperties) error
-	Delete(ctx context.Context, gameId string, userId string) error
+	Put(ctx context.Context, gameId string, userId string, userProp UserProperties, rec WriteRecords) error
+	Delete(ctx context.Context, gameId string, userId string, rec WriteRecords) error
 	Get(ctx context.Context, gameId string, userId string) (*UserProperties, error)
-	Top(ctx context.Context, gameId string, nTop uint32) (TopData, error)
+	Top(ctx context.Context, gameId string, nTop uint32, opts TopOptions) (TopData, error)
+	// Calls fn for every entry of the game with the last submission time earlier than before (unix ms) and the score above
+	// the specified one (entries already decayed to the floor are skipped). Entries are read by pages of INACTIVE_PAGE_SIZE
+	Inactive(ctx context.Context, gameId string, before int64, above UScoreType, fn func(UserData) error) error
+	// Updates the score of the entry only if its last submission time is still equal to ts (rec is recorded only then).
+	// Returns whether the score is updated
+	SetScore(ctx context.Context, gameId string, userId string, score UScoreType, ts int64, rec WriteRecords) (bool, error)
+	// Removes entries of the game with the last submission time earlier than before (unix ms), recording a delete
+	// of every removed entry (rec.Changes aren't used). Providers with native expiry rely on the entry expiration time instead and may do nothing
+	// here unless changes are recorded. Returns the number of removed entries
+	Expire(ctx context.Context, gameId string, before int64, rec WriteRecords) (uint32, error)
+	// Removes entries of the game that are ranked below maxEntries, recording a delete of every removed entry
+	// (rec.Changes aren't used). Returns the number of removed entries
+	Trim(ctx context.Context, gameId string, maxEntries uint32, rec WriteRecords) (uint32, error)
+	// Returns the number of entries of the game
+	Count(ctx context.Context, gameId string) (uint64, error)
+	// Stores a run of the user keeping only maxRuns best runs of the user. Deletes of evicted runs are recorded after
+	// rec.Changes, returns the number of evicted runs
+	PutRun(ctx context.Context, gameId string, userId string, run RunProperties, maxRuns uint32, rec WriteRecords) (uint32, error)
+	DeleteRuns(ctx context.Context, gameId string, userId string, rec WriteRecords) error
+	// Returns runs of the user sorted in descending order of score
+	GetRuns(ctx context.Context, gameId string, userId string) ([]RunProperties, error)
+	TopRuns(ctx context.Context, gameId string, nTop uint32) (RunTopData, error)
+	// Returns the number of runs of all users of the game
+	CountRuns(ctx context.Context, gameId string) (uint64, error)
+	// Sets the visibility state of the user. Entries of not visible users are skipped by Top and TopRuns
+	SetUserState(ctx context.Context, gameId string, userId string, state UserState, rec WriteRecords) error
+	GetUserState(ctx context.Context, gameId string, userId string) (UserState, error)
+	// Stores a submission held for review, quarantined submissions don't affect the board
+	PutQuarantined(ctx context.Context, gameId string, item QuarantineItem) error
+	GetQuarantined(ctx context.Context, gameId string, id string) (*QuarantineItem, error)
+	// Returns up to limit quarantined submissions of the game in ascending order of id
+	ListQuarantined(ctx context.Context, gameId string, limit uint32) ([]QuarantineItem, error)
+	// Removes the quarantined submission and returns it (nil - not found), so only one of concurrent callers gets it
+	DeleteQuarantined(ctx context.Context, gameId string, id string) (*QuarantineItem, error)
+	// Appends an entry to the audit log, returns ErrAuditConflict if an entry with the same sequence number exists
+	PutAuditEntry(ctx context.Context, entry AuditEntry) error
+	// Returns up to limit audit entries with sequence numbers starting from fromSeq in ascending order
+	ListAuditEntries(ctx context.Context, fromSeq uint64, limit uint32) ([]AuditEntry, error)
+	// Returns the audit entry with the highest sequence number (nil - the log is empty)
+	LastAuditEntry(ctx context.Context) (*AuditEntry, error)
+	// Returns up to limit changes of the game with versions starting from fromVersion in ascending order
+	ListChanges(ctx context.Context, gameId string, fromVersion uint64, limit uint32) ([]ChangeEntry, error)
+	// Returns the change of the game with the highest version (nil - no changes)
+	LastChange(ctx context.Context, gameId string) (*ChangeEntry, error)
+	// Removes changes of the game with versions lower than beforeVersion
+	TrimChanges(ctx context.Context, gameId string, beforeVersion uint64) error
+	// Returns up to limit events of the outbox in ascending order of ids
+	ListOutboxEvents(ctx context.Context, limit uint32) ([]ScoreEvent, error)
+	// Removes the published event from the outbox
+	DeleteOutboxEvent(ctx context.Context, id string) error
 	Shutdown(ctx context.Context) error
 }
+
+const TENANT_SEPARATOR = "/"
+
+const ALL_TENANTS = "*" // Tenant filter of lists matching the data of all tenants
+
+const INACTIVE_PAGE_SIZE = 100 // Number of entries read at once by Inactive
+
+// Returns the id under which the game of the tenant is stored. Games of the default tenant (empty id) keep their
+// ids, games of other tenants are prefixed with the tenant id, so tenants never share keys, rows or partitions
+func TenantGameId(tenant string, gameId string) string {
+	if tenant == "" {
+		return gameId
+	}
+	return tenant + TENANT_SEPARATOR + gameId
+}
+
+// Returns the tenant and the gameId of the tenant the stored id belongs to, reverse of TenantGameId
+func SplitTenantGameId(id string) (string, string) {
+	tenant, gameId, found := strings.Cut(id, TENANT_SEPARATOR)
+	if !found {
+		return "", id
+	}
+	return tenant, gameId
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	dbprovider "go-leaderboard-server/internal/db"
 	log "go-leaderboard-server/internal/logger"
+	"math"
 	"sort"
 	"strconv"
 	"sync"
@@ -32,6 +33,22 @@ type DynamoProviderConfig struct {
 
 const DBTABLE_NAME string = "Leaderboard"
 const DBTABLE_INDEX_NAME string = "ScoreIndex"
+const DBTABLE_RUNS_NAME string = "LeaderboardRuns"
+const DBTABLE_RUNS_INDEX_NAME string = "ScoreIndex"
+const DBTABLE_STATES_NAME string = "LeaderboardStates"
+const DBTABLE_QUARANTINE_NAME string = "LeaderboardQuarantine"
+const DBTABLE_AUDIT_NAME string = "LeaderboardAudit"
+const DBTABLE_CHANGES_NAME string = "LeaderboardChanges"
+const DBTABLE_OUTBOX_NAME string = "LeaderboardOutbox"
+
+// All audit entries are kept in one partition, so they can be read in order of sequence numbers
+const auditPartition string = "audit"
+
+// All outbox events are kept in one partition, so they can be read in order of ids
+const outboxPartition string = "outbox"
+
+// Partition of the outbox table keeping the counter of ids of events
+const outboxSeqPartition string = "seq"
 
 type DynamoProvider struct {
 	db      *dynamodb.Client
@@ -56,6 +73,10 @@ func getHashKey(gameId string, userId string, nShards uint32) string {
 	return key
 }
 
+func getRunKey(userId string, runId string) string {
+	return fmt.Sprintf("%s:%s", userId, runId)
+}
+
 func (p *DynamoProvider) Initialize(ctx context.Context, config dbprovider.IDBProviderConfig) error {
 	logger.Debug("DB provider initialization")
 
@@ -80,11 +101,232 @@ func (p *DynamoProvider) Initialize(ctx context.Context, config dbprovider.IDBPr
 	return nil
 }
 
-func (p *DynamoProvider) Put(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties) error {
+// A condition of a write item failed (the entry was changed in the meantime)
+var errEntryChanged = errors.New("entry changed")
+
+// Runs the write items in a transaction together with puts of the changes of rec followed by the changes made by
+// the items (deletes of removed entries) and the outbox event of rec. Writes recording nothing run the item without
+// a transaction. Returns ErrChangeConflict if a version of a change is taken already and errEntryChanged if a
+// condition of an item fails
+func (p *DynamoProvider) transactWrite(ctx context.Context, gameId string, rec dbprovider.WriteRecords, items []types.TransactWriteItem, made ...dbprovider.ChangeEntry) error {
+	changes := rec.Entries(made...)
+	if len(changes) == 0 && len(items) == 1 && rec.Event == nil {
+		return p.writeItem(ctx, items[0])
+	}
+
+	nWrites := len(items)
+	for _, change := range changes {
+		av, err := attributevalue.MarshalMap(change)
+		if err != nil {
+			return err
+		}
+		av["gId"] = &types.AttributeValueMemberS{Value: gameId}
+		items = append(items, types.TransactWriteItem{Put: &types.Put{
+			TableName:           aws.String(DBTABLE_CHANGES_NAME),
+			Item:                av,
+			ConditionExpression: aws.String("attribute_not_exists(v)"),
+		}})
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	if rec.Event == nil {
+		return p.transactItems(ctx, items, nWrites, len(items))
+	}
+
+	// the id of the event is the next value of the counter, the put of the counter fails if another write took
+	// the value in the meantime, then the write is retried with the next one
+	nRecords := len(items)
+	for {
+		seqItems, err := p.outboxEventItems(ctx, *rec.Event)
+		if err != nil {
+			return err
+		}
+		err = p.transactItems(ctx, append(items[:nRecords:nRecords], seqItems...), nWrites, nRecords)
+		if !errors.Is(err, errOutboxSeqTaken) {
+			return err
+		}
+	}
+}
+
+// The id of an outbox event was taken by another write
+var errOutboxSeqTaken = errors.New("outbox sequence taken")
+
+// Runs the items in a transaction. Items below nWrites are writes of entries, below nRecords puts of changes and the
+// rest the counter of outbox ids and the event
+func (p *DynamoProvider) transactItems(ctx context.Context, items []types.TransactWriteItem, nWrites int, nRecords int) error {
+	_, err := p.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
+	if err != nil {
+		var tcErr *types.TransactionCanceledException
+		if errors.As(err, &tcErr) {
+			for i, reason := range tcErr.CancellationReasons {
+				if aws.ToString(reason.Code) != "ConditionalCheckFailed" {
+					continue
+				}
+				if i < nWrites {
+					return errEntryChanged
+				}
+				if i < nRecords {
+					return dbprovider.ErrChangeConflict
+				}
+				return errOutboxSeqTaken
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Returns the items storing the event to the outbox with the next id of the counter
+func (p *DynamoProvider) outboxEventItems(ctx context.Context, event dbprovider.ScoreEvent) ([]types.TransactWriteItem, error) {
+	seqKey := map[string]types.AttributeValue{
+		"ob":  &types.AttributeValueMemberS{Value: outboxSeqPartition},
+		"eId": &types.AttributeValueMemberS{Value: outboxPartition},
+	}
+	result, err := p.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(DBTABLE_OUTBOX_NAME),
+		Key:            seqKey,
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var counter struct {
+		Seq uint64 `dynamodbav:"seq"`
+	}
+	condition := aws.String("attribute_not_exists(#seq)")
+	var values map[string]types.AttributeValue
+	if result.Item != nil {
+		err = attributevalue.UnmarshalMap(result.Item, &counter)
+		if err != nil {
+			return nil, err
+		}
+		condition = aws.String("#seq = :seq")
+		values = map[string]types.AttributeValue{
+			":seq": &types.AttributeValueMemberN{Value: strconv.FormatUint(counter.Seq, 10)},
+		}
+	}
+
+	seqItem := map[string]types.AttributeValue{
+		"ob":  seqKey["ob"],
+		"eId": seqKey["eId"],
+		"seq": &types.AttributeValueMemberN{Value: strconv.FormatUint(counter.Seq+1, 10)},
+	}
+
+	event.Id = dbprovider.OutboxEventId(counter.Seq + 1)
+	av, err := attributevalue.MarshalMap(event)
+	if err != nil {
+		return nil, err
+	}
+	av["ob"] = &types.AttributeValueMemberS{Value: outboxPartition}
+
+	return []types.TransactWriteItem{
+		{Put: &types.Put{
+			TableName:                 aws.String(DBTABLE_OUTBOX_NAME),
+			Item:                      seqItem,
+			ConditionExpression:       condition,
+			ExpressionAttributeNames:  map[string]string{"#seq": "seq"},
+			ExpressionAttributeValues: values,
+		}},
+		{Put: &types.Put{
+			TableName: aws.String(DBTABLE_OUTBOX_NAME),
+			Item:      av,
+		}},
+	}, nil
+}
+
+// Runs the write item on its own, returns errEntryChanged if its condition fails
+func (p *DynamoProvider) writeItem(ctx context.Context, item types.TransactWriteItem) error {
+	var err error
+	switch {
+	case item.Put != nil:
+		_, err = p.db.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName:                 item.Put.TableName,
+			Item:                      item.Put.Item,
+			ConditionExpression:       item.Put.ConditionExpression,
+			ExpressionAttributeNames:  item.Put.ExpressionAttributeNames,
+			ExpressionAttributeValues: item.Put.ExpressionAttributeValues,
+		})
+	case item.Delete != nil:
+		_, err = p.db.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName:                 item.Delete.TableName,
+			Key:                       item.Delete.Key,
+			ConditionExpression:       item.Delete.ConditionExpression,
+			ExpressionAttributeNames:  item.Delete.ExpressionAttributeNames,
+			ExpressionAttributeValues: item.Delete.ExpressionAttributeValues,
+		})
+	case item.Update != nil:
+		_, err = p.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:                 item.Update.TableName,
+			Key:                       item.Update.Key,
+			UpdateExpression:          item.Update.UpdateExpression,
+			ConditionExpression:       item.Update.ConditionExpression,
+			ExpressionAttributeNames:  item.Update.ExpressionAttributeNames,
+			ExpressionAttributeValues: item.Update.ExpressionAttributeValues,
+		})
+	}
+	if err != nil {
+		var ccfErr *types.ConditionalCheckFailedException
+		if errors.As(err, &ccfErr) {
+			return errEntryChanged
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Removes the entries one by one, every removal is a transaction of its own recording the delete of the entry.
+// The condition of an entry (if any) is checked by its removal, entries failing it are kept.
+// Returns the number of removed entries
+func (p *DynamoProvider) removeEntries(ctx context.Context, gameId string, keys []map[string]types.AttributeValue,
+	condition *string, values map[string]types.AttributeValue, rec dbprovider.WriteRecords) (uint32, error) {
+	var removed uint32
+	for _, key := range keys {
+		var userId string
+		err := attributevalue.Unmarshal(key["uId"], &userId)
+		if err != nil {
+			return removed, err
+		}
+
+		item := types.TransactWriteItem{Delete: &types.Delete{
+			TableName:                 aws.String(DBTABLE_NAME),
+			Key:                       key,
+			ConditionExpression:       condition,
+			ExpressionAttributeNames:  map[string]string{"#ts": "ts"},
+			ExpressionAttributeValues: values,
+		}}
+		if condition == nil {
+			item.Delete.ExpressionAttributeNames = nil
+		}
+
+		batch := dbprovider.WriteRecords{Ts: rec.Ts}
+		if rec.Version != 0 {
+			batch.Version = rec.Version + uint64(removed)
+		}
+		err = p.transactWrite(ctx, gameId, batch, []types.TransactWriteItem{item},
+			dbprovider.ChangeEntry{Op: dbprovider.CHANGEOP_DELETE, UserId: userId})
+		if errors.Is(err, errEntryChanged) {
+			continue // the entry was submitted again in the meantime
+		}
+		if err != nil {
+			return removed, err
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+func (p *DynamoProvider) Put(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties, rec dbprovider.WriteRecords) error {
 	item := map[string]types.AttributeValue{
 		"gId": &types.AttributeValueMemberS{Value: getHashKey(gameId, userId, p.nShards)},
 		"uId": &types.AttributeValueMemberS{Value: userId},
 		"sc":  &types.AttributeValueMemberN{Value: strconv.FormatFloat(float64(userProp.Score), 'f', -1, 64)},
+		"bs":  &types.AttributeValueMemberN{Value: strconv.FormatFloat(float64(userProp.Base), 'f', -1, 64)},
+		"ts":  &types.AttributeValueMemberN{Value: strconv.FormatInt(userProp.Ts, 10)},
 	}
 
 	if userProp.Name != "" {
@@ -93,33 +335,25 @@ func (p *DynamoProvider) Put(ctx context.Context, gameId string, userId string,
 	if userProp.Params != "" {
 		item["pl"] = &types.AttributeValueMemberS{Value: userProp.Params}
 	}
-
-	_, err := p.db.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(DBTABLE_NAME),
-		Item:      item,
-	})
-	if err != nil {
-		return err
+	if userProp.Exp != 0 {
+		// TTL attribute requires Unix epoch time format in seconds
+		item["ex"] = &types.AttributeValueMemberN{Value: strconv.FormatInt((userProp.Exp+999)/1000, 10)}
 	}
 
-	return nil
+	return p.transactWrite(ctx, gameId, rec, []types.TransactWriteItem{
+		{Put: &types.Put{TableName: aws.String(DBTABLE_NAME), Item: item}},
+	})
 }
 
-func (p *DynamoProvider) Delete(ctx context.Context, gameId string, userId string) error {
+func (p *DynamoProvider) Delete(ctx context.Context, gameId string, userId string, rec dbprovider.WriteRecords) error {
 	key := map[string]types.AttributeValue{
 		"gId": &types.AttributeValueMemberS{Value: getHashKey(gameId, userId, p.nShards)},
 		"uId": &types.AttributeValueMemberS{Value: userId},
 	}
 
-	_, err := p.db.DeleteItem(ctx, &dynamodb.DeleteItemInput{
-		TableName: aws.String(DBTABLE_NAME),
-		Key:       key,
+	return p.transactWrite(ctx, gameId, rec, []types.TransactWriteItem{
+		{Delete: &types.Delete{TableName: aws.String(DBTABLE_NAME), Key: key}},
 	})
-	if err != nil {
-		return err
-	}
-
-	return nil
 }
 
 func (p *DynamoProvider) Get(ctx context.Context, gameId string, userId string) (*dbprovider.UserProperties, error) {
@@ -150,10 +384,43 @@ func (p *DynamoProvider) Get(ctx context.Context, gameId string, userId string)
 	return &item, nil
 }
 
-func (p *DynamoProvider) Top(ctx context.Context, gameId string, nTop uint32) (dbprovider.TopData, error) {
+func (p *DynamoProvider) Top(ctx context.Context, gameId string, nTop uint32, opts dbprovider.TopOptions) (dbprovider.TopData, error) {
 	type Result struct {
-		result *dynamodb.QueryOutput
-		err    error
+		items []map[string]types.AttributeValue
+		err   error
+	}
+
+	var queryFilter map[string]types.Condition
+	if opts.MinTs != 0 {
+		queryFilter = map[string]types.Condition{
+			"ts": {
+				ComparisonOperator: types.ComparisonOperatorGe,
+				AttributeValueList: []types.AttributeValue{
+					&types.AttributeValueMemberN{Value: strconv.FormatInt(opts.MinTs, 10)},
+				},
+			},
+		}
+	}
+
+	// omitted fields are not read (the legacy parameter is used, as the query filter can't be combined with expressions)
+	var (
+		selectAttrs     types.Select
+		attributesToGet []string
+	)
+	if opts.OmitFields != 0 {
+		selectAttrs = types.SelectSpecificAttributes
+		attributesToGet = []string{"gId", "uId", "sc", "bs", "ts"}
+		if opts.OmitFields&dbprovider.TOPFIELD_NAME == 0 {
+			attributesToGet = append(attributesToGet, "nm")
+		}
+		if opts.OmitFields&dbprovider.TOPFIELD_PARAMS == 0 {
+			attributesToGet = append(attributesToGet, "pl")
+		}
+	}
+
+	hidden, err := p.hiddenUsers(ctx, gameId)
+	if err != nil {
+		return dbprovider.TopData{}, err
 	}
 
 	N := max(p.nShards, 1)
@@ -162,21 +429,44 @@ func (p *DynamoProvider) Top(ctx context.Context, gameId string, nTop uint32) (d
 	var wg sync.WaitGroup
 	QueryAsync := func(idx uint32) {
 		defer wg.Done()
-		result, err := p.db.Query(ctx, &dynamodb.QueryInput{
-			TableName: aws.String(DBTABLE_NAME),
-			IndexName: aws.String(DBTABLE_INDEX_NAME),
-			KeyConditions: map[string]types.Condition{
-				"gId": {
-					ComparisonOperator: types.ComparisonOperatorEq,
-					AttributeValueList: []types.AttributeValue{
-						&types.AttributeValueMemberS{Value: fmt.Sprintf("%s:%d", gameId, idx)},
+
+		// the limit is applied before the filters, so filtered queries may require several pages
+		var (
+			items    []map[string]types.AttributeValue
+			startKey map[string]types.AttributeValue
+		)
+		for {
+			result, err := p.db.Query(ctx, &dynamodb.QueryInput{
+				TableName: aws.String(DBTABLE_NAME),
+				IndexName: aws.String(DBTABLE_INDEX_NAME),
+				KeyConditions: map[string]types.Condition{
+					"gId": {
+						ComparisonOperator: types.ComparisonOperatorEq,
+						AttributeValueList: []types.AttributeValue{
+							&types.AttributeValueMemberS{Value: fmt.Sprintf("%s:%d", gameId, idx)},
+						},
 					},
 				},
-			},
-			ScanIndexForward: aws.Bool(false),
-			Limit:            aws.Int32(int32(nTop)),
-		})
-		resChan <- Result{result, err}
+				QueryFilter:       queryFilter,
+				Select:            selectAttrs,
+				AttributesToGet:   attributesToGet,
+				ScanIndexForward:  aws.Bool(false),
+				Limit:             aws.Int32(int32(nTop)),
+				ExclusiveStartKey: startKey,
+			})
+			if err != nil {
+				resChan <- Result{nil, err}
+				return
+			}
+
+			items = appendVisible(items, result.Items, hidden)
+			if len(items) >= int(nTop) || len(result.LastEvaluatedKey) == 0 {
+				break
+			}
+			startKey = result.LastEvaluatedKey
+		}
+
+		resChan <- Result{items[:min(len(items), int(nTop))], nil}
 	}
 
 	for i := uint32(0); i < N; i++ {
@@ -186,20 +476,20 @@ func (p *DynamoProvider) Top(ctx context.Context, gameId string, nTop uint32) (d
 
 	wg.Wait()
 
-	results := make([]*dynamodb.QueryOutput, N)
+	results := make([][]map[string]types.AttributeValue, N)
 	var nitems = 0
 	for i := range results {
 		res := <-resChan
 		if res.err != nil {
 			return dbprovider.TopData{}, res.err
 		}
-		results[i] = res.result
-		nitems += len(res.result.Items)
+		results[i] = res.items
+		nitems += len(res.items)
 	}
 
 	uscores := make(dbprovider.TopData, 0, nitems)
 	for _, result := range results {
-		for _, item := range result.Items {
+		for _, item := range result {
 			var udata dbprovider.UserData
 			err := attributevalue.UnmarshalMap(item, &udata)
 			if err != nil {
@@ -219,6 +509,964 @@ func (p *DynamoProvider) Top(ctx context.Context, gameId string, nTop uint32) (d
 	return top, nil
 }
 
+func (p *DynamoProvider) Inactive(ctx context.Context, gameId string, before int64, above dbprovider.UScoreType, fn func(dbprovider.UserData) error) error {
+	N := max(p.nShards, 1)
+	for i := uint32(0); i < N; i++ {
+		var startKey map[string]types.AttributeValue
+		for {
+			result, err := p.db.Query(ctx, &dynamodb.QueryInput{
+				TableName: aws.String(DBTABLE_NAME),
+				KeyConditions: map[string]types.Condition{
+					"gId": {
+						ComparisonOperator: types.ComparisonOperatorEq,
+						AttributeValueList: []types.AttributeValue{
+							&types.AttributeValueMemberS{Value: fmt.Sprintf("%s:%d", gameId, i)},
+						},
+					},
+				},
+				QueryFilter: map[string]types.Condition{
+					"ts": {
+						ComparisonOperator: types.ComparisonOperatorLt,
+						AttributeValueList: []types.AttributeValue{
+							&types.AttributeValueMemberN{Value: strconv.FormatInt(before, 10)},
+						},
+					},
+					"sc": {
+						ComparisonOperator: types.ComparisonOperatorGt,
+						AttributeValueList: []types.AttributeValue{
+							&types.AttributeValueMemberN{Value: strconv.FormatFloat(float64(above), 'f', -1, 64)},
+						},
+					},
+				},
+				Limit:             aws.Int32(dbprovider.INACTIVE_PAGE_SIZE),
+				ExclusiveStartKey: startKey,
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, item := range result.Items {
+				var udata dbprovider.UserData
+				err := attributevalue.UnmarshalMap(item, &udata)
+				if err != nil {
+					return err
+				}
+				err = fn(udata)
+				if err != nil {
+					return err
+				}
+			}
+
+			if len(result.LastEvaluatedKey) == 0 {
+				break
+			}
+			startKey = result.LastEvaluatedKey
+		}
+	}
+
+	return nil
+}
+
+func (p *DynamoProvider) SetScore(ctx context.Context, gameId string, userId string, score dbprovider.UScoreType, ts int64, rec dbprovider.WriteRecords) (bool, error) {
+	key := map[string]types.AttributeValue{
+		"gId": &types.AttributeValueMemberS{Value: getHashKey(gameId, userId, p.nShards)},
+		"uId": &types.AttributeValueMemberS{Value: userId},
+	}
+
+	err := p.transactWrite(ctx, gameId, rec, []types.TransactWriteItem{{Update: &types.Update{
+		TableName:                aws.String(DBTABLE_NAME),
+		Key:                      key,
+		UpdateExpression:         aws.String("SET sc = :sc"),
+		ConditionExpression:      aws.String("#ts = :ts"),
+		ExpressionAttributeNames: map[string]string{"#ts": "ts"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":sc": &types.AttributeValueMemberN{Value: strconv.FormatFloat(float64(score), 'f', -1, 64)},
+			":ts": &types.AttributeValueMemberN{Value: strconv.FormatInt(ts, 10)},
+		},
+	}}})
+	if errors.Is(err, errEntryChanged) {
+		return false, nil // the entry was updated or removed in the meantime
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Removes entries submitted before the time. DynamoDB TTL on the ex attribute removes them as well if it's enabled,
+// but it may lag behind for days and isn't enabled by table creation
+func (p *DynamoProvider) Expire(ctx context.Context, gameId string, before int64, rec dbprovider.WriteRecords) (uint32, error) {
+	beforeValue := &types.AttributeValueMemberN{Value: strconv.FormatInt(before, 10)}
+
+	var removed uint32
+	N := max(p.nShards, 1)
+	for i := uint32(0); i < N; i++ {
+		var startKey map[string]types.AttributeValue
+		for {
+			result, err := p.db.Query(ctx, &dynamodb.QueryInput{
+				TableName: aws.String(DBTABLE_NAME),
+				IndexName: aws.String(DBTABLE_INDEX_NAME),
+				KeyConditions: map[string]types.Condition{
+					"gId": {
+						ComparisonOperator: types.ComparisonOperatorEq,
+						AttributeValueList: []types.AttributeValue{
+							&types.AttributeValueMemberS{Value: fmt.Sprintf("%s:%d", gameId, i)},
+						},
+					},
+				},
+				QueryFilter: map[string]types.Condition{
+					"ts": {
+						ComparisonOperator: types.ComparisonOperatorLt,
+						AttributeValueList: []types.AttributeValue{beforeValue},
+					},
+				},
+				ProjectionExpression: aws.String("gId, uId"),
+				ExclusiveStartKey:    startKey,
+			})
+			if err != nil {
+				return removed, err
+			}
+
+			keys := make([]map[string]types.AttributeValue, 0, len(result.Items))
+			for _, item := range result.Items {
+				keys = append(keys, map[string]types.AttributeValue{"gId": item["gId"], "uId": item["uId"]})
+			}
+			batch := rec
+			if rec.Version != 0 {
+				batch.Version = rec.Version + uint64(removed)
+			}
+			n, err := p.removeEntries(ctx, gameId, keys, aws.String("#ts < :before"),
+				map[string]types.AttributeValue{":before": beforeValue}, batch)
+			removed += n
+			if err != nil {
+				return removed, err
+			}
+
+			if len(result.LastEvaluatedKey) == 0 {
+				break
+			}
+			startKey = result.LastEvaluatedKey
+		}
+	}
+
+	return removed, nil
+}
+
+func (p *DynamoProvider) Trim(ctx context.Context, gameId string, maxEntries uint32, rec dbprovider.WriteRecords) (uint32, error) {
+	type Entry struct {
+		Key   string                `dynamodbav:"gId"`
+		Id    string                `dynamodbav:"uId"`
+		Score dbprovider.UScoreType `dynamodbav:"sc"`
+	}
+
+	N := max(p.nShards, 1)
+	entries := make([]Entry, 0)
+	for i := uint32(0); i < N; i++ {
+		var startKey map[string]types.AttributeValue
+		for {
+			result, err := p.db.Query(ctx, &dynamodb.QueryInput{
+				TableName: aws.String(DBTABLE_NAME),
+				IndexName: aws.String(DBTABLE_INDEX_NAME),
+				KeyConditions: map[string]types.Condition{
+					"gId": {
+						ComparisonOperator: types.ComparisonOperatorEq,
+						AttributeValueList: []types.AttributeValue{
+							&types.AttributeValueMemberS{Value: fmt.Sprintf("%s:%d", gameId, i)},
+						},
+					},
+				},
+				ProjectionExpression: aws.String("gId, uId, sc"),
+				ExclusiveStartKey:    startKey,
+			})
+			if err != nil {
+				return 0, err
+			}
+
+			for _, item := range result.Items {
+				var entry Entry
+				err := attributevalue.UnmarshalMap(item, &entry)
+				if err != nil {
+					return 0, err
+				}
+				entries = append(entries, entry)
+			}
+
+			if len(result.LastEvaluatedKey) == 0 {
+				break
+			}
+			startKey = result.LastEvaluatedKey
+		}
+	}
+
+	if len(entries) <= int(maxEntries) {
+		return 0, nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[j].Score < entries[i].Score
+	})
+
+	evicted := entries[maxEntries:]
+	keys := make([]map[string]types.AttributeValue, 0, len(evicted))
+	for _, entry := range evicted {
+		keys = append(keys, map[string]types.AttributeValue{
+			"gId": &types.AttributeValueMemberS{Value: entry.Key},
+			"uId": &types.AttributeValueMemberS{Value: entry.Id},
+		})
+	}
+
+	if rec.Version != 0 {
+		return p.removeEntries(ctx, gameId, keys, nil, nil, rec)
+	}
+
+	err := p.batchDelete(ctx, DBTABLE_NAME, keys)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(len(keys)), nil
+}
+
+func (p *DynamoProvider) batchDelete(ctx context.Context, tableName string, keys []map[string]types.AttributeValue) error {
+	const batchSize = 25 // maximum number of requests in BatchWriteItem
+
+	for len(keys) > 0 {
+		batch := keys[:min(len(keys), batchSize)]
+		keys = keys[len(batch):]
+
+		requests := make([]types.WriteRequest, 0, len(batch))
+		for _, key := range batch {
+			requests = append(requests, types.WriteRequest{
+				DeleteRequest: &types.DeleteRequest{Key: key},
+			})
+		}
+
+		for len(requests) > 0 {
+			result, err := p.db.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: map[string][]types.WriteRequest{tableName: requests},
+			})
+			if err != nil {
+				return err
+			}
+			requests = result.UnprocessedItems[tableName]
+		}
+	}
+
+	return nil
+}
+
+func (p *DynamoProvider) queryRuns(ctx context.Context, gameId string, userId string) ([]map[string]types.AttributeValue, error) {
+	items := make([]map[string]types.AttributeValue, 0)
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := p.db.Query(ctx, &dynamodb.QueryInput{
+			TableName: aws.String(DBTABLE_RUNS_NAME),
+			KeyConditions: map[string]types.Condition{
+				"gId": {
+					ComparisonOperator: types.ComparisonOperatorEq,
+					AttributeValueList: []types.AttributeValue{
+						&types.AttributeValueMemberS{Value: getHashKey(gameId, userId, p.nShards)},
+					},
+				},
+				"rk": {
+					ComparisonOperator: types.ComparisonOperatorBeginsWith,
+					AttributeValueList: []types.AttributeValue{
+						&types.AttributeValueMemberS{Value: getRunKey(userId, "")},
+					},
+				},
+			},
+			ConsistentRead:    aws.Bool(true),
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, result.Items...)
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return items, nil
+}
+
+func (p *DynamoProvider) Count(ctx context.Context, gameId string) (uint64, error) {
+	return p.countItems(ctx, DBTABLE_NAME, gameId)
+}
+
+func (p *DynamoProvider) PutRun(ctx context.Context, gameId string, userId string, run dbprovider.RunProperties, maxRuns uint32, rec dbprovider.WriteRecords) (uint32, error) {
+	hashKey := getHashKey(gameId, userId, p.nShards)
+	item := map[string]types.AttributeValue{
+		"gId": &types.AttributeValueMemberS{Value: hashKey},
+		"rk":  &types.AttributeValueMemberS{Value: getRunKey(userId, run.RunId)},
+		"uId": &types.AttributeValueMemberS{Value: userId},
+		"rId": &types.AttributeValueMemberS{Value: run.RunId},
+		"sc":  &types.AttributeValueMemberN{Value: strconv.FormatFloat(float64(run.Score), 'f', -1, 64)},
+		"ts":  &types.AttributeValueMemberN{Value: strconv.FormatInt(run.Ts, 10)},
+	}
+
+	if run.Name != "" {
+		item["nm"] = &types.AttributeValueMemberS{Value: run.Name}
+	}
+	if run.Params != "" {
+		item["pl"] = &types.AttributeValueMemberS{Value: run.Params}
+	}
+
+	// evicted runs are found before the write, so the put and the removals are written in one transaction
+	stored, err := p.GetRuns(ctx, gameId, userId)
+	if err != nil {
+		return 0, err
+	}
+	runs := make([]dbprovider.RunProperties, 0, len(stored)+1)
+	for _, r := range stored {
+		if r.RunId != run.RunId {
+			runs = append(runs, r)
+		}
+	}
+	runs = append(runs, run)
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[j].Score < runs[i].Score
+	})
+
+	items := []types.TransactWriteItem{{Put: &types.Put{TableName: aws.String(DBTABLE_RUNS_NAME), Item: item}}}
+	evicted := make([]dbprovider.ChangeEntry, 0)
+	for i := int(maxRuns); i < len(runs); i++ {
+		key := map[string]types.AttributeValue{
+			"gId": &types.AttributeValueMemberS{Value: hashKey},
+			"rk":  &types.AttributeValueMemberS{Value: getRunKey(userId, runs[i].RunId)},
+		}
+		if runs[i].RunId == run.RunId {
+			items[0] = types.TransactWriteItem{Delete: &types.Delete{TableName: aws.String(DBTABLE_RUNS_NAME), Key: key}}
+		} else {
+			items = append(items, types.TransactWriteItem{Delete: &types.Delete{TableName: aws.String(DBTABLE_RUNS_NAME), Key: key}})
+		}
+		evicted = append(evicted, dbprovider.ChangeEntry{Op: dbprovider.CHANGEOP_DELETE, UserId: userId, RunId: runs[i].RunId})
+	}
+
+	err = p.transactWrite(ctx, gameId, rec, items, evicted...)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(len(evicted)), nil
+}
+
+func (p *DynamoProvider) DeleteRuns(ctx context.Context, gameId string, userId string, rec dbprovider.WriteRecords) error {
+	items, err := p.queryRuns(ctx, gameId, userId)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]map[string]types.AttributeValue, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, map[string]types.AttributeValue{
+			"gId": item["gId"],
+			"rk":  item["rk"],
+		})
+	}
+
+	if rec.Version == 0 {
+		return p.batchDelete(ctx, DBTABLE_RUNS_NAME, keys)
+	}
+
+	deletes := make([]types.TransactWriteItem, 0, len(keys))
+	for _, key := range keys {
+		deletes = append(deletes, types.TransactWriteItem{Delete: &types.Delete{TableName: aws.String(DBTABLE_RUNS_NAME), Key: key}})
+	}
+
+	return p.transactWrite(ctx, gameId, rec, deletes)
+}
+
+func (p *DynamoProvider) GetRuns(ctx context.Context, gameId string, userId string) ([]dbprovider.RunProperties, error) {
+	items, err := p.queryRuns(ctx, gameId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]dbprovider.RunProperties, 0, len(items))
+	for _, item := range items {
+		var run dbprovider.RunProperties
+		err := attributevalue.UnmarshalMap(item, &run)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[j].Score < runs[i].Score
+	})
+
+	return runs, nil
+}
+
+func (p *DynamoProvider) TopRuns(ctx context.Context, gameId string, nTop uint32) (dbprovider.RunTopData, error) {
+	type Result struct {
+		items []map[string]types.AttributeValue
+		err   error
+	}
+
+	hidden, err := p.hiddenUsers(ctx, gameId)
+	if err != nil {
+		return dbprovider.RunTopData{}, err
+	}
+
+	N := max(p.nShards, 1)
+	resChan := make(chan Result, N)
+
+	var wg sync.WaitGroup
+	QueryAsync := func(idx uint32) {
+		defer wg.Done()
+
+		var (
+			items    []map[string]types.AttributeValue
+			startKey map[string]types.AttributeValue
+		)
+		for {
+			result, err := p.db.Query(ctx, &dynamodb.QueryInput{
+				TableName: aws.String(DBTABLE_RUNS_NAME),
+				IndexName: aws.String(DBTABLE_RUNS_INDEX_NAME),
+				KeyConditions: map[string]types.Condition{
+					"gId": {
+						ComparisonOperator: types.ComparisonOperatorEq,
+						AttributeValueList: []types.AttributeValue{
+							&types.AttributeValueMemberS{Value: fmt.Sprintf("%s:%d", gameId, idx)},
+						},
+					},
+				},
+				ScanIndexForward:  aws.Bool(false),
+				Limit:             aws.Int32(int32(nTop)),
+				ExclusiveStartKey: startKey,
+			})
+			if err != nil {
+				resChan <- Result{nil, err}
+				return
+			}
+
+			items = appendVisible(items, result.Items, hidden)
+			if len(items) >= int(nTop) || len(result.LastEvaluatedKey) == 0 {
+				break
+			}
+			startKey = result.LastEvaluatedKey
+		}
+
+		resChan <- Result{items[:min(len(items), int(nTop))], nil}
+	}
+
+	for i := uint32(0); i < N; i++ {
+		wg.Add(1)
+		go QueryAsync(i)
+	}
+
+	wg.Wait()
+
+	results := make([][]map[string]types.AttributeValue, N)
+	var nitems = 0
+	for i := range results {
+		res := <-resChan
+		if res.err != nil {
+			return dbprovider.RunTopData{}, res.err
+		}
+		results[i] = res.items
+		nitems += len(res.items)
+	}
+
+	runs := make(dbprovider.RunTopData, 0, nitems)
+	for _, result := range results {
+		for _, item := range result {
+			var rdata dbprovider.RunData
+			err := attributevalue.UnmarshalMap(item, &rdata)
+			if err != nil {
+				return dbprovider.RunTopData{}, err
+			}
+			runs = append(runs, rdata)
+		}
+	}
+
+	if N > 1 {
+		sort.Slice(runs, func(i, j int) bool {
+			return runs[j].RunProperties.Score < runs[i].RunProperties.Score
+		})
+	}
+
+	top := runs[:min(len(runs), int(nTop))]
+	return top, nil
+}
+
+// Returns ids of not visible users of the game
+func (p *DynamoProvider) hiddenUsers(ctx context.Context, gameId string) (map[string]bool, error) {
+	hidden := make(map[string]bool)
+	var startKey map[string]types.AttributeValue
+	for {
+		result, err := p.db.Query(ctx, &dynamodb.QueryInput{
+			TableName: aws.String(DBTABLE_STATES_NAME),
+			KeyConditions: map[string]types.Condition{
+				"gId": {
+					ComparisonOperator: types.ComparisonOperatorEq,
+					AttributeValueList: []types.AttributeValue{
+						&types.AttributeValueMemberS{Value: gameId},
+					},
+				},
+			},
+			ProjectionExpression: aws.String("uId"),
+			ExclusiveStartKey:    startKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range result.Items {
+			if uId, ok := item["uId"].(*types.AttributeValueMemberS); ok {
+				hidden[uId.Value] = true
+			}
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return hidden, nil
+}
+
+// Appends items that don't belong to not visible users
+func appendVisible(items []map[string]types.AttributeValue, newItems []map[string]types.AttributeValue, hidden map[string]bool) []map[string]types.AttributeValue {
+	for _, item := range newItems {
+		if uId, ok := item["uId"].(*types.AttributeValueMemberS); ok && hidden[uId.Value] {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func (p *DynamoProvider) CountRuns(ctx context.Context, gameId string) (uint64, error) {
+	return p.countItems(ctx, DBTABLE_RUNS_NAME, gameId)
+}
+
+// Counts items of the game in all shards of the table
+func (p *DynamoProvider) countItems(ctx context.Context, tableName string, gameId string) (uint64, error) {
+	var n uint64
+
+	N := max(p.nShards, 1)
+	for i := uint32(0); i < N; i++ {
+		var startKey map[string]types.AttributeValue
+		for {
+			result, err := p.db.Query(ctx, &dynamodb.QueryInput{
+				TableName: aws.String(tableName),
+				KeyConditions: map[string]types.Condition{
+					"gId": {
+						ComparisonOperator: types.ComparisonOperatorEq,
+						AttributeValueList: []types.AttributeValue{
+							&types.AttributeValueMemberS{Value: fmt.Sprintf("%s:%d", gameId, i)},
+						},
+					},
+				},
+				Select:            types.SelectCount,
+				ExclusiveStartKey: startKey,
+			})
+			if err != nil {
+				return 0, err
+			}
+
+			n += uint64(result.Count)
+
+			if len(result.LastEvaluatedKey) == 0 {
+				break
+			}
+			startKey = result.LastEvaluatedKey
+		}
+	}
+
+	return n, nil
+}
+
+func (p *DynamoProvider) SetUserState(ctx context.Context, gameId string, userId string, state dbprovider.UserState, rec dbprovider.WriteRecords) error {
+	key := map[string]types.AttributeValue{
+		"gId": &types.AttributeValueMemberS{Value: gameId},
+		"uId": &types.AttributeValueMemberS{Value: userId},
+	}
+
+	if state == dbprovider.USERSTATE_VISIBLE {
+		return p.transactWrite(ctx, gameId, rec, []types.TransactWriteItem{
+			{Delete: &types.Delete{TableName: aws.String(DBTABLE_STATES_NAME), Key: key}},
+		})
+	}
+
+	key["st"] = &types.AttributeValueMemberN{Value: strconv.Itoa(int(state))}
+	return p.transactWrite(ctx, gameId, rec, []types.TransactWriteItem{
+		{Put: &types.Put{TableName: aws.String(DBTABLE_STATES_NAME), Item: key}},
+	})
+}
+
+func (p *DynamoProvider) GetUserState(ctx context.Context, gameId string, userId string) (dbprovider.UserState, error) {
+	key := map[string]types.AttributeValue{
+		"gId": &types.AttributeValueMemberS{Value: gameId},
+		"uId": &types.AttributeValueMemberS{Value: userId},
+	}
+
+	result, err := p.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(DBTABLE_STATES_NAME),
+		Key:            key,
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return dbprovider.USERSTATE_VISIBLE, err
+	}
+
+	if result.Item == nil {
+		return dbprovider.USERSTATE_VISIBLE, nil
+	}
+
+	var item struct {
+		State int `dynamodbav:"st"`
+	}
+	err = attributevalue.UnmarshalMap(result.Item, &item)
+	if err != nil {
+		return dbprovider.USERSTATE_VISIBLE, err
+	}
+
+	return dbprovider.UserState(item.State), nil
+}
+
+func (p *DynamoProvider) PutQuarantined(ctx context.Context, gameId string, item dbprovider.QuarantineItem) error {
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return err
+	}
+	av["gId"] = &types.AttributeValueMemberS{Value: gameId}
+
+	_, err = p.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(DBTABLE_QUARANTINE_NAME),
+		Item:      av,
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *DynamoProvider) GetQuarantined(ctx context.Context, gameId string, id string) (*dbprovider.QuarantineItem, error) {
+	key := map[string]types.AttributeValue{
+		"gId": &types.AttributeValueMemberS{Value: gameId},
+		"qId": &types.AttributeValueMemberS{Value: id},
+	}
+
+	result, err := p.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(DBTABLE_QUARANTINE_NAME),
+		Key:            key,
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var item dbprovider.QuarantineItem
+	err = attributevalue.UnmarshalMap(result.Item, &item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (p *DynamoProvider) ListQuarantined(ctx context.Context, gameId string, limit uint32) ([]dbprovider.QuarantineItem, error) {
+	items := make([]dbprovider.QuarantineItem, 0)
+	var startKey map[string]types.AttributeValue
+	for len(items) < int(limit) {
+		result, err := p.db.Query(ctx, &dynamodb.QueryInput{
+			TableName: aws.String(DBTABLE_QUARANTINE_NAME),
+			KeyConditions: map[string]types.Condition{
+				"gId": {
+					ComparisonOperator: types.ComparisonOperatorEq,
+					AttributeValueList: []types.AttributeValue{
+						&types.AttributeValueMemberS{Value: gameId},
+					},
+				},
+			},
+			ScanIndexForward:  aws.Bool(true),
+			Limit:             aws.Int32(int32(int(limit) - len(items))),
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, av := range result.Items {
+			var item dbprovider.QuarantineItem
+			err := attributevalue.UnmarshalMap(av, &item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return items, nil
+}
+
+func (p *DynamoProvider) DeleteQuarantined(ctx context.Context, gameId string, id string) (*dbprovider.QuarantineItem, error) {
+	key := map[string]types.AttributeValue{
+		"gId": &types.AttributeValueMemberS{Value: gameId},
+		"qId": &types.AttributeValueMemberS{Value: id},
+	}
+
+	result, err := p.db.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(DBTABLE_QUARANTINE_NAME),
+		Key:          key,
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Attributes) == 0 {
+		return nil, nil
+	}
+
+	var item dbprovider.QuarantineItem
+	err = attributevalue.UnmarshalMap(result.Attributes, &item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (p *DynamoProvider) PutAuditEntry(ctx context.Context, entry dbprovider.AuditEntry) error {
+	av, err := attributevalue.MarshalMap(entry)
+	if err != nil {
+		return err
+	}
+	av["lg"] = &types.AttributeValueMemberS{Value: auditPartition}
+
+	_, err = p.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(DBTABLE_AUDIT_NAME),
+		Item:      av,
+		Expected: map[string]types.ExpectedAttributeValue{
+			"seq": {Exists: aws.Bool(false)},
+		},
+	})
+	if err != nil {
+		var ccfErr *types.ConditionalCheckFailedException
+		if errors.As(err, &ccfErr) {
+			return dbprovider.ErrAuditConflict
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (p *DynamoProvider) ListAuditEntries(ctx context.Context, fromSeq uint64, limit uint32) ([]dbprovider.AuditEntry, error) {
+	return p.queryAuditEntries(ctx, fromSeq, limit, true)
+}
+
+func (p *DynamoProvider) LastAuditEntry(ctx context.Context) (*dbprovider.AuditEntry, error) {
+	entries, err := p.queryAuditEntries(ctx, 0, 1, false)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+
+	return &entries[0], nil
+}
+
+func (p *DynamoProvider) queryAuditEntries(ctx context.Context, fromSeq uint64, limit uint32, forward bool) ([]dbprovider.AuditEntry, error) {
+	entries := make([]dbprovider.AuditEntry, 0)
+	var startKey map[string]types.AttributeValue
+	for len(entries) < int(limit) {
+		result, err := p.db.Query(ctx, &dynamodb.QueryInput{
+			TableName: aws.String(DBTABLE_AUDIT_NAME),
+			KeyConditions: map[string]types.Condition{
+				"lg": {
+					ComparisonOperator: types.ComparisonOperatorEq,
+					AttributeValueList: []types.AttributeValue{
+						&types.AttributeValueMemberS{Value: auditPartition},
+					},
+				},
+				"seq": {
+					ComparisonOperator: types.ComparisonOperatorGe,
+					AttributeValueList: []types.AttributeValue{
+						&types.AttributeValueMemberN{Value: strconv.FormatUint(fromSeq, 10)},
+					},
+				},
+			},
+			ConsistentRead:    aws.Bool(true),
+			ScanIndexForward:  aws.Bool(forward),
+			Limit:             aws.Int32(int32(int(limit) - len(entries))),
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, av := range result.Items {
+			var entry dbprovider.AuditEntry
+			err := attributevalue.UnmarshalMap(av, &entry)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return entries, nil
+}
+
+func (p *DynamoProvider) ListChanges(ctx context.Context, gameId string, fromVersion uint64, limit uint32) ([]dbprovider.ChangeEntry, error) {
+	return p.queryChanges(ctx, gameId, types.ComparisonOperatorGe, fromVersion, limit, true)
+}
+
+func (p *DynamoProvider) LastChange(ctx context.Context, gameId string) (*dbprovider.ChangeEntry, error) {
+	changes, err := p.queryChanges(ctx, gameId, types.ComparisonOperatorGe, 0, 1, false)
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+
+	return &changes[0], nil
+}
+
+func (p *DynamoProvider) TrimChanges(ctx context.Context, gameId string, beforeVersion uint64) error {
+	changes, err := p.queryChanges(ctx, gameId, types.ComparisonOperatorLt, beforeVersion, math.MaxUint32, true)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]map[string]types.AttributeValue, 0, len(changes))
+	for _, change := range changes {
+		keys = append(keys, map[string]types.AttributeValue{
+			"gId": &types.AttributeValueMemberS{Value: gameId},
+			"v":   &types.AttributeValueMemberN{Value: strconv.FormatUint(change.Version, 10)},
+		})
+	}
+
+	return p.batchDelete(ctx, DBTABLE_CHANGES_NAME, keys)
+}
+
+// Returns up to limit changes of the game with versions matching the operator and the version
+func (p *DynamoProvider) queryChanges(ctx context.Context, gameId string, op types.ComparisonOperator, version uint64, limit uint32, forward bool) ([]dbprovider.ChangeEntry, error) {
+	changes := make([]dbprovider.ChangeEntry, 0)
+	var startKey map[string]types.AttributeValue
+	for len(changes) < int(limit) {
+		result, err := p.db.Query(ctx, &dynamodb.QueryInput{
+			TableName: aws.String(DBTABLE_CHANGES_NAME),
+			KeyConditions: map[string]types.Condition{
+				"gId": {
+					ComparisonOperator: types.ComparisonOperatorEq,
+					AttributeValueList: []types.AttributeValue{
+						&types.AttributeValueMemberS{Value: gameId},
+					},
+				},
+				"v": {
+					ComparisonOperator: op,
+					AttributeValueList: []types.AttributeValue{
+						&types.AttributeValueMemberN{Value: strconv.FormatUint(version, 10)},
+					},
+				},
+			},
+			ConsistentRead:    aws.Bool(true),
+			ScanIndexForward:  aws.Bool(forward),
+			Limit:             aws.Int32(int32(min(int(limit)-len(changes), math.MaxInt32))),
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, av := range result.Items {
+			var change dbprovider.ChangeEntry
+			err := attributevalue.UnmarshalMap(av, &change)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, change)
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return changes, nil
+}
+
+func (p *DynamoProvider) ListOutboxEvents(ctx context.Context, limit uint32) ([]dbprovider.ScoreEvent, error) {
+	events := make([]dbprovider.ScoreEvent, 0)
+	var startKey map[string]types.AttributeValue
+	for len(events) < int(limit) {
+		result, err := p.db.Query(ctx, &dynamodb.QueryInput{
+			TableName: aws.String(DBTABLE_OUTBOX_NAME),
+			KeyConditions: map[string]types.Condition{
+				"ob": {
+					ComparisonOperator: types.ComparisonOperatorEq,
+					AttributeValueList: []types.AttributeValue{
+						&types.AttributeValueMemberS{Value: outboxPartition},
+					},
+				},
+			},
+			ConsistentRead:    aws.Bool(true),
+			Limit:             aws.Int32(int32(min(int(limit)-len(events), math.MaxInt32))),
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, av := range result.Items {
+			var event dbprovider.ScoreEvent
+			err := attributevalue.UnmarshalMap(av, &event)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, event)
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = result.LastEvaluatedKey
+	}
+
+	return events, nil
+}
+
+func (p *DynamoProvider) DeleteOutboxEvent(ctx context.Context, id string) error {
+	key := map[string]types.AttributeValue{
+		"ob":  &types.AttributeValueMemberS{Value: outboxPartition},
+		"eId": &types.AttributeValueMemberS{Value: id},
+	}
+
+	_, err := p.db.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(DBTABLE_OUTBOX_NAME),
+		Key:       key,
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (p *DynamoProvider) Shutdown(ctx context.Context) error {
 	if p.db == nil {
 		return nil
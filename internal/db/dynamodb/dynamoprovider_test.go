@@ -3,6 +3,7 @@ package dynamo_provider
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	dbprovider "go-leaderboard-server/internal/db"
 	"go-leaderboard-server/internal/utils"
@@ -112,6 +113,18 @@ func TestDynamoProvider(t *testing.T) {
 	gameId1 := "game1"
 	gameId2 := "game2"
 	gameId3 := "game3"
+	gameId4 := "game4"
+	gameId5 := "game5"
+	gameId6 := "game6"
+	gameId7 := "game7"
+	gameId8 := "game8"
+	gameId9 := "game9"
+	gameId10 := "game10"
+	gameId11 := "game11"
+	gameId12 := "game12"
+	gameId13 := "game13"
+	gameId14 := "game14"
+	gameId15 := "game15"
 	userId1 := "user1"
 	userId2 := "user2"
 
@@ -142,7 +155,7 @@ func TestDynamoProvider(t *testing.T) {
 			err  error
 		)
 
-		err = dbProvider.Put(context.Background(), gameId2, userId1, userProp1)
+		err = dbProvider.Put(context.Background(), gameId2, userId1, userProp1, dbprovider.WriteRecords{})
 		require.NoError(t, err)
 		data, err = dbProvider.Get(context.Background(), gameId2, userId1)
 		require.NoError(t, err)
@@ -150,13 +163,13 @@ func TestDynamoProvider(t *testing.T) {
 
 		var userProp1Mod = userProp1
 		userProp1Mod.Score = 33
-		err = dbProvider.Put(context.Background(), gameId2, userId1, userProp1Mod)
+		err = dbProvider.Put(context.Background(), gameId2, userId1, userProp1Mod, dbprovider.WriteRecords{})
 		require.NoError(t, err)
 		data, err = dbProvider.Get(context.Background(), gameId2, userId1)
 		require.NoError(t, err)
 		require.Equal(t, userProp1Mod, *data)
 
-		err = dbProvider.Delete(context.Background(), gameId2, userId1)
+		err = dbProvider.Delete(context.Background(), gameId2, userId1, dbprovider.WriteRecords{})
 		require.NoError(t, err)
 		data, err = dbProvider.Get(context.Background(), gameId2, userId1)
 		require.NoError(t, err)
@@ -169,22 +182,663 @@ func TestDynamoProvider(t *testing.T) {
 			err error
 		)
 
-		top, err = dbProvider.Top(context.Background(), gameId3, 10)
+		top, err = dbProvider.Top(context.Background(), gameId3, 10, dbprovider.TopOptions{})
 		require.NoError(t, err)
 		require.Equal(t, dbprovider.TopData{}, top)
 
-		err = dbProvider.Put(context.Background(), gameId3, userId1, userProp1)
+		err = dbProvider.Put(context.Background(), gameId3, userId1, userProp1, dbprovider.WriteRecords{})
 		require.NoError(t, err)
-		err = dbProvider.Put(context.Background(), gameId3, userId2, userProp2)
+		err = dbProvider.Put(context.Background(), gameId3, userId2, userProp2, dbprovider.WriteRecords{})
 		require.NoError(t, err)
 
-		top, err = dbProvider.Top(context.Background(), gameId3, 10)
+		top, err = dbProvider.Top(context.Background(), gameId3, 10, dbprovider.TopOptions{})
 		require.NoError(t, err)
 		require.Equal(t, topData, top)
 
-		top, err = dbProvider.Top(context.Background(), gameId3, 1)
+		top, err = dbProvider.Top(context.Background(), gameId3, 1, dbprovider.TopOptions{})
 		require.NoError(t, err)
 		require.Equal(t, topData[:1], top)
 	})
 
+	runTest(t, "get inactive data and set score", func(t *testing.T, dbProvider *DynamoProvider) {
+		var (
+			data *dbprovider.UserProperties
+			err  error
+		)
+
+		inactive := func(before int64, above dbprovider.UScoreType) []dbprovider.UserData {
+			result := make([]dbprovider.UserData, 0)
+			err := dbProvider.Inactive(context.Background(), gameId4, before, above, func(udata dbprovider.UserData) error {
+				result = append(result, udata)
+				return nil
+			})
+			require.NoError(t, err)
+			return result
+		}
+
+		userProp1Ts := userProp1
+		userProp1Ts.Base = userProp1.Score
+		userProp1Ts.Ts = 1000
+		userProp2Ts := userProp2
+		userProp2Ts.Base = userProp2.Score
+		userProp2Ts.Ts = 2000
+
+		require.Empty(t, inactive(3000, 0))
+
+		err = dbProvider.Put(context.Background(), gameId4, userId1, userProp1Ts, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		err = dbProvider.Put(context.Background(), gameId4, userId2, userProp2Ts, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+
+		require.Empty(t, inactive(1000, 0))
+		require.Equal(t, []dbprovider.UserData{{UserId: userId1, UserProperties: userProp1Ts}}, inactive(2000, 0))
+		require.ElementsMatch(t, []dbprovider.UserData{
+			{UserId: userId1, UserProperties: userProp1Ts},
+			{UserId: userId2, UserProperties: userProp2Ts},
+		}, inactive(3000, 0))
+
+		_, err = dbProvider.SetScore(context.Background(), gameId4, userId1, 5, userProp1Ts.Ts, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		data, err = dbProvider.Get(context.Background(), gameId4, userId1)
+		require.NoError(t, err)
+		userProp1Decayed := userProp1Ts
+		userProp1Decayed.Score = 5
+		require.Equal(t, userProp1Decayed, *data)
+
+		// entries decayed to the floor are skipped
+		require.Equal(t, []dbprovider.UserData{{UserId: userId2, UserProperties: userProp2Ts}}, inactive(3000, 5))
+
+		_, err = dbProvider.SetScore(context.Background(), gameId4, userId2, 5, userProp1Ts.Ts, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		data, err = dbProvider.Get(context.Background(), gameId4, userId2)
+		require.NoError(t, err)
+		require.Equal(t, userProp2Ts, *data)
+
+		// entries updated while they are read are visited once
+		pagedGameId := gameId4 + "paged"
+		nEntries := dbprovider.INACTIVE_PAGE_SIZE*2 + 1
+		for i := 0; i < nEntries; i++ {
+			err = dbProvider.Put(context.Background(), pagedGameId, fmt.Sprintf("user%03d", i), dbprovider.UserProperties{Score: 10, Base: 10, Ts: 1000}, dbprovider.WriteRecords{})
+			require.NoError(t, err)
+		}
+		visited := make(map[string]int)
+		err = dbProvider.Inactive(context.Background(), pagedGameId, 2000, 5, func(udata dbprovider.UserData) error {
+			visited[udata.UserId]++
+			_, err := dbProvider.SetScore(context.Background(), pagedGameId, udata.UserId, 5, udata.Ts, dbprovider.WriteRecords{})
+			return err
+		})
+		require.NoError(t, err)
+		require.Len(t, visited, nEntries)
+		for _, count := range visited {
+			require.Equal(t, 1, count)
+		}
+		err = dbProvider.Inactive(context.Background(), pagedGameId, 2000, 5, func(udata dbprovider.UserData) error {
+			return errors.New("entry at the floor")
+		})
+		require.NoError(t, err)
+	})
+
+	runTest(t, "filter and expire data", func(t *testing.T, dbProvider *DynamoProvider) {
+		var (
+			top dbprovider.TopData
+			err error
+		)
+
+		userProp1Ts := userProp1
+		userProp1Ts.Ts = 1000
+		userProp2Ts := userProp2
+		userProp2Ts.Ts = 2000
+
+		userProp1Exp := userProp1Ts
+		userProp1Exp.Exp = userProp1Ts.Ts + 60000
+		userProp2Exp := userProp2Ts
+		userProp2Exp.Exp = userProp2Ts.Ts + 60000
+
+		err = dbProvider.Put(context.Background(), gameId5, userId1, userProp1Exp, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		err = dbProvider.Put(context.Background(), gameId5, userId2, userProp2Exp, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+
+		top, err = dbProvider.Top(context.Background(), gameId5, 10, dbprovider.TopOptions{MinTs: 1000})
+		require.NoError(t, err)
+		require.ElementsMatch(t, dbprovider.TopData{
+			{UserId: userId1, UserProperties: userProp1Ts},
+			{UserId: userId2, UserProperties: userProp2Ts},
+		}, top)
+
+		top, err = dbProvider.Top(context.Background(), gameId5, 1, dbprovider.TopOptions{MinTs: 1500})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{{UserId: userId2, UserProperties: userProp2Ts}}, top)
+
+		_, err = dbProvider.Expire(context.Background(), gameId5, 1500, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+
+		top, err = dbProvider.Top(context.Background(), gameId5, 10, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{{UserId: userId2, UserProperties: userProp2Ts}}, top)
+	})
+
+	runTest(t, "trim data", func(t *testing.T, dbProvider *DynamoProvider) {
+		var (
+			top  dbprovider.TopData
+			data *dbprovider.UserProperties
+			err  error
+		)
+
+		_, err = dbProvider.Trim(context.Background(), gameId6, 1, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+
+		err = dbProvider.Put(context.Background(), gameId6, userId1, userProp1, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		err = dbProvider.Put(context.Background(), gameId6, userId2, userProp2, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+
+		_, err = dbProvider.Trim(context.Background(), gameId6, 2, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		top, err = dbProvider.Top(context.Background(), gameId6, 10, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Equal(t, topData, top)
+
+		_, err = dbProvider.Trim(context.Background(), gameId6, 1, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		top, err = dbProvider.Top(context.Background(), gameId6, 10, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Equal(t, topData[:1], top)
+
+		data, err = dbProvider.Get(context.Background(), gameId6, userId1)
+		require.NoError(t, err)
+		require.Nil(t, data)
+	})
+
+	runTest(t, "put, get and delete runs", func(t *testing.T, dbProvider *DynamoProvider) {
+		var (
+			runs []dbprovider.RunProperties
+			top  dbprovider.RunTopData
+			err  error
+		)
+
+		run1 := dbprovider.RunProperties{RunId: "run1", Score: 10, Name: "Ted", Ts: 1000}
+		run2 := dbprovider.RunProperties{RunId: "run2", Score: 30, Name: "Ted", Params: "some_payload_2", Ts: 2000}
+		run3 := dbprovider.RunProperties{RunId: "run3", Score: 20, Ts: 3000}
+		run4 := dbprovider.RunProperties{RunId: "run1", Score: 25, Ts: 4000}
+
+		runs, err = dbProvider.GetRuns(context.Background(), gameId7, userId1)
+		require.NoError(t, err)
+		require.Empty(t, runs)
+
+		for _, run := range []dbprovider.RunProperties{run1, run2, run3} {
+			_, err = dbProvider.PutRun(context.Background(), gameId7, userId1, run, 2, dbprovider.WriteRecords{})
+			require.NoError(t, err)
+		}
+		_, err = dbProvider.PutRun(context.Background(), gameId7, userId2, run4, 2, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+
+		runs, err = dbProvider.GetRuns(context.Background(), gameId7, userId1)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.RunProperties{run2, run3}, runs)
+
+		top, err = dbProvider.TopRuns(context.Background(), gameId7, 10)
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.RunTopData{
+			{UserId: userId1, RunProperties: run2},
+			{UserId: userId2, RunProperties: run4},
+			{UserId: userId1, RunProperties: run3},
+		}, top)
+
+		top, err = dbProvider.TopRuns(context.Background(), gameId7, 1)
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.RunTopData{{UserId: userId1, RunProperties: run2}}, top)
+
+		err = dbProvider.DeleteRuns(context.Background(), gameId7, userId1, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		runs, err = dbProvider.GetRuns(context.Background(), gameId7, userId1)
+		require.NoError(t, err)
+		require.Empty(t, runs)
+
+		top, err = dbProvider.TopRuns(context.Background(), gameId7, 10)
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.RunTopData{{UserId: userId2, RunProperties: run4}}, top)
+	})
+
+	runTest(t, "set user state and hide users", func(t *testing.T, dbProvider *DynamoProvider) {
+		var (
+			state dbprovider.UserState
+			top   dbprovider.TopData
+			runs  dbprovider.RunTopData
+			data  *dbprovider.UserProperties
+			err   error
+		)
+
+		run1 := dbprovider.RunProperties{RunId: "run1", Score: 10, Ts: 1000}
+		run2 := dbprovider.RunProperties{RunId: "run2", Score: 20, Ts: 2000}
+
+		state, err = dbProvider.GetUserState(context.Background(), gameId8, userId1)
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.USERSTATE_VISIBLE, state)
+
+		err = dbProvider.Put(context.Background(), gameId8, userId1, userProp1, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		err = dbProvider.Put(context.Background(), gameId8, userId2, userProp2, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		_, err = dbProvider.PutRun(context.Background(), gameId8, userId1, run1, 2, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		_, err = dbProvider.PutRun(context.Background(), gameId8, userId2, run2, 2, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+
+		err = dbProvider.SetUserState(context.Background(), gameId8, userId2, dbprovider.USERSTATE_SHADOWBANNED, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		state, err = dbProvider.GetUserState(context.Background(), gameId8, userId2)
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.USERSTATE_SHADOWBANNED, state)
+
+		top, err = dbProvider.Top(context.Background(), gameId8, 10, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Equal(t, topData[1:], top)
+		top, err = dbProvider.Top(context.Background(), gameId8, 1, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Equal(t, topData[1:], top)
+		runs, err = dbProvider.TopRuns(context.Background(), gameId8, 1)
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.RunTopData{{UserId: userId1, RunProperties: run1}}, runs)
+
+		data, err = dbProvider.Get(context.Background(), gameId8, userId2)
+		require.NoError(t, err)
+		require.Equal(t, userProp2, *data)
+
+		err = dbProvider.SetUserState(context.Background(), gameId8, userId2, dbprovider.USERSTATE_BANNED, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		state, err = dbProvider.GetUserState(context.Background(), gameId8, userId2)
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.USERSTATE_BANNED, state)
+
+		err = dbProvider.SetUserState(context.Background(), gameId8, userId2, dbprovider.USERSTATE_VISIBLE, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		state, err = dbProvider.GetUserState(context.Background(), gameId8, userId2)
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.USERSTATE_VISIBLE, state)
+
+		top, err = dbProvider.Top(context.Background(), gameId8, 10, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Equal(t, topData, top)
+	})
+
+	runTest(t, "put, list and delete quarantined submissions", func(t *testing.T, dbProvider *DynamoProvider) {
+		var (
+			items []dbprovider.QuarantineItem
+			item  *dbprovider.QuarantineItem
+			err   error
+		)
+
+		item1 := dbprovider.QuarantineItem{
+			Id: "0000000001000a", UserId: userId1, Rule: "max_delta", Reason: "too big", Score: 500, Ts: 1000,
+		}
+		item2 := dbprovider.QuarantineItem{
+			Id: "0000000002000b", UserId: userId2, Rule: "score_range", Reason: "too big", Score: 1e10,
+			Name: "name2", Params: "params2", RunId: "run2", Duration: 1500, Ts: 2000,
+		}
+
+		items, err = dbProvider.ListQuarantined(context.Background(), gameId9, 10)
+		require.NoError(t, err)
+		require.Empty(t, items)
+		item, err = dbProvider.GetQuarantined(context.Background(), gameId9, item1.Id)
+		require.NoError(t, err)
+		require.Nil(t, item)
+
+		err = dbProvider.PutQuarantined(context.Background(), gameId9, item2)
+		require.NoError(t, err)
+		err = dbProvider.PutQuarantined(context.Background(), gameId9, item1)
+		require.NoError(t, err)
+
+		item, err = dbProvider.GetQuarantined(context.Background(), gameId9, item2.Id)
+		require.NoError(t, err)
+		require.Equal(t, item2, *item)
+
+		items, err = dbProvider.ListQuarantined(context.Background(), gameId9, 10)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.QuarantineItem{item1, item2}, items)
+		items, err = dbProvider.ListQuarantined(context.Background(), gameId9, 1)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.QuarantineItem{item1}, items)
+
+		// quarantined submissions don't affect the board
+		top, err := dbProvider.Top(context.Background(), gameId9, 10, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Empty(t, top)
+
+		item, err = dbProvider.DeleteQuarantined(context.Background(), gameId9, item1.Id)
+		require.NoError(t, err)
+		require.Equal(t, item1, *item)
+		// the removed submission is returned once
+		item, err = dbProvider.DeleteQuarantined(context.Background(), gameId9, item1.Id)
+		require.NoError(t, err)
+		require.Nil(t, item)
+		items, err = dbProvider.ListQuarantined(context.Background(), gameId9, 10)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.QuarantineItem{item2}, items)
+	})
+
+	runTest(t, "put and list audit entries", func(t *testing.T, dbProvider *DynamoProvider) {
+		var (
+			entries []dbprovider.AuditEntry
+			entry   *dbprovider.AuditEntry
+			err     error
+		)
+
+		entry1 := dbprovider.AuditEntry{
+			Seq: 1, Ts: 1000, Actor: "key:0123456789ab", Action: "delete_score", GameId: "game1", UserId: userId1,
+			Before: `{"score":10}`, RequestId: "req1", Hash: "hash1",
+		}
+		entry2 := dbprovider.AuditEntry{
+			Seq: 2, Ts: 2000, Actor: "sub:admin1", Action: "set_user_state", GameId: "game2", UserId: userId2,
+			Before: `{"state":"visible"}`, After: `{"state":"banned"}`, PrevHash: "hash1", Hash: "hash2",
+		}
+
+		entry, err = dbProvider.LastAuditEntry(context.Background())
+		require.NoError(t, err)
+		require.Nil(t, entry)
+
+		err = dbProvider.PutAuditEntry(context.Background(), entry1)
+		require.NoError(t, err)
+		err = dbProvider.PutAuditEntry(context.Background(), entry2)
+		require.NoError(t, err)
+		err = dbProvider.PutAuditEntry(context.Background(), dbprovider.AuditEntry{Seq: 2, Action: "delete_score", Hash: "other"})
+		require.ErrorIs(t, err, dbprovider.ErrAuditConflict)
+
+		entry, err = dbProvider.LastAuditEntry(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, entry2, *entry)
+
+		entries, err = dbProvider.ListAuditEntries(context.Background(), 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.AuditEntry{entry1, entry2}, entries)
+		entries, err = dbProvider.ListAuditEntries(context.Background(), 2, 10)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.AuditEntry{entry2}, entries)
+		entries, err = dbProvider.ListAuditEntries(context.Background(), 1, 1)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.AuditEntry{entry1}, entries)
+	})
+
+	runTest(t, "isolate games of tenants", func(t *testing.T, dbProvider *DynamoProvider) {
+		tenantGameId1 := dbprovider.TenantGameId("tenant1", gameId10)
+		tenantGameId2 := dbprovider.TenantGameId("tenant2", gameId10)
+
+		err := dbProvider.Put(context.Background(), gameId10, userId1, dbprovider.UserProperties{Score: 10}, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		err = dbProvider.Put(context.Background(), tenantGameId1, userId1, dbprovider.UserProperties{Score: 20}, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		err = dbProvider.SetUserState(context.Background(), tenantGameId1, userId1, dbprovider.USERSTATE_BANNED, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+
+		props, err := dbProvider.Get(context.Background(), gameId10, userId1)
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.UScoreType(10), props.Score)
+		props, err = dbProvider.Get(context.Background(), tenantGameId2, userId1)
+		require.NoError(t, err)
+		require.Nil(t, props)
+
+		top, err := dbProvider.Top(context.Background(), gameId10, 10, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Len(t, top, 1)
+		top, err = dbProvider.Top(context.Background(), tenantGameId1, 10, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Empty(t, top)
+
+		state, err := dbProvider.GetUserState(context.Background(), gameId10, userId1)
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.USERSTATE_VISIBLE, state)
+
+		err = dbProvider.Delete(context.Background(), tenantGameId1, userId1, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		props, err = dbProvider.Get(context.Background(), gameId10, userId1)
+		require.NoError(t, err)
+		require.NotNil(t, props)
+	})
+
+	runTest(t, "count entries and runs", func(t *testing.T, dbProvider *DynamoProvider) {
+		n, err := dbProvider.Count(context.Background(), gameId11)
+		require.NoError(t, err)
+		require.Zero(t, n)
+
+		err = dbProvider.Put(context.Background(), gameId11, userId1, dbprovider.UserProperties{Score: 10}, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		err = dbProvider.Put(context.Background(), gameId11, userId2, dbprovider.UserProperties{Score: 20}, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		err = dbProvider.Put(context.Background(), gameId11, userId1, dbprovider.UserProperties{Score: 30}, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		n, err = dbProvider.Count(context.Background(), gameId11)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), n)
+
+		_, err = dbProvider.PutRun(context.Background(), gameId11, userId1, dbprovider.RunProperties{RunId: "run1", Score: 10}, 2, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		_, err = dbProvider.PutRun(context.Background(), gameId11, userId1, dbprovider.RunProperties{RunId: "run2", Score: 20}, 2, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		_, err = dbProvider.PutRun(context.Background(), gameId11, userId1, dbprovider.RunProperties{RunId: "run3", Score: 30}, 2, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		_, err = dbProvider.PutRun(context.Background(), gameId11, userId2, dbprovider.RunProperties{RunId: "run1", Score: 10}, 2, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		n, err = dbProvider.CountRuns(context.Background(), gameId11)
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), n)
+
+		err = dbProvider.Delete(context.Background(), gameId11, userId2, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		n, err = dbProvider.Count(context.Background(), gameId11)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), n)
+	})
+
+	runTest(t, "put, list and trim changes", func(t *testing.T, dbProvider *DynamoProvider) {
+		var (
+			changes []dbprovider.ChangeEntry
+			change  *dbprovider.ChangeEntry
+			err     error
+		)
+
+		change1 := dbprovider.ChangeEntry{Version: 1, Op: dbprovider.CHANGEOP_PUT, UserId: userId1, Score: 10, Name: "John", Ts: 1000}
+		change2 := dbprovider.ChangeEntry{Version: 2, Op: dbprovider.CHANGEOP_PUT, UserId: userId2, RunId: "run1", Score: 20, Params: "p", Ts: 2000}
+		change3 := dbprovider.ChangeEntry{Version: 3, Op: dbprovider.CHANGEOP_DELETE, UserId: userId1, Ts: 3000}
+
+		change, err = dbProvider.LastChange(context.Background(), gameId12)
+		require.NoError(t, err)
+		require.Nil(t, change)
+
+		userPropC1 := dbprovider.UserProperties{Score: 10, Name: "John"}
+		err = dbProvider.Put(context.Background(), gameId12, userId1, userPropC1,
+			dbprovider.WriteRecords{Version: 1, Changes: []dbprovider.ChangeEntry{change1}, Ts: 1000})
+		require.NoError(t, err)
+		_, err = dbProvider.PutRun(context.Background(), gameId12, userId2, dbprovider.RunProperties{RunId: "run1", Score: 20, Params: "p"}, 2,
+			dbprovider.WriteRecords{Version: 2, Changes: []dbprovider.ChangeEntry{change2}, Ts: 2000})
+		require.NoError(t, err)
+		err = dbProvider.Delete(context.Background(), gameId12, userId1,
+			dbprovider.WriteRecords{Version: 3, Changes: []dbprovider.ChangeEntry{change3}, Ts: 3000})
+		require.NoError(t, err)
+
+		// writes with taken versions aren't applied
+		err = dbProvider.Put(context.Background(), gameId12, userId2, userPropC1,
+			dbprovider.WriteRecords{Version: 3, Changes: []dbprovider.ChangeEntry{{Op: dbprovider.CHANGEOP_PUT, UserId: userId2}}, Ts: 4000})
+		require.ErrorIs(t, err, dbprovider.ErrChangeConflict)
+		data, err := dbProvider.Get(context.Background(), gameId12, userId2)
+		require.NoError(t, err)
+		require.Nil(t, data)
+
+		// versions of other games are independent
+		err = dbProvider.Put(context.Background(), dbprovider.TenantGameId("tenant1", gameId12), userId1, userPropC1,
+			dbprovider.WriteRecords{Version: 1, Changes: []dbprovider.ChangeEntry{change1}, Ts: 1000})
+		require.NoError(t, err)
+
+		change, err = dbProvider.LastChange(context.Background(), gameId12)
+		require.NoError(t, err)
+		require.Equal(t, change3, *change)
+
+		changes, err = dbProvider.ListChanges(context.Background(), gameId12, 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.ChangeEntry{change1, change2, change3}, changes)
+		changes, err = dbProvider.ListChanges(context.Background(), gameId12, 2, 1)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.ChangeEntry{change2}, changes)
+		changes, err = dbProvider.ListChanges(context.Background(), gameId12, 4, 10)
+		require.NoError(t, err)
+		require.Empty(t, changes)
+
+		err = dbProvider.TrimChanges(context.Background(), gameId12, 3)
+		require.NoError(t, err)
+		changes, err = dbProvider.ListChanges(context.Background(), gameId12, 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.ChangeEntry{change3}, changes)
+		changes, err = dbProvider.ListChanges(context.Background(), dbprovider.TenantGameId("tenant1", gameId12), 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.ChangeEntry{change1}, changes)
+	})
+
+	runTest(t, "record changes of removals", func(t *testing.T, dbProvider *DynamoProvider) {
+		var (
+			changes []dbprovider.ChangeEntry
+			n       uint32
+			updated bool
+			err     error
+		)
+
+		for i, userId := range []string{userId1, userId2, "user3"} {
+			score := dbprovider.UScoreType(10 * (i + 1))
+			err = dbProvider.Put(context.Background(), gameId14, userId, dbprovider.UserProperties{Score: score, Base: score, Ts: int64(1000 * (i + 1))},
+				dbprovider.WriteRecords{})
+			require.NoError(t, err)
+		}
+
+		// the change of a score is recorded only if the score is updated
+		decayed := dbprovider.WriteRecords{Version: 1, Changes: []dbprovider.ChangeEntry{{Op: dbprovider.CHANGEOP_PUT, UserId: userId1, Score: 5}}, Ts: 5000}
+		updated, err = dbProvider.SetScore(context.Background(), gameId14, userId1, 5, 2000, decayed)
+		require.NoError(t, err)
+		require.False(t, updated)
+		updated, err = dbProvider.SetScore(context.Background(), gameId14, userId1, 5, 1000, decayed)
+		require.NoError(t, err)
+		require.True(t, updated)
+
+		n, err = dbProvider.Expire(context.Background(), gameId14, 2000, dbprovider.WriteRecords{Version: 2, Ts: 5000})
+		require.NoError(t, err)
+		require.Equal(t, uint32(1), n)
+		n, err = dbProvider.Trim(context.Background(), gameId14, 1, dbprovider.WriteRecords{Version: 3, Ts: 5000})
+		require.NoError(t, err)
+		require.Equal(t, uint32(1), n)
+
+		n, err = dbProvider.PutRun(context.Background(), gameId14, userId1, dbprovider.RunProperties{RunId: "run1", Score: 10}, 1, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		require.Equal(t, uint32(0), n)
+		n, err = dbProvider.PutRun(context.Background(), gameId14, userId1, dbprovider.RunProperties{RunId: "run2", Score: 20}, 1, dbprovider.WriteRecords{
+			Version: 4, Changes: []dbprovider.ChangeEntry{{Op: dbprovider.CHANGEOP_PUT, UserId: userId1, RunId: "run2", Score: 20}}, Ts: 5000,
+		})
+		require.NoError(t, err)
+		require.Equal(t, uint32(1), n)
+
+		changes, err = dbProvider.ListChanges(context.Background(), gameId14, 0, 10)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.ChangeEntry{
+			{Version: 1, Op: dbprovider.CHANGEOP_PUT, UserId: userId1, Score: 5, Ts: 5000},
+			{Version: 2, Op: dbprovider.CHANGEOP_DELETE, UserId: userId1, Ts: 5000},
+			{Version: 3, Op: dbprovider.CHANGEOP_DELETE, UserId: userId2, Ts: 5000},
+			{Version: 4, Op: dbprovider.CHANGEOP_PUT, UserId: userId1, RunId: "run2", Score: 20, Ts: 5000},
+			{Version: 5, Op: dbprovider.CHANGEOP_DELETE, UserId: userId1, RunId: "run1", Ts: 5000},
+		}, changes)
+	})
+
+	runTest(t, "store, list and delete outbox events", func(t *testing.T, dbProvider *DynamoProvider) {
+		var (
+			events []dbprovider.ScoreEvent
+			err    error
+		)
+
+		event1 := dbprovider.ScoreEvent{GameId: gameId15, UserId: "user1", NewScore: 10, NewRank: 1, Ts: 1000}
+		event2 := dbprovider.ScoreEvent{GameId: gameId15, UserId: "user2", RunId: "run1", OldScore: 5, NewScore: 20,
+			OldRank: 2, NewRank: 1, Ts: 2000}
+		event3 := dbprovider.ScoreEvent{GameId: gameId15, UserId: "user1", NewScore: 30, Ts: 3000}
+		change := dbprovider.ChangeEntry{Op: dbprovider.CHANGEOP_PUT, UserId: "user1", Score: 10}
+
+		events, err = dbProvider.ListOutboxEvents(context.Background(), 10)
+		require.NoError(t, err)
+		require.Empty(t, events)
+
+		// events are stored by writes with ids from the sequence of the outbox
+		err = dbProvider.Put(context.Background(), gameId15, "user1", dbprovider.UserProperties{Score: 10, Ts: 1000},
+			dbprovider.WriteRecords{Version: 1, Changes: []dbprovider.ChangeEntry{change}, Ts: 1000, Event: &event1})
+		require.NoError(t, err)
+		// the event of a conflicting write isn't stored
+		err = dbProvider.Put(context.Background(), gameId15, "user2", dbprovider.UserProperties{Score: 20, Ts: 1000},
+			dbprovider.WriteRecords{Version: 1, Changes: []dbprovider.ChangeEntry{change}, Ts: 1000, Event: &event2})
+		require.ErrorIs(t, err, dbprovider.ErrChangeConflict)
+		_, err = dbProvider.PutRun(context.Background(), gameId15, "user2", dbprovider.RunProperties{RunId: "run1", Score: 20, Ts: 2000}, 2,
+			dbprovider.WriteRecords{Ts: 2000, Event: &event2})
+		require.NoError(t, err)
+		err = dbProvider.Put(context.Background(), gameId15, "user1", dbprovider.UserProperties{Score: 30, Ts: 3000},
+			dbprovider.WriteRecords{Ts: 3000, Event: &event3})
+		require.NoError(t, err)
+
+		event1.Id = dbprovider.OutboxEventId(1)
+		event2.Id = dbprovider.OutboxEventId(2)
+		event3.Id = dbprovider.OutboxEventId(3)
+		events, err = dbProvider.ListOutboxEvents(context.Background(), 10)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.ScoreEvent{event1, event2, event3}, events)
+		events, err = dbProvider.ListOutboxEvents(context.Background(), 1)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.ScoreEvent{event1}, events)
+
+		err = dbProvider.DeleteOutboxEvent(context.Background(), event1.Id)
+		require.NoError(t, err)
+		err = dbProvider.DeleteOutboxEvent(context.Background(), "unknown")
+		require.NoError(t, err)
+		events, err = dbProvider.ListOutboxEvents(context.Background(), 10)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.ScoreEvent{event2, event3}, events)
+
+		err = dbProvider.DeleteOutboxEvent(context.Background(), event2.Id)
+		require.NoError(t, err)
+		err = dbProvider.DeleteOutboxEvent(context.Background(), event3.Id)
+		require.NoError(t, err)
+	})
+
+	runTest(t, "get top data with omitted fields", func(t *testing.T, dbProvider *DynamoProvider) {
+		var (
+			top dbprovider.TopData
+			err error
+		)
+
+		userPropF1 := dbprovider.UserProperties{Score: 20, Name: "Jack", Params: "some_payload_1", Ts: 1000}
+		userPropF2 := dbprovider.UserProperties{Score: 10, Name: "Tom", Params: "some_payload_2", Ts: 2000}
+
+		err = dbProvider.Put(context.Background(), gameId13, userId1, userPropF1, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		err = dbProvider.Put(context.Background(), gameId13, userId2, userPropF2, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+
+		top, err = dbProvider.Top(context.Background(), gameId13, 10, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{
+			{UserId: userId1, UserProperties: userPropF1},
+			{UserId: userId2, UserProperties: userPropF2},
+		}, top)
+
+		top, err = dbProvider.Top(context.Background(), gameId13, 10, dbprovider.TopOptions{OmitFields: dbprovider.TOPFIELD_PARAMS})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{
+			{UserId: userId1, UserProperties: dbprovider.UserProperties{Score: 20, Name: "Jack", Ts: 1000}},
+			{UserId: userId2, UserProperties: dbprovider.UserProperties{Score: 10, Name: "Tom", Ts: 2000}},
+		}, top)
+
+		top, err = dbProvider.Top(context.Background(), gameId13, 10, dbprovider.TopOptions{OmitFields: dbprovider.TOPFIELD_NAME})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{
+			{UserId: userId1, UserProperties: dbprovider.UserProperties{Score: 20, Params: "some_payload_1", Ts: 1000}},
+			{UserId: userId2, UserProperties: dbprovider.UserProperties{Score: 10, Params: "some_payload_2", Ts: 2000}},
+		}, top)
+
+		// the submission time is still read for the filter
+		top, err = dbProvider.Top(context.Background(), gameId13, 10, dbprovider.TopOptions{MinTs: 1500, OmitFields: dbprovider.TOPFIELDS_OPTIONAL})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{
+			{UserId: userId2, UserProperties: dbprovider.UserProperties{Score: 10, Ts: 2000}},
+		}, top)
+	})
+
 }
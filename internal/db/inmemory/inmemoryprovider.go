@@ -5,6 +5,7 @@ import (
 	"errors"
 	dbprovider "go-leaderboard-server/internal/db"
 	log "go-leaderboard-server/internal/logger"
+	"slices"
 	"sort"
 	"sync"
 )
@@ -16,13 +17,24 @@ type DbInMemoryProviderConfig struct {
 }
 
 type DbInMemoryProvider struct {
-	mutex sync.RWMutex
-	data  map[string](map[string]dbprovider.UserProperties)
+	mutex   sync.RWMutex
+	data    map[string](map[string]dbprovider.UserProperties)
+	runs    map[string](map[string][]dbprovider.RunProperties)
+	states  map[string](map[string]dbprovider.UserState) // not visible users only
+	queue   map[string](map[string]dbprovider.QuarantineItem)
+	audit   []dbprovider.AuditEntry             // ordered by sequence number
+	changes map[string][]dbprovider.ChangeEntry // ordered by version
+	outbox  []dbprovider.ScoreEvent             // ordered by id
+	seq     uint64                              // sequence number of the last outbox event
 }
 
 func NewDbInMemoryProvider() *DbInMemoryProvider {
 	return &DbInMemoryProvider{
-		data: make(map[string](map[string]dbprovider.UserProperties)),
+		data:    make(map[string](map[string]dbprovider.UserProperties)),
+		runs:    make(map[string](map[string][]dbprovider.RunProperties)),
+		states:  make(map[string](map[string]dbprovider.UserState)),
+		queue:   make(map[string](map[string]dbprovider.QuarantineItem)),
+		changes: make(map[string][]dbprovider.ChangeEntry),
 	}
 }
 
@@ -41,26 +53,37 @@ func (p *DbInMemoryProvider) Initialize(ctx context.Context, config dbprovider.I
 	return nil
 }
 
-func (p *DbInMemoryProvider) Put(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties) error {
+func (p *DbInMemoryProvider) Put(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties, rec dbprovider.WriteRecords) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
+	if !p.isVersionFree(gameId, rec) {
+		return dbprovider.ErrChangeConflict
+	}
+
 	if _, ok := p.data[gameId]; !ok {
 		p.data[gameId] = make(map[string]dbprovider.UserProperties)
 	}
 
+	userProp.Exp = 0 // expired entries are removed by Expire
 	p.data[gameId][userId] = userProp
+	p.record(gameId, rec)
 
 	return nil
 }
 
-func (p *DbInMemoryProvider) Delete(ctx context.Context, gameId string, userId string) error {
+func (p *DbInMemoryProvider) Delete(ctx context.Context, gameId string, userId string, rec dbprovider.WriteRecords) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
+	if !p.isVersionFree(gameId, rec) {
+		return dbprovider.ErrChangeConflict
+	}
+
 	if _, ok := p.data[gameId]; ok {
 		delete(p.data[gameId], userId)
 	}
+	p.record(gameId, rec)
 
 	return nil
 }
@@ -82,7 +105,7 @@ func (p *DbInMemoryProvider) Get(ctx context.Context, gameId string, userId stri
 	return &ud, nil
 }
 
-func (p *DbInMemoryProvider) Top(ctx context.Context, gameId string, nTop uint32) (dbprovider.TopData, error) {
+func (p *DbInMemoryProvider) Top(ctx context.Context, gameId string, nTop uint32, opts dbprovider.TopOptions) (dbprovider.TopData, error) {
 	p.mutex.RLock()
 	defer p.mutex.RUnlock()
 
@@ -92,11 +115,24 @@ func (p *DbInMemoryProvider) Top(ctx context.Context, gameId string, nTop uint32
 	}
 
 	uscores := make([]dbprovider.UserData, 0, len(gd))
+	hidden := p.states[gameId]
 	for k, v := range gd {
+		if opts.MinTs != 0 && v.Ts < opts.MinTs {
+			continue
+		}
+		if _, ok := hidden[k]; ok {
+			continue
+		}
+		if opts.OmitFields&dbprovider.TOPFIELD_NAME != 0 {
+			v.Name = ""
+		}
+		if opts.OmitFields&dbprovider.TOPFIELD_PARAMS != 0 {
+			v.Params = ""
+		}
 		uscores = append(uscores,
 			dbprovider.UserData{
 				UserId:         k,
-				UserProperties: dbprovider.UserProperties{Score: v.Score, Name: v.Name, Params: v.Params},
+				UserProperties: v,
 			},
 		)
 	}
@@ -109,6 +145,406 @@ func (p *DbInMemoryProvider) Top(ctx context.Context, gameId string, nTop uint32
 	return top, nil
 }
 
+func (p *DbInMemoryProvider) Inactive(ctx context.Context, gameId string, before int64, above dbprovider.UScoreType, fn func(dbprovider.UserData) error) error {
+	p.mutex.RLock()
+	gd := p.data[gameId]
+	entries := make([]dbprovider.UserData, 0)
+	for k, v := range gd {
+		if v.Ts < before && v.Score > above {
+			entries = append(entries, dbprovider.UserData{UserId: k, UserProperties: v})
+		}
+	}
+	p.mutex.RUnlock()
+
+	for _, entry := range entries {
+		err := fn(entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *DbInMemoryProvider) SetScore(ctx context.Context, gameId string, userId string, score dbprovider.UScoreType, ts int64, rec dbprovider.WriteRecords) (bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.isVersionFree(gameId, rec) {
+		return false, dbprovider.ErrChangeConflict
+	}
+
+	ud, ok := p.data[gameId][userId]
+	if !ok || ud.Ts != ts {
+		return false, nil
+	}
+
+	ud.Score = score
+	p.data[gameId][userId] = ud
+	p.record(gameId, rec)
+
+	return true, nil
+}
+
+func (p *DbInMemoryProvider) Expire(ctx context.Context, gameId string, before int64, rec dbprovider.WriteRecords) (uint32, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.isVersionFree(gameId, rec) {
+		return 0, dbprovider.ErrChangeConflict
+	}
+
+	userIds := make([]string, 0)
+	for k, v := range p.data[gameId] {
+		if v.Ts < before {
+			userIds = append(userIds, k)
+		}
+	}
+	sort.Strings(userIds)
+
+	p.removeEntries(gameId, userIds, rec)
+
+	return uint32(len(userIds)), nil
+}
+
+func (p *DbInMemoryProvider) Trim(ctx context.Context, gameId string, maxEntries uint32, rec dbprovider.WriteRecords) (uint32, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.isVersionFree(gameId, rec) {
+		return 0, dbprovider.ErrChangeConflict
+	}
+
+	gd := p.data[gameId]
+	if len(gd) <= int(maxEntries) {
+		return 0, nil
+	}
+
+	userIds := make([]string, 0, len(gd))
+	for k := range gd {
+		userIds = append(userIds, k)
+	}
+
+	sort.Slice(userIds, func(i, j int) bool {
+		return gd[userIds[j]].Score < gd[userIds[i]].Score
+	})
+
+	p.removeEntries(gameId, userIds[maxEntries:], rec)
+
+	return uint32(len(userIds)) - maxEntries, nil
+}
+
+// Removes the entries of the game recording their deletes (the mutex is locked)
+func (p *DbInMemoryProvider) removeEntries(gameId string, userIds []string, rec dbprovider.WriteRecords) {
+	removed := make([]dbprovider.ChangeEntry, 0, len(userIds))
+	for _, userId := range userIds {
+		delete(p.data[gameId], userId)
+		removed = append(removed, dbprovider.ChangeEntry{Op: dbprovider.CHANGEOP_DELETE, UserId: userId})
+	}
+	p.record(gameId, rec, removed...)
+}
+
+func (p *DbInMemoryProvider) Count(ctx context.Context, gameId string) (uint64, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return uint64(len(p.data[gameId])), nil
+}
+
+func (p *DbInMemoryProvider) PutRun(ctx context.Context, gameId string, userId string, run dbprovider.RunProperties, maxRuns uint32, rec dbprovider.WriteRecords) (uint32, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.isVersionFree(gameId, rec) {
+		return 0, dbprovider.ErrChangeConflict
+	}
+
+	if _, ok := p.runs[gameId]; !ok {
+		p.runs[gameId] = make(map[string][]dbprovider.RunProperties)
+	}
+
+	runs := make([]dbprovider.RunProperties, 0, len(p.runs[gameId][userId])+1)
+	for _, r := range p.runs[gameId][userId] {
+		if r.RunId != run.RunId {
+			runs = append(runs, r)
+		}
+	}
+	runs = append(runs, run)
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[j].Score < runs[i].Score
+	})
+
+	n := min(len(runs), int(maxRuns))
+	p.runs[gameId][userId] = runs[:n]
+	evicted := make([]dbprovider.ChangeEntry, 0, len(runs)-n)
+	for _, r := range runs[n:] {
+		evicted = append(evicted, dbprovider.ChangeEntry{Op: dbprovider.CHANGEOP_DELETE, UserId: userId, RunId: r.RunId})
+	}
+	p.record(gameId, rec, evicted...)
+
+	return uint32(len(runs) - n), nil
+}
+
+func (p *DbInMemoryProvider) DeleteRuns(ctx context.Context, gameId string, userId string, rec dbprovider.WriteRecords) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.isVersionFree(gameId, rec) {
+		return dbprovider.ErrChangeConflict
+	}
+
+	if _, ok := p.runs[gameId]; ok {
+		delete(p.runs[gameId], userId)
+	}
+	p.record(gameId, rec)
+
+	return nil
+}
+
+func (p *DbInMemoryProvider) GetRuns(ctx context.Context, gameId string, userId string) ([]dbprovider.RunProperties, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	runs := make([]dbprovider.RunProperties, len(p.runs[gameId][userId]))
+	copy(runs, p.runs[gameId][userId])
+
+	return runs, nil
+}
+
+func (p *DbInMemoryProvider) TopRuns(ctx context.Context, gameId string, nTop uint32) (dbprovider.RunTopData, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	gd, ok := p.runs[gameId]
+	if !ok {
+		return dbprovider.RunTopData{}, nil
+	}
+
+	hidden := p.states[gameId]
+	rscores := make(dbprovider.RunTopData, 0)
+	for k, v := range gd {
+		if _, ok := hidden[k]; ok {
+			continue
+		}
+		for _, run := range v {
+			rscores = append(rscores, dbprovider.RunData{UserId: k, RunProperties: run})
+		}
+	}
+
+	sort.Slice(rscores, func(i, j int) bool {
+		return rscores[j].Score < rscores[i].Score
+	})
+
+	top := rscores[:min(len(rscores), int(nTop))]
+	return top, nil
+}
+
+func (p *DbInMemoryProvider) CountRuns(ctx context.Context, gameId string) (uint64, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var n uint64
+	for _, runs := range p.runs[gameId] {
+		n += uint64(len(runs))
+	}
+
+	return n, nil
+}
+
+func (p *DbInMemoryProvider) SetUserState(ctx context.Context, gameId string, userId string, state dbprovider.UserState, rec dbprovider.WriteRecords) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.isVersionFree(gameId, rec) {
+		return dbprovider.ErrChangeConflict
+	}
+
+	if state == dbprovider.USERSTATE_VISIBLE {
+		if _, ok := p.states[gameId]; ok {
+			delete(p.states[gameId], userId)
+		}
+	} else {
+		if _, ok := p.states[gameId]; !ok {
+			p.states[gameId] = make(map[string]dbprovider.UserState)
+		}
+		p.states[gameId][userId] = state
+	}
+	p.record(gameId, rec)
+
+	return nil
+}
+
+func (p *DbInMemoryProvider) GetUserState(ctx context.Context, gameId string, userId string) (dbprovider.UserState, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.states[gameId][userId], nil
+}
+
+func (p *DbInMemoryProvider) PutQuarantined(ctx context.Context, gameId string, item dbprovider.QuarantineItem) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, ok := p.queue[gameId]; !ok {
+		p.queue[gameId] = make(map[string]dbprovider.QuarantineItem)
+	}
+	p.queue[gameId][item.Id] = item
+
+	return nil
+}
+
+func (p *DbInMemoryProvider) GetQuarantined(ctx context.Context, gameId string, id string) (*dbprovider.QuarantineItem, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	item, ok := p.queue[gameId][id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &item, nil
+}
+
+func (p *DbInMemoryProvider) ListQuarantined(ctx context.Context, gameId string, limit uint32) ([]dbprovider.QuarantineItem, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	items := make([]dbprovider.QuarantineItem, 0, len(p.queue[gameId]))
+	for _, item := range p.queue[gameId] {
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Id < items[j].Id
+	})
+
+	return items[:min(len(items), int(limit))], nil
+}
+
+func (p *DbInMemoryProvider) DeleteQuarantined(ctx context.Context, gameId string, id string) (*dbprovider.QuarantineItem, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	item, ok := p.queue[gameId][id]
+	if !ok {
+		return nil, nil
+	}
+	delete(p.queue[gameId], id)
+
+	return &item, nil
+}
+
+func (p *DbInMemoryProvider) PutAuditEntry(ctx context.Context, entry dbprovider.AuditEntry) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	i := sort.Search(len(p.audit), func(i int) bool { return p.audit[i].Seq >= entry.Seq })
+	if i < len(p.audit) && p.audit[i].Seq == entry.Seq {
+		return dbprovider.ErrAuditConflict
+	}
+	p.audit = slices.Insert(p.audit, i, entry)
+
+	return nil
+}
+
+func (p *DbInMemoryProvider) ListAuditEntries(ctx context.Context, fromSeq uint64, limit uint32) ([]dbprovider.AuditEntry, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	i := sort.Search(len(p.audit), func(i int) bool { return p.audit[i].Seq >= fromSeq })
+	entries := p.audit[i:min(len(p.audit), i+int(limit))]
+
+	return append([]dbprovider.AuditEntry{}, entries...), nil
+}
+
+func (p *DbInMemoryProvider) LastAuditEntry(ctx context.Context) (*dbprovider.AuditEntry, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if len(p.audit) == 0 {
+		return nil, nil
+	}
+	entry := p.audit[len(p.audit)-1]
+
+	return &entry, nil
+}
+
+// Reports whether the version of the records isn't taken by a change of the game (the mutex is locked)
+func (p *DbInMemoryProvider) isVersionFree(gameId string, rec dbprovider.WriteRecords) bool {
+	changes := p.changes[gameId]
+	return rec.Version == 0 || len(changes) == 0 || changes[len(changes)-1].Version < rec.Version
+}
+
+// Appends the changes of the records and the made changes to the change feed of the game and stores the event
+// to the outbox (the mutex is locked)
+func (p *DbInMemoryProvider) record(gameId string, rec dbprovider.WriteRecords, made ...dbprovider.ChangeEntry) {
+	if changes := rec.Entries(made...); len(changes) > 0 {
+		p.changes[gameId] = append(p.changes[gameId], changes...)
+	}
+
+	if rec.Event != nil {
+		p.seq++
+		event := *rec.Event
+		event.Id = dbprovider.OutboxEventId(p.seq)
+		p.outbox = append(p.outbox, event)
+	}
+}
+
+func (p *DbInMemoryProvider) ListChanges(ctx context.Context, gameId string, fromVersion uint64, limit uint32) ([]dbprovider.ChangeEntry, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	changes := p.changes[gameId]
+	i := sort.Search(len(changes), func(i int) bool { return changes[i].Version >= fromVersion })
+	changes = changes[i:min(len(changes), i+int(limit))]
+
+	return append([]dbprovider.ChangeEntry{}, changes...), nil
+}
+
+func (p *DbInMemoryProvider) LastChange(ctx context.Context, gameId string) (*dbprovider.ChangeEntry, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	changes := p.changes[gameId]
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	change := changes[len(changes)-1]
+
+	return &change, nil
+}
+
+func (p *DbInMemoryProvider) TrimChanges(ctx context.Context, gameId string, beforeVersion uint64) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	changes := p.changes[gameId]
+	i := sort.Search(len(changes), func(i int) bool { return changes[i].Version >= beforeVersion })
+	p.changes[gameId] = slices.Clone(changes[i:])
+
+	return nil
+}
+
+func (p *DbInMemoryProvider) ListOutboxEvents(ctx context.Context, limit uint32) ([]dbprovider.ScoreEvent, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return append([]dbprovider.ScoreEvent{}, p.outbox[:min(len(p.outbox), int(limit))]...), nil
+}
+
+func (p *DbInMemoryProvider) DeleteOutboxEvent(ctx context.Context, id string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	i := sort.Search(len(p.outbox), func(i int) bool { return p.outbox[i].Id >= id })
+	if i < len(p.outbox) && p.outbox[i].Id == id {
+		p.outbox = slices.Delete(p.outbox, i, i+1)
+	}
+
+	return nil
+}
+
 func (p *DbInMemoryProvider) Shutdown(ctx context.Context) error {
 	logger.Debug("DB provider shutdown")
 
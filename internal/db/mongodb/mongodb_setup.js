@@ -31,6 +31,142 @@ db.createCollection('UserData', {
 				},
 				pl: {
 					bsonType: ['null', 'string']
+				},
+				bs: {
+					bsonType: ['int', 'long', 'double']
+				},
+				ts: {
+					bsonType: ['int', 'long']
+				},
+				ex: {
+					bsonType: 'date'
+				}
+			},
+			additionalProperties: false
+		}
+	}
+});
+
+db.getCollection('UserData').createIndex({ '_id.gId': 1, sc: -1 }, { name: 'ScoreIndex' });
+db.getCollection('UserData').createIndex({ '_id.gId': 1, ts: 1 }, { name: 'TsIndex' });
+db.getCollection('UserData').createIndex({ ex: 1 }, { name: 'TtlIndex', expireAfterSeconds: 0 });
+
+db.createCollection('RunData', {
+	validator: {
+		$jsonSchema: {
+			bsonType: 'object',
+			required: ['_id', 'sc', 'ts'],
+			properties: {
+				_id: {
+					bsonType: 'object',
+					required: ['gId', 'uId', 'rId'],
+					properties: {
+						gId: {
+							bsonType: 'string'
+						},
+						uId: {
+							bsonType: 'string'
+						},
+						rId: {
+							bsonType: 'string'
+						},
+					},
+					additionalProperties: false
+				},
+				sc: {
+					bsonType: ['int', 'long', 'double']
+				},
+				nm: {
+					bsonType: ['null', 'string']
+				},
+				pl: {
+					bsonType: ['null', 'string']
+				},
+				ts: {
+					bsonType: ['int', 'long']
+				}
+			},
+			additionalProperties: false
+		}
+	}
+});
+
+db.getCollection('RunData').createIndex({ '_id.gId': 1, sc: -1 }, { name: 'ScoreIndex' });
+db.getCollection('RunData').createIndex({ '_id.gId': 1, '_id.uId': 1, sc: -1 }, { name: 'UserScoreIndex' });
+
+db.createCollection('UserState', {
+	validator: {
+		$jsonSchema: {
+			bsonType: 'object',
+			required: ['_id', 'st'],
+			properties: {
+				_id: {
+					bsonType: 'object',
+					required: ['gId', 'uId'],
+					properties: {
+						gId: {
+							bsonType: 'string'
+						},
+						uId: {
+							bsonType: 'string'
+						},
+					},
+					additionalProperties: false
+				},
+				st: {
+					bsonType: ['int', 'long']
+				}
+			},
+			additionalProperties: false
+		}
+	}
+});
+
+db.createCollection('Quarantine', {
+	validator: {
+		$jsonSchema: {
+			bsonType: 'object',
+			required: ['_id', 'uId', 'rl', 'rs', 'sc', 'ts'],
+			properties: {
+				_id: {
+					bsonType: 'object',
+					required: ['gId', 'qId'],
+					properties: {
+						gId: {
+							bsonType: 'string'
+						},
+						qId: {
+							bsonType: 'string'
+						},
+					},
+					additionalProperties: false
+				},
+				uId: {
+					bsonType: 'string'
+				},
+				rl: {
+					bsonType: 'string'
+				},
+				rs: {
+					bsonType: 'string'
+				},
+				sc: {
+					bsonType: ['int', 'long', 'double']
+				},
+				nm: {
+					bsonType: ['null', 'string']
+				},
+				pl: {
+					bsonType: ['null', 'string']
+				},
+				rId: {
+					bsonType: ['null', 'string']
+				},
+				du: {
+					bsonType: ['null', 'int', 'long']
+				},
+				ts: {
+					bsonType: ['int', 'long']
 				}
 			},
 			additionalProperties: false
@@ -38,4 +174,154 @@ db.createCollection('UserData', {
 	}
 });
 
-db.getCollection('UserData').createIndex({ '_id.gId': 1, sc: -1 }, { name: 'ScoreIndex' });
\ No newline at end of file
+db.getCollection('Quarantine').createIndex({ '_id.gId': 1, '_id.qId': 1 }, { name: 'QuarantineIndex' });
+
+db.createCollection('Audit', {
+	validator: {
+		$jsonSchema: {
+			bsonType: 'object',
+			required: ['_id', 'ts', 'ac', 'an', 'gId', 'ph', 'hs'],
+			properties: {
+				_id: {
+					bsonType: ['int', 'long']
+				},
+				ts: {
+					bsonType: ['int', 'long']
+				},
+				ac: {
+					bsonType: 'string'
+				},
+				an: {
+					bsonType: 'string'
+				},
+				gId: {
+					bsonType: 'string'
+				},
+				uId: {
+					bsonType: 'string'
+				},
+				bf: {
+					bsonType: 'string'
+				},
+				af: {
+					bsonType: 'string'
+				},
+				rqId: {
+					bsonType: 'string'
+				},
+				ph: {
+					bsonType: 'string'
+				},
+				hs: {
+					bsonType: 'string'
+				}
+			},
+			additionalProperties: false
+		}
+	}
+});
+
+db.createCollection('Changes', {
+	validator: {
+		$jsonSchema: {
+			bsonType: 'object',
+			required: ['_id', 'op', 'uId', 'sc', 'ts'],
+			properties: {
+				_id: {
+					bsonType: 'object',
+					required: ['gId', 'v'],
+					properties: {
+						gId: {
+							bsonType: 'string'
+						},
+						v: {
+							bsonType: ['int', 'long']
+						},
+					},
+					additionalProperties: false
+				},
+				op: {
+					bsonType: 'string'
+				},
+				uId: {
+					bsonType: 'string'
+				},
+				rId: {
+					bsonType: ['null', 'string']
+				},
+				sc: {
+					bsonType: ['int', 'long', 'double']
+				},
+				nm: {
+					bsonType: ['null', 'string']
+				},
+				pl: {
+					bsonType: ['null', 'string']
+				},
+				ts: {
+					bsonType: ['int', 'long']
+				}
+			},
+			additionalProperties: false
+		}
+	}
+});
+
+db.getCollection('Changes').createIndex({ '_id.gId': 1, '_id.v': 1 }, { name: 'ChangesIndex' });
+
+db.createCollection('Outbox', {
+	validator: {
+		$jsonSchema: {
+			bsonType: 'object',
+			required: ['_id', 'gId', 'uId', 'os', 'ns', 'or', 'nr', 'ts'],
+			properties: {
+				_id: {
+					bsonType: 'string'
+				},
+				gId: {
+					bsonType: 'string'
+				},
+				uId: {
+					bsonType: 'string'
+				},
+				rId: {
+					bsonType: ['null', 'string']
+				},
+				os: {
+					bsonType: ['int', 'long', 'double']
+				},
+				ns: {
+					bsonType: ['int', 'long', 'double']
+				},
+				or: {
+					bsonType: ['int', 'long']
+				},
+				nr: {
+					bsonType: ['int', 'long']
+				},
+				ts: {
+					bsonType: ['int', 'long']
+				}
+			},
+			additionalProperties: false
+		}
+	}
+});
+
+db.createCollection('Counters', {
+	validator: {
+		$jsonSchema: {
+			bsonType: 'object',
+			required: ['_id', 'seq'],
+			properties: {
+				_id: {
+					bsonType: 'string'
+				},
+				seq: {
+					bsonType: ['int', 'long']
+				}
+			},
+			additionalProperties: false
+		}
+	}
+});
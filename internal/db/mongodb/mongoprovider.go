@@ -5,6 +5,7 @@ import (
 	"errors"
 	dbprovider "go-leaderboard-server/internal/db"
 	log "go-leaderboard-server/internal/logger"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -28,12 +29,62 @@ type MongoUserData struct {
 	dbprovider.UserProperties `bson:",inline"`
 }
 
+type MongoUserDocument struct {
+	dbprovider.UserProperties `bson:",inline"`
+	Exp                       *time.Time `bson:"ex,omitempty"` // Expiration time (used by the TTL index)
+}
+
+type MongoRunID struct {
+	UserId string `bson:"uId"`
+	RunId  string `bson:"rId"`
+}
+
+type MongoRunData struct {
+	MongoRunID               `bson:"_id"`
+	dbprovider.RunProperties `bson:",inline"`
+}
+
+type MongoQuarantineID struct {
+	Id string `bson:"qId"`
+}
+
+type MongoQuarantineItem struct {
+	MongoQuarantineID         `bson:"_id"`
+	dbprovider.QuarantineItem `bson:",inline"`
+}
+
+type MongoChangeID struct {
+	Version uint64 `bson:"v"`
+}
+
+type MongoChange struct {
+	MongoChangeID          `bson:"_id"`
+	dbprovider.ChangeEntry `bson:",inline"`
+}
+
 const DB_NAME string = "GoLeaderboard"
 const DB_COLLECTION_NAME string = "UserData"
+const DB_RUNS_COLLECTION_NAME string = "RunData"
+const DB_STATES_COLLECTION_NAME string = "UserState"
+const DB_QUARANTINE_COLLECTION_NAME string = "Quarantine"
+const DB_AUDIT_COLLECTION_NAME string = "Audit"
+const DB_CHANGES_COLLECTION_NAME string = "Changes"
+const DB_OUTBOX_COLLECTION_NAME string = "Outbox"
+const DB_COUNTERS_COLLECTION_NAME string = "Counters"
+
+// Id of the counter of outbox events
+const outboxCounterId string = "outbox"
 
 type MongoProvider struct {
-	client     *mongo.Client
-	collection *mongo.Collection
+	client             *mongo.Client
+	collection         *mongo.Collection
+	runsCollection     *mongo.Collection
+	statesCollection   *mongo.Collection
+	queueCollection    *mongo.Collection
+	auditCollection    *mongo.Collection
+	changesCollection  *mongo.Collection
+	outboxCollection   *mongo.Collection
+	countersCollection *mongo.Collection
 }
 
 func NewMongoProvider() *MongoProvider {
@@ -60,31 +111,172 @@ func (p *MongoProvider) Initialize(ctx context.Context, config dbprovider.IDBPro
 	}
 
 	p.collection = p.client.Database(DB_NAME).Collection(DB_COLLECTION_NAME)
+	p.runsCollection = p.client.Database(DB_NAME).Collection(DB_RUNS_COLLECTION_NAME)
+	p.statesCollection = p.client.Database(DB_NAME).Collection(DB_STATES_COLLECTION_NAME)
+	p.queueCollection = p.client.Database(DB_NAME).Collection(DB_QUARANTINE_COLLECTION_NAME)
+	p.auditCollection = p.client.Database(DB_NAME).Collection(DB_AUDIT_COLLECTION_NAME)
+	p.changesCollection = p.client.Database(DB_NAME).Collection(DB_CHANGES_COLLECTION_NAME)
+	p.outboxCollection = p.client.Database(DB_NAME).Collection(DB_OUTBOX_COLLECTION_NAME)
+	p.countersCollection = p.client.Database(DB_NAME).Collection(DB_COUNTERS_COLLECTION_NAME)
 
 	return nil
 }
 
-func (p *MongoProvider) Put(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties) error {
-	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "gId", Value: gameId}, {Key: "uId", Value: userId}}}}
-	opts := options.Replace().SetUpsert(true)
-	_, err := p.collection.ReplaceOne(ctx, filter, userProp, opts)
+// Runs the write in a transaction recording the changes of rec to the change feed of the game (transactions need
+// a replica set, writes recording no changes don't use them). The write returns deletes of entries (runs) it removed
+// besides the changes of rec, they are recorded after them. A taken version of a change fails the transaction
+// with ErrChangeConflict
+func (p *MongoProvider) recordWrite(ctx context.Context, gameId string, rec dbprovider.WriteRecords,
+	write func(ctx context.Context) ([]dbprovider.ChangeEntry, error)) error {
+	if rec.Version == 0 && rec.Event == nil {
+		_, err := write(ctx)
+		return err
+	}
+
+	session, err := p.client.StartSession()
 	if err != nil {
 		return err
 	}
+	defer session.EndSession(ctx)
 
-	return nil
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		removed, err := write(sc)
+		if err != nil {
+			return nil, err
+		}
+		err = p.insertChanges(sc, gameId, rec.Entries(removed...))
+		if err != nil || rec.Event == nil {
+			return nil, err
+		}
+		return nil, p.insertOutboxEvent(sc, *rec.Event)
+	})
+
+	return err
 }
 
-func (p *MongoProvider) Delete(ctx context.Context, gameId string, userId string) error {
-	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "gId", Value: gameId}, {Key: "uId", Value: userId}}}}
-	_, err := p.collection.DeleteOne(ctx, filter)
+// Stores the event to the outbox with the next id of the counter. Concurrent transactions conflict on the counter
+// and are retried, so ids are assigned in the order of commits
+func (p *MongoProvider) insertOutboxEvent(ctx context.Context, event dbprovider.ScoreEvent) error {
+	var counter struct {
+		Seq uint64 `bson:"seq"`
+	}
+	filter := bson.D{{Key: "_id", Value: outboxCounterId}}
+	update := bson.D{{Key: "$inc", Value: bson.D{{Key: "seq", Value: int64(1)}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := p.countersCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&counter)
 	if err != nil {
 		return err
 	}
 
+	event.Id = dbprovider.OutboxEventId(counter.Seq)
+	_, err = p.outboxCollection.InsertOne(ctx, event)
+
+	return err
+}
+
+// Appends the changes to the change feed of the game, returns ErrChangeConflict if a version is taken already
+func (p *MongoProvider) insertChanges(ctx context.Context, gameId string, changes []dbprovider.ChangeEntry) error {
+	for _, change := range changes {
+		filter := bson.D{{Key: "_id", Value: bson.D{{Key: "gId", Value: gameId}, {Key: "v", Value: change.Version}}}}
+		update := bson.D{{Key: "$setOnInsert", Value: change}}
+		opts := options.Update().SetUpsert(true)
+		result, err := p.changesCollection.UpdateOne(ctx, filter, update, opts)
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return dbprovider.ErrChangeConflict
+			}
+			return err
+		}
+		if result.UpsertedCount == 0 {
+			return dbprovider.ErrChangeConflict
+		}
+	}
+
 	return nil
 }
 
+// Removes entries of the game selected by the filter and the options in batches, every batch is a transaction of its
+// own recording deletes of the removed entries. Returns the number of removed entries
+func (p *MongoProvider) removeEntries(ctx context.Context, gameId string, filter bson.D, opts *options.FindOptions,
+	rec dbprovider.WriteRecords) (uint32, error) {
+	const batchSize = 1000
+
+	opts.SetLimit(batchSize).SetProjection(bson.D{{Key: "_id", Value: 1}})
+
+	var removed uint32
+	for {
+		batch := dbprovider.WriteRecords{Ts: rec.Ts}
+		if rec.Version != 0 {
+			batch.Version = rec.Version + uint64(removed)
+		}
+
+		n := 0
+		err := p.recordWrite(ctx, gameId, batch, func(ctx context.Context) ([]dbprovider.ChangeEntry, error) {
+			cursor, err := p.collection.Find(ctx, filter, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			defer cursor.Close(ctx)
+
+			ids := make(bson.A, 0, batchSize)
+			deletes := make([]dbprovider.ChangeEntry, 0, batchSize)
+			for cursor.Next(ctx) {
+				var mres MongoUserData
+				err := cursor.Decode(&mres)
+				if err != nil {
+					return nil, err
+				}
+				ids = append(ids, cursor.Current.Lookup("_id"))
+				deletes = append(deletes, dbprovider.ChangeEntry{Op: dbprovider.CHANGEOP_DELETE, UserId: mres.MongoUserID.UserId})
+			}
+			err = cursor.Err()
+			if err != nil {
+				return nil, err
+			}
+
+			n = len(ids)
+			if n == 0 {
+				return deletes, nil
+			}
+			_, err = p.collection.DeleteMany(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}})
+			return deletes, err
+		})
+		if err != nil {
+			return removed, err
+		}
+
+		removed += uint32(n)
+		if n < batchSize {
+			return removed, nil
+		}
+	}
+}
+
+func (p *MongoProvider) Put(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties, rec dbprovider.WriteRecords) error {
+	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "gId", Value: gameId}, {Key: "uId", Value: userId}}}}
+	doc := MongoUserDocument{UserProperties: userProp}
+	if userProp.Exp != 0 {
+		exp := time.UnixMilli(userProp.Exp)
+		doc.Exp = &exp
+	}
+	opts := options.Replace().SetUpsert(true)
+
+	return p.recordWrite(ctx, gameId, rec, func(ctx context.Context) ([]dbprovider.ChangeEntry, error) {
+		_, err := p.collection.ReplaceOne(ctx, filter, doc, opts)
+		return nil, err
+	})
+}
+
+func (p *MongoProvider) Delete(ctx context.Context, gameId string, userId string, rec dbprovider.WriteRecords) error {
+	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "gId", Value: gameId}, {Key: "uId", Value: userId}}}}
+
+	return p.recordWrite(ctx, gameId, rec, func(ctx context.Context) ([]dbprovider.ChangeEntry, error) {
+		_, err := p.collection.DeleteOne(ctx, filter)
+		return nil, err
+	})
+}
+
 func (p *MongoProvider) Get(ctx context.Context, gameId string, userId string) (*dbprovider.UserProperties, error) {
 	var result dbprovider.UserProperties
 	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "gId", Value: gameId}, {Key: "uId", Value: userId}}}}
@@ -99,10 +291,31 @@ func (p *MongoProvider) Get(ctx context.Context, gameId string, userId string) (
 	return &result, nil
 }
 
-func (p *MongoProvider) Top(ctx context.Context, gameId string, nTop uint32) (dbprovider.TopData, error) {
+func (p *MongoProvider) Top(ctx context.Context, gameId string, nTop uint32, opts dbprovider.TopOptions) (dbprovider.TopData, error) {
+	hidden, err := p.hiddenUsers(ctx, gameId)
+	if err != nil {
+		return dbprovider.TopData{}, err
+	}
+
 	filter := bson.D{{Key: "_id.gId", Value: gameId}}
-	opts := options.Find().SetHint("ScoreIndex").SetSort(bson.D{{Key: "sc", Value: -1}}).SetLimit(int64(nTop))
-	cursor, err := p.collection.Find(ctx, filter, opts)
+	if opts.MinTs != 0 {
+		filter = append(filter, bson.E{Key: "ts", Value: bson.D{{Key: "$gte", Value: opts.MinTs}}})
+	}
+	if len(hidden) > 0 {
+		filter = append(filter, bson.E{Key: "_id.uId", Value: bson.D{{Key: "$nin", Value: hidden}}})
+	}
+	findOpts := options.Find().SetHint("ScoreIndex").SetSort(bson.D{{Key: "sc", Value: -1}}).SetLimit(int64(nTop))
+	if opts.OmitFields != 0 {
+		projection := bson.D{}
+		if opts.OmitFields&dbprovider.TOPFIELD_NAME != 0 {
+			projection = append(projection, bson.E{Key: "nm", Value: 0})
+		}
+		if opts.OmitFields&dbprovider.TOPFIELD_PARAMS != 0 {
+			projection = append(projection, bson.E{Key: "pl", Value: 0})
+		}
+		findOpts.SetProjection(projection)
+	}
+	cursor, err := p.collection.Find(ctx, filter, findOpts)
 	if err != nil {
 		return dbprovider.TopData{}, err
 	}
@@ -131,6 +344,513 @@ func (p *MongoProvider) Top(ctx context.Context, gameId string, nTop uint32) (db
 	return result, nil
 }
 
+func (p *MongoProvider) Inactive(ctx context.Context, gameId string, before int64, above dbprovider.UScoreType, fn func(dbprovider.UserData) error) error {
+	filter := bson.D{
+		{Key: "_id.gId", Value: gameId},
+		{Key: "ts", Value: bson.D{{Key: "$lt", Value: before}}},
+		{Key: "sc", Value: bson.D{{Key: "$gt", Value: above}}},
+	}
+	opts := options.Find().SetHint("TsIndex").SetBatchSize(dbprovider.INACTIVE_PAGE_SIZE)
+	cursor, err := p.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var mres MongoUserData
+		err := cursor.Decode(&mres)
+		if err != nil {
+			return err
+		}
+
+		err = fn(dbprovider.UserData{
+			UserId:         mres.UserId,
+			UserProperties: mres.UserProperties,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+func (p *MongoProvider) SetScore(ctx context.Context, gameId string, userId string, score dbprovider.UScoreType, ts int64, rec dbprovider.WriteRecords) (bool, error) {
+	filter := bson.D{
+		{Key: "_id", Value: bson.D{{Key: "gId", Value: gameId}, {Key: "uId", Value: userId}}},
+		{Key: "ts", Value: ts},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "sc", Value: score}}}}
+
+	updated := false
+	check := rec
+	check.Changes = nil // recorded only if the score is updated
+	err := p.recordWrite(ctx, gameId, check, func(ctx context.Context) ([]dbprovider.ChangeEntry, error) {
+		result, err := p.collection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return nil, err
+		}
+		updated = result.MatchedCount > 0
+		if !updated {
+			return nil, nil
+		}
+		return rec.Changes, nil
+	})
+
+	return updated, err
+}
+
+func (p *MongoProvider) Expire(ctx context.Context, gameId string, before int64, rec dbprovider.WriteRecords) (uint32, error) {
+	if rec.Version == 0 {
+		return 0, nil // expired entries are removed by the TTL index
+	}
+
+	filter := bson.D{{Key: "_id.gId", Value: gameId}, {Key: "ts", Value: bson.D{{Key: "$lt", Value: before}}}}
+	return p.removeEntries(ctx, gameId, filter, options.Find().SetHint("TsIndex"), rec)
+}
+
+func (p *MongoProvider) Trim(ctx context.Context, gameId string, maxEntries uint32, rec dbprovider.WriteRecords) (uint32, error) {
+	// entries below maxEntries are removed by batches, so every batch skips the same kept entries
+	filter := bson.D{{Key: "_id.gId", Value: gameId}}
+	opts := options.Find().
+		SetHint("ScoreIndex").
+		SetSort(bson.D{{Key: "sc", Value: -1}}).
+		SetSkip(int64(maxEntries))
+	return p.removeEntries(ctx, gameId, filter, opts, rec)
+}
+
+func (p *MongoProvider) Count(ctx context.Context, gameId string) (uint64, error) {
+	n, err := p.collection.CountDocuments(ctx, bson.D{{Key: "_id.gId", Value: gameId}})
+	return uint64(n), err
+}
+
+func (p *MongoProvider) PutRun(ctx context.Context, gameId string, userId string, run dbprovider.RunProperties, maxRuns uint32, rec dbprovider.WriteRecords) (uint32, error) {
+	var evicted []dbprovider.ChangeEntry
+	err := p.recordWrite(ctx, gameId, rec, func(ctx context.Context) ([]dbprovider.ChangeEntry, error) {
+		filter := bson.D{{Key: "_id", Value: bson.D{{Key: "gId", Value: gameId}, {Key: "uId", Value: userId}, {Key: "rId", Value: run.RunId}}}}
+		opts := options.Replace().SetUpsert(true)
+		_, err := p.runsCollection.ReplaceOne(ctx, filter, run, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		filter = bson.D{{Key: "_id.gId", Value: gameId}, {Key: "_id.uId", Value: userId}}
+		findOpts := options.Find().
+			SetHint("UserScoreIndex").
+			SetSort(bson.D{{Key: "sc", Value: -1}}).
+			SetSkip(int64(maxRuns)).
+			SetProjection(bson.D{{Key: "_id", Value: 1}})
+		cursor, err := p.runsCollection.Find(ctx, filter, findOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		defer cursor.Close(ctx)
+
+		ids := make(bson.A, 0)
+		evicted = make([]dbprovider.ChangeEntry, 0)
+		for cursor.Next(ctx) {
+			var mres MongoRunData
+			err := cursor.Decode(&mres)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, cursor.Current.Lookup("_id"))
+			evicted = append(evicted, dbprovider.ChangeEntry{Op: dbprovider.CHANGEOP_DELETE, UserId: userId, RunId: mres.MongoRunID.RunId})
+		}
+		err = cursor.Err()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ids) == 0 {
+			return evicted, nil
+		}
+		_, err = p.runsCollection.DeleteMany(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}})
+		return evicted, err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(len(evicted)), nil
+}
+
+func (p *MongoProvider) DeleteRuns(ctx context.Context, gameId string, userId string, rec dbprovider.WriteRecords) error {
+	filter := bson.D{{Key: "_id.gId", Value: gameId}, {Key: "_id.uId", Value: userId}}
+
+	return p.recordWrite(ctx, gameId, rec, func(ctx context.Context) ([]dbprovider.ChangeEntry, error) {
+		_, err := p.runsCollection.DeleteMany(ctx, filter)
+		return nil, err
+	})
+}
+
+func (p *MongoProvider) GetRuns(ctx context.Context, gameId string, userId string) ([]dbprovider.RunProperties, error) {
+	filter := bson.D{{Key: "_id.gId", Value: gameId}, {Key: "_id.uId", Value: userId}}
+	opts := options.Find().SetHint("UserScoreIndex").SetSort(bson.D{{Key: "sc", Value: -1}})
+	cursor, err := p.runsCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dbprovider.RunProperties, 0)
+
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var mres MongoRunData
+		err := cursor.Decode(&mres)
+		if err != nil {
+			return nil, err
+		}
+
+		run := mres.RunProperties
+		run.RunId = mres.MongoRunID.RunId
+		result = append(result, run)
+	}
+	err = cursor.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (p *MongoProvider) TopRuns(ctx context.Context, gameId string, nTop uint32) (dbprovider.RunTopData, error) {
+	hidden, err := p.hiddenUsers(ctx, gameId)
+	if err != nil {
+		return dbprovider.RunTopData{}, err
+	}
+
+	filter := bson.D{{Key: "_id.gId", Value: gameId}}
+	if len(hidden) > 0 {
+		filter = append(filter, bson.E{Key: "_id.uId", Value: bson.D{{Key: "$nin", Value: hidden}}})
+	}
+	opts := options.Find().SetHint("ScoreIndex").SetSort(bson.D{{Key: "sc", Value: -1}}).SetLimit(int64(nTop))
+	cursor, err := p.runsCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return dbprovider.RunTopData{}, err
+	}
+
+	var result dbprovider.RunTopData = make(dbprovider.RunTopData, 0, nTop)
+
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var mres MongoRunData
+		err := cursor.Decode(&mres)
+		if err != nil {
+			return dbprovider.RunTopData{}, err
+		}
+
+		run := mres.RunProperties
+		run.RunId = mres.MongoRunID.RunId
+		result = append(result, dbprovider.RunData{
+			UserId:        mres.MongoRunID.UserId,
+			RunProperties: run,
+		})
+	}
+	err = cursor.Err()
+	if err != nil {
+		return dbprovider.RunTopData{}, err
+	}
+
+	return result, nil
+}
+
+// Returns ids of not visible users of the game
+func (p *MongoProvider) hiddenUsers(ctx context.Context, gameId string) (bson.A, error) {
+	filter := bson.D{{Key: "_id.gId", Value: gameId}}
+	cursor, err := p.statesCollection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	hidden := make(bson.A, 0)
+
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var mres MongoUserData
+		err := cursor.Decode(&mres)
+		if err != nil {
+			return nil, err
+		}
+		hidden = append(hidden, mres.UserId)
+	}
+	err = cursor.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return hidden, nil
+}
+
+func (p *MongoProvider) CountRuns(ctx context.Context, gameId string) (uint64, error) {
+	n, err := p.runsCollection.CountDocuments(ctx, bson.D{{Key: "_id.gId", Value: gameId}})
+	return uint64(n), err
+}
+
+func (p *MongoProvider) SetUserState(ctx context.Context, gameId string, userId string, state dbprovider.UserState, rec dbprovider.WriteRecords) error {
+	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "gId", Value: gameId}, {Key: "uId", Value: userId}}}}
+
+	return p.recordWrite(ctx, gameId, rec, func(ctx context.Context) ([]dbprovider.ChangeEntry, error) {
+		var err error
+		if state == dbprovider.USERSTATE_VISIBLE {
+			_, err = p.statesCollection.DeleteOne(ctx, filter)
+		} else {
+			opts := options.Replace().SetUpsert(true)
+			_, err = p.statesCollection.ReplaceOne(ctx, filter, bson.D{{Key: "st", Value: int(state)}}, opts)
+		}
+		return nil, err
+	})
+}
+
+func (p *MongoProvider) GetUserState(ctx context.Context, gameId string, userId string) (dbprovider.UserState, error) {
+	var result struct {
+		State int `bson:"st"`
+	}
+	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "gId", Value: gameId}, {Key: "uId", Value: userId}}}}
+	err := p.statesCollection.FindOne(ctx, filter).Decode(&result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return dbprovider.USERSTATE_VISIBLE, nil
+		}
+		return dbprovider.USERSTATE_VISIBLE, err
+	}
+
+	return dbprovider.UserState(result.State), nil
+}
+
+func (p *MongoProvider) PutQuarantined(ctx context.Context, gameId string, item dbprovider.QuarantineItem) error {
+	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "gId", Value: gameId}, {Key: "qId", Value: item.Id}}}}
+	opts := options.Replace().SetUpsert(true)
+	_, err := p.queueCollection.ReplaceOne(ctx, filter, item, opts)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *MongoProvider) GetQuarantined(ctx context.Context, gameId string, id string) (*dbprovider.QuarantineItem, error) {
+	var mres MongoQuarantineItem
+	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "gId", Value: gameId}, {Key: "qId", Value: id}}}}
+	err := p.queueCollection.FindOne(ctx, filter).Decode(&mres)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	item := mres.QuarantineItem
+	item.Id = mres.MongoQuarantineID.Id
+	return &item, nil
+}
+
+func (p *MongoProvider) ListQuarantined(ctx context.Context, gameId string, limit uint32) ([]dbprovider.QuarantineItem, error) {
+	if limit == 0 {
+		return []dbprovider.QuarantineItem{}, nil
+	}
+
+	filter := bson.D{{Key: "_id.gId", Value: gameId}}
+	opts := options.Find().SetHint("QuarantineIndex").SetSort(bson.D{{Key: "_id.qId", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := p.queueCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dbprovider.QuarantineItem, 0)
+
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var mres MongoQuarantineItem
+		err := cursor.Decode(&mres)
+		if err != nil {
+			return nil, err
+		}
+
+		item := mres.QuarantineItem
+		item.Id = mres.MongoQuarantineID.Id
+		result = append(result, item)
+	}
+	err = cursor.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (p *MongoProvider) DeleteQuarantined(ctx context.Context, gameId string, id string) (*dbprovider.QuarantineItem, error) {
+	var mres MongoQuarantineItem
+	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "gId", Value: gameId}, {Key: "qId", Value: id}}}}
+	err := p.queueCollection.FindOneAndDelete(ctx, filter).Decode(&mres)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	item := mres.QuarantineItem
+	item.Id = mres.MongoQuarantineID.Id
+	return &item, nil
+}
+
+func (p *MongoProvider) PutAuditEntry(ctx context.Context, entry dbprovider.AuditEntry) error {
+	_, err := p.auditCollection.InsertOne(ctx, entry)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return dbprovider.ErrAuditConflict
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (p *MongoProvider) ListAuditEntries(ctx context.Context, fromSeq uint64, limit uint32) ([]dbprovider.AuditEntry, error) {
+	if limit == 0 {
+		return []dbprovider.AuditEntry{}, nil
+	}
+
+	filter := bson.D{{Key: "_id", Value: bson.D{{Key: "$gte", Value: fromSeq}}}}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := p.auditCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dbprovider.AuditEntry, 0)
+
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var entry dbprovider.AuditEntry
+		err := cursor.Decode(&entry)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, entry)
+	}
+	err = cursor.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (p *MongoProvider) LastAuditEntry(ctx context.Context) (*dbprovider.AuditEntry, error) {
+	var entry dbprovider.AuditEntry
+	opts := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+	err := p.auditCollection.FindOne(ctx, bson.D{}, opts).Decode(&entry)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (p *MongoProvider) ListChanges(ctx context.Context, gameId string, fromVersion uint64, limit uint32) ([]dbprovider.ChangeEntry, error) {
+	if limit == 0 {
+		return []dbprovider.ChangeEntry{}, nil
+	}
+
+	filter := bson.D{{Key: "_id.gId", Value: gameId}, {Key: "_id.v", Value: bson.D{{Key: "$gte", Value: fromVersion}}}}
+	opts := options.Find().SetHint("ChangesIndex").SetSort(bson.D{{Key: "_id.v", Value: 1}}).SetLimit(int64(limit))
+
+	return p.findChanges(ctx, filter, opts)
+}
+
+func (p *MongoProvider) LastChange(ctx context.Context, gameId string) (*dbprovider.ChangeEntry, error) {
+	filter := bson.D{{Key: "_id.gId", Value: gameId}}
+	opts := options.Find().SetHint("ChangesIndex").SetSort(bson.D{{Key: "_id.v", Value: -1}}).SetLimit(1)
+
+	changes, err := p.findChanges(ctx, filter, opts)
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+
+	return &changes[0], nil
+}
+
+func (p *MongoProvider) TrimChanges(ctx context.Context, gameId string, beforeVersion uint64) error {
+	filter := bson.D{{Key: "_id.gId", Value: gameId}, {Key: "_id.v", Value: bson.D{{Key: "$lt", Value: beforeVersion}}}}
+	_, err := p.changesCollection.DeleteMany(ctx, filter)
+
+	return err
+}
+
+func (p *MongoProvider) findChanges(ctx context.Context, filter bson.D, opts *options.FindOptions) ([]dbprovider.ChangeEntry, error) {
+	cursor, err := p.changesCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dbprovider.ChangeEntry, 0)
+
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var mres MongoChange
+		err := cursor.Decode(&mres)
+		if err != nil {
+			return nil, err
+		}
+
+		change := mres.ChangeEntry
+		change.Version = mres.MongoChangeID.Version
+		result = append(result, change)
+	}
+	err = cursor.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (p *MongoProvider) ListOutboxEvents(ctx context.Context, limit uint32) ([]dbprovider.ScoreEvent, error) {
+	if limit == 0 {
+		return []dbprovider.ScoreEvent{}, nil
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := p.outboxCollection.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dbprovider.ScoreEvent, 0)
+
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var event dbprovider.ScoreEvent
+		err := cursor.Decode(&event)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, event)
+	}
+	err = cursor.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (p *MongoProvider) DeleteOutboxEvent(ctx context.Context, id string) error {
+	_, err := p.outboxCollection.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (p *MongoProvider) Shutdown(ctx context.Context) error {
 	if p.client == nil {
 		return nil
@@ -0,0 +1,95 @@
+-- Upgrades a database created by the first version of mysql_setup.sql (UserData table only) to the current schema.
+-- MySQL can't add columns only if they are missing, so the ALTER TABLE statements are run once, the rest can be rerun
+
+ALTER TABLE UserData MODIFY gameId varchar(100) NOT NULL;
+ALTER TABLE UserData ADD COLUMN base double precision NOT NULL DEFAULT 0, ADD COLUMN ts bigint NOT NULL DEFAULT 0;
+CREATE INDEX TsIndex ON UserData (gameId ASC, ts ASC);
+
+-- entries submitted before the upgrade are treated as submitted at the time of the upgrade (for decay and expiration)
+UPDATE UserData SET base = score, ts = CAST(UNIX_TIMESTAMP(NOW(3)) * 1000 AS UNSIGNED) WHERE ts = 0;
+
+CREATE TABLE IF NOT EXISTS RunData (
+	gameId varchar(100) NOT NULL,
+	userId varchar(50) NOT NULL,
+	runId varchar(50) NOT NULL,
+	score double precision NOT NULL CHECK (score >= 0),
+	name varchar(50),
+	params varchar(255),
+	ts bigint NOT NULL DEFAULT 0,
+	PRIMARY KEY (gameId, userId, runId),
+	INDEX RunScoreIndex (gameId ASC, score DESC),
+	INDEX UserRunScoreIndex (gameId ASC, userId ASC, score DESC)
+);
+
+CREATE TABLE IF NOT EXISTS UserState (
+	gameId varchar(100) NOT NULL,
+	userId varchar(50) NOT NULL,
+	state smallint NOT NULL,
+	PRIMARY KEY (gameId, userId)
+);
+
+CREATE TABLE IF NOT EXISTS Quarantine (
+	gameId varchar(100) NOT NULL,
+	id varchar(50) NOT NULL,
+	userId varchar(50) NOT NULL,
+	ruleName varchar(50) NOT NULL,
+	reason varchar(255) NOT NULL,
+	score double precision NOT NULL,
+	name varchar(50),
+	params varchar(255),
+	runId varchar(50),
+	duration bigint NOT NULL DEFAULT 0,
+	ts bigint NOT NULL DEFAULT 0,
+	PRIMARY KEY (gameId, id)
+);
+
+CREATE TABLE IF NOT EXISTS Audit (
+	seq bigint NOT NULL,
+	ts bigint NOT NULL,
+	actor varchar(255) NOT NULL,
+	action varchar(50) NOT NULL,
+	gameId varchar(100) NOT NULL,
+	userId varchar(50) NOT NULL DEFAULT '',
+	beforeData text NOT NULL,
+	afterData text NOT NULL,
+	requestId varchar(255) NOT NULL DEFAULT '',
+	prevHash varchar(64) NOT NULL,
+	hash varchar(64) NOT NULL,
+	PRIMARY KEY (seq)
+);
+ALTER TABLE Audit ADD COLUMN tenant varchar(32) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS Changes (
+	gameId varchar(100) NOT NULL,
+	version bigint NOT NULL,
+	op varchar(10) NOT NULL,
+	userId varchar(50) NOT NULL,
+	runId varchar(50),
+	score double precision NOT NULL,
+	name varchar(50),
+	params varchar(255),
+	ts bigint NOT NULL,
+	PRIMARY KEY (gameId, version)
+);
+
+CREATE TABLE IF NOT EXISTS Outbox (
+	id varchar(50) NOT NULL,
+	gameId varchar(100) NOT NULL,
+	userId varchar(50) NOT NULL,
+	runId varchar(50),
+	oldScore double precision NOT NULL,
+	newScore double precision NOT NULL,
+	oldRank integer NOT NULL,
+	newRank integer NOT NULL,
+	ts bigint NOT NULL,
+	PRIMARY KEY (id)
+);
+
+-- Sequence of ids of outbox events (a single row)
+CREATE TABLE IF NOT EXISTS OutboxSequence (
+	id integer NOT NULL,
+	seq bigint NOT NULL,
+	PRIMARY KEY (id)
+);
+
+INSERT IGNORE INTO OutboxSequence VALUES (1, 0);
@@ -1,10 +1,100 @@
 CREATE TABLE UserData (
-	gameId varchar(50) NOT NULL,
+	gameId varchar(100) NOT NULL,
 	userId varchar(50) NOT NULL,
 	score double precision NOT NULL CHECK (score >= 0),
 	name varchar(50),
 	params varchar(255),
+	base double precision NOT NULL DEFAULT 0,
+	ts bigint NOT NULL DEFAULT 0,
 	PRIMARY KEY (gameId, userId)
 );
 
-CREATE INDEX ScoreIndex ON UserData (gameId ASC, score DESC);
\ No newline at end of file
+CREATE INDEX ScoreIndex ON UserData (gameId ASC, score DESC);
+CREATE INDEX TsIndex ON UserData (gameId ASC, ts ASC);
+
+CREATE TABLE RunData (
+	gameId varchar(100) NOT NULL,
+	userId varchar(50) NOT NULL,
+	runId varchar(50) NOT NULL,
+	score double precision NOT NULL CHECK (score >= 0),
+	name varchar(50),
+	params varchar(255),
+	ts bigint NOT NULL DEFAULT 0,
+	PRIMARY KEY (gameId, userId, runId)
+);
+
+CREATE INDEX RunScoreIndex ON RunData (gameId ASC, score DESC);
+CREATE INDEX UserRunScoreIndex ON RunData (gameId ASC, userId ASC, score DESC);
+
+CREATE TABLE UserState (
+	gameId varchar(100) NOT NULL,
+	userId varchar(50) NOT NULL,
+	state smallint NOT NULL,
+	PRIMARY KEY (gameId, userId)
+);
+
+CREATE TABLE Quarantine (
+	gameId varchar(100) NOT NULL,
+	id varchar(50) NOT NULL,
+	userId varchar(50) NOT NULL,
+	ruleName varchar(50) NOT NULL,
+	reason varchar(255) NOT NULL,
+	score double precision NOT NULL,
+	name varchar(50),
+	params varchar(255),
+	runId varchar(50),
+	duration bigint NOT NULL DEFAULT 0,
+	ts bigint NOT NULL DEFAULT 0,
+	PRIMARY KEY (gameId, id)
+);
+
+CREATE TABLE Audit (
+	seq bigint NOT NULL,
+	ts bigint NOT NULL,
+	actor varchar(255) NOT NULL,
+	action varchar(50) NOT NULL,
+	tenant varchar(32) NOT NULL DEFAULT '',
+	gameId varchar(100) NOT NULL,
+	userId varchar(50) NOT NULL DEFAULT '',
+	beforeData text NOT NULL,
+	afterData text NOT NULL,
+	requestId varchar(255) NOT NULL DEFAULT '',
+	prevHash varchar(64) NOT NULL,
+	hash varchar(64) NOT NULL,
+	PRIMARY KEY (seq)
+);
+
+CREATE TABLE Changes (
+	gameId varchar(100) NOT NULL,
+	version bigint NOT NULL,
+	op varchar(10) NOT NULL,
+	userId varchar(50) NOT NULL,
+	runId varchar(50),
+	score double precision NOT NULL,
+	name varchar(50),
+	params varchar(255),
+	ts bigint NOT NULL,
+	PRIMARY KEY (gameId, version)
+);
+
+CREATE TABLE Outbox (
+	id varchar(50) NOT NULL,
+	gameId varchar(100) NOT NULL,
+	userId varchar(50) NOT NULL,
+	runId varchar(50),
+	oldScore double precision NOT NULL,
+	newScore double precision NOT NULL,
+	oldRank integer NOT NULL,
+	newRank integer NOT NULL,
+	ts bigint NOT NULL,
+	PRIMARY KEY (id)
+);
+
+-- Sequence of ids of outbox events (a single row)
+CREATE TABLE OutboxSequence (
+	id integer NOT NULL,
+	seq bigint NOT NULL,
+	PRIMARY KEY (id)
+);
+
+INSERT INTO OutboxSequence VALUES (1, 0);
@@ -7,10 +7,11 @@ import (
 	"fmt"
 	dbprovider "go-leaderboard-server/internal/db"
 	log "go-leaderboard-server/internal/logger"
+	"strings"
 	"time"
 
 	"github.com/georgysavva/scany/sqlscan"
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
 var logger = log.GetLogger()
@@ -28,6 +29,8 @@ type MySqlUserProperties struct {
 	Score  dbprovider.UScoreType `db:"score"`
 	Name   *string               `db:"name"`
 	Params *string               `db:"params"`
+	Base   dbprovider.UScoreType `db:"base"`
+	Ts     int64                 `db:"ts"`
 }
 
 type MySqlUserData struct {
@@ -35,7 +38,81 @@ type MySqlUserData struct {
 	MySqlUserProperties
 }
 
+type MySqlRunProperties struct {
+	RunId  string                `db:"runId"`
+	Score  dbprovider.UScoreType `db:"score"`
+	Name   *string               `db:"name"`
+	Params *string               `db:"params"`
+	Ts     int64                 `db:"ts"`
+}
+
+type MySqlRunData struct {
+	UserId string `db:"userId"`
+	MySqlRunProperties
+}
+
+// Same fields as dbprovider.AuditEntry, so they are convertible to each other
+type MySqlAuditEntry struct {
+	Seq       uint64 `db:"seq"`
+	Ts        int64  `db:"ts"`
+	Actor     string `db:"actor"`
+	Action    string `db:"action"`
+	Tenant    string `db:"tenant"`
+	GameId    string `db:"gameId"`
+	UserId    string `db:"userId"`
+	Before    string `db:"beforeData"`
+	After     string `db:"afterData"`
+	RequestId string `db:"requestId"`
+	PrevHash  string `db:"prevHash"`
+	Hash      string `db:"hash"`
+}
+
+type MySqlChangeEntry struct {
+	Version uint64                `db:"version"`
+	Op      string                `db:"op"`
+	UserId  string                `db:"userId"`
+	RunId   *string               `db:"runId"`
+	Score   dbprovider.UScoreType `db:"score"`
+	Name    *string               `db:"name"`
+	Params  *string               `db:"params"`
+	Ts      int64                 `db:"ts"`
+}
+
+type MySqlScoreEvent struct {
+	Id       string                `db:"id"`
+	GameId   string                `db:"gameId"`
+	UserId   string                `db:"userId"`
+	RunId    *string               `db:"runId"`
+	OldScore dbprovider.UScoreType `db:"oldScore"`
+	NewScore dbprovider.UScoreType `db:"newScore"`
+	OldRank  int                   `db:"oldRank"`
+	NewRank  int                   `db:"newRank"`
+	Ts       int64                 `db:"ts"`
+}
+
+type MySqlQuarantineItem struct {
+	Id       string                `db:"id"`
+	UserId   string                `db:"userId"`
+	Rule     string                `db:"ruleName"`
+	Reason   string                `db:"reason"`
+	Score    dbprovider.UScoreType `db:"score"`
+	Name     *string               `db:"name"`
+	Params   *string               `db:"params"`
+	RunId    *string               `db:"runId"`
+	Duration int64                 `db:"duration"`
+	Ts       int64                 `db:"ts"`
+}
+
 const DB_TABLE_NAME string = "UserData"
+const DB_RUNS_TABLE_NAME string = "RunData"
+const DB_STATES_TABLE_NAME string = "UserState"
+const DB_QUARANTINE_TABLE_NAME string = "Quarantine"
+const DB_AUDIT_TABLE_NAME string = "Audit"
+const DB_CHANGES_TABLE_NAME string = "Changes"
+const DB_OUTBOX_TABLE_NAME string = "Outbox"
+const DB_OUTBOX_SEQUENCE_TABLE_NAME string = "OutboxSequence"
+
+const ER_DUP_ENTRY = 1062 // MySQL error of a duplicate key
 
 type MySqlProvider struct {
 	db *sql.DB
@@ -59,6 +136,58 @@ func toString(s *string) string {
 	return *s
 }
 
+func toRunProperties(run MySqlRunProperties) dbprovider.RunProperties {
+	return dbprovider.RunProperties{
+		RunId:  run.RunId,
+		Score:  run.Score,
+		Name:   toString(run.Name),
+		Params: toString(run.Params),
+		Ts:     run.Ts,
+	}
+}
+
+func toQuarantineItem(item MySqlQuarantineItem) dbprovider.QuarantineItem {
+	return dbprovider.QuarantineItem{
+		Id:       item.Id,
+		UserId:   item.UserId,
+		Rule:     item.Rule,
+		Reason:   item.Reason,
+		Score:    item.Score,
+		Name:     toString(item.Name),
+		Params:   toString(item.Params),
+		RunId:    toString(item.RunId),
+		Duration: uint32(item.Duration),
+		Ts:       item.Ts,
+	}
+}
+
+func toChangeEntry(change MySqlChangeEntry) dbprovider.ChangeEntry {
+	return dbprovider.ChangeEntry{
+		Version: change.Version,
+		Op:      change.Op,
+		UserId:  change.UserId,
+		RunId:   toString(change.RunId),
+		Score:   change.Score,
+		Name:    toString(change.Name),
+		Params:  toString(change.Params),
+		Ts:      change.Ts,
+	}
+}
+
+func toScoreEvent(event MySqlScoreEvent) dbprovider.ScoreEvent {
+	return dbprovider.ScoreEvent{
+		Id:       event.Id,
+		GameId:   event.GameId,
+		UserId:   event.UserId,
+		RunId:    toString(event.RunId),
+		OldScore: event.OldScore,
+		NewScore: event.NewScore,
+		OldRank:  event.OldRank,
+		NewRank:  event.NewRank,
+		Ts:       event.Ts,
+	}
+}
+
 func (p *MySqlProvider) Initialize(ctx context.Context, config dbprovider.IDBProviderConfig) error {
 	logger.Debug("DB provider initialization")
 
@@ -88,30 +217,160 @@ func (p *MySqlProvider) Initialize(ctx context.Context, config dbprovider.IDBPro
 	return nil
 }
 
-func (p *MySqlProvider) Put(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties) error {
-	_, err := p.db.ExecContext(ctx,
-		fmt.Sprintf(`INSERT INTO %s VALUES (?, ?, ?, ?, ?) AS new
-			ON DUPLICATE KEY UPDATE
-			score = new.score, name = new.name, params = new.params`, DB_TABLE_NAME),
-		gameId, userId, userProp.Score, toStringOrNull(userProp.Name), toStringOrNull(userProp.Params),
+// Runs fn in a transaction, which is committed if fn succeeds and rolled back otherwise
+func (p *MySqlProvider) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Runs the write in a transaction recording the changes of rec to the change feed of the game. The write returns
+// deletes of entries (runs) it removed besides the changes of rec, they are recorded after them. A taken version
+// of a change fails the transaction with ErrChangeConflict
+func (p *MySqlProvider) recordWrite(ctx context.Context, gameId string, rec dbprovider.WriteRecords,
+	write func(tx *sql.Tx) ([]dbprovider.ChangeEntry, error)) error {
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		removed, err := write(tx)
+		if err != nil {
+			return err
+		}
+
+		err = insertChanges(ctx, tx, gameId, rec.Entries(removed...))
+		if err != nil || rec.Event == nil {
+			return err
+		}
+
+		return insertOutboxEvent(ctx, tx, *rec.Event)
+	})
+}
+
+// Stores the event to the outbox with the next id of the sequence. The sequence row stays locked until the commit,
+// so ids are assigned in the order of commits
+func insertOutboxEvent(ctx context.Context, tx *sql.Tx, event dbprovider.ScoreEvent) error {
+	result, err := tx.ExecContext(ctx,
+		fmt.Sprintf(`UPDATE %s SET seq = LAST_INSERT_ID(seq + 1) WHERE id = 1`, DB_OUTBOX_SEQUENCE_TABLE_NAME),
+	)
+	if err != nil {
+		return err
+	}
+	seq, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		fmt.Sprintf(`INSERT INTO %s VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`, DB_OUTBOX_TABLE_NAME),
+		dbprovider.OutboxEventId(uint64(seq)), event.GameId, event.UserId, toStringOrNull(event.RunId), event.OldScore, event.NewScore,
+		event.OldRank, event.NewRank, event.Ts,
 	)
 
 	return err
 }
 
-func (p *MySqlProvider) Delete(ctx context.Context, gameId string, userId string) error {
-	_, err := p.db.ExecContext(ctx,
-		fmt.Sprintf(`DELETE FROM %s WHERE gameId = ? AND userId = ?`, DB_TABLE_NAME),
-		gameId, userId,
+// Appends the changes to the change feed of the game, returns ErrChangeConflict if a version is taken already
+func insertChanges(ctx context.Context, tx *sql.Tx, gameId string, changes []dbprovider.ChangeEntry) error {
+	for _, change := range changes {
+		_, err := tx.ExecContext(ctx,
+			fmt.Sprintf(`INSERT INTO %s VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`, DB_CHANGES_TABLE_NAME),
+			gameId, change.Version, change.Op, change.UserId, toStringOrNull(change.RunId), change.Score,
+			toStringOrNull(change.Name), toStringOrNull(change.Params), change.Ts,
+		)
+		if err != nil {
+			var mysqlErr *mysql.MySQLError
+			if errors.As(err, &mysqlErr) && mysqlErr.Number == ER_DUP_ENTRY {
+				return dbprovider.ErrChangeConflict
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Locks the entries (userId, runId) selected by the query for removal and returns their deletes
+func selectForRemoval(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]dbprovider.ChangeEntry, error) {
+	rows, err := tx.QueryContext(ctx, query+" FOR UPDATE", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	removed := []dbprovider.ChangeEntry{}
+	for rows.Next() {
+		change := dbprovider.ChangeEntry{Op: dbprovider.CHANGEOP_DELETE}
+		err = rows.Scan(&change.UserId, &change.RunId)
+		if err != nil {
+			return nil, err
+		}
+		removed = append(removed, change)
+	}
+
+	return removed, rows.Err()
+}
+
+// Removes the selected entries of users of the game
+func (p *MySqlProvider) removeUsers(ctx context.Context, tx *sql.Tx, gameId string, removed []dbprovider.ChangeEntry) error {
+	if len(removed) == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, len(removed)+1)
+	args = append(args, gameId)
+	for _, change := range removed {
+		args = append(args, change.UserId)
+	}
+	_, err := tx.ExecContext(ctx,
+		fmt.Sprintf(`DELETE FROM %s WHERE gameId = ? AND userId IN (%s)`, DB_TABLE_NAME, placeholders(len(removed))),
+		args...,
 	)
 
 	return err
 }
 
+// Returns n comma separated placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func (p *MySqlProvider) Put(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties, rec dbprovider.WriteRecords) error {
+	return p.recordWrite(ctx, gameId, rec, func(tx *sql.Tx) ([]dbprovider.ChangeEntry, error) {
+		_, err := tx.ExecContext(ctx,
+			fmt.Sprintf(`INSERT INTO %s VALUES (?, ?, ?, ?, ?, ?, ?) AS new
+				ON DUPLICATE KEY UPDATE
+				score = new.score, name = new.name, params = new.params,
+				base = new.base, ts = new.ts`, DB_TABLE_NAME),
+			gameId, userId, userProp.Score, toStringOrNull(userProp.Name), toStringOrNull(userProp.Params),
+			userProp.Base, userProp.Ts,
+		)
+		return nil, err
+	})
+}
+
+func (p *MySqlProvider) Delete(ctx context.Context, gameId string, userId string, rec dbprovider.WriteRecords) error {
+	return p.recordWrite(ctx, gameId, rec, func(tx *sql.Tx) ([]dbprovider.ChangeEntry, error) {
+		_, err := tx.ExecContext(ctx,
+			fmt.Sprintf(`DELETE FROM %s WHERE gameId = ? AND userId = ?`, DB_TABLE_NAME),
+			gameId, userId,
+		)
+		return nil, err
+	})
+}
+
 func (p *MySqlProvider) Get(ctx context.Context, gameId string, userId string) (*dbprovider.UserProperties, error) {
 	var err error
 	rows, err := p.db.QueryContext(ctx,
-		fmt.Sprintf(`SELECT score, name, params FROM %s WHERE gameId = ? AND userId = ?`, DB_TABLE_NAME),
+		fmt.Sprintf(`SELECT score, name, params, base, ts FROM %s WHERE gameId = ? AND userId = ?`, DB_TABLE_NAME),
 		gameId, userId,
 	)
 	if err != nil {
@@ -131,15 +390,31 @@ func (p *MySqlProvider) Get(ctx context.Context, gameId string, userId string) (
 		Score:  uprop.Score,
 		Name:   toString(uprop.Name),
 		Params: toString(uprop.Params),
+		Base:   uprop.Base,
+		Ts:     uprop.Ts,
 	}, nil
 }
 
-func (p *MySqlProvider) Top(ctx context.Context, gameId string, nTop uint32) (dbprovider.TopData, error) {
+// Returns the columns of a top query, omitted fields are not selected
+func topColumns(omitFields dbprovider.TopFields) string {
+	columns := `userId as "userId", score, base, ts`
+	if omitFields&dbprovider.TOPFIELD_NAME == 0 {
+		columns += ", name"
+	}
+	if omitFields&dbprovider.TOPFIELD_PARAMS == 0 {
+		columns += ", params"
+	}
+	return columns
+}
+
+func (p *MySqlProvider) Top(ctx context.Context, gameId string, nTop uint32, opts dbprovider.TopOptions) (dbprovider.TopData, error) {
 	var err error
 	rows, err := p.db.QueryContext(ctx,
-		fmt.Sprintf(`SELECT userId as "userId", score, name, params FROM %s
-			WHERE gameId = ? ORDER BY gameId ASC, score DESC LIMIT ?`, DB_TABLE_NAME),
-		gameId, nTop,
+		fmt.Sprintf(`SELECT %s FROM %s
+			WHERE gameId = ? AND (? = 0 OR ts >= ?)
+			AND userId NOT IN (SELECT userId FROM %s WHERE gameId = ?)
+			ORDER BY gameId ASC, score DESC LIMIT ?`, topColumns(opts.OmitFields), DB_TABLE_NAME, DB_STATES_TABLE_NAME),
+		gameId, opts.MinTs, opts.MinTs, gameId, nTop,
 	)
 	if err != nil {
 		return dbprovider.TopData{}, err
@@ -159,6 +434,8 @@ func (p *MySqlProvider) Top(ctx context.Context, gameId string, nTop uint32) (db
 				Score:  udata.Score,
 				Name:   toString(udata.Name),
 				Params: toString(udata.Params),
+				Base:   udata.Base,
+				Ts:     udata.Ts,
 			},
 		})
 	}
@@ -166,6 +443,534 @@ func (p *MySqlProvider) Top(ctx context.Context, gameId string, nTop uint32) (db
 	return result, nil
 }
 
+func (p *MySqlProvider) Inactive(ctx context.Context, gameId string, before int64, above dbprovider.UScoreType, fn func(dbprovider.UserData) error) error {
+	// pages are read by the primary key, so updates of the entries don't move them between pages
+	lastUserId := ""
+	for {
+		rows, err := p.db.QueryContext(ctx,
+			fmt.Sprintf(`SELECT userId as "userId", score, name, params, base, ts FROM %s
+				WHERE gameId = ? AND ts < ? AND score > ? AND userId > ? ORDER BY userId LIMIT ?`, DB_TABLE_NAME),
+			gameId, before, above, lastUserId, dbprovider.INACTIVE_PAGE_SIZE,
+		)
+		if err != nil {
+			return err
+		}
+
+		var entries []MySqlUserData
+		err = sqlscan.ScanAll(&entries, rows)
+		if err != nil {
+			return err
+		}
+
+		for _, udata := range entries {
+			err = fn(dbprovider.UserData{
+				UserId: udata.UserId,
+				UserProperties: dbprovider.UserProperties{
+					Score:  udata.Score,
+					Name:   toString(udata.Name),
+					Params: toString(udata.Params),
+					Base:   udata.Base,
+					Ts:     udata.Ts,
+				},
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < dbprovider.INACTIVE_PAGE_SIZE {
+			return nil
+		}
+		lastUserId = entries[len(entries)-1].UserId
+	}
+}
+
+func (p *MySqlProvider) SetScore(ctx context.Context, gameId string, userId string, score dbprovider.UScoreType, ts int64, rec dbprovider.WriteRecords) (bool, error) {
+	updated := false
+	err := p.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			fmt.Sprintf(`UPDATE %s SET score = ? WHERE gameId = ? AND userId = ? AND ts = ?`, DB_TABLE_NAME),
+			score, gameId, userId, ts,
+		)
+		if err != nil {
+			return err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		updated = n > 0
+		if !updated {
+			return nil // nothing to record
+		}
+		return insertChanges(ctx, tx, gameId, rec.Entries())
+	})
+
+	return updated, err
+}
+
+func (p *MySqlProvider) Expire(ctx context.Context, gameId string, before int64, rec dbprovider.WriteRecords) (uint32, error) {
+	var removed []dbprovider.ChangeEntry
+	rec.Changes = nil // only deletes of removed entries are recorded
+	err := p.recordWrite(ctx, gameId, rec, func(tx *sql.Tx) ([]dbprovider.ChangeEntry, error) {
+		var err error
+		removed, err = selectForRemoval(ctx, tx,
+			fmt.Sprintf(`SELECT userId, '' FROM %s WHERE gameId = ? AND ts < ?`, DB_TABLE_NAME),
+			gameId, before,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return removed, p.removeUsers(ctx, tx, gameId, removed)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(len(removed)), nil
+}
+
+func (p *MySqlProvider) Trim(ctx context.Context, gameId string, maxEntries uint32, rec dbprovider.WriteRecords) (uint32, error) {
+	var removed []dbprovider.ChangeEntry
+	rec.Changes = nil // only deletes of removed entries are recorded
+	err := p.recordWrite(ctx, gameId, rec, func(tx *sql.Tx) ([]dbprovider.ChangeEntry, error) {
+		// MySQL does not support OFFSET without LIMIT, so the maximum possible limit is used
+		var err error
+		removed, err = selectForRemoval(ctx, tx,
+			fmt.Sprintf(`SELECT userId, '' FROM %s WHERE gameId = ?
+				ORDER BY gameId ASC, score DESC LIMIT 18446744073709551615 OFFSET ?`, DB_TABLE_NAME),
+			gameId, maxEntries,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return removed, p.removeUsers(ctx, tx, gameId, removed)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(len(removed)), nil
+}
+
+func (p *MySqlProvider) Count(ctx context.Context, gameId string) (uint64, error) {
+	var n uint64
+	err := p.db.QueryRowContext(ctx,
+		fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE gameId = ?`, DB_TABLE_NAME),
+		gameId,
+	).Scan(&n)
+
+	return n, err
+}
+
+func (p *MySqlProvider) PutRun(ctx context.Context, gameId string, userId string, run dbprovider.RunProperties, maxRuns uint32, rec dbprovider.WriteRecords) (uint32, error) {
+	var evicted []dbprovider.ChangeEntry
+	err := p.recordWrite(ctx, gameId, rec, func(tx *sql.Tx) ([]dbprovider.ChangeEntry, error) {
+		_, err := tx.ExecContext(ctx,
+			fmt.Sprintf(`INSERT INTO %s VALUES (?, ?, ?, ?, ?, ?, ?) AS new
+				ON DUPLICATE KEY UPDATE
+				score = new.score, name = new.name, params = new.params, ts = new.ts`, DB_RUNS_TABLE_NAME),
+			gameId, userId, run.RunId, run.Score, toStringOrNull(run.Name), toStringOrNull(run.Params), run.Ts,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		evicted, err = selectForRemoval(ctx, tx,
+			fmt.Sprintf(`SELECT userId, runId FROM %s WHERE gameId = ? AND userId = ?
+				ORDER BY gameId ASC, userId ASC, score DESC LIMIT 18446744073709551615 OFFSET ?`, DB_RUNS_TABLE_NAME),
+			gameId, userId, maxRuns,
+		)
+		if err != nil || len(evicted) == 0 {
+			return evicted, err
+		}
+
+		args := []any{gameId, userId}
+		for _, change := range evicted {
+			args = append(args, change.RunId)
+		}
+		_, err = tx.ExecContext(ctx,
+			fmt.Sprintf(`DELETE FROM %s WHERE gameId = ? AND userId = ? AND runId IN (%s)`, DB_RUNS_TABLE_NAME, placeholders(len(evicted))),
+			args...,
+		)
+		return evicted, err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(len(evicted)), nil
+}
+
+func (p *MySqlProvider) DeleteRuns(ctx context.Context, gameId string, userId string, rec dbprovider.WriteRecords) error {
+	return p.recordWrite(ctx, gameId, rec, func(tx *sql.Tx) ([]dbprovider.ChangeEntry, error) {
+		_, err := tx.ExecContext(ctx,
+			fmt.Sprintf(`DELETE FROM %s WHERE gameId = ? AND userId = ?`, DB_RUNS_TABLE_NAME),
+			gameId, userId,
+		)
+		return nil, err
+	})
+}
+
+func (p *MySqlProvider) GetRuns(ctx context.Context, gameId string, userId string) ([]dbprovider.RunProperties, error) {
+	var err error
+	rows, err := p.db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT runId as "runId", score, name, params, ts FROM %s
+			WHERE gameId = ? AND userId = ? ORDER BY gameId ASC, userId ASC, score DESC`, DB_RUNS_TABLE_NAME),
+		gameId, userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []MySqlRunProperties
+	err = sqlscan.ScanAll(&runs, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dbprovider.RunProperties, 0, len(runs))
+	for _, run := range runs {
+		result = append(result, toRunProperties(run))
+	}
+
+	return result, nil
+}
+
+func (p *MySqlProvider) TopRuns(ctx context.Context, gameId string, nTop uint32) (dbprovider.RunTopData, error) {
+	var err error
+	rows, err := p.db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT userId as "userId", runId as "runId", score, name, params, ts FROM %s
+			WHERE gameId = ? AND userId NOT IN (SELECT userId FROM %s WHERE gameId = ?)
+			ORDER BY gameId ASC, score DESC LIMIT ?`, DB_RUNS_TABLE_NAME, DB_STATES_TABLE_NAME),
+		gameId, gameId, nTop,
+	)
+	if err != nil {
+		return dbprovider.RunTopData{}, err
+	}
+
+	var top []MySqlRunData
+	err = sqlscan.ScanAll(&top, rows)
+	if err != nil {
+		return dbprovider.RunTopData{}, err
+	}
+
+	var result dbprovider.RunTopData = make(dbprovider.RunTopData, 0, len(top))
+	for _, rdata := range top {
+		result = append(result, dbprovider.RunData{
+			UserId:        rdata.UserId,
+			RunProperties: toRunProperties(rdata.MySqlRunProperties),
+		})
+	}
+
+	return result, nil
+}
+
+func (p *MySqlProvider) CountRuns(ctx context.Context, gameId string) (uint64, error) {
+	var n uint64
+	err := p.db.QueryRowContext(ctx,
+		fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE gameId = ?`, DB_RUNS_TABLE_NAME),
+		gameId,
+	).Scan(&n)
+
+	return n, err
+}
+
+func (p *MySqlProvider) SetUserState(ctx context.Context, gameId string, userId string, state dbprovider.UserState, rec dbprovider.WriteRecords) error {
+	return p.recordWrite(ctx, gameId, rec, func(tx *sql.Tx) ([]dbprovider.ChangeEntry, error) {
+		var err error
+		if state == dbprovider.USERSTATE_VISIBLE {
+			_, err = tx.ExecContext(ctx,
+				fmt.Sprintf(`DELETE FROM %s WHERE gameId = ? AND userId = ?`, DB_STATES_TABLE_NAME),
+				gameId, userId,
+			)
+		} else {
+			_, err = tx.ExecContext(ctx,
+				fmt.Sprintf(`INSERT INTO %s VALUES (?, ?, ?) AS new
+					ON DUPLICATE KEY UPDATE state = new.state`, DB_STATES_TABLE_NAME),
+				gameId, userId, int(state),
+			)
+		}
+		return nil, err
+	})
+}
+
+func (p *MySqlProvider) GetUserState(ctx context.Context, gameId string, userId string) (dbprovider.UserState, error) {
+	var state int
+	err := p.db.QueryRowContext(ctx,
+		fmt.Sprintf(`SELECT state FROM %s WHERE gameId = ? AND userId = ?`, DB_STATES_TABLE_NAME),
+		gameId, userId,
+	).Scan(&state)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return dbprovider.USERSTATE_VISIBLE, nil
+		}
+		return dbprovider.USERSTATE_VISIBLE, err
+	}
+
+	return dbprovider.UserState(state), nil
+}
+
+func (p *MySqlProvider) PutQuarantined(ctx context.Context, gameId string, item dbprovider.QuarantineItem) error {
+	_, err := p.db.ExecContext(ctx,
+		fmt.Sprintf(`INSERT IGNORE INTO %s VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, DB_QUARANTINE_TABLE_NAME),
+		gameId, item.Id, item.UserId, item.Rule, item.Reason, item.Score,
+		toStringOrNull(item.Name), toStringOrNull(item.Params), toStringOrNull(item.RunId), item.Duration, item.Ts,
+	)
+
+	return err
+}
+
+func (p *MySqlProvider) GetQuarantined(ctx context.Context, gameId string, id string) (*dbprovider.QuarantineItem, error) {
+	var err error
+	rows, err := p.db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT id, userId as "userId", ruleName as "ruleName", reason, score, name, params,
+			runId as "runId", duration, ts FROM %s WHERE gameId = ? AND id = ?`, DB_QUARANTINE_TABLE_NAME),
+		gameId, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var mItem MySqlQuarantineItem
+	err = sqlscan.ScanOne(&mItem, rows)
+	if err != nil {
+		if sqlscan.NotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	item := toQuarantineItem(mItem)
+	return &item, nil
+}
+
+func (p *MySqlProvider) ListQuarantined(ctx context.Context, gameId string, limit uint32) ([]dbprovider.QuarantineItem, error) {
+	var err error
+	rows, err := p.db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT id, userId as "userId", ruleName as "ruleName", reason, score, name, params,
+			runId as "runId", duration, ts FROM %s WHERE gameId = ? ORDER BY gameId ASC, id ASC LIMIT ?`, DB_QUARANTINE_TABLE_NAME),
+		gameId, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []MySqlQuarantineItem
+	err = sqlscan.ScanAll(&items, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dbprovider.QuarantineItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, toQuarantineItem(item))
+	}
+
+	return result, nil
+}
+
+func (p *MySqlProvider) DeleteQuarantined(ctx context.Context, gameId string, id string) (*dbprovider.QuarantineItem, error) {
+	var item *dbprovider.QuarantineItem
+	err := p.inTx(ctx, func(tx *sql.Tx) error {
+		// the row is locked, so only one of concurrent callers reads it before it's removed
+		rows, err := tx.QueryContext(ctx,
+			fmt.Sprintf(`SELECT id, userId as "userId", ruleName as "ruleName", reason, score, name, params,
+				runId as "runId", duration, ts FROM %s WHERE gameId = ? AND id = ? FOR UPDATE`, DB_QUARANTINE_TABLE_NAME),
+			gameId, id,
+		)
+		if err != nil {
+			return err
+		}
+
+		var mItem MySqlQuarantineItem
+		err = sqlscan.ScanOne(&mItem, rows)
+		if err != nil {
+			if sqlscan.NotFound(err) {
+				return nil
+			}
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			fmt.Sprintf(`DELETE FROM %s WHERE gameId = ? AND id = ?`, DB_QUARANTINE_TABLE_NAME),
+			gameId, id,
+		)
+		if err != nil {
+			return err
+		}
+
+		deleted := toQuarantineItem(mItem)
+		item = &deleted
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (p *MySqlProvider) PutAuditEntry(ctx context.Context, entry dbprovider.AuditEntry) error {
+	_, err := p.db.ExecContext(ctx,
+		fmt.Sprintf(`INSERT INTO %s (seq, ts, actor, action, tenant, gameId, userId, beforeData, afterData, requestId, prevHash, hash)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, DB_AUDIT_TABLE_NAME),
+		entry.Seq, entry.Ts, entry.Actor, entry.Action, entry.Tenant, entry.GameId, entry.UserId,
+		entry.Before, entry.After, entry.RequestId, entry.PrevHash, entry.Hash,
+	)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == ER_DUP_ENTRY {
+			return dbprovider.ErrAuditConflict
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (p *MySqlProvider) ListAuditEntries(ctx context.Context, fromSeq uint64, limit uint32) ([]dbprovider.AuditEntry, error) {
+	var err error
+	rows, err := p.db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT seq, ts, actor, action, tenant, gameId as "gameId", userId as "userId", beforeData as "beforeData",
+			afterData as "afterData", requestId as "requestId", prevHash as "prevHash", hash
+			FROM %s WHERE seq >= ? ORDER BY seq ASC LIMIT ?`, DB_AUDIT_TABLE_NAME),
+		fromSeq, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []MySqlAuditEntry
+	err = sqlscan.ScanAll(&entries, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dbprovider.AuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, dbprovider.AuditEntry(entry))
+	}
+
+	return result, nil
+}
+
+func (p *MySqlProvider) LastAuditEntry(ctx context.Context) (*dbprovider.AuditEntry, error) {
+	var err error
+	rows, err := p.db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT seq, ts, actor, action, tenant, gameId as "gameId", userId as "userId", beforeData as "beforeData",
+			afterData as "afterData", requestId as "requestId", prevHash as "prevHash", hash
+			FROM %s ORDER BY seq DESC LIMIT 1`, DB_AUDIT_TABLE_NAME),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var mEntry MySqlAuditEntry
+	err = sqlscan.ScanOne(&mEntry, rows)
+	if err != nil {
+		if sqlscan.NotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	entry := dbprovider.AuditEntry(mEntry)
+	return &entry, nil
+}
+
+func (p *MySqlProvider) ListChanges(ctx context.Context, gameId string, fromVersion uint64, limit uint32) ([]dbprovider.ChangeEntry, error) {
+	var err error
+	rows, err := p.db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT version, op, userId as "userId", runId as "runId", score, name, params, ts
+			FROM %s WHERE gameId = ? AND version >= ? ORDER BY gameId ASC, version ASC LIMIT ?`, DB_CHANGES_TABLE_NAME),
+		gameId, fromVersion, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []MySqlChangeEntry
+	err = sqlscan.ScanAll(&changes, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dbprovider.ChangeEntry, 0, len(changes))
+	for _, change := range changes {
+		result = append(result, toChangeEntry(change))
+	}
+
+	return result, nil
+}
+
+func (p *MySqlProvider) LastChange(ctx context.Context, gameId string) (*dbprovider.ChangeEntry, error) {
+	var err error
+	rows, err := p.db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT version, op, userId as "userId", runId as "runId", score, name, params, ts
+			FROM %s WHERE gameId = ? ORDER BY gameId DESC, version DESC LIMIT 1`, DB_CHANGES_TABLE_NAME),
+		gameId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var mChange MySqlChangeEntry
+	err = sqlscan.ScanOne(&mChange, rows)
+	if err != nil {
+		if sqlscan.NotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	change := toChangeEntry(mChange)
+	return &change, nil
+}
+
+func (p *MySqlProvider) TrimChanges(ctx context.Context, gameId string, beforeVersion uint64) error {
+	_, err := p.db.ExecContext(ctx,
+		fmt.Sprintf(`DELETE FROM %s WHERE gameId = ? AND version < ?`, DB_CHANGES_TABLE_NAME),
+		gameId, beforeVersion,
+	)
+
+	return err
+}
+
+func (p *MySqlProvider) ListOutboxEvents(ctx context.Context, limit uint32) ([]dbprovider.ScoreEvent, error) {
+	var err error
+	rows, err := p.db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT id, gameId as "gameId", userId as "userId", runId as "runId", oldScore as "oldScore",
+			newScore as "newScore", oldRank as "oldRank", newRank as "newRank", ts FROM %s ORDER BY id ASC LIMIT ?`, DB_OUTBOX_TABLE_NAME),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []MySqlScoreEvent
+	err = sqlscan.ScanAll(&events, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dbprovider.ScoreEvent, 0, len(events))
+	for _, event := range events {
+		result = append(result, toScoreEvent(event))
+	}
+
+	return result, nil
+}
+
+func (p *MySqlProvider) DeleteOutboxEvent(ctx context.Context, id string) error {
+	_, err := p.db.ExecContext(ctx,
+		fmt.Sprintf(`DELETE FROM %s WHERE id = ?`, DB_OUTBOX_TABLE_NAME),
+		id,
+	)
+
+	return err
+}
+
 func (p *MySqlProvider) Shutdown(ctx context.Context) error {
 	if p.db == nil {
 		return nil
@@ -0,0 +1,98 @@
+-- Upgrades a database created by an earlier version of postgresql_setup.sql to the current schema.
+-- The script can be run more than once, existing data is kept
+
+ALTER TABLE UserData ALTER COLUMN gameId TYPE varchar(100);
+ALTER TABLE UserData ADD COLUMN IF NOT EXISTS base double precision NOT NULL DEFAULT 0;
+ALTER TABLE UserData ADD COLUMN IF NOT EXISTS ts bigint NOT NULL DEFAULT 0;
+
+-- entries submitted before the upgrade are treated as submitted at the time of the upgrade (for decay and expiration)
+UPDATE UserData SET base = score, ts = (extract(epoch FROM now()) * 1000)::bigint WHERE ts = 0;
+
+CREATE INDEX IF NOT EXISTS TsIndex ON UserData (gameId ASC, ts ASC);
+
+CREATE TABLE IF NOT EXISTS RunData (
+	gameId varchar(100) NOT NULL,
+	userId varchar(50) NOT NULL,
+	runId varchar(50) NOT NULL,
+	score double precision NOT NULL CHECK (score >= 0),
+	name varchar(50),
+	params varchar(255),
+	ts bigint NOT NULL DEFAULT 0,
+	PRIMARY KEY (gameId, userId, runId)
+);
+
+CREATE INDEX IF NOT EXISTS RunScoreIndex ON RunData (gameId ASC, score DESC);
+CREATE INDEX IF NOT EXISTS UserRunScoreIndex ON RunData (gameId ASC, userId ASC, score DESC);
+
+CREATE TABLE IF NOT EXISTS UserState (
+	gameId varchar(100) NOT NULL,
+	userId varchar(50) NOT NULL,
+	state smallint NOT NULL,
+	PRIMARY KEY (gameId, userId)
+);
+
+CREATE TABLE IF NOT EXISTS Quarantine (
+	gameId varchar(100) NOT NULL,
+	id varchar(50) NOT NULL,
+	userId varchar(50) NOT NULL,
+	ruleName varchar(50) NOT NULL,
+	reason varchar(255) NOT NULL,
+	score double precision NOT NULL,
+	name varchar(50),
+	params varchar(255),
+	runId varchar(50),
+	duration bigint NOT NULL DEFAULT 0,
+	ts bigint NOT NULL DEFAULT 0,
+	PRIMARY KEY (gameId, id)
+);
+
+CREATE TABLE IF NOT EXISTS Audit (
+	seq bigint NOT NULL,
+	ts bigint NOT NULL,
+	actor varchar(255) NOT NULL,
+	action varchar(50) NOT NULL,
+	gameId varchar(100) NOT NULL,
+	userId varchar(50) NOT NULL DEFAULT '',
+	beforeData text NOT NULL,
+	afterData text NOT NULL,
+	requestId varchar(255) NOT NULL DEFAULT '',
+	prevHash varchar(64) NOT NULL,
+	hash varchar(64) NOT NULL,
+	PRIMARY KEY (seq)
+);
+ALTER TABLE Audit ADD COLUMN IF NOT EXISTS tenant varchar(32) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS Changes (
+	gameId varchar(100) NOT NULL,
+	version bigint NOT NULL,
+	op varchar(10) NOT NULL,
+	userId varchar(50) NOT NULL,
+	runId varchar(50),
+	score double precision NOT NULL,
+	name varchar(50),
+	params varchar(255),
+	ts bigint NOT NULL,
+	PRIMARY KEY (gameId, version)
+);
+
+CREATE TABLE IF NOT EXISTS Outbox (
+	id varchar(50) NOT NULL,
+	gameId varchar(100) NOT NULL,
+	userId varchar(50) NOT NULL,
+	runId varchar(50),
+	oldScore double precision NOT NULL,
+	newScore double precision NOT NULL,
+	oldRank integer NOT NULL,
+	newRank integer NOT NULL,
+	ts bigint NOT NULL,
+	PRIMARY KEY (id)
+);
+
+-- Sequence of ids of outbox events (a single row)
+CREATE TABLE IF NOT EXISTS OutboxSequence (
+	id integer NOT NULL,
+	seq bigint NOT NULL,
+	PRIMARY KEY (id)
+);
+
+INSERT INTO OutboxSequence VALUES (1, 0) ON CONFLICT DO NOTHING;
@@ -22,6 +22,8 @@ type PostgreUserProperties struct {
 	Score  dbprovider.UScoreType `db:"score"`
 	Name   *string               `db:"name"`
 	Params *string               `db:"params"`
+	Base   dbprovider.UScoreType `db:"base"`
+	Ts     int64                 `db:"ts"`
 }
 
 type PostgreUserData struct {
@@ -29,7 +31,79 @@ type PostgreUserData struct {
 	PostgreUserProperties
 }
 
+type PostgreRunProperties struct {
+	RunId  string                `db:"runId"`
+	Score  dbprovider.UScoreType `db:"score"`
+	Name   *string               `db:"name"`
+	Params *string               `db:"params"`
+	Ts     int64                 `db:"ts"`
+}
+
+type PostgreRunData struct {
+	UserId string `db:"userId"`
+	PostgreRunProperties
+}
+
+// Same fields as dbprovider.AuditEntry, so they are convertible to each other
+type PostgreAuditEntry struct {
+	Seq       uint64 `db:"seq"`
+	Ts        int64  `db:"ts"`
+	Actor     string `db:"actor"`
+	Action    string `db:"action"`
+	Tenant    string `db:"tenant"`
+	GameId    string `db:"gameId"`
+	UserId    string `db:"userId"`
+	Before    string `db:"beforeData"`
+	After     string `db:"afterData"`
+	RequestId string `db:"requestId"`
+	PrevHash  string `db:"prevHash"`
+	Hash      string `db:"hash"`
+}
+
+type PostgreChangeEntry struct {
+	Version uint64                `db:"version"`
+	Op      string                `db:"op"`
+	UserId  string                `db:"userId"`
+	RunId   *string               `db:"runId"`
+	Score   dbprovider.UScoreType `db:"score"`
+	Name    *string               `db:"name"`
+	Params  *string               `db:"params"`
+	Ts      int64                 `db:"ts"`
+}
+
+type PostgreScoreEvent struct {
+	Id       string                `db:"id"`
+	GameId   string                `db:"gameId"`
+	UserId   string                `db:"userId"`
+	RunId    *string               `db:"runId"`
+	OldScore dbprovider.UScoreType `db:"oldScore"`
+	NewScore dbprovider.UScoreType `db:"newScore"`
+	OldRank  int                   `db:"oldRank"`
+	NewRank  int                   `db:"newRank"`
+	Ts       int64                 `db:"ts"`
+}
+
+type PostgreQuarantineItem struct {
+	Id       string                `db:"id"`
+	UserId   string                `db:"userId"`
+	Rule     string                `db:"ruleName"`
+	Reason   string                `db:"reason"`
+	Score    dbprovider.UScoreType `db:"score"`
+	Name     *string               `db:"name"`
+	Params   *string               `db:"params"`
+	RunId    *string               `db:"runId"`
+	Duration int64                 `db:"duration"`
+	Ts       int64                 `db:"ts"`
+}
+
 const DB_TABLE_NAME string = "UserData"
+const DB_RUNS_TABLE_NAME string = "RunData"
+const DB_STATES_TABLE_NAME string = "UserState"
+const DB_QUARANTINE_TABLE_NAME string = "Quarantine"
+const DB_AUDIT_TABLE_NAME string = "Audit"
+const DB_CHANGES_TABLE_NAME string = "Changes"
+const DB_OUTBOX_TABLE_NAME string = "Outbox"
+const DB_OUTBOX_SEQUENCE_TABLE_NAME string = "OutboxSequence"
 
 type PostgreProvider struct {
 	pool *pgxpool.Pool
@@ -53,6 +127,58 @@ func toString(s *string) string {
 	return *s
 }
 
+func toRunProperties(run PostgreRunProperties) dbprovider.RunProperties {
+	return dbprovider.RunProperties{
+		RunId:  run.RunId,
+		Score:  run.Score,
+		Name:   toString(run.Name),
+		Params: toString(run.Params),
+		Ts:     run.Ts,
+	}
+}
+
+func toQuarantineItem(item PostgreQuarantineItem) dbprovider.QuarantineItem {
+	return dbprovider.QuarantineItem{
+		Id:       item.Id,
+		UserId:   item.UserId,
+		Rule:     item.Rule,
+		Reason:   item.Reason,
+		Score:    item.Score,
+		Name:     toString(item.Name),
+		Params:   toString(item.Params),
+		RunId:    toString(item.RunId),
+		Duration: uint32(item.Duration),
+		Ts:       item.Ts,
+	}
+}
+
+func toChangeEntry(change PostgreChangeEntry) dbprovider.ChangeEntry {
+	return dbprovider.ChangeEntry{
+		Version: change.Version,
+		Op:      change.Op,
+		UserId:  change.UserId,
+		RunId:   toString(change.RunId),
+		Score:   change.Score,
+		Name:    toString(change.Name),
+		Params:  toString(change.Params),
+		Ts:      change.Ts,
+	}
+}
+
+func toScoreEvent(event PostgreScoreEvent) dbprovider.ScoreEvent {
+	return dbprovider.ScoreEvent{
+		Id:       event.Id,
+		GameId:   event.GameId,
+		UserId:   event.UserId,
+		RunId:    toString(event.RunId),
+		OldScore: event.OldScore,
+		NewScore: event.NewScore,
+		OldRank:  event.OldRank,
+		NewRank:  event.NewRank,
+		Ts:       event.Ts,
+	}
+}
+
 func (p *PostgreProvider) Initialize(ctx context.Context, config dbprovider.IDBProviderConfig) error {
 	logger.Debug("DB provider initialization")
 
@@ -77,30 +203,108 @@ func (p *PostgreProvider) Initialize(ctx context.Context, config dbprovider.IDBP
 	return nil
 }
 
-func (p *PostgreProvider) Put(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties) error {
-	_, err := p.pool.Exec(ctx,
-		fmt.Sprintf(`INSERT INTO %s VALUES ($1, $2, $3, $4, $5)
-			ON CONFLICT(gameId, userId) DO UPDATE SET
-			score = EXCLUDED.score, name = EXCLUDED.name, params = EXCLUDED.params`, DB_TABLE_NAME),
-		gameId, userId, userProp.Score, toStringOrNull(userProp.Name), toStringOrNull(userProp.Params),
-	)
+// Runs the write in a transaction recording the changes of rec to the change feed of the game. The write returns
+// deletes of entries (runs) it removed besides the changes of rec, they are recorded after them. A taken version
+// of a change fails the transaction with ErrChangeConflict
+func (p *PostgreProvider) recordWrite(ctx context.Context, gameId string, rec dbprovider.WriteRecords,
+	write func(tx pgx.Tx) ([]dbprovider.ChangeEntry, error)) error {
+	return pgx.BeginFunc(ctx, p.pool, func(tx pgx.Tx) error {
+		removed, err := write(tx)
+		if err != nil {
+			return err
+		}
 
-	return err
+		err = insertChanges(ctx, tx, gameId, rec.Entries(removed...))
+		if err != nil || rec.Event == nil {
+			return err
+		}
+
+		return insertOutboxEvent(ctx, tx, *rec.Event)
+	})
 }
 
-func (p *PostgreProvider) Delete(ctx context.Context, gameId string, userId string) error {
-	_, err := p.pool.Exec(ctx,
-		fmt.Sprintf(`DELETE FROM %s WHERE gameId = $1 AND userId = $2`, DB_TABLE_NAME),
-		gameId, userId,
+// Stores the event to the outbox with the next id of the sequence. The sequence row stays locked until the commit,
+// so ids are assigned in the order of commits
+func insertOutboxEvent(ctx context.Context, tx pgx.Tx, event dbprovider.ScoreEvent) error {
+	var seq uint64
+	err := tx.QueryRow(ctx,
+		fmt.Sprintf(`UPDATE %s SET seq = seq + 1 WHERE id = 1 RETURNING seq`, DB_OUTBOX_SEQUENCE_TABLE_NAME),
+	).Scan(&seq)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		fmt.Sprintf(`INSERT INTO %s VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`, DB_OUTBOX_TABLE_NAME),
+		dbprovider.OutboxEventId(seq), event.GameId, event.UserId, toStringOrNull(event.RunId), event.OldScore, event.NewScore,
+		event.OldRank, event.NewRank, event.Ts,
 	)
 
 	return err
 }
 
+// Appends the changes to the change feed of the game, returns ErrChangeConflict if a version is taken already
+func insertChanges(ctx context.Context, tx pgx.Tx, gameId string, changes []dbprovider.ChangeEntry) error {
+	for _, change := range changes {
+		tag, err := tx.Exec(ctx,
+			fmt.Sprintf(`INSERT INTO %s VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+				ON CONFLICT(gameId, version) DO NOTHING`, DB_CHANGES_TABLE_NAME),
+			gameId, change.Version, change.Op, change.UserId, toStringOrNull(change.RunId), change.Score,
+			toStringOrNull(change.Name), toStringOrNull(change.Params), change.Ts,
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return dbprovider.ErrChangeConflict
+		}
+	}
+
+	return nil
+}
+
+// Runs the query removing entries (runs) and returns their deletes
+func removeEntries(ctx context.Context, tx pgx.Tx, query string, args ...any) ([]dbprovider.ChangeEntry, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (dbprovider.ChangeEntry, error) {
+		change := dbprovider.ChangeEntry{Op: dbprovider.CHANGEOP_DELETE}
+		err := row.Scan(&change.UserId, &change.RunId)
+		return change, err
+	})
+}
+
+func (p *PostgreProvider) Put(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties, rec dbprovider.WriteRecords) error {
+	return p.recordWrite(ctx, gameId, rec, func(tx pgx.Tx) ([]dbprovider.ChangeEntry, error) {
+		_, err := tx.Exec(ctx,
+			fmt.Sprintf(`INSERT INTO %s VALUES ($1, $2, $3, $4, $5, $6, $7)
+				ON CONFLICT(gameId, userId) DO UPDATE SET
+				score = EXCLUDED.score, name = EXCLUDED.name, params = EXCLUDED.params,
+				base = EXCLUDED.base, ts = EXCLUDED.ts`, DB_TABLE_NAME),
+			gameId, userId, userProp.Score, toStringOrNull(userProp.Name), toStringOrNull(userProp.Params),
+			userProp.Base, userProp.Ts,
+		)
+		return nil, err
+	})
+}
+
+func (p *PostgreProvider) Delete(ctx context.Context, gameId string, userId string, rec dbprovider.WriteRecords) error {
+	return p.recordWrite(ctx, gameId, rec, func(tx pgx.Tx) ([]dbprovider.ChangeEntry, error) {
+		_, err := tx.Exec(ctx,
+			fmt.Sprintf(`DELETE FROM %s WHERE gameId = $1 AND userId = $2`, DB_TABLE_NAME),
+			gameId, userId,
+		)
+		return nil, err
+	})
+}
+
 func (p *PostgreProvider) Get(ctx context.Context, gameId string, userId string) (*dbprovider.UserProperties, error) {
 	var err error
 	rows, err := p.pool.Query(ctx,
-		fmt.Sprintf(`SELECT score, name, params FROM %s WHERE gameId = $1 AND userId = $2`, DB_TABLE_NAME),
+		fmt.Sprintf(`SELECT score, name, params, base, ts FROM %s WHERE gameId = $1 AND userId = $2`, DB_TABLE_NAME),
 		gameId, userId,
 	)
 	if err != nil {
@@ -119,22 +323,38 @@ func (p *PostgreProvider) Get(ctx context.Context, gameId string, userId string)
 		Score:  uprop.Score,
 		Name:   toString(uprop.Name),
 		Params: toString(uprop.Params),
+		Base:   uprop.Base,
+		Ts:     uprop.Ts,
 	}, nil
 }
 
-func (p *PostgreProvider) Top(ctx context.Context, gameId string, nTop uint32) (dbprovider.TopData, error) {
+// Returns the columns of a top query, omitted fields are not selected
+func topColumns(omitFields dbprovider.TopFields) string {
+	columns := `userId as "userId", score, base, ts`
+	if omitFields&dbprovider.TOPFIELD_NAME == 0 {
+		columns += ", name"
+	}
+	if omitFields&dbprovider.TOPFIELD_PARAMS == 0 {
+		columns += ", params"
+	}
+	return columns
+}
+
+func (p *PostgreProvider) Top(ctx context.Context, gameId string, nTop uint32, opts dbprovider.TopOptions) (dbprovider.TopData, error) {
 	var err error
 	rows, err := p.pool.Query(ctx,
-		fmt.Sprintf(`SELECT userId as "userId", score, name, params FROM %s
-			WHERE gameId = $1 ORDER BY gameId ASC, score DESC LIMIT $2`, DB_TABLE_NAME),
-		gameId, nTop,
+		fmt.Sprintf(`SELECT %s FROM %s
+			WHERE gameId = $1 AND ($2::bigint = 0 OR ts >= $2)
+			AND userId NOT IN (SELECT userId FROM %s WHERE gameId = $1)
+			ORDER BY gameId ASC, score DESC LIMIT $3`, topColumns(opts.OmitFields), DB_TABLE_NAME, DB_STATES_TABLE_NAME),
+		gameId, opts.MinTs, nTop,
 	)
 	if err != nil {
 		return dbprovider.TopData{}, err
 	}
 
 	var result dbprovider.TopData = make(dbprovider.TopData, 0, nTop)
-	top, err := pgx.CollectRows(rows, pgx.RowToStructByName[PostgreUserData])
+	top, err := pgx.CollectRows(rows, pgx.RowToStructByNameLax[PostgreUserData])
 	if err != nil {
 		return dbprovider.TopData{}, err
 	}
@@ -146,6 +366,8 @@ func (p *PostgreProvider) Top(ctx context.Context, gameId string, nTop uint32) (
 				Score:  udata.Score,
 				Name:   toString(udata.Name),
 				Params: toString(udata.Params),
+				Base:   udata.Base,
+				Ts:     udata.Ts,
 			},
 		})
 	}
@@ -153,6 +375,485 @@ func (p *PostgreProvider) Top(ctx context.Context, gameId string, nTop uint32) (
 	return result, nil
 }
 
+func (p *PostgreProvider) Inactive(ctx context.Context, gameId string, before int64, above dbprovider.UScoreType, fn func(dbprovider.UserData) error) error {
+	// pages are read by the primary key, so updates of the entries don't move them between pages
+	lastUserId := ""
+	for {
+		rows, err := p.pool.Query(ctx,
+			fmt.Sprintf(`SELECT userId as "userId", score, name, params, base, ts FROM %s
+				WHERE gameId = $1 AND ts < $2 AND score > $3 AND userId > $4 ORDER BY userId LIMIT $5`, DB_TABLE_NAME),
+			gameId, before, above, lastUserId, dbprovider.INACTIVE_PAGE_SIZE,
+		)
+		if err != nil {
+			return err
+		}
+
+		entries, err := pgx.CollectRows(rows, pgx.RowToStructByName[PostgreUserData])
+		if err != nil {
+			return err
+		}
+
+		for _, udata := range entries {
+			err = fn(dbprovider.UserData{
+				UserId: udata.UserId,
+				UserProperties: dbprovider.UserProperties{
+					Score:  udata.Score,
+					Name:   toString(udata.Name),
+					Params: toString(udata.Params),
+					Base:   udata.Base,
+					Ts:     udata.Ts,
+				},
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < dbprovider.INACTIVE_PAGE_SIZE {
+			return nil
+		}
+		lastUserId = entries[len(entries)-1].UserId
+	}
+}
+
+func (p *PostgreProvider) SetScore(ctx context.Context, gameId string, userId string, score dbprovider.UScoreType, ts int64, rec dbprovider.WriteRecords) (bool, error) {
+	updated := false
+	err := pgx.BeginFunc(ctx, p.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx,
+			fmt.Sprintf(`UPDATE %s SET score = $1 WHERE gameId = $2 AND userId = $3 AND ts = $4`, DB_TABLE_NAME),
+			score, gameId, userId, ts,
+		)
+		if err != nil {
+			return err
+		}
+		updated = tag.RowsAffected() > 0
+		if !updated {
+			return nil // nothing to record
+		}
+		return insertChanges(ctx, tx, gameId, rec.Entries())
+	})
+
+	return updated, err
+}
+
+func (p *PostgreProvider) Expire(ctx context.Context, gameId string, before int64, rec dbprovider.WriteRecords) (uint32, error) {
+	var removed []dbprovider.ChangeEntry
+	rec.Changes = nil // only deletes of removed entries are recorded
+	err := p.recordWrite(ctx, gameId, rec, func(tx pgx.Tx) ([]dbprovider.ChangeEntry, error) {
+		var err error
+		removed, err = removeEntries(ctx, tx,
+			fmt.Sprintf(`DELETE FROM %s WHERE gameId = $1 AND ts < $2 RETURNING userId, ''`, DB_TABLE_NAME),
+			gameId, before,
+		)
+		return removed, err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(len(removed)), nil
+}
+
+func (p *PostgreProvider) Trim(ctx context.Context, gameId string, maxEntries uint32, rec dbprovider.WriteRecords) (uint32, error) {
+	var removed []dbprovider.ChangeEntry
+	rec.Changes = nil // only deletes of removed entries are recorded
+	err := p.recordWrite(ctx, gameId, rec, func(tx pgx.Tx) ([]dbprovider.ChangeEntry, error) {
+		var err error
+		removed, err = removeEntries(ctx, tx,
+			fmt.Sprintf(`DELETE FROM %[1]s WHERE gameId = $1 AND userId IN (
+				SELECT userId FROM %[1]s WHERE gameId = $1 ORDER BY gameId ASC, score DESC OFFSET $2)
+				RETURNING userId, ''`, DB_TABLE_NAME),
+			gameId, maxEntries,
+		)
+		return removed, err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(len(removed)), nil
+}
+
+func (p *PostgreProvider) Count(ctx context.Context, gameId string) (uint64, error) {
+	var n int64
+	err := p.pool.QueryRow(ctx,
+		fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE gameId = $1`, DB_TABLE_NAME),
+		gameId,
+	).Scan(&n)
+
+	return uint64(n), err
+}
+
+func (p *PostgreProvider) PutRun(ctx context.Context, gameId string, userId string, run dbprovider.RunProperties, maxRuns uint32, rec dbprovider.WriteRecords) (uint32, error) {
+	var evicted []dbprovider.ChangeEntry
+	err := p.recordWrite(ctx, gameId, rec, func(tx pgx.Tx) ([]dbprovider.ChangeEntry, error) {
+		_, err := tx.Exec(ctx,
+			fmt.Sprintf(`INSERT INTO %s VALUES ($1, $2, $3, $4, $5, $6, $7)
+				ON CONFLICT(gameId, userId, runId) DO UPDATE SET
+				score = EXCLUDED.score, name = EXCLUDED.name, params = EXCLUDED.params, ts = EXCLUDED.ts`, DB_RUNS_TABLE_NAME),
+			gameId, userId, run.RunId, run.Score, toStringOrNull(run.Name), toStringOrNull(run.Params), run.Ts,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		evicted, err = removeEntries(ctx, tx,
+			fmt.Sprintf(`DELETE FROM %[1]s WHERE gameId = $1 AND userId = $2 AND runId IN (
+				SELECT runId FROM %[1]s WHERE gameId = $1 AND userId = $2
+				ORDER BY gameId ASC, userId ASC, score DESC OFFSET $3)
+				RETURNING userId, runId`, DB_RUNS_TABLE_NAME),
+			gameId, userId, maxRuns,
+		)
+		return evicted, err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(len(evicted)), nil
+}
+
+func (p *PostgreProvider) DeleteRuns(ctx context.Context, gameId string, userId string, rec dbprovider.WriteRecords) error {
+	return p.recordWrite(ctx, gameId, rec, func(tx pgx.Tx) ([]dbprovider.ChangeEntry, error) {
+		_, err := tx.Exec(ctx,
+			fmt.Sprintf(`DELETE FROM %s WHERE gameId = $1 AND userId = $2`, DB_RUNS_TABLE_NAME),
+			gameId, userId,
+		)
+		return nil, err
+	})
+}
+
+func (p *PostgreProvider) GetRuns(ctx context.Context, gameId string, userId string) ([]dbprovider.RunProperties, error) {
+	var err error
+	rows, err := p.pool.Query(ctx,
+		fmt.Sprintf(`SELECT runId as "runId", score, name, params, ts FROM %s
+			WHERE gameId = $1 AND userId = $2 ORDER BY gameId ASC, userId ASC, score DESC`, DB_RUNS_TABLE_NAME),
+		gameId, userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := pgx.CollectRows(rows, pgx.RowToStructByName[PostgreRunProperties])
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dbprovider.RunProperties, 0, len(runs))
+	for _, run := range runs {
+		result = append(result, toRunProperties(run))
+	}
+
+	return result, nil
+}
+
+func (p *PostgreProvider) TopRuns(ctx context.Context, gameId string, nTop uint32) (dbprovider.RunTopData, error) {
+	var err error
+	rows, err := p.pool.Query(ctx,
+		fmt.Sprintf(`SELECT userId as "userId", runId as "runId", score, name, params, ts FROM %s
+			WHERE gameId = $1 AND userId NOT IN (SELECT userId FROM %s WHERE gameId = $1)
+			ORDER BY gameId ASC, score DESC LIMIT $2`, DB_RUNS_TABLE_NAME, DB_STATES_TABLE_NAME),
+		gameId, nTop,
+	)
+	if err != nil {
+		return dbprovider.RunTopData{}, err
+	}
+
+	top, err := pgx.CollectRows(rows, pgx.RowToStructByName[PostgreRunData])
+	if err != nil {
+		return dbprovider.RunTopData{}, err
+	}
+
+	var result dbprovider.RunTopData = make(dbprovider.RunTopData, 0, len(top))
+	for _, rdata := range top {
+		result = append(result, dbprovider.RunData{
+			UserId:        rdata.UserId,
+			RunProperties: toRunProperties(rdata.PostgreRunProperties),
+		})
+	}
+
+	return result, nil
+}
+
+func (p *PostgreProvider) CountRuns(ctx context.Context, gameId string) (uint64, error) {
+	var n int64
+	err := p.pool.QueryRow(ctx,
+		fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE gameId = $1`, DB_RUNS_TABLE_NAME),
+		gameId,
+	).Scan(&n)
+
+	return uint64(n), err
+}
+
+func (p *PostgreProvider) SetUserState(ctx context.Context, gameId string, userId string, state dbprovider.UserState, rec dbprovider.WriteRecords) error {
+	return p.recordWrite(ctx, gameId, rec, func(tx pgx.Tx) ([]dbprovider.ChangeEntry, error) {
+		var err error
+		if state == dbprovider.USERSTATE_VISIBLE {
+			_, err = tx.Exec(ctx,
+				fmt.Sprintf(`DELETE FROM %s WHERE gameId = $1 AND userId = $2`, DB_STATES_TABLE_NAME),
+				gameId, userId,
+			)
+		} else {
+			_, err = tx.Exec(ctx,
+				fmt.Sprintf(`INSERT INTO %s VALUES ($1, $2, $3)
+					ON CONFLICT(gameId, userId) DO UPDATE SET state = EXCLUDED.state`, DB_STATES_TABLE_NAME),
+				gameId, userId, int(state),
+			)
+		}
+		return nil, err
+	})
+}
+
+func (p *PostgreProvider) GetUserState(ctx context.Context, gameId string, userId string) (dbprovider.UserState, error) {
+	var state int
+	err := p.pool.QueryRow(ctx,
+		fmt.Sprintf(`SELECT state FROM %s WHERE gameId = $1 AND userId = $2`, DB_STATES_TABLE_NAME),
+		gameId, userId,
+	).Scan(&state)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return dbprovider.USERSTATE_VISIBLE, nil
+		}
+		return dbprovider.USERSTATE_VISIBLE, err
+	}
+
+	return dbprovider.UserState(state), nil
+}
+
+func (p *PostgreProvider) PutQuarantined(ctx context.Context, gameId string, item dbprovider.QuarantineItem) error {
+	_, err := p.pool.Exec(ctx,
+		fmt.Sprintf(`INSERT INTO %s VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT(gameId, id) DO NOTHING`, DB_QUARANTINE_TABLE_NAME),
+		gameId, item.Id, item.UserId, item.Rule, item.Reason, item.Score,
+		toStringOrNull(item.Name), toStringOrNull(item.Params), toStringOrNull(item.RunId), item.Duration, item.Ts,
+	)
+
+	return err
+}
+
+func (p *PostgreProvider) GetQuarantined(ctx context.Context, gameId string, id string) (*dbprovider.QuarantineItem, error) {
+	var err error
+	rows, err := p.pool.Query(ctx,
+		fmt.Sprintf(`SELECT id, userId as "userId", ruleName as "ruleName", reason, score, name, params,
+			runId as "runId", duration, ts FROM %s WHERE gameId = $1 AND id = $2`, DB_QUARANTINE_TABLE_NAME),
+		gameId, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	pgItem, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[PostgreQuarantineItem])
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	item := toQuarantineItem(pgItem)
+	return &item, nil
+}
+
+func (p *PostgreProvider) ListQuarantined(ctx context.Context, gameId string, limit uint32) ([]dbprovider.QuarantineItem, error) {
+	var err error
+	rows, err := p.pool.Query(ctx,
+		fmt.Sprintf(`SELECT id, userId as "userId", ruleName as "ruleName", reason, score, name, params,
+			runId as "runId", duration, ts FROM %s WHERE gameId = $1 ORDER BY gameId ASC, id ASC LIMIT $2`, DB_QUARANTINE_TABLE_NAME),
+		gameId, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := pgx.CollectRows(rows, pgx.RowToStructByName[PostgreQuarantineItem])
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dbprovider.QuarantineItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, toQuarantineItem(item))
+	}
+
+	return result, nil
+}
+
+func (p *PostgreProvider) DeleteQuarantined(ctx context.Context, gameId string, id string) (*dbprovider.QuarantineItem, error) {
+	rows, err := p.pool.Query(ctx,
+		fmt.Sprintf(`DELETE FROM %s WHERE gameId = $1 AND id = $2 RETURNING id, userId as "userId", ruleName as "ruleName",
+			reason, score, name, params, runId as "runId", duration, ts`, DB_QUARANTINE_TABLE_NAME),
+		gameId, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	pgItem, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[PostgreQuarantineItem])
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	item := toQuarantineItem(pgItem)
+	return &item, nil
+}
+
+func (p *PostgreProvider) PutAuditEntry(ctx context.Context, entry dbprovider.AuditEntry) error {
+	tag, err := p.pool.Exec(ctx,
+		fmt.Sprintf(`INSERT INTO %s (seq, ts, actor, action, tenant, gameId, userId, beforeData, afterData, requestId, prevHash, hash)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) ON CONFLICT(seq) DO NOTHING`, DB_AUDIT_TABLE_NAME),
+		entry.Seq, entry.Ts, entry.Actor, entry.Action, entry.Tenant, entry.GameId, entry.UserId,
+		entry.Before, entry.After, entry.RequestId, entry.PrevHash, entry.Hash,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return dbprovider.ErrAuditConflict
+	}
+
+	return nil
+}
+
+func (p *PostgreProvider) ListAuditEntries(ctx context.Context, fromSeq uint64, limit uint32) ([]dbprovider.AuditEntry, error) {
+	var err error
+	rows, err := p.pool.Query(ctx,
+		fmt.Sprintf(`SELECT seq, ts, actor, action, tenant, gameId as "gameId", userId as "userId", beforeData as "beforeData",
+			afterData as "afterData", requestId as "requestId", prevHash as "prevHash", hash
+			FROM %s WHERE seq >= $1 ORDER BY seq ASC LIMIT $2`, DB_AUDIT_TABLE_NAME),
+		fromSeq, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := pgx.CollectRows(rows, pgx.RowToStructByName[PostgreAuditEntry])
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dbprovider.AuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, dbprovider.AuditEntry(entry))
+	}
+
+	return result, nil
+}
+
+func (p *PostgreProvider) LastAuditEntry(ctx context.Context) (*dbprovider.AuditEntry, error) {
+	var err error
+	rows, err := p.pool.Query(ctx,
+		fmt.Sprintf(`SELECT seq, ts, actor, action, tenant, gameId as "gameId", userId as "userId", beforeData as "beforeData",
+			afterData as "afterData", requestId as "requestId", prevHash as "prevHash", hash
+			FROM %s ORDER BY seq DESC LIMIT 1`, DB_AUDIT_TABLE_NAME),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	pgEntry, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[PostgreAuditEntry])
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	entry := dbprovider.AuditEntry(pgEntry)
+	return &entry, nil
+}
+
+func (p *PostgreProvider) ListChanges(ctx context.Context, gameId string, fromVersion uint64, limit uint32) ([]dbprovider.ChangeEntry, error) {
+	var err error
+	rows, err := p.pool.Query(ctx,
+		fmt.Sprintf(`SELECT version, op, userId as "userId", runId as "runId", score, name, params, ts
+			FROM %s WHERE gameId = $1 AND version >= $2 ORDER BY gameId ASC, version ASC LIMIT $3`, DB_CHANGES_TABLE_NAME),
+		gameId, fromVersion, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := pgx.CollectRows(rows, pgx.RowToStructByName[PostgreChangeEntry])
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dbprovider.ChangeEntry, 0, len(changes))
+	for _, change := range changes {
+		result = append(result, toChangeEntry(change))
+	}
+
+	return result, nil
+}
+
+func (p *PostgreProvider) LastChange(ctx context.Context, gameId string) (*dbprovider.ChangeEntry, error) {
+	var err error
+	rows, err := p.pool.Query(ctx,
+		fmt.Sprintf(`SELECT version, op, userId as "userId", runId as "runId", score, name, params, ts
+			FROM %s WHERE gameId = $1 ORDER BY gameId DESC, version DESC LIMIT 1`, DB_CHANGES_TABLE_NAME),
+		gameId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	pgChange, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[PostgreChangeEntry])
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	change := toChangeEntry(pgChange)
+	return &change, nil
+}
+
+func (p *PostgreProvider) TrimChanges(ctx context.Context, gameId string, beforeVersion uint64) error {
+	_, err := p.pool.Exec(ctx,
+		fmt.Sprintf(`DELETE FROM %s WHERE gameId = $1 AND version < $2`, DB_CHANGES_TABLE_NAME),
+		gameId, beforeVersion,
+	)
+
+	return err
+}
+
+func (p *PostgreProvider) ListOutboxEvents(ctx context.Context, limit uint32) ([]dbprovider.ScoreEvent, error) {
+	var err error
+	rows, err := p.pool.Query(ctx,
+		fmt.Sprintf(`SELECT id, gameId as "gameId", userId as "userId", runId as "runId", oldScore as "oldScore",
+			newScore as "newScore", oldRank as "oldRank", newRank as "newRank", ts FROM %s ORDER BY id ASC LIMIT $1`, DB_OUTBOX_TABLE_NAME),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByName[PostgreScoreEvent])
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dbprovider.ScoreEvent, 0, len(events))
+	for _, event := range events {
+		result = append(result, toScoreEvent(event))
+	}
+
+	return result, nil
+}
+
+func (p *PostgreProvider) DeleteOutboxEvent(ctx context.Context, id string) error {
+	_, err := p.pool.Exec(ctx,
+		fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, DB_OUTBOX_TABLE_NAME),
+		id,
+	)
+
+	return err
+}
+
 func (p *PostgreProvider) Shutdown(ctx context.Context) error {
 	if p.pool == nil {
 		return nil
@@ -2,10 +2,13 @@ package redis_provider
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	dbprovider "go-leaderboard-server/internal/db"
 	log "go-leaderboard-server/internal/logger"
+	"strconv"
+	"strings"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -14,23 +17,234 @@ var logger = log.GetLogger()
 
 type RedisOptions redis.Options
 
+// Prefix of keys used when KeyPrefix isn't set
+const DEFAULT_KEY_PREFIX = "ldb:"
+
 type RedisProviderConfig struct {
 	dbprovider.DBProviderBaseConfig
-	Opts RedisOptions
+	Opts      RedisOptions
+	KeyPrefix string // Prefix of all keys, separates leaderboard data from other data of the database (empty - DEFAULT_KEY_PREFIX)
 }
 
 type RedisProvider struct {
-	rdb *redis.Client
+	rdb    *redis.Client
+	prefix string
 }
 
 func NewRedisProvider() *RedisProvider {
 	return &RedisProvider{}
 }
 
-func getUserKey(gameId string, userId string) string {
-	return fmt.Sprintf("%s:%s", gameId, userId)
+// Sorted set of users by score
+func (p *RedisProvider) getBoardKey(gameId string) string {
+	return p.prefix + gameId
+}
+
+func (p *RedisProvider) getUserKey(gameId string, userId string) string {
+	return fmt.Sprintf("%s%s:%s", p.prefix, gameId, userId)
+}
+
+// Sorted set of users by the last submission time
+func (p *RedisProvider) getTsKey(gameId string) string {
+	return fmt.Sprintf("%s%s::ts", p.prefix, gameId)
+}
+
+// Sorted set of all runs of the game (members are "userId:runId")
+func (p *RedisProvider) getRunsKey(gameId string) string {
+	return fmt.Sprintf("%s%s::runs", p.prefix, gameId)
+}
+
+// Sorted set of runs of the user (members are run ids)
+func (p *RedisProvider) getUserRunsKey(gameId string, userId string) string {
+	return fmt.Sprintf("%s%s::runs:%s", p.prefix, gameId, userId)
+}
+
+// Hash of not visible users of the game (field - userId, value - state)
+func (p *RedisProvider) getStatesKey(gameId string) string {
+	return fmt.Sprintf("%s%s::states", p.prefix, gameId)
+}
+
+// Sorted set of ids of quarantined submissions (all scores are 0, so members are ordered by id)
+func (p *RedisProvider) getQuarantineKey(gameId string) string {
+	return fmt.Sprintf("%s%s::quarantine", p.prefix, gameId)
+}
+
+// Hash of quarantined submissions (field - id, value - item in JSON)
+func (p *RedisProvider) getQuarantineItemsKey(gameId string) string {
+	return fmt.Sprintf("%s%s::quarantine:items", p.prefix, gameId)
+}
+
+// Hash of audit entries (field - sequence number, value - entry in JSON)
+func (p *RedisProvider) getAuditKey() string {
+	return p.prefix + "::audit"
+}
+
+// Sorted set of sequence numbers of audit entries
+func (p *RedisProvider) getAuditSeqKey() string {
+	return p.prefix + "::audit:seq"
+}
+
+// Stream of changes of the game (id - version-0, field "data" - change in JSON)
+func (p *RedisProvider) getChangesKey(gameId string) string {
+	return fmt.Sprintf("%s%s::changes", p.prefix, gameId)
+}
+
+// Sorted set of ids of outbox events (all scores are 0, so members are ordered by id)
+func (p *RedisProvider) getOutboxKey() string {
+	return p.prefix + "::outbox"
 }
 
+// Hash of outbox events (field - id, value - event in JSON)
+func (p *RedisProvider) getOutboxEventsKey() string {
+	return p.prefix + "::outbox:events"
+}
+
+// Sequence number of the last outbox event
+func (p *RedisProvider) getOutboxSeqKey() string {
+	return p.prefix + "::outbox:seq"
+}
+
+func (p *RedisProvider) getRunKey(gameId string, userId string, runId string) string {
+	return fmt.Sprintf("%s%s::run:%s:%s", p.prefix, gameId, userId, runId)
+}
+
+func toRunProperties(runId string, score float64, hval map[string]string) dbprovider.RunProperties {
+	ts, _ := strconv.ParseInt(hval["ts"], 10, 64)
+	return dbprovider.RunProperties{
+		RunId:  runId,
+		Score:  dbprovider.UScoreType(score),
+		Name:   hval["nm"],
+		Params: hval["pl"],
+		Ts:     ts,
+	}
+}
+
+func toUserProperties(score float64, hval map[string]string) dbprovider.UserProperties {
+	base, _ := strconv.ParseFloat(hval["bs"], 64)
+	ts, _ := strconv.ParseInt(hval["ts"], 10, 64)
+	return dbprovider.UserProperties{
+		Score:  dbprovider.UScoreType(score),
+		Name:   hval["nm"],
+		Params: hval["pl"],
+		Base:   dbprovider.UScoreType(base),
+		Ts:     ts,
+	}
+}
+
+// Function of scripts recording the delete of a removed entry to the stream of changes (version 0 - not recorded)
+const recordRemovalFunc = `
+local function recordRemoval(key, version, ts, userId, runId)
+	if version == 0 then
+		return
+	end
+	local change = {version = version, op = "delete", userId = userId, score = 0, ts = ts}
+	if runId then
+		change.runId = runId
+	end
+	redis.call("XADD", key, version .. "-0", "data", cjson.encode(change))
+end
+`
+
+// Changes the score only if the last submission time has not changed since it was read, and records the change
+// then (ARGV[4] - id of the change in the stream KEYS[3], empty - not recorded, ARGV[5] - change in JSON).
+// Returns 1 if the score is changed
+var setScoreScript = redis.NewScript(`
+if redis.call("HGET", KEYS[1], "ts") == ARGV[1] and redis.call("ZSCORE", KEYS[2], ARGV[3]) then
+	redis.call("ZADD", KEYS[2], ARGV[2], ARGV[3])
+	if ARGV[4] ~= "" then
+		redis.call("XADD", KEYS[3], ARGV[4], "data", ARGV[5])
+	end
+	return 1
+end
+return 0
+`)
+
+// Removes a batch of entries with the last submission time earlier than the specified one, recording their deletes
+// to the stream KEYS[3] from the version ARGV[4]
+var expireScript = redis.NewScript(recordRemovalFunc + `
+local ids = redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", "(" .. ARGV[1], "LIMIT", 0, ARGV[3])
+for i, id in ipairs(ids) do
+	redis.call("ZREM", KEYS[1], id)
+	redis.call("ZREM", KEYS[2], id)
+	redis.call("DEL", ARGV[2] .. id)
+	recordRemoval(KEYS[3], tonumber(ARGV[4]) + i - 1, tonumber(ARGV[5]), id)
+end
+return #ids
+`)
+
+// Stores the run and removes the worst runs of the user exceeding the limit, recording their deletes
+// to the stream KEYS[3] from the version ARGV[9]
+var putRunScript = redis.NewScript(recordRemovalFunc + `
+redis.call("ZADD", KEYS[1], ARGV[1], ARGV[2] .. ":" .. ARGV[3])
+redis.call("ZADD", KEYS[2], ARGV[1], ARGV[3])
+local hkey = ARGV[5] .. ARGV[3]
+redis.call("DEL", hkey)
+redis.call("HSET", hkey, "ts", ARGV[6])
+if ARGV[7] ~= "" then
+	redis.call("HSET", hkey, "nm", ARGV[7])
+end
+if ARGV[8] ~= "" then
+	redis.call("HSET", hkey, "pl", ARGV[8])
+end
+local stop = -(tonumber(ARGV[4]) + 1)
+local ids = redis.call("ZRANGE", KEYS[2], 0, stop)
+if #ids > 0 then
+	redis.call("ZREMRANGEBYRANK", KEYS[2], 0, stop)
+	for i, id in ipairs(ids) do
+		redis.call("ZREM", KEYS[1], ARGV[2] .. ":" .. id)
+		redis.call("DEL", ARGV[5] .. id)
+		recordRemoval(KEYS[3], tonumber(ARGV[9]) + i - 1, tonumber(ARGV[10]), ARGV[2], id)
+	end
+end
+return #ids
+`)
+
+// Adds the audit entry only if there is no entry with the same sequence number.
+// KEYS[1] - audit hash, KEYS[2] - sequence numbers, ARGV[1] - sequence number, ARGV[2] - entry (JSON)
+var putAuditEntryScript = redis.NewScript(`
+if redis.call("HSETNX", KEYS[1], ARGV[1], ARGV[2]) == 0 then
+	return 0
+end
+redis.call("ZADD", KEYS[2], ARGV[1], ARGV[1])
+return 1
+`)
+
+// Removes all runs of the user
+var deleteRunsScript = redis.NewScript(`
+local ids = redis.call("ZRANGE", KEYS[2], 0, -1)
+for _, id in ipairs(ids) do
+	redis.call("ZREM", KEYS[1], ARGV[1] .. ":" .. id)
+	redis.call("DEL", ARGV[2] .. id)
+end
+redis.call("DEL", KEYS[2])
+return #ids
+`)
+
+// Removes entries ranked below the specified number of the best ones, recording their deletes to the stream KEYS[3]
+// from the version ARGV[3]
+var trimScript = redis.NewScript(recordRemovalFunc + `
+local stop = -(tonumber(ARGV[1]) + 1)
+local ids = redis.call("ZRANGE", KEYS[1], 0, stop)
+if #ids > 0 then
+	redis.call("ZREMRANGEBYRANK", KEYS[1], 0, stop)
+	for i, id in ipairs(ids) do
+		redis.call("ZREM", KEYS[2], id)
+		redis.call("DEL", ARGV[2] .. id)
+		recordRemoval(KEYS[3], tonumber(ARGV[3]) + i - 1, tonumber(ARGV[4]), id)
+	end
+end
+return #ids
+`)
+
+// Adds the event to the outbox with the next id of the sequence.
+// KEYS[1] - sequence, KEYS[2] - ids, KEYS[3] - events hash, ARGV[1] - event without id (JSON)
+var putOutboxEventScript = redis.NewScript(`
+local id = string.format("%020d", redis.call("INCR", KEYS[1]))
+redis.call("HSET", KEYS[3], id, ARGV[1])
+redis.call("ZADD", KEYS[2], 0, id)
+return id
+`)
+
 func (p *RedisProvider) Initialize(ctx context.Context, config dbprovider.IDBProviderConfig) error {
 	logger.Debug("DB provider initialization")
 
@@ -42,6 +256,10 @@ func (p *RedisProvider) Initialize(ctx context.Context, config dbprovider.IDBPro
 	opts := redis.Options(conf.Opts)
 	rdb := redis.NewClient(&opts)
 	p.rdb = rdb
+	p.prefix = conf.KeyPrefix
+	if p.prefix == "" {
+		p.prefix = DEFAULT_KEY_PREFIX
+	}
 
 	err := p.rdb.Ping(ctx).Err()
 	if err != nil {
@@ -51,49 +269,118 @@ func (p *RedisProvider) Initialize(ctx context.Context, config dbprovider.IDBPro
 	return nil
 }
 
-func (p *RedisProvider) Put(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties) error {
-	var err error
-
-	if userProp.Name != "" || userProp.Params != "" {
-		hval := make(map[string]string)
-		if userProp.Name != "" {
-			hval["nm"] = userProp.Name
+// Runs the write in a transaction appending the changes of rec to the change feed of the game. The stream of changes
+// is watched from the check of its last version, so the transaction fails with ErrChangeConflict if another write
+// records a change in the meantime
+func (p *RedisProvider) recordWrite(ctx context.Context, gameId string, rec dbprovider.WriteRecords, write func(pipe redis.Pipeliner) error) error {
+	key := p.getChangesKey(gameId)
+	record := func(pipe redis.Pipeliner) error {
+		for _, change := range rec.Entries() {
+			data, err := json.Marshal(change)
+			if err != nil {
+				return err
+			}
+			pipe.XAdd(ctx, &redis.XAddArgs{Stream: key, ID: changeId(change.Version), Values: []string{"data", string(data)}})
 		}
-		if userProp.Params != "" {
-			hval["pl"] = userProp.Params
+		if rec.Event != nil {
+			// the id is assigned by the script, events are stored without it
+			event := *rec.Event
+			event.Id = ""
+			data, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			putOutboxEventScript.Eval(ctx, pipe, []string{p.getOutboxSeqKey(), p.getOutboxKey(), p.getOutboxEventsKey()}, data)
 		}
+		return write(pipe)
+	}
+
+	if rec.Version == 0 {
+		_, err := p.rdb.TxPipelined(ctx, record)
+		return err
+	}
 
-		err = p.rdb.HSet(ctx, getUserKey(gameId, userId), hval).Err()
+	err := p.rdb.Watch(ctx, func(tx *redis.Tx) error {
+		last, err := lastVersion(ctx, tx, key)
 		if err != nil {
 			return err
 		}
-	}
+		if last >= rec.Version {
+			return dbprovider.ErrChangeConflict
+		}
 
-	err = p.rdb.ZAdd(ctx, gameId, redis.Z{
-		Score:  float64(userProp.Score),
-		Member: userId,
-	}).Err()
-	if err != nil {
+		_, err = tx.TxPipelined(ctx, record)
 		return err
+	}, key)
+	if errors.Is(err, redis.TxFailedErr) {
+		return dbprovider.ErrChangeConflict
 	}
 
-	return nil
+	return err
 }
 
-func (p *RedisProvider) Delete(ctx context.Context, gameId string, userId string) error {
-	var err error
+// Returns the version of the last change of the stream (the stream keeps the last id even if its entries are trimmed)
+func lastVersion(ctx context.Context, c redis.Cmdable, key string) (uint64, error) {
+	n, err := c.Exists(ctx, key).Result()
+	if err != nil || n == 0 {
+		return 0, err
+	}
 
-	err = p.rdb.ZRem(ctx, gameId, userId).Err()
+	info, err := c.XInfoStream(ctx, key).Result()
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	err = p.rdb.Del(ctx, getUserKey(gameId, userId)).Err()
-	if err != nil {
-		return err
+	version, _, _ := strings.Cut(info.LastGeneratedID, "-")
+	return strconv.ParseUint(version, 10, 64)
+}
+
+// Returns the id of the change with the version in the stream of changes
+func changeId(version uint64) string {
+	return strconv.FormatUint(version, 10) + "-0"
+}
+
+// Returns the version of the first delete recorded by a script after n changes ("0" - changes aren't recorded)
+func removalVersion(rec dbprovider.WriteRecords, n int) string {
+	if rec.Version == 0 {
+		return "0"
 	}
+	return strconv.FormatUint(rec.Version+uint64(n), 10)
+}
 
-	return nil
+func (p *RedisProvider) Put(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties, rec dbprovider.WriteRecords) error {
+	hval := map[string]string{
+		"bs": strconv.FormatFloat(float64(userProp.Base), 'f', -1, 64),
+		"ts": strconv.FormatInt(userProp.Ts, 10),
+	}
+	if userProp.Name != "" {
+		hval["nm"] = userProp.Name
+	}
+	if userProp.Params != "" {
+		hval["pl"] = userProp.Params
+	}
+
+	return p.recordWrite(ctx, gameId, rec, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, p.getUserKey(gameId, userId), hval)
+		pipe.ZAdd(ctx, p.getBoardKey(gameId), redis.Z{
+			Score:  float64(userProp.Score),
+			Member: userId,
+		})
+		pipe.ZAdd(ctx, p.getTsKey(gameId), redis.Z{
+			Score:  float64(userProp.Ts),
+			Member: userId,
+		})
+		return nil
+	})
+}
+
+func (p *RedisProvider) Delete(ctx context.Context, gameId string, userId string, rec dbprovider.WriteRecords) error {
+	return p.recordWrite(ctx, gameId, rec, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, p.getBoardKey(gameId), userId)
+		pipe.ZRem(ctx, p.getTsKey(gameId), userId)
+		pipe.Del(ctx, p.getUserKey(gameId, userId))
+		return nil
+	})
 }
 
 func (p *RedisProvider) Get(ctx context.Context, gameId string, userId string) (*dbprovider.UserProperties, error) {
@@ -105,12 +392,12 @@ func (p *RedisProvider) Get(ctx context.Context, gameId string, userId string) (
 	chanHash := make(chan Result[map[string]string])
 
 	go func() {
-		score, err := p.rdb.ZScore(ctx, gameId, userId).Result()
+		score, err := p.rdb.ZScore(ctx, p.getBoardKey(gameId), userId).Result()
 		chanScore <- Result[float64]{score, err}
 	}()
 
 	go func() {
-		hval, err := p.rdb.HGetAll(ctx, getUserKey(gameId, userId)).Result()
+		hval, err := p.rdb.HGetAll(ctx, p.getUserKey(gameId, userId)).Result()
 		chanHash <- Result[map[string]string]{hval, err}
 	}()
 
@@ -127,61 +414,717 @@ func (p *RedisProvider) Get(ctx context.Context, gameId string, userId string) (
 		return nil, resHash.err
 	}
 
-	return &dbprovider.UserProperties{
-		Score:  dbprovider.UScoreType(resScore.value),
-		Name:   resHash.value["nm"],
-		Params: resHash.value["pl"],
-	}, nil
+	userProp := toUserProperties(resScore.value, resHash.value)
+	return &userProp, nil
+}
+
+func (p *RedisProvider) Top(ctx context.Context, gameId string, nTop uint32, opts dbprovider.TopOptions) (dbprovider.TopData, error) {
+	var top dbprovider.TopData = make(dbprovider.TopData, 0, nTop)
+
+	hidden, err := p.rdb.HGetAll(ctx, p.getStatesKey(gameId)).Result()
+	if err != nil {
+		return dbprovider.TopData{}, err
+	}
+
+	// entries filtered out by options are skipped, so the range is requested in windows until enough data is collected
+	for start := int64(0); len(top) < int(nTop); start += int64(nTop) {
+		topData, err := p.rdb.ZRangeArgsWithScores(ctx, redis.ZRangeArgs{
+			Key:   p.getBoardKey(gameId),
+			Start: start,
+			Stop:  start + int64(nTop) - 1,
+			Rev:   true,
+		}).Result()
+		if err != nil {
+			return dbprovider.TopData{}, err
+		}
+
+		N := len(topData)
+		if N < 1 {
+			break
+		}
+
+		props, err := p.topProperties(ctx, gameId, topData, opts.OmitFields)
+		if err != nil {
+			return dbprovider.TopData{}, err
+		}
+
+		for i, userProp := range props {
+			if opts.MinTs != 0 && userProp.Ts < opts.MinTs {
+				continue
+			}
+			if _, ok := hidden[topData[i].Member.(string)]; ok {
+				continue
+			}
+			top = append(top, dbprovider.UserData{
+				UserId:         topData[i].Member.(string),
+				UserProperties: userProp,
+			})
+			if len(top) == int(nTop) {
+				break
+			}
+		}
+
+		if N < int(nTop) {
+			break
+		}
+	}
+
+	return top, nil
+}
+
+// Reads properties of the top entries. Hashes of users are fetched only if the name or params are requested (only the requested fields),
+// otherwise the submission times are read from the ts index in a single command
+func (p *RedisProvider) topProperties(ctx context.Context, gameId string, topData []redis.Z, omitFields dbprovider.TopFields) ([]dbprovider.UserProperties, error) {
+	props := make([]dbprovider.UserProperties, len(topData))
+
+	if omitFields&(dbprovider.TOPFIELD_NAME|dbprovider.TOPFIELD_PARAMS) == dbprovider.TOPFIELD_NAME|dbprovider.TOPFIELD_PARAMS {
+		userIds := make([]string, len(topData))
+		for i, key := range topData {
+			userIds[i] = key.Member.(string)
+		}
+		tss, err := p.rdb.ZMScore(ctx, p.getTsKey(gameId), userIds...).Result()
+		if err != nil {
+			return nil, err
+		}
+		for i, key := range topData {
+			props[i] = dbprovider.UserProperties{Score: dbprovider.UScoreType(key.Score), Ts: int64(tss[i])}
+		}
+		return props, nil
+	}
+
+	if omitFields != 0 {
+		fields := []string{"bs", "ts"}
+		if omitFields&dbprovider.TOPFIELD_NAME == 0 {
+			fields = append(fields, "nm")
+		}
+		if omitFields&dbprovider.TOPFIELD_PARAMS == 0 {
+			fields = append(fields, "pl")
+		}
+
+		pipe := p.rdb.Pipeline()
+		cmds := make([]*redis.SliceCmd, len(topData))
+		for i, key := range topData {
+			cmds[i] = pipe.HMGet(ctx, p.getUserKey(gameId, key.Member.(string)), fields...)
+		}
+
+		_, err := pipe.Exec(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, cmd := range cmds {
+			hval := make(map[string]string, len(fields))
+			for j, value := range cmd.Val() {
+				if value, ok := value.(string); ok {
+					hval[fields[j]] = value
+				}
+			}
+			props[i] = toUserProperties(topData[i].Score, hval)
+		}
+		return props, nil
+	}
+
+	pipe := p.rdb.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(topData))
+	for i, key := range topData {
+		cmds[i] = pipe.HGetAll(ctx, p.getUserKey(gameId, key.Member.(string)))
+	}
+
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, cmd := range cmds {
+		result, err := cmd.Result()
+		if err != nil {
+			return nil, err
+		}
+		props[i] = toUserProperties(topData[i].Score, result)
+	}
+	return props, nil
+}
+
+func (p *RedisProvider) Inactive(ctx context.Context, gameId string, before int64, above dbprovider.UScoreType, fn func(dbprovider.UserData) error) error {
+	// updates of the entries keep their times, so pages of the time index stay in place
+	for offset := int64(0); ; offset += dbprovider.INACTIVE_PAGE_SIZE {
+		userIds, err := p.rdb.ZRangeArgs(ctx, redis.ZRangeArgs{
+			Key:     p.getTsKey(gameId),
+			Start:   "-inf",
+			Stop:    fmt.Sprintf("(%d", before),
+			ByScore: true,
+			Offset:  offset,
+			Count:   dbprovider.INACTIVE_PAGE_SIZE,
+		}).Result()
+		if err != nil {
+			return err
+		}
+		if len(userIds) == 0 {
+			return nil
+		}
+
+		// hashes are read only for entries above the floor (removed entries have no score)
+		scores, err := p.rdb.ZMScore(ctx, p.getBoardKey(gameId), userIds...).Result()
+		if err != nil {
+			return err
+		}
+		batch := make([]string, 0, len(userIds))
+		batchScores := make([]float64, 0, len(userIds))
+		for i, userId := range userIds {
+			if dbprovider.UScoreType(scores[i]) > above {
+				batch = append(batch, userId)
+				batchScores = append(batchScores, scores[i])
+			}
+		}
+
+		pipe := p.rdb.Pipeline()
+		hashCmds := make([]*redis.MapStringStringCmd, len(batch))
+		for i, userId := range batch {
+			hashCmds[i] = pipe.HGetAll(ctx, p.getUserKey(gameId, userId))
+		}
+		if len(batch) > 0 {
+			_, err = pipe.Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		for i, userId := range batch {
+			err = fn(dbprovider.UserData{
+				UserId:         userId,
+				UserProperties: toUserProperties(batchScores[i], hashCmds[i].Val()),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(userIds) < dbprovider.INACTIVE_PAGE_SIZE {
+			return nil
+		}
+	}
+}
+
+func (p *RedisProvider) SetScore(ctx context.Context, gameId string, userId string, score dbprovider.UScoreType, ts int64, rec dbprovider.WriteRecords) (bool, error) {
+	id, data := "", []byte{}
+	if changes := rec.Entries(); len(changes) > 0 {
+		var err error
+		id = changeId(changes[0].Version)
+		data, err = json.Marshal(changes[0])
+		if err != nil {
+			return false, err
+		}
+	}
+
+	var cmd *redis.Cmd
+	check := rec
+	check.Changes = nil // recorded by the script if the score is changed
+	err := p.recordWrite(ctx, gameId, check, func(pipe redis.Pipeliner) error {
+		cmd = setScoreScript.Eval(ctx, pipe,
+			[]string{p.getUserKey(gameId, userId), p.getBoardKey(gameId), p.getChangesKey(gameId)},
+			strconv.FormatInt(ts, 10), strconv.FormatFloat(float64(score), 'f', -1, 64), userId, id, data,
+		)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	updated, err := cmd.Int()
+	return updated == 1, err
+}
+
+func (p *RedisProvider) Expire(ctx context.Context, gameId string, before int64, rec dbprovider.WriteRecords) (uint32, error) {
+	const batchSize = 100
+
+	var removed uint32
+	for {
+		// every batch is a transaction of its own, recording deletes after the ones of the previous batches
+		batch := dbprovider.WriteRecords{Ts: rec.Ts}
+		if rec.Version != 0 {
+			batch.Version = rec.Version + uint64(removed)
+		}
+
+		var cmd *redis.Cmd
+		err := p.recordWrite(ctx, gameId, batch, func(pipe redis.Pipeliner) error {
+			cmd = expireScript.Eval(ctx, pipe,
+				[]string{p.getBoardKey(gameId), p.getTsKey(gameId), p.getChangesKey(gameId)},
+				before, p.getUserKey(gameId, ""), batchSize, removalVersion(batch, 0), rec.Ts,
+			)
+			return nil
+		})
+		if err != nil {
+			return removed, err
+		}
+
+		n, err := cmd.Int()
+		if err != nil {
+			return removed, err
+		}
+		removed += uint32(n)
+		if n < batchSize {
+			return removed, nil
+		}
+	}
+}
+
+func (p *RedisProvider) Trim(ctx context.Context, gameId string, maxEntries uint32, rec dbprovider.WriteRecords) (uint32, error) {
+	var cmd *redis.Cmd
+	rec.Changes = nil // only deletes of removed entries are recorded
+	err := p.recordWrite(ctx, gameId, rec, func(pipe redis.Pipeliner) error {
+		cmd = trimScript.Eval(ctx, pipe,
+			[]string{p.getBoardKey(gameId), p.getTsKey(gameId), p.getChangesKey(gameId)},
+			maxEntries, p.getUserKey(gameId, ""), removalVersion(rec, 0), rec.Ts,
+		)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := cmd.Int()
+	return uint32(n), err
+}
+
+func (p *RedisProvider) Count(ctx context.Context, gameId string) (uint64, error) {
+	n, err := p.rdb.ZCard(ctx, p.getBoardKey(gameId)).Result()
+	return uint64(n), err
+}
+
+func (p *RedisProvider) PutRun(ctx context.Context, gameId string, userId string, run dbprovider.RunProperties, maxRuns uint32, rec dbprovider.WriteRecords) (uint32, error) {
+	var cmd *redis.Cmd
+	err := p.recordWrite(ctx, gameId, rec, func(pipe redis.Pipeliner) error {
+		cmd = putRunScript.Eval(ctx, pipe,
+			[]string{p.getRunsKey(gameId), p.getUserRunsKey(gameId, userId), p.getChangesKey(gameId)},
+			strconv.FormatFloat(float64(run.Score), 'f', -1, 64), userId, run.RunId, maxRuns,
+			p.getRunKey(gameId, userId, ""), run.Ts, run.Name, run.Params, removalVersion(rec, len(rec.Changes)), rec.Ts,
+		)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := cmd.Int()
+	return uint32(n), err
 }
 
-func (p *RedisProvider) Top(ctx context.Context, gameId string, nTop uint32) (dbprovider.TopData, error) {
+func (p *RedisProvider) DeleteRuns(ctx context.Context, gameId string, userId string, rec dbprovider.WriteRecords) error {
+	return p.recordWrite(ctx, gameId, rec, func(pipe redis.Pipeliner) error {
+		deleteRunsScript.Eval(ctx, pipe,
+			[]string{p.getRunsKey(gameId), p.getUserRunsKey(gameId, userId)},
+			userId, p.getRunKey(gameId, userId, ""),
+		)
+		return nil
+	})
+}
+
+func (p *RedisProvider) GetRuns(ctx context.Context, gameId string, userId string) ([]dbprovider.RunProperties, error) {
 	var err error
 
-	topData, err := p.rdb.ZRangeArgsWithScores(ctx, redis.ZRangeArgs{
-		Key:   gameId,
+	runData, err := p.rdb.ZRangeArgsWithScores(ctx, redis.ZRangeArgs{
+		Key:   p.getUserRunsKey(gameId, userId),
 		Start: 0,
-		Stop:  int64(nTop) - 1,
+		Stop:  -1,
 		Rev:   true,
 	}).Result()
 	if err != nil {
-		return dbprovider.TopData{}, err
+		return nil, err
 	}
 
-	N := len(topData)
+	N := len(runData)
 	if N < 1 {
-		return dbprovider.TopData{}, nil
+		return []dbprovider.RunProperties{}, nil
 	}
 
 	pipe := p.rdb.Pipeline()
 	cmds := make([]*redis.MapStringStringCmd, N)
-	for i, key := range topData {
-		cmds[i] = pipe.HGetAll(ctx, getUserKey(gameId, key.Member.(string)))
+	for i, key := range runData {
+		cmds[i] = pipe.HGetAll(ctx, p.getRunKey(gameId, userId, key.Member.(string)))
 	}
 
 	_, err = pipe.Exec(ctx)
 	if err != nil {
-		return dbprovider.TopData{}, err
+		return nil, err
 	}
 
-	var top dbprovider.TopData = make(dbprovider.TopData, 0, N)
+	runs := make([]dbprovider.RunProperties, 0, N)
 	for i, cmd := range cmds {
 		result, err := cmd.Result()
 		if err != nil {
-			return dbprovider.TopData{}, nil
+			return nil, err
+		}
+		runs = append(runs, toRunProperties(runData[i].Member.(string), runData[i].Score, result))
+	}
+
+	return runs, nil
+}
+
+func (p *RedisProvider) TopRuns(ctx context.Context, gameId string, nTop uint32) (dbprovider.RunTopData, error) {
+	top := make(dbprovider.RunTopData, 0, nTop)
+
+	hidden, err := p.rdb.HGetAll(ctx, p.getStatesKey(gameId)).Result()
+	if err != nil {
+		return dbprovider.RunTopData{}, err
+	}
+
+	// runs of not visible users are skipped, so the range is requested in windows until enough data is collected
+	for start := int64(0); len(top) < int(nTop); start += int64(nTop) {
+		topData, err := p.rdb.ZRangeArgsWithScores(ctx, redis.ZRangeArgs{
+			Key:   p.getRunsKey(gameId),
+			Start: start,
+			Stop:  start + int64(nTop) - 1,
+			Rev:   true,
+		}).Result()
+		if err != nil {
+			return dbprovider.RunTopData{}, err
+		}
+
+		N := len(topData)
+		if N < 1 {
+			break
+		}
+
+		pipe := p.rdb.Pipeline()
+		ids := make([][]string, N)
+		cmds := make([]*redis.MapStringStringCmd, N)
+		for i, key := range topData {
+			ids[i] = strings.SplitN(key.Member.(string), ":", 2)
+			if len(ids[i]) != 2 {
+				return dbprovider.RunTopData{}, errors.New("wrong run member format")
+			}
+			cmds[i] = pipe.HGetAll(ctx, p.getRunKey(gameId, ids[i][0], ids[i][1]))
+		}
+
+		_, err = pipe.Exec(ctx)
+		if err != nil {
+			return dbprovider.RunTopData{}, err
+		}
+
+		for i, cmd := range cmds {
+			result, err := cmd.Result()
+			if err != nil {
+				return dbprovider.RunTopData{}, err
+			}
+			if _, ok := hidden[ids[i][0]]; ok {
+				continue
+			}
+			top = append(top, dbprovider.RunData{
+				UserId:        ids[i][0],
+				RunProperties: toRunProperties(ids[i][1], topData[i].Score, result),
+			})
+			if len(top) == int(nTop) {
+				break
+			}
+		}
+
+		if N < int(nTop) {
+			break
 		}
-		top = append(top, dbprovider.UserData{
-			UserId: topData[i].Member.(string),
-			UserProperties: dbprovider.UserProperties{
-				Score:  dbprovider.UScoreType(topData[i].Score),
-				Name:   result["nm"],
-				Params: result["pl"],
-			},
-		})
 	}
 
 	return top, nil
 }
 
+func (p *RedisProvider) CountRuns(ctx context.Context, gameId string) (uint64, error) {
+	n, err := p.rdb.ZCard(ctx, p.getRunsKey(gameId)).Result()
+	return uint64(n), err
+}
+
+func (p *RedisProvider) SetUserState(ctx context.Context, gameId string, userId string, state dbprovider.UserState, rec dbprovider.WriteRecords) error {
+	return p.recordWrite(ctx, gameId, rec, func(pipe redis.Pipeliner) error {
+		if state == dbprovider.USERSTATE_VISIBLE {
+			pipe.HDel(ctx, p.getStatesKey(gameId), userId)
+		} else {
+			pipe.HSet(ctx, p.getStatesKey(gameId), userId, int(state))
+		}
+		return nil
+	})
+}
+
+func (p *RedisProvider) GetUserState(ctx context.Context, gameId string, userId string) (dbprovider.UserState, error) {
+	state, err := p.rdb.HGet(ctx, p.getStatesKey(gameId), userId).Int()
+	if err != nil {
+		if err == redis.Nil {
+			return dbprovider.USERSTATE_VISIBLE, nil
+		}
+		return dbprovider.USERSTATE_VISIBLE, err
+	}
+
+	return dbprovider.UserState(state), nil
+}
+
+func (p *RedisProvider) PutQuarantined(ctx context.Context, gameId string, item dbprovider.QuarantineItem) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, p.getQuarantineItemsKey(gameId), item.Id, data)
+		pipe.ZAdd(ctx, p.getQuarantineKey(gameId), redis.Z{Score: 0, Member: item.Id})
+		return nil
+	})
+
+	return err
+}
+
+func (p *RedisProvider) GetQuarantined(ctx context.Context, gameId string, id string) (*dbprovider.QuarantineItem, error) {
+	data, err := p.rdb.HGet(ctx, p.getQuarantineItemsKey(gameId), id).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var item dbprovider.QuarantineItem
+	err = json.Unmarshal(data, &item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (p *RedisProvider) ListQuarantined(ctx context.Context, gameId string, limit uint32) ([]dbprovider.QuarantineItem, error) {
+	if limit == 0 {
+		return []dbprovider.QuarantineItem{}, nil
+	}
+
+	ids, err := p.rdb.ZRange(ctx, p.getQuarantineKey(gameId), 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dbprovider.QuarantineItem, 0, len(ids))
+	if len(ids) == 0 {
+		return items, nil
+	}
+
+	values, err := p.rdb.HMGet(ctx, p.getQuarantineItemsKey(gameId), ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue // removed in the meantime
+		}
+		var item dbprovider.QuarantineItem
+		err = json.Unmarshal([]byte(data), &item)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (p *RedisProvider) DeleteQuarantined(ctx context.Context, gameId string, id string) (*dbprovider.QuarantineItem, error) {
+	// the item is read and removed in one transaction, so only one of concurrent callers gets it
+	var get *redis.StringCmd
+	_, err := p.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.HGet(ctx, p.getQuarantineItemsKey(gameId), id)
+		pipe.ZRem(ctx, p.getQuarantineKey(gameId), id)
+		pipe.HDel(ctx, p.getQuarantineItemsKey(gameId), id)
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	data, err := get.Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var item dbprovider.QuarantineItem
+	err = json.Unmarshal(data, &item)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (p *RedisProvider) PutAuditEntry(ctx context.Context, entry dbprovider.AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	added, err := putAuditEntryScript.Run(ctx, p.rdb, []string{p.getAuditKey(), p.getAuditSeqKey()},
+		entry.Seq, data).Int()
+	if err != nil {
+		return err
+	}
+	if added == 0 {
+		return dbprovider.ErrAuditConflict
+	}
+
+	return nil
+}
+
+func (p *RedisProvider) ListAuditEntries(ctx context.Context, fromSeq uint64, limit uint32) ([]dbprovider.AuditEntry, error) {
+	if limit == 0 {
+		return []dbprovider.AuditEntry{}, nil
+	}
+
+	seqs, err := p.rdb.ZRangeByScore(ctx, p.getAuditSeqKey(), &redis.ZRangeBy{
+		Min:   strconv.FormatUint(fromSeq, 10),
+		Max:   "+inf",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return p.getAuditEntries(ctx, seqs)
+}
+
+func (p *RedisProvider) LastAuditEntry(ctx context.Context) (*dbprovider.AuditEntry, error) {
+	seqs, err := p.rdb.ZRevRange(ctx, p.getAuditSeqKey(), 0, 0).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := p.getAuditEntries(ctx, seqs)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+
+	return &entries[0], nil
+}
+
+func (p *RedisProvider) getAuditEntries(ctx context.Context, seqs []string) ([]dbprovider.AuditEntry, error) {
+	entries := make([]dbprovider.AuditEntry, 0, len(seqs))
+	if len(seqs) == 0 {
+		return entries, nil
+	}
+
+	values, err := p.rdb.HMGet(ctx, p.getAuditKey(), seqs...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var entry dbprovider.AuditEntry
+		err = json.Unmarshal([]byte(data), &entry)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (p *RedisProvider) ListChanges(ctx context.Context, gameId string, fromVersion uint64, limit uint32) ([]dbprovider.ChangeEntry, error) {
+	if limit == 0 {
+		return []dbprovider.ChangeEntry{}, nil
+	}
+
+	messages, err := p.rdb.XRangeN(ctx, p.getChangesKey(gameId), strconv.FormatUint(fromVersion, 10), "+", int64(limit)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return toChanges(messages)
+}
+
+func (p *RedisProvider) LastChange(ctx context.Context, gameId string) (*dbprovider.ChangeEntry, error) {
+	messages, err := p.rdb.XRevRangeN(ctx, p.getChangesKey(gameId), "+", "-", 1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := toChanges(messages)
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+
+	return &changes[0], nil
+}
+
+func (p *RedisProvider) TrimChanges(ctx context.Context, gameId string, beforeVersion uint64) error {
+	return p.rdb.XTrimMinID(ctx, p.getChangesKey(gameId), strconv.FormatUint(beforeVersion, 10)).Err()
+}
+
+func (p *RedisProvider) ListOutboxEvents(ctx context.Context, limit uint32) ([]dbprovider.ScoreEvent, error) {
+	if limit == 0 {
+		return []dbprovider.ScoreEvent{}, nil
+	}
+
+	ids, err := p.rdb.ZRange(ctx, p.getOutboxKey(), 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]dbprovider.ScoreEvent, 0, len(ids))
+	if len(ids) == 0 {
+		return events, nil
+	}
+
+	values, err := p.rdb.HMGet(ctx, p.getOutboxEventsKey(), ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue // removed in the meantime
+		}
+		var event dbprovider.ScoreEvent
+		err = json.Unmarshal([]byte(data), &event)
+		if err != nil {
+			return nil, err
+		}
+		event.Id = ids[i]
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func (p *RedisProvider) DeleteOutboxEvent(ctx context.Context, id string) error {
+	_, err := p.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, p.getOutboxKey(), id)
+		pipe.HDel(ctx, p.getOutboxEventsKey(), id)
+		return nil
+	})
+
+	return err
+}
+
+func toChanges(messages []redis.XMessage) ([]dbprovider.ChangeEntry, error) {
+	changes := make([]dbprovider.ChangeEntry, 0, len(messages))
+	for _, message := range messages {
+		data, ok := message.Values["data"].(string)
+		if !ok {
+			continue
+		}
+		var change dbprovider.ChangeEntry
+		err := json.Unmarshal([]byte(data), &change)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
 func (p *RedisProvider) Shutdown(ctx context.Context) error {
 	if p.rdb == nil {
 		return nil
@@ -0,0 +1,40 @@
+package deadletterprovider
+
+import (
+	"context"
+)
+
+type DeadLetterProviderBaseConfig struct {
+	IsDebug bool // Debug flag
+}
+
+func (c *DeadLetterProviderBaseConfig) GetBaseConfig() *DeadLetterProviderBaseConfig {
+	return c
+}
+
+type IDeadLetterProviderConfig interface {
+	GetBaseConfig() *DeadLetterProviderBaseConfig
+}
+
+// Webhook delivery that failed all attempts
+type DeadLetter struct {
+	Id       string `json:"id" example:"5f2b8c1d9e7a4b6c8d0e1f2a3b4c5d6e"`                    // Id of the event
+	Tenant   string `json:"tenant,omitempty" example:"studio1"`                               // Id of tenant of the event (empty - default tenant)
+	Payload  string `json:"payload" example:"{\"type\":\"took_first\",\"userId\":\"user1\"}"` // Body of the webhook (JSON)
+	Attempts uint32 `json:"attempts" example:"8"`                                             // Number of failed attempts
+	Error    string `json:"error" example:"unexpected status 500"`                            // Error of the last attempt
+	Ts       int64  `json:"ts" example:"1700000000000"`                                       // Time of the last attempt (unix ms)
+}
+
+type IDeadLetterProvider interface {
+	Initialize(ctx context.Context, config IDeadLetterProviderConfig) error
+	// Stores the dead letter of the endpoint, replacing the one with the same id
+	Put(ctx context.Context, endpoint string, letter DeadLetter) error
+	// Returns up to limit dead letters of the endpoint and the tenant (dbprovider.ALL_TENANTS - of all tenants)
+	// in ascending order of time
+	List(ctx context.Context, endpoint string, tenant string, limit uint32) ([]DeadLetter, error)
+	// Returns the dead letter of the endpoint (nil - not found)
+	Get(ctx context.Context, endpoint string, id string) (*DeadLetter, error)
+	Delete(ctx context.Context, endpoint string, id string) error
+	Shutdown(ctx context.Context) error
+}
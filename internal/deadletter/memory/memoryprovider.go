@@ -0,0 +1,99 @@
+package deadletter_memory_provider
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	dbprovider "go-leaderboard-server/internal/db"
+	deadletterprovider "go-leaderboard-server/internal/deadletter"
+	log "go-leaderboard-server/internal/logger"
+	"slices"
+	"sync"
+)
+
+var logger = log.GetLogger()
+
+type DeadLetterMemoryProviderConfig struct {
+	deadletterprovider.DeadLetterProviderBaseConfig
+}
+
+// Keeps dead letters in process memory, so they are lost on restart
+type DeadLetterMemoryProvider struct {
+	letters map[string]map[string]deadletterprovider.DeadLetter // key - endpoint, id
+	mutex   sync.Mutex
+}
+
+func NewDeadLetterMemoryProvider() *DeadLetterMemoryProvider {
+	return &DeadLetterMemoryProvider{
+		letters: make(map[string]map[string]deadletterprovider.DeadLetter),
+	}
+}
+
+func (p *DeadLetterMemoryProvider) Initialize(ctx context.Context, config deadletterprovider.IDeadLetterProviderConfig) error {
+	logger.Debug("Dead letter provider initialization")
+
+	_, ok := config.(*DeadLetterMemoryProviderConfig)
+	if !ok {
+		return errors.New("wrong config")
+	}
+
+	return nil
+}
+
+func (p *DeadLetterMemoryProvider) Put(ctx context.Context, endpoint string, letter deadletterprovider.DeadLetter) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	letters, ok := p.letters[endpoint]
+	if !ok {
+		letters = make(map[string]deadletterprovider.DeadLetter)
+		p.letters[endpoint] = letters
+	}
+	letters[letter.Id] = letter
+
+	return nil
+}
+
+func (p *DeadLetterMemoryProvider) List(ctx context.Context, endpoint string, tenant string, limit uint32) ([]deadletterprovider.DeadLetter, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	letters := make([]deadletterprovider.DeadLetter, 0, len(p.letters[endpoint]))
+	for _, letter := range p.letters[endpoint] {
+		if tenant == dbprovider.ALL_TENANTS || letter.Tenant == tenant {
+			letters = append(letters, letter)
+		}
+	}
+	slices.SortFunc(letters, func(a, b deadletterprovider.DeadLetter) int {
+		return cmp.Or(cmp.Compare(a.Ts, b.Ts), cmp.Compare(a.Id, b.Id))
+	})
+
+	return letters[:min(len(letters), int(limit))], nil
+}
+
+func (p *DeadLetterMemoryProvider) Get(ctx context.Context, endpoint string, id string) (*deadletterprovider.DeadLetter, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	letter, ok := p.letters[endpoint][id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &letter, nil
+}
+
+func (p *DeadLetterMemoryProvider) Delete(ctx context.Context, endpoint string, id string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.letters[endpoint], id)
+
+	return nil
+}
+
+func (p *DeadLetterMemoryProvider) Shutdown(ctx context.Context) error {
+	logger.Debug("Dead letter provider shutdown")
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package deadletter_redis_provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	dbprovider "go-leaderboard-server/internal/db"
+	deadletterprovider "go-leaderboard-server/internal/deadletter"
+	log "go-leaderboard-server/internal/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var logger = log.GetLogger()
+
+type RedisOptions redis.Options
+
+// Prefix of keys used when KeyPrefix isn't set
+const DEFAULT_KEY_PREFIX = "ldb:"
+
+type DeadLetterRedisProviderConfig struct {
+	deadletterprovider.DeadLetterProviderBaseConfig
+	Opts      RedisOptions
+	KeyPrefix string // Prefix of all keys, separates leaderboard data from other data of the database (empty - DEFAULT_KEY_PREFIX)
+}
+
+// Keeps dead letters in Redis, so they are shared by all server instances.
+// Letters of an endpoint are stored in a hash, their ids are ordered by time in a sorted set
+// and in a sorted set of the tenant of the letter
+type DeadLetterRedisProvider struct {
+	rdb    *redis.Client
+	prefix string
+}
+
+func NewDeadLetterRedisProvider() *DeadLetterRedisProvider {
+	return &DeadLetterRedisProvider{}
+}
+
+func (p *DeadLetterRedisProvider) getIdsKey(endpoint string) string {
+	return p.prefix + "deadletter:" + endpoint
+}
+
+func (p *DeadLetterRedisProvider) getTenantIdsKey(endpoint string, tenant string) string {
+	return p.prefix + "deadletter:" + endpoint + ":tenant:" + tenant
+}
+
+func (p *DeadLetterRedisProvider) getLettersKey(endpoint string) string {
+	return p.prefix + "deadletter:" + endpoint + ":letters"
+}
+
+func (p *DeadLetterRedisProvider) Initialize(ctx context.Context, config deadletterprovider.IDeadLetterProviderConfig) error {
+	logger.Debug("Dead letter provider initialization")
+
+	conf, ok := config.(*DeadLetterRedisProviderConfig)
+	if !ok {
+		return errors.New("wrong config")
+	}
+
+	opts := redis.Options(conf.Opts)
+	p.rdb = redis.NewClient(&opts)
+	p.prefix = conf.KeyPrefix
+	if p.prefix == "" {
+		p.prefix = DEFAULT_KEY_PREFIX
+	}
+
+	return p.rdb.Ping(ctx).Err()
+}
+
+func (p *DeadLetterRedisProvider) Put(ctx context.Context, endpoint string, letter deadletterprovider.DeadLetter) error {
+	if p.rdb == nil {
+		return errors.New("uninitialized")
+	}
+
+	data, err := json.Marshal(&letter)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, p.getLettersKey(endpoint), letter.Id, data)
+		pipe.ZAdd(ctx, p.getIdsKey(endpoint), redis.Z{Score: float64(letter.Ts), Member: letter.Id})
+		pipe.ZAdd(ctx, p.getTenantIdsKey(endpoint, letter.Tenant), redis.Z{Score: float64(letter.Ts), Member: letter.Id})
+		return nil
+	})
+
+	return err
+}
+
+func (p *DeadLetterRedisProvider) List(ctx context.Context, endpoint string, tenant string, limit uint32) ([]deadletterprovider.DeadLetter, error) {
+	if p.rdb == nil {
+		return nil, errors.New("uninitialized")
+	}
+
+	letters := make([]deadletterprovider.DeadLetter, 0)
+	if limit == 0 {
+		return letters, nil
+	}
+
+	idsKey := p.getIdsKey(endpoint)
+	if tenant != dbprovider.ALL_TENANTS {
+		idsKey = p.getTenantIdsKey(endpoint, tenant)
+	}
+
+	ids, err := p.rdb.ZRange(ctx, idsKey, 0, int64(limit)-1).Result()
+	if err != nil || len(ids) == 0 {
+		return letters, err
+	}
+
+	values, err := p.rdb.HMGet(ctx, p.getLettersKey(endpoint), ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue // deleted meanwhile
+		}
+		var letter deadletterprovider.DeadLetter
+		err = json.Unmarshal([]byte(data), &letter)
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+
+	return letters, nil
+}
+
+func (p *DeadLetterRedisProvider) Get(ctx context.Context, endpoint string, id string) (*deadletterprovider.DeadLetter, error) {
+	if p.rdb == nil {
+		return nil, errors.New("uninitialized")
+	}
+
+	data, err := p.rdb.HGet(ctx, p.getLettersKey(endpoint), id).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var letter deadletterprovider.DeadLetter
+	err = json.Unmarshal([]byte(data), &letter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &letter, nil
+}
+
+func (p *DeadLetterRedisProvider) Delete(ctx context.Context, endpoint string, id string) error {
+	if p.rdb == nil {
+		return errors.New("uninitialized")
+	}
+
+	// the letter is read to find the index of its tenant
+	letter, err := p.Get(ctx, endpoint, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, p.getLettersKey(endpoint), id)
+		pipe.ZRem(ctx, p.getIdsKey(endpoint), id)
+		if letter != nil {
+			pipe.ZRem(ctx, p.getTenantIdsKey(endpoint, letter.Tenant), id)
+		}
+		return nil
+	})
+
+	return err
+}
+
+func (p *DeadLetterRedisProvider) Shutdown(ctx context.Context) error {
+	logger.Debug("Dead letter provider shutdown")
+
+	if p.rdb == nil {
+		return nil
+	}
+
+	return p.rdb.Close()
+}
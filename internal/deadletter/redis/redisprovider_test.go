@@ -0,0 +1,129 @@
+package deadletter_redis_provider
+
+import (
+	"context"
+	dbprovider "go-leaderboard-server/internal/db"
+	deadletterprovider "go-leaderboard-server/internal/deadletter"
+	"go-leaderboard-server/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var dbEndpoint string
+
+func prepareTest(t *testing.T) {
+	t.Log("prepare test env")
+
+	ctx := context.Background()
+	dbContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "redis:7.2.3",
+			ExposedPorts: []string{"6379"},
+			WaitingFor:   wait.ForExposedPort(),
+		},
+		Started: true,
+	})
+	require.NoError(t, err, "container should start successfully")
+
+	t.Cleanup(func() {
+		t.Log("terminate test env")
+
+		err := dbContainer.Terminate(ctx)
+		require.NoError(t, err, "container should be terminated successfully")
+	})
+
+	ep, err := dbContainer.Endpoint(ctx, "")
+	require.NoError(t, err, "container endpoint should be obtained successfully")
+
+	dbEndpoint = ep
+}
+
+func setupTest() (func() error, *DeadLetterRedisProvider, error) {
+	provider := NewDeadLetterRedisProvider()
+	err := provider.Initialize(context.Background(), &DeadLetterRedisProviderConfig{
+		DeadLetterProviderBaseConfig: deadletterprovider.DeadLetterProviderBaseConfig{
+			IsDebug: true,
+		},
+		Opts: RedisOptions{
+			Addr: dbEndpoint,
+		},
+	})
+
+	return func() error {
+		return provider.Shutdown(context.Background())
+	}, provider, err
+}
+
+func runTest(t *testing.T, name string, testFunc utils.TestFcn[*DeadLetterRedisProvider]) {
+	utils.RunTest(t, name, setupTest, testFunc)
+}
+
+func TestDeadLetterRedisProvider(t *testing.T) {
+	prepareTest(t)
+
+	runTest(t, "put, list and delete letters", func(t *testing.T, provider *DeadLetterRedisProvider) {
+		ctx := context.Background()
+
+		letters := []deadletterprovider.DeadLetter{
+			{Id: "id2", Payload: `{"type":"entered_top"}`, Attempts: 3, Error: "unexpected status 500", Ts: 2000},
+			{Id: "id1", Payload: `{"type":"took_first"}`, Attempts: 3, Error: "timeout", Ts: 1000},
+			{Id: "id3", Payload: `{"type":"overtaken"}`, Attempts: 1, Error: "queue is full", Ts: 3000},
+		}
+		for _, letter := range letters {
+			err := provider.Put(ctx, "endpoint1", letter)
+			require.NoError(t, err)
+		}
+		err := provider.Put(ctx, "endpoint2", deadletterprovider.DeadLetter{Id: "id4", Payload: "{}", Ts: 500})
+		require.NoError(t, err)
+
+		list, err := provider.List(ctx, "endpoint1", dbprovider.ALL_TENANTS, 2)
+		require.NoError(t, err)
+		require.Equal(t, []deadletterprovider.DeadLetter{letters[1], letters[0]}, list)
+
+		letter, err := provider.Get(ctx, "endpoint1", "id3")
+		require.NoError(t, err)
+		require.Equal(t, &letters[2], letter)
+		letter, err = provider.Get(ctx, "endpoint2", "id3")
+		require.NoError(t, err)
+		require.Nil(t, letter)
+
+		// a letter is replaced by the next failure of the same event
+		letters[1].Ts = 4000
+		err = provider.Put(ctx, "endpoint1", letters[1])
+		require.NoError(t, err)
+		err = provider.Delete(ctx, "endpoint1", "id2")
+		require.NoError(t, err)
+
+		list, err = provider.List(ctx, "endpoint1", dbprovider.ALL_TENANTS, 10)
+		require.NoError(t, err)
+		require.Equal(t, []deadletterprovider.DeadLetter{letters[2], letters[1]}, list)
+
+		// letters of a tenant are listed separately from letters of the default tenant
+		tenantLetter := deadletterprovider.DeadLetter{Id: "id5", Tenant: "studio1", Payload: "{}", Ts: 5000}
+		err = provider.Put(ctx, "endpoint1", tenantLetter)
+		require.NoError(t, err)
+
+		list, err = provider.List(ctx, "endpoint1", "studio1", 10)
+		require.NoError(t, err)
+		require.Equal(t, []deadletterprovider.DeadLetter{tenantLetter}, list)
+		list, err = provider.List(ctx, "endpoint1", "", 10)
+		require.NoError(t, err)
+		require.Equal(t, []deadletterprovider.DeadLetter{letters[2], letters[1]}, list)
+		list, err = provider.List(ctx, "endpoint1", dbprovider.ALL_TENANTS, 10)
+		require.NoError(t, err)
+		require.Equal(t, []deadletterprovider.DeadLetter{letters[2], letters[1], tenantLetter}, list)
+
+		err = provider.Delete(ctx, "endpoint1", "id5")
+		require.NoError(t, err)
+		list, err = provider.List(ctx, "endpoint1", "studio1", 10)
+		require.NoError(t, err)
+		require.Empty(t, list)
+
+		list, err = provider.List(ctx, "endpoint3", dbprovider.ALL_TENANTS, 10)
+		require.NoError(t, err)
+		require.Empty(t, list)
+	})
+}
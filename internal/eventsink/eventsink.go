@@ -0,0 +1,27 @@
+package eventsink
+
+import (
+	"context"
+	dbprovider "go-leaderboard-server/internal/db"
+)
+
+type EventSinkBaseConfig struct {
+	IsDebug bool // Debug flag
+}
+
+func (c *EventSinkBaseConfig) GetBaseConfig() *EventSinkBaseConfig {
+	return c
+}
+
+type IEventSinkConfig interface {
+	GetBaseConfig() *EventSinkBaseConfig
+}
+
+// Destination of score events published from the outbox. Delivery is at least once: an event is kept in the outbox
+// and published again until the sink accepts it, so consumers deduplicate events by ids
+type IEventSink interface {
+	Initialize(ctx context.Context, config IEventSinkConfig) error
+	// Publishes the event, returns an error if the event isn't accepted by the destination
+	Publish(ctx context.Context, event dbprovider.ScoreEvent) error
+	Shutdown(ctx context.Context) error
+}
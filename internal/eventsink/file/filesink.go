@@ -0,0 +1,81 @@
+package event_file_sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	dbprovider "go-leaderboard-server/internal/db"
+	eventsink "go-leaderboard-server/internal/eventsink"
+	log "go-leaderboard-server/internal/logger"
+	"os"
+	"sync"
+)
+
+var logger = log.GetLogger()
+
+type EventFileSinkConfig struct {
+	eventsink.EventSinkBaseConfig
+	Path string // Path to the NDJSON file (created if missing, empty - stdout)
+}
+
+// Appends events to a local file or stdout as JSON lines, so they can be shipped by a log collector
+type EventFileSink struct {
+	file  *os.File
+	mutex sync.Mutex
+}
+
+func NewEventFileSink() *EventFileSink {
+	return &EventFileSink{}
+}
+
+func (s *EventFileSink) Initialize(ctx context.Context, config eventsink.IEventSinkConfig) error {
+	logger.Debug("Event sink initialization")
+
+	conf, ok := config.(*EventFileSinkConfig)
+	if !ok {
+		return errors.New("wrong config")
+	}
+
+	if conf.Path == "" {
+		s.file = os.Stdout
+		return nil
+	}
+
+	file, err := os.OpenFile(conf.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	s.file = file
+
+	return nil
+}
+
+func (s *EventFileSink) Publish(ctx context.Context, event dbprovider.ScoreEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err = s.file.Write(append(line, '\n'))
+	if err != nil {
+		return err
+	}
+	if s.file == os.Stdout {
+		return nil
+	}
+
+	return s.file.Sync()
+}
+
+func (s *EventFileSink) Shutdown(ctx context.Context) error {
+	logger.Debug("Event sink shutdown")
+
+	if s.file == nil || s.file == os.Stdout {
+		return nil
+	}
+
+	return s.file.Close()
+}
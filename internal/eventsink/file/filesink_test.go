@@ -0,0 +1,77 @@
+package event_file_sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	dbprovider "go-leaderboard-server/internal/db"
+	eventsink "go-leaderboard-server/internal/eventsink"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newSink(t *testing.T, path string) *EventFileSink {
+	sink := NewEventFileSink()
+	err := sink.Initialize(context.Background(), &EventFileSinkConfig{
+		EventSinkBaseConfig: eventsink.EventSinkBaseConfig{
+			IsDebug: true,
+		},
+		Path: path,
+	})
+	require.NoError(t, err)
+	return sink
+}
+
+func readEvents(t *testing.T, path string) []dbprovider.ScoreEvent {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	events := []dbprovider.ScoreEvent{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event dbprovider.ScoreEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestEventFileSink(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("publish events", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.ndjson")
+		sink := newSink(t, path)
+
+		event1 := dbprovider.ScoreEvent{Id: "id1", GameId: "game1", UserId: "user1", NewScore: 10, NewRank: 1, Ts: 1000}
+		event2 := dbprovider.ScoreEvent{Id: "id2", GameId: "game1", UserId: "user2", RunId: "run1", OldScore: 5, NewScore: 20,
+			OldRank: 2, NewRank: 1, Ts: 2000}
+		require.NoError(t, sink.Publish(ctx, event1))
+		require.NoError(t, sink.Publish(ctx, event2))
+		require.NoError(t, sink.Shutdown(ctx))
+
+		require.Equal(t, []dbprovider.ScoreEvent{event1, event2}, readEvents(t, path))
+
+		// events are appended to the existing file
+		sink = newSink(t, path)
+		require.NoError(t, sink.Publish(ctx, event1))
+		require.NoError(t, sink.Shutdown(ctx))
+		require.Len(t, readEvents(t, path), 3)
+	})
+
+	t.Run("stdout", func(t *testing.T) {
+		sink := newSink(t, "")
+		require.Equal(t, os.Stdout, sink.file)
+		require.NoError(t, sink.Shutdown(ctx))
+	})
+
+	t.Run("wrong config", func(t *testing.T) {
+		err := NewEventFileSink().Initialize(ctx, &EventFileSinkConfig{Path: filepath.Join(t.TempDir(), "missing", "events.ndjson")})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,116 @@
+package event_nats_sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	dbprovider "go-leaderboard-server/internal/db"
+	eventsink "go-leaderboard-server/internal/eventsink"
+	log "go-leaderboard-server/internal/logger"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+var logger = log.GetLogger()
+
+const defaultTimeout = 5 * time.Second
+
+type EventNatsSinkConfig struct {
+	eventsink.EventSinkBaseConfig
+	Url       string // NATS server URLs (comma separated)
+	Subject   string // Subject the events are published to
+	JetStream bool   // Publish to the JetStream stream of the subject and wait for acknowledgements (the stream has to exist)
+	Timeout   uint32 // Timeout of publishing an event (ms, 0 - 5000)
+}
+
+// Publishes events as JSON messages with ids in the Nats-Msg-Id header, so JetStream streams deduplicate
+// events published again. Without JetStream an event is accepted once the server has received it
+type EventNatsSink struct {
+	conn    *nats.Conn
+	js      nats.JetStreamContext
+	subject string
+	timeout time.Duration
+}
+
+func NewEventNatsSink() *EventNatsSink {
+	return &EventNatsSink{}
+}
+
+func (s *EventNatsSink) Initialize(ctx context.Context, config eventsink.IEventSinkConfig) error {
+	logger.Debug("Event sink initialization")
+
+	conf, ok := config.(*EventNatsSinkConfig)
+	if !ok {
+		return errors.New("wrong config")
+	}
+
+	if conf.Subject == "" {
+		return errors.New("NATS subject is not set")
+	}
+
+	s.subject = conf.Subject
+	s.timeout = defaultTimeout
+	if conf.Timeout != 0 {
+		s.timeout = time.Duration(conf.Timeout) * time.Millisecond
+	}
+
+	// the server may be unavailable, events are kept in the outbox until it's connected
+	conn, err := nats.Connect(conf.Url,
+		nats.Name("go-leaderboard-server"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+		nats.ReconnectBufSize(-1),
+	)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+
+	if conf.JetStream {
+		s.js, err = conn.JetStream()
+		if err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *EventNatsSink) Publish(ctx context.Context, event dbprovider.ScoreEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(s.subject)
+	msg.Data = data
+	msg.Header.Set(nats.MsgIdHdr, event.Id)
+
+	if s.js != nil {
+		ctx, cancel := context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+		_, err = s.js.PublishMsg(msg, nats.Context(ctx))
+		return err
+	}
+
+	err = s.conn.PublishMsg(msg)
+	if err != nil {
+		return err
+	}
+
+	// core NATS doesn't acknowledge messages, a flush confirms that the server has received them
+	return s.conn.FlushTimeout(s.timeout)
+}
+
+func (s *EventNatsSink) Shutdown(ctx context.Context) error {
+	logger.Debug("Event sink shutdown")
+
+	if s.conn == nil {
+		return nil
+	}
+
+	s.conn.Close()
+	return nil
+}
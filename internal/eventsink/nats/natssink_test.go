@@ -0,0 +1,113 @@
+package event_nats_sink
+
+import (
+	"context"
+	"encoding/json"
+	dbprovider "go-leaderboard-server/internal/db"
+	eventsink "go-leaderboard-server/internal/eventsink"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var natsUrl string
+
+func prepareTest(t *testing.T) {
+	t.Log("prepare test env")
+
+	ctx := context.Background()
+	natsContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "nats:2.10.7",
+			Cmd:          []string{"-js"},
+			ExposedPorts: []string{"4222"},
+			WaitingFor:   wait.ForExposedPort(),
+		},
+		Started: true,
+	})
+	require.NoError(t, err, "container should start successfully")
+
+	t.Cleanup(func() {
+		t.Log("terminate test env")
+
+		err := natsContainer.Terminate(ctx)
+		require.NoError(t, err, "container should be terminated successfully")
+	})
+
+	ep, err := natsContainer.PortEndpoint(ctx, "4222", "nats")
+	require.NoError(t, err, "container endpoint should be obtained successfully")
+
+	natsUrl = ep
+}
+
+func newSink(t *testing.T, subject string, jetStream bool) *EventNatsSink {
+	sink := NewEventNatsSink()
+	err := sink.Initialize(context.Background(), &EventNatsSinkConfig{
+		EventSinkBaseConfig: eventsink.EventSinkBaseConfig{
+			IsDebug: true,
+		},
+		Url:       natsUrl,
+		Subject:   subject,
+		JetStream: jetStream,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, sink.Shutdown(context.Background()))
+	})
+	return sink
+}
+
+func TestEventNatsSink(t *testing.T) {
+	prepareTest(t)
+
+	ctx := context.Background()
+	conn, err := nats.Connect(natsUrl)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	event1 := dbprovider.ScoreEvent{Id: "id1", GameId: "game1", UserId: "user1", NewScore: 10, NewRank: 1, Ts: 1000}
+	event2 := dbprovider.ScoreEvent{Id: "id2", GameId: "game1", UserId: "user2", OldScore: 5, NewScore: 20, OldRank: 2, NewRank: 1, Ts: 2000}
+
+	t.Run("publish events", func(t *testing.T) {
+		sub, err := conn.SubscribeSync("scores")
+		require.NoError(t, err)
+		require.NoError(t, conn.Flush())
+
+		sink := newSink(t, "scores", false)
+		require.NoError(t, sink.Publish(ctx, event1))
+		require.NoError(t, sink.Publish(ctx, event2))
+
+		for _, expected := range []dbprovider.ScoreEvent{event1, event2} {
+			msg, err := sub.NextMsg(time.Second)
+			require.NoError(t, err)
+			require.Equal(t, expected.Id, msg.Header.Get(nats.MsgIdHdr))
+			var event dbprovider.ScoreEvent
+			require.NoError(t, json.Unmarshal(msg.Data, &event))
+			require.Equal(t, expected, event)
+		}
+	})
+
+	t.Run("publish events to jetstream", func(t *testing.T) {
+		js, err := conn.JetStream()
+		require.NoError(t, err)
+		_, err = js.AddStream(&nats.StreamConfig{Name: "SCORES", Subjects: []string{"js.scores"}})
+		require.NoError(t, err)
+
+		sink := newSink(t, "js.scores", true)
+		require.NoError(t, sink.Publish(ctx, event1))
+		require.NoError(t, sink.Publish(ctx, event2))
+		require.NoError(t, sink.Publish(ctx, event1)) // deduplicated by the stream
+
+		info, err := js.StreamInfo("SCORES")
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), info.State.Msgs)
+
+		// the stream has to exist
+		sink = newSink(t, "missing", true)
+		require.Error(t, sink.Publish(ctx, event1))
+	})
+}
@@ -0,0 +1,387 @@
+package grpcapi
+
+import (
+	"context"
+	ac "go-leaderboard-server/internal/appcontext"
+	"go-leaderboard-server/internal/config"
+	leaderboardpb "go-leaderboard-server/internal/grpcapi/pb"
+	idempotencyprovider "go-leaderboard-server/internal/idempotency"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/middleware"
+	"go-leaderboard-server/internal/services"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	METADATA_API_KEY              = "x-api-key"
+	METADATA_AUTHORIZATION        = "authorization"
+	METADATA_REQUEST_ID           = "x-request-id"
+	METADATA_RETRY_AFTER          = "retry-after"
+	METADATA_IDEMPOTENCY_KEY      = "idempotency-key"
+	METADATA_IDEMPOTENCY_REPLAYED = "idempotent-replayed" // Set to "true" in the header of responses returned from the store
+
+	maxIdempotencyKeyLength = 255
+	mimeProtobuf            = "application/x-protobuf" // Content type of stored responses of calls
+)
+
+type methodAuth struct {
+	role string // Minimal role of the API key
+	jwt  bool   // Bearer token of a player is required (if the API key has no server role)
+}
+
+// The same requirements as of the HTTP routes of the operations
+var methodAuths = map[string]methodAuth{
+	leaderboardpb.Leaderboard_SendScore_FullMethodName:   {role: config.ROLE_CLIENT, jwt: true},
+	leaderboardpb.Leaderboard_GetScore_FullMethodName:    {role: config.ROLE_CLIENT, jwt: true},
+	leaderboardpb.Leaderboard_DeleteScore_FullMethodName: {role: config.ROLE_ADMIN, jwt: true},
+	leaderboardpb.Leaderboard_GetTop_FullMethodName:      {role: config.ROLE_CLIENT},
+	leaderboardpb.Leaderboard_WatchTop_FullMethodName:    {role: config.ROLE_CLIENT},
+}
+
+// Responses of the methods that accept idempotency keys, the same operations as of the HTTP routes
+// the idempotency middleware is applied to
+var idempotentMethods = map[string]func() proto.Message{
+	leaderboardpb.Leaderboard_SendScore_FullMethodName:   func() proto.Message { return &leaderboardpb.SendScoreResponse{} },
+	leaderboardpb.Leaderboard_DeleteScore_FullMethodName: func() proto.Message { return &leaderboardpb.DeleteScoreResponse{} },
+}
+
+// Credentials of the request, stored in the context by the auth interceptors
+type requestAuth struct {
+	apiKey    *services.ApiKey    // nil - authentication is disabled
+	claims    *services.JwtClaims // nil - no token is required
+	tenant    string
+	requestId string
+}
+
+type requestAuthKey struct{}
+
+func getRequestAuth(ctx context.Context) *requestAuth {
+	if auth, ok := ctx.Value(requestAuthKey{}).(*requestAuth); ok {
+		return auth
+	}
+	return &requestAuth{}
+}
+
+// Stream with the context of the interceptors
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// Logs unary calls and turns panics into INTERNAL errors
+func loggingUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		start := time.Now()
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverError(r)
+			}
+			logCall(info.FullMethod, start, err)
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// Logs streaming calls and turns panics into INTERNAL errors
+func loggingStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		start := time.Now()
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverError(r)
+			}
+			logCall(info.FullMethod, start, err)
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+func recoverError(r any) error {
+	goErr := errors.Wrap(r, 3)
+	logger.Error("Unexpected error", log.LogParams{"error": goErr, "stack": string(goErr.Stack())})
+	return status.Error(codes.Internal, "Internal server error")
+}
+
+func logCall(method string, start time.Time, err error) {
+	params := log.LogParams{"method": method, "code": status.Code(err).String(), "duration": int(time.Since(start).Milliseconds())}
+	switch status.Code(err) {
+	case codes.OK:
+		logger.Debug("gRPC call", params)
+	case codes.Internal, codes.Unknown:
+		params["error"] = err
+		logger.Error("gRPC call failed", params)
+	default:
+		params["error"] = err
+		logger.Info("gRPC call failed", params)
+	}
+}
+
+// Rejects unary calls that exceed the rate limits of the leaderboard route group, the same way as the rate limit middleware does
+func rateLimitUnaryInterceptor(appContext *ac.AppContext) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := takeRateLimit(ctx, appContext, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Rejects streaming calls that exceed the rate limits of the leaderboard route group when they are opened
+func rateLimitStreamInterceptor(appContext *ac.AppContext) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		_, err := takeRateLimit(ss.Context(), appContext, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+type rateLimitIdentityKey struct{}
+
+// Takes tokens of the peer address, the API key and the tenant of the call, returns the context with the identity.
+// Calls are let through if the limits store fails
+func takeRateLimit(ctx context.Context, appContext *ac.AppContext, method string) (context.Context, error) {
+	groupConf := appContext.RateLimitService.GetGroupConfig(config.ROUTEGROUP_LEADERBOARD)
+	if groupConf == nil {
+		return ctx, nil
+	}
+
+	identity := services.RateLimitIdentity{}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			// addresses without ports (e.g. unix sockets) share one bucket
+			host = p.Addr.String()
+		}
+		identity.Ip = host
+	}
+
+	if (groupConf.ApiKey != nil || groupConf.Tenant != nil) && appContext.AuthService.IsEnabled() {
+		md, _ := metadata.FromIncomingContext(ctx)
+		apiKey, err := appContext.AuthService.Authenticate(firstValue(md, METADATA_API_KEY))
+		if err == nil {
+			// wrong keys are rejected by the auth interceptor, they don't get buckets of their own
+			identity.KeyId = apiKey.Id
+			identity.Tenant = apiKey.Tenant
+		}
+	}
+
+	retryAfter, err := appContext.RateLimitService.Take(ctx, config.ROUTEGROUP_LEADERBOARD, identity)
+	err = checkRateLimit(ctx, identity, "", method, retryAfter, err)
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, rateLimitIdentityKey{}, identity), nil
+}
+
+// Rejects authenticated unary calls that exceed the rate limit of their user, the same way as the user rate limit middleware does
+func userRateLimitUnaryInterceptor(appContext *ac.AppContext) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		identity, ok := ctx.Value(rateLimitIdentityKey{}).(services.RateLimitIdentity)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		userId := ""
+		if userReq, ok := req.(interface{ GetUserId() string }); ok {
+			userId = userReq.GetUserId()
+		}
+		auth := getRequestAuth(ctx)
+		user := services.RateLimitUser(auth.apiKey, auth.claims, auth.tenant, userId)
+
+		retryAfter, err := appContext.RateLimitService.TakeUser(ctx, config.ROUTEGROUP_LEADERBOARD, identity, user)
+		err = checkRateLimit(ctx, identity, user, info.FullMethod, retryAfter, err)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Checks the result of taking rate limit tokens, returns ResourceExhausted if the call is over the limit
+func checkRateLimit(ctx context.Context, identity services.RateLimitIdentity, user string, method string, retryAfter int64, err error) error {
+	if err == services.ErrRateLimited {
+		logger.Warn("Rate limit exceeded", log.LogParams{"ip": identity.Ip, "user": user, "method": method})
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(METADATA_RETRY_AFTER, strconv.FormatInt((retryAfter+999)/1000, 10)))
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	if err != nil {
+		logger.Error("Failed to check rate limit", log.LogParams{"error": err, "method": method})
+	}
+	return nil
+}
+
+// Performs calls with the idempotency-key metadata only once, the same way as the idempotency middleware does:
+// successful responses are stored for the window and returned to repeated calls
+func idempotencyUnaryInterceptor(appContext *ac.AppContext) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		key := firstValue(md, METADATA_IDEMPOTENCY_KEY)
+		newResponse, ok := idempotentMethods[info.FullMethod]
+		if !ok || !appContext.IdempotencyService.IsEnabled() || key == "" {
+			return handler(ctx, req)
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			return nil, status.Error(codes.InvalidArgument, services.ErrIdempotencyKeyTooLong.Error())
+		}
+
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req.(proto.Message))
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		auth := getRequestAuth(ctx)
+		request := services.IdempotencyRequest{
+			Key:    key,
+			Path:   info.FullMethod,
+			ApiKey: firstValue(md, METADATA_API_KEY),
+			Tenant: auth.tenant,
+			Body:   body,
+		}
+		if auth.claims != nil {
+			request.Subject = auth.claims.Subject
+		}
+
+		var (
+			resp    any
+			respErr error
+		)
+		record, replayed, err := appContext.IdempotencyService.Do(ctx, request, func() *idempotencyprovider.Record {
+			resp, respErr = handler(ctx, req)
+			// only successful responses are stored, failed calls can be repeated
+			if respErr != nil {
+				return nil
+			}
+			data, err := proto.Marshal(resp.(proto.Message))
+			if err != nil {
+				logger.Error("Failed to store idempotent response", log.LogParams{"error": err, "method": info.FullMethod})
+				return nil
+			}
+			return &idempotencyprovider.Record{Status: http.StatusOK, ContentType: mimeProtobuf, Body: data}
+		})
+
+		switch {
+		case err == services.ErrIdempotencyKeyReused:
+			logger.Warn("Idempotency key reused", log.LogParams{"method": info.FullMethod})
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case err == services.ErrIdempotencyKeyInProgress:
+			logger.Warn("Idempotent request is in progress", log.LogParams{"method": info.FullMethod})
+			return nil, status.Error(codes.Aborted, err.Error())
+		case err != nil:
+			logger.Error("Failed to process idempotency key", log.LogParams{"error": err, "method": info.FullMethod})
+			return nil, internalError()
+		case replayed:
+			stored := newResponse()
+			err = proto.Unmarshal(record.Body, stored)
+			if err != nil {
+				logger.Error("Failed to read idempotent response", log.LogParams{"error": err, "method": info.FullMethod})
+				return nil, internalError()
+			}
+			_ = grpc.SetHeader(ctx, metadata.Pairs(METADATA_IDEMPOTENCY_REPLAYED, "true"))
+			return stored, nil
+		}
+		return resp, respErr
+	}
+}
+
+// Checks the API key and the bearer token of unary calls, the same way as the auth and JWT middlewares do
+func authUnaryInterceptor(appContext *ac.AppContext) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, appContext, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Checks the API key and the bearer token of streaming calls, the same way as the auth and JWT middlewares do
+func authStreamInterceptor(appContext *ac.AppContext) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), appContext, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// Returns the context with the credentials of the call and its request id (also sent in the response header)
+func authenticate(ctx context.Context, appContext *ac.AppContext, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	auth := &requestAuth{requestId: middleware.RequestId(firstValue(md, METADATA_REQUEST_ID))}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(METADATA_REQUEST_ID, auth.requestId))
+
+	methodAuth, ok := methodAuths[method]
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "unknown method")
+	}
+
+	if appContext.AuthService.IsEnabled() {
+		apiKey, err := appContext.AuthService.Authenticate(firstValue(md, METADATA_API_KEY))
+		if err != nil {
+			logger.Warn("Authentication failed", log.LogParams{"error": err, "method": method})
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		if !apiKey.HasRole(methodAuth.role) {
+			logger.Warn("Access denied", log.LogParams{"role": apiKey.Role, "method": method})
+			return nil, status.Error(codes.PermissionDenied, services.ErrAccessDenied.Error())
+		}
+
+		auth.apiKey = apiKey
+		auth.tenant = apiKey.Tenant
+	}
+
+	if methodAuth.jwt && appContext.JwtService.IsEnabled() && (auth.apiKey == nil || !auth.apiKey.HasRole(config.ROLE_SERVER)) {
+		token := ""
+		scheme, value, found := strings.Cut(firstValue(md, METADATA_AUTHORIZATION), " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			token = strings.TrimSpace(value)
+		}
+
+		claims, err := appContext.JwtService.Verify(token)
+		if err != nil {
+			logger.Warn("Token verification failed", log.LogParams{"error": err, "method": method})
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		if auth.apiKey != nil && auth.tenant != claims.Tenant {
+			logger.Warn("Access denied", log.LogParams{"tenant": claims.Tenant, "method": method})
+			return nil, status.Error(codes.PermissionDenied, services.ErrAccessDenied.Error())
+		}
+
+		auth.claims = claims
+		auth.tenant = claims.Tenant
+	}
+
+	return context.WithValue(ctx, requestAuthKey{}, auth), nil
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,339 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	ac "go-leaderboard-server/internal/appcontext"
+	"go-leaderboard-server/internal/config"
+	"go-leaderboard-server/internal/controllers"
+	dbprovider "go-leaderboard-server/internal/db"
+	leaderboardpb "go-leaderboard-server/internal/grpcapi/pb"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin/binding"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var ErrSignatureNotSupported = errors.New("board requires signed submissions, which are accepted by the HTTP API only")
+
+// Leaderboard operations on top of the same services as the HTTP controllers
+type leaderboardServer struct {
+	leaderboardpb.UnimplementedLeaderboardServer
+	appContext *ac.AppContext
+}
+
+func (s *leaderboardServer) SendScore(ctx context.Context, req *leaderboardpb.SendScoreRequest) (*leaderboardpb.SendScoreResponse, error) {
+	params := controllers.SendScoreParams{
+		GameId: req.GameId,
+		UserId: req.UserId,
+		ScoreData: controllers.ScoreData{
+			Score:    req.Score,
+			Name:     req.Name,
+			Params:   req.Params,
+			RunId:    req.RunId,
+			Duration: req.Duration,
+		},
+	}
+	gameId, err := checkRequest(ctx, &params, params.GameId, params.UserId, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// signatures cover the JSON body of HTTP requests
+	if s.appContext.SignatureService.IsRequired(gameId) {
+		return nil, status.Error(codes.PermissionDenied, ErrSignatureNotSupported.Error())
+	}
+
+	if s.appContext.AppConfig.GetBoardConfig(gameId).Type == config.BOARDTYPE_RUNS {
+		err = s.appContext.LeaderboardService.SubmitUserRun(ctx, gameId, params.UserId, dbprovider.RunProperties{
+			RunId:  params.RunId,
+			Score:  dbprovider.UScoreType(params.Score),
+			Name:   params.Name,
+			Params: params.Params,
+		}, params.Duration)
+	} else {
+		err = s.appContext.LeaderboardService.SubmitUserScore(ctx, gameId, params.UserId, dbprovider.UserProperties{
+			Score:  dbprovider.UScoreType(params.Score),
+			Name:   params.Name,
+			Params: params.Params,
+		}, params.Duration)
+	}
+
+	var (
+		ruleErr  *services.RuleViolationError
+		quotaErr *services.QuotaExceededError
+	)
+	switch {
+	case errors.As(err, &ruleErr):
+		if ruleErr.Quarantine {
+			return &leaderboardpb.SendScoreResponse{Quarantined: true}, nil
+		}
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case errors.As(err, &quotaErr):
+		logger.Warn("Quota exceeded", log.LogParams{"gameId": gameId, "quota": quotaErr.Quota, "scope": quotaErr.Scope})
+		if quotaErr.RetryAfter > 0 {
+			_ = grpc.SetTrailer(ctx, metadata.Pairs(METADATA_RETRY_AFTER, strconv.FormatInt((quotaErr.RetryAfter+999)/1000, 10)))
+		}
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, services.ErrUserBanned):
+		logger.Info("Score of banned user rejected", log.LogParams{"gameId": gameId, "userId": params.UserId})
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		logger.Error("Failed to put user score", log.LogParams{"error": err, "gameId": gameId, "userId": params.UserId})
+		return nil, internalError()
+	}
+
+	return &leaderboardpb.SendScoreResponse{}, nil
+}
+
+func (s *leaderboardServer) GetScore(ctx context.Context, req *leaderboardpb.GetScoreRequest) (*leaderboardpb.GetScoreResponse, error) {
+	params := controllers.GetScoreParams{GameId: req.GameId, UserId: req.UserId}
+	gameId, err := checkRequest(ctx, &params, params.GameId, params.UserId, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.appContext.AppConfig.GetBoardConfig(gameId).Type == config.BOARDTYPE_RUNS {
+		runs, err := s.appContext.LeaderboardService.GetUserRuns(ctx, gameId, params.UserId)
+		if err != nil {
+			logger.Error("Failed to get user runs", log.LogParams{"error": err, "gameId": gameId, "userId": params.UserId})
+			return nil, internalError()
+		}
+		if len(runs) == 0 {
+			return nil, status.Error(codes.NotFound, services.ErrUserNotFound.Error())
+		}
+		err = s.checkShown(ctx, gameId, params.UserId)
+		if err != nil {
+			return nil, err
+		}
+
+		resp := &leaderboardpb.GetScoreResponse{Runs: make([]*leaderboardpb.RunProperties, 0, len(runs))}
+		for _, run := range runs {
+			resp.Runs = append(resp.Runs, &leaderboardpb.RunProperties{
+				RunId: run.RunId, Score: float64(run.Score), Name: run.Name, Params: run.Params, Ts: run.Ts,
+			})
+		}
+		return resp, nil
+	}
+
+	userProp, err := s.appContext.LeaderboardService.GetUserScore(ctx, gameId, params.UserId)
+	if err != nil {
+		logger.Error("Failed to get user score", log.LogParams{"error": err, "gameId": gameId, "userId": params.UserId})
+		return nil, internalError()
+	}
+	if userProp == nil {
+		return nil, status.Error(codes.NotFound, services.ErrUserNotFound.Error())
+	}
+	err = s.checkShown(ctx, gameId, params.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &leaderboardpb.GetScoreResponse{
+		User: &leaderboardpb.UserProperties{Score: float64(userProp.Score), Name: userProp.Name, Params: userProp.Params},
+	}, nil
+}
+
+// Returns NotFound if entries of the user are hidden from the caller (shown only to the user itself and admins)
+func (s *leaderboardServer) checkShown(ctx context.Context, gameId string, userId string) error {
+	auth := getRequestAuth(ctx)
+	shown, err := s.appContext.LeaderboardService.IsShown(ctx, gameId, userId, services.CanSeeHidden(auth.apiKey, auth.claims, userId))
+	if err != nil {
+		logger.Error("Failed to get user state", log.LogParams{"error": err, "gameId": gameId, "userId": userId})
+		return internalError()
+	}
+	if !shown {
+		return status.Error(codes.NotFound, services.ErrUserNotFound.Error())
+	}
+	return nil
+}
+
+func (s *leaderboardServer) DeleteScore(ctx context.Context, req *leaderboardpb.DeleteScoreRequest) (*leaderboardpb.DeleteScoreResponse, error) {
+	params := controllers.DeleteScoreParams{GameId: req.GameId, UserId: req.UserId}
+	gameId, err := checkRequest(ctx, &params, params.GameId, params.UserId, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		before any
+		found  bool
+	)
+	isRuns := s.appContext.AppConfig.GetBoardConfig(gameId).Type == config.BOARDTYPE_RUNS
+	if isRuns {
+		var runs []dbprovider.RunProperties
+		runs, err = s.appContext.LeaderboardService.GetUserRuns(ctx, gameId, params.UserId)
+		before, found = runs, len(runs) > 0
+	} else {
+		var userProp *dbprovider.UserProperties
+		userProp, err = s.appContext.LeaderboardService.GetUserScore(ctx, gameId, params.UserId)
+		before, found = userProp, userProp != nil
+	}
+	if err != nil {
+		logger.Error("Failed to get user score", log.LogParams{"error": err, "gameId": gameId, "userId": params.UserId})
+		return nil, internalError()
+	}
+	if !found {
+		return nil, status.Error(codes.NotFound, services.ErrUserNotFound.Error())
+	}
+
+	if isRuns {
+		err = s.appContext.LeaderboardService.DeleteUserRuns(ctx, gameId, params.UserId)
+	} else {
+		err = s.appContext.LeaderboardService.DeleteUserScore(ctx, gameId, params.UserId)
+	}
+	if err != nil {
+		logger.Error("Failed to delete user score", log.LogParams{"error": err, "gameId": gameId, "userId": params.UserId})
+		return nil, internalError()
+	}
+
+	s.audit(ctx, services.AuditRecord{Action: services.AUDITACTION_DELETE_SCORE, GameId: gameId, UserId: params.UserId, Before: before})
+
+	return &leaderboardpb.DeleteScoreResponse{}, nil
+}
+
+func (s *leaderboardServer) GetTop(ctx context.Context, req *leaderboardpb.GetTopRequest) (*leaderboardpb.GetTopResponse, error) {
+	params := controllers.GetTopParams{GameId: req.GameId, NTop: req.NTop}
+	gameId, err := checkRequest(ctx, &params, params.GameId, "", false)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getTop(ctx, gameId, params.NTop)
+}
+
+// Sends the top on subscription and then on every change of it, see SubscriptionService
+func (s *leaderboardServer) WatchTop(req *leaderboardpb.GetTopRequest, stream leaderboardpb.Leaderboard_WatchTopServer) error {
+	ctx := stream.Context()
+	params := controllers.GetTopParams{GameId: req.GameId, NTop: req.NTop}
+	gameId, err := checkRequest(ctx, &params, params.GameId, "", false)
+	if err != nil {
+		return err
+	}
+
+	sub, err := s.appContext.SubscriptionService.Subscribe(gameId, params.NTop, false)
+	if err != nil {
+		if errors.Is(err, services.ErrTooManySubscriptions) || errors.Is(err, services.ErrSubscriptionsClosed) {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+		logger.Error("Failed to subscribe to top", log.LogParams{"error": err, "gameId": gameId})
+		return internalError()
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case update, ok := <-sub.Updates:
+			if !ok {
+				if errors.Is(sub.Err(), services.ErrSlowConsumer) {
+					return status.Error(codes.ResourceExhausted, sub.Err().Error())
+				}
+				return status.Error(codes.Unavailable, services.ErrSubscriptionsClosed.Error())
+			}
+
+			resp := &leaderboardpb.GetTopResponse{Entries: make([]*leaderboardpb.TopEntry, 0, len(update.Entries))}
+			for _, e := range update.Entries {
+				resp.Entries = append(resp.Entries, &leaderboardpb.TopEntry{
+					UserId: e.UserId, Score: float64(e.Score), Name: e.Name, Params: e.Params, RunId: e.RunId, Ts: e.Ts,
+				})
+			}
+			err = stream.Send(resp)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *leaderboardServer) getTop(ctx context.Context, gameId string, nTop uint32) (*leaderboardpb.GetTopResponse, error) {
+	if s.appContext.AppConfig.GetBoardConfig(gameId).Type == config.BOARDTYPE_RUNS {
+		top, err := s.appContext.LeaderboardService.GetTopRuns(ctx, gameId, nTop)
+		if err != nil {
+			logger.Error("Failed to get top runs", log.LogParams{"error": err, "gameId": gameId, "nTop": int(nTop)})
+			return nil, internalError()
+		}
+
+		resp := &leaderboardpb.GetTopResponse{Entries: make([]*leaderboardpb.TopEntry, 0, len(top))}
+		for _, run := range top {
+			resp.Entries = append(resp.Entries, &leaderboardpb.TopEntry{
+				UserId: run.UserId, Score: float64(run.Score), Name: run.Name, Params: run.Params, RunId: run.RunId, Ts: run.Ts,
+			})
+		}
+		return resp, nil
+	}
+
+	top, err := s.appContext.LeaderboardService.GetTop(ctx, gameId, nTop)
+	if err != nil {
+		logger.Error("Failed to get top", log.LogParams{"error": err, "gameId": gameId, "nTop": int(nTop)})
+		return nil, internalError()
+	}
+
+	resp := &leaderboardpb.GetTopResponse{Entries: make([]*leaderboardpb.TopEntry, 0, len(top))}
+	for _, user := range top {
+		resp.Entries = append(resp.Entries, &leaderboardpb.TopEntry{
+			UserId: user.UserId, Score: float64(user.Score), Name: user.Name, Params: user.Params,
+		})
+	}
+	return resp, nil
+}
+
+// Records the operation to the audit log, failures are logged, but don't fail the call
+func (s *leaderboardServer) audit(ctx context.Context, record services.AuditRecord) {
+	if !s.appContext.AuditService.IsEnabled() {
+		return
+	}
+
+	auth := getRequestAuth(ctx)
+	switch {
+	case auth.claims != nil:
+		record.Actor = "sub:" + auth.claims.Subject
+	case auth.apiKey != nil:
+		record.Actor = "key:" + auth.apiKey.Id
+	default:
+		record.Actor = "anonymous"
+	}
+	record.Tenant, record.GameId = dbprovider.SplitTenantGameId(record.GameId)
+	record.RequestId = auth.requestId
+
+	_, err := s.appContext.AuditService.Record(ctx, record)
+	if err != nil {
+		logger.Error("Failed to record audit entry", log.LogParams{"error": err, "action": record.Action, "actor": record.Actor,
+			"gameId": record.GameId, "userId": record.UserId, "requestId": record.RequestId})
+	}
+}
+
+// Validates params of the call with the rules of the HTTP API and checks access to the game and the user
+// (submission of scores of the user if submit is set). Returns the id under which the game is stored for the tenant of the call
+func checkRequest(ctx context.Context, params any, gameId string, userId string, submit bool) (string, error) {
+	err := binding.Validator.ValidateStruct(params)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	auth := getRequestAuth(ctx)
+	submitUserId := ""
+	if submit {
+		submitUserId = userId
+	}
+	if auth.apiKey != nil && (!auth.apiKey.CanAccessGame(gameId) || (submitUserId != "" && !auth.apiKey.CanSubmitFor(submitUserId))) {
+		logger.Warn("Access denied", log.LogParams{"gameId": gameId})
+		return "", status.Error(codes.PermissionDenied, services.ErrAccessDenied.Error())
+	}
+	if userId != "" && auth.claims != nil && !auth.claims.CanActFor(userId) {
+		logger.Warn("Access denied", log.LogParams{"gameId": gameId, "userId": userId})
+		return "", status.Error(codes.PermissionDenied, services.ErrAccessDenied.Error())
+	}
+
+	return dbprovider.TenantGameId(auth.tenant, gameId), nil
+}
+
+func internalError() error {
+	return status.Error(codes.Internal, "Internal server error")
+}
@@ -0,0 +1,295 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: internal/grpcapi/pb/leaderboard.proto
+
+package leaderboardpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Leaderboard_SendScore_FullMethodName   = "/leaderboard.v1.Leaderboard/SendScore"
+	Leaderboard_GetScore_FullMethodName    = "/leaderboard.v1.Leaderboard/GetScore"
+	Leaderboard_DeleteScore_FullMethodName = "/leaderboard.v1.Leaderboard/DeleteScore"
+	Leaderboard_GetTop_FullMethodName      = "/leaderboard.v1.Leaderboard/GetTop"
+	Leaderboard_WatchTop_FullMethodName    = "/leaderboard.v1.Leaderboard/WatchTop"
+)
+
+// LeaderboardClient is the client API for Leaderboard service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LeaderboardClient interface {
+	// Stores user data (a new run of the user for runs boards)
+	SendScore(ctx context.Context, in *SendScoreRequest, opts ...grpc.CallOption) (*SendScoreResponse, error)
+	// Gets user data (runs of the user sorted in descending order of score for runs boards), NOT_FOUND if the user has no data
+	GetScore(ctx context.Context, in *GetScoreRequest, opts ...grpc.CallOption) (*GetScoreResponse, error)
+	// Removes user data (all runs of the user for runs boards), NOT_FOUND if the user has no data
+	DeleteScore(ctx context.Context, in *DeleteScoreRequest, opts ...grpc.CallOption) (*DeleteScoreResponse, error)
+	// Gets the top of users (runs for runs boards) sorted in descending order of score
+	GetTop(ctx context.Context, in *GetTopRequest, opts ...grpc.CallOption) (*GetTopResponse, error)
+	// Sends the current top and then every change of it
+	WatchTop(ctx context.Context, in *GetTopRequest, opts ...grpc.CallOption) (Leaderboard_WatchTopClient, error)
+}
+
+type leaderboardClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLeaderboardClient(cc grpc.ClientConnInterface) LeaderboardClient {
+	return &leaderboardClient{cc}
+}
+
+func (c *leaderboardClient) SendScore(ctx context.Context, in *SendScoreRequest, opts ...grpc.CallOption) (*SendScoreResponse, error) {
+	out := new(SendScoreResponse)
+	err := c.cc.Invoke(ctx, Leaderboard_SendScore_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *leaderboardClient) GetScore(ctx context.Context, in *GetScoreRequest, opts ...grpc.CallOption) (*GetScoreResponse, error) {
+	out := new(GetScoreResponse)
+	err := c.cc.Invoke(ctx, Leaderboard_GetScore_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *leaderboardClient) DeleteScore(ctx context.Context, in *DeleteScoreRequest, opts ...grpc.CallOption) (*DeleteScoreResponse, error) {
+	out := new(DeleteScoreResponse)
+	err := c.cc.Invoke(ctx, Leaderboard_DeleteScore_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *leaderboardClient) GetTop(ctx context.Context, in *GetTopRequest, opts ...grpc.CallOption) (*GetTopResponse, error) {
+	out := new(GetTopResponse)
+	err := c.cc.Invoke(ctx, Leaderboard_GetTop_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *leaderboardClient) WatchTop(ctx context.Context, in *GetTopRequest, opts ...grpc.CallOption) (Leaderboard_WatchTopClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Leaderboard_ServiceDesc.Streams[0], Leaderboard_WatchTop_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &leaderboardWatchTopClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Leaderboard_WatchTopClient interface {
+	Recv() (*GetTopResponse, error)
+	grpc.ClientStream
+}
+
+type leaderboardWatchTopClient struct {
+	grpc.ClientStream
+}
+
+func (x *leaderboardWatchTopClient) Recv() (*GetTopResponse, error) {
+	m := new(GetTopResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LeaderboardServer is the server API for Leaderboard service.
+// All implementations must embed UnimplementedLeaderboardServer
+// for forward compatibility
+type LeaderboardServer interface {
+	// Stores user data (a new run of the user for runs boards)
+	SendScore(context.Context, *SendScoreRequest) (*SendScoreResponse, error)
+	// Gets user data (runs of the user sorted in descending order of score for runs boards), NOT_FOUND if the user has no data
+	GetScore(context.Context, *GetScoreRequest) (*GetScoreResponse, error)
+	// Removes user data (all runs of the user for runs boards), NOT_FOUND if the user has no data
+	DeleteScore(context.Context, *DeleteScoreRequest) (*DeleteScoreResponse, error)
+	// Gets the top of users (runs for runs boards) sorted in descending order of score
+	GetTop(context.Context, *GetTopRequest) (*GetTopResponse, error)
+	// Sends the current top and then every change of it
+	WatchTop(*GetTopRequest, Leaderboard_WatchTopServer) error
+	mustEmbedUnimplementedLeaderboardServer()
+}
+
+// UnimplementedLeaderboardServer must be embedded to have forward compatible implementations.
+type UnimplementedLeaderboardServer struct {
+}
+
+func (UnimplementedLeaderboardServer) SendScore(context.Context, *SendScoreRequest) (*SendScoreResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendScore not implemented")
+}
+func (UnimplementedLeaderboardServer) GetScore(context.Context, *GetScoreRequest) (*GetScoreResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetScore not implemented")
+}
+func (UnimplementedLeaderboardServer) DeleteScore(context.Context, *DeleteScoreRequest) (*DeleteScoreResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteScore not implemented")
+}
+func (UnimplementedLeaderboardServer) GetTop(context.Context, *GetTopRequest) (*GetTopResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTop not implemented")
+}
+func (UnimplementedLeaderboardServer) WatchTop(*GetTopRequest, Leaderboard_WatchTopServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTop not implemented")
+}
+func (UnimplementedLeaderboardServer) mustEmbedUnimplementedLeaderboardServer() {}
+
+// UnsafeLeaderboardServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LeaderboardServer will
+// result in compilation errors.
+type UnsafeLeaderboardServer interface {
+	mustEmbedUnimplementedLeaderboardServer()
+}
+
+func RegisterLeaderboardServer(s grpc.ServiceRegistrar, srv LeaderboardServer) {
+	s.RegisterService(&Leaderboard_ServiceDesc, srv)
+}
+
+func _Leaderboard_SendScore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendScoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LeaderboardServer).SendScore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Leaderboard_SendScore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LeaderboardServer).SendScore(ctx, req.(*SendScoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Leaderboard_GetScore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetScoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LeaderboardServer).GetScore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Leaderboard_GetScore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LeaderboardServer).GetScore(ctx, req.(*GetScoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Leaderboard_DeleteScore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteScoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LeaderboardServer).DeleteScore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Leaderboard_DeleteScore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LeaderboardServer).DeleteScore(ctx, req.(*DeleteScoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Leaderboard_GetTop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LeaderboardServer).GetTop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Leaderboard_GetTop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LeaderboardServer).GetTop(ctx, req.(*GetTopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Leaderboard_WatchTop_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetTopRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LeaderboardServer).WatchTop(m, &leaderboardWatchTopServer{stream})
+}
+
+type Leaderboard_WatchTopServer interface {
+	Send(*GetTopResponse) error
+	grpc.ServerStream
+}
+
+type leaderboardWatchTopServer struct {
+	grpc.ServerStream
+}
+
+func (x *leaderboardWatchTopServer) Send(m *GetTopResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Leaderboard_ServiceDesc is the grpc.ServiceDesc for Leaderboard service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Leaderboard_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "leaderboard.v1.Leaderboard",
+	HandlerType: (*LeaderboardServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SendScore",
+			Handler:    _Leaderboard_SendScore_Handler,
+		},
+		{
+			MethodName: "GetScore",
+			Handler:    _Leaderboard_GetScore_Handler,
+		},
+		{
+			MethodName: "DeleteScore",
+			Handler:    _Leaderboard_DeleteScore_Handler,
+		},
+		{
+			MethodName: "GetTop",
+			Handler:    _Leaderboard_GetTop_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTop",
+			Handler:       _Leaderboard_WatchTop_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "internal/grpcapi/pb/leaderboard.proto",
+}
@@ -0,0 +1,24 @@
+package grpcapi
+
+import (
+	ac "go-leaderboard-server/internal/appcontext"
+	leaderboardpb "go-leaderboard-server/internal/grpcapi/pb"
+	log "go-leaderboard-server/internal/logger"
+
+	"google.golang.org/grpc"
+)
+
+var logger = log.GetLogger()
+
+// Creates the gRPC server with the leaderboard service, calls are logged, rate limited, authenticated
+// and then performed once per idempotency key
+func NewGrpcServer(appContext *ac.AppContext) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(loggingUnaryInterceptor(), rateLimitUnaryInterceptor(appContext),
+			authUnaryInterceptor(appContext), userRateLimitUnaryInterceptor(appContext), idempotencyUnaryInterceptor(appContext)),
+		grpc.ChainStreamInterceptor(loggingStreamInterceptor(), rateLimitStreamInterceptor(appContext), authStreamInterceptor(appContext)),
+	)
+	leaderboardpb.RegisterLeaderboardServer(server, &leaderboardServer{appContext: appContext})
+
+	return server
+}
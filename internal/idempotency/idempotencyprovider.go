@@ -0,0 +1,38 @@
+package idempotencyprovider
+
+import (
+	"context"
+)
+
+type IdempotencyProviderBaseConfig struct {
+	IsDebug bool // Debug flag
+}
+
+func (c *IdempotencyProviderBaseConfig) GetBaseConfig() *IdempotencyProviderBaseConfig {
+	return c
+}
+
+type IIdempotencyProviderConfig interface {
+	GetBaseConfig() *IdempotencyProviderBaseConfig
+}
+
+// Request made with an idempotency key and its response (when completed)
+type Record struct {
+	Fingerprint string `json:"fp"`           // Hash of the request body
+	Done        bool   `json:"dn"`           // Whether the response is stored (false - the request is in progress)
+	Status      int    `json:"st,omitempty"` // HTTP status of the response
+	ContentType string `json:"ct,omitempty"` // Content type of the response
+	Body        []byte `json:"bd,omitempty"` // Body of the response
+}
+
+type IIdempotencyProvider interface {
+	Initialize(ctx context.Context, config IIdempotencyProviderConfig) error
+	// Reserves the key for a request in progress for ttl ms.
+	// Returns the existing record if the key is already taken (nil - the key is reserved)
+	Reserve(ctx context.Context, key string, fingerprint string, ttl uint32, now int64) (*Record, error)
+	// Stores the response of the reserved key for ttl ms
+	Complete(ctx context.Context, key string, record Record, ttl uint32, now int64) error
+	// Releases the key, so the request can be made again
+	Delete(ctx context.Context, key string) error
+	Shutdown(ctx context.Context) error
+}
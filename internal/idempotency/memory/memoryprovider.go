@@ -0,0 +1,99 @@
+package idempotency_memory_provider
+
+import (
+	"context"
+	"errors"
+	idempotencyprovider "go-leaderboard-server/internal/idempotency"
+	log "go-leaderboard-server/internal/logger"
+	"sync"
+)
+
+var logger = log.GetLogger()
+
+const sweepInterval = 60000 // ms
+
+type IdempotencyMemoryProviderConfig struct {
+	idempotencyprovider.IdempotencyProviderBaseConfig
+}
+
+type memoryRecord struct {
+	idempotencyprovider.Record
+	exp int64 // unix ms
+}
+
+// Keeps records in process memory, so keys are known only to the server instance
+type IdempotencyMemoryProvider struct {
+	records   map[string]*memoryRecord
+	mutex     sync.Mutex
+	lastSweep int64
+}
+
+func NewIdempotencyMemoryProvider() *IdempotencyMemoryProvider {
+	return &IdempotencyMemoryProvider{
+		records: make(map[string]*memoryRecord),
+	}
+}
+
+func (p *IdempotencyMemoryProvider) Initialize(ctx context.Context, config idempotencyprovider.IIdempotencyProviderConfig) error {
+	logger.Debug("Idempotency provider initialization")
+
+	_, ok := config.(*IdempotencyMemoryProviderConfig)
+	if !ok {
+		return errors.New("wrong config")
+	}
+
+	return nil
+}
+
+func (p *IdempotencyMemoryProvider) Reserve(ctx context.Context, key string, fingerprint string, ttl uint32, now int64) (*idempotencyprovider.Record, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if now-p.lastSweep >= sweepInterval {
+		for k, r := range p.records {
+			if r.exp <= now {
+				delete(p.records, k)
+			}
+		}
+		p.lastSweep = now
+	}
+
+	if r, ok := p.records[key]; ok && r.exp > now {
+		record := r.Record
+		return &record, nil
+	}
+
+	p.records[key] = &memoryRecord{
+		Record: idempotencyprovider.Record{Fingerprint: fingerprint},
+		exp:    now + int64(ttl),
+	}
+
+	return nil, nil
+}
+
+func (p *IdempotencyMemoryProvider) Complete(ctx context.Context, key string, record idempotencyprovider.Record, ttl uint32, now int64) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	record.Done = true
+	p.records[key] = &memoryRecord{Record: record, exp: now + int64(ttl)}
+
+	return nil
+}
+
+func (p *IdempotencyMemoryProvider) Delete(ctx context.Context, key string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.records, key)
+
+	return nil
+}
+
+func (p *IdempotencyMemoryProvider) Shutdown(ctx context.Context) error {
+	logger.Debug("Idempotency provider shutdown")
+
+	/* do nothing */
+
+	return nil
+}
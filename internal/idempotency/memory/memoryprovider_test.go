@@ -0,0 +1,67 @@
+package idempotency_memory_provider
+
+import (
+	"context"
+	idempotencyprovider "go-leaderboard-server/internal/idempotency"
+	"go-leaderboard-server/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupTest() (func() error, *IdempotencyMemoryProvider, error) {
+	provider := NewIdempotencyMemoryProvider()
+	err := provider.Initialize(context.Background(), &IdempotencyMemoryProviderConfig{
+		IdempotencyProviderBaseConfig: idempotencyprovider.IdempotencyProviderBaseConfig{
+			IsDebug: true,
+		},
+	})
+
+	return func() error {
+		return provider.Shutdown(context.Background())
+	}, provider, err
+}
+
+func runTest(t *testing.T, name string, testFunc utils.TestFcn[*IdempotencyMemoryProvider]) {
+	utils.RunTest(t, name, setupTest, testFunc)
+}
+
+func TestIdempotencyMemoryProvider(t *testing.T) {
+	now := int64(1000000)
+
+	runTest(t, "reserve and complete keys", func(t *testing.T, provider *IdempotencyMemoryProvider) {
+		ctx := context.Background()
+
+		record, err := provider.Reserve(ctx, "key1", "fp1", 1000, now)
+		require.NoError(t, err)
+		require.Nil(t, record)
+
+		record, err = provider.Reserve(ctx, "key1", "fp2", 1000, now)
+		require.NoError(t, err)
+		require.Equal(t, &idempotencyprovider.Record{Fingerprint: "fp1"}, record)
+
+		// reservation expires
+		record, err = provider.Reserve(ctx, "key1", "fp2", 1000, now+1000)
+		require.NoError(t, err)
+		require.Nil(t, record)
+
+		response := idempotencyprovider.Record{Fingerprint: "fp2", Status: 200, ContentType: "application/json", Body: []byte(`{}`)}
+		err = provider.Complete(ctx, "key1", response, 5000, now+1000)
+		require.NoError(t, err)
+
+		record, err = provider.Reserve(ctx, "key1", "fp2", 1000, now+2000)
+		require.NoError(t, err)
+		response.Done = true
+		require.Equal(t, &response, record)
+
+		record, err = provider.Reserve(ctx, "key1", "fp2", 1000, now+6000)
+		require.NoError(t, err)
+		require.Nil(t, record)
+
+		err = provider.Delete(ctx, "key1")
+		require.NoError(t, err)
+		record, err = provider.Reserve(ctx, "key1", "fp3", 1000, now+6000)
+		require.NoError(t, err)
+		require.Nil(t, record)
+	})
+}
@@ -0,0 +1,125 @@
+package idempotency_redis_provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	idempotencyprovider "go-leaderboard-server/internal/idempotency"
+	log "go-leaderboard-server/internal/logger"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var logger = log.GetLogger()
+
+type RedisOptions redis.Options
+
+// Prefix of keys used when KeyPrefix isn't set
+const DEFAULT_KEY_PREFIX = "ldb:"
+
+type IdempotencyRedisProviderConfig struct {
+	idempotencyprovider.IdempotencyProviderBaseConfig
+	Opts      RedisOptions
+	KeyPrefix string // Prefix of all keys, separates leaderboard data from other data of the database (empty - DEFAULT_KEY_PREFIX)
+}
+
+// Keeps records in Redis (JSON strings with native expiry), so keys are shared by all server instances
+type IdempotencyRedisProvider struct {
+	rdb    *redis.Client
+	prefix string
+}
+
+func NewIdempotencyRedisProvider() *IdempotencyRedisProvider {
+	return &IdempotencyRedisProvider{}
+}
+
+func (p *IdempotencyRedisProvider) getRecordKey(key string) string {
+	return p.prefix + "idempotency:" + key
+}
+
+func (p *IdempotencyRedisProvider) Initialize(ctx context.Context, config idempotencyprovider.IIdempotencyProviderConfig) error {
+	logger.Debug("Idempotency provider initialization")
+
+	conf, ok := config.(*IdempotencyRedisProviderConfig)
+	if !ok {
+		return errors.New("wrong config")
+	}
+
+	opts := redis.Options(conf.Opts)
+	p.rdb = redis.NewClient(&opts)
+	p.prefix = conf.KeyPrefix
+	if p.prefix == "" {
+		p.prefix = DEFAULT_KEY_PREFIX
+	}
+
+	return p.rdb.Ping(ctx).Err()
+}
+
+func (p *IdempotencyRedisProvider) Reserve(ctx context.Context, key string, fingerprint string, ttl uint32, now int64) (*idempotencyprovider.Record, error) {
+	if p.rdb == nil {
+		return nil, errors.New("uninitialized")
+	}
+
+	data, err := json.Marshal(idempotencyprovider.Record{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		ok, err := p.rdb.SetNX(ctx, p.getRecordKey(key), data, time.Duration(ttl)*time.Millisecond).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return nil, nil
+		}
+
+		val, err := p.rdb.Get(ctx, p.getRecordKey(key)).Bytes()
+		if err == redis.Nil {
+			continue // expired in between
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var record idempotencyprovider.Record
+		err = json.Unmarshal(val, &record)
+		if err != nil {
+			return nil, err
+		}
+		return &record, nil
+	}
+}
+
+func (p *IdempotencyRedisProvider) Complete(ctx context.Context, key string, record idempotencyprovider.Record, ttl uint32, now int64) error {
+	if p.rdb == nil {
+		return errors.New("uninitialized")
+	}
+
+	record.Done = true
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return p.rdb.Set(ctx, p.getRecordKey(key), data, time.Duration(ttl)*time.Millisecond).Err()
+}
+
+func (p *IdempotencyRedisProvider) Delete(ctx context.Context, key string) error {
+	if p.rdb == nil {
+		return errors.New("uninitialized")
+	}
+
+	return p.rdb.Del(ctx, p.getRecordKey(key)).Err()
+}
+
+func (p *IdempotencyRedisProvider) Shutdown(ctx context.Context) error {
+	logger.Debug("Idempotency provider shutdown")
+
+	if p.rdb == nil {
+		return nil
+	}
+
+	return p.rdb.Close()
+}
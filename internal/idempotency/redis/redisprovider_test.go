@@ -0,0 +1,96 @@
+package idempotency_redis_provider
+
+import (
+	"context"
+	idempotencyprovider "go-leaderboard-server/internal/idempotency"
+	"go-leaderboard-server/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var dbEndpoint string
+
+func prepareTest(t *testing.T) {
+	t.Log("prepare test env")
+
+	ctx := context.Background()
+	dbContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "redis:7.2.3",
+			ExposedPorts: []string{"6379"},
+			WaitingFor:   wait.ForExposedPort(),
+		},
+		Started: true,
+	})
+	require.NoError(t, err, "container should start successfully")
+
+	t.Cleanup(func() {
+		t.Log("terminate test env")
+
+		err := dbContainer.Terminate(ctx)
+		require.NoError(t, err, "container should be terminated successfully")
+	})
+
+	ep, err := dbContainer.Endpoint(ctx, "")
+	require.NoError(t, err, "container endpoint should be obtained successfully")
+
+	dbEndpoint = ep
+}
+
+func setupTest() (func() error, *IdempotencyRedisProvider, error) {
+	provider := NewIdempotencyRedisProvider()
+	err := provider.Initialize(context.Background(), &IdempotencyRedisProviderConfig{
+		IdempotencyProviderBaseConfig: idempotencyprovider.IdempotencyProviderBaseConfig{
+			IsDebug: true,
+		},
+		Opts: RedisOptions{
+			Addr: dbEndpoint,
+		},
+	})
+
+	return func() error {
+		return provider.Shutdown(context.Background())
+	}, provider, err
+}
+
+func runTest(t *testing.T, name string, testFunc utils.TestFcn[*IdempotencyRedisProvider]) {
+	utils.RunTest(t, name, setupTest, testFunc)
+}
+
+func TestIdempotencyRedisProvider(t *testing.T) {
+	prepareTest(t)
+
+	runTest(t, "reserve and complete keys", func(t *testing.T, provider *IdempotencyRedisProvider) {
+		ctx := context.Background()
+
+		record, err := provider.Reserve(ctx, "key1", "fp1", 10000, 0)
+		require.NoError(t, err)
+		require.Nil(t, record)
+
+		record, err = provider.Reserve(ctx, "key1", "fp2", 10000, 0)
+		require.NoError(t, err)
+		require.Equal(t, &idempotencyprovider.Record{Fingerprint: "fp1"}, record)
+
+		response := idempotencyprovider.Record{Fingerprint: "fp1", Status: 200, ContentType: "application/json", Body: []byte(`{}`)}
+		err = provider.Complete(ctx, "key1", response, 60000, 0)
+		require.NoError(t, err)
+
+		record, err = provider.Reserve(ctx, "key1", "fp1", 10000, 0)
+		require.NoError(t, err)
+		response.Done = true
+		require.Equal(t, &response, record)
+
+		ttl, err := provider.rdb.PTTL(ctx, provider.getRecordKey("key1")).Result()
+		require.NoError(t, err)
+		require.Greater(t, ttl.Milliseconds(), int64(10000))
+
+		err = provider.Delete(ctx, "key1")
+		require.NoError(t, err)
+		record, err = provider.Reserve(ctx, "key1", "fp2", 10000, 0)
+		require.NoError(t, err)
+		require.Nil(t, record)
+	})
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	ac "go-leaderboard-server/internal/appcontext"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const HEADER_API_KEY = "X-Api-Key"
+
+// Rejects requests without a valid API key of the specified role (or a more privileged one).
+// The key and its tenant are stored in the context, so controllers can check game and user scopes
+func AuthMiddleware(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+			logger               = log.GetLogger()
+		)
+
+		if !ac.AuthService.IsEnabled() {
+			c.Next()
+			return
+		}
+
+		apiKey, err := ac.AuthService.Authenticate(c.GetHeader(HEADER_API_KEY))
+		if err != nil {
+			logger.Warn("Authentication failed", log.LogParams{"error": err, "path": c.FullPath()})
+			_ = c.AbortWithError(http.StatusUnauthorized, err)
+			return
+		}
+
+		if !apiKey.HasRole(role) {
+			logger.Warn("Access denied", log.LogParams{"role": apiKey.Role, "path": c.FullPath()})
+			_ = c.AbortWithError(http.StatusForbidden, services.ErrAccessDenied)
+			return
+		}
+
+		c.Set("apikey", apiKey)
+		c.Set("tenant", apiKey.Tenant)
+		c.Next()
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"go-leaderboard-server/internal/config"
+	log "go-leaderboard-server/internal/logger"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ErrBodyTooLarge = errors.New("request body is too large")
+
+// Rejects requests with bodies larger than the limit of the route with 413 error.
+// The body is read before the handler, so the handler and other middlewares never see a partial body
+func BodyLimitMiddleware(conf config.HttpConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		limit := int64(conf.MaxBodySize)
+		if routeLimit, ok := conf.MaxBodySizes[c.Request.Method+" "+c.FullPath()]; ok && routeLimit > 0 {
+			limit = int64(routeLimit)
+		}
+
+		if c.Request.ContentLength > limit {
+			log.GetLogger().Warn("Request body is too large", log.LogParams{"size": int(c.Request.ContentLength), "path": c.FullPath()})
+			_ = c.AbortWithError(http.StatusRequestEntityTooLarge, ErrBodyTooLarge)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+		if err != nil {
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		if int64(len(body)) > limit {
+			log.GetLogger().Warn("Request body is too large", log.LogParams{"path": c.FullPath()})
+			_ = c.AbortWithError(http.StatusRequestEntityTooLarge, ErrBodyTooLarge)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		c.Next()
+	}
+}
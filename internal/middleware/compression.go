@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"go-leaderboard-server/internal/config"
+	log "go-leaderboard-server/internal/logger"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ENCODING_BROTLI = "br"
+	ENCODING_GZIP   = "gzip"
+)
+
+// Buffers the beginning of the response until it reaches the minimum size, then compresses it.
+// Smaller responses, event streams and responses that are already encoded are sent as is
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding   string
+	minSize    int
+	buf        bytes.Buffer
+	compressor io.WriteCloser // nil - not compressed
+	decided    bool
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		contentType := w.Header().Get("Content-Type")
+		if w.Header().Get("Content-Encoding") != "" || strings.HasPrefix(contentType, "text/event-stream") {
+			w.decide(false)
+			return w.ResponseWriter.Write(data)
+		}
+
+		w.buf.Write(data)
+		if w.buf.Len() >= w.minSize {
+			w.decide(true)
+		}
+		return len(data), nil
+	}
+
+	if w.compressor != nil {
+		return w.compressor.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// The header is written with the body, after the encoding is known
+func (w *compressWriter) WriteHeaderNow() {
+	if w.decided {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *compressWriter) Written() bool {
+	return w.buf.Len() > 0 || w.ResponseWriter.Written()
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(w.buf.Len() >= w.minSize)
+	}
+	if flusher, ok := w.compressor.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Starts the response with or without compression and writes the buffered data
+func (w *compressWriter) decide(compress bool) {
+	w.decided = true
+	if compress {
+		w.Header().Del("Content-Length")
+		w.Header().Set("Content-Encoding", w.encoding)
+		if w.encoding == ENCODING_BROTLI {
+			w.compressor = brotli.NewWriterLevel(w.ResponseWriter, brotli.DefaultCompression)
+		} else {
+			w.compressor, _ = gzip.NewWriterLevel(w.ResponseWriter, gzip.DefaultCompression)
+		}
+	}
+
+	if w.buf.Len() > 0 {
+		data := w.buf.Bytes()
+		w.buf = bytes.Buffer{}
+		if w.compressor != nil {
+			_, _ = w.compressor.Write(data)
+		} else {
+			_, _ = w.ResponseWriter.Write(data)
+		}
+	}
+}
+
+// Finishes the response: small responses are sent as is, the compressed stream is closed
+func (w *compressWriter) close() error {
+	if !w.decided {
+		w.decide(false)
+	}
+	if w.compressor != nil {
+		return w.compressor.Close()
+	}
+	return nil
+}
+
+// Compresses responses with brotli or gzip as negotiated by the Accept-Encoding header.
+// Must be the first middleware, so the response is compressed after it is encoded
+func CompressionMiddleware(conf *config.CompressionConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: int(conf.MinSize)}
+		c.Writer = writer
+		defer func() {
+			c.Writer = writer.ResponseWriter
+			err := writer.close()
+			if err != nil {
+				log.GetLogger().Error("Failed to compress response", log.LogParams{"error": err, "path": c.FullPath()})
+			}
+		}()
+		c.Next()
+	}
+}
+
+// Returns the supported encoding with the highest quality value of the Accept-Encoding header (empty - none)
+func negotiateEncoding(acceptEncoding string) string {
+	var (
+		encoding string
+		quality  float64
+	)
+	for _, item := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		q := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			var err error
+			q, err = strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "*" {
+			name = ENCODING_BROTLI
+		}
+		if name != ENCODING_BROTLI && name != ENCODING_GZIP || q <= 0 {
+			continue
+		}
+		if q > quality || q == quality && name == ENCODING_BROTLI {
+			encoding, quality = name, q
+		}
+	}
+	return encoding
+}
@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	log "go-leaderboard-server/internal/logger"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/ugorji/go/codec"
+)
+
+const (
+	MIME_MSGPACK    = "application/msgpack"
+	MIME_XMSGPACK   = binding.MIMEMSGPACK2 // application/x-msgpack
+	MIME_PROTOBUF   = "application/protobuf"
+	MIME_XPROTOBUF  = binding.MIMEPROTOBUF // application/x-protobuf
+	mimeJsonCharset = "application/json; charset=utf-8"
+)
+
+var ErrWrongBodyEncoding = errors.New("request body can't be decoded")
+
+// Media types of the responses, the first one is used when the request has no Accept header
+var responseMimeTypes = []string{binding.MIMEJSON, MIME_MSGPACK, MIME_XMSGPACK, MIME_XPROTOBUF, MIME_PROTOBUF}
+
+var msgpackHandle = func() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{}
+	h.MapType = reflect.TypeOf(map[string]interface{}(nil))
+	h.RawToString = true
+	h.WriteExt = true
+	h.Canonical = true
+	return h
+}()
+
+// Buffers JSON responses, so they can be encoded in the negotiated media type.
+// Other responses (event streams, replayed records of other types) are passed through
+type encodingWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	buffering bool
+	decided   bool
+}
+
+func (w *encodingWriter) decide() {
+	if !w.decided {
+		w.decided = true
+		w.buffering = strings.HasPrefix(w.Header().Get("Content-Type"), binding.MIMEJSON)
+	}
+}
+
+func (w *encodingWriter) Write(data []byte) (int, error) {
+	w.decide()
+	if w.buffering {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *encodingWriter) WriteString(s string) (int, error) {
+	w.decide()
+	if w.buffering {
+		return w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// The header is written with the body, after the Content-Type is known
+func (w *encodingWriter) WriteHeaderNow() {}
+
+func (w *encodingWriter) Written() bool {
+	return w.body.Len() > 0 || w.ResponseWriter.Written()
+}
+
+func (w *encodingWriter) Flush() {
+	if !w.buffering {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// Encodes JSON responses as MessagePack or Protobuf (messages of internal/controllers/pb/dto.proto)
+// when the Accept header asks for it. Must be the first middleware, so responses of the recovery and the error handler are encoded too
+func ResponseEncodingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mimeType := c.NegotiateFormat(responseMimeTypes...)
+		if mimeType == "" || mimeType == binding.MIMEJSON {
+			c.Next()
+			return
+		}
+
+		writer := &encodingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if !writer.buffering {
+			return
+		}
+
+		var (
+			body []byte
+			err  error
+		)
+		if mimeType == MIME_PROTOBUF || mimeType == MIME_XPROTOBUF {
+			body, err = encodeProtoBody(writer.body.Bytes(), c.Request.Method+" "+c.FullPath(), writer.Status())
+		} else {
+			body, err = encodeMsgpackBody(writer.body.Bytes())
+		}
+		if err != nil {
+			// the response is still correct, just not in the requested media type
+			log.GetLogger().Error("Failed to encode response", log.LogParams{"error": err, "mimeType": mimeType})
+			c.Writer.Header().Set("Content-Type", mimeJsonCharset)
+			body = writer.body.Bytes()
+		} else {
+			c.Writer.Header().Set("Content-Type", mimeType)
+		}
+		c.Writer.Header().Del("Content-Length")
+		_, _ = c.Writer.Write(body)
+	}
+}
+
+// Decodes MessagePack and Protobuf (the params message of the route) request bodies to JSON,
+// so the handlers and the middlewares reading the body bind and validate them as JSON requests.
+// The media type of the decoded body is stored in the context as "bodyencoding"
+func RequestDecodingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mimeType := c.ContentType()
+		if c.Request.Body == nil ||
+			mimeType != MIME_MSGPACK && mimeType != MIME_XMSGPACK && mimeType != MIME_PROTOBUF && mimeType != MIME_XPROTOBUF {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+
+		if len(body) > 0 {
+			if mimeType == MIME_PROTOBUF || mimeType == MIME_XPROTOBUF {
+				body, err = decodeProtoBody(body, c.Request.Method+" "+c.FullPath())
+			} else {
+				body, err = decodeMsgpackBody(body)
+			}
+			if err != nil {
+				log.GetLogger().Error("Wrong params", log.LogParams{"error": err, "mimeType": mimeType})
+				_ = c.AbortWithError(http.StatusBadRequest, ErrWrongBodyEncoding)
+				return
+			}
+		}
+
+		c.Set("bodyencoding", mimeType)
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+		c.Request.Header.Set("Content-Type", binding.MIMEJSON)
+		c.Request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		c.Next()
+	}
+}
+
+// Returns the JSON body encoded as MessagePack
+func encodeMsgpackBody(body []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+
+	var encoded []byte
+	err = codec.NewEncoderBytes(&encoded, msgpackHandle).Encode(fromJsonNumbers(value))
+	return encoded, err
+}
+
+// Returns the MessagePack body as JSON
+func decodeMsgpackBody(body []byte) ([]byte, error) {
+	var value interface{}
+	err := codec.NewDecoderBytes(body, msgpackHandle).Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// Replaces JSON numbers with integers where possible and floats otherwise
+func fromJsonNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = fromJsonNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = fromJsonNumbers(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"bytes"
+	ac "go-leaderboard-server/internal/appcontext"
+	idempotencyprovider "go-leaderboard-server/internal/idempotency"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HEADER_IDEMPOTENCY_KEY      = "Idempotency-Key"
+	HEADER_IDEMPOTENCY_REPLAYED = "Idempotent-Replayed" // Set to "true" on responses returned from the store
+
+	maxIdempotencyKeyLength = 255
+)
+
+// Copies the response body, so it can be stored
+type captureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Performs requests with the Idempotency-Key header only once: successful responses are stored for the window
+// and returned to repeated requests, duplicates that arrive while the request is in progress wait for its response
+func IdempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+			logger               = log.GetLogger()
+		)
+
+		key := c.GetHeader(HEADER_IDEMPOTENCY_KEY)
+		if !ac.IdempotencyService.IsEnabled() || key == "" {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			logger.Error("Wrong params", log.LogParams{"error": services.ErrIdempotencyKeyTooLong})
+			_ = c.AbortWithError(http.StatusBadRequest, services.ErrIdempotencyKeyTooLong)
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		request := services.IdempotencyRequest{
+			Key:    key,
+			Path:   c.Request.URL.Path,
+			ApiKey: c.GetHeader(HEADER_API_KEY),
+			Tenant: c.GetString("tenant"),
+			Body:   body,
+		}
+		if value, ok := c.Get("jwtclaims"); ok {
+			request.Subject = value.(*services.JwtClaims).Subject
+		}
+
+		record, replayed, err := ac.IdempotencyService.Do(c.Request.Context(), request, func() *idempotencyprovider.Record {
+			// the signature nonce is used up only by requests that are performed, not by the replayed ones
+			if useNonce, ok := c.Get("usenonce"); ok && !useNonce.(func() bool)() {
+				return nil
+			}
+
+			writer := &captureWriter{ResponseWriter: c.Writer}
+			c.Writer = writer
+			c.Next()
+			c.Writer = writer.ResponseWriter
+
+			// only successful responses are stored, failed requests can be repeated
+			status := writer.Status()
+			if c.IsAborted() || status < 200 || status >= 300 {
+				return nil
+			}
+			return &idempotencyprovider.Record{
+				Status:      status,
+				ContentType: writer.Header().Get("Content-Type"),
+				Body:        writer.body.Bytes(),
+			}
+		})
+
+		switch {
+		case err == services.ErrIdempotencyKeyReused:
+			logger.Warn("Idempotency key reused", log.LogParams{"path": c.FullPath()})
+			_ = c.AbortWithError(http.StatusUnprocessableEntity, err)
+		case err == services.ErrIdempotencyKeyInProgress:
+			logger.Warn("Idempotent request is in progress", log.LogParams{"path": c.FullPath()})
+			_ = c.AbortWithError(http.StatusConflict, err)
+		case err != nil:
+			logger.Error("Failed to process idempotency key", log.LogParams{"error": err, "path": c.FullPath()})
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+		case replayed:
+			c.Header(HEADER_IDEMPOTENCY_REPLAYED, "true")
+			c.Data(record.Status, record.ContentType, record.Body)
+			c.Abort()
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	ac "go-leaderboard-server/internal/appcontext"
+	"go-leaderboard-server/internal/config"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const HEADER_AUTHORIZATION = "Authorization"
+
+// Rejects requests without a valid bearer token of a player.
+// Requests authenticated by a server or admin API key don't need a token.
+// The claims are stored in the context, so controllers can check the user of the request.
+// Tokens of a tenant other than the one of the API key are rejected
+func JwtMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+			logger               = log.GetLogger()
+		)
+
+		if !ac.JwtService.IsEnabled() {
+			c.Next()
+			return
+		}
+
+		if value, ok := c.Get("apikey"); ok && value.(*services.ApiKey).HasRole(config.ROLE_SERVER) {
+			c.Next()
+			return
+		}
+
+		token := ""
+		scheme, value, found := strings.Cut(c.GetHeader(HEADER_AUTHORIZATION), " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			token = strings.TrimSpace(value)
+		}
+
+		claims, err := ac.JwtService.Verify(token)
+		if err != nil {
+			logger.Warn("Token verification failed", log.LogParams{"error": err, "path": c.FullPath()})
+			_ = c.AbortWithError(http.StatusUnauthorized, err)
+			return
+		}
+
+		if tenant, ok := c.Get("tenant"); ok && tenant != claims.Tenant {
+			logger.Warn("Access denied", log.LogParams{"tenant": claims.Tenant, "path": c.FullPath()})
+			_ = c.AbortWithError(http.StatusForbidden, services.ErrAccessDenied)
+			return
+		}
+
+		c.Set("jwtclaims", claims)
+		c.Set("tenant", claims.Tenant)
+		c.Next()
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	ac "go-leaderboard-server/internal/appcontext"
 	"go-leaderboard-server/internal/controllers"
 
@@ -19,17 +20,36 @@ func ErrorHandlerMiddleware(isDebug bool) gin.HandlerFunc {
 		c.Next()
 		if len(c.Errors) > 0 {
 			err := c.Errors.Last()
+			var errCode string
+			var coded interface{ ErrorCode() string }
+			if errors.As(err.Err, &coded) {
+				errCode = coded.ErrorCode()
+			}
 			if isDebug {
-				c.JSON(-1, &controllers.ResultError{Error: err.Error()})
+				c.JSON(-1, &controllers.ResultError{Error: err.Error(), Code: errCode})
 			} else {
 				var errMsg string // hide error details from client
 				switch c.Writer.Status() {
 				case 400:
 					errMsg = "Wrong params"
+				case 401:
+					errMsg = "Unauthorized"
+				case 403:
+					errMsg = "Forbidden"
+				case 404:
+					errMsg = "Not found"
+				case 409:
+					errMsg = "Conflict"
+				case 413:
+					errMsg = "Request body is too large"
+				case 422:
+					errMsg = err.Error() // rejection reason is meant for client
+				case 429:
+					errMsg = "Too many requests"
 				default:
 					errMsg = "Internal server error"
 				}
-				c.JSON(-1, &controllers.ResultError{Error: errMsg})
+				c.JSON(-1, &controllers.ResultError{Error: errMsg, Code: errCode})
 			}
 		}
 	}
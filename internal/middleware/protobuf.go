@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	dtopb "go-leaderboard-server/internal/controllers/pb"
+	"net/http"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Messages of the bodies of a route
+type protoRoute struct {
+	params  func() proto.Message   // Message of the request body (nil - the route has no body)
+	results []func() proto.Message // Messages of successful responses, the first one the response fits into is used
+}
+
+func protoMessage[T any, PT interface {
+	*T
+	proto.Message
+}]() func() proto.Message {
+	return func() proto.Message { return PT(new(T)) }
+}
+
+var (
+	resultSuccess = []func() proto.Message{protoMessage[dtopb.ResultSuccess]()}
+	resultScore   = []func() proto.Message{protoMessage[dtopb.GetScoreResultSuccess](), protoMessage[dtopb.GetScoreRunsResultSuccess]()}
+)
+
+// Messages of the routes (key - method and full path of the route)
+var protoRoutes = map[string]protoRoute{
+	"GET /Status": {results: resultSuccess},
+
+	"POST /leaderboard/SendScore":   {params: protoMessage[dtopb.SendScoreParams](), results: resultSuccess},
+	"POST /leaderboard/DeleteScore": {params: protoMessage[dtopb.DeleteScoreParams](), results: resultSuccess},
+	"POST /leaderboard/GetScore":    {params: protoMessage[dtopb.GetScoreParams](), results: resultScore},
+	"POST /leaderboard/GetTop": {params: protoMessage[dtopb.GetTopParams](),
+		results: []func() proto.Message{protoMessage[dtopb.GetTopResultSuccess]()}},
+	"POST /leaderboard/GetChanges": {params: protoMessage[dtopb.GetChangesParams](),
+		results: []func() proto.Message{protoMessage[dtopb.GetChangesResultSuccess]()}},
+
+	"POST /admin/SetUserState": {params: protoMessage[dtopb.SetUserStateParams](), results: resultSuccess},
+	"POST /admin/GetUserState": {params: protoMessage[dtopb.GetUserStateParams](),
+		results: []func() proto.Message{protoMessage[dtopb.GetUserStateResultSuccess]()}},
+	"POST /admin/GetQuarantine": {params: protoMessage[dtopb.GetQuarantineParams](),
+		results: []func() proto.Message{protoMessage[dtopb.GetQuarantineResultSuccess]()}},
+	"POST /admin/ApproveQuarantined": {params: protoMessage[dtopb.ApproveQuarantinedParams](), results: resultSuccess},
+	"POST /admin/RejectQuarantined":  {params: protoMessage[dtopb.RejectQuarantinedParams](), results: resultSuccess},
+	"POST /admin/GetAudit": {params: protoMessage[dtopb.GetAuditParams](),
+		results: []func() proto.Message{protoMessage[dtopb.GetAuditResultSuccess]()}},
+	"POST /admin/GetUsage": {params: protoMessage[dtopb.GetUsageParams](),
+		results: []func() proto.Message{protoMessage[dtopb.GetUsageResultSuccess]()}},
+	"POST /admin/GetDeadLetters": {params: protoMessage[dtopb.GetDeadLettersParams](),
+		results: []func() proto.Message{protoMessage[dtopb.GetDeadLettersResultSuccess]()}},
+	"POST /admin/ReplayDeadLetters": {params: protoMessage[dtopb.ReplayDeadLettersParams](),
+		results: []func() proto.Message{protoMessage[dtopb.ReplayDeadLettersResultSuccess]()}},
+
+	// path and query params of v2 routes take precedence over the fields of the body
+	"PUT /v2/games/:gameId/users/:userId":    {params: protoMessage[dtopb.SendScoreParams](), results: resultSuccess},
+	"DELETE /v2/games/:gameId/users/:userId": {params: protoMessage[dtopb.DeleteScoreParams](), results: resultSuccess},
+	"GET /v2/games/:gameId/users/:userId":    {params: protoMessage[dtopb.GetScoreParams](), results: resultScore},
+	"GET /v2/games/:gameId/top": {params: protoMessage[dtopb.GetTopParams](),
+		results: []func() proto.Message{protoMessage[dtopb.GetTopResultSuccess]()}},
+	"GET /v2/games/:gameId/top/subscribe": {params: protoMessage[dtopb.SubscribeTopParams]()},
+	"GET /v2/games/:gameId/changes": {params: protoMessage[dtopb.GetChangesParams](),
+		results: []func() proto.Message{protoMessage[dtopb.GetChangesResultSuccess]()}},
+	"PUT /v2/games/:gameId/users/:userId/state": {params: protoMessage[dtopb.SetUserStateParams](), results: resultSuccess},
+	"GET /v2/games/:gameId/users/:userId/state": {params: protoMessage[dtopb.GetUserStateParams](),
+		results: []func() proto.Message{protoMessage[dtopb.GetUserStateResultSuccess]()}},
+	"GET /v2/games/:gameId/quarantine": {params: protoMessage[dtopb.GetQuarantineParams](),
+		results: []func() proto.Message{protoMessage[dtopb.GetQuarantineResultSuccess]()}},
+	"POST /v2/games/:gameId/quarantine/:id/approve": {params: protoMessage[dtopb.ApproveQuarantinedParams](), results: resultSuccess},
+	"DELETE /v2/games/:gameId/quarantine/:id":       {params: protoMessage[dtopb.RejectQuarantinedParams](), results: resultSuccess},
+	"GET /v2/audit": {params: protoMessage[dtopb.GetAuditParams](),
+		results: []func() proto.Message{protoMessage[dtopb.GetAuditResultSuccess]()}},
+	"GET /v2/usage": {params: protoMessage[dtopb.GetUsageParams](),
+		results: []func() proto.Message{protoMessage[dtopb.GetUsageResultSuccess]()}},
+	"GET /v2/webhooks/:endpoint/deadletters": {params: protoMessage[dtopb.GetDeadLettersParams](),
+		results: []func() proto.Message{protoMessage[dtopb.GetDeadLettersResultSuccess]()}},
+	"POST /v2/webhooks/:endpoint/deadletters/replay": {params: protoMessage[dtopb.ReplayDeadLettersParams](),
+		results: []func() proto.Message{protoMessage[dtopb.ReplayDeadLettersResultSuccess]()}},
+}
+
+// Returns the JSON response of the route encoded as the message of the response (ResultError for errors)
+func encodeProtoBody(body []byte, route string, status int) ([]byte, error) {
+	results := []func() proto.Message{protoMessage[dtopb.ResultError]()}
+	if status < http.StatusBadRequest {
+		results = protoRoutes[route].results
+	}
+
+	var err error = fmt.Errorf("no protobuf message of the response of %q", route)
+	for _, result := range results {
+		message := result()
+		// the JSON mapping keeps the numbers as they are, 64-bit integers included
+		err = protojson.Unmarshal(body, message)
+		if err == nil {
+			return proto.Marshal(message)
+		}
+	}
+	return nil, err
+}
+
+// Returns the protobuf request body of the route as JSON
+func decodeProtoBody(body []byte, route string) ([]byte, error) {
+	params := protoRoutes[route].params
+	if params == nil {
+		return nil, fmt.Errorf("no protobuf message of the request of %q", route)
+	}
+
+	message := params()
+	err := proto.Unmarshal(body, message)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(protoToMap(message.ProtoReflect()))
+}
+
+// Returns the populated fields of the message by their JSON names. Unlike protojson, which writes
+// 64-bit integers as strings, numbers are kept as numbers, so the body binds to the params as is
+func protoToMap(message protoreflect.Message) map[string]interface{} {
+	fields := make(map[string]interface{})
+	message.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.IsList() {
+			list := v.List()
+			items := make([]interface{}, list.Len())
+			for i := range items {
+				items[i] = protoValue(fd, list.Get(i))
+			}
+			fields[fd.JSONName()] = items
+		} else {
+			fields[fd.JSONName()] = protoValue(fd, v)
+		}
+		return true
+	})
+	return fields
+}
+
+func protoValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	if fd.Kind() == protoreflect.MessageKind {
+		return protoToMap(v.Message())
+	}
+	return v.Interface()
+}
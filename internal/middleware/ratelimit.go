@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	ac "go-leaderboard-server/internal/appcontext"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const HEADER_RETRY_AFTER = "Retry-After"
+
+// Rejects requests of the route group that exceed the rate limits of their IP, authenticated API key or tenant of the API key.
+// Users are limited by UserRateLimitMiddleware once the request is authenticated. Requests are let through if the limits store fails
+func RateLimitMiddleware(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			ac ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+		)
+
+		groupConf := ac.RateLimitService.GetGroupConfig(group)
+		if groupConf == nil {
+			c.Next()
+			return
+		}
+
+		identity := services.RateLimitIdentity{
+			Ip: c.ClientIP(),
+		}
+
+		if (groupConf.ApiKey != nil || groupConf.Tenant != nil) && ac.AuthService.IsEnabled() {
+			apiKey, err := ac.AuthService.Authenticate(c.GetHeader(HEADER_API_KEY))
+			if err == nil {
+				// wrong keys are rejected by the auth middleware, they don't get buckets of their own
+				identity.KeyId = apiKey.Id
+				identity.Tenant = apiKey.Tenant
+			}
+		}
+
+		retryAfter, err := ac.RateLimitService.Take(c, group, identity)
+		if !checkRateLimit(c, identity, "", retryAfter, err) {
+			return
+		}
+
+		c.Set("ratelimit", identity)
+		c.Next()
+	}
+}
+
+// Rejects authenticated requests of the route group that exceed the rate limit of their user: the subject of the token,
+// the user of the client key, otherwise the userId of the path or the JSON body (restored for the handler), limited separately
+// for every API key that sends it. Tokens taken by RateLimitMiddleware are returned if the request is rejected
+func UserRateLimitMiddleware(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+			logger               = log.GetLogger()
+			apiKey *services.ApiKey
+			claims *services.JwtClaims
+		)
+
+		groupConf := ac.RateLimitService.GetGroupConfig(group)
+		if groupConf == nil || groupConf.User == nil {
+			c.Next()
+			return
+		}
+
+		if value, ok := c.Get("apikey"); ok {
+			apiKey = value.(*services.ApiKey)
+		}
+		if value, ok := c.Get("jwtclaims"); ok {
+			claims = value.(*services.JwtClaims)
+		}
+
+		user := services.RateLimitUser(apiKey, claims, c.GetString("tenant"), "")
+		if user == "" && c.Param("userId") != "" {
+			user = services.RateLimitUser(apiKey, claims, c.GetString("tenant"), c.Param("userId"))
+		} else if user == "" {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				_ = c.AbortWithError(http.StatusBadRequest, err)
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+			// the body is parsed strictly, so the handler can't bind a userId the limit doesn't see
+			var params struct {
+				UserId string `json:"userId"`
+			}
+			if len(bytes.TrimSpace(body)) > 0 {
+				err = json.Unmarshal(body, &params)
+				if err != nil {
+					logger.Warn("Wrong params", log.LogParams{"error": err, "path": c.FullPath()})
+					_ = c.AbortWithError(http.StatusBadRequest, ErrWrongBody)
+					return
+				}
+			}
+			user = services.RateLimitUser(apiKey, claims, c.GetString("tenant"), params.UserId)
+		}
+
+		identity := c.MustGet("ratelimit").(services.RateLimitIdentity)
+		retryAfter, err := ac.RateLimitService.TakeUser(c, group, identity, user)
+		if !checkRateLimit(c, identity, user, retryAfter, err) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Checks the result of taking rate limit tokens, rejects the request if it's over the limit
+func checkRateLimit(c *gin.Context, identity services.RateLimitIdentity, user string, retryAfter int64, err error) bool {
+	logger := log.GetLogger()
+
+	if err == services.ErrRateLimited {
+		logger.Warn("Rate limit exceeded", log.LogParams{"ip": identity.Ip, "user": user, "path": c.FullPath()})
+		c.Header(HEADER_RETRY_AFTER, strconv.FormatInt((retryAfter+999)/1000, 10))
+		_ = c.AbortWithError(http.StatusTooManyRequests, err)
+		return false
+	}
+	if err != nil {
+		logger.Error("Failed to check rate limit", log.LogParams{"error": err, "path": c.FullPath()})
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+const HEADER_REQUEST_ID = "X-Request-Id"
+
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Assigns an id to the request, the id from the request header is kept if it is valid.
+// The id is returned in the response header and stored in the context for the audit log
+func RequestIdMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := RequestId(c.GetHeader(HEADER_REQUEST_ID))
+		c.Set("requestid", requestId)
+		c.Header(HEADER_REQUEST_ID, requestId)
+		c.Next()
+	}
+}
+
+// Returns the id of the request: the passed value if it is valid or a new random id
+func RequestId(value string) string {
+	if requestIdPattern.MatchString(value) {
+		return value
+	}
+
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
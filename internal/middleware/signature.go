@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	ac "go-leaderboard-server/internal/appcontext"
+	dbprovider "go-leaderboard-server/internal/db"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/services"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	HEADER_SIGNATURE           = "X-Signature"           // HMAC-SHA256 of the request (hex)
+	HEADER_SIGNATURE_TIMESTAMP = "X-Signature-Timestamp" // Time of signing (unix ms)
+	HEADER_SIGNATURE_NONCE     = "X-Signature-Nonce"     // Unique value of the request (up to 64 characters)
+)
+
+var (
+	ErrWrongBody         = errors.New("request body is not a JSON object")
+	ErrSignedBodyNotJson = errors.New("signed request body must be JSON")
+)
+
+// Rejects unsigned or wrongly signed requests to boards that require signatures.
+// The gameId is taken from the path or the JSON body (within the tenant of the request), the body is restored for the handler.
+// Ids of the path are signed as part of the body, so a v2 request has the same signature as the equivalent v1 request.
+// Bodies that can't be parsed strictly (e.g. with trailing data) are rejected, the handler would bind them differently.
+// MessagePack and Protobuf bodies of signed requests are rejected, the signature covers the JSON form only.
+// The nonce of a request with an idempotency key is used up by the idempotency middleware when the request is performed,
+// so an exact retry gets the stored response instead of a replay error
+func SignatureMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			ac     ac.AppContext = c.MustGet("appcontext").(ac.AppContext)
+			logger               = log.GetLogger()
+		)
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		signed, err := addPathParams(c, body)
+		var params struct {
+			GameId string `json:"gameId"`
+		}
+		if err == nil {
+			err = json.Unmarshal(signed, &params)
+		}
+		if err != nil {
+			logger.Warn("Wrong params", log.LogParams{"error": err, "path": c.FullPath()})
+			_ = c.AbortWithError(http.StatusBadRequest, ErrWrongBody)
+			return
+		}
+
+		gameId := dbprovider.TenantGameId(c.GetString("tenant"), params.GameId)
+		if !ac.SignatureService.IsRequired(gameId) {
+			c.Next()
+			return
+		}
+
+		if encoding := c.GetString("bodyencoding"); encoding != "" {
+			logger.Warn("Signature check failed", log.LogParams{"error": ErrSignedBodyNotJson, "encoding": encoding, "path": c.FullPath()})
+			_ = c.AbortWithError(http.StatusUnsupportedMediaType, ErrSignedBodyNotJson)
+			return
+		}
+
+		timestamp, nonce := c.GetHeader(HEADER_SIGNATURE_TIMESTAMP), c.GetHeader(HEADER_SIGNATURE_NONCE)
+		err = ac.SignatureService.Check(gameId, timestamp, nonce, c.GetHeader(HEADER_SIGNATURE), signed)
+		if err != nil {
+			logger.Warn("Signature check failed", log.LogParams{"error": err, "gameId": gameId, "path": c.FullPath()})
+			_ = c.AbortWithError(http.StatusUnauthorized, err)
+			return
+		}
+
+		useNonce := func() bool {
+			err := ac.SignatureService.UseNonce(c, gameId, timestamp, nonce)
+			if err == services.ErrSignatureReplay {
+				logger.Warn("Signature check failed", log.LogParams{"error": err, "gameId": gameId, "path": c.FullPath()})
+				_ = c.AbortWithError(http.StatusUnauthorized, err)
+				return false
+			}
+			if err != nil {
+				logger.Error("Failed to use signature nonce", log.LogParams{"error": err, "gameId": gameId, "path": c.FullPath()})
+				_ = c.AbortWithError(http.StatusInternalServerError, err)
+				return false
+			}
+			return true
+		}
+
+		if ac.IdempotencyService.IsEnabled() && c.GetHeader(HEADER_IDEMPOTENCY_KEY) != "" {
+			c.Set("usenonce", useNonce)
+		} else if !useNonce() {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Returns the JSON body with the ids of the path (gameId, userId) added to it
+func addPathParams(c *gin.Context, body []byte) ([]byte, error) {
+	if len(c.Params) == 0 {
+		return body, nil
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if len(bytes.TrimSpace(body)) > 0 {
+		err := json.Unmarshal(body, &fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, key := range []string{"gameId", "userId"} {
+		value, ok := c.Params.Get(key)
+		if !ok {
+			continue
+		}
+		field, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[key] = field
+	}
+
+	return json.Marshal(fields)
+}
@@ -0,0 +1,129 @@
+package quota_memory_provider
+
+import (
+	"context"
+	"errors"
+	log "go-leaderboard-server/internal/logger"
+	quotaprovider "go-leaderboard-server/internal/quota"
+	"sort"
+	"sync"
+)
+
+var logger = log.GetLogger()
+
+const sweepInterval = 60000 // ms
+
+type QuotaMemoryProviderConfig struct {
+	quotaprovider.QuotaProviderBaseConfig
+}
+
+type memoryCounter struct {
+	value int64
+	exp   int64 // unix ms
+}
+
+// Keeps counters in process memory, so usage is counted per server instance
+type QuotaMemoryProvider struct {
+	counters  map[string]*memoryCounter
+	sets      map[string]map[string]bool
+	mutex     sync.Mutex
+	lastSweep int64
+}
+
+func NewQuotaMemoryProvider() *QuotaMemoryProvider {
+	return &QuotaMemoryProvider{
+		counters: make(map[string]*memoryCounter),
+		sets:     make(map[string]map[string]bool),
+	}
+}
+
+func (p *QuotaMemoryProvider) Initialize(ctx context.Context, config quotaprovider.IQuotaProviderConfig) error {
+	logger.Debug("Quota provider initialization")
+
+	_, ok := config.(*QuotaMemoryProviderConfig)
+	if !ok {
+		return errors.New("wrong config")
+	}
+
+	return nil
+}
+
+func (p *QuotaMemoryProvider) Incr(ctx context.Context, key string, delta int64, limit int64, exp int64, now int64) (int64, bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// drop expired counters
+	if now-p.lastSweep >= sweepInterval {
+		for k, c := range p.counters {
+			if c.exp <= now {
+				delete(p.counters, k)
+			}
+		}
+		p.lastSweep = now
+	}
+
+	c, ok := p.counters[key]
+	if !ok || c.exp <= now {
+		c = &memoryCounter{}
+		p.counters[key] = c
+	}
+
+	if limit > 0 && c.value+delta > limit {
+		return c.value, false, nil
+	}
+
+	c.value += delta
+	c.exp = exp
+
+	return c.value, true, nil
+}
+
+func (p *QuotaMemoryProvider) Get(ctx context.Context, keys []string, now int64) ([]int64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	values := make([]int64, len(keys))
+	for i, key := range keys {
+		c, ok := p.counters[key]
+		if ok && c.exp > now {
+			values[i] = c.value
+		}
+	}
+
+	return values, nil
+}
+
+func (p *QuotaMemoryProvider) AddMember(ctx context.Context, key string, member string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	set, ok := p.sets[key]
+	if !ok {
+		set = make(map[string]bool)
+		p.sets[key] = set
+	}
+	set[member] = true
+
+	return nil
+}
+
+func (p *QuotaMemoryProvider) Members(ctx context.Context, key string) ([]string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	members := make([]string, 0, len(p.sets[key]))
+	for member := range p.sets[key] {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+
+	return members, nil
+}
+
+func (p *QuotaMemoryProvider) Shutdown(ctx context.Context) error {
+	logger.Debug("Quota provider shutdown")
+
+	/* do nothing */
+
+	return nil
+}
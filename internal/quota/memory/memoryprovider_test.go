@@ -0,0 +1,91 @@
+package quota_memory_provider
+
+import (
+	"context"
+	quotaprovider "go-leaderboard-server/internal/quota"
+	"go-leaderboard-server/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupTest() (func() error, *QuotaMemoryProvider, error) {
+	provider := NewQuotaMemoryProvider()
+	err := provider.Initialize(context.Background(), &QuotaMemoryProviderConfig{
+		QuotaProviderBaseConfig: quotaprovider.QuotaProviderBaseConfig{
+			IsDebug: true,
+		},
+	})
+
+	return func() error {
+		return provider.Shutdown(context.Background())
+	}, provider, err
+}
+
+func runTest(t *testing.T, name string, testFunc utils.TestFcn[*QuotaMemoryProvider]) {
+	utils.RunTest(t, name, setupTest, testFunc)
+}
+
+func TestQuotaMemoryProvider(t *testing.T) {
+	now := int64(1000000)
+	exp := now + 1000
+
+	runTest(t, "increment counters", func(t *testing.T, provider *QuotaMemoryProvider) {
+		ctx := context.Background()
+
+		for i := int64(1); i <= 2; i++ {
+			value, ok, err := provider.Incr(ctx, "key1", 1, 2, exp, now)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, i, value)
+		}
+		value, ok, err := provider.Incr(ctx, "key1", 1, 2, exp, now)
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Equal(t, int64(2), value)
+
+		value, ok, err = provider.Incr(ctx, "key1", -1, 0, exp, now)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, int64(1), value)
+
+		values, err := provider.Get(ctx, []string{"key1", "key2"}, now)
+		require.NoError(t, err)
+		require.Equal(t, []int64{1, 0}, values)
+	})
+
+	runTest(t, "expire counters", func(t *testing.T, provider *QuotaMemoryProvider) {
+		ctx := context.Background()
+
+		_, _, err := provider.Incr(ctx, "key1", 2, 0, exp, now)
+		require.NoError(t, err)
+		values, err := provider.Get(ctx, []string{"key1"}, exp)
+		require.NoError(t, err)
+		require.Equal(t, []int64{0}, values)
+
+		value, ok, err := provider.Incr(ctx, "key1", 1, 1, exp+1000, exp)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, int64(1), value)
+
+		_, _, err = provider.Incr(ctx, "key2", 1, 0, exp, exp+sweepInterval)
+		require.NoError(t, err)
+		require.Len(t, provider.counters, 1)
+	})
+
+	runTest(t, "add and list members", func(t *testing.T, provider *QuotaMemoryProvider) {
+		ctx := context.Background()
+
+		for _, member := range []string{"game2", "game1", "game2"} {
+			err := provider.AddMember(ctx, "set1", member)
+			require.NoError(t, err)
+		}
+
+		members, err := provider.Members(ctx, "set1")
+		require.NoError(t, err)
+		require.Equal(t, []string{"game1", "game2"}, members)
+		members, err = provider.Members(ctx, "set2")
+		require.NoError(t, err)
+		require.Empty(t, members)
+	})
+}
@@ -0,0 +1,31 @@
+package quotaprovider
+
+import (
+	"context"
+)
+
+type QuotaProviderBaseConfig struct {
+	IsDebug bool // Debug flag
+}
+
+func (c *QuotaProviderBaseConfig) GetBaseConfig() *QuotaProviderBaseConfig {
+	return c
+}
+
+type IQuotaProviderConfig interface {
+	GetBaseConfig() *QuotaProviderBaseConfig
+}
+
+type IQuotaProvider interface {
+	Initialize(ctx context.Context, config IQuotaProviderConfig) error
+	// Adds delta to the counter unless the result exceeds limit (0 - unlimited), the counter is removed at exp (unix ms).
+	// Returns the value of the counter and whether delta is added
+	Incr(ctx context.Context, key string, delta int64, limit int64, exp int64, now int64) (int64, bool, error)
+	// Returns values of the counters (0 - missing or expired counter)
+	Get(ctx context.Context, keys []string, now int64) ([]int64, error)
+	// Adds the member to the set
+	AddMember(ctx context.Context, key string, member string) error
+	// Returns members of the set in ascending order
+	Members(ctx context.Context, key string) ([]string, error)
+	Shutdown(ctx context.Context) error
+}
@@ -0,0 +1,150 @@
+package quota_redis_provider
+
+import (
+	"context"
+	"errors"
+	log "go-leaderboard-server/internal/logger"
+	quotaprovider "go-leaderboard-server/internal/quota"
+	"sort"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var logger = log.GetLogger()
+
+type RedisOptions redis.Options
+
+// Prefix of keys used when KeyPrefix isn't set
+const DEFAULT_KEY_PREFIX = "ldb:"
+
+type QuotaRedisProviderConfig struct {
+	quotaprovider.QuotaProviderBaseConfig
+	Opts      RedisOptions
+	KeyPrefix string // Prefix of all keys, separates leaderboard data from other data of the database (empty - DEFAULT_KEY_PREFIX)
+}
+
+// Adds delta to the counter unless the result exceeds the limit and sets its expiration time.
+// KEYS[1] - counter, ARGV[1] - delta, ARGV[2] - limit (0 - unlimited), ARGV[3] - expiration time (unix ms).
+// Returns the value of the counter and 1 if delta is added
+var incrScript = redis.NewScript(`
+local delta = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local value = tonumber(redis.call("GET", KEYS[1]) or "0")
+if limit > 0 and value + delta > limit then
+	return {value, 0}
+end
+value = redis.call("INCRBY", KEYS[1], delta)
+redis.call("PEXPIREAT", KEYS[1], ARGV[3])
+return {value, 1}
+`)
+
+// Keeps counters in Redis, so usage is shared by all server instances
+type QuotaRedisProvider struct {
+	rdb    *redis.Client
+	prefix string
+}
+
+func NewQuotaRedisProvider() *QuotaRedisProvider {
+	return &QuotaRedisProvider{}
+}
+
+func (p *QuotaRedisProvider) getQuotaKey(key string) string {
+	return p.prefix + "quota:" + key
+}
+
+func (p *QuotaRedisProvider) Initialize(ctx context.Context, config quotaprovider.IQuotaProviderConfig) error {
+	logger.Debug("Quota provider initialization")
+
+	conf, ok := config.(*QuotaRedisProviderConfig)
+	if !ok {
+		return errors.New("wrong config")
+	}
+
+	opts := redis.Options(conf.Opts)
+	p.rdb = redis.NewClient(&opts)
+	p.prefix = conf.KeyPrefix
+	if p.prefix == "" {
+		p.prefix = DEFAULT_KEY_PREFIX
+	}
+
+	return p.rdb.Ping(ctx).Err()
+}
+
+func (p *QuotaRedisProvider) Incr(ctx context.Context, key string, delta int64, limit int64, exp int64, now int64) (int64, bool, error) {
+	if p.rdb == nil {
+		return 0, false, errors.New("uninitialized")
+	}
+
+	res, err := incrScript.Run(ctx, p.rdb, []string{p.getQuotaKey(key)}, delta, limit, exp).Int64Slice()
+	if err != nil {
+		return 0, false, err
+	}
+
+	return res[0], res[1] == 1, nil
+}
+
+func (p *QuotaRedisProvider) Get(ctx context.Context, keys []string, now int64) ([]int64, error) {
+	if p.rdb == nil {
+		return nil, errors.New("uninitialized")
+	}
+
+	values := make([]int64, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	qkeys := make([]string, len(keys))
+	for i, key := range keys {
+		qkeys[i] = p.getQuotaKey(key)
+	}
+	res, err := p.rdb.MGet(ctx, qkeys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, v := range res {
+		s, ok := v.(string)
+		if !ok {
+			continue // missing counter
+		}
+		values[i], err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+func (p *QuotaRedisProvider) AddMember(ctx context.Context, key string, member string) error {
+	if p.rdb == nil {
+		return errors.New("uninitialized")
+	}
+
+	return p.rdb.SAdd(ctx, p.getQuotaKey(key), member).Err()
+}
+
+func (p *QuotaRedisProvider) Members(ctx context.Context, key string) ([]string, error) {
+	if p.rdb == nil {
+		return nil, errors.New("uninitialized")
+	}
+
+	members, err := p.rdb.SMembers(ctx, p.getQuotaKey(key)).Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(members)
+
+	return members, nil
+}
+
+func (p *QuotaRedisProvider) Shutdown(ctx context.Context) error {
+	logger.Debug("Quota provider shutdown")
+
+	if p.rdb == nil {
+		return nil
+	}
+
+	return p.rdb.Close()
+}
@@ -0,0 +1,113 @@
+package quota_redis_provider
+
+import (
+	"context"
+	quotaprovider "go-leaderboard-server/internal/quota"
+	"go-leaderboard-server/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var dbEndpoint string
+
+func prepareTest(t *testing.T) {
+	t.Log("prepare test env")
+
+	ctx := context.Background()
+	dbContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "redis:7.2.3",
+			ExposedPorts: []string{"6379"},
+			WaitingFor:   wait.ForExposedPort(),
+		},
+		Started: true,
+	})
+	require.NoError(t, err, "container should start successfully")
+
+	t.Cleanup(func() {
+		t.Log("terminate test env")
+
+		err := dbContainer.Terminate(ctx)
+		require.NoError(t, err, "container should be terminated successfully")
+	})
+
+	ep, err := dbContainer.Endpoint(ctx, "")
+	require.NoError(t, err, "container endpoint should be obtained successfully")
+
+	dbEndpoint = ep
+}
+
+func setupTest() (func() error, *QuotaRedisProvider, error) {
+	provider := NewQuotaRedisProvider()
+	err := provider.Initialize(context.Background(), &QuotaRedisProviderConfig{
+		QuotaProviderBaseConfig: quotaprovider.QuotaProviderBaseConfig{
+			IsDebug: true,
+		},
+		Opts: RedisOptions{
+			Addr: dbEndpoint,
+		},
+	})
+
+	return func() error {
+		return provider.Shutdown(context.Background())
+	}, provider, err
+}
+
+func runTest(t *testing.T, name string, testFunc utils.TestFcn[*QuotaRedisProvider]) {
+	utils.RunTest(t, name, setupTest, testFunc)
+}
+
+func TestQuotaRedisProvider(t *testing.T) {
+	prepareTest(t)
+
+	now := time.Now().UnixMilli()
+	exp := now + 60000
+
+	runTest(t, "increment counters", func(t *testing.T, provider *QuotaRedisProvider) {
+		ctx := context.Background()
+
+		for i := int64(1); i <= 2; i++ {
+			value, ok, err := provider.Incr(ctx, "key1", 1, 2, exp, now)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, i, value)
+		}
+		value, ok, err := provider.Incr(ctx, "key1", 1, 2, exp, now)
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Equal(t, int64(2), value)
+
+		value, ok, err = provider.Incr(ctx, "key1", -1, 0, exp, now)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, int64(1), value)
+
+		values, err := provider.Get(ctx, []string{"key1", "key2"}, now)
+		require.NoError(t, err)
+		require.Equal(t, []int64{1, 0}, values)
+
+		ttl, err := provider.rdb.PTTL(ctx, provider.getQuotaKey("key1")).Result()
+		require.NoError(t, err)
+		require.Positive(t, ttl)
+	})
+
+	runTest(t, "add and list members", func(t *testing.T, provider *QuotaRedisProvider) {
+		ctx := context.Background()
+
+		for _, member := range []string{"game2", "game1", "game2"} {
+			err := provider.AddMember(ctx, "set1", member)
+			require.NoError(t, err)
+		}
+
+		members, err := provider.Members(ctx, "set1")
+		require.NoError(t, err)
+		require.Equal(t, []string{"game1", "game2"}, members)
+		members, err = provider.Members(ctx, "set2")
+		require.NoError(t, err)
+		require.Empty(t, members)
+	})
+}
@@ -0,0 +1,95 @@
+package ratelimit_memory_provider
+
+import (
+	"context"
+	"errors"
+	log "go-leaderboard-server/internal/logger"
+	ratelimitprovider "go-leaderboard-server/internal/ratelimit"
+	"sync"
+)
+
+var logger = log.GetLogger()
+
+const sweepInterval = 60000 // ms
+
+type RateLimitMemoryProviderConfig struct {
+	ratelimitprovider.RateLimitProviderBaseConfig
+}
+
+type memoryBucket struct {
+	ratelimitprovider.Bucket
+	exp int64 // time the bucket becomes full (unix ms)
+}
+
+// Keeps buckets in process memory, so limits are counted per server instance
+type RateLimitMemoryProvider struct {
+	buckets   map[string]*memoryBucket
+	mutex     sync.Mutex
+	lastSweep int64
+}
+
+func NewRateLimitMemoryProvider() *RateLimitMemoryProvider {
+	return &RateLimitMemoryProvider{
+		buckets: make(map[string]*memoryBucket),
+	}
+}
+
+func (p *RateLimitMemoryProvider) Initialize(ctx context.Context, config ratelimitprovider.IRateLimitProviderConfig) error {
+	logger.Debug("Rate limit provider initialization")
+
+	_, ok := config.(*RateLimitMemoryProviderConfig)
+	if !ok {
+		return errors.New("wrong config")
+	}
+
+	return nil
+}
+
+func (p *RateLimitMemoryProvider) Take(ctx context.Context, key string, limit ratelimitprovider.Limit, now int64) (int64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// drop full buckets, they are the same as new ones
+	if now-p.lastSweep >= sweepInterval {
+		for k, b := range p.buckets {
+			if b.exp < now {
+				delete(p.buckets, k)
+			}
+		}
+		p.lastSweep = now
+	}
+
+	b, ok := p.buckets[key]
+	if !ok {
+		b = &memoryBucket{}
+		p.buckets[key] = b
+	}
+
+	retryAfter := b.Take(limit, now)
+	b.exp = b.Ts + b.FullIn(limit)
+
+	return retryAfter, nil
+}
+
+func (p *RateLimitMemoryProvider) Refund(ctx context.Context, key string, limit ratelimitprovider.Limit) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	b, ok := p.buckets[key]
+	if !ok {
+		return nil // the bucket is full already
+	}
+
+	b.Refund(limit)
+	b.exp = b.Ts + b.FullIn(limit)
+
+	return nil
+}
+
+func (p *RateLimitMemoryProvider) Shutdown(ctx context.Context) error {
+	logger.Debug("Rate limit provider shutdown")
+
+	/* do nothing */
+
+	return nil
+}
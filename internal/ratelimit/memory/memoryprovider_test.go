@@ -0,0 +1,103 @@
+package ratelimit_memory_provider
+
+import (
+	"context"
+	ratelimitprovider "go-leaderboard-server/internal/ratelimit"
+	"go-leaderboard-server/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupTest() (func() error, *RateLimitMemoryProvider, error) {
+	provider := NewRateLimitMemoryProvider()
+	err := provider.Initialize(context.Background(), &RateLimitMemoryProviderConfig{
+		RateLimitProviderBaseConfig: ratelimitprovider.RateLimitProviderBaseConfig{
+			IsDebug: true,
+		},
+	})
+
+	return func() error {
+		return provider.Shutdown(context.Background())
+	}, provider, err
+}
+
+func runTest(t *testing.T, name string, testFunc utils.TestFcn[*RateLimitMemoryProvider]) {
+	utils.RunTest(t, name, setupTest, testFunc)
+}
+
+func TestRateLimitMemoryProvider(t *testing.T) {
+	limit := ratelimitprovider.Limit{Burst: 2, Rate: 1}
+	now := int64(1000000)
+
+	runTest(t, "take tokens", func(t *testing.T, provider *RateLimitMemoryProvider) {
+		ctx := context.Background()
+
+		for i := 0; i < 2; i++ {
+			retryAfter, err := provider.Take(ctx, "key1", limit, now)
+			require.NoError(t, err)
+			require.Zero(t, retryAfter)
+		}
+		retryAfter, err := provider.Take(ctx, "key1", limit, now)
+		require.NoError(t, err)
+		require.Equal(t, int64(1000), retryAfter)
+
+		retryAfter, err = provider.Take(ctx, "key2", limit, now)
+		require.NoError(t, err)
+		require.Zero(t, retryAfter)
+
+		retryAfter, err = provider.Take(ctx, "key1", limit, now+500)
+		require.NoError(t, err)
+		require.Equal(t, int64(500), retryAfter)
+		retryAfter, err = provider.Take(ctx, "key1", limit, now+1000)
+		require.NoError(t, err)
+		require.Zero(t, retryAfter)
+	})
+
+	runTest(t, "forget full buckets", func(t *testing.T, provider *RateLimitMemoryProvider) {
+		ctx := context.Background()
+
+		_, err := provider.Take(ctx, "key1", limit, now)
+		require.NoError(t, err)
+		_, err = provider.Take(ctx, "key2", limit, now+sweepInterval-1)
+		require.NoError(t, err)
+		require.Len(t, provider.buckets, 2)
+
+		_, err = provider.Take(ctx, "key2", limit, now+sweepInterval)
+		require.NoError(t, err)
+		require.Len(t, provider.buckets, 1)
+	})
+
+	runTest(t, "refund tokens", func(t *testing.T, provider *RateLimitMemoryProvider) {
+		ctx := context.Background()
+
+		require.NoError(t, provider.Refund(ctx, "key3", limit))
+		for i := 0; i < 2; i++ {
+			retryAfter, err := provider.Take(ctx, "key3", limit, now)
+			require.NoError(t, err)
+			require.Zero(t, retryAfter)
+		}
+		require.NoError(t, provider.Refund(ctx, "key3", limit))
+		retryAfter, err := provider.Take(ctx, "key3", limit, now)
+		require.NoError(t, err)
+		require.Zero(t, retryAfter)
+		retryAfter, err = provider.Take(ctx, "key3", limit, now)
+		require.NoError(t, err)
+		require.Equal(t, int64(1000), retryAfter)
+
+		// the bucket is never refilled over the burst
+		retryAfter, err = provider.Take(ctx, "key3", limit, now+2000)
+		require.NoError(t, err)
+		require.Zero(t, retryAfter)
+		require.NoError(t, provider.Refund(ctx, "key3", limit))
+		require.NoError(t, provider.Refund(ctx, "key3", limit))
+		for i := 0; i < 2; i++ {
+			retryAfter, err = provider.Take(ctx, "key3", limit, now+2000)
+			require.NoError(t, err)
+			require.Zero(t, retryAfter)
+		}
+		retryAfter, err = provider.Take(ctx, "key3", limit, now+2000)
+		require.NoError(t, err)
+		require.Equal(t, int64(1000), retryAfter)
+	})
+}
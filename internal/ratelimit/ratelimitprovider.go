@@ -0,0 +1,69 @@
+package ratelimitprovider
+
+import (
+	"context"
+	"math"
+)
+
+type RateLimitProviderBaseConfig struct {
+	IsDebug bool // Debug flag
+}
+
+func (c *RateLimitProviderBaseConfig) GetBaseConfig() *RateLimitProviderBaseConfig {
+	return c
+}
+
+type IRateLimitProviderConfig interface {
+	GetBaseConfig() *RateLimitProviderBaseConfig
+}
+
+// Token bucket: up to Burst requests at once, refilled by Rate requests per second
+type Limit struct {
+	Burst uint32
+	Rate  float64
+}
+
+// State of a token bucket
+type Bucket struct {
+	Tokens float64 // Tokens left
+	Ts     int64   // Time of the last update (unix ms)
+}
+
+// Refills the bucket up to the current time and takes a token.
+// Returns the time until the next token (ms) if the bucket is empty (0 - the token is taken)
+func (b *Bucket) Take(limit Limit, now int64) int64 {
+	if b.Ts == 0 {
+		b.Tokens = float64(limit.Burst)
+	} else if now > b.Ts {
+		b.Tokens = math.Min(float64(limit.Burst), b.Tokens+float64(now-b.Ts)*limit.Rate/1000)
+	}
+	if now > b.Ts {
+		b.Ts = now
+	}
+
+	if b.Tokens >= 1 {
+		b.Tokens--
+		return 0
+	}
+
+	return int64(math.Ceil((1 - b.Tokens) * 1000 / limit.Rate))
+}
+
+// Returns a taken token to the bucket
+func (b *Bucket) Refund(limit Limit) {
+	b.Tokens = math.Min(float64(limit.Burst), b.Tokens+1)
+}
+
+// Returns the time the bucket needs to become full (ms), it can be forgotten after that
+func (b *Bucket) FullIn(limit Limit) int64 {
+	return int64(math.Ceil((float64(limit.Burst) - b.Tokens) * 1000 / limit.Rate))
+}
+
+type IRateLimitProvider interface {
+	Initialize(ctx context.Context, config IRateLimitProviderConfig) error
+	// Takes a token from the bucket of the key, returns the time until the next token (ms) if the bucket is empty (0 - the token is taken)
+	Take(ctx context.Context, key string, limit Limit, now int64) (int64, error)
+	// Returns a token taken from the bucket of the key (the request was rejected by another bucket)
+	Refund(ctx context.Context, key string, limit Limit) error
+	Shutdown(ctx context.Context) error
+}
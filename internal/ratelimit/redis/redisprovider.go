@@ -0,0 +1,125 @@
+package ratelimit_redis_provider
+
+import (
+	"context"
+	"errors"
+	log "go-leaderboard-server/internal/logger"
+	ratelimitprovider "go-leaderboard-server/internal/ratelimit"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var logger = log.GetLogger()
+
+type RedisOptions redis.Options
+
+// Prefix of keys used when KeyPrefix isn't set
+const DEFAULT_KEY_PREFIX = "ldb:"
+
+type RateLimitRedisProviderConfig struct {
+	ratelimitprovider.RateLimitProviderBaseConfig
+	Opts      RedisOptions
+	KeyPrefix string // Prefix of all keys, separates leaderboard data from other data of the database (empty - DEFAULT_KEY_PREFIX)
+}
+
+// Refills the bucket (hash with "tk" - tokens and "ts" - time of the last update) and takes a token.
+// The bucket expires when it becomes full.
+// KEYS[1] - bucket, ARGV[1] - burst, ARGV[2] - rate (per second), ARGV[3] - now (unix ms).
+// Returns the time until the next token (ms), 0 - the token is taken
+var takeScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local vals = redis.call("HMGET", KEYS[1], "tk", "ts")
+local tokens = tonumber(vals[1])
+local ts = tonumber(vals[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+elseif now > ts then
+	tokens = math.min(burst, tokens + (now - ts) * rate / 1000)
+	ts = now
+end
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+else
+	wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+redis.call("HSET", KEYS[1], "tk", tostring(tokens), "ts", ts)
+redis.call("PEXPIRE", KEYS[1], math.max(1, math.ceil((burst - tokens) * 1000 / rate)))
+return wait
+`)
+
+// Returns a taken token to the bucket, a missing bucket is full already.
+// KEYS[1] - bucket, ARGV[1] - burst, ARGV[2] - rate (per second)
+var refundScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local tokens = tonumber(redis.call("HGET", KEYS[1], "tk"))
+if tokens == nil then
+	return 0
+end
+tokens = math.min(burst, tokens + 1)
+redis.call("HSET", KEYS[1], "tk", tostring(tokens))
+redis.call("PEXPIRE", KEYS[1], math.max(1, math.ceil((burst - tokens) * 1000 / rate)))
+return 1
+`)
+
+// Keeps buckets in Redis, so limits are shared by all server instances
+type RateLimitRedisProvider struct {
+	rdb    *redis.Client
+	prefix string
+}
+
+func NewRateLimitRedisProvider() *RateLimitRedisProvider {
+	return &RateLimitRedisProvider{}
+}
+
+func (p *RateLimitRedisProvider) getBucketKey(key string) string {
+	return p.prefix + "ratelimit:" + key
+}
+
+func (p *RateLimitRedisProvider) Initialize(ctx context.Context, config ratelimitprovider.IRateLimitProviderConfig) error {
+	logger.Debug("Rate limit provider initialization")
+
+	conf, ok := config.(*RateLimitRedisProviderConfig)
+	if !ok {
+		return errors.New("wrong config")
+	}
+
+	opts := redis.Options(conf.Opts)
+	p.rdb = redis.NewClient(&opts)
+	p.prefix = conf.KeyPrefix
+	if p.prefix == "" {
+		p.prefix = DEFAULT_KEY_PREFIX
+	}
+
+	return p.rdb.Ping(ctx).Err()
+}
+
+func (p *RateLimitRedisProvider) Take(ctx context.Context, key string, limit ratelimitprovider.Limit, now int64) (int64, error) {
+	if p.rdb == nil {
+		return 0, errors.New("uninitialized")
+	}
+
+	return takeScript.Run(ctx, p.rdb, []string{p.getBucketKey(key)}, limit.Burst, limit.Rate, now).Int64()
+}
+
+func (p *RateLimitRedisProvider) Refund(ctx context.Context, key string, limit ratelimitprovider.Limit) error {
+	if p.rdb == nil {
+		return errors.New("uninitialized")
+	}
+
+	return refundScript.Run(ctx, p.rdb, []string{p.getBucketKey(key)}, limit.Burst, limit.Rate).Err()
+}
+
+func (p *RateLimitRedisProvider) Shutdown(ctx context.Context) error {
+	logger.Debug("Rate limit provider shutdown")
+
+	if p.rdb == nil {
+		return nil
+	}
+
+	return p.rdb.Close()
+}
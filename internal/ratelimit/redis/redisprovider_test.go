@@ -0,0 +1,129 @@
+package ratelimit_redis_provider
+
+import (
+	"context"
+	ratelimitprovider "go-leaderboard-server/internal/ratelimit"
+	"go-leaderboard-server/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var dbEndpoint string
+
+func prepareTest(t *testing.T) {
+	t.Log("prepare test env")
+
+	ctx := context.Background()
+	dbContainer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "redis:7.2.3",
+			ExposedPorts: []string{"6379"},
+			WaitingFor:   wait.ForExposedPort(),
+		},
+		Started: true,
+	})
+	require.NoError(t, err, "container should start successfully")
+
+	t.Cleanup(func() {
+		t.Log("terminate test env")
+
+		err := dbContainer.Terminate(ctx)
+		require.NoError(t, err, "container should be terminated successfully")
+	})
+
+	ep, err := dbContainer.Endpoint(ctx, "")
+	require.NoError(t, err, "container endpoint should be obtained successfully")
+
+	dbEndpoint = ep
+}
+
+func setupTest() (func() error, *RateLimitRedisProvider, error) {
+	provider := NewRateLimitRedisProvider()
+	err := provider.Initialize(context.Background(), &RateLimitRedisProviderConfig{
+		RateLimitProviderBaseConfig: ratelimitprovider.RateLimitProviderBaseConfig{
+			IsDebug: true,
+		},
+		Opts: RedisOptions{
+			Addr: dbEndpoint,
+		},
+	})
+
+	return func() error {
+		return provider.Shutdown(context.Background())
+	}, provider, err
+}
+
+func runTest(t *testing.T, name string, testFunc utils.TestFcn[*RateLimitRedisProvider]) {
+	utils.RunTest(t, name, setupTest, testFunc)
+}
+
+func TestRateLimitRedisProvider(t *testing.T) {
+	prepareTest(t)
+
+	limit := ratelimitprovider.Limit{Burst: 2, Rate: 1}
+	now := int64(1000000)
+
+	runTest(t, "take tokens", func(t *testing.T, provider *RateLimitRedisProvider) {
+		ctx := context.Background()
+
+		for i := 0; i < 2; i++ {
+			retryAfter, err := provider.Take(ctx, "key1", limit, now)
+			require.NoError(t, err)
+			require.Zero(t, retryAfter)
+		}
+		retryAfter, err := provider.Take(ctx, "key1", limit, now)
+		require.NoError(t, err)
+		require.Equal(t, int64(1000), retryAfter)
+
+		retryAfter, err = provider.Take(ctx, "key2", limit, now)
+		require.NoError(t, err)
+		require.Zero(t, retryAfter)
+
+		retryAfter, err = provider.Take(ctx, "key1", limit, now+500)
+		require.NoError(t, err)
+		require.Equal(t, int64(500), retryAfter)
+		retryAfter, err = provider.Take(ctx, "key1", limit, now+1000)
+		require.NoError(t, err)
+		require.Zero(t, retryAfter)
+
+		ttl, err := provider.rdb.PTTL(ctx, provider.getBucketKey("key1")).Result()
+		require.NoError(t, err)
+		require.Positive(t, ttl)
+	})
+
+	runTest(t, "refund tokens", func(t *testing.T, provider *RateLimitRedisProvider) {
+		ctx := context.Background()
+
+		require.NoError(t, provider.Refund(ctx, "key3", limit))
+		for i := 0; i < 2; i++ {
+			retryAfter, err := provider.Take(ctx, "key3", limit, now)
+			require.NoError(t, err)
+			require.Zero(t, retryAfter)
+		}
+		require.NoError(t, provider.Refund(ctx, "key3", limit))
+		retryAfter, err := provider.Take(ctx, "key3", limit, now)
+		require.NoError(t, err)
+		require.Zero(t, retryAfter)
+		retryAfter, err = provider.Take(ctx, "key3", limit, now)
+		require.NoError(t, err)
+		require.Equal(t, int64(1000), retryAfter)
+
+		// the bucket is never refilled over the burst
+		retryAfter, err = provider.Take(ctx, "key3", limit, now+2000)
+		require.NoError(t, err)
+		require.Zero(t, retryAfter)
+		require.NoError(t, provider.Refund(ctx, "key3", limit))
+		require.NoError(t, provider.Refund(ctx, "key3", limit))
+		for i := 0; i < 2; i++ {
+			retryAfter, err = provider.Take(ctx, "key3", limit, now+2000)
+			require.NoError(t, err)
+			require.Zero(t, retryAfter)
+		}
+		retryAfter, err = provider.Take(ctx, "key3", limit, now+2000)
+		require.NoError(t, err)
+		require.Equal(t, int64(1000), retryAfter)
+	})
+}
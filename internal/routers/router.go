@@ -2,6 +2,7 @@ package routers
 
 import (
 	ac "go-leaderboard-server/internal/appcontext"
+	"go-leaderboard-server/internal/config"
 	"go-leaderboard-server/internal/controllers"
 	log "go-leaderboard-server/internal/logger"
 	"go-leaderboard-server/internal/middleware"
@@ -32,17 +33,81 @@ func SetupRouter(appContext *ac.AppContext) *gin.Engine {
 
 	router := gin.New()
 
+	// the client IP is taken from the headers of trusted proxies only, it identifies clients for rate limits
+	err := router.SetTrustedProxies(appContext.AppConfig.Http.TrustedProxies)
+	if err != nil {
+		logger.Error("Wrong trusted proxies", log.LogParams{"error": err})
+	}
+
+	if appContext.AppConfig.Http.Compression != nil {
+		router.Use(middleware.CompressionMiddleware(appContext.AppConfig.Http.Compression))
+	}
+	router.Use(middleware.ResponseEncodingMiddleware())
 	router.Use(gin.CustomRecovery(errorHandler))
 	router.Use(middleware.AppContextMiddleware(appContext))
+	router.Use(middleware.RequestIdMiddleware())
 	router.Use(middleware.ErrorHandlerMiddleware(appContext.AppConfig.IsDebug))
+	router.Use(middleware.BodyLimitMiddleware(appContext.AppConfig.Http))
+	router.Use(middleware.RequestDecodingMiddleware())
 
 	router.GET("/Status", controllers.StatusHandler)
+	// users are limited once the requests are authenticated
+	ldbrdUserLimit := middleware.UserRateLimitMiddleware(config.ROUTEGROUP_LEADERBOARD)
+	adminUserLimit := middleware.UserRateLimitMiddleware(config.ROUTEGROUP_ADMIN)
 	ldbrdGr := router.Group("/leaderboard")
+	ldbrdGr.Use(middleware.RateLimitMiddleware(config.ROUTEGROUP_LEADERBOARD))
+	{
+		ldbrdGr.POST("/SendScore", middleware.AuthMiddleware(config.ROLE_CLIENT), middleware.JwtMiddleware(),
+			ldbrdUserLimit, middleware.SignatureMiddleware(), middleware.IdempotencyMiddleware(), controllers.SendScoreHandler)
+		ldbrdGr.POST("/DeleteScore", middleware.AuthMiddleware(config.ROLE_ADMIN), middleware.JwtMiddleware(),
+			ldbrdUserLimit, middleware.IdempotencyMiddleware(), controllers.DeleteScoreHandler)
+		ldbrdGr.POST("/GetScore", middleware.AuthMiddleware(config.ROLE_CLIENT), middleware.JwtMiddleware(),
+			ldbrdUserLimit, controllers.GetScoreHandler)
+		ldbrdGr.POST("/GetTop", middleware.AuthMiddleware(config.ROLE_CLIENT), ldbrdUserLimit, controllers.GetTopHandler)
+		ldbrdGr.POST("/GetChanges", middleware.AuthMiddleware(config.ROLE_SERVER), ldbrdUserLimit, controllers.GetChangesHandler)
+	}
+	adminGr := router.Group("/admin")
+	adminGr.Use(middleware.RateLimitMiddleware(config.ROUTEGROUP_ADMIN), middleware.AuthMiddleware(config.ROLE_ADMIN), adminUserLimit)
+	{
+		adminGr.POST("/SetUserState", controllers.SetUserStateHandler)
+		adminGr.POST("/GetUserState", controllers.GetUserStateHandler)
+		adminGr.POST("/GetQuarantine", controllers.GetQuarantineHandler)
+		adminGr.POST("/ApproveQuarantined", controllers.ApproveQuarantinedHandler)
+		adminGr.POST("/RejectQuarantined", controllers.RejectQuarantinedHandler)
+		adminGr.POST("/GetAudit", controllers.GetAuditHandler)
+		adminGr.POST("/GetUsage", controllers.GetUsageHandler)
+		adminGr.POST("/GetDeadLetters", controllers.GetDeadLettersHandler)
+		adminGr.POST("/ReplayDeadLetters", controllers.ReplayDeadLettersHandler)
+	}
+	// resource-oriented routes, same handling as the routes above
+	ldbrdV2Gr := router.Group("/v2/games/:gameId/users/:userId")
+	ldbrdV2Gr.Use(middleware.RateLimitMiddleware(config.ROUTEGROUP_LEADERBOARD))
+	{
+		ldbrdV2Gr.PUT("", middleware.AuthMiddleware(config.ROLE_CLIENT), middleware.JwtMiddleware(),
+			ldbrdUserLimit, middleware.SignatureMiddleware(), middleware.IdempotencyMiddleware(), controllers.SendScoreV2Handler)
+		ldbrdV2Gr.DELETE("", middleware.AuthMiddleware(config.ROLE_ADMIN), middleware.JwtMiddleware(),
+			ldbrdUserLimit, middleware.IdempotencyMiddleware(), controllers.DeleteScoreV2Handler)
+		ldbrdV2Gr.GET("", middleware.AuthMiddleware(config.ROLE_CLIENT), middleware.JwtMiddleware(),
+			ldbrdUserLimit, controllers.GetScoreV2Handler)
+	}
+	router.GET("/v2/games/:gameId/top", middleware.RateLimitMiddleware(config.ROUTEGROUP_LEADERBOARD),
+		middleware.AuthMiddleware(config.ROLE_CLIENT), ldbrdUserLimit, controllers.GetTopV2Handler)
+	router.GET("/v2/games/:gameId/top/subscribe", middleware.RateLimitMiddleware(config.ROUTEGROUP_LEADERBOARD),
+		middleware.AuthMiddleware(config.ROLE_CLIENT), ldbrdUserLimit, controllers.SubscribeTopV2Handler)
+	router.GET("/v2/games/:gameId/changes", middleware.RateLimitMiddleware(config.ROUTEGROUP_LEADERBOARD),
+		middleware.AuthMiddleware(config.ROLE_SERVER), ldbrdUserLimit, controllers.GetChangesV2Handler)
+	adminV2Gr := router.Group("/v2")
+	adminV2Gr.Use(middleware.RateLimitMiddleware(config.ROUTEGROUP_ADMIN), middleware.AuthMiddleware(config.ROLE_ADMIN), adminUserLimit)
 	{
-		ldbrdGr.POST("/SendScore", controllers.SendScoreHandler)
-		ldbrdGr.POST("/DeleteScore", controllers.DeleteScoreHandler)
-		ldbrdGr.POST("/GetScore", controllers.GetScoreHandler)
-		ldbrdGr.POST("/GetTop", controllers.GetTopHandler)
+		adminV2Gr.PUT("/games/:gameId/users/:userId/state", controllers.SetUserStateV2Handler)
+		adminV2Gr.GET("/games/:gameId/users/:userId/state", controllers.GetUserStateV2Handler)
+		adminV2Gr.GET("/games/:gameId/quarantine", controllers.GetQuarantineV2Handler)
+		adminV2Gr.POST("/games/:gameId/quarantine/:id/approve", controllers.ApproveQuarantinedV2Handler)
+		adminV2Gr.DELETE("/games/:gameId/quarantine/:id", controllers.RejectQuarantinedV2Handler)
+		adminV2Gr.GET("/audit", controllers.GetAuditV2Handler)
+		adminV2Gr.GET("/usage", controllers.GetUsageV2Handler)
+		adminV2Gr.GET("/webhooks/:endpoint/deadletters", controllers.GetDeadLettersV2Handler)
+		adminV2Gr.POST("/webhooks/:endpoint/deadletters/replay", controllers.ReplayDeadLettersV2Handler)
 	}
 
 	if appContext.AppConfig.ApiUI {
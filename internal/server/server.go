@@ -5,6 +5,7 @@ import (
 	"errors"
 	ac "go-leaderboard-server/internal/appcontext"
 	"go-leaderboard-server/internal/config"
+	"go-leaderboard-server/internal/grpcapi"
 	log "go-leaderboard-server/internal/logger"
 	"go-leaderboard-server/internal/routers"
 	"go-leaderboard-server/internal/services"
@@ -16,6 +17,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 )
 
 var logger = log.GetLogger()
@@ -25,6 +27,7 @@ type AppServer struct {
 	appContext *ac.AppContext
 	router     *gin.Engine
 	server     *http.Server
+	grpcServer *grpc.Server // nil - gRPC API is disabled
 	err        error
 }
 
@@ -55,6 +58,9 @@ func (s *AppServer) Initialize(config *config.Config) error {
 	}
 
 	s.router = routers.SetupRouter(s.appContext)
+	if config.Grpc != nil {
+		s.grpcServer = grpcapi.NewGrpcServer(s.appContext)
+	}
 
 	return nil
 }
@@ -80,6 +86,26 @@ func (s *AppServer) Start() {
 		stop()
 	}()
 
+	if s.grpcServer != nil {
+		grpcPort := s.appContext.AppConfig.Grpc.Port
+		listener, err := net.Listen("tcp", net.JoinHostPort(host, grpcPort))
+		if err != nil {
+			logger.Error("Failed to start gRPC server", log.LogParams{"error": err})
+			s.err = err
+			stop()
+		} else {
+			go func() {
+				logger.Info("gRPC server started", log.LogParams{"host": host, "port": grpcPort})
+				err := s.grpcServer.Serve(listener)
+				if err != nil {
+					logger.Error("Failed to start gRPC server", log.LogParams{"error": err})
+					s.err = errors.Join(s.err, err)
+				}
+				stop()
+			}()
+		}
+	}
+
 	<-ctx.Done()
 	stop()
 }
@@ -87,6 +113,11 @@ func (s *AppServer) Start() {
 func (s *AppServer) Shutdown() error {
 	logger.Info("Server shutting down...")
 
+	if s.appContext != nil && s.appContext.SubscriptionService != nil {
+		// subscription streams don't end by themselves, so they would hold the graceful shutdown
+		s.appContext.SubscriptionService.Close()
+	}
+
 	if s.server != nil {
 		ctx, cancel := utils.GetContextByTimeout(
 			context.Background(),
@@ -100,6 +131,20 @@ func (s *AppServer) Shutdown() error {
 		}
 	}
 
+	if s.grpcServer != nil {
+		// calls still running after the timeout of the graceful stop are cancelled
+		done := make(chan struct{})
+		go func() {
+			s.grpcServer.GracefulStop()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Duration(s.appContext.AppConfig.TimeoutServerClose) * time.Millisecond):
+			s.grpcServer.Stop()
+		}
+	}
+
 	if s.appContext != nil {
 		err := services.ShutdownServices(context.Background(), s.appContext.AppConfig, &s.appContext.Services)
 		if err != nil {
@@ -1,21 +1,53 @@
 package server
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	audit_db_sink "go-leaderboard-server/internal/audit/db"
 	"go-leaderboard-server/internal/config"
 	"go-leaderboard-server/internal/controllers"
+	dtopb "go-leaderboard-server/internal/controllers/pb"
 	dbprovider "go-leaderboard-server/internal/db"
+	deadletter_memory_provider "go-leaderboard-server/internal/deadletter/memory"
+	"go-leaderboard-server/internal/grpcapi"
+	leaderboardpb "go-leaderboard-server/internal/grpcapi/pb"
+	idempotency_memory_provider "go-leaderboard-server/internal/idempotency/memory"
 	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/middleware"
+	quota_memory_provider "go-leaderboard-server/internal/quota/memory"
+	ratelimitprovider "go-leaderboard-server/internal/ratelimit"
+	ratelimit_memory_provider "go-leaderboard-server/internal/ratelimit/memory"
+	"go-leaderboard-server/internal/services"
 	"go-leaderboard-server/internal/utils"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/andybalholm/brotli"
 	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestServer(t *testing.T) {
@@ -50,6 +82,19 @@ func TestServer(t *testing.T) {
 		return w
 	}
 
+	signedApiCall := func(server *AppServer, path string, body string, secret string, nonce string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		ts := strconv.FormatInt(server.clock.Now().UnixMilli(), 10)
+		sig, _ := services.Sign(secret, ts, nonce, []byte(body))
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.HEADER_SIGNATURE_TIMESTAMP, ts)
+		req.Header.Set(middleware.HEADER_SIGNATURE_NONCE, nonce)
+		req.Header.Set(middleware.HEADER_SIGNATURE, sig)
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
 	gameId := "game1"
 
 	user1 := dbprovider.UserData{
@@ -185,6 +230,58 @@ func TestServer(t *testing.T) {
 		}
 	})
 
+	runTest("hide and ban user => success", func(t *testing.T, server *AppServer) {
+		var w *httptest.ResponseRecorder
+
+		for _, user := range []dbprovider.UserData{user1, user2} {
+			w = apiCall(server, "POST", "/leaderboard/SendScore",
+				fmt.Sprintf(`{ "gameId": "%s", "userId": "%s", "name": "%s", "score": %f, "params": "%s" }`,
+					gameId, user.UserId, user.Name, user.Score, user.Params),
+			)
+			require.Equal(t, http.StatusOK, w.Code)
+		}
+
+		jtop, _ := json.Marshal(dbprovider.TopData{user2, user1})
+		w = apiCall(server, "POST", "/leaderboard/GetTop", fmt.Sprintf(`{ "gameId": "%s", "nTop": 10 }`, gameId))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, fmt.Sprintf(`{"result": %s}`, string(jtop)), w.Body.String())
+
+		w = apiCall(server, "POST", "/admin/SetUserState",
+			fmt.Sprintf(`{ "gameId": "%s", "userId": "%s", "state": "shadowbanned" }`, gameId, user2.UserId),
+		)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result": "success"}`, w.Body.String())
+
+		jtop, _ = json.Marshal(dbprovider.TopData{user1})
+		w = apiCall(server, "POST", "/leaderboard/GetTop", fmt.Sprintf(`{ "gameId": "%s", "nTop": 10 }`, gameId))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, fmt.Sprintf(`{"result": %s}`, string(jtop)), w.Body.String())
+
+		w = apiCall(server, "POST", "/leaderboard/GetScore", fmt.Sprintf(`{ "gameId": "%s", "userId": "%s" }`, gameId, user2.UserId))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, fmt.Sprintf(`{"result": { "name": "%s", "score": %f, "params": "%s" } }`,
+			user2.Name, user2.Score, user2.Params), w.Body.String())
+
+		w = apiCall(server, "POST", "/admin/SetUserState",
+			fmt.Sprintf(`{ "gameId": "%s", "userId": "%s", "state": "banned" }`, gameId, user2.UserId),
+		)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = apiCall(server, "POST", "/admin/GetUserState", fmt.Sprintf(`{ "gameId": "%s", "userId": "%s" }`, gameId, user2.UserId))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result": { "state": "banned" } }`, w.Body.String())
+
+		w = apiCall(server, "POST", "/leaderboard/SendScore",
+			fmt.Sprintf(`{ "gameId": "%s", "userId": "%s", "score": %f }`, gameId, user2.UserId, user2.Score),
+		)
+		require.Equal(t, http.StatusForbidden, w.Code)
+
+		w = apiCall(server, "POST", "/admin/SetUserState",
+			fmt.Sprintf(`{ "gameId": "%s", "userId": "%s", "state": "unknown" }`, gameId, user2.UserId),
+		)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
 	runTest("not found => error 404", func(t *testing.T, server *AppServer) {
 		w := apiCall(server, "POST", "/fake_path", "")
 		require.Equal(t, http.StatusNotFound, w.Code)
@@ -206,4 +303,1922 @@ func TestServer(t *testing.T) {
 		require.Equal(t, http.StatusInternalServerError, w.Code)
 		require.JSONEq(t, `{"error": "Internal server error"}`, w.Body.String())
 	})
+
+	runTest("send signed score => success", func(t *testing.T, server *AppServer) {
+		var w *httptest.ResponseRecorder
+
+		body := fmt.Sprintf(`{ "gameId": "signedgame", "userId": "%s", "score": %f }`, user1.UserId, user1.Score)
+
+		w = apiCall(server, "POST", "/leaderboard/SendScore", body)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.JSONEq(t, `{"error": "signature is missing"}`, w.Body.String())
+
+		// bodies with trailing data are bound by the handler, but can't be checked
+		for _, trailing := range []string{" x", "{}", ` { "gameId": "game1" }`} {
+			w = apiCall(server, "POST", "/leaderboard/SendScore", body+trailing)
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			require.JSONEq(t, `{"error": "request body is not a JSON object"}`, w.Body.String())
+		}
+
+		w = signedApiCall(server, "/leaderboard/SendScore", body, "wrong-secret-0123456789", "nonce1")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = signedApiCall(server, "/leaderboard/SendScore", body, "test-secret-0123456789", "nonce1")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result": "success"}`, w.Body.String())
+
+		w = signedApiCall(server, "/leaderboard/SendScore", body, "test-secret-0123456789", "nonce1")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.JSONEq(t, `{"error": "signature nonce has already been used"}`, w.Body.String())
+
+		w = apiCall(server, "POST", "/leaderboard/GetScore", fmt.Sprintf(`{ "gameId": "signedgame", "userId": "%s" }`, user1.UserId))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, fmt.Sprintf(`{"result": { "score": %f } }`, user1.Score), w.Body.String())
+	})
+}
+
+func TestServerAuth(t *testing.T) {
+	conf := *config.GetAppConfig()
+	conf.Auth = &config.AuthConfig{
+		Keys: []config.ApiKeyConfig{
+			{Key: "client-key-0123456789", Role: config.ROLE_CLIENT, UserId: "user1", Games: []string{"game1"}},
+			{Key: "server-key-0123456789", Role: config.ROLE_SERVER, Games: []string{"*"}},
+			{Key: "admin-key-0123456789", Role: config.ROLE_ADMIN, Games: []string{"game1"}},
+		},
+	}
+
+	setupTest := func() (func() error, *AppServer, error) {
+		server := NewAppServer(nil)
+		err := server.Initialize(&conf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*AppServer]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	apiCall := func(server *AppServer, method string, path string, body string, apiKey string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		if apiKey != "" {
+			req.Header.Set(middleware.HEADER_API_KEY, apiKey)
+		}
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	runTest("check api key scopes", func(t *testing.T, server *AppServer) {
+		sendScore := func(gameId string, userId string, apiKey string) int {
+			return apiCall(server, "POST", "/leaderboard/SendScore",
+				fmt.Sprintf(`{ "gameId": "%s", "userId": "%s", "score": 10 }`, gameId, userId), apiKey).Code
+		}
+
+		require.Equal(t, http.StatusOK, apiCall(server, "GET", "/Status", "", "").Code)
+
+		require.Equal(t, http.StatusUnauthorized, sendScore("game1", "user1", ""))
+		require.Equal(t, http.StatusUnauthorized, sendScore("game1", "user1", "unknown-key-0123456789"))
+
+		require.Equal(t, http.StatusOK, sendScore("game1", "user1", "client-key-0123456789"))
+		require.Equal(t, http.StatusForbidden, sendScore("game1", "user2", "client-key-0123456789"))
+		require.Equal(t, http.StatusForbidden, sendScore("game2", "user1", "client-key-0123456789"))
+		require.Equal(t, http.StatusOK, sendScore("game2", "user2", "server-key-0123456789"))
+		require.Equal(t, http.StatusOK, sendScore("game1", "user2", "admin-key-0123456789"))
+		require.Equal(t, http.StatusForbidden, sendScore("game2", "user2", "admin-key-0123456789"))
+
+		w := apiCall(server, "POST", "/leaderboard/GetTop", `{ "gameId": "game1", "nTop": 10 }`, "client-key-0123456789")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = apiCall(server, "POST", "/leaderboard/DeleteScore", `{ "gameId": "game1", "userId": "user1" }`, "server-key-0123456789")
+		require.Equal(t, http.StatusForbidden, w.Code)
+		w = apiCall(server, "POST", "/leaderboard/DeleteScore", `{ "gameId": "game1", "userId": "user1" }`, "admin-key-0123456789")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = apiCall(server, "POST", "/admin/GetUserState", `{ "gameId": "game1", "userId": "user1" }`, "server-key-0123456789")
+		require.Equal(t, http.StatusForbidden, w.Code)
+		w = apiCall(server, "POST", "/admin/GetUserState", `{ "gameId": "game1", "userId": "user1" }`, "admin-key-0123456789")
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	runTest("hide scores of hidden users from other callers", func(t *testing.T, server *AppServer) {
+		w := apiCall(server, "POST", "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user1", "score": 10 }`, "client-key-0123456789")
+		require.Equal(t, http.StatusOK, w.Code)
+		w = apiCall(server, "POST", "/admin/SetUserState", `{ "gameId": "game1", "userId": "user1", "state": "shadowbanned" }`, "admin-key-0123456789")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		for _, apiKey := range []string{"client-key-0123456789", "admin-key-0123456789"} {
+			w = apiCall(server, "POST", "/leaderboard/GetScore", `{ "gameId": "game1", "userId": "user1" }`, apiKey)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.JSONEq(t, `{"result": { "score": 10 } }`, w.Body.String())
+		}
+
+		w = apiCall(server, "POST", "/leaderboard/GetScore", `{ "gameId": "game1", "userId": "user1" }`, "server-key-0123456789")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result": {} }`, w.Body.String())
+		w = apiCall(server, "GET", "/v2/games/game1/users/user1", "", "server-key-0123456789")
+		require.Equal(t, http.StatusNotFound, w.Code)
+		w = apiCall(server, "GET", "/v2/games/game1/users/user1", "", "client-key-0123456789")
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestServerJwt(t *testing.T) {
+	secret := "jwt-secret-0123456789-0123456789"
+
+	conf := *config.GetAppConfig()
+	conf.Jwt = &config.JwtConfig{Secret: secret, Leeway: 30000}
+
+	setupTest := func() (func() error, *AppServer, error) {
+		server := NewAppServer(nil)
+		err := server.Initialize(&conf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*AppServer]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	newToken := func(sub string, scope string) string {
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+		payload := base64.RawURLEncoding.EncodeToString([]byte(
+			fmt.Sprintf(`{"sub":"%s","scope":"%s","exp":%d}`, sub, scope, time.Now().Add(time.Hour).Unix())))
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(header + "." + payload))
+		return header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	apiCall := func(server *AppServer, path string, body string, token string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set(middleware.HEADER_AUTHORIZATION, "Bearer "+token)
+		}
+		server.router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	runTest("check token subject", func(t *testing.T, server *AppServer) {
+		user1Token := newToken("user1", "")
+		serverToken := newToken("server1", "server")
+
+		require.Equal(t, http.StatusUnauthorized, apiCall(server, "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user1", "score": 10 }`, ""))
+		require.Equal(t, http.StatusUnauthorized, apiCall(server, "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user1", "score": 10 }`, user1Token+"x"))
+		require.Equal(t, http.StatusOK, apiCall(server, "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user1", "score": 10 }`, user1Token))
+		require.Equal(t, http.StatusForbidden, apiCall(server, "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user2", "score": 10 }`, user1Token))
+		require.Equal(t, http.StatusOK, apiCall(server, "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user2", "score": 10 }`, serverToken))
+
+		require.Equal(t, http.StatusOK, apiCall(server, "/leaderboard/GetScore", `{ "gameId": "game1", "userId": "user1" }`, user1Token))
+		require.Equal(t, http.StatusForbidden, apiCall(server, "/leaderboard/GetScore", `{ "gameId": "game1", "userId": "user2" }`, user1Token))
+		require.Equal(t, http.StatusOK, apiCall(server, "/leaderboard/GetTop", `{ "gameId": "game1", "nTop": 10 }`, ""))
+
+		require.Equal(t, http.StatusForbidden, apiCall(server, "/leaderboard/DeleteScore", `{ "gameId": "game1", "userId": "user2" }`, user1Token))
+		require.Equal(t, http.StatusOK, apiCall(server, "/leaderboard/DeleteScore", `{ "gameId": "game1", "userId": "user2" }`, serverToken))
+	})
+}
+
+func TestServerRateLimit(t *testing.T) {
+	now := time.UnixMilli(1000000)
+
+	conf := *config.GetAppConfig()
+	conf.RateLimit = &config.RateLimitConfig{
+		Type:   config.RATELIMITTYPE_MEMORY,
+		Config: &ratelimit_memory_provider.RateLimitMemoryProviderConfig{},
+		Groups: map[string]config.RateLimitGroupConfig{
+			config.ROUTEGROUP_LEADERBOARD: {
+				Ip:   &ratelimitprovider.Limit{Burst: 10, Rate: 0.2},
+				User: &ratelimitprovider.Limit{Burst: 2, Rate: 0.2},
+			},
+		},
+	}
+
+	setupTest := func() (func() error, *AppServer, error) {
+		clock := &utils.MockClock{}
+		clock.SetTime(now)
+		server := NewAppServer(clock)
+		err := server.Initialize(&conf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*AppServer]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	runTest("limit score submissions", func(t *testing.T, server *AppServer) {
+		sendScore := func(userId string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/leaderboard/SendScore",
+				bytes.NewBuffer([]byte(fmt.Sprintf(`{ "gameId": "game1", "userId": "%s", "score": 10 }`, userId))))
+			req.Header.Set("Content-Type", "application/json")
+			server.router.ServeHTTP(w, req)
+			return w
+		}
+
+		require.Equal(t, http.StatusOK, sendScore("user1").Code)
+		require.Equal(t, http.StatusOK, sendScore("user1").Code)
+		w := sendScore("user1")
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		require.Equal(t, "5", w.Header().Get(middleware.HEADER_RETRY_AFTER))
+		require.Equal(t, http.StatusOK, sendScore("user2").Code)
+
+		server.clock.(*utils.MockClock).SetTime(now.Add(5 * time.Second))
+		require.Equal(t, http.StatusOK, sendScore("user1").Code)
+		require.Equal(t, http.StatusTooManyRequests, sendScore("user1").Code)
+	})
+
+	runTest("limit spoofed identities", func(t *testing.T, server *AppServer) {
+		sendScore := func(body string, forwardedFor string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/leaderboard/SendScore", bytes.NewBuffer([]byte(body)))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+			req.RemoteAddr = "192.0.2.1:1234"
+			server.router.ServeHTTP(w, req)
+			return w
+		}
+
+		// bodies the user limit can't read are rejected
+		body := `{ "gameId": "game1", "userId": "user1", "score": 10 }`
+		require.Equal(t, http.StatusOK, sendScore(body, "10.0.0.1").Code)
+		require.Equal(t, http.StatusOK, sendScore(body, "10.0.0.1").Code)
+		require.Equal(t, http.StatusBadRequest, sendScore(body+" x", "10.0.0.1").Code)
+		require.Equal(t, http.StatusBadRequest, sendScore(`{ "gameId": "game1", "userId": 1, "score": 10 }`, "10.0.0.1").Code)
+
+		// forwarded IPs of untrusted proxies are ignored, rejected bodies used up the IP limit as well
+		for i := 0; i < 6; i++ {
+			require.Equal(t, http.StatusOK,
+				sendScore(fmt.Sprintf(`{ "gameId": "game1", "userId": "user%d", "score": 10 }`, i+2), fmt.Sprintf("10.0.1.%d", i)).Code)
+		}
+		require.Equal(t, http.StatusTooManyRequests, sendScore(`{ "gameId": "game1", "userId": "user9", "score": 10 }`, "10.0.2.1").Code)
+	})
+
+	keyConf := conf
+	keyConf.Auth = &config.AuthConfig{
+		Keys: []config.ApiKeyConfig{{Key: "server-key-0123456789", Role: config.ROLE_SERVER, Games: []string{"*"}}},
+	}
+	keyConf.RateLimit = &config.RateLimitConfig{
+		Type:   config.RATELIMITTYPE_MEMORY,
+		Config: &ratelimit_memory_provider.RateLimitMemoryProviderConfig{},
+		Groups: map[string]config.RateLimitGroupConfig{
+			config.ROUTEGROUP_LEADERBOARD: {
+				Ip:     &ratelimitprovider.Limit{Burst: 4, Rate: 0.2},
+				ApiKey: &ratelimitprovider.Limit{Burst: 2, Rate: 0.2},
+			},
+		},
+	}
+
+	setupKeyTest := func() (func() error, *AppServer, error) {
+		clock := &utils.MockClock{}
+		clock.SetTime(now)
+		server := NewAppServer(clock)
+		err := server.Initialize(&keyConf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	utils.RunTest(t, "limit authenticated api keys", setupKeyTest, func(t *testing.T, server *AppServer) {
+		sendScore := func(apiKey string) int {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/leaderboard/SendScore", bytes.NewBuffer([]byte(`{ "gameId": "game1", "userId": "user1", "score": 10 }`)))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(middleware.HEADER_API_KEY, apiKey)
+			req.RemoteAddr = "192.0.2.1:1234"
+			server.router.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		// unknown keys share the limit of the IP, rejected requests don't use it up
+		require.Equal(t, http.StatusUnauthorized, sendScore("unknown-key-1"))
+		require.Equal(t, http.StatusOK, sendScore("server-key-0123456789"))
+		require.Equal(t, http.StatusOK, sendScore("server-key-0123456789"))
+		for i := 0; i < 5; i++ {
+			require.Equal(t, http.StatusTooManyRequests, sendScore("server-key-0123456789"))
+		}
+		require.Equal(t, http.StatusUnauthorized, sendScore("unknown-key-2"))
+		require.Equal(t, http.StatusTooManyRequests, sendScore("unknown-key-3"))
+	})
+
+	secret := "jwt-secret-0123456789-0123456789"
+	jwtConf := conf
+	jwtConf.Jwt = &config.JwtConfig{Secret: secret, Leeway: 30000}
+
+	setupJwtTest := func() (func() error, *AppServer, error) {
+		clock := &utils.MockClock{}
+		clock.SetTime(time.Now())
+		server := NewAppServer(clock)
+		err := server.Initialize(&jwtConf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	utils.RunTest(t, "limit authenticated users", setupJwtTest, func(t *testing.T, server *AppServer) {
+		newToken := func(sub string) string {
+			header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+			payload := base64.RawURLEncoding.EncodeToString([]byte(
+				fmt.Sprintf(`{"sub":"%s","exp":%d}`, sub, time.Now().Add(time.Hour).Unix())))
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write([]byte(header + "." + payload))
+			return header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+		}
+
+		sendScore := func(userId string, token string) int {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/leaderboard/SendScore",
+				bytes.NewBuffer([]byte(fmt.Sprintf(`{ "gameId": "game1", "userId": "%s", "score": 10 }`, userId))))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(middleware.HEADER_AUTHORIZATION, "Bearer "+token)
+			server.router.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		// the user of the token is limited, whatever userId the body names
+		user1Token := newToken("user1")
+		require.Equal(t, http.StatusOK, sendScore("user1", user1Token))
+		require.Equal(t, http.StatusOK, sendScore("user1", user1Token))
+		require.Equal(t, http.StatusTooManyRequests, sendScore("user2", user1Token))
+		require.Equal(t, http.StatusOK, sendScore("user2", newToken("user2")))
+	})
+}
+
+func TestServerIdempotency(t *testing.T) {
+	conf := *config.GetAppConfig()
+	conf.Idempotency = &config.IdempotencyConfig{
+		Type:        config.IDEMPOTENCYTYPE_MEMORY,
+		Config:      &idempotency_memory_provider.IdempotencyMemoryProviderConfig{},
+		Window:      60000,
+		LockTimeout: 1000,
+	}
+	conf.Boards = map[string]config.BoardConfig{
+		"signedgame": {Secrets: []string{"test-secret-0123456789"}},
+	}
+
+	setupTest := func() (func() error, *AppServer, error) {
+		server := NewAppServer(nil)
+		err := server.Initialize(&conf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*AppServer]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	apiCall := func(server *AppServer, path string, body string, key string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(middleware.HEADER_IDEMPOTENCY_KEY, key)
+		}
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	runTest("replay signed score submission", func(t *testing.T, server *AppServer) {
+		body := `{ "gameId": "signedgame", "userId": "user1", "score": 10 }`
+		ts := strconv.FormatInt(server.clock.Now().UnixMilli(), 10)
+		sig, err := services.Sign("test-secret-0123456789", ts, "nonce1", []byte(body))
+		require.NoError(t, err)
+
+		signedCall := func(key string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/leaderboard/SendScore", bytes.NewBuffer([]byte(body)))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(middleware.HEADER_SIGNATURE_TIMESTAMP, ts)
+			req.Header.Set(middleware.HEADER_SIGNATURE_NONCE, "nonce1")
+			req.Header.Set(middleware.HEADER_SIGNATURE, sig)
+			if key != "" {
+				req.Header.Set(middleware.HEADER_IDEMPOTENCY_KEY, key)
+			}
+			server.router.ServeHTTP(w, req)
+			return w
+		}
+
+		w := signedCall("key1")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		// an exact retry gets the stored response, the nonce can't be used by other requests
+		w = signedCall("key1")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "true", w.Header().Get(middleware.HEADER_IDEMPOTENCY_REPLAYED))
+
+		w = signedCall("key2")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Contains(t, w.Body.String(), services.ErrSignatureReplay.Error())
+		w = signedCall("")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+
+		// a failed signature check doesn't store a response
+		w = apiCall(server, "/leaderboard/SendScore", body, "key3")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		w = signedCall("key3")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Contains(t, w.Body.String(), services.ErrSignatureReplay.Error())
+	})
+
+	runTest("replay score submission", func(t *testing.T, server *AppServer) {
+		body := `{ "gameId": "game1", "userId": "user1", "score": 10 }`
+
+		w := apiCall(server, "/leaderboard/SendScore", body, "key1")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, w.Header().Get(middleware.HEADER_IDEMPOTENCY_REPLAYED))
+
+		w = apiCall(server, "/leaderboard/DeleteScore", `{ "gameId": "game1", "userId": "user1" }`, "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		// the replay doesn't store the score again
+		w = apiCall(server, "/leaderboard/SendScore", body, "key1")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "true", w.Header().Get(middleware.HEADER_IDEMPOTENCY_REPLAYED))
+		require.JSONEq(t, `{"result":"success"}`, w.Body.String())
+
+		w = apiCall(server, "/leaderboard/GetScore", `{ "gameId": "game1", "userId": "user1" }`, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result":{}}`, w.Body.String())
+
+		w = apiCall(server, "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user1", "score": 20 }`, "key1")
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		// failed requests are not stored
+		w = apiCall(server, "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user1" }`, "key2")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		w = apiCall(server, "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user1" }`, "key2")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Empty(t, w.Header().Get(middleware.HEADER_IDEMPOTENCY_REPLAYED))
+	})
+}
+
+func TestServerAudit(t *testing.T) {
+	conf := *config.GetAppConfig()
+	conf.Auth = &config.AuthConfig{
+		Keys: []config.ApiKeyConfig{
+			{Key: "admin-key-0123456789", Role: config.ROLE_ADMIN, Games: []string{"*"}},
+			{Key: "game-admin-key-0123456789", Role: config.ROLE_ADMIN, Games: []string{"game1"}},
+			{Key: "studio1-key-0123456789", Role: config.ROLE_ADMIN, Games: []string{"*"}, Tenant: "studio1"},
+			{Key: "operator-key-0123456789", Role: config.ROLE_ADMIN, Games: []string{"*"}, Operator: true},
+		},
+	}
+	conf.Audit = &config.AuditConfig{
+		Type:   config.AUDITSINKTYPE_DB,
+		Config: &audit_db_sink.AuditDbSinkConfig{},
+		Key:    "audit-key-0123456789-0123456789-01",
+	}
+
+	setupTest := func() (func() error, *AppServer, error) {
+		server := NewAppServer(nil)
+		err := server.Initialize(&conf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*AppServer]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	apiCall := func(server *AppServer, path string, body string, apiKey string, requestId string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.HEADER_API_KEY, apiKey)
+		if requestId != "" {
+			req.Header.Set(middleware.HEADER_REQUEST_ID, requestId)
+		}
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	runTest("record destructive operations", func(t *testing.T, server *AppServer) {
+		w := apiCall(server, "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user1", "score": 10 }`, "admin-key-0123456789", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, w.Header().Get(middleware.HEADER_REQUEST_ID), 32)
+
+		w = apiCall(server, "/leaderboard/DeleteScore", `{ "gameId": "game1", "userId": "user1" }`, "game-admin-key-0123456789", "req-1")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "req-1", w.Header().Get(middleware.HEADER_REQUEST_ID))
+
+		w = apiCall(server, "/admin/SetUserState", `{ "gameId": "game1", "userId": "user2", "state": "banned" }`, "admin-key-0123456789", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		// keys limited to some games can't read the log of all games
+		w = apiCall(server, "/admin/GetAudit", `{ "limit": 10 }`, "game-admin-key-0123456789", "")
+		require.Equal(t, http.StatusForbidden, w.Code)
+
+		// keys of tenants read entries of their tenant only
+		var result controllers.GetAuditResultSuccess
+		w = apiCall(server, "/admin/GetAudit", `{ "limit": 10 }`, "studio1-key-0123456789", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		require.True(t, result.Result.Valid)
+		require.Empty(t, result.Result.Entries)
+
+		w = apiCall(server, "/admin/GetAudit", `{ "limit": 10 }`, "admin-key-0123456789", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		require.True(t, result.Result.Valid)
+		require.Len(t, result.Result.Entries, 2)
+
+		entry := result.Result.Entries[0]
+		require.Equal(t, uint64(1), entry.Seq)
+		require.Regexp(t, `^key:[0-9a-f]{12}$`, entry.Actor)
+		require.Equal(t, services.AUDITACTION_DELETE_SCORE, entry.Action)
+		require.Equal(t, "game1", entry.GameId)
+		require.Equal(t, "user1", entry.UserId)
+		require.JSONEq(t, `{"score":10}`, entry.Before)
+		require.Equal(t, "req-1", entry.RequestId)
+
+		entry = result.Result.Entries[1]
+		require.NotEqual(t, result.Result.Entries[0].Actor, entry.Actor)
+		require.Equal(t, services.AUDITACTION_SET_USER_STATE, entry.Action)
+		require.JSONEq(t, `{"state":"visible"}`, entry.Before)
+		require.JSONEq(t, `{"state":"banned"}`, entry.After)
+		require.Equal(t, result.Result.Entries[0].Hash, entry.PrevHash)
+
+		w = apiCall(server, "/admin/GetAudit", `{ "fromSeq": 2, "limit": 10 }`, "admin-key-0123456789", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		require.True(t, result.Result.Valid)
+		require.Len(t, result.Result.Entries, 1)
+		require.Equal(t, uint64(2), result.Result.Entries[0].Seq)
+
+		w = apiCall(server, "/admin/SetUserState", `{ "gameId": "game1", "userId": "user2", "state": "banned" }`, "studio1-key-0123456789", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = apiCall(server, "/admin/GetAudit", `{ "limit": 10 }`, "studio1-key-0123456789", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		require.True(t, result.Result.Valid)
+		require.Len(t, result.Result.Entries, 1)
+		require.Equal(t, uint64(3), result.Result.Entries[0].Seq)
+		require.Equal(t, "studio1", result.Result.Entries[0].Tenant)
+		require.Equal(t, "game1", result.Result.Entries[0].GameId)
+
+		// keys of the default tenant read its entries only, operator keys read entries of all tenants
+		w = apiCall(server, "/admin/GetAudit", `{ "limit": 10 }`, "admin-key-0123456789", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		require.Len(t, result.Result.Entries, 2)
+		require.Equal(t, "", result.Result.Entries[1].Tenant)
+
+		w = apiCall(server, "/admin/GetAudit", `{ "limit": 10 }`, "operator-key-0123456789", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		require.Len(t, result.Result.Entries, 3)
+	})
+}
+
+func TestServerTenants(t *testing.T) {
+	secret := "jwt-secret-0123456789-0123456789"
+
+	conf := *config.GetAppConfig()
+	conf.Auth = &config.AuthConfig{
+		Keys: []config.ApiKeyConfig{
+			{Key: "server-key-0123456789", Role: config.ROLE_SERVER, Games: []string{"*"}},
+			{Key: "studio1-key-0123456789", Role: config.ROLE_ADMIN, Games: []string{"*"}, Tenant: "studio1"},
+			{Key: "studio2-key-0123456789", Role: config.ROLE_ADMIN, Games: []string{"*"}, Tenant: "studio2"},
+			{Key: "studio1-client-key-0123456789", Role: config.ROLE_CLIENT, UserId: "user1", Games: []string{"game1"}, Tenant: "studio1"},
+		},
+	}
+	conf.Jwt = &config.JwtConfig{Secret: secret, Leeway: 30000}
+	conf.Boards = map[string]config.BoardConfig{}
+	for gameId, board := range config.GetAppConfig().Boards {
+		conf.Boards[gameId] = board
+	}
+	conf.Boards["studio2/game1"] = config.BoardConfig{Type: config.BOARDTYPE_RUNS, RunsPerUser: 2}
+
+	setupTest := func() (func() error, *AppServer, error) {
+		server := NewAppServer(nil)
+		err := server.Initialize(&conf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*AppServer]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	newToken := func(sub string, tenant string) string {
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+		payload := base64.RawURLEncoding.EncodeToString([]byte(
+			fmt.Sprintf(`{"sub":"%s","tenant":"%s","exp":%d}`, sub, tenant, time.Now().Add(time.Hour).Unix())))
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(header + "." + payload))
+		return header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	apiCall := func(server *AppServer, path string, body string, apiKey string, token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.HEADER_API_KEY, apiKey)
+		if token != "" {
+			req.Header.Set(middleware.HEADER_AUTHORIZATION, "Bearer "+token)
+		}
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	runTest("isolate tenant data", func(t *testing.T, server *AppServer) {
+		sendScore := func(apiKey string, score int) {
+			w := apiCall(server, "/leaderboard/SendScore",
+				fmt.Sprintf(`{ "gameId": "game1", "userId": "user1", "score": %d, "runId": "run1" }`, score), apiKey, "")
+			require.Equal(t, http.StatusOK, w.Code)
+		}
+		getTop := func(apiKey string) string {
+			w := apiCall(server, "/leaderboard/GetTop", `{ "gameId": "game1", "nTop": 10 }`, apiKey, "")
+			require.Equal(t, http.StatusOK, w.Code)
+			return w.Body.String()
+		}
+
+		sendScore("server-key-0123456789", 10)
+		sendScore("studio1-key-0123456789", 20)
+		sendScore("studio2-key-0123456789", 30)
+
+		require.JSONEq(t, `{"result":[{"userId":"user1","score":10}]}`, getTop("server-key-0123456789"))
+		require.JSONEq(t, `{"result":[{"userId":"user1","score":20}]}`, getTop("studio1-key-0123456789"))
+		// boards of tenants have their own settings
+		var runsTop controllers.GetTopRunsResultSuccess
+		require.NoError(t, json.Unmarshal([]byte(getTop("studio2-key-0123456789")), &runsTop))
+		require.Len(t, runsTop.Result, 1)
+		require.Equal(t, "run1", runsTop.Result[0].RunId)
+		require.Equal(t, dbprovider.UScoreType(30), runsTop.Result[0].Score)
+
+		// admin operations are limited to the tenant of the key
+		w := apiCall(server, "/admin/SetUserState", `{ "gameId": "game1", "userId": "user1", "state": "banned" }`, "studio1-key-0123456789", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result":[]}`, getTop("studio1-key-0123456789"))
+		require.JSONEq(t, `{"result":[{"userId":"user1","score":10}]}`, getTop("server-key-0123456789"))
+
+		w = apiCall(server, "/leaderboard/DeleteScore", `{ "gameId": "game1", "userId": "user1" }`, "studio2-key-0123456789", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		w = apiCall(server, "/admin/GetUserState", `{ "gameId": "game1", "userId": "user1" }`, "studio2-key-0123456789", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result":{"state":"visible"}}`, w.Body.String())
+		require.JSONEq(t, `{"result":[{"userId":"user1","score":10}]}`, getTop("server-key-0123456789"))
+	})
+
+	runTest("check token tenant", func(t *testing.T, server *AppServer) {
+		body := `{ "gameId": "game1", "userId": "user1", "score": 10 }`
+
+		w := apiCall(server, "/leaderboard/SendScore", body, "studio1-client-key-0123456789", newToken("user1", "studio1"))
+		require.Equal(t, http.StatusOK, w.Code)
+		w = apiCall(server, "/leaderboard/SendScore", body, "studio1-client-key-0123456789", newToken("user1", "studio2"))
+		require.Equal(t, http.StatusForbidden, w.Code)
+		w = apiCall(server, "/leaderboard/SendScore", body, "studio1-client-key-0123456789", newToken("user1", ""))
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestServerQuota(t *testing.T) {
+	conf := *config.GetAppConfig()
+	conf.Auth = &config.AuthConfig{
+		Keys: []config.ApiKeyConfig{
+			{Key: "admin-key-0123456789", Role: config.ROLE_ADMIN, Games: []string{"*"}},
+			{Key: "studio1-key-0123456789", Role: config.ROLE_ADMIN, Games: []string{"*"}, Tenant: "studio1"},
+			{Key: "operator-key-0123456789", Role: config.ROLE_ADMIN, Games: []string{"*"}, Operator: true},
+		},
+	}
+	conf.Quota = &config.QuotaConfig{
+		Type:   config.QUOTATYPE_MEMORY,
+		Config: &quota_memory_provider.QuotaMemoryProviderConfig{},
+		Games: map[string]config.QuotaLimits{
+			"studio1/game1": {WritesPerDay: 2},
+		},
+		Tenants: map[string]config.QuotaLimits{
+			"studio1": {MaxEntries: 10},
+		},
+	}
+
+	setupTest := func() (func() error, *AppServer, error) {
+		server := NewAppServer(nil)
+		err := server.Initialize(&conf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*AppServer]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	apiCall := func(server *AppServer, path string, body string, apiKey string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.HEADER_API_KEY, apiKey)
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	runTest("reject writes over quota", func(t *testing.T, server *AppServer) {
+		for i := 0; i < 2; i++ {
+			w := apiCall(server, "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user1", "score": 10 }`, "studio1-key-0123456789")
+			require.Equal(t, http.StatusOK, w.Code)
+		}
+		w := apiCall(server, "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user1", "score": 10 }`, "studio1-key-0123456789")
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		var result controllers.ResultError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		require.Equal(t, "quota_writes_per_day", result.Code)
+		retryAfter, err := strconv.Atoi(w.Header().Get(middleware.HEADER_RETRY_AFTER))
+		require.NoError(t, err)
+		require.Positive(t, retryAfter)
+
+		// the game of the default tenant has no quota
+		for i := 0; i < 3; i++ {
+			w = apiCall(server, "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user1", "score": 10 }`, "admin-key-0123456789")
+			require.Equal(t, http.StatusOK, w.Code)
+		}
+	})
+
+	runTest("report usage", func(t *testing.T, server *AppServer) {
+		w := apiCall(server, "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user1", "score": 10 }`, "studio1-key-0123456789")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		expected := `{"result":{"tenant":"studio1","writes":1,"entries":1,"writesPerDay":0,"maxEntries":10,
+			"games":[{"gameId":"game1","writes":1,"entries":1,"writesPerDay":2,"maxEntries":0}]}}`
+		w = apiCall(server, "/admin/GetUsage", `{}`, "studio1-key-0123456789")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, expected, w.Body.String())
+		w = apiCall(server, "/admin/GetUsage", `{ "tenant": "studio1" }`, "operator-key-0123456789")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, expected, w.Body.String())
+
+		// keys of tenants, including the default one, can't see usage of other tenants
+		w = apiCall(server, "/admin/GetUsage", `{ "tenant": "studio2" }`, "studio1-key-0123456789")
+		require.Equal(t, http.StatusForbidden, w.Code)
+		w = apiCall(server, "/admin/GetUsage", `{ "tenant": "studio1" }`, "admin-key-0123456789")
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestServerV2(t *testing.T) {
+	maxScore := 100.0
+	conf := *config.GetAppConfig()
+	conf.Boards = map[string]config.BoardConfig{
+		"signedgame": {Secrets: []string{"test-secret-0123456789"}},
+		"runsgame":   {Type: config.BOARDTYPE_RUNS, RunsPerUser: 3},
+		"strictgame": {Rules: &config.RulesConfig{MaxScore: &maxScore, Action: config.RULEACTION_QUARANTINE}},
+	}
+
+	setupTest := func() (func() error, *AppServer, error) {
+		server := NewAppServer(nil)
+		err := server.Initialize(&conf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*AppServer]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	apiCall := func(server *AppServer, method string, path string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		var bbuf io.Reader
+		if body != "" {
+			bbuf = bytes.NewBuffer([]byte(body))
+		}
+		req, _ := http.NewRequest(method, path, bbuf)
+		req.Header.Set("Content-Type", "application/json")
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	runTest("manage user scores", func(t *testing.T, server *AppServer) {
+		var w *httptest.ResponseRecorder
+
+		w = apiCall(server, "GET", "/v2/games/game1/users/user1", "")
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.JSONEq(t, `{"error": "user not found"}`, w.Body.String())
+
+		w = apiCall(server, "PUT", "/v2/games/game1/users/user1", `{ "score": 10, "name": "John" }`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.JSONEq(t, `{"result": "success"}`, w.Body.String())
+
+		w = apiCall(server, "PUT", "/v2/games/game1/users/user1", `{ "score": 20, "name": "John" }`)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Empty(t, w.Body.String())
+
+		w = apiCall(server, "GET", "/v2/games/game1/users/user1", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result": { "score": 20, "name": "John" } }`, w.Body.String())
+
+		jtop, _ := json.Marshal(dbprovider.TopData{{UserId: "user1", UserProperties: dbprovider.UserProperties{Score: 20, Name: "John"}}})
+		w = apiCall(server, "GET", "/v2/games/game1/top?n=10", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, fmt.Sprintf(`{"result": %s}`, string(jtop)), w.Body.String())
+
+		// v1 routes work with the same data
+		w = apiCall(server, "POST", "/leaderboard/GetScore", `{ "gameId": "game1", "userId": "user1" }`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result": { "score": 20, "name": "John" } }`, w.Body.String())
+
+		w = apiCall(server, "DELETE", "/v2/games/game1/users/user1", "")
+		require.Equal(t, http.StatusNoContent, w.Code)
+		w = apiCall(server, "DELETE", "/v2/games/game1/users/user1", "")
+		require.Equal(t, http.StatusNotFound, w.Code)
+		w = apiCall(server, "GET", "/v2/games/game1/users/user1", "")
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	runTest("manage user runs", func(t *testing.T, server *AppServer) {
+		for _, runId := range []string{"run1", "run2"} {
+			w := apiCall(server, "PUT", "/v2/games/runsgame/users/user1", fmt.Sprintf(`{ "score": 10, "runId": "%s" }`, runId))
+			require.Equal(t, http.StatusCreated, w.Code)
+		}
+
+		w := apiCall(server, "GET", "/v2/games/runsgame/users/user1", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var result controllers.GetScoreResultSuccess[[]dbprovider.RunProperties]
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		require.Len(t, result.Result, 2)
+
+		w = apiCall(server, "DELETE", "/v2/games/runsgame/users/user1", "")
+		require.Equal(t, http.StatusNoContent, w.Code)
+		w = apiCall(server, "GET", "/v2/games/runsgame/users/user1", "")
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	runTest("wrong request params => error 400", func(t *testing.T, server *AppServer) {
+		w := apiCall(server, "GET", "/v2/games/game1/top?n=abc", "")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		w = apiCall(server, "GET", "/v2/games/game1/top", "")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		w = apiCall(server, "PUT", "/v2/games/game1/users/user1", `{ "name": "John" }`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		w = apiCall(server, "PUT", "/v2/games/game_1/users/user1", `{ "score": 10 }`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	runTest("select fields of top entries", func(t *testing.T, server *AppServer) {
+		var w *httptest.ResponseRecorder
+
+		w = apiCall(server, "PUT", "/v2/games/game1/users/user1", `{ "score": 20, "name": "John", "params": "payload1" }`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		w = apiCall(server, "PUT", "/v2/games/game1/users/user2", `{ "score": 10, "name": "Jane", "params": "payload2" }`)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		w = apiCall(server, "GET", "/v2/games/game1/top?n=10&fields=userId,score", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result": [{ "userId": "user1", "score": 20 }, { "userId": "user2", "score": 10 }]}`, w.Body.String())
+
+		w = apiCall(server, "POST", "/leaderboard/GetTop", `{ "gameId": "game1", "nTop": 1, "fields": "name" }`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result": [{ "userId": "user1", "score": 20, "name": "John" }]}`, w.Body.String())
+
+		w = apiCall(server, "GET", "/v2/games/game1/top?n=10", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result": [
+			{ "userId": "user1", "score": 20, "name": "John", "params": "payload1" },
+			{ "userId": "user2", "score": 10, "name": "Jane", "params": "payload2" }
+		]}`, w.Body.String())
+
+		w = apiCall(server, "GET", "/v2/games/game1/top?n=10&fields=userId,email", "")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		// runs boards
+		w = apiCall(server, "PUT", "/v2/games/runsgame/users/user1", `{ "score": 10, "runId": "run1", "name": "John", "params": "payload1" }`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		w = apiCall(server, "GET", "/v2/games/runsgame/top?n=10&fields=userId,score,runId", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var runsTop controllers.GetTopRunsResultSuccess
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &runsTop))
+		require.Len(t, runsTop.Result, 1)
+		require.Equal(t, "run1", runsTop.Result[0].RunId)
+		require.Empty(t, runsTop.Result[0].Name)
+		require.Empty(t, runsTop.Result[0].Params)
+	})
+
+	runTest("send signed score", func(t *testing.T, server *AppServer) {
+		w := apiCall(server, "PUT", "/v2/games/signedgame/users/user1", `{ "score": 10 }`)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+
+		// the signature covers the ids of the path as if they were in the body
+		ts := strconv.FormatInt(server.clock.Now().UnixMilli(), 10)
+		sig, _ := services.Sign("test-secret-0123456789", ts, "nonce1", []byte(`{ "gameId": "signedgame", "userId": "user1", "score": 10 }`))
+		req, _ := http.NewRequest("PUT", "/v2/games/signedgame/users/user1", bytes.NewBuffer([]byte(`{ "score": 10 }`)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.HEADER_SIGNATURE_TIMESTAMP, ts)
+		req.Header.Set(middleware.HEADER_SIGNATURE_NONCE, "nonce1")
+		req.Header.Set(middleware.HEADER_SIGNATURE, sig)
+		w = httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	runTest("moderate users", func(t *testing.T, server *AppServer) {
+		var w *httptest.ResponseRecorder
+
+		w = apiCall(server, "PUT", "/v2/games/game1/users/user1/state", `{ "state": "banned" }`)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		w = apiCall(server, "GET", "/v2/games/game1/users/user1/state", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result": { "state": "banned" } }`, w.Body.String())
+		w = apiCall(server, "PUT", "/v2/games/game1/users/user1", `{ "score": 10 }`)
+		require.Equal(t, http.StatusForbidden, w.Code)
+
+		w = apiCall(server, "PUT", "/v2/games/strictgame/users/user2", `{ "score": 500 }`)
+		require.Equal(t, http.StatusAccepted, w.Code)
+		require.JSONEq(t, `{"result": "quarantined"}`, w.Body.String())
+		w = apiCall(server, "PUT", "/v2/games/strictgame/users/user3", `{ "score": 600 }`)
+		require.Equal(t, http.StatusAccepted, w.Code)
+
+		w = apiCall(server, "GET", "/v2/games/strictgame/quarantine?limit=10", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var result controllers.GetQuarantineResultSuccess
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		require.Len(t, result.Result, 2)
+		ids := map[string]string{}
+		for _, item := range result.Result {
+			ids[item.UserId] = item.Id
+		}
+
+		w = apiCall(server, "POST", "/v2/games/strictgame/quarantine/"+ids["user2"]+"/approve", "")
+		require.Equal(t, http.StatusNoContent, w.Code)
+		w = apiCall(server, "POST", "/v2/games/strictgame/quarantine/"+ids["user2"]+"/approve", "")
+		require.Equal(t, http.StatusNotFound, w.Code)
+		w = apiCall(server, "DELETE", "/v2/games/strictgame/quarantine/"+ids["user3"], "")
+		require.Equal(t, http.StatusNoContent, w.Code)
+		w = apiCall(server, "DELETE", "/v2/games/strictgame/quarantine/"+ids["user3"], "")
+		require.Equal(t, http.StatusNotFound, w.Code)
+
+		w = apiCall(server, "GET", "/v2/games/strictgame/users/user2", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result": { "score": 500 } }`, w.Body.String())
+		w = apiCall(server, "GET", "/v2/games/strictgame/users/user3", "")
+		require.Equal(t, http.StatusNotFound, w.Code)
+
+		// audit and quotas are disabled
+		w = apiCall(server, "GET", "/v2/audit?limit=10", "")
+		require.Equal(t, http.StatusNotFound, w.Code)
+		w = apiCall(server, "GET", "/v2/usage", "")
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestServerSubscriptions(t *testing.T) {
+	conf := *config.GetAppConfig()
+	conf.Subscriptions.Heartbeat = 50
+
+	setupTest := func() (func() error, *AppServer, error) {
+		server := NewAppServer(nil)
+		err := server.Initialize(&conf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*AppServer]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	type event struct {
+		name string
+		data string
+	}
+
+	// Returns events of the stream, skipping heartbeats if required
+	subscribe := func(t *testing.T, ctx context.Context, url string) (*http.Response, func(heartbeats bool) event) {
+		req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		events := make(chan event, 100)
+		go func() {
+			defer close(events)
+			scanner := bufio.NewScanner(resp.Body)
+			ev := event{}
+			for scanner.Scan() {
+				line := scanner.Text()
+				switch {
+				case strings.HasPrefix(line, "event:"):
+					ev.name = line[len("event:"):]
+				case strings.HasPrefix(line, "data:"):
+					ev.data = line[len("data:"):]
+				case line == "":
+					events <- ev
+					ev = event{}
+				}
+			}
+		}()
+
+		return resp, func(heartbeats bool) event {
+			for {
+				select {
+				case ev, ok := <-events:
+					require.True(t, ok, "stream is closed")
+					if ev.name != "heartbeat" || heartbeats {
+						return ev
+					}
+				case <-time.After(time.Second):
+					require.FailNow(t, "no event")
+				}
+			}
+		}
+	}
+
+	apiCall := func(server *AppServer, method string, path string, body string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		server.router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	runTest("subscribe to top", func(t *testing.T, server *AppServer) {
+		httpServer := httptest.NewServer(server.router)
+		defer httpServer.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		require.Equal(t, http.StatusCreated, apiCall(server, "PUT", "/v2/games/game1/users/user1", `{ "score": 10 }`))
+
+		resp, next := subscribe(t, ctx, httpServer.URL+"/v2/games/game1/top/subscribe?n=2&mode=diff")
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		ev := next(false)
+		require.Equal(t, "snapshot", ev.name)
+		require.JSONEq(t, `{"type": "snapshot", "seq": 1, "entries": [{"rank": 1, "userId": "user1", "score": 10}]}`, ev.data)
+
+		require.Equal(t, http.StatusCreated, apiCall(server, "PUT", "/v2/games/game1/users/user2", `{ "score": 20, "name": "Jane" }`))
+		ev = next(false)
+		require.Equal(t, "diff", ev.name)
+		require.JSONEq(t, `{"type": "diff", "seq": 2, "entries": [
+			{"rank": 1, "userId": "user2", "score": 20, "name": "Jane"}, {"rank": 2, "userId": "user1", "score": 10}
+		]}`, ev.data)
+
+		require.Equal(t, http.StatusNoContent, apiCall(server, "DELETE", "/v2/games/game1/users/user2", ""))
+		ev = next(false)
+		require.JSONEq(t, `{"type": "diff", "seq": 3, "entries": [{"rank": 1, "userId": "user1", "score": 10}], "removed": ["user2"]}`, ev.data)
+
+		// idle streams get heartbeats
+		require.Equal(t, "heartbeat", next(true).name)
+
+		// the stream ends on shutdown
+		server.appContext.SubscriptionService.Close()
+		ev = next(true)
+		require.Equal(t, "error", ev.name)
+		require.JSONEq(t, `{"error": "subscriptions are closed"}`, ev.data)
+	})
+
+	runTest("wrong params", func(t *testing.T, server *AppServer) {
+		require.Equal(t, http.StatusBadRequest, apiCall(server, "GET", "/v2/games/game1/top/subscribe", ""))
+		require.Equal(t, http.StatusBadRequest, apiCall(server, "GET", "/v2/games/game1/top/subscribe?n=10&mode=full", ""))
+		require.Equal(t, http.StatusBadRequest, apiCall(server, "GET", "/v2/games/game_1/top/subscribe?n=10", ""))
+
+		server.appContext.SubscriptionService.Close()
+		require.Equal(t, http.StatusServiceUnavailable, apiCall(server, "GET", "/v2/games/game1/top/subscribe?n=10", ""))
+	})
+}
+
+func TestServerChanges(t *testing.T) {
+	conf := *config.GetAppConfig()
+	conf.Auth = &config.AuthConfig{
+		Keys: []config.ApiKeyConfig{
+			{Key: "server-key-0123456789", Role: config.ROLE_SERVER, Games: []string{"*"}},
+			{Key: "client-key-0123456789", Role: config.ROLE_CLIENT, Games: []string{"*"}, UserId: "user1"},
+		},
+	}
+	conf.Changes = &config.ChangesConfig{Retention: 10, MaxWait: 1000, PollInterval: 1000}
+
+	setupTest := func() (func() error, *AppServer, error) {
+		server := NewAppServer(nil)
+		err := server.Initialize(&conf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*AppServer]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	apiCall := func(server *AppServer, method string, path string, body string, apiKey string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.HEADER_API_KEY, apiKey)
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	getChanges := func(t *testing.T, w *httptest.ResponseRecorder) services.ChangeList {
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var result controllers.GetChangesResultSuccess
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		return result.Result
+	}
+
+	runTest("get changes", func(t *testing.T, server *AppServer) {
+		w := apiCall(server, "POST", "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user1", "score": 10, "name": "John" }`, "server-key-0123456789")
+		require.Equal(t, http.StatusOK, w.Code)
+		w = apiCall(server, "PUT", "/v2/games/game1/users/user2", `{ "score": 20 }`, "server-key-0123456789")
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		list := getChanges(t, apiCall(server, "POST", "/leaderboard/GetChanges", `{ "gameId": "game1", "since": 0, "limit": 1 }`, "server-key-0123456789"))
+		require.Equal(t, uint64(1), list.Version)
+		require.True(t, list.More)
+		require.Len(t, list.Changes, 1)
+		require.Equal(t, dbprovider.ChangeEntry{Version: 1, Op: dbprovider.CHANGEOP_PUT, UserId: "user1", Score: 10, Name: "John", Ts: list.Changes[0].Ts},
+			list.Changes[0])
+
+		list = getChanges(t, apiCall(server, "GET", "/v2/games/game1/changes?since=1&limit=10", "", "server-key-0123456789"))
+		require.Equal(t, uint64(2), list.Version)
+		require.False(t, list.More)
+		require.Len(t, list.Changes, 1)
+		require.Equal(t, "user2", list.Changes[0].UserId)
+
+		// clients can't read changes of other users
+		w = apiCall(server, "GET", "/v2/games/game1/changes?limit=10", "", "client-key-0123456789")
+		require.Equal(t, http.StatusForbidden, w.Code)
+		w = apiCall(server, "GET", "/v2/games/game1/changes?limit=0", "", "server-key-0123456789")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	runTest("long polling", func(t *testing.T, server *AppServer) {
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_ = apiCall(server, "PUT", "/v2/games/game1/users/user1", `{ "score": 10 }`, "server-key-0123456789")
+		}()
+
+		list := getChanges(t, apiCall(server, "GET", "/v2/games/game1/changes?limit=10&wait=5000", "", "server-key-0123456789"))
+		require.Equal(t, uint64(1), list.Version)
+		require.Len(t, list.Changes, 1)
+
+		list = getChanges(t, apiCall(server, "GET", "/v2/games/game1/changes?since=1&limit=10&wait=10", "", "server-key-0123456789"))
+		require.Equal(t, uint64(1), list.Version)
+		require.Empty(t, list.Changes)
+	})
+
+	runTest("resync", func(t *testing.T, server *AppServer) {
+		for i := 0; i < 100; i++ {
+			w := apiCall(server, "PUT", fmt.Sprintf("/v2/games/game1/users/user%d", i), `{ "score": 10 }`, "server-key-0123456789")
+			require.Equal(t, http.StatusCreated, w.Code)
+		}
+
+		w := apiCall(server, "GET", "/v2/games/game1/changes?since=50&limit=10", "", "server-key-0123456789")
+		require.Equal(t, http.StatusGone, w.Code)
+		require.Equal(t, "100", w.Header().Get("X-Changes-Version"))
+		var result controllers.ResultError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		require.Equal(t, "changes_resync", result.Code)
+	})
+}
+
+func TestServerWebhooks(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	received := make(chan services.WebhookEvent, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var event services.WebhookEvent
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&event))
+		received <- event
+	}))
+	defer receiver.Close()
+
+	conf := *config.GetAppConfig()
+	conf.Auth = &config.AuthConfig{
+		Keys: []config.ApiKeyConfig{
+			{Key: "admin-key-0123456789", Role: config.ROLE_ADMIN, Games: []string{"*"}},
+			{Key: "studio1-key-0123456789", Role: config.ROLE_ADMIN, Games: []string{"*"}, Tenant: "studio1"},
+		},
+	}
+	conf.Webhooks = &config.WebhooksConfig{
+		Endpoints:    []config.WebhookEndpointConfig{{Id: "rewards", Url: receiver.URL, Secret: "secret1"}},
+		TopSize:      10,
+		MaxAttempts:  2,
+		RetryInitial: 1,
+		RetryMax:     1,
+		Timeout:      1000,
+		QueueSize:    10,
+		DeadLetter: config.DeadLetterConfig{
+			Type:   config.DEADLETTERTYPE_MEMORY,
+			Config: &deadletter_memory_provider.DeadLetterMemoryProviderConfig{},
+		},
+	}
+
+	setupTest := func() (func() error, *AppServer, error) {
+		server := NewAppServer(nil)
+		err := server.Initialize(&conf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*AppServer]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	apiCall := func(server *AppServer, method string, path string, body string, apiKey string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.HEADER_API_KEY, apiKey)
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	runTest("list and replay dead letters", func(t *testing.T, server *AppServer) {
+		w := apiCall(server, "POST", "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user1", "score": 10 }`, "admin-key-0123456789")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var result controllers.GetDeadLettersResultSuccess
+		require.Eventually(t, func() bool {
+			w = apiCall(server, "POST", "/admin/GetDeadLetters", `{ "endpoint": "rewards", "limit": 10 }`, "admin-key-0123456789")
+			require.Equal(t, http.StatusOK, w.Code)
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+			return len(result.Result) == 2 // entered_top and took_first
+		}, time.Second, time.Millisecond)
+		require.Equal(t, uint32(2), result.Result[0].Attempts)
+		require.Equal(t, "unexpected status 503", result.Result[0].Error)
+
+		w = apiCall(server, "GET", "/v2/webhooks/rewards/deadletters?limit=1", "", "admin-key-0123456789")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		require.Len(t, result.Result, 1)
+
+		// keys of tenants don't see events of other tenants
+		w = apiCall(server, "GET", "/v2/webhooks/rewards/deadletters?limit=1", "", "studio1-key-0123456789")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result":[]}`, w.Body.String())
+		w = apiCall(server, "POST", "/v2/webhooks/rewards/deadletters/replay", fmt.Sprintf(`{ "ids": ["%s"] }`, result.Result[0].Id),
+			"studio1-key-0123456789")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result":{"replayed":0}}`, w.Body.String())
+		w = apiCall(server, "GET", "/v2/webhooks/unknown/deadletters?limit=1", "", "admin-key-0123456789")
+		require.Equal(t, http.StatusNotFound, w.Code)
+
+		failing.Store(false)
+		w = apiCall(server, "POST", "/v2/webhooks/rewards/deadletters/replay", fmt.Sprintf(`{ "ids": ["%s"] }`, result.Result[0].Id),
+			"admin-key-0123456789")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result":{"replayed":1}}`, w.Body.String())
+		w = apiCall(server, "POST", "/admin/ReplayDeadLetters", `{ "endpoint": "rewards" }`, "admin-key-0123456789")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result":{"replayed":1}}`, w.Body.String())
+
+		types := []string{}
+		for i := 0; i < 2; i++ {
+			select {
+			case event := <-received:
+				require.Equal(t, "user1", event.UserId)
+				types = append(types, event.Type)
+			case <-time.After(time.Second):
+				require.FailNow(t, "no webhook")
+			}
+		}
+		require.ElementsMatch(t, []string{config.WEBHOOKEVENT_ENTERED_TOP, config.WEBHOOKEVENT_TOOK_FIRST}, types)
+	})
+}
+
+func TestServerGrpc(t *testing.T) {
+	conf := *config.GetAppConfig()
+	conf.Auth = &config.AuthConfig{
+		Keys: []config.ApiKeyConfig{
+			{Key: "admin-key-0123456789", Role: config.ROLE_ADMIN, Games: []string{"*"}},
+			{Key: "client-key-0123456789", Role: config.ROLE_CLIENT, UserId: "user1", Games: []string{"game1", "signedgame"}},
+		},
+	}
+	conf.Grpc = &config.GrpcConfig{Port: "8416"}
+
+	setupTest := func() (func() error, *AppServer, error) {
+		server := NewAppServer(nil)
+		err := server.Initialize(&conf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*AppServer]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	newClient := func(t *testing.T, server *AppServer) (leaderboardpb.LeaderboardClient, *grpc.ClientConn) {
+		listener := bufconn.Listen(1024 * 1024)
+		go func() {
+			_ = server.grpcServer.Serve(listener)
+		}()
+		conn, err := grpc.DialContext(context.Background(), "bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		require.NoError(t, err)
+		return leaderboardpb.NewLeaderboardClient(conn), conn
+	}
+
+	withKey := func(apiKey string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), grpcapi.METADATA_API_KEY, apiKey)
+	}
+
+	runTest("manage scores", func(t *testing.T, server *AppServer) {
+		client, conn := newClient(t, server)
+		defer conn.Close()
+		clientCtx := withKey("client-key-0123456789")
+		adminCtx := withKey("admin-key-0123456789")
+
+		_, err := client.SendScore(context.Background(), &leaderboardpb.SendScoreRequest{GameId: "game1", UserId: "user1", Score: 10})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		_, err = client.SendScore(clientCtx, &leaderboardpb.SendScoreRequest{GameId: "game1", UserId: "user2", Score: 10})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+		_, err = client.SendScore(clientCtx, &leaderboardpb.SendScoreRequest{GameId: "game_1", UserId: "user1", Score: 10})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = client.SendScore(clientCtx, &leaderboardpb.SendScoreRequest{GameId: "signedgame", UserId: "user1", Score: 10})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		resp, err := client.SendScore(clientCtx, &leaderboardpb.SendScoreRequest{GameId: "game1", UserId: "user1", Score: 10, Name: "John"})
+		require.NoError(t, err)
+		require.False(t, resp.Quarantined)
+
+		score, err := client.GetScore(clientCtx, &leaderboardpb.GetScoreRequest{GameId: "game1", UserId: "user1"})
+		require.NoError(t, err)
+		require.Equal(t, 10.0, score.User.Score)
+		require.Equal(t, "John", score.User.Name)
+
+		// data is shared with the HTTP API
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/leaderboard/GetScore", bytes.NewBuffer([]byte(`{ "gameId": "game1", "userId": "user1" }`)))
+		req.Header.Set(middleware.HEADER_API_KEY, "client-key-0123456789")
+		server.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result": { "score": 10, "name": "John" } }`, w.Body.String())
+
+		top, err := client.GetTop(clientCtx, &leaderboardpb.GetTopRequest{GameId: "game1", NTop: 10})
+		require.NoError(t, err)
+		require.Len(t, top.Entries, 1)
+		require.Equal(t, "user1", top.Entries[0].UserId)
+
+		_, err = client.DeleteScore(clientCtx, &leaderboardpb.DeleteScoreRequest{GameId: "game1", UserId: "user1"})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+		_, err = client.DeleteScore(adminCtx, &leaderboardpb.DeleteScoreRequest{GameId: "game1", UserId: "user1"})
+		require.NoError(t, err)
+		_, err = client.DeleteScore(adminCtx, &leaderboardpb.DeleteScoreRequest{GameId: "game1", UserId: "user1"})
+		require.Equal(t, codes.NotFound, status.Code(err))
+		_, err = client.GetScore(clientCtx, &leaderboardpb.GetScoreRequest{GameId: "game1", UserId: "user1"})
+		require.Equal(t, codes.NotFound, status.Code(err))
+
+		// scores of hidden users are returned only to the users and admins
+		_, err = client.SendScore(adminCtx, &leaderboardpb.SendScoreRequest{GameId: "game1", UserId: "user2", Score: 20})
+		require.NoError(t, err)
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/admin/SetUserState", bytes.NewBuffer([]byte(`{ "gameId": "game1", "userId": "user2", "state": "shadowbanned" }`)))
+		req.Header.Set(middleware.HEADER_API_KEY, "admin-key-0123456789")
+		server.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		_, err = client.GetScore(clientCtx, &leaderboardpb.GetScoreRequest{GameId: "game1", UserId: "user2"})
+		require.Equal(t, codes.NotFound, status.Code(err))
+		score, err = client.GetScore(adminCtx, &leaderboardpb.GetScoreRequest{GameId: "game1", UserId: "user2"})
+		require.NoError(t, err)
+		require.Equal(t, 20.0, score.User.Score)
+	})
+
+	runTest("watch top", func(t *testing.T, server *AppServer) {
+		client, conn := newClient(t, server)
+		defer conn.Close()
+		ctx, cancel := context.WithCancel(withKey("admin-key-0123456789"))
+		defer cancel()
+
+		stream, err := client.WatchTop(ctx, &leaderboardpb.GetTopRequest{GameId: "game1", NTop: 10})
+		require.NoError(t, err)
+		top, err := stream.Recv()
+		require.NoError(t, err)
+		require.Empty(t, top.Entries)
+
+		_, err = client.SendScore(ctx, &leaderboardpb.SendScoreRequest{GameId: "game1", UserId: "user1", Score: 10})
+		require.NoError(t, err)
+		top, err = stream.Recv()
+		require.NoError(t, err)
+		require.Len(t, top.Entries, 1)
+		require.Equal(t, 10.0, top.Entries[0].Score)
+
+		_, err = client.SendScore(ctx, &leaderboardpb.SendScoreRequest{GameId: "game1", UserId: "user1", Score: 20})
+		require.NoError(t, err)
+		top, err = stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, 20.0, top.Entries[0].Score)
+	})
+
+	limitConf := conf
+	limitConf.RateLimit = &config.RateLimitConfig{
+		Type:   config.RATELIMITTYPE_MEMORY,
+		Config: &ratelimit_memory_provider.RateLimitMemoryProviderConfig{},
+		Groups: map[string]config.RateLimitGroupConfig{
+			config.ROUTEGROUP_LEADERBOARD: {ApiKey: &ratelimitprovider.Limit{Burst: 3, Rate: 0.001}},
+		},
+	}
+	limitConf.Idempotency = &config.IdempotencyConfig{
+		Type:        config.IDEMPOTENCYTYPE_MEMORY,
+		Config:      &idempotency_memory_provider.IdempotencyMemoryProviderConfig{},
+		Window:      60000,
+		LockTimeout: 1000,
+	}
+
+	setupLimitTest := func() (func() error, *AppServer, error) {
+		server := NewAppServer(nil)
+		err := server.Initialize(&limitConf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	utils.RunTest(t, "limit and deduplicate calls", setupLimitTest, func(t *testing.T, server *AppServer) {
+		client, conn := newClient(t, server)
+		defer conn.Close()
+		keyCtx := metadata.AppendToOutgoingContext(withKey("client-key-0123456789"), grpcapi.METADATA_IDEMPOTENCY_KEY, "key1")
+
+		var header metadata.MD
+		_, err := client.SendScore(keyCtx, &leaderboardpb.SendScoreRequest{GameId: "game1", UserId: "user1", Score: 10}, grpc.Header(&header))
+		require.NoError(t, err)
+		require.Empty(t, header.Get(grpcapi.METADATA_IDEMPOTENCY_REPLAYED))
+
+		_, err = client.SendScore(keyCtx, &leaderboardpb.SendScoreRequest{GameId: "game1", UserId: "user1", Score: 10}, grpc.Header(&header))
+		require.NoError(t, err)
+		require.Equal(t, []string{"true"}, header.Get(grpcapi.METADATA_IDEMPOTENCY_REPLAYED))
+
+		_, err = client.SendScore(keyCtx, &leaderboardpb.SendScoreRequest{GameId: "game1", UserId: "user1", Score: 20})
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		var trailer metadata.MD
+		_, err = client.GetScore(withKey("client-key-0123456789"), &leaderboardpb.GetScoreRequest{GameId: "game1", UserId: "user1"}, grpc.Trailer(&trailer))
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+		require.NotEmpty(t, trailer.Get(grpcapi.METADATA_RETRY_AFTER))
+
+		// other keys have limits of their own, the score is stored once
+		score, err := client.GetScore(withKey("admin-key-0123456789"), &leaderboardpb.GetScoreRequest{GameId: "game1", UserId: "user1"})
+		require.NoError(t, err)
+		require.Equal(t, 10.0, score.User.Score)
+	})
+}
+
+func TestServerEncodings(t *testing.T) {
+	conf := *config.GetAppConfig()
+	conf.Boards = map[string]config.BoardConfig{
+		"signedgame": {Secrets: []string{"test-secret-0123456789"}},
+	}
+	conf.Changes = &config.ChangesConfig{Retention: 10, MaxWait: 1000, PollInterval: 1000}
+
+	setupTest := func() (func() error, *AppServer, error) {
+		server := NewAppServer(nil)
+		err := server.Initialize(&conf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*AppServer]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	msgpackHandle := &codec.MsgpackHandle{}
+	msgpackHandle.MapType = reflect.TypeOf(map[string]interface{}(nil))
+	msgpackHandle.RawToString = true
+
+	// encodes the JSON body in the media type (as the message for protobuf)
+	encode := func(t *testing.T, mimeType string, body string, message proto.Message) []byte {
+		var encoded []byte
+		if mimeType == middleware.MIME_XPROTOBUF {
+			require.NoError(t, protojson.Unmarshal([]byte(body), message))
+			encoded, err := proto.Marshal(message)
+			require.NoError(t, err)
+			return encoded
+		}
+
+		var value map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(body), &value))
+		require.NoError(t, codec.NewEncoderBytes(&encoded, msgpackHandle).Encode(value))
+		return encoded
+	}
+
+	// returns the body of the media type (the message for protobuf) as JSON
+	decode := func(t *testing.T, mimeType string, body []byte, message proto.Message) string {
+		if mimeType == middleware.MIME_XPROTOBUF {
+			require.NoError(t, proto.Unmarshal(body, message))
+			decoded, err := protojson.Marshal(message)
+			require.NoError(t, err)
+			return string(decoded)
+		}
+
+		var value interface{}
+		require.NoError(t, codec.NewDecoderBytes(body, msgpackHandle).Decode(&value))
+		decoded, err := json.Marshal(value)
+		require.NoError(t, err)
+		return string(decoded)
+	}
+
+	apiCall := func(server *AppServer, method string, path string, mimeType string, body []byte, headers map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		var bbuf io.Reader
+		if body != nil {
+			bbuf = bytes.NewBuffer(body)
+		}
+		req, _ := http.NewRequest(method, path, bbuf)
+		req.Header.Set("Content-Type", mimeType)
+		req.Header.Set("Accept", mimeType)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, mimeType := range []string{middleware.MIME_MSGPACK, middleware.MIME_XPROTOBUF} {
+		runTest(fmt.Sprintf("send and read scores (%s)", mimeType), func(t *testing.T, server *AppServer) {
+			var w *httptest.ResponseRecorder
+
+			w = apiCall(server, "POST", "/leaderboard/SendScore", mimeType,
+				encode(t, mimeType, `{ "gameId": "game1", "userId": "user1", "score": 24, "name": "John", "params": "payload" }`, &dtopb.SendScoreParams{}), nil)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, mimeType, w.Header().Get("Content-Type"))
+			require.JSONEq(t, `{"result": "success"}`, decode(t, mimeType, w.Body.Bytes(), &dtopb.ResultSuccess{}))
+
+			w = apiCall(server, "PUT", "/v2/games/game1/users/user2", mimeType, encode(t, mimeType, `{ "score": 83.5 }`, &dtopb.SendScoreParams{}), nil)
+			require.Equal(t, http.StatusCreated, w.Code)
+
+			w = apiCall(server, "POST", "/leaderboard/GetTop", mimeType, encode(t, mimeType, `{ "gameId": "game1", "nTop": 10 }`, &dtopb.GetTopParams{}), nil)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.JSONEq(t, `{"result": [
+				{ "userId": "user2", "score": 83.5 },
+				{ "userId": "user1", "score": 24, "name": "John", "params": "payload" }
+			]}`, decode(t, mimeType, w.Body.Bytes(), &dtopb.GetTopResultSuccess{}))
+
+			w = apiCall(server, "GET", "/v2/games/game1/users/user1", mimeType, nil, nil)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.JSONEq(t, `{"result": { "score": 24, "name": "John", "params": "payload" } }`,
+				decode(t, mimeType, w.Body.Bytes(), &dtopb.GetScoreResultSuccess{}))
+		})
+
+		runTest(fmt.Sprintf("validate as json (%s)", mimeType), func(t *testing.T, server *AppServer) {
+			bodies := []string{
+				`{ "userId": "user1", "score": 10 }`,
+				`{ "gameId": "game1", "userId": "user1", "score": "10" }`,
+				`{ "gameId": "game1", "userId": "user1", "score": 10, "name": 5 }`,
+			}
+			if mimeType == middleware.MIME_XPROTOBUF {
+				// types of the fields are checked by the message
+				bodies = bodies[:1]
+			}
+			for _, body := range bodies {
+				jsonReq, _ := http.NewRequest("POST", "/leaderboard/SendScore", bytes.NewBufferString(body))
+				jsonReq.Header.Set("Content-Type", "application/json")
+				jw := httptest.NewRecorder()
+				server.router.ServeHTTP(jw, jsonReq)
+
+				w := apiCall(server, "POST", "/leaderboard/SendScore", mimeType, encode(t, mimeType, body, &dtopb.SendScoreParams{}), nil)
+				require.Equal(t, jw.Code, w.Code, body)
+				require.Equal(t, http.StatusBadRequest, w.Code, body)
+				require.JSONEq(t, jw.Body.String(), decode(t, mimeType, w.Body.Bytes(), &dtopb.ResultError{}), body)
+			}
+
+			w := apiCall(server, "POST", "/leaderboard/SendScore", mimeType, []byte{0xc1, 0xff, 0x00}, nil)
+			require.Equal(t, http.StatusBadRequest, w.Code)
+			require.Equal(t, mimeType, w.Header().Get("Content-Type"))
+
+			w = apiCall(server, "GET", "/unknown", mimeType, nil, nil)
+			require.Equal(t, http.StatusNotFound, w.Code)
+			require.JSONEq(t, `{"error": "Not found"}`, decode(t, mimeType, w.Body.Bytes(), &dtopb.ResultError{}))
+		})
+
+		runTest(fmt.Sprintf("reject signed bodies (%s)", mimeType), func(t *testing.T, server *AppServer) {
+			ts := strconv.FormatInt(server.clock.Now().UnixMilli(), 10)
+			sig, _ := services.Sign("test-secret-0123456789", ts, "nonce1", []byte(`{ "gameId": "signedgame", "userId": "user1", "score": 10 }`))
+			headers := map[string]string{
+				middleware.HEADER_SIGNATURE_TIMESTAMP: ts,
+				middleware.HEADER_SIGNATURE_NONCE:     "nonce1",
+				middleware.HEADER_SIGNATURE:           sig,
+			}
+			body := encode(t, mimeType, `{ "gameId": "signedgame", "userId": "user1", "score": 10 }`, &dtopb.SendScoreParams{})
+
+			// the signature covers JSON bodies only
+			w := apiCall(server, "POST", "/leaderboard/SendScore", mimeType, body, headers)
+			require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+			w = apiCall(server, "POST", "/leaderboard/SendScore", mimeType,
+				encode(t, mimeType, `{ "gameId": "game1", "userId": "user1", "score": 10 }`, &dtopb.SendScoreParams{}), nil)
+			require.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+
+	runTest("typed protobuf messages", func(t *testing.T, server *AppServer) {
+		w := apiCall(server, "POST", "/leaderboard/SendScore", middleware.MIME_XPROTOBUF,
+			encode(t, middleware.MIME_XPROTOBUF, `{ "gameId": "game1", "userId": "user1", "score": 24 }`, &dtopb.SendScoreParams{}), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = apiCall(server, "POST", "/leaderboard/GetChanges", middleware.MIME_XPROTOBUF,
+			encode(t, middleware.MIME_XPROTOBUF, `{ "gameId": "game1", "limit": 10 }`, &dtopb.GetChangesParams{}), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var result dtopb.GetChangesResultSuccess
+		require.NoError(t, proto.Unmarshal(w.Body.Bytes(), &result))
+		require.Equal(t, uint64(1), result.Result.Version)
+		require.Len(t, result.Result.Changes, 1)
+		require.Equal(t, "user1", result.Result.Changes[0].UserId)
+		require.Equal(t, 24.0, result.Result.Changes[0].Score)
+		require.InDelta(t, time.Now().UnixMilli(), result.Result.Changes[0].Ts, 60000)
+
+		// routes without a request message don't accept bodies
+		w = apiCall(server, "GET", "/Status", middleware.MIME_XPROTOBUF, []byte{0x0a, 0x01, 0x61}, nil)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	runTest("json by default", func(t *testing.T, server *AppServer) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/leaderboard/GetTop", bytes.NewBufferString(`{ "gameId": "game1", "nTop": 10 }`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "*/*")
+		server.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+		require.JSONEq(t, `{"result": []}`, w.Body.String())
+	})
+}
+
+func TestServerHttpCaching(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	conf := *config.GetAppConfig()
+	require.NotNil(t, conf.Cache.Config, "the test config has no cache")
+	ttl := conf.Cache.Config.GetBaseConfig().Ttl
+	require.NotZero(t, ttl, "the test config has no cache ttl")
+
+	setupTest := func() (func() error, *AppServer, error) {
+		clock := &utils.MockClock{}
+		clock.SetTime(now)
+		server := NewAppServer(clock)
+		err := server.Initialize(&conf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*AppServer]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	apiCall := func(server *AppServer, method string, path string, body string, headers map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		var bbuf io.Reader
+		if body != "" {
+			bbuf = bytes.NewBuffer([]byte(body))
+		}
+		req, _ := http.NewRequest(method, path, bbuf)
+		req.Header.Set("Content-Type", "application/json")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	runTest("conditional top reads", func(t *testing.T, server *AppServer) {
+		var w *httptest.ResponseRecorder
+
+		w = apiCall(server, "PUT", "/v2/games/game1/users/user1", `{ "score": 10 }`, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		w = apiCall(server, "GET", "/v2/games/game1/top?n=10", "", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		etag := w.Header().Get("ETag")
+		require.Regexp(t, `^W/"[0-9a-f]{32}"$`, etag)
+		require.Equal(t, fmt.Sprintf("public, max-age=%d", ttl/1000), w.Header().Get("Cache-Control"))
+		require.Equal(t, now.UTC().Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+
+		// the cached top keeps its times, the lifetime decreases
+		server.clock.(*utils.MockClock).SetTime(now.Add(time.Duration(ttl/2) * time.Millisecond))
+		w = apiCall(server, "GET", "/v2/games/game1/top?n=10", "", map[string]string{"If-None-Match": etag})
+		require.Equal(t, http.StatusNotModified, w.Code)
+		require.Empty(t, w.Body.String())
+		require.Equal(t, etag, w.Header().Get("ETag"))
+		require.Equal(t, fmt.Sprintf("public, max-age=%d", ttl/2/1000), w.Header().Get("Cache-Control"))
+		require.Equal(t, now.UTC().Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+
+		w = apiCall(server, "GET", "/v2/games/game1/top?n=10", "", map[string]string{"If-None-Match": `"other", ` + strings.TrimPrefix(etag, "W/")})
+		require.Equal(t, http.StatusNotModified, w.Code)
+
+		// v1 reads get the same headers, but POST requests are never conditional
+		w = apiCall(server, "POST", "/leaderboard/GetTop", `{ "gameId": "game1", "nTop": 10 }`, map[string]string{"If-None-Match": etag})
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, etag, w.Header().Get("ETag"))
+
+		// other encodings have the same ETag
+		w = apiCall(server, "GET", "/v2/games/game1/top?n=10", "", map[string]string{"If-None-Match": etag, "Accept": middleware.MIME_MSGPACK})
+		require.Equal(t, http.StatusNotModified, w.Code)
+		require.Empty(t, w.Body.String())
+
+		w = apiCall(server, "PUT", "/v2/games/game1/users/user2", `{ "score": 20 }`, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		// the new top is read once the cached top expires
+		server.clock.(*utils.MockClock).SetTime(now.Add(time.Duration(ttl) * time.Millisecond))
+		w = apiCall(server, "GET", "/v2/games/game1/top?n=10", "", map[string]string{"If-None-Match": etag})
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NotEqual(t, etag, w.Header().Get("ETag"))
+		require.JSONEq(t, `{"result": [{ "userId": "user2", "score": 20 }, { "userId": "user1", "score": 10 }]}`, w.Body.String())
+	})
+
+	runTest("conditional score reads", func(t *testing.T, server *AppServer) {
+		var w *httptest.ResponseRecorder
+
+		w = apiCall(server, "PUT", "/v2/games/game1/users/user1", `{ "score": 10 }`, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		w = apiCall(server, "GET", "/v2/games/game1/users/user1", "", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		etag := w.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+		require.Equal(t, "private, max-age=0", w.Header().Get("Cache-Control"))
+		require.Equal(t, now.UTC().Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+
+		w = apiCall(server, "GET", "/v2/games/game1/users/user1", "", map[string]string{"If-None-Match": etag})
+		require.Equal(t, http.StatusNotModified, w.Code)
+		require.Empty(t, w.Body.String())
+
+		later := now.Add(time.Minute)
+		server.clock.(*utils.MockClock).SetTime(later)
+		w = apiCall(server, "PUT", "/v2/games/game1/users/user1", `{ "score": 15 }`, nil)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = apiCall(server, "GET", "/v2/games/game1/users/user1", "", map[string]string{"If-None-Match": etag})
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"result": { "score": 15 } }`, w.Body.String())
+		require.Equal(t, later.UTC().Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+
+		w = apiCall(server, "GET", "/v2/games/game1/users/user2", "", map[string]string{"If-None-Match": "*"})
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Empty(t, w.Header().Get("ETag"))
+	})
+}
+
+func TestServerHttpCompression(t *testing.T) {
+	conf := *config.GetAppConfig()
+	conf.Http = config.HttpConfig{
+		Compression:  &config.CompressionConfig{MinSize: 1024},
+		MaxBodySize:  1024,
+		MaxBodySizes: map[string]uint32{"PUT /v2/games/:gameId/users/:userId": 64},
+	}
+
+	setupTest := func() (func() error, *AppServer, error) {
+		server := NewAppServer(nil)
+		err := server.Initialize(&conf)
+		return func() error {
+			return server.Shutdown()
+		}, server, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*AppServer]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	apiCall := func(server *AppServer, method string, path string, body string, headers map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		var bbuf io.Reader
+		if body != "" {
+			bbuf = bytes.NewBuffer([]byte(body))
+		}
+		req, _ := http.NewRequest(method, path, bbuf)
+		req.Header.Set("Content-Type", "application/json")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	// returns the body of the response, decompressed with its Content-Encoding
+	readBody := func(t *testing.T, w *httptest.ResponseRecorder) string {
+		var reader io.Reader = w.Body
+		switch w.Header().Get("Content-Encoding") {
+		case middleware.ENCODING_GZIP:
+			gzipReader, err := gzip.NewReader(w.Body)
+			require.NoError(t, err)
+			reader = gzipReader
+		case middleware.ENCODING_BROTLI:
+			reader = brotli.NewReader(w.Body)
+		}
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	fillTop := func(t *testing.T, server *AppServer) {
+		for i := 0; i < 30; i++ {
+			w := apiCall(server, "POST", "/leaderboard/SendScore",
+				fmt.Sprintf(`{ "gameId": "game1", "userId": "user%02d", "score": %d, "name": "Player with a rather long name %02d" }`, i, i+1, i), nil)
+			require.Equal(t, http.StatusOK, w.Code)
+		}
+	}
+
+	runTest("compress large responses", func(t *testing.T, server *AppServer) {
+		fillTop(t, server)
+
+		w := apiCall(server, "GET", "/v2/games/game1/top?n=30", "", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, w.Header().Get("Content-Encoding"))
+		require.Greater(t, w.Body.Len(), 1024)
+		plain := w.Body.String()
+
+		for acceptEncoding, encoding := range map[string]string{
+			"gzip":                    middleware.ENCODING_GZIP,
+			"br":                      middleware.ENCODING_BROTLI,
+			"gzip, deflate, br":       middleware.ENCODING_BROTLI,
+			"gzip;q=1.0, br;q=0.5":    middleware.ENCODING_GZIP,
+			"br;q=0, gzip;q=0.1":      middleware.ENCODING_GZIP,
+			"*":                       middleware.ENCODING_BROTLI,
+			"deflate, identity":       "",
+			"gzip;q=0, br;q=0, *;q=0": "",
+		} {
+			w = apiCall(server, "GET", "/v2/games/game1/top?n=30", "", map[string]string{"Accept-Encoding": acceptEncoding})
+			require.Equal(t, http.StatusOK, w.Code, acceptEncoding)
+			require.Equal(t, encoding, w.Header().Get("Content-Encoding"), acceptEncoding)
+			require.Contains(t, w.Header().Values("Vary"), "Accept-Encoding")
+			require.Equal(t, plain, readBody(t, w), acceptEncoding)
+			if encoding != "" {
+				require.Less(t, w.Body.Len(), len(plain))
+			}
+		}
+
+		// encoded responses are compressed too
+		w = apiCall(server, "POST", "/leaderboard/GetTop", `{ "gameId": "game1", "nTop": 30 }`,
+			map[string]string{"Accept-Encoding": "gzip", "Accept": middleware.MIME_MSGPACK})
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, middleware.MIME_MSGPACK, w.Header().Get("Content-Type"))
+		require.Equal(t, middleware.ENCODING_GZIP, w.Header().Get("Content-Encoding"))
+		require.NotEmpty(t, readBody(t, w))
+	})
+
+	runTest("small responses are not compressed", func(t *testing.T, server *AppServer) {
+		w := apiCall(server, "POST", "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user1", "score": 10 }`,
+			map[string]string{"Accept-Encoding": "gzip, br"})
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, w.Header().Get("Content-Encoding"))
+		require.JSONEq(t, `{"result": "success"}`, w.Body.String())
+
+		w = apiCall(server, "GET", "/v2/games/game1/users/user2", "", map[string]string{"Accept-Encoding": "gzip, br"})
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Empty(t, w.Header().Get("Content-Encoding"))
+		require.Contains(t, w.Body.String(), `"error"`)
+	})
+
+	runTest("event streams are not compressed", func(t *testing.T, server *AppServer) {
+		httpServer := httptest.NewServer(server.router)
+		defer httpServer.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		req, _ := http.NewRequestWithContext(ctx, "GET", httpServer.URL+"/v2/games/game1/top/subscribe?n=2", nil)
+		req.Header.Set("Accept-Encoding", "gzip, br")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		require.Empty(t, resp.Header.Get("Content-Encoding"))
+
+		line, err := bufio.NewReader(resp.Body).ReadString('\n')
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(line, "event:"), line)
+	})
+
+	runTest("request bodies over the limit => error 413", func(t *testing.T, server *AppServer) {
+		var w *httptest.ResponseRecorder
+
+		name := strings.Repeat("a", 1024)
+		w = apiCall(server, "POST", "/leaderboard/SendScore",
+			fmt.Sprintf(`{ "gameId": "game1", "userId": "user1", "score": 10, "name": "%s" }`, name), nil)
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		require.JSONEq(t, fmt.Sprintf(`{"error": "%s"}`, middleware.ErrBodyTooLarge), w.Body.String())
+
+		// the body is read to the limit when its length is unknown
+		w = httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/leaderboard/SendScore",
+			io.MultiReader(strings.NewReader(fmt.Sprintf(`{ "gameId": "game1", "userId": "user1", "score": 10, "name": "%s" }`, name))))
+		req.Header.Set("Content-Type", "application/json")
+		require.Equal(t, int64(0), req.ContentLength)
+		server.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+		// the route limit overrides the default one
+		w = apiCall(server, "PUT", "/v2/games/game1/users/user1", `{ "score": 10, "name": "Player with a rather long name", "params": "payload" }`, nil)
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+		w = apiCall(server, "PUT", "/v2/games/game1/users/user1", `{ "score": 10, "name": "Player" }`, nil)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		w = apiCall(server, "POST", "/leaderboard/SendScore", `{ "gameId": "game1", "userId": "user2", "score": 20, "name": "Player" }`, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
 }
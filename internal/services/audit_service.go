@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	auditsink "go-leaderboard-server/internal/audit"
+	audit_db_sink "go-leaderboard-server/internal/audit/db"
+	audit_file_sink "go-leaderboard-server/internal/audit/file"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	"go-leaderboard-server/internal/utils"
+	"sync"
+)
+
+var ErrAuditDisabled = errors.New("audit is disabled")
+
+const (
+	AUDITACTION_DELETE_SCORE        = "delete_score"
+	AUDITACTION_SET_USER_STATE      = "set_user_state"
+	AUDITACTION_APPROVE_QUARANTINED = "approve_quarantined"
+	AUDITACTION_REJECT_QUARANTINED  = "reject_quarantined"
+)
+
+// Number of attempts to append an entry when other server instances append entries at the same time
+const auditAppendAttempts = 5
+
+// Number of entries read from the sink at once by List
+const auditPageSize = 100
+
+// Operation to record in the audit log
+type AuditRecord struct {
+	Actor     string
+	Action    string // AUDITACTION_*
+	Tenant    string // Id of tenant of the game (empty - default tenant)
+	GameId    string // Id of game of the tenant
+	UserId    string
+	Before    any // Affected data before the operation, stored as JSON (nil - none)
+	After     any // Affected data after the operation, stored as JSON (nil - none)
+	RequestId string
+}
+
+// Records operations to the audit log. Every entry contains the hash of the previous one,
+// so a changed, removed or inserted entry breaks the chain. Hashes are HMACs with the audit key,
+// so the chain can't be rebuilt by someone who can write to the sink, but doesn't know the key
+type AuditService struct {
+	config     *config.Config
+	dbprovider dbprovider.IDbProvider
+	clock      *utils.IClock
+	sink       auditsink.IAuditSink
+	mutex      sync.Mutex
+}
+
+func NewAuditService(config *config.Config, dbProvider dbprovider.IDbProvider) *AuditService {
+	return &AuditService{
+		config:     config,
+		dbprovider: dbProvider,
+	}
+}
+
+func (s *AuditService) Initialize(ctx context.Context, clock *utils.IClock) error {
+	logger.Debug("Audit service initialization")
+
+	s.clock = clock
+
+	if !s.IsEnabled() {
+		return nil
+	}
+
+	switch s.config.Audit.Type {
+	case config.AUDITSINKTYPE_FILE:
+		s.sink = audit_file_sink.NewAuditFileSink()
+	case config.AUDITSINKTYPE_DB:
+		s.sink = audit_db_sink.NewAuditDbSink(s.dbprovider)
+	default:
+		return errors.New("unknown Audit sink type")
+	}
+
+	return s.sink.Initialize(ctx, s.config.Audit.Config)
+}
+
+func (s *AuditService) IsEnabled() bool {
+	return s.config.Audit != nil
+}
+
+// Appends the operation to the log (does nothing if the audit is disabled)
+func (s *AuditService) Record(ctx context.Context, record AuditRecord) (*dbprovider.AuditEntry, error) {
+	if !s.IsEnabled() {
+		return nil, nil
+	}
+
+	before, err := marshalAuditValue(record.Before)
+	if err != nil {
+		return nil, err
+	}
+	after, err := marshalAuditValue(record.After)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := dbprovider.AuditEntry{
+		Ts:        (*s.clock).Now().UnixMilli(),
+		Actor:     record.Actor,
+		Action:    record.Action,
+		Tenant:    record.Tenant,
+		GameId:    record.GameId,
+		UserId:    record.UserId,
+		Before:    before,
+		After:     after,
+		RequestId: record.RequestId,
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		last, err := s.sink.Last(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.Seq = 1
+		entry.PrevHash = ""
+		if last != nil {
+			entry.Seq = last.Seq + 1
+			entry.PrevHash = last.Hash
+		}
+		entry.Hash = hashAuditEntry([]byte(s.config.Audit.Key), entry)
+
+		err = s.sink.Append(ctx, entry)
+		if errors.Is(err, dbprovider.ErrAuditConflict) && attempt < auditAppendAttempts {
+			continue // the sequence number has been taken by another instance
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &entry, nil
+	}
+}
+
+// Returns up to limit entries of the tenant (ALL_TENANTS - of all tenants) starting from fromSeq and whether
+// the hash chain is intact. Entries of other tenants are skipped, but the chain is checked over all read entries
+// (including the link to the entry preceding fromSeq)
+func (s *AuditService) List(ctx context.Context, tenant string, fromSeq uint64, limit uint32) ([]dbprovider.AuditEntry, bool, error) {
+	if fromSeq == 0 {
+		fromSeq = 1
+	}
+
+	startSeq := fromSeq
+	if fromSeq > 1 {
+		startSeq = fromSeq - 1 // the preceding entry is read to check the link to it
+	}
+
+	key := []byte(s.config.Audit.Key)
+	entries := []dbprovider.AuditEntry{}
+	valid := true
+	var last *dbprovider.AuditEntry // Last entry of the previous page, the next page links to it
+	for seq := startSeq; uint32(len(entries)) < limit; {
+		page, err := s.sink.List(ctx, seq, auditPageSize)
+		if err != nil {
+			return nil, false, err
+		}
+
+		chain, chainSeq := page, seq
+		if last != nil {
+			chain, chainSeq = append([]dbprovider.AuditEntry{*last}, page...), last.Seq
+		}
+		valid = valid && verifyAuditChain(key, chain, chainSeq)
+
+		for _, entry := range page {
+			if uint32(len(entries)) == limit {
+				break
+			}
+			if entry.Seq >= fromSeq && (tenant == dbprovider.ALL_TENANTS || entry.Tenant == tenant) {
+				entries = append(entries, entry)
+			}
+		}
+
+		if len(page) < auditPageSize {
+			break
+		}
+		last = &page[len(page)-1]
+		seq = last.Seq + 1
+	}
+
+	return entries, valid, nil
+}
+
+func (s *AuditService) Shutdown(ctx context.Context) error {
+	logger.Debug("Audit service shutdown")
+
+	if s.sink == nil {
+		return nil
+	}
+
+	return s.sink.Shutdown(ctx)
+}
+
+// Checks that entries go without gaps from startSeq and every entry matches its hash and links to the previous one
+func verifyAuditChain(key []byte, entries []dbprovider.AuditEntry, startSeq uint64) bool {
+	for i, entry := range entries {
+		if !hmac.Equal([]byte(entry.Hash), []byte(hashAuditEntry(key, entry))) {
+			return false
+		}
+		if i == 0 {
+			if entry.Seq != startSeq || (entry.Seq == 1 && entry.PrevHash != "") {
+				return false
+			}
+			continue
+		}
+		prev := entries[i-1]
+		if entry.Seq != prev.Seq+1 || entry.PrevHash != prev.Hash {
+			return false
+		}
+	}
+	return true
+}
+
+// HMAC-SHA256 of the entry without its hash, the previous hash is a part of the entry
+func hashAuditEntry(key []byte, entry dbprovider.AuditEntry) string {
+	entry.Hash = ""
+	data, _ := json.Marshal(entry) // fields are marshaled in the order of declaration
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func marshalAuditValue(value any) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	if string(data) == "null" {
+		return "", nil
+	}
+	return string(data), nil
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	auditsink "go-leaderboard-server/internal/audit"
+	audit_db_sink "go-leaderboard-server/internal/audit/db"
+	audit_file_sink "go-leaderboard-server/internal/audit/file"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	db_inmemory_provider "go-leaderboard-server/internal/db/inmemory"
+	"go-leaderboard-server/internal/utils"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testAuditKey = "audit-key-0123456789-0123456789-01"
+
+// Sink that appends an entry of "another instance" before the first append, so it ends with a conflict
+type racingAuditSink struct {
+	auditsink.IAuditSink
+	raced bool
+}
+
+func (s *racingAuditSink) Append(ctx context.Context, entry dbprovider.AuditEntry) error {
+	if !s.raced {
+		s.raced = true
+		other := entry
+		other.Actor = "key:other"
+		other.Hash = hashAuditEntry([]byte(testAuditKey), other)
+		err := s.IAuditSink.Append(ctx, other)
+		if err != nil {
+			return err
+		}
+	}
+	return s.IAuditSink.Append(ctx, entry)
+}
+
+func TestAuditService(t *testing.T) {
+	now := time.UnixMilli(1000000)
+
+	newService := func(t *testing.T, auditConf *config.AuditConfig, dbProvider dbprovider.IDbProvider) *AuditService {
+		var clock utils.IClock = &utils.MockClock{}
+		clock.(*utils.MockClock).SetTime(now)
+
+		service := NewAuditService(&config.Config{Audit: auditConf}, dbProvider)
+		err := service.Initialize(context.Background(), &clock)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = service.Shutdown(context.Background()) })
+		return service
+	}
+
+	newDbProvider := func(t *testing.T) dbprovider.IDbProvider {
+		dbProvider := db_inmemory_provider.NewDbInMemoryProvider()
+		err := dbProvider.Initialize(context.Background(), &db_inmemory_provider.DbInMemoryProviderConfig{})
+		require.NoError(t, err)
+		return dbProvider
+	}
+
+	t.Run("record and list entries", func(t *testing.T) {
+		ctx := context.Background()
+		service := newService(t, &config.AuditConfig{
+			Type:   config.AUDITSINKTYPE_DB,
+			Config: &audit_db_sink.AuditDbSinkConfig{},
+			Key:    testAuditKey,
+		}, newDbProvider(t))
+
+		entry, err := service.Record(ctx, AuditRecord{
+			Actor: "key:0123456789ab", Action: AUDITACTION_DELETE_SCORE, GameId: "game1", UserId: "user1",
+			Before: &dbprovider.UserProperties{Score: 10}, RequestId: "req1",
+		})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), entry.Seq)
+		require.Equal(t, now.UnixMilli(), entry.Ts)
+		require.Equal(t, `{"score":10}`, entry.Before)
+		require.Empty(t, entry.After)
+		require.Empty(t, entry.PrevHash)
+
+		var noScore *dbprovider.UserProperties
+		for i := 0; i < 3; i++ {
+			_, err = service.Record(ctx, AuditRecord{Actor: "sub:admin1", Action: AUDITACTION_DELETE_SCORE, GameId: "game1", UserId: "user2", Before: noScore})
+			require.NoError(t, err)
+		}
+
+		entries, valid, err := service.List(ctx, dbprovider.ALL_TENANTS, 0, 10)
+		require.NoError(t, err)
+		require.True(t, valid)
+		require.Len(t, entries, 4)
+		require.Equal(t, *entry, entries[0])
+		require.Empty(t, entries[1].Before)
+		for i := 1; i < len(entries); i++ {
+			require.Equal(t, uint64(i+1), entries[i].Seq)
+			require.Equal(t, entries[i-1].Hash, entries[i].PrevHash)
+		}
+
+		entries, valid, err = service.List(ctx, dbprovider.ALL_TENANTS, 3, 1)
+		require.NoError(t, err)
+		require.True(t, valid)
+		require.Len(t, entries, 1)
+		require.Equal(t, uint64(3), entries[0].Seq)
+	})
+
+	t.Run("list entries of a tenant", func(t *testing.T) {
+		ctx := context.Background()
+		service := newService(t, &config.AuditConfig{
+			Type:   config.AUDITSINKTYPE_DB,
+			Config: &audit_db_sink.AuditDbSinkConfig{},
+			Key:    testAuditKey,
+		}, newDbProvider(t))
+
+		// entries of the tenant are spread over more than one page of the sink
+		for i := 1; i <= auditPageSize+20; i++ {
+			record := AuditRecord{Actor: "key:0123456789ab", Action: AUDITACTION_DELETE_SCORE, GameId: "game1", UserId: "user1"}
+			if i == 5 || i == auditPageSize+10 {
+				record.Tenant = "studio1"
+			}
+			_, err := service.Record(ctx, record)
+			require.NoError(t, err)
+		}
+
+		entries, valid, err := service.List(ctx, "studio1", 0, 10)
+		require.NoError(t, err)
+		require.True(t, valid)
+		require.Len(t, entries, 2)
+		require.Equal(t, uint64(5), entries[0].Seq)
+		require.Equal(t, uint64(auditPageSize+10), entries[1].Seq)
+		require.Equal(t, "studio1", entries[1].Tenant)
+
+		entries, valid, err = service.List(ctx, "studio1", 6, 10)
+		require.NoError(t, err)
+		require.True(t, valid)
+		require.Len(t, entries, 1)
+		require.Equal(t, uint64(auditPageSize+10), entries[0].Seq)
+
+		entries, valid, err = service.List(ctx, "", 0, 10)
+		require.NoError(t, err)
+		require.True(t, valid)
+		require.Len(t, entries, 10)
+		require.Equal(t, uint64(11), entries[9].Seq) // the entry of the other tenant is skipped
+
+		entries, _, err = service.List(ctx, "studio2", 0, 10)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("retry on conflict", func(t *testing.T) {
+		ctx := context.Background()
+		service := newService(t, &config.AuditConfig{
+			Type:   config.AUDITSINKTYPE_DB,
+			Config: &audit_db_sink.AuditDbSinkConfig{},
+			Key:    testAuditKey,
+		}, newDbProvider(t))
+		service.sink = &racingAuditSink{IAuditSink: service.sink}
+
+		entry, err := service.Record(ctx, AuditRecord{Actor: "key:0123456789ab", Action: AUDITACTION_SET_USER_STATE, GameId: "game1", UserId: "user1"})
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), entry.Seq)
+
+		entries, valid, err := service.List(ctx, dbprovider.ALL_TENANTS, 0, 10)
+		require.NoError(t, err)
+		require.True(t, valid)
+		require.Equal(t, "key:other", entries[0].Actor)
+		require.Equal(t, *entry, entries[1])
+	})
+
+	t.Run("detect tampering", func(t *testing.T) {
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "audit.log")
+		auditConf := &config.AuditConfig{
+			Type:   config.AUDITSINKTYPE_FILE,
+			Config: &audit_file_sink.AuditFileSinkConfig{Path: path},
+			Key:    testAuditKey,
+		}
+
+		service := newService(t, auditConf, nil)
+		for _, userId := range []string{"user1", "user2", "user3"} {
+			_, err := service.Record(ctx, AuditRecord{Actor: "key:0123456789ab", Action: AUDITACTION_DELETE_SCORE, GameId: "game1", UserId: userId})
+			require.NoError(t, err)
+		}
+		require.NoError(t, service.Shutdown(ctx))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 3)
+
+		// changed entry
+		var entry dbprovider.AuditEntry
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+		entry.UserId = "user4"
+		changed, _ := json.Marshal(entry)
+		require.NoError(t, os.WriteFile(path, []byte(lines[0]+"\n"+string(changed)+"\n"+lines[2]+"\n"), 0600))
+
+		service = newService(t, auditConf, nil)
+		_, valid, err := service.List(ctx, dbprovider.ALL_TENANTS, 0, 10)
+		require.NoError(t, err)
+		require.False(t, valid)
+		_, valid, err = service.List(ctx, dbprovider.ALL_TENANTS, 3, 10) // the link to the changed entry is checked too
+		require.NoError(t, err)
+		require.False(t, valid)
+		require.NoError(t, service.Shutdown(ctx))
+
+		// removed entry
+		require.NoError(t, os.WriteFile(path, []byte(lines[0]+"\n"+lines[2]+"\n"), 0600))
+
+		service = newService(t, auditConf, nil)
+		_, valid, err = service.List(ctx, dbprovider.ALL_TENANTS, 0, 10)
+		require.NoError(t, err)
+		require.False(t, valid)
+		require.NoError(t, service.Shutdown(ctx))
+
+		// changed entry with the chain rebuilt without the key
+		forged := ""
+		prevHash := ""
+		for i, line := range lines {
+			require.NoError(t, json.Unmarshal([]byte(line), &entry))
+			if i == 1 {
+				entry.UserId = "user4"
+			}
+			entry.PrevHash = prevHash
+			entry.Hash = hashAuditEntry([]byte("wrong-key-0123456789-0123456789-01"), entry)
+			prevHash = entry.Hash
+			data, _ := json.Marshal(entry)
+			forged += string(data) + "\n"
+		}
+		require.NoError(t, os.WriteFile(path, []byte(forged), 0600))
+
+		service = newService(t, auditConf, nil)
+		_, valid, err = service.List(ctx, dbprovider.ALL_TENANTS, 0, 10)
+		require.NoError(t, err)
+		require.False(t, valid)
+	})
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-leaderboard-server/internal/config"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/utils"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	ErrApiKeyMissing = errors.New("api key is missing")
+	ErrApiKeyInvalid = errors.New("api key is invalid")
+	ErrAccessDenied  = errors.New("access denied")
+)
+
+const apiKeyIdLength = 12
+
+var roleLevels = map[string]int{
+	config.ROLE_CLIENT: 1,
+	config.ROLE_SERVER: 2,
+	config.ROLE_ADMIN:  3,
+}
+
+// Authenticated API key
+type ApiKey struct {
+	Id       string // Public id of the key (prefix of its hash), identifies the key in the audit log
+	Role     string
+	UserId   string
+	Tenant   string          // Tenant the key belongs to (empty - default tenant)
+	Operator bool            // Reads data of all tenants
+	games    map[string]bool // nil - all games of the tenant
+}
+
+// Checks whether the key has the specified role or a more privileged one
+func (k *ApiKey) HasRole(role string) bool {
+	return roleLevels[k.Role] >= roleLevels[role]
+}
+
+func (k *ApiKey) CanAccessGame(gameId string) bool {
+	return k.games == nil || k.games[gameId]
+}
+
+// Checks whether the key can submit scores of the user (client keys can submit only their own scores)
+func (k *ApiKey) CanSubmitFor(userId string) bool {
+	return k.Role != config.ROLE_CLIENT || k.UserId == userId
+}
+
+// Checks whether the caller authenticated by the API key and the token (nil - not sent) can see entries of the user
+// while it's hidden: admins and the user itself can, everyone can if authentication is disabled
+func CanSeeHidden(apiKey *ApiKey, claims *JwtClaims, userId string) bool {
+	if apiKey == nil && claims == nil {
+		return true
+	}
+	if apiKey != nil && (apiKey.HasRole(config.ROLE_ADMIN) || (apiKey.Role == config.ROLE_CLIENT && apiKey.UserId == userId)) {
+		return true
+	}
+	return claims != nil && (claims.Subject == userId || slices.Contains(claims.Scopes, config.ROLE_ADMIN))
+}
+
+// Authenticates requests by API keys from the config and the keys file (reloaded when changed)
+type AuthService struct {
+	config      *config.Config
+	clock       *utils.IClock
+	mutex       sync.RWMutex
+	keys        map[string]*ApiKey // key - SHA-256 of the key value (hex)
+	fileModTime time.Time
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+func NewAuthService(config *config.Config) *AuthService {
+	return &AuthService{
+		config: config,
+	}
+}
+
+func (s *AuthService) Initialize(ctx context.Context, clock *utils.IClock) error {
+	logger.Debug("Auth service initialization")
+
+	s.clock = clock
+
+	if !s.IsEnabled() {
+		return nil
+	}
+
+	err := s.reload()
+	if err != nil {
+		return err
+	}
+
+	if s.config.Auth.KeysFile == "" {
+		return nil
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(time.Duration(s.config.Auth.ReloadInterval) * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+				err := s.reloadIfChanged()
+				if err != nil {
+					// previous keys stay active until the file is fixed
+					logger.Error("Failed to reload api keys", log.LogParams{"error": err, "file": s.config.Auth.KeysFile})
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (s *AuthService) IsEnabled() bool {
+	return s.config.Auth != nil
+}
+
+// Returns the API key with the specified value
+func (s *AuthService) Authenticate(key string) (*ApiKey, error) {
+	if key == "" {
+		return nil, ErrApiKeyMissing
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	apiKey, ok := s.keys[hashApiKey(key)]
+	if !ok {
+		return nil, ErrApiKeyInvalid
+	}
+
+	return apiKey, nil
+}
+
+func (s *AuthService) Shutdown(ctx context.Context) error {
+	logger.Debug("Auth service shutdown")
+
+	if s.cancel == nil {
+		return nil
+	}
+
+	s.cancel()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Reloads the keys if the keys file has been modified since the last load
+func (s *AuthService) reloadIfChanged() error {
+	info, err := os.Stat(s.config.Auth.KeysFile)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.RLock()
+	changed := !info.ModTime().Equal(s.fileModTime)
+	s.mutex.RUnlock()
+
+	if !changed {
+		return nil
+	}
+
+	return s.reload()
+}
+
+// Loads the keys from the config and the keys file
+func (s *AuthService) reload() error {
+	keyConfs := s.config.Auth.Keys
+
+	var modTime time.Time
+	if s.config.Auth.KeysFile != "" {
+		info, err := os.Stat(s.config.Auth.KeysFile)
+		if err != nil {
+			return err
+		}
+		modTime = info.ModTime()
+
+		data, err := os.ReadFile(s.config.Auth.KeysFile)
+		if err != nil {
+			return err
+		}
+
+		var fileKeys []config.ApiKeyConfig
+		err = json.Unmarshal(data, &fileKeys)
+		if err != nil {
+			return err
+		}
+
+		for i := range fileKeys {
+			err = fileKeys[i].Validate()
+			if err != nil {
+				return fmt.Errorf("wrong api key #%d in file: %w", i, err)
+			}
+		}
+
+		keyConfs = append(append([]config.ApiKeyConfig{}, keyConfs...), fileKeys...)
+	}
+
+	keys := make(map[string]*ApiKey, len(keyConfs))
+	for _, keyConf := range keyConfs {
+		hash := hashApiKey(keyConf.Key)
+		apiKey := &ApiKey{Id: hash[:apiKeyIdLength], Role: keyConf.Role, UserId: keyConf.UserId, Tenant: keyConf.Tenant,
+			Operator: keyConf.Operator}
+		for _, gameId := range keyConf.Games {
+			if gameId == "*" {
+				apiKey.games = nil
+				break
+			}
+			if apiKey.games == nil {
+				apiKey.games = make(map[string]bool)
+			}
+			apiKey.games[gameId] = true
+		}
+		keys[hash] = apiKey
+	}
+
+	s.mutex.Lock()
+	s.keys = keys
+	s.fileModTime = modTime
+	s.mutex.Unlock()
+
+	logger.Info("Api keys loaded", log.LogParams{"count": len(keys)})
+
+	return nil
+}
+
+// Keys are looked up by hash, so lookup time doesn't depend on how much of the key value matches
+func hashApiKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"go-leaderboard-server/internal/config"
+	"go-leaderboard-server/internal/utils"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService(t *testing.T) {
+	configKey := "config-key-0123456789"
+	fileKey := "file-key-0123456789"
+	keysFile := filepath.Join(t.TempDir(), "keys.json")
+
+	setupTest := func() (func() error, *AuthService, error) {
+		var clock utils.IClock = &utils.MockClock{}
+
+		err := os.WriteFile(keysFile,
+			[]byte(`[{ "key": "`+fileKey+`", "role": "client", "userId": "user1", "games": ["game1", "game2"] }]`), 0600)
+		if err != nil {
+			return func() error { return nil }, nil, err
+		}
+
+		conf := &config.Config{
+			Auth: &config.AuthConfig{
+				Keys:           []config.ApiKeyConfig{{Key: configKey, Role: config.ROLE_SERVER, Games: []string{"*"}}},
+				KeysFile:       keysFile,
+				ReloadInterval: 3600000,
+			},
+		}
+
+		service := NewAuthService(conf)
+		err = service.Initialize(context.Background(), &clock)
+		return func() error {
+			return service.Shutdown(context.Background())
+		}, service, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*AuthService]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	runTest("authenticate keys", func(t *testing.T, service *AuthService) {
+		_, err := service.Authenticate("")
+		require.ErrorIs(t, err, ErrApiKeyMissing)
+		_, err = service.Authenticate("unknown-key-0123456789")
+		require.ErrorIs(t, err, ErrApiKeyInvalid)
+
+		key, err := service.Authenticate(configKey)
+		require.NoError(t, err)
+		require.True(t, key.HasRole(config.ROLE_CLIENT))
+		require.True(t, key.HasRole(config.ROLE_SERVER))
+		require.False(t, key.HasRole(config.ROLE_ADMIN))
+		require.True(t, key.CanAccessGame("game3"))
+		require.True(t, key.CanSubmitFor("user2"))
+
+		key, err = service.Authenticate(fileKey)
+		require.NoError(t, err)
+		require.True(t, key.HasRole(config.ROLE_CLIENT))
+		require.False(t, key.HasRole(config.ROLE_SERVER))
+		require.True(t, key.CanAccessGame("game2"))
+		require.False(t, key.CanAccessGame("game3"))
+		require.True(t, key.CanSubmitFor("user1"))
+		require.False(t, key.CanSubmitFor("user2"))
+	})
+
+	runTest("reload keys file", func(t *testing.T, service *AuthService) {
+		newKey := "new-file-key-0123456789"
+
+		err := service.reloadIfChanged()
+		require.NoError(t, err)
+		_, err = service.Authenticate(fileKey)
+		require.NoError(t, err)
+
+		err = os.WriteFile(keysFile, []byte(`[{ "key": "`+newKey+`", "role": "admin", "games": ["*"] }]`), 0600)
+		require.NoError(t, err)
+		err = os.Chtimes(keysFile, time.Now(), time.Now().Add(time.Minute))
+		require.NoError(t, err)
+
+		err = service.reloadIfChanged()
+		require.NoError(t, err)
+		_, err = service.Authenticate(fileKey)
+		require.ErrorIs(t, err, ErrApiKeyInvalid)
+		key, err := service.Authenticate(newKey)
+		require.NoError(t, err)
+		require.True(t, key.HasRole(config.ROLE_ADMIN))
+		_, err = service.Authenticate(configKey)
+		require.NoError(t, err)
+
+		// broken file keeps the previous keys
+		err = os.WriteFile(keysFile, []byte(`[{ "key": "short", "role": "admin", "games": ["*"] }]`), 0600)
+		require.NoError(t, err)
+		err = os.Chtimes(keysFile, time.Now(), time.Now().Add(2*time.Minute))
+		require.NoError(t, err)
+
+		err = service.reloadIfChanged()
+		require.Error(t, err)
+		_, err = service.Authenticate(newKey)
+		require.NoError(t, err)
+	})
+}
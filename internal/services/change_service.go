@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/utils"
+	"sync"
+	"time"
+)
+
+var ErrChangesDisabled = errors.New("change feed is disabled")
+
+// Number of attempts of a write when other server instances record changes of the game at the same time
+const changeRecordAttempts = 5
+
+// Changes older than the retention are trimmed every changeTrimInterval versions
+const changeTrimInterval = 100
+
+// Requested version isn't in the change feed anymore (or is ahead of it), the client has to reload the board
+// and continue from Version
+type ChangesResyncError struct {
+	Version uint64 // Current version of the board
+}
+
+func (e *ChangesResyncError) Error() string {
+	return "changes are too old, resync is required"
+}
+
+func (e *ChangesResyncError) ErrorCode() string {
+	return "changes_resync"
+}
+
+// Changes of a board after a version
+type ChangeList struct {
+	Version uint64                   `json:"version" example:"42"` // Version of the last returned change (the requested one if there are no changes)
+	More    bool                     `json:"more" example:"false"` // More changes are available right away
+	Changes []dbprovider.ChangeEntry `json:"changes"`              // Changes in the order of versions
+}
+
+// Records puts and deletes of boards to their change feeds with monotonically increasing versions.
+// Changes are recorded in the same transaction as the writes making them
+type ChangeService struct {
+	config     *config.Config
+	dbprovider dbprovider.IDbProvider
+	clock      *utils.IClock
+	locks      gameLocks // serialize writes of a game within the server instance
+	mu         sync.Mutex
+	waiters    map[string]chan struct{} // closed on a new change of a game (key - gameId)
+}
+
+// Per game locks, a lock is removed once no one holds or waits for it
+type gameLocks struct {
+	mu    sync.Mutex
+	locks map[string]*gameLock // key - gameId
+}
+
+type gameLock struct {
+	sync.Mutex
+	refs int // number of holders and waiters
+}
+
+// Locks the game, the returned func unlocks it
+func (l *gameLocks) lock(gameId string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*gameLock)
+	}
+	lock, ok := l.locks[gameId]
+	if !ok {
+		lock = &gameLock{}
+		l.locks[gameId] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(l.locks, gameId)
+		}
+	}
+}
+
+func NewChangeService(config *config.Config, dbProvider dbprovider.IDbProvider) *ChangeService {
+	return &ChangeService{
+		config:     config,
+		dbprovider: dbProvider,
+	}
+}
+
+func (s *ChangeService) Initialize(ctx context.Context, clock *utils.IClock) error {
+	logger.Debug("Change service initialization")
+
+	if s.dbprovider == nil {
+		return errors.New("uninitialized db provider")
+	}
+
+	s.clock = clock
+	s.waiters = make(map[string]chan struct{})
+
+	return nil
+}
+
+func (s *ChangeService) IsEnabled() bool {
+	return s.config.Changes != nil
+}
+
+// Applies the write recording its changes to the change feed of the game in the same transaction. The write gets
+// the version of its first change (0 - the feed is disabled, nothing is recorded) and the time of the changes, and
+// returns the number of changes it made. Writes of a game are serialized within the server instance, a write losing
+// the version to another instance is retried with the next one. Returns the number of changes made by all attempts
+func (s *ChangeService) Record(ctx context.Context, gameId string, write func(ctx context.Context, rec dbprovider.WriteRecords) (uint32, error)) (uint32, error) {
+	ts := (*s.clock).Now().UnixMilli()
+	if !s.IsEnabled() {
+		return write(ctx, dbprovider.WriteRecords{Ts: ts})
+	}
+
+	unlock := s.locks.lock(gameId)
+	defer unlock()
+
+	var (
+		first, next uint64 // the first version taken by the write and the version after its last change
+		made        uint32
+	)
+	for attempt := 1; ; attempt++ {
+		last, err := s.dbprovider.LastChange(ctx, gameId)
+		if err != nil {
+			return made, err
+		}
+
+		next = 1
+		if last != nil {
+			next = last.Version + 1
+		}
+		if first == 0 {
+			first = next
+		}
+
+		// changes made by a failed attempt are committed already (removals run in batches)
+		n, err := write(ctx, dbprovider.WriteRecords{Version: next, Ts: ts})
+		made += n
+		next += uint64(n)
+		if errors.Is(err, dbprovider.ErrChangeConflict) && attempt < changeRecordAttempts {
+			continue // the version has been taken by another instance
+		}
+		if err != nil {
+			if made > 0 {
+				s.notify(gameId)
+			}
+			return made, err
+		}
+		break
+	}
+
+	if made == 0 {
+		return 0, nil
+	}
+
+	s.notify(gameId)
+
+	// trimmed once the versions pass a multiple of the interval
+	retention := uint64(s.config.Changes.Retention)
+	last := next - 1
+	if last/changeTrimInterval > (first-1)/changeTrimInterval && last > retention {
+		err := s.dbprovider.TrimChanges(ctx, gameId, last-retention+1)
+		if err != nil {
+			logger.Error("Failed to trim changes", log.LogParams{"error": err, "gameId": gameId})
+		}
+	}
+
+	return made, nil
+}
+
+// Returns up to limit changes of the game after the version. If there are none, waits for them up to the wait duration
+// (limited by the config). Returns ChangesResyncError if the changes after the version are trimmed
+func (s *ChangeService) GetChanges(ctx context.Context, gameId string, since uint64, limit uint32, wait time.Duration) (*ChangeList, error) {
+	if !s.IsEnabled() {
+		return nil, ErrChangesDisabled
+	}
+
+	wait = min(wait, time.Duration(s.config.Changes.MaxWait)*time.Millisecond)
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	poll := time.NewTicker(time.Duration(s.config.Changes.PollInterval) * time.Millisecond)
+	defer poll.Stop()
+
+	for {
+		waiter := s.waiter(gameId) // taken before reading, so changes recorded meanwhile aren't missed
+
+		list, err := s.readChanges(ctx, gameId, since, limit)
+		if err != nil || len(list.Changes) > 0 {
+			return list, err
+		}
+
+		select {
+		case <-waiter:
+		case <-poll.C:
+		case <-deadline.C:
+			return list, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (s *ChangeService) readChanges(ctx context.Context, gameId string, since uint64, limit uint32) (*ChangeList, error) {
+	// one more change to learn if there are more
+	changes, err := s.dbprovider.ListChanges(ctx, gameId, since+1, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(changes) > 0 {
+		if changes[0].Version != since+1 {
+			return nil, s.resyncError(ctx, gameId)
+		}
+
+		list := &ChangeList{Changes: changes[:min(len(changes), int(limit))], More: len(changes) > int(limit)}
+		list.Version = list.Changes[len(list.Changes)-1].Version
+		return list, nil
+	}
+
+	if since > 0 {
+		// the version has to be the current one, otherwise it's unknown to the feed
+		last, err := s.dbprovider.LastChange(ctx, gameId)
+		if err != nil {
+			return nil, err
+		}
+		if last == nil || last.Version != since {
+			return nil, s.resyncError(ctx, gameId)
+		}
+	}
+
+	return &ChangeList{Version: since, Changes: []dbprovider.ChangeEntry{}}, nil
+}
+
+func (s *ChangeService) resyncError(ctx context.Context, gameId string) error {
+	last, err := s.dbprovider.LastChange(ctx, gameId)
+	if err != nil {
+		return err
+	}
+	if last == nil {
+		return &ChangesResyncError{}
+	}
+	return &ChangesResyncError{Version: last.Version}
+}
+
+func (s *ChangeService) waiter(gameId string) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch, ok := s.waiters[gameId]
+	if !ok {
+		ch = make(chan struct{})
+		s.waiters[gameId] = ch
+	}
+	return ch
+}
+
+// Wakes up requests waiting for changes of the game
+func (s *ChangeService) notify(gameId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ch, ok := s.waiters[gameId]; ok {
+		close(ch)
+		delete(s.waiters, gameId)
+	}
+}
+
+func (s *ChangeService) Shutdown(ctx context.Context) error {
+	logger.Debug("Change service shutdown")
+	return nil
+}
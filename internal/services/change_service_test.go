@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	cacheprovider "go-leaderboard-server/internal/cache"
+	cache_simple_provider "go-leaderboard-server/internal/cache/simple"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	db_inmemory_provider "go-leaderboard-server/internal/db/inmemory"
+	"go-leaderboard-server/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Provider that writes a score of "another instance" with the same version before the first put,
+// so the put ends with a conflict
+type racingChangeProvider struct {
+	dbprovider.IDbProvider
+	raced bool
+}
+
+func (p *racingChangeProvider) Put(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties, rec dbprovider.WriteRecords) error {
+	if !p.raced {
+		p.raced = true
+		other := rec
+		other.Changes = []dbprovider.ChangeEntry{{Op: dbprovider.CHANGEOP_PUT, UserId: "other", Score: 1}}
+		err := p.IDbProvider.Put(ctx, gameId, "other", dbprovider.UserProperties{Score: 1}, other)
+		if err != nil {
+			return err
+		}
+	}
+	return p.IDbProvider.Put(ctx, gameId, userId, userProp, rec)
+}
+
+func TestChangeService(t *testing.T) {
+	gameId := "game1"
+	runsGameId := "game2"
+
+	setupTest := func() (func() error, *LeaderboardService, error) {
+		var clock utils.IClock = &utils.MockClock{}
+		clock.(*utils.MockClock).SetTime(time.UnixMilli(1000000))
+
+		conf := &config.Config{
+			Db: config.DbConfig{
+				Type:   config.DBTYPE_INMEMORY,
+				Config: &db_inmemory_provider.DbInMemoryProviderConfig{},
+			},
+			Cache: config.CacheConfig{
+				Type: config.CACHETYPE_SIMPLE,
+				Config: &cache_simple_provider.CacheSimpleProviderConfig{
+					CacheProviderBaseConfig: cacheprovider.CacheProviderBaseConfig{Ttl: 1000},
+				},
+			},
+			Boards: map[string]config.BoardConfig{
+				runsGameId: {Type: config.BOARDTYPE_RUNS, RunsPerUser: 2},
+			},
+			Changes: &config.ChangesConfig{Retention: 10, MaxWait: 1000, PollInterval: 1000},
+		}
+
+		service := NewLeaderboardService(conf)
+		err := service.Initialize(context.Background(), &clock)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		service.changes = NewChangeService(conf, service.dbprovider)
+		err = service.changes.Initialize(context.Background(), &clock)
+		return func() error {
+			return errors.Join(service.changes.Shutdown(context.Background()), service.Shutdown(context.Background()))
+		}, service, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*LeaderboardService]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	runTest("record and get changes", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		list, err := service.changes.GetChanges(ctx, gameId, 0, 10, 0)
+		require.NoError(t, err)
+		require.Equal(t, &ChangeList{Version: 0, Changes: []dbprovider.ChangeEntry{}}, list)
+
+		require.NoError(t, service.PutUserScore(ctx, gameId, "user1", dbprovider.UserProperties{Score: 10, Name: "John", Params: "p"}))
+		require.NoError(t, service.PutUserScore(ctx, gameId, "user2", dbprovider.UserProperties{Score: 20}))
+		require.NoError(t, service.DeleteUserScore(ctx, gameId, "user1"))
+		require.NoError(t, service.PutUserScore(ctx, "game3", "user1", dbprovider.UserProperties{Score: 5}))
+
+		list, err = service.changes.GetChanges(ctx, gameId, 0, 2, 0)
+		require.NoError(t, err)
+		require.Equal(t, &ChangeList{Version: 2, More: true, Changes: []dbprovider.ChangeEntry{
+			{Version: 1, Op: dbprovider.CHANGEOP_PUT, UserId: "user1", Score: 10, Name: "John", Params: "p", Ts: 1000000},
+			{Version: 2, Op: dbprovider.CHANGEOP_PUT, UserId: "user2", Score: 20, Ts: 1000000},
+		}}, list)
+
+		list, err = service.changes.GetChanges(ctx, gameId, list.Version, 2, 0)
+		require.NoError(t, err)
+		require.Equal(t, &ChangeList{Version: 3, Changes: []dbprovider.ChangeEntry{
+			{Version: 3, Op: dbprovider.CHANGEOP_DELETE, UserId: "user1", Ts: 1000000},
+		}}, list)
+
+		// versions are per game
+		list, err = service.changes.GetChanges(ctx, "game3", 0, 10, 0)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), list.Version)
+	})
+
+	runTest("runs", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		require.NoError(t, service.PutUserRun(ctx, runsGameId, "user1", dbprovider.RunProperties{RunId: "run1", Score: 10}))
+		require.NoError(t, service.DeleteUserRuns(ctx, runsGameId, "user1"))
+
+		list, err := service.changes.GetChanges(ctx, runsGameId, 0, 10, 0)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.ChangeEntry{
+			{Version: 1, Op: dbprovider.CHANGEOP_PUT, UserId: "user1", RunId: "run1", Score: 10, Ts: 1000000},
+			{Version: 2, Op: dbprovider.CHANGEOP_DELETE, UserId: "user1", Ts: 1000000},
+		}, list.Changes)
+	})
+
+	runTest("long polling", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_ = service.PutUserScore(ctx, gameId, "user1", dbprovider.UserProperties{Score: 10})
+		}()
+
+		start := time.Now()
+		list, err := service.changes.GetChanges(ctx, gameId, 0, 10, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, list.Changes, 1)
+		require.Less(t, time.Since(start), 500*time.Millisecond)
+
+		// the wait is limited by the config
+		start = time.Now()
+		list, err = service.changes.GetChanges(ctx, gameId, 1, 10, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, &ChangeList{Version: 1, Changes: []dbprovider.ChangeEntry{}}, list)
+		require.GreaterOrEqual(t, time.Since(start), time.Second)
+
+		ctxCancel, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err = service.changes.GetChanges(ctxCancel, gameId, 1, 10, time.Minute)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	runTest("resync", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		for i := 0; i < 100; i++ {
+			require.NoError(t, service.PutUserScore(ctx, gameId, fmt.Sprintf("user%d", i), dbprovider.UserProperties{Score: 1}))
+		}
+
+		// the latest 10 changes are retained
+		list, err := service.changes.GetChanges(ctx, gameId, 90, 100, 0)
+		require.NoError(t, err)
+		require.Len(t, list.Changes, 10)
+		require.Equal(t, uint64(91), list.Changes[0].Version)
+
+		var resyncErr *ChangesResyncError
+		_, err = service.changes.GetChanges(ctx, gameId, 89, 100, 0)
+		require.ErrorAs(t, err, &resyncErr)
+		require.Equal(t, uint64(100), resyncErr.Version)
+
+		// versions unknown to the feed
+		_, err = service.changes.GetChanges(ctx, gameId, 101, 100, 0)
+		require.ErrorAs(t, err, &resyncErr)
+		require.Equal(t, uint64(100), resyncErr.Version)
+		_, err = service.changes.GetChanges(ctx, "game3", 1, 100, 0)
+		require.ErrorAs(t, err, &resyncErr)
+		require.Equal(t, uint64(0), resyncErr.Version)
+
+		list, err = service.changes.GetChanges(ctx, gameId, 100, 100, 0)
+		require.NoError(t, err)
+		require.Empty(t, list.Changes)
+	})
+
+	runTest("conflict", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		service.dbprovider = &racingChangeProvider{IDbProvider: service.dbprovider}
+
+		require.NoError(t, service.PutUserScore(ctx, gameId, "user1", dbprovider.UserProperties{Score: 10}))
+
+		list, err := service.changes.GetChanges(ctx, gameId, 0, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, list.Changes, 2)
+		require.Equal(t, "other", list.Changes[0].UserId)
+		require.Equal(t, "user1", list.Changes[1].UserId)
+		require.Equal(t, uint64(2), list.Changes[1].Version)
+	})
+
+	runTest("user states", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		require.NoError(t, service.PutUserScore(ctx, gameId, "user1", dbprovider.UserProperties{Score: 10, Name: "John"}))
+		require.NoError(t, service.SetUserState(ctx, gameId, "user1", dbprovider.USERSTATE_SHADOWBANNED))
+		require.NoError(t, service.PutUserScore(ctx, gameId, "user1", dbprovider.UserProperties{Score: 20, Name: "John"}))
+		require.NoError(t, service.SetUserState(ctx, gameId, "user1", dbprovider.USERSTATE_BANNED))
+		require.NoError(t, service.SetUserState(ctx, gameId, "user1", dbprovider.USERSTATE_VISIBLE))
+
+		require.NoError(t, service.PutUserRun(ctx, runsGameId, "user1", dbprovider.RunProperties{RunId: "run1", Score: 10}))
+		require.NoError(t, service.PutUserRun(ctx, runsGameId, "user1", dbprovider.RunProperties{RunId: "run2", Score: 20}))
+		require.NoError(t, service.SetUserState(ctx, runsGameId, "user1", dbprovider.USERSTATE_SHADOWBANNED))
+		require.NoError(t, service.SetUserState(ctx, runsGameId, "user1", dbprovider.USERSTATE_VISIBLE))
+
+		// puts of hidden users aren't recorded, the current entries are put once the user is visible again
+		list, err := service.changes.GetChanges(ctx, gameId, 0, 10, 0)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.ChangeEntry{
+			{Version: 1, Op: dbprovider.CHANGEOP_PUT, UserId: "user1", Score: 10, Name: "John", Ts: 1000000},
+			{Version: 2, Op: dbprovider.CHANGEOP_DELETE, UserId: "user1", Ts: 1000000},
+			{Version: 3, Op: dbprovider.CHANGEOP_PUT, UserId: "user1", Score: 20, Name: "John", Ts: 1000000},
+		}, list.Changes)
+
+		list, err = service.changes.GetChanges(ctx, runsGameId, 2, 10, 0)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.ChangeEntry{
+			{Version: 3, Op: dbprovider.CHANGEOP_DELETE, UserId: "user1", Ts: 1000000},
+			{Version: 4, Op: dbprovider.CHANGEOP_PUT, UserId: "user1", RunId: "run2", Score: 20, Ts: 1000000},
+			{Version: 5, Op: dbprovider.CHANGEOP_PUT, UserId: "user1", RunId: "run1", Score: 10, Ts: 1000000},
+		}, list.Changes)
+	})
+
+	runTest("evicted runs", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		for i, score := range []dbprovider.UScoreType{10, 20, 30} {
+			require.NoError(t, service.PutUserRun(ctx, runsGameId, "user1", dbprovider.RunProperties{RunId: fmt.Sprintf("run%d", i+1), Score: score}))
+		}
+
+		list, err := service.changes.GetChanges(ctx, runsGameId, 2, 10, 0)
+		require.NoError(t, err)
+		require.Equal(t, []dbprovider.ChangeEntry{
+			{Version: 3, Op: dbprovider.CHANGEOP_PUT, UserId: "user1", RunId: "run3", Score: 30, Ts: 1000000},
+			{Version: 4, Op: dbprovider.CHANGEOP_DELETE, UserId: "user1", RunId: "run1", Ts: 1000000},
+		}, list.Changes)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		service := NewChangeService(&config.Config{}, db_inmemory_provider.NewDbInMemoryProvider())
+		require.NoError(t, service.Initialize(context.Background(), nil))
+		require.False(t, service.IsEnabled())
+
+		_, err := service.GetChanges(context.Background(), gameId, 0, 10, 0)
+		require.ErrorIs(t, err, ErrChangesDisabled)
+	})
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	eventsink "go-leaderboard-server/internal/eventsink"
+	event_file_sink "go-leaderboard-server/internal/eventsink/file"
+	event_nats_sink "go-leaderboard-server/internal/eventsink/nats"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/utils"
+	"sync"
+	"time"
+)
+
+// Events of score submissions are stored to the outbox of the leaderboard DB in the transactions of the submissions,
+// the service relays them to the event sink in the order of ids. Events stay in the outbox until the sink accepts them,
+// so they aren't lost while the sink is down (delivery is at least once)
+type EventService struct {
+	config      *config.Config
+	dbprovider  dbprovider.IDbProvider
+	leaderboard *LeaderboardService
+	clock       *utils.IClock
+	sink        eventsink.IEventSink
+	wake        chan struct{} // signals the relay of new events
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+func NewEventService(config *config.Config, leaderboard *LeaderboardService) *EventService {
+	return &EventService{
+		config:      config,
+		leaderboard: leaderboard,
+	}
+}
+
+func (s *EventService) Initialize(ctx context.Context, clock *utils.IClock) error {
+	logger.Debug("Event service initialization")
+
+	s.clock = clock
+
+	if !s.IsEnabled() {
+		return nil
+	}
+
+	if s.leaderboard == nil || s.leaderboard.dbprovider == nil {
+		return errors.New("uninitialized leaderboard service")
+	}
+	s.dbprovider = s.leaderboard.dbprovider
+
+	switch s.config.Events.Type {
+	case config.EVENTSINKTYPE_FILE:
+		s.sink = event_file_sink.NewEventFileSink()
+	case config.EVENTSINKTYPE_NATS:
+		s.sink = event_nats_sink.NewEventNatsSink()
+	default:
+		return errors.New("unknown Event sink type")
+	}
+
+	err := s.sink.Initialize(ctx, s.config.Events.Config)
+	if err != nil {
+		return err
+	}
+
+	s.wake = make(chan struct{}, 1)
+
+	ctxRun, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.wg.Add(1)
+	go s.relay(ctxRun)
+
+	return nil
+}
+
+func (s *EventService) IsEnabled() bool {
+	return s.config.Events != nil
+}
+
+// Returns the event of the submission of the user stored to the outbox by the write. Scores are the best entries
+// of the user before and after the write (maxEntries - number of kept runs), ranks are looked up in the tracked depth
+func (s *EventService) newEvent(gameId string, submitted dbprovider.ChangeEntry, transition *topTransition, maxEntries uint32, ts int64) *dbprovider.ScoreEvent {
+	event := &dbprovider.ScoreEvent{
+		GameId:  gameId,
+		UserId:  submitted.UserId,
+		RunId:   submitted.RunId,
+		OldRank: userRanks(topEntries(transition.before, s.config.Events.RankDepth))[submitted.UserId],
+		NewRank: userRanks(topEntries(transition.after, s.config.Events.RankDepth))[submitted.UserId],
+		Ts:      ts,
+	}
+	if len(transition.entries) > 0 {
+		event.OldScore = transition.entries[0].Score
+	}
+	if entries := changedEntries(transition.entries, []dbprovider.ChangeEntry{submitted}, maxEntries); len(entries) > 0 {
+		event.NewScore = entries[0].Score
+	}
+	return event
+}
+
+// Signals the relay of a new event
+func (s *EventService) wakeRelay() {
+	select {
+	case s.wake <- struct{}{}:
+	default: // the relay is signaled already
+	}
+}
+
+// Publishes events of the outbox on signals of new events and periodically, to retry events the sink didn't accept
+// and publish events stored by other server instances
+func (s *EventService) relay(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(s.config.Events.RelayInterval) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+
+		err := s.publishOutbox(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Warn("Failed to publish events", log.LogParams{"error": err})
+		}
+	}
+}
+
+// Publishes events of the outbox in the order of ids until it's empty or an event isn't accepted,
+// removing published events from the outbox
+func (s *EventService) publishOutbox(ctx context.Context) error {
+	for {
+		events, err := s.dbprovider.ListOutboxEvents(ctx, s.config.Events.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			err = s.sink.Publish(ctx, event)
+			if err != nil {
+				return err // kept in the outbox until the next attempt
+			}
+			err = s.dbprovider.DeleteOutboxEvent(ctx, event.Id)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(events) < int(s.config.Events.BatchSize) {
+			return nil
+		}
+	}
+}
+
+func (s *EventService) Shutdown(ctx context.Context) error {
+	logger.Debug("Event service shutdown")
+
+	if s.sink == nil {
+		return nil
+	}
+
+	s.cancel()
+	s.wg.Wait()
+
+	// events that aren't published are kept in the outbox for the next start
+	err := s.publishOutbox(ctx)
+	if err != nil {
+		logger.Warn("Failed to publish events", log.LogParams{"error": err})
+	}
+
+	return s.sink.Shutdown(ctx)
+}
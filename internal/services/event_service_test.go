@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"errors"
+	cacheprovider "go-leaderboard-server/internal/cache"
+	cache_simple_provider "go-leaderboard-server/internal/cache/simple"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	db_inmemory_provider "go-leaderboard-server/internal/db/inmemory"
+	eventsink "go-leaderboard-server/internal/eventsink"
+	event_file_sink "go-leaderboard-server/internal/eventsink/file"
+	"go-leaderboard-server/internal/utils"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Sink that keeps published events, rejects them while it's down
+type memoryEventSink struct {
+	down   atomic.Bool
+	mu     sync.Mutex
+	events []dbprovider.ScoreEvent
+}
+
+func (s *memoryEventSink) Initialize(ctx context.Context, config eventsink.IEventSinkConfig) error {
+	return nil
+}
+
+func (s *memoryEventSink) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (s *memoryEventSink) Publish(ctx context.Context, event dbprovider.ScoreEvent) error {
+	if s.down.Load() {
+		return errors.New("sink is down")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+// Returns published events without ids
+func (s *memoryEventSink) published() []dbprovider.ScoreEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := make([]dbprovider.ScoreEvent, 0, len(s.events))
+	for _, event := range s.events {
+		event.Id = ""
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestEventService(t *testing.T) {
+	newService := func(t *testing.T) (*LeaderboardService, *memoryEventSink) {
+		var clock utils.IClock = &utils.MockClock{}
+		clock.(*utils.MockClock).SetTime(time.UnixMilli(1000000))
+
+		conf := &config.Config{
+			Db: config.DbConfig{
+				Type:   config.DBTYPE_INMEMORY,
+				Config: &db_inmemory_provider.DbInMemoryProviderConfig{},
+			},
+			Cache: config.CacheConfig{
+				Type: config.CACHETYPE_SIMPLE,
+				Config: &cache_simple_provider.CacheSimpleProviderConfig{
+					CacheProviderBaseConfig: cacheprovider.CacheProviderBaseConfig{Ttl: 1000},
+				},
+			},
+			Boards: map[string]config.BoardConfig{
+				"runs": {Type: config.BOARDTYPE_RUNS, RunsPerUser: 2},
+			},
+			Events: &config.EventsConfig{
+				Type:          config.EVENTSINKTYPE_FILE,
+				Config:        &event_file_sink.EventFileSinkConfig{Path: filepath.Join(t.TempDir(), "events.ndjson")},
+				RankDepth:     2,
+				RelayInterval: 10,
+				BatchSize:     2,
+			},
+		}
+
+		service := NewLeaderboardService(conf)
+		require.NoError(t, service.Initialize(context.Background(), &clock))
+		service.events = NewEventService(conf, service)
+		require.NoError(t, service.events.Initialize(context.Background(), &clock))
+		require.NoError(t, service.events.sink.Shutdown(context.Background()))
+		sink := &memoryEventSink{}
+		service.events.sink = sink
+		t.Cleanup(func() {
+			require.NoError(t, service.events.Shutdown(context.Background()))
+			require.NoError(t, service.Shutdown(context.Background()))
+		})
+		return service, sink
+	}
+
+	t.Run("publish events of submissions", func(t *testing.T) {
+		ctx := context.Background()
+		service, sink := newService(t)
+
+		require.NoError(t, service.PutUserScore(ctx, "game1", "user1", dbprovider.UserProperties{Score: 10}))
+		require.NoError(t, service.PutUserScore(ctx, "game1", "user2", dbprovider.UserProperties{Score: 20}))
+		require.NoError(t, service.PutUserScore(ctx, "game1", "user3", dbprovider.UserProperties{Score: 5}))
+		require.NoError(t, service.PutUserScore(ctx, "game1", "user3", dbprovider.UserProperties{Score: 30}))
+		// deletions aren't submissions
+		require.NoError(t, service.DeleteUserScore(ctx, "game1", "user1"))
+
+		expected := []dbprovider.ScoreEvent{
+			{GameId: "game1", UserId: "user1", NewScore: 10, NewRank: 1, Ts: 1000000},
+			{GameId: "game1", UserId: "user2", NewScore: 20, OldRank: 0, NewRank: 1, Ts: 1000000},
+			{GameId: "game1", UserId: "user3", NewScore: 5, Ts: 1000000}, // below the rank depth
+			{GameId: "game1", UserId: "user3", OldScore: 5, NewScore: 30, NewRank: 1, Ts: 1000000},
+		}
+		require.Eventually(t, func() bool { return len(sink.published()) == len(expected) }, time.Second, time.Millisecond)
+		require.ElementsMatch(t, expected, sink.published())
+
+		events, err := service.dbprovider.ListOutboxEvents(ctx, 10)
+		require.NoError(t, err)
+		require.Empty(t, events)
+	})
+
+	t.Run("runs", func(t *testing.T) {
+		ctx := context.Background()
+		service, sink := newService(t)
+
+		require.NoError(t, service.PutUserRun(ctx, "runs", "user1", dbprovider.RunProperties{RunId: "run1", Score: 20}))
+		require.NoError(t, service.PutUserRun(ctx, "runs", "user1", dbprovider.RunProperties{RunId: "run2", Score: 10}))
+
+		// runs boards rank users by their best runs
+		expected := []dbprovider.ScoreEvent{
+			{GameId: "runs", UserId: "user1", RunId: "run1", NewScore: 20, NewRank: 1, Ts: 1000000},
+			{GameId: "runs", UserId: "user1", RunId: "run2", OldScore: 20, NewScore: 20, OldRank: 1, NewRank: 1, Ts: 1000000},
+		}
+		require.Eventually(t, func() bool { return len(sink.published()) == len(expected) }, time.Second, time.Millisecond)
+		require.ElementsMatch(t, expected, sink.published())
+	})
+
+	t.Run("evicted runs", func(t *testing.T) {
+		ctx := context.Background()
+		service, sink := newService(t)
+
+		require.NoError(t, service.PutUserRun(ctx, "runs", "user1", dbprovider.RunProperties{RunId: "run1", Score: 20}))
+		require.NoError(t, service.PutUserRun(ctx, "runs", "user1", dbprovider.RunProperties{RunId: "run2", Score: 10}))
+		require.NoError(t, service.PutUserRun(ctx, "runs", "user2", dbprovider.RunProperties{RunId: "run3", Score: 15}))
+		// the worst run of user1 is evicted, a replaced best run lowers the score
+		require.NoError(t, service.PutUserRun(ctx, "runs", "user1", dbprovider.RunProperties{RunId: "run4", Score: 5}))
+		require.NoError(t, service.PutUserRun(ctx, "runs", "user1", dbprovider.RunProperties{RunId: "run1", Score: 12}))
+
+		expected := []dbprovider.ScoreEvent{
+			{GameId: "runs", UserId: "user1", RunId: "run1", NewScore: 20, NewRank: 1, Ts: 1000000},
+			{GameId: "runs", UserId: "user1", RunId: "run2", OldScore: 20, NewScore: 20, OldRank: 1, NewRank: 1, Ts: 1000000},
+			{GameId: "runs", UserId: "user2", RunId: "run3", NewScore: 15, NewRank: 2, Ts: 1000000},
+			{GameId: "runs", UserId: "user1", RunId: "run4", OldScore: 20, NewScore: 20, OldRank: 1, NewRank: 1, Ts: 1000000},
+			{GameId: "runs", UserId: "user1", RunId: "run1", OldScore: 20, NewScore: 12, OldRank: 1, NewRank: 2, Ts: 1000000},
+		}
+		require.Eventually(t, func() bool { return len(sink.published()) == len(expected) }, time.Second, time.Millisecond)
+		require.ElementsMatch(t, expected, sink.published())
+	})
+
+	t.Run("sequential ids", func(t *testing.T) {
+		ctx := context.Background()
+		service, sink := newService(t)
+		sink.down.Store(true)
+
+		for _, userId := range []string{"user1", "user2", "user3"} {
+			require.NoError(t, service.PutUserScore(ctx, "game1", userId, dbprovider.UserProperties{Score: 10}))
+		}
+		events, err := service.dbprovider.ListOutboxEvents(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 3)
+		for i, event := range events {
+			require.Equal(t, dbprovider.OutboxEventId(uint64(i+1)), event.Id)
+			require.Equal(t, "user"+strconv.Itoa(i+1), event.UserId)
+		}
+	})
+
+	t.Run("no events of failed writes", func(t *testing.T) {
+		ctx := context.Background()
+		service, sink := newService(t)
+		sink.down.Store(true)
+		service.dbprovider = &countingDbProvider{IDbProvider: service.dbprovider, failPut: true}
+
+		require.Error(t, service.PutUserScore(ctx, "game1", "user1", dbprovider.UserProperties{Score: 10}))
+		events, err := service.dbprovider.ListOutboxEvents(ctx, 10)
+		require.NoError(t, err)
+		require.Empty(t, events)
+	})
+
+	t.Run("keep events while the sink is down", func(t *testing.T) {
+		ctx := context.Background()
+		service, sink := newService(t)
+		sink.down.Store(true)
+
+		for _, userId := range []string{"user1", "user2", "user3"} {
+			require.NoError(t, service.PutUserScore(ctx, "game1", userId, dbprovider.UserProperties{Score: 10}))
+		}
+		time.Sleep(50 * time.Millisecond)
+		events, err := service.dbprovider.ListOutboxEvents(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, events, 3)
+		require.Empty(t, sink.published())
+
+		sink.down.Store(false)
+		require.Eventually(t, func() bool { return len(sink.published()) == 3 }, time.Second, time.Millisecond)
+		events, err = service.dbprovider.ListOutboxEvents(ctx, 10)
+		require.NoError(t, err)
+		require.Empty(t, events)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		service := NewEventService(&config.Config{}, nil)
+		require.NoError(t, service.Initialize(context.Background(), nil))
+		require.False(t, service.IsEnabled())
+		require.NoError(t, service.Shutdown(context.Background()))
+	})
+}
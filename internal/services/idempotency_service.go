@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-leaderboard-server/internal/config"
+	idempotencyprovider "go-leaderboard-server/internal/idempotency"
+	idempotency_memory_provider "go-leaderboard-server/internal/idempotency/memory"
+	idempotency_redis_provider "go-leaderboard-server/internal/idempotency/redis"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/utils"
+	"sync"
+	"time"
+)
+
+var (
+	ErrIdempotencyKeyReused     = errors.New("idempotency key has already been used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("request with the same idempotency key is in progress")
+	ErrIdempotencyKeyTooLong    = errors.New("idempotency key is too long")
+
+	errIdempotencyPending = errors.New("idempotent request is in progress on another instance")
+)
+
+const idempotencyPollInterval = 100 * time.Millisecond
+
+// Request made with an idempotency key. Keys are scoped by the path, the client and the tenant of the request
+type IdempotencyRequest struct {
+	Key     string // Idempotency key
+	Path    string // Path of the request (including ids of v2 routes)
+	ApiKey  string // API key of the request (empty - none)
+	Subject string // Subject of the bearer token of the request (empty - none)
+	Tenant  string // Tenant of the request (empty - default tenant)
+	Body    []byte
+}
+
+// Makes sure that a request with an idempotency key is performed only once within the window
+type IdempotencyService struct {
+	config   *config.Config
+	clock    *utils.IClock
+	provider idempotencyprovider.IIdempotencyProvider
+	mutex    sync.Mutex
+	inflight map[string]chan struct{} // requests in progress on this server instance (closed when finished)
+}
+
+func NewIdempotencyService(config *config.Config) *IdempotencyService {
+	return &IdempotencyService{
+		config:   config,
+		inflight: make(map[string]chan struct{}),
+	}
+}
+
+func (s *IdempotencyService) Initialize(ctx context.Context, clock *utils.IClock) error {
+	logger.Debug("Idempotency service initialization")
+
+	s.clock = clock
+
+	if !s.IsEnabled() {
+		return nil
+	}
+
+	switch s.config.Idempotency.Type {
+	case config.IDEMPOTENCYTYPE_MEMORY:
+		s.provider = idempotency_memory_provider.NewIdempotencyMemoryProvider()
+	case config.IDEMPOTENCYTYPE_REDIS:
+		s.provider = idempotency_redis_provider.NewIdempotencyRedisProvider()
+	default:
+		return errors.New("unknown Idempotency provider type")
+	}
+
+	return s.provider.Initialize(ctx, s.config.Idempotency.Config)
+}
+
+func (s *IdempotencyService) IsEnabled() bool {
+	return s.config.Idempotency != nil
+}
+
+// Performs the request by calling fn, unless a request with the same key has already been completed.
+// Returns the stored response and true in this case. Duplicates that arrive while the request is in progress wait
+// for its response. fn returns the response to store (nil - the request failed and can be repeated with the key)
+func (s *IdempotencyService) Do(ctx context.Context, request IdempotencyRequest,
+	fn func() *idempotencyprovider.Record) (*idempotencyprovider.Record, bool, error) {
+	key := hashParts(request.Path, request.ApiKey, request.Tenant, request.Subject, request.Key)
+	fingerprint := hashParts(string(request.Body))
+	timeout := time.NewTimer(time.Duration(s.config.Idempotency.LockTimeout) * time.Millisecond)
+	defer timeout.Stop()
+
+	for {
+		// duplicates on this instance wait for the request in progress without polling the store
+		s.mutex.Lock()
+		done, ok := s.inflight[key]
+		if !ok {
+			done = make(chan struct{})
+			s.inflight[key] = done
+		}
+		s.mutex.Unlock()
+
+		if ok {
+			select {
+			case <-done:
+				continue
+			case <-timeout.C:
+				return nil, false, ErrIdempotencyKeyInProgress
+			case <-ctx.Done():
+				return nil, false, ctx.Err()
+			}
+		}
+
+		record, replayed, err := s.attempt(ctx, key, fingerprint, done, fn)
+		if err != errIdempotencyPending {
+			return record, replayed, err
+		}
+
+		// in progress on another instance
+		select {
+		case <-time.After(idempotencyPollInterval):
+		case <-timeout.C:
+			return nil, false, ErrIdempotencyKeyInProgress
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+}
+
+// Takes the key in the store for ttl ms, so values used once (e.g. nonces of signed requests) are shared by all
+// server instances of the store. Returns false if the key is already taken
+func (s *IdempotencyService) Claim(ctx context.Context, key string, ttl uint32) (bool, error) {
+	record, err := s.provider.Reserve(ctx, key, "", ttl, s.now())
+	if err != nil {
+		return false, err
+	}
+	return record == nil, nil
+}
+
+func (s *IdempotencyService) Shutdown(ctx context.Context) error {
+	logger.Debug("Idempotency service shutdown")
+
+	if s.provider == nil {
+		return nil
+	}
+
+	return s.provider.Shutdown(ctx)
+}
+
+// Reserves the key and performs the request, or returns the stored response of the completed one
+func (s *IdempotencyService) attempt(ctx context.Context, key string, fingerprint string, done chan struct{},
+	fn func() *idempotencyprovider.Record) (*idempotencyprovider.Record, bool, error) {
+	defer s.finish(key, done)
+
+	record, err := s.provider.Reserve(ctx, key, fingerprint, s.config.Idempotency.LockTimeout, s.now())
+	if err != nil {
+		return nil, false, err
+	}
+	if record == nil {
+		return s.perform(ctx, key, fingerprint, fn), false, nil
+	}
+
+	if record.Fingerprint != fingerprint {
+		return nil, false, ErrIdempotencyKeyReused
+	}
+	if !record.Done {
+		return nil, false, errIdempotencyPending
+	}
+
+	return record, true, nil
+}
+
+// Calls fn for the reserved key and stores its response (or releases the key if the request failed)
+func (s *IdempotencyService) perform(ctx context.Context, key string, fingerprint string,
+	fn func() *idempotencyprovider.Record) *idempotencyprovider.Record {
+	record := fn()
+
+	// the response has already been sent, so it is stored even if the client is gone, and store errors are only logged
+	ctx = context.WithoutCancel(ctx)
+	if record == nil {
+		err := s.provider.Delete(ctx, key)
+		if err != nil {
+			logger.Error("Failed to release idempotency key", log.LogParams{"error": err})
+		}
+		return nil
+	}
+
+	record.Fingerprint = fingerprint
+	err := s.provider.Complete(ctx, key, *record, s.config.Idempotency.Window, s.now())
+	if err != nil {
+		logger.Error("Failed to store idempotent response", log.LogParams{"error": err})
+	}
+
+	return record
+}
+
+func (s *IdempotencyService) finish(key string, done chan struct{}) {
+	s.mutex.Lock()
+	delete(s.inflight, key)
+	s.mutex.Unlock()
+	close(done)
+}
+
+func (s *IdempotencyService) now() int64 {
+	return (*s.clock).Now().UnixMilli()
+}
+
+func hashParts(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(hash, "%d:%s", len(part), part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
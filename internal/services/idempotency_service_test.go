@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"go-leaderboard-server/internal/config"
+	idempotencyprovider "go-leaderboard-server/internal/idempotency"
+	idempotency_memory_provider "go-leaderboard-server/internal/idempotency/memory"
+	"go-leaderboard-server/internal/utils"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyService(t *testing.T) {
+	now := time.UnixMilli(1000000)
+
+	setupTest := func() (func() error, *IdempotencyService, error) {
+		var clock utils.IClock = &utils.MockClock{}
+		clock.(*utils.MockClock).SetTime(now)
+
+		conf := &config.Config{
+			Idempotency: &config.IdempotencyConfig{
+				Type:        config.IDEMPOTENCYTYPE_MEMORY,
+				Config:      &idempotency_memory_provider.IdempotencyMemoryProviderConfig{},
+				Window:      60000,
+				LockTimeout: 1000,
+			},
+		}
+
+		service := NewIdempotencyService(conf)
+		err := service.Initialize(context.Background(), &clock)
+		return func() error {
+			return service.Shutdown(context.Background())
+		}, service, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*IdempotencyService]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	request := IdempotencyRequest{Key: "key1", Path: "/leaderboard/SendScore", ApiKey: "apikey", Body: []byte(`{"score":1}`)}
+	response := &idempotencyprovider.Record{Status: 200, ContentType: "application/json", Body: []byte(`{"result":"success"}`)}
+
+	runTest("replay completed requests", func(t *testing.T, service *IdempotencyService) {
+		ctx := context.Background()
+		mockClock := (*service.clock).(*utils.MockClock)
+		calls := 0
+		fn := func() *idempotencyprovider.Record {
+			calls++
+			return response
+		}
+
+		record, replayed, err := service.Do(ctx, request, fn)
+		require.NoError(t, err)
+		require.False(t, replayed)
+		require.Equal(t, response.Body, record.Body)
+
+		record, replayed, err = service.Do(ctx, request, fn)
+		require.NoError(t, err)
+		require.True(t, replayed)
+		require.Equal(t, 200, record.Status)
+		require.Equal(t, response.Body, record.Body)
+		require.Equal(t, 1, calls)
+
+		// keys are scoped by the client
+		other := request
+		other.ApiKey = "otherkey"
+		_, replayed, err = service.Do(ctx, other, fn)
+		require.NoError(t, err)
+		require.False(t, replayed)
+		require.Equal(t, 2, calls)
+
+		changed := request
+		changed.Body = []byte(`{"score":2}`)
+		_, _, err = service.Do(ctx, changed, fn)
+		require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+		mockClock.SetTime(now.Add(time.Minute))
+		_, replayed, err = service.Do(ctx, request, fn)
+		require.NoError(t, err)
+		require.False(t, replayed)
+		require.Equal(t, 3, calls)
+	})
+
+	runTest("repeat failed requests", func(t *testing.T, service *IdempotencyService) {
+		ctx := context.Background()
+		calls := 0
+
+		record, _, err := service.Do(ctx, request, func() *idempotencyprovider.Record {
+			calls++
+			return nil
+		})
+		require.NoError(t, err)
+		require.Nil(t, record)
+
+		_, replayed, err := service.Do(ctx, request, func() *idempotencyprovider.Record {
+			calls++
+			return response
+		})
+		require.NoError(t, err)
+		require.False(t, replayed)
+		require.Equal(t, 2, calls)
+	})
+
+	runTest("coalesce concurrent duplicates", func(t *testing.T, service *IdempotencyService) {
+		ctx := context.Background()
+		var calls atomic.Int32
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		var wg sync.WaitGroup
+		results := make([]bool, 5)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if i > 0 {
+					<-started
+				}
+				record, replayed, err := service.Do(ctx, request, func() *idempotencyprovider.Record {
+					calls.Add(1)
+					close(started)
+					<-release
+					return response
+				})
+				require.NoError(t, err)
+				require.Equal(t, response.Body, record.Body)
+				results[i] = replayed
+			}(i)
+		}
+
+		<-started
+		time.Sleep(50 * time.Millisecond) // let duplicates wait
+		close(release)
+		wg.Wait()
+
+		require.Equal(t, int32(1), calls.Load())
+		require.Equal(t, []bool{false, true, true, true, true}, results)
+	})
+}
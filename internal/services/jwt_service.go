@@ -0,0 +1,271 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-leaderboard-server/internal/config"
+	"go-leaderboard-server/internal/utils"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrTokenMissing = errors.New("bearer token is missing")
+	ErrTokenInvalid = errors.New("bearer token is invalid")
+	ErrTokenExpired = errors.New("bearer token is expired or not yet valid")
+)
+
+// Verified claims of a bearer token
+type JwtClaims struct {
+	Subject string
+	Scopes  []string
+	Tenant  string // Tenant of the player (empty - default tenant)
+}
+
+// Checks whether the token can act on behalf of the user (tokens with server or admin scope can act for anyone)
+func (c *JwtClaims) CanActFor(userId string) bool {
+	return c.Subject == userId || slices.Contains(c.Scopes, config.ROLE_SERVER) || slices.Contains(c.Scopes, config.ROLE_ADMIN)
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtPayload struct {
+	Sub    string          `json:"sub"`
+	Iss    string          `json:"iss"`
+	Aud    json.RawMessage `json:"aud"` // string or array of strings
+	Exp    *json.Number    `json:"exp"`
+	Nbf    *json.Number    `json:"nbf"`
+	Scope  string          `json:"scope"`
+	Tenant string          `json:"tenant"`
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// Verifies HS256 and RS256 bearer tokens of players
+type JwtService struct {
+	config  *config.Config
+	clock   *utils.IClock
+	rsaKeys map[string]*rsa.PublicKey // key - kid
+}
+
+func NewJwtService(config *config.Config) *JwtService {
+	return &JwtService{
+		config: config,
+	}
+}
+
+func (s *JwtService) Initialize(ctx context.Context, clock *utils.IClock) error {
+	logger.Debug("Jwt service initialization")
+
+	s.clock = clock
+
+	if !s.IsEnabled() || s.config.Jwt.JwksFile == "" {
+		return nil
+	}
+
+	keys, err := loadJwks(s.config.Jwt.JwksFile)
+	if err != nil {
+		return fmt.Errorf("failed to load jwks file: %w", err)
+	}
+	s.rsaKeys = keys
+
+	return nil
+}
+
+func (s *JwtService) IsEnabled() bool {
+	return s.config.Jwt != nil
+}
+
+// Verifies the signature and the claims of the token
+func (s *JwtService) Verify(token string) (*JwtClaims, error) {
+	if token == "" {
+		return nil, ErrTokenMissing
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrTokenInvalid
+	}
+
+	var header jwtHeader
+	err := decodeJwtPart(parts[0], &header)
+	if err != nil {
+		return nil, ErrTokenInvalid
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrTokenInvalid
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	switch header.Alg {
+	case "HS256":
+		if s.config.Jwt.Secret == "" {
+			return nil, ErrTokenInvalid
+		}
+		mac := hmac.New(sha256.New, []byte(s.config.Jwt.Secret))
+		mac.Write(signed)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return nil, ErrTokenInvalid
+		}
+	case "RS256":
+		key := s.rsaKey(header.Kid)
+		if key == nil {
+			return nil, ErrTokenInvalid
+		}
+		hash := sha256.Sum256(signed)
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], sig) != nil {
+			return nil, ErrTokenInvalid
+		}
+	default:
+		// "none" and unexpected algorithms are never accepted
+		return nil, ErrTokenInvalid
+	}
+
+	var payload jwtPayload
+	err = decodeJwtPart(parts[1], &payload)
+	if err != nil {
+		return nil, ErrTokenInvalid
+	}
+
+	return s.checkClaims(&payload)
+}
+
+func (s *JwtService) Shutdown(ctx context.Context) error {
+	logger.Debug("Jwt service shutdown")
+
+	/* do nothing */
+
+	return nil
+}
+
+func (s *JwtService) checkClaims(payload *jwtPayload) (*JwtClaims, error) {
+	if payload.Sub == "" || payload.Exp == nil {
+		return nil, ErrTokenInvalid
+	}
+
+	now := (*s.clock).Now().UnixMilli()
+	leeway := int64(s.config.Jwt.Leeway)
+
+	exp, err := payload.Exp.Float64()
+	if err != nil {
+		return nil, ErrTokenInvalid
+	}
+	if int64(exp*1000)+leeway < now {
+		return nil, ErrTokenExpired
+	}
+	if payload.Nbf != nil {
+		nbf, err := payload.Nbf.Float64()
+		if err != nil {
+			return nil, ErrTokenInvalid
+		}
+		if int64(nbf*1000)-leeway > now {
+			return nil, ErrTokenExpired
+		}
+	}
+
+	if s.config.Jwt.Issuer != "" && payload.Iss != s.config.Jwt.Issuer {
+		return nil, ErrTokenInvalid
+	}
+	if s.config.Jwt.Audience != "" && !hasAudience(payload.Aud, s.config.Jwt.Audience) {
+		return nil, ErrTokenInvalid
+	}
+
+	if payload.Tenant != "" && !config.IsValidTenant(payload.Tenant) {
+		return nil, ErrTokenInvalid
+	}
+
+	return &JwtClaims{Subject: payload.Sub, Scopes: strings.Fields(payload.Scope), Tenant: payload.Tenant}, nil
+}
+
+// Returns the RSA key with the specified id (the only key, if the token doesn't specify it)
+func (s *JwtService) rsaKey(kid string) *rsa.PublicKey {
+	if kid == "" && len(s.rsaKeys) == 1 {
+		for _, key := range s.rsaKeys {
+			return key
+		}
+	}
+	return s.rsaKeys[kid]
+}
+
+func decodeJwtPart(part string, value any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(value)
+}
+
+func hasAudience(aud json.RawMessage, expected string) bool {
+	var single string
+	if json.Unmarshal(aud, &single) == nil {
+		return single == expected
+	}
+	var list []string
+	if json.Unmarshal(aud, &list) == nil {
+		return slices.Contains(list, expected)
+	}
+	return false
+}
+
+// Loads RSA public keys from JWKS file (keys of other types are skipped)
+func loadJwks(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	err = json.Unmarshal(data, &jwks)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for i, key := range jwks.Keys {
+		if key.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, fmt.Errorf("wrong modulus of key #%d: %w", i, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, fmt.Errorf("wrong exponent of key #%d: %w", i, err)
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("wrong exponent of key #%d", i)
+		}
+		keys[key.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no rsa keys found")
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"go-leaderboard-server/internal/config"
+	"go-leaderboard-server/internal/utils"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJwtService(t *testing.T) {
+	secret := "jwt-secret-0123456789-0123456789"
+	now := time.Unix(1000000, 0)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	jwks, _ := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "key1",
+		"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+	}}})
+	err = os.WriteFile(jwksFile, jwks, 0600)
+	require.NoError(t, err)
+
+	newToken := func(alg string, kid string, claims map[string]any) string {
+		header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT", "kid": kid})
+		payload, _ := json.Marshal(claims)
+		signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+		var sig []byte
+		switch alg {
+		case "HS256":
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write([]byte(signed))
+			sig = mac.Sum(nil)
+		case "RS256":
+			hash := sha256.Sum256([]byte(signed))
+			sig, _ = rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, hash[:])
+		}
+		return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+	}
+
+	setupTest := func() (func() error, *JwtService, error) {
+		var clock utils.IClock = &utils.MockClock{}
+		clock.(*utils.MockClock).SetTime(now)
+
+		conf := &config.Config{
+			Jwt: &config.JwtConfig{
+				Secret:   secret,
+				JwksFile: jwksFile,
+				Issuer:   "game",
+				Audience: "leaderboard",
+				Leeway:   30000,
+			},
+		}
+
+		service := NewJwtService(conf)
+		err := service.Initialize(context.Background(), &clock)
+		return func() error {
+			return service.Shutdown(context.Background())
+		}, service, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*JwtService]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	claims := func(sub string, exp time.Time, scope string) map[string]any {
+		return map[string]any{"sub": sub, "exp": exp.Unix(), "iss": "game", "aud": []string{"leaderboard"}, "scope": scope}
+	}
+
+	runTest("verify tokens", func(t *testing.T, service *JwtService) {
+		for _, alg := range []string{"HS256", "RS256"} {
+			c, err := service.Verify(newToken(alg, "key1", claims("user1", now.Add(time.Hour), "")))
+			require.NoError(t, err)
+			require.Equal(t, "user1", c.Subject)
+			require.True(t, c.CanActFor("user1"))
+			require.False(t, c.CanActFor("user2"))
+		}
+
+		c, err := service.Verify(newToken("RS256", "", claims("server1", now.Add(time.Hour), "read server"))) // the only key
+		require.NoError(t, err)
+		require.True(t, c.CanActFor("user2"))
+
+		_, err = service.Verify("")
+		require.ErrorIs(t, err, ErrTokenMissing)
+		_, err = service.Verify("abc.def")
+		require.ErrorIs(t, err, ErrTokenInvalid)
+		_, err = service.Verify(newToken("none", "", claims("user1", now.Add(time.Hour), "")))
+		require.ErrorIs(t, err, ErrTokenInvalid)
+		_, err = service.Verify(newToken("RS256", "key2", claims("user1", now.Add(time.Hour), "")))
+		require.ErrorIs(t, err, ErrTokenInvalid)
+
+		token := newToken("HS256", "", claims("user1", now.Add(time.Hour), ""))
+		forged := newToken("HS256", "", claims("user2", now.Add(time.Hour), ""))
+		_, err = service.Verify(token[:len(token)-10] + forged[len(forged)-10:])
+		require.ErrorIs(t, err, ErrTokenInvalid)
+	})
+
+	runTest("check claims", func(t *testing.T, service *JwtService) {
+		_, err := service.Verify(newToken("HS256", "", claims("user1", now.Add(-10*time.Second), "")))
+		require.NoError(t, err) // within leeway
+		_, err = service.Verify(newToken("HS256", "", claims("user1", now.Add(-time.Minute), "")))
+		require.ErrorIs(t, err, ErrTokenExpired)
+
+		c := claims("user1", now.Add(time.Hour), "")
+		c["nbf"] = now.Add(time.Minute).Unix()
+		_, err = service.Verify(newToken("HS256", "", c))
+		require.ErrorIs(t, err, ErrTokenExpired)
+
+		c = claims("user1", now.Add(time.Hour), "")
+		c["iss"] = "other"
+		_, err = service.Verify(newToken("HS256", "", c))
+		require.ErrorIs(t, err, ErrTokenInvalid)
+
+		c = claims("user1", now.Add(time.Hour), "")
+		c["aud"] = "other"
+		_, err = service.Verify(newToken("HS256", "", c))
+		require.ErrorIs(t, err, ErrTokenInvalid)
+
+		c = claims("user1", now.Add(time.Hour), "")
+		delete(c, "exp")
+		_, err = service.Verify(newToken("HS256", "", c))
+		require.ErrorIs(t, err, ErrTokenInvalid)
+
+		c = claims("user1", now.Add(time.Hour), "")
+		c["tenant"] = "studio1"
+		verified, err := service.Verify(newToken("HS256", "", c))
+		require.NoError(t, err)
+		require.Equal(t, "studio1", verified.Tenant)
+
+		c["tenant"] = "studio1/game1"
+		_, err = service.Verify(newToken("HS256", "", c))
+		require.ErrorIs(t, err, ErrTokenInvalid)
+	})
+}
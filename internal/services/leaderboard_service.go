@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	cacheprovider "go-leaderboard-server/internal/cache"
 	cache_simple_provider "go-leaderboard-server/internal/cache/simple"
 
@@ -15,13 +18,34 @@ import (
 	mysql_provider "go-leaderboard-server/internal/db/mysql"
 	postgre_provider "go-leaderboard-server/internal/db/postgresql"
 	redis_provider "go-leaderboard-server/internal/db/redis"
+	log "go-leaderboard-server/internal/logger"
 	"go-leaderboard-server/internal/utils"
+	"slices"
+	"sort"
 )
 
+var ErrUserBanned = errors.New("user is banned")
+var ErrQuarantinedNotFound = errors.New("quarantined submission not found")
+var ErrUserNotFound = errors.New("user not found")
+
+// Top of the board as it is cached
+type CachedTop struct {
+	Data   dbprovider.TopData
+	Ts     int64 // Time the top was read from the DB (unix ms)
+	MaxAge int64 // Remaining lifetime of the cached top (ms)
+}
+
 type LeaderboardService struct {
 	config        *config.Config
 	dbprovider    dbprovider.IDbProvider
 	cacheprovider cacheprovider.ICacheProvider
+	clock         *utils.IClock
+	rules         map[string][]IScoreRule // anti-cheat rules of boards (key - gameId)
+	quota         *QuotaService           // usage quotas of games and tenants (nil - not counted)
+	subscriptions *SubscriptionService    // subscribers to tops notified of writes (nil - none)
+	changes       *ChangeService          // change feeds of boards (nil - not recorded)
+	webhooks      *WebhookService         // webhooks on changes of tops (nil - not sent)
+	events        *EventService           // events of score submissions (nil - not published)
 }
 
 func NewLeaderboardService(config *config.Config) *LeaderboardService {
@@ -37,6 +61,13 @@ func (s *LeaderboardService) Initialize(ctx context.Context, clock *utils.IClock
 
 	logger.Debug("Leaderboard service initialization")
 
+	s.clock = clock
+
+	s.rules = make(map[string][]IScoreRule)
+	for gameId, board := range s.config.Boards {
+		s.rules[gameId] = NewScoreRules(board.Rules)
+	}
+
 	switch s.config.Db.Type {
 	case config.DBTYPE_INMEMORY:
 		s.dbprovider = db_inmemory_provider.NewDbInMemoryProvider()
@@ -75,19 +106,721 @@ func (s *LeaderboardService) Initialize(ctx context.Context, clock *utils.IClock
 }
 
 func (s *LeaderboardService) PutUserScore(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties) error {
-	return s.dbprovider.Put(ctx, gameId, userId, userProp)
+	err := s.checkNotBanned(ctx, gameId, userId)
+	if err != nil {
+		return err
+	}
+
+	return s.putUserScore(ctx, gameId, userId, userProp)
+}
+
+// Stores a score submitted by a client after checking it with the anti-cheat rules of the board
+func (s *LeaderboardService) SubmitUserScore(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties, duration uint32) error {
+	err := s.checkNotBanned(ctx, gameId, userId)
+	if err != nil {
+		return err
+	}
+
+	err = s.checkRules(ctx, &ScoreSubmission{
+		GameId:   gameId,
+		UserId:   userId,
+		Score:    userProp.Score,
+		Duration: duration,
+		Ts:       (*s.clock).Now().UnixMilli(),
+		GetPrevious: func(ctx context.Context) (*dbprovider.UScoreType, error) {
+			prev, err := s.dbprovider.Get(ctx, gameId, userId)
+			if err != nil || prev == nil {
+				return nil, err
+			}
+			return &prev.Score, nil
+		},
+	})
+	if err != nil {
+		return s.holdIfQuarantined(ctx, gameId, err, dbprovider.QuarantineItem{
+			UserId:   userId,
+			Score:    userProp.Score,
+			Name:     userProp.Name,
+			Params:   userProp.Params,
+			Duration: duration,
+		})
+	}
+
+	return s.putUserScore(ctx, gameId, userId, userProp)
+}
+
+func (s *LeaderboardService) putUserScore(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties) error {
+	board := s.config.GetBoardConfig(gameId)
+	userProp.Base = userProp.Score
+	userProp.Ts = (*s.clock).Now().UnixMilli()
+	if board.Ttl != 0 && !s.recordsChanges() {
+		// entries of boards with change feeds are removed by the maintenance, so their deletes are recorded
+		userProp.Exp = userProp.Ts + int64(board.Ttl)
+	}
+
+	quotaRes, err := s.reserveQuota(ctx, gameId, func(ctx context.Context) (bool, error) {
+		prev, err := s.dbprovider.Get(ctx, gameId, userId)
+		return prev == nil, err
+	})
+	if err != nil {
+		return err
+	}
+
+	put := dbprovider.ChangeEntry{
+		Op: dbprovider.CHANGEOP_PUT, UserId: userId, Score: userProp.Score, Name: userProp.Name, Params: userProp.Params,
+	}
+	err = s.applyWrite(ctx, gameId, userId, &put, func(ctx context.Context) ([]dbprovider.ChangeEntry, error) {
+		return s.visibleChanges(ctx, gameId, userId, put)
+	}, func(ctx context.Context, rec dbprovider.WriteRecords) (uint32, error) {
+		return 0, s.dbprovider.Put(ctx, gameId, userId, userProp, rec)
+	})
+	s.completeQuota(ctx, gameId, quotaRes, err)
+	if err != nil {
+		return err
+	}
+
+	s.notifyChanged(gameId)
+	return nil
 }
 
 func (s *LeaderboardService) DeleteUserScore(ctx context.Context, gameId string, userId string) error {
-	return s.dbprovider.Delete(ctx, gameId, userId)
+	err := s.applyWrite(ctx, gameId, userId, nil, deleteChange(userId), func(ctx context.Context, rec dbprovider.WriteRecords) (uint32, error) {
+		return 0, s.dbprovider.Delete(ctx, gameId, userId, rec)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notifyChanged(gameId)
+	return nil
 }
 
 func (s *LeaderboardService) GetUserScore(ctx context.Context, gameId string, userId string) (*dbprovider.UserProperties, error) {
-	return s.dbprovider.Get(ctx, gameId, userId)
+	userProp, err := s.dbprovider.Get(ctx, gameId, userId)
+	if err != nil || userProp == nil {
+		return userProp, err
+	}
+
+	minTs := s.getMinTs(gameId)
+	if minTs != 0 && userProp.Ts < minTs {
+		return nil, nil // expired, but not removed yet
+	}
+
+	return userProp, nil
 }
 
 func (s *LeaderboardService) GetTop(ctx context.Context, gameId string, nTop uint32) (dbprovider.TopData, error) {
-	return s.cacheprovider.Top(ctx, gameId, nTop)
+	return s.cacheprovider.Top(ctx, gameId, nTop, dbprovider.TopOptions{
+		MinTs: s.getMinTs(gameId),
+	})
+}
+
+// Same as GetTop, but also returns when the top was read from the DB and how long it stays in the cache.
+// Omitted fields are not read and left empty
+func (s *LeaderboardService) GetCachedTop(ctx context.Context, gameId string, nTop uint32, omitFields dbprovider.TopFields) (*CachedTop, error) {
+	cacheData, err := s.cacheprovider.CachedTop(ctx, gameId, nTop, dbprovider.TopOptions{
+		MinTs:      s.getMinTs(gameId),
+		OmitFields: omitFields,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	maxAge := cacheData.Exp - (*s.clock).Now().UnixMilli()
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	return &CachedTop{Data: cacheData.Data, Ts: cacheData.Ts, MaxAge: maxAge}, nil
+}
+
+// Stores a run of the user on a runs board (a random run id is generated if it's empty)
+func (s *LeaderboardService) PutUserRun(ctx context.Context, gameId string, userId string, run dbprovider.RunProperties) error {
+	err := s.checkNotBanned(ctx, gameId, userId)
+	if err != nil {
+		return err
+	}
+
+	return s.putUserRun(ctx, gameId, userId, run)
+}
+
+// Stores a run submitted by a client after checking it with the anti-cheat rules of the board.
+// The best run of the user is used as the previous score
+func (s *LeaderboardService) SubmitUserRun(ctx context.Context, gameId string, userId string, run dbprovider.RunProperties, duration uint32) error {
+	err := s.checkNotBanned(ctx, gameId, userId)
+	if err != nil {
+		return err
+	}
+
+	// the run id is assigned before the checks, so an approved quarantined run can't be stored twice
+	if run.RunId == "" {
+		run.RunId, err = newRandomId()
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.checkRules(ctx, &ScoreSubmission{
+		GameId:   gameId,
+		UserId:   userId,
+		Score:    run.Score,
+		Duration: duration,
+		Ts:       (*s.clock).Now().UnixMilli(),
+		GetPrevious: func(ctx context.Context) (*dbprovider.UScoreType, error) {
+			runs, err := s.dbprovider.GetRuns(ctx, gameId, userId)
+			if err != nil || len(runs) == 0 {
+				return nil, err
+			}
+			return &runs[0].Score, nil
+		},
+	})
+	if err != nil {
+		return s.holdIfQuarantined(ctx, gameId, err, dbprovider.QuarantineItem{
+			UserId:   userId,
+			Score:    run.Score,
+			Name:     run.Name,
+			Params:   run.Params,
+			RunId:    run.RunId,
+			Duration: duration,
+		})
+	}
+
+	return s.putUserRun(ctx, gameId, userId, run)
+}
+
+func (s *LeaderboardService) putUserRun(ctx context.Context, gameId string, userId string, run dbprovider.RunProperties) error {
+	board := s.config.GetBoardConfig(gameId)
+	if run.RunId == "" {
+		var err error
+		run.RunId, err = newRandomId()
+		if err != nil {
+			return err
+		}
+	}
+	run.Ts = (*s.clock).Now().UnixMilli()
+
+	quotaRes, err := s.reserveQuota(ctx, gameId, func(ctx context.Context) (bool, error) {
+		runs, err := s.dbprovider.GetRuns(ctx, gameId, userId)
+		if err != nil {
+			return false, err
+		}
+		for _, r := range runs {
+			if r.RunId == run.RunId {
+				return false, nil // replaces the stored run
+			}
+		}
+		return len(runs) < int(board.RunsPerUser), nil // otherwise the worst run is evicted
+	})
+	if err != nil {
+		return err
+	}
+
+	put := dbprovider.ChangeEntry{
+		Op: dbprovider.CHANGEOP_PUT, UserId: userId, RunId: run.RunId, Score: run.Score, Name: run.Name, Params: run.Params,
+	}
+	err = s.applyWrite(ctx, gameId, userId, &put, func(ctx context.Context) ([]dbprovider.ChangeEntry, error) {
+		return s.visibleChanges(ctx, gameId, userId, put)
+	}, func(ctx context.Context, rec dbprovider.WriteRecords) (uint32, error) {
+		return s.dbprovider.PutRun(ctx, gameId, userId, run, board.RunsPerUser, rec)
+	})
+	s.completeQuota(ctx, gameId, quotaRes, err)
+	if err != nil {
+		return err
+	}
+
+	s.notifyChanged(gameId)
+	return nil
+}
+
+func (s *LeaderboardService) DeleteUserRuns(ctx context.Context, gameId string, userId string) error {
+	// a delete without a run id removes all runs of the user
+	err := s.applyWrite(ctx, gameId, userId, nil, deleteChange(userId), func(ctx context.Context, rec dbprovider.WriteRecords) (uint32, error) {
+		return 0, s.dbprovider.DeleteRuns(ctx, gameId, userId, rec)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notifyChanged(gameId)
+	return nil
+}
+
+func (s *LeaderboardService) GetUserRuns(ctx context.Context, gameId string, userId string) ([]dbprovider.RunProperties, error) {
+	return s.dbprovider.GetRuns(ctx, gameId, userId)
+}
+
+func (s *LeaderboardService) GetTopRuns(ctx context.Context, gameId string, nTop uint32) (dbprovider.RunTopData, error) {
+	return s.dbprovider.TopRuns(ctx, gameId, nTop)
+}
+
+// Sets the visibility state of the user. Not visible users are removed from tops immediately, the change feed
+// records a delete of the user when it's hidden and puts of its entries when it's shown again
+func (s *LeaderboardService) SetUserState(ctx context.Context, gameId string, userId string, state dbprovider.UserState) error {
+	err := s.applyWrite(ctx, gameId, userId, nil, func(ctx context.Context) ([]dbprovider.ChangeEntry, error) {
+		prev, err := s.dbprovider.GetUserState(ctx, gameId, userId)
+		if err != nil {
+			return nil, err
+		}
+
+		if prev == dbprovider.USERSTATE_VISIBLE && state != dbprovider.USERSTATE_VISIBLE {
+			return []dbprovider.ChangeEntry{{Op: dbprovider.CHANGEOP_DELETE, UserId: userId}}, nil
+		}
+		if prev != dbprovider.USERSTATE_VISIBLE && state == dbprovider.USERSTATE_VISIBLE {
+			return s.entryChanges(ctx, gameId, userId)
+		}
+		return nil, nil
+	}, func(ctx context.Context, rec dbprovider.WriteRecords) (uint32, error) {
+		return 0, s.dbprovider.SetUserState(ctx, gameId, userId, state, rec)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notifyChanged(gameId)
+	return s.cacheprovider.Invalidate(ctx, gameId)
+}
+
+// Checks whether entries of the user are shown to a caller, entries of hidden users are shown only to callers
+// that can see them (the user itself and admins)
+func (s *LeaderboardService) IsShown(ctx context.Context, gameId string, userId string, canSeeHidden bool) (bool, error) {
+	if canSeeHidden {
+		return true, nil
+	}
+	state, err := s.dbprovider.GetUserState(ctx, gameId, userId)
+	return state == dbprovider.USERSTATE_VISIBLE, err
+}
+
+func (s *LeaderboardService) GetUserState(ctx context.Context, gameId string, userId string) (dbprovider.UserState, error) {
+	return s.dbprovider.GetUserState(ctx, gameId, userId)
+}
+
+// Returns submissions of the game held for review, the oldest first
+func (s *LeaderboardService) ListQuarantined(ctx context.Context, gameId string, limit uint32) ([]dbprovider.QuarantineItem, error) {
+	return s.dbprovider.ListQuarantined(ctx, gameId, limit)
+}
+
+// Removes the submission from the quarantine and applies it to the board as a regular score.
+// The submission is claimed first, so concurrent approvals apply it once; it's put back if applying fails.
+// Returns the applied submission
+func (s *LeaderboardService) ApproveQuarantined(ctx context.Context, gameId string, id string) (*dbprovider.QuarantineItem, error) {
+	item, err := s.dbprovider.DeleteQuarantined(ctx, gameId, id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrQuarantinedNotFound
+	}
+
+	if s.config.GetBoardConfig(gameId).Type == config.BOARDTYPE_RUNS {
+		err = s.PutUserRun(ctx, gameId, item.UserId, dbprovider.RunProperties{
+			RunId:  item.RunId,
+			Score:  item.Score,
+			Name:   item.Name,
+			Params: item.Params,
+		})
+	} else {
+		err = s.PutUserScore(ctx, gameId, item.UserId, dbprovider.UserProperties{
+			Score:  item.Score,
+			Name:   item.Name,
+			Params: item.Params,
+		})
+	}
+	if err != nil {
+		if perr := s.dbprovider.PutQuarantined(ctx, gameId, *item); perr != nil {
+			return nil, errors.Join(err, perr)
+		}
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// Drops the quarantined submission without applying it. Returns the dropped submission
+func (s *LeaderboardService) RejectQuarantined(ctx context.Context, gameId string, id string) (*dbprovider.QuarantineItem, error) {
+	item, err := s.dbprovider.DeleteQuarantined(ctx, gameId, id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrQuarantinedNotFound
+	}
+
+	return item, nil
+}
+
+// Stores the submission for review if the violated rule requires it. The violation error is returned anyway
+func (s *LeaderboardService) holdIfQuarantined(ctx context.Context, gameId string, err error, item dbprovider.QuarantineItem) error {
+	var ruleErr *RuleViolationError
+	if !errors.As(err, &ruleErr) || !ruleErr.Quarantine {
+		return err
+	}
+
+	suffix, rerr := newRandomId()
+	if rerr != nil {
+		return rerr
+	}
+	item.Ts = (*s.clock).Now().UnixMilli()
+	item.Id = fmt.Sprintf("%013d%s", item.Ts, suffix) // ordered by creation time
+	item.Rule = ruleErr.Rule
+	item.Reason = ruleErr.Reason
+
+	perr := s.dbprovider.PutQuarantined(ctx, gameId, item)
+	if perr != nil {
+		return perr
+	}
+
+	return err
+}
+
+// Runs the anti-cheat rules of the board, the first violation is returned as RuleViolationError
+func (s *LeaderboardService) checkRules(ctx context.Context, sub *ScoreSubmission) error {
+	rules := s.rules[sub.GameId]
+	if len(rules) == 0 {
+		return nil
+	}
+
+	quarantine := s.config.GetBoardConfig(sub.GameId).Rules.Action == config.RULEACTION_QUARANTINE
+	for _, rule := range rules {
+		reason, err := rule.Check(ctx, sub)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			logger.Warn("Score rule violation", log.LogParams{
+				"gameId": sub.GameId, "userId": sub.UserId, "score": float64(sub.Score), "rule": rule.Name(), "reason": reason,
+			})
+			return &RuleViolationError{Rule: rule.Name(), Reason: reason, Quarantine: quarantine}
+		}
+	}
+
+	return nil
+}
+
+// Reserves the write in the quotas of the game and its tenant, isNewEntry is called only if new entries are limited.
+// The reservation is completed by completeQuota once the write is made or fails (nil - quotas are disabled)
+func (s *LeaderboardService) reserveQuota(ctx context.Context, gameId string, isNewEntry func(ctx context.Context) (bool, error)) (*QuotaReservation, error) {
+	if s.quota == nil || !s.quota.IsEnabled() {
+		return nil, nil
+	}
+
+	isNew := false
+	if s.quota.LimitsEntries(gameId) {
+		var err error
+		isNew, err = isNewEntry(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.quota.ReserveWrite(ctx, gameId, isNew)
+}
+
+// Completes the quota reservation of the write, returning it if the write failed.
+// Failures are logged, but don't change the result of the write
+func (s *LeaderboardService) completeQuota(ctx context.Context, gameId string, res *QuotaReservation, writeErr error) {
+	err := s.quota.CompleteWrite(ctx, res, writeErr == nil)
+	if err != nil {
+		logger.Error("Failed to complete quota reservation", log.LogParams{"error": err, "gameId": gameId})
+	}
+}
+
+// Reads the top of the board from the DB bypassing the cache
+func (s *LeaderboardService) readTop(ctx context.Context, gameId string, nTop uint32) ([]TopEntry, error) {
+	if s.config.GetBoardConfig(gameId).Type == config.BOARDTYPE_RUNS {
+		top, err := s.dbprovider.TopRuns(ctx, gameId, nTop)
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]TopEntry, 0, len(top))
+		for i, run := range top {
+			entries = append(entries, TopEntry{
+				Rank: i + 1, UserId: run.UserId, RunId: run.RunId, Score: run.Score, Name: run.Name, Params: run.Params, Ts: run.Ts,
+			})
+		}
+		return entries, nil
+	}
+
+	top, err := s.dbprovider.Top(ctx, gameId, nTop, dbprovider.TopOptions{
+		MinTs: s.getMinTs(gameId),
+	})
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]TopEntry, 0, len(top))
+	for i, user := range top {
+		entries = append(entries, TopEntry{
+			Rank: i + 1, UserId: user.UserId, Score: user.Score, Name: user.Name, Params: user.Params,
+		})
+	}
+	return entries, nil
+}
+
+// Applies the write of the entries of the user (submitted - the submitted score or run, nil - the write isn't
+// a submission). The changes returned by prepare are recorded to the change feed (prepare is called before every
+// attempt of the write, no changes - nothing is recorded), the write returns the number of changes it made besides
+// them (evicted runs). The top is read once before every attempt of the write and the top after it is derived from
+// the changes: the event of a submission is stored to the outbox by the write and webhooks of the changes of the top
+// are sent once the write is committed
+func (s *LeaderboardService) applyWrite(ctx context.Context, gameId string, userId string, submitted *dbprovider.ChangeEntry,
+	prepare func(ctx context.Context) ([]dbprovider.ChangeEntry, error),
+	write func(ctx context.Context, rec dbprovider.WriteRecords) (uint32, error)) error {
+	tracksEvent := submitted != nil && s.events != nil && s.events.IsEnabled()
+	watched := s.webhooks != nil && s.webhooks.IsEnabled() && s.webhooks.Watches(gameId)
+
+	var transition *topTransition // of the last attempt
+	attempt := func(ctx context.Context, rec dbprovider.WriteRecords) (uint32, error) {
+		var changes []dbprovider.ChangeEntry
+		if rec.Version != 0 || tracksEvent || watched {
+			var err error
+			changes, err = prepare(ctx)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		transition = nil
+		if tracksEvent || watched {
+			var depth uint32
+			if tracksEvent {
+				depth = s.config.Events.RankDepth
+			}
+			if watched {
+				depth = max(depth, s.config.Webhooks.TopSize)
+			}
+
+			var err error
+			transition, err = s.readTransition(ctx, gameId, userId, depth, changes)
+			if err != nil && tracksEvent {
+				logger.Error("Failed to read top for event", log.LogParams{"error": err, "gameId": gameId, "userId": userId})
+				return 0, err
+			}
+			if err != nil { // webhooks are best effort
+				logger.Error("Failed to read top for webhooks", log.LogParams{"error": err, "gameId": gameId})
+			}
+		}
+		if tracksEvent {
+			rec.Event = s.events.newEvent(gameId, *submitted, transition, s.runsPerUser(gameId), rec.Ts)
+		}
+
+		if len(changes) == 0 {
+			rec.Version = 0
+		}
+		rec.Changes = changes
+
+		n, err := write(ctx, rec)
+		if err != nil || rec.Version == 0 {
+			return 0, err
+		}
+		return uint32(len(changes)) + n, nil
+	}
+
+	var err error
+	if s.recordsChanges() {
+		_, err = s.changes.Record(ctx, gameId, attempt)
+	} else {
+		_, err = attempt(ctx, dbprovider.WriteRecords{Ts: (*s.clock).Now().UnixMilli()})
+	}
+	if err != nil {
+		return err
+	}
+
+	if tracksEvent {
+		s.events.wakeRelay()
+	}
+	if watched && transition != nil {
+		submitter := ""
+		if submitted != nil {
+			submitter = userId
+		}
+		s.webhooks.publishTransition(ctx, gameId, submitter, transition)
+	}
+	return nil
+}
+
+// Change of the top made by a write of the entries of a user
+type topTransition struct {
+	userId  string
+	entries []TopEntry // Entries of the user before the write, the best first (hidden users too)
+	before  []TopEntry // Top before the write
+	after   []TopEntry // Top after the write derived from the top before it and the changes of the write
+}
+
+// Reads the top of depth entries before the changes of the entries of the user and derives the top after them.
+// Entries below the top are read as well, so entries of the user leaving the top are replaced
+func (s *LeaderboardService) readTransition(ctx context.Context, gameId string, userId string, depth uint32,
+	changes []dbprovider.ChangeEntry) (*topTransition, error) {
+	entries, err := s.userEntries(ctx, gameId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	top, err := s.readTop(ctx, gameId, depth+uint32(len(entries)))
+	if err != nil {
+		return nil, err
+	}
+
+	after := top
+	if len(changes) > 0 { // no changes are recorded for hidden users, they aren't in the top
+		after = make([]TopEntry, 0, len(top)+len(entries)+1)
+		for _, e := range top {
+			if e.UserId != userId {
+				after = append(after, e)
+			}
+		}
+		after = append(after, changedEntries(entries, changes, s.runsPerUser(gameId))...)
+		sort.SliceStable(after, func(i, j int) bool {
+			return after[j].Score < after[i].Score
+		})
+		for i := range after {
+			after[i].Rank = i + 1
+		}
+	}
+
+	return &topTransition{
+		userId:  userId,
+		entries: entries,
+		before:  topEntries(top, depth),
+		after:   topEntries(after, depth),
+	}, nil
+}
+
+// Returns the stored entries of the user, the best first (runs on runs boards)
+func (s *LeaderboardService) userEntries(ctx context.Context, gameId string, userId string) ([]TopEntry, error) {
+	if s.config.GetBoardConfig(gameId).Type == config.BOARDTYPE_RUNS {
+		runs, err := s.dbprovider.GetRuns(ctx, gameId, userId)
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]TopEntry, 0, len(runs))
+		for _, run := range runs {
+			entries = append(entries, TopEntry{
+				UserId: userId, RunId: run.RunId, Score: run.Score, Name: run.Name, Params: run.Params, Ts: run.Ts,
+			})
+		}
+		return entries, nil
+	}
+
+	userProp, err := s.dbprovider.Get(ctx, gameId, userId)
+	if err != nil || userProp == nil {
+		return nil, err
+	}
+	return []TopEntry{{UserId: userId, Score: userProp.Score, Name: userProp.Name, Params: userProp.Params}}, nil
+}
+
+// Maximum number of entries of a user (the number of kept runs on runs boards)
+func (s *LeaderboardService) runsPerUser(gameId string) uint32 {
+	board := s.config.GetBoardConfig(gameId)
+	if board.Type == config.BOARDTYPE_RUNS {
+		return board.RunsPerUser
+	}
+	return 1
+}
+
+// Returns the entries of the user (the best first) after the changes: a put replaces the entry with the same run id
+// evicting the worst entries beyond maxEntries, a delete removes it (all entries without a run id)
+func changedEntries(entries []TopEntry, changes []dbprovider.ChangeEntry, maxEntries uint32) []TopEntry {
+	result := slices.Clone(entries)
+	for _, change := range changes {
+		result = slices.DeleteFunc(result, func(e TopEntry) bool {
+			return e.RunId == change.RunId || (change.Op == dbprovider.CHANGEOP_DELETE && change.RunId == "")
+		})
+		if change.Op != dbprovider.CHANGEOP_PUT {
+			continue
+		}
+		result = append(result, TopEntry{
+			UserId: change.UserId, RunId: change.RunId, Score: change.Score, Name: change.Name, Params: change.Params,
+		})
+		sort.SliceStable(result, func(i, j int) bool {
+			return result[j].Score < result[i].Score
+		})
+		result = result[:min(len(result), int(maxEntries))]
+	}
+	return result
+}
+
+// Returns the entries within the depth of the top
+func topEntries(entries []TopEntry, depth uint32) []TopEntry {
+	return entries[:min(len(entries), int(depth))]
+}
+
+func (s *LeaderboardService) recordsChanges() bool {
+	return s.changes != nil && s.changes.IsEnabled()
+}
+
+// Returns the changes if the user is visible, changes of hidden users aren't recorded
+func (s *LeaderboardService) visibleChanges(ctx context.Context, gameId string, userId string, changes ...dbprovider.ChangeEntry) ([]dbprovider.ChangeEntry, error) {
+	state, err := s.dbprovider.GetUserState(ctx, gameId, userId)
+	if err != nil || state != dbprovider.USERSTATE_VISIBLE {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// Returns puts of the stored entries of the user (its runs on runs boards)
+func (s *LeaderboardService) entryChanges(ctx context.Context, gameId string, userId string) ([]dbprovider.ChangeEntry, error) {
+	if s.config.GetBoardConfig(gameId).Type == config.BOARDTYPE_RUNS {
+		runs, err := s.dbprovider.GetRuns(ctx, gameId, userId)
+		if err != nil {
+			return nil, err
+		}
+		changes := make([]dbprovider.ChangeEntry, 0, len(runs))
+		for _, run := range runs {
+			changes = append(changes, dbprovider.ChangeEntry{
+				Op: dbprovider.CHANGEOP_PUT, UserId: userId, RunId: run.RunId, Score: run.Score, Name: run.Name, Params: run.Params,
+			})
+		}
+		return changes, nil
+	}
+
+	userProp, err := s.dbprovider.Get(ctx, gameId, userId)
+	if err != nil || userProp == nil {
+		return nil, err
+	}
+	return []dbprovider.ChangeEntry{{
+		Op: dbprovider.CHANGEOP_PUT, UserId: userId, Score: userProp.Score, Name: userProp.Name, Params: userProp.Params,
+	}}, nil
+}
+
+// Returns prepare of a delete of the user (all runs of the user on runs boards)
+func deleteChange(userId string) func(ctx context.Context) ([]dbprovider.ChangeEntry, error) {
+	return func(ctx context.Context) ([]dbprovider.ChangeEntry, error) {
+		return []dbprovider.ChangeEntry{{Op: dbprovider.CHANGEOP_DELETE, UserId: userId}}, nil
+	}
+}
+
+// Signals subscribers of the board after a successful write
+func (s *LeaderboardService) notifyChanged(gameId string) {
+	if s.subscriptions != nil {
+		s.subscriptions.NotifyChanged(gameId)
+	}
+}
+
+func (s *LeaderboardService) checkNotBanned(ctx context.Context, gameId string, userId string) error {
+	state, err := s.dbprovider.GetUserState(ctx, gameId, userId)
+	if err != nil {
+		return err
+	}
+	if state == dbprovider.USERSTATE_BANNED {
+		return ErrUserBanned
+	}
+	return nil
+}
+
+// Returns a random alphanumeric id
+func newRandomId() (string, error) {
+	b := make([]byte, 8)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Returns the minimum last submission time of not expired entries
+func (s *LeaderboardService) getMinTs(gameId string) int64 {
+	board := s.config.GetBoardConfig(gameId)
+	if board.Ttl == 0 {
+		return 0
+	}
+	return (*s.clock).Now().UnixMilli() - int64(board.Ttl)
 }
 
 func (s *LeaderboardService) Shutdown(ctx context.Context) error {
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	cacheprovider "go-leaderboard-server/internal/cache"
+	cache_simple_provider "go-leaderboard-server/internal/cache/simple"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	db_inmemory_provider "go-leaderboard-server/internal/db/inmemory"
+	"go-leaderboard-server/internal/utils"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaderboardService(t *testing.T) {
+	gameId := "game1"
+	ttlGameId := "game2"
+	runsGameId := "game3"
+	rulesGameId := "game4"
+	quarantineGameId := "game5"
+	now := time.UnixMilli(1000000)
+
+	minScore, maxScore, maxDelta := 0.0, 1000.0, 100.0
+
+	setupTest := func() (func() error, *LeaderboardService, error) {
+		var clock utils.IClock = &utils.MockClock{}
+		clock.(*utils.MockClock).SetTime(now)
+
+		conf := &config.Config{
+			Db: config.DbConfig{
+				Type:   config.DBTYPE_INMEMORY,
+				Config: &db_inmemory_provider.DbInMemoryProviderConfig{},
+			},
+			Cache: config.CacheConfig{
+				Type: config.CACHETYPE_SIMPLE,
+				Config: &cache_simple_provider.CacheSimpleProviderConfig{
+					CacheProviderBaseConfig: cacheprovider.CacheProviderBaseConfig{Ttl: 1000},
+				},
+			},
+			Boards: map[string]config.BoardConfig{
+				ttlGameId:  {Ttl: 60000},
+				runsGameId: {Type: config.BOARDTYPE_RUNS, RunsPerUser: 2},
+				rulesGameId: {Rules: &config.RulesConfig{
+					MinScore: &minScore, MaxScore: &maxScore, MaxDelta: &maxDelta, MaxSubmissions: 2, MinDuration: 1000,
+				}},
+				quarantineGameId: {Rules: &config.RulesConfig{MaxScore: &maxScore, Action: config.RULEACTION_QUARANTINE}},
+			},
+		}
+
+		service := NewLeaderboardService(conf)
+		err := service.Initialize(context.Background(), &clock)
+		return func() error {
+			return service.Shutdown(context.Background())
+		}, service, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*LeaderboardService]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	runTest("hide expired entries", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		mockClock := (*service.clock).(*utils.MockClock)
+
+		for _, gid := range []string{gameId, ttlGameId} {
+			err := service.PutUserScore(ctx, gid, "user1", dbprovider.UserProperties{Score: 10})
+			require.NoError(t, err)
+		}
+
+		mockClock.SetTime(now.Add(30 * time.Second))
+		for _, gid := range []string{gameId, ttlGameId} {
+			err := service.PutUserScore(ctx, gid, "user2", dbprovider.UserProperties{Score: 20})
+			require.NoError(t, err)
+		}
+
+		top, err := service.GetTop(ctx, ttlGameId, 10)
+		require.NoError(t, err)
+		require.Len(t, top, 2)
+
+		mockClock.SetTime(now.Add(61 * time.Second))
+
+		for _, gid := range []string{gameId, ttlGameId} {
+			data, err := service.GetUserScore(ctx, gid, "user2")
+			require.NoError(t, err)
+			require.NotNil(t, data)
+		}
+
+		data, err := service.GetUserScore(ctx, gameId, "user1")
+		require.NoError(t, err)
+		require.NotNil(t, data)
+		data, err = service.GetUserScore(ctx, ttlGameId, "user1")
+		require.NoError(t, err)
+		require.Nil(t, data)
+
+		top, err = service.GetTop(ctx, gameId, 10)
+		require.NoError(t, err)
+		require.Len(t, top, 2)
+		top, err = service.GetTop(ctx, ttlGameId, 10)
+		require.NoError(t, err)
+		require.Len(t, top, 1)
+		require.Equal(t, "user2", top[0].UserId)
+	})
+
+	runTest("keep best runs", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		mockClock := (*service.clock).(*utils.MockClock)
+
+		for i, score := range []dbprovider.UScoreType{10, 30, 20} {
+			mockClock.SetTime(now.Add(time.Duration(i) * time.Second))
+			err := service.PutUserRun(ctx, runsGameId, "user1", dbprovider.RunProperties{Score: score})
+			require.NoError(t, err)
+		}
+		err := service.PutUserRun(ctx, runsGameId, "user2", dbprovider.RunProperties{RunId: "run1", Score: 25})
+		require.NoError(t, err)
+
+		runs, err := service.GetUserRuns(ctx, runsGameId, "user1")
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		require.Equal(t, dbprovider.UScoreType(30), runs[0].Score)
+		require.Equal(t, now.Add(time.Second).UnixMilli(), runs[0].Ts)
+		require.Equal(t, dbprovider.UScoreType(20), runs[1].Score)
+		require.NotEmpty(t, runs[0].RunId)
+		require.NotEqual(t, runs[0].RunId, runs[1].RunId)
+
+		top, err := service.GetTopRuns(ctx, runsGameId, 10)
+		require.NoError(t, err)
+		require.Len(t, top, 3)
+		require.Equal(t, "user2", top[1].UserId)
+		require.Equal(t, "run1", top[1].RunId)
+
+		err = service.DeleteUserRuns(ctx, runsGameId, "user1")
+		require.NoError(t, err)
+		top, err = service.GetTopRuns(ctx, runsGameId, 10)
+		require.NoError(t, err)
+		require.Len(t, top, 1)
+	})
+
+	runTest("check anti-cheat rules", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		mockClock := (*service.clock).(*utils.MockClock)
+
+		checkViolation := func(err error, rule string) {
+			var ruleErr *RuleViolationError
+			require.ErrorAs(t, err, &ruleErr)
+			require.Equal(t, rule, ruleErr.Rule)
+			require.False(t, ruleErr.Quarantine)
+		}
+
+		err := service.SubmitUserScore(ctx, rulesGameId, "user1", dbprovider.UserProperties{Score: 1e300}, 5000)
+		checkViolation(err, "score_range")
+		err = service.SubmitUserScore(ctx, rulesGameId, "user1", dbprovider.UserProperties{Score: 50}, 500)
+		checkViolation(err, "min_duration")
+		err = service.SubmitUserScore(ctx, rulesGameId, "user1", dbprovider.UserProperties{Score: 50}, 5000)
+		require.NoError(t, err)
+		err = service.SubmitUserScore(ctx, rulesGameId, "user1", dbprovider.UserProperties{Score: 500}, 5000)
+		checkViolation(err, "max_delta")
+
+		data, err := service.GetUserScore(ctx, rulesGameId, "user1")
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.UScoreType(50), data.Score)
+
+		// the limit is reached by the previous submissions within the minute
+		err = service.SubmitUserScore(ctx, rulesGameId, "user1", dbprovider.UserProperties{Score: 60}, 5000)
+		checkViolation(err, "rate_limit")
+		err = service.SubmitUserScore(ctx, rulesGameId, "user2", dbprovider.UserProperties{Score: 60}, 5000)
+		require.NoError(t, err)
+
+		mockClock.SetTime(now.Add(61 * time.Second))
+		err = service.SubmitUserScore(ctx, rulesGameId, "user1", dbprovider.UserProperties{Score: 60}, 5000)
+		require.NoError(t, err)
+
+		// other boards and trusted writes are not checked
+		err = service.SubmitUserScore(ctx, gameId, "user1", dbprovider.UserProperties{Score: 1e300}, 0)
+		require.NoError(t, err)
+		err = service.PutUserScore(ctx, rulesGameId, "user1", dbprovider.UserProperties{Score: 1e300})
+		require.NoError(t, err)
+	})
+
+	runTest("approve and reject quarantined submissions", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		mockClock := (*service.clock).(*utils.MockClock)
+
+		for i, score := range []dbprovider.UScoreType{2000, 3000} {
+			mockClock.SetTime(now.Add(time.Duration(i) * time.Second))
+			err := service.SubmitUserScore(ctx, quarantineGameId, "user1", dbprovider.UserProperties{Score: score, Name: "name1"}, 0)
+			var ruleErr *RuleViolationError
+			require.ErrorAs(t, err, &ruleErr)
+			require.True(t, ruleErr.Quarantine)
+		}
+
+		data, err := service.GetUserScore(ctx, quarantineGameId, "user1")
+		require.NoError(t, err)
+		require.Nil(t, data)
+
+		items, err := service.ListQuarantined(ctx, quarantineGameId, 10)
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		require.Equal(t, "user1", items[0].UserId)
+		require.Equal(t, "score_range", items[0].Rule)
+		require.Equal(t, now.UnixMilli(), items[0].Ts)
+
+		item, err := service.RejectQuarantined(ctx, quarantineGameId, items[1].Id)
+		require.NoError(t, err)
+		require.Equal(t, items[1], *item)
+		item, err = service.ApproveQuarantined(ctx, quarantineGameId, items[0].Id)
+		require.NoError(t, err)
+		require.Equal(t, items[0], *item)
+		_, err = service.ApproveQuarantined(ctx, quarantineGameId, items[0].Id)
+		require.ErrorIs(t, err, ErrQuarantinedNotFound)
+
+		data, err = service.GetUserScore(ctx, quarantineGameId, "user1")
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.UScoreType(2000), data.Score)
+		require.Equal(t, "name1", data.Name)
+
+		items, err = service.ListQuarantined(ctx, quarantineGameId, 10)
+		require.NoError(t, err)
+		require.Empty(t, items)
+	})
+
+	runTest("approve quarantined submissions once", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+
+		err := service.SubmitUserScore(ctx, quarantineGameId, "user1", dbprovider.UserProperties{Score: 2000}, 0)
+		require.Error(t, err)
+		items, err := service.ListQuarantined(ctx, quarantineGameId, 10)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+
+		// the submission is put back if applying it fails
+		db := &countingDbProvider{IDbProvider: service.dbprovider, failPut: true}
+		service.dbprovider = db
+		_, err = service.ApproveQuarantined(ctx, quarantineGameId, items[0].Id)
+		require.Error(t, err)
+		restored, err := service.ListQuarantined(ctx, quarantineGameId, 10)
+		require.NoError(t, err)
+		require.Equal(t, items, restored)
+		db.failPut = false
+
+		var approved atomic.Int32
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := service.ApproveQuarantined(ctx, quarantineGameId, items[0].Id)
+				if err == nil {
+					approved.Add(1)
+				} else {
+					require.ErrorIs(t, err, ErrQuarantinedNotFound)
+				}
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, int32(1), approved.Load())
+
+		data, err := service.GetUserScore(ctx, quarantineGameId, "user1")
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.UScoreType(2000), data.Score)
+	})
+}
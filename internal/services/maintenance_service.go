@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/utils"
+	"math"
+	"time"
+)
+
+// Performs periodic background work on leaderboards (score decay, removal of expired and evicted entries)
+type MaintenanceService struct {
+	config        *config.Config
+	dbprovider    dbprovider.IDbProvider
+	clock         *utils.IClock
+	subscriptions *SubscriptionService // subscribers to tops notified of changes made by passes (nil - none)
+	changes       *ChangeService       // change feeds recording removals and decayed scores (nil - not recorded)
+	cancel        context.CancelFunc
+	done          chan struct{}
+}
+
+func NewMaintenanceService(config *config.Config, dbProvider dbprovider.IDbProvider) *MaintenanceService {
+	return &MaintenanceService{
+		config:     config,
+		dbprovider: dbProvider,
+	}
+}
+
+func (s *MaintenanceService) Initialize(ctx context.Context, clock *utils.IClock) error {
+	logger.Debug("Maintenance service initialization")
+
+	if s.dbprovider == nil {
+		return errors.New("uninitialized DB provider")
+	}
+
+	s.clock = clock
+
+	if !s.isRequired() {
+		return nil
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(time.Duration(s.config.MaintenanceInterval) * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+				err := s.Run(runCtx)
+				if err != nil && runCtx.Err() == nil {
+					logger.Error("Leaderboard maintenance failed", log.LogParams{"error": err})
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (s *MaintenanceService) isRequired() bool {
+	for _, board := range s.config.Boards {
+		if board.Decay != nil || board.Ttl != 0 || board.MaxEntries != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Performs a single maintenance pass over all configured leaderboards
+func (s *MaintenanceService) Run(ctx context.Context) error {
+	var err error
+
+	now := (*s.clock).Now().UnixMilli()
+
+	for gameId, board := range s.config.Boards {
+		var n, changed uint32
+		var rerr error
+
+		if board.Ttl != 0 {
+			n, rerr = s.record(ctx, gameId, func(ctx context.Context, rec dbprovider.WriteRecords) (uint32, error) {
+				return s.dbprovider.Expire(ctx, gameId, now-int64(board.Ttl), rec)
+			})
+			changed += n
+			err = errors.Join(err, rerr)
+		}
+		if board.Decay != nil {
+			n, rerr = s.applyDecay(ctx, gameId, board.Decay, now)
+			changed += n
+			err = errors.Join(err, rerr)
+		}
+		if board.MaxEntries != 0 {
+			n, rerr = s.record(ctx, gameId, func(ctx context.Context, rec dbprovider.WriteRecords) (uint32, error) {
+				return s.dbprovider.Trim(ctx, gameId, board.MaxEntries, rec)
+			})
+			changed += n
+			err = errors.Join(err, rerr)
+		}
+
+		if changed > 0 && s.subscriptions != nil {
+			s.subscriptions.NotifyChanged(gameId)
+		}
+	}
+
+	return err
+}
+
+// Applies the write recording the changes it makes to the change feed of the game (if it's enabled).
+// Returns the number of changes
+func (s *MaintenanceService) record(ctx context.Context, gameId string, write func(ctx context.Context, rec dbprovider.WriteRecords) (uint32, error)) (uint32, error) {
+	if s.changes == nil {
+		return write(ctx, dbprovider.WriteRecords{})
+	}
+	return s.changes.Record(ctx, gameId, write)
+}
+
+// Decays scores of inactive users, decayed scores of visible users are recorded as puts. Returns the number of updated scores
+func (s *MaintenanceService) applyDecay(ctx context.Context, gameId string, decay *config.DecayConfig, now int64) (uint32, error) {
+	var nUpdated uint32
+
+	before := now - int64(decay.Delay)
+	err := s.dbprovider.Inactive(ctx, gameId, before, dbprovider.UScoreType(decay.Floor), func(udata dbprovider.UserData) error {
+		if udata.Ts == 0 {
+			return nil // no info about the last activity
+		}
+
+		score := DecayScore(decay, udata.Base, now-udata.Ts)
+		if score == udata.Score {
+			return nil
+		}
+
+		n, err := s.record(ctx, gameId, func(ctx context.Context, rec dbprovider.WriteRecords) (uint32, error) {
+			if rec.Version != 0 {
+				state, err := s.dbprovider.GetUserState(ctx, gameId, udata.UserId)
+				if err != nil {
+					return 0, err
+				}
+				if state == dbprovider.USERSTATE_VISIBLE {
+					rec.Changes = []dbprovider.ChangeEntry{{
+						Op: dbprovider.CHANGEOP_PUT, UserId: udata.UserId, Score: score, Name: udata.Name, Params: udata.Params,
+					}}
+				} else {
+					rec.Version = 0
+				}
+			}
+
+			updated, err := s.dbprovider.SetScore(ctx, gameId, udata.UserId, score, udata.Ts, rec)
+			if !updated {
+				return 0, err // the entry was updated or removed in the meantime
+			}
+			return 1, err
+		})
+		nUpdated += n
+		return err
+	})
+
+	if nUpdated > 0 {
+		logger.Debug("Score decay applied", log.LogParams{"gameId": gameId, "nUpdated": int(nUpdated)})
+	}
+
+	return nUpdated, err
+}
+
+// Returns the decayed value of the submitted score after the specified inactivity time (ms)
+func DecayScore(decay *config.DecayConfig, base dbprovider.UScoreType, inactivity int64) dbprovider.UScoreType {
+	floor := dbprovider.UScoreType(decay.Floor)
+	elapsed := inactivity - int64(decay.Delay)
+	if elapsed <= 0 || base <= floor {
+		return base
+	}
+
+	periods := float64(elapsed) / float64(decay.Period)
+
+	var score dbprovider.UScoreType
+	switch decay.Type {
+	case config.DECAYTYPE_LINEAR:
+		score = base * dbprovider.UScoreType(math.Max(0, 1-decay.Rate*periods))
+	case config.DECAYTYPE_EXPONENTIAL:
+		score = floor + (base-floor)*dbprovider.UScoreType(math.Pow(1-decay.Rate, periods))
+	default:
+		return base
+	}
+
+	return max(score, floor)
+}
+
+func (s *MaintenanceService) Shutdown(ctx context.Context) error {
+	logger.Debug("Maintenance service shutdown")
+
+	if s.cancel == nil {
+		return nil
+	}
+
+	s.cancel()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	db_inmemory_provider "go-leaderboard-server/internal/db/inmemory"
+	"go-leaderboard-server/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const week = 7 * 24 * 3600 * 1000
+
+func TestDecayScore(t *testing.T) {
+	linear := &config.DecayConfig{
+		Type:   config.DECAYTYPE_LINEAR,
+		Rate:   0.1,
+		Period: week,
+		Delay:  week,
+		Floor:  20,
+	}
+
+	exponential := &config.DecayConfig{
+		Type:   config.DECAYTYPE_EXPONENTIAL,
+		Rate:   0.5,
+		Period: week,
+		Floor:  20,
+	}
+
+	require.Equal(t, dbprovider.UScoreType(100), DecayScore(linear, 100, week))
+	require.Equal(t, dbprovider.UScoreType(90), DecayScore(linear, 100, 2*week))
+	require.InDelta(t, 70, float64(DecayScore(linear, 100, 4*week)), 1e-9)
+	require.Equal(t, dbprovider.UScoreType(20), DecayScore(linear, 100, 20*week))
+	require.Equal(t, dbprovider.UScoreType(10), DecayScore(linear, 10, 20*week))
+
+	require.Equal(t, dbprovider.UScoreType(100), DecayScore(exponential, 100, 0))
+	require.Equal(t, dbprovider.UScoreType(60), DecayScore(exponential, 100, week))
+	require.Equal(t, dbprovider.UScoreType(40), DecayScore(exponential, 100, 2*week))
+	require.InDelta(t, 20, float64(DecayScore(exponential, 100, 100*week)), 1e-9)
+}
+
+func TestMaintenanceService(t *testing.T) {
+	gameId := "game1"
+	ttlGameId := "game3"
+	trimGameId := "game4"
+	now := time.UnixMilli(100 * week)
+
+	setupTest := func() (func() error, *MaintenanceService, error) {
+		var clock utils.IClock = &utils.MockClock{}
+		clock.(*utils.MockClock).SetTime(now)
+
+		dbProvider := db_inmemory_provider.NewDbInMemoryProvider()
+		err := dbProvider.Initialize(context.Background(), &db_inmemory_provider.DbInMemoryProviderConfig{})
+		if err != nil {
+			return func() error { return nil }, nil, err
+		}
+
+		conf := &config.Config{
+			Boards: map[string]config.BoardConfig{
+				gameId: {
+					Decay: &config.DecayConfig{
+						Type:   config.DECAYTYPE_LINEAR,
+						Rate:   0.5,
+						Period: week,
+					},
+				},
+				ttlGameId: {
+					Ttl: 3600000,
+				},
+				trimGameId: {
+					MaxEntries: 2,
+				},
+			},
+			MaintenanceInterval: 60000,
+		}
+
+		service := NewMaintenanceService(conf, dbProvider)
+		err = service.Initialize(context.Background(), &clock)
+		return func() error {
+			return service.Shutdown(context.Background())
+		}, service, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*MaintenanceService]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	runTest("decay scores of inactive users", func(t *testing.T, service *MaintenanceService) {
+		ctx := context.Background()
+		put := func(userId string, score dbprovider.UScoreType, ts int64) {
+			err := service.dbprovider.Put(ctx, gameId, userId, dbprovider.UserProperties{Score: score, Base: score, Ts: ts}, dbprovider.WriteRecords{})
+			require.NoError(t, err)
+		}
+
+		put("user1", 100, now.UnixMilli())
+		put("user2", 80, now.UnixMilli()-week)
+		put("user3", 60, 0)
+		err := service.dbprovider.Put(ctx, "game2", "user4", dbprovider.UserProperties{Score: 50, Base: 50, Ts: 1}, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+
+		err = service.Run(ctx)
+		require.NoError(t, err)
+
+		top, err := service.dbprovider.Top(ctx, gameId, 10, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{
+			{UserId: "user1", UserProperties: dbprovider.UserProperties{Score: 100, Base: 100, Ts: now.UnixMilli()}},
+			{UserId: "user3", UserProperties: dbprovider.UserProperties{Score: 60, Base: 60, Ts: 0}},
+			{UserId: "user2", UserProperties: dbprovider.UserProperties{Score: 40, Base: 80, Ts: now.UnixMilli() - week}},
+		}, top)
+
+		data, err := service.dbprovider.Get(ctx, "game2", "user4")
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.UScoreType(50), data.Score)
+
+		(*service.clock).(*utils.MockClock).SetTime(now.Add(time.Duration(week) * time.Millisecond))
+		err = service.Run(ctx)
+		require.NoError(t, err)
+
+		data, err = service.dbprovider.Get(ctx, gameId, "user2")
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.UScoreType(0), data.Score)
+		data, err = service.dbprovider.Get(ctx, gameId, "user1")
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.UScoreType(50), data.Score)
+	})
+
+	runTest("remove expired entries", func(t *testing.T, service *MaintenanceService) {
+		ctx := context.Background()
+		put := func(userId string, ts int64) {
+			err := service.dbprovider.Put(ctx, ttlGameId, userId, dbprovider.UserProperties{Score: 10, Base: 10, Ts: ts}, dbprovider.WriteRecords{})
+			require.NoError(t, err)
+		}
+
+		put("user1", now.UnixMilli()-3600*1000)
+		put("user2", now.UnixMilli()-3600*1000-1)
+
+		err := service.Run(ctx)
+		require.NoError(t, err)
+
+		data, err := service.dbprovider.Get(ctx, ttlGameId, "user1")
+		require.NoError(t, err)
+		require.NotNil(t, data)
+		data, err = service.dbprovider.Get(ctx, ttlGameId, "user2")
+		require.NoError(t, err)
+		require.Nil(t, data)
+	})
+
+	runTest("evict the lowest ranked entries", func(t *testing.T, service *MaintenanceService) {
+		ctx := context.Background()
+		for i, userId := range []string{"user1", "user2", "user3", "user4"} {
+			score := dbprovider.UScoreType(10 * (i + 1))
+			err := service.dbprovider.Put(ctx, trimGameId, userId, dbprovider.UserProperties{Score: score, Base: score, Ts: 1}, dbprovider.WriteRecords{})
+			require.NoError(t, err)
+		}
+
+		err := service.Run(ctx)
+		require.NoError(t, err)
+
+		top, err := service.dbprovider.Top(ctx, trimGameId, 10, dbprovider.TopOptions{})
+		require.NoError(t, err)
+		require.Equal(t, dbprovider.TopData{
+			{UserId: "user4", UserProperties: dbprovider.UserProperties{Score: 40, Base: 40, Ts: 1}},
+			{UserId: "user3", UserProperties: dbprovider.UserProperties{Score: 30, Base: 30, Ts: 1}},
+		}, top)
+
+		data, err := service.dbprovider.Get(ctx, trimGameId, "user1")
+		require.NoError(t, err)
+		require.Nil(t, data)
+	})
+
+	runTest("record changes", func(t *testing.T, service *MaintenanceService) {
+		ctx := context.Background()
+		service.config.Changes = &config.ChangesConfig{Retention: 100, MaxWait: 1000, PollInterval: 1000}
+		service.changes = NewChangeService(service.config, service.dbprovider)
+		require.NoError(t, service.changes.Initialize(ctx, service.clock))
+
+		put := func(gameId string, userId string, score dbprovider.UScoreType, ts int64) {
+			err := service.dbprovider.Put(ctx, gameId, userId, dbprovider.UserProperties{Score: score, Base: score, Ts: ts, Name: "n"}, dbprovider.WriteRecords{})
+			require.NoError(t, err)
+		}
+		put(gameId, "user1", 80, now.UnixMilli()-week)
+		put(ttlGameId, "user1", 10, now.UnixMilli()-3600*1000-1)
+		put(trimGameId, "user1", 10, 1)
+		put(trimGameId, "user2", 20, 1)
+		put(trimGameId, "user3", 30, 1)
+
+		err := service.Run(ctx)
+		require.NoError(t, err)
+
+		ts := now.UnixMilli()
+		for id, changes := range map[string][]dbprovider.ChangeEntry{
+			gameId:     {{Version: 1, Op: dbprovider.CHANGEOP_PUT, UserId: "user1", Score: 40, Name: "n", Ts: ts}},
+			ttlGameId:  {{Version: 1, Op: dbprovider.CHANGEOP_DELETE, UserId: "user1", Ts: ts}},
+			trimGameId: {{Version: 1, Op: dbprovider.CHANGEOP_DELETE, UserId: "user1", Ts: ts}},
+		} {
+			list, err := service.changes.GetChanges(ctx, id, 0, 10, 0)
+			require.NoError(t, err)
+			require.Equal(t, changes, list.Changes, id)
+		}
+
+		// nothing is changed by the next pass
+		err = service.Run(ctx)
+		require.NoError(t, err)
+		last, err := service.dbprovider.LastChange(ctx, gameId)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), last.Version)
+	})
+
+	runTest("notify subscriptions of changed boards", func(t *testing.T, service *MaintenanceService) {
+		ctx := context.Background()
+		hubs := make(map[string]*topHub)
+		for _, id := range []string{gameId, ttlGameId, trimGameId} {
+			hubs[id] = &topHub{gameId: id, changed: make(chan struct{}, 1)}
+		}
+		service.subscriptions = &SubscriptionService{hubs: hubs}
+		notified := func(id string) bool {
+			select {
+			case <-hubs[id].changed:
+				return true
+			default:
+				return false
+			}
+		}
+
+		err := service.dbprovider.Put(ctx, gameId, "user1", dbprovider.UserProperties{Score: 80, Base: 80, Ts: now.UnixMilli() - week}, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+		err = service.dbprovider.Put(ctx, trimGameId, "user1", dbprovider.UserProperties{Score: 10, Ts: 1}, dbprovider.WriteRecords{})
+		require.NoError(t, err)
+
+		err = service.Run(ctx)
+		require.NoError(t, err)
+		require.True(t, notified(gameId))
+		require.False(t, notified(ttlGameId))
+		require.False(t, notified(trimGameId))
+
+		// nothing is changed by the next pass
+		err = service.Run(ctx)
+		require.NoError(t, err)
+		require.False(t, notified(gameId))
+	})
+}
@@ -0,0 +1,386 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	quotaprovider "go-leaderboard-server/internal/quota"
+	quota_memory_provider "go-leaderboard-server/internal/quota/memory"
+	quota_redis_provider "go-leaderboard-server/internal/quota/redis"
+	"go-leaderboard-server/internal/utils"
+	"slices"
+)
+
+var ErrQuotaDisabled = errors.New("quotas are disabled")
+
+const (
+	QUOTA_WRITES_PER_DAY = "writes_per_day"
+	QUOTA_MAX_ENTRIES    = "max_entries"
+)
+
+const (
+	QUOTASCOPE_GAME   = "game"
+	QUOTASCOPE_TENANT = "tenant"
+)
+
+const quotaDay = 86400000 // ms
+
+// Interval of counting entries of tenants in the DB, removed entries are counted until the next interval (ms)
+const quotaEntriesInterval = 60000
+
+// Write rejected by a quota of the game or its tenant
+type QuotaExceededError struct {
+	Quota      string // QUOTA_*
+	Scope      string // QUOTASCOPE_*
+	RetryAfter int64  // Time until the quota is reset (ms, 0 - not reset by time)
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s quota of the %s is exceeded", e.Quota, e.Scope)
+}
+
+func (e *QuotaExceededError) ErrorCode() string {
+	return "quota_" + e.Quota
+}
+
+// Usage of a game or a tenant with its limits (0 - unlimited)
+type QuotaUsage struct {
+	Writes       int64  // Writes made today (UTC)
+	Entries      uint64 // Stored entries
+	WritesPerDay uint32
+	MaxEntries   uint32
+}
+
+type GameUsage struct {
+	GameId string // gameId within the tenant
+	QuotaUsage
+}
+
+type TenantUsage struct {
+	Tenant string // Tenant id (empty - default tenant)
+	QuotaUsage
+	Games []GameUsage // Games with writes since the quotas are enabled, sorted by gameId
+}
+
+// Counts writes and stored entries of games and tenants and rejects writes over their quotas
+type QuotaService struct {
+	config     *config.Config
+	dbprovider dbprovider.IDbProvider
+	clock      *utils.IClock
+	provider   quotaprovider.IQuotaProvider
+}
+
+func NewQuotaService(config *config.Config, dbProvider dbprovider.IDbProvider) *QuotaService {
+	return &QuotaService{
+		config:     config,
+		dbprovider: dbProvider,
+	}
+}
+
+func (s *QuotaService) Initialize(ctx context.Context, clock *utils.IClock) error {
+	logger.Debug("Quota service initialization")
+
+	s.clock = clock
+
+	if !s.IsEnabled() {
+		return nil
+	}
+
+	if s.dbprovider == nil {
+		return errors.New("uninitialized DB provider")
+	}
+
+	switch s.config.Quota.Type {
+	case config.QUOTATYPE_MEMORY:
+		s.provider = quota_memory_provider.NewQuotaMemoryProvider()
+	case config.QUOTATYPE_REDIS:
+		s.provider = quota_redis_provider.NewQuotaRedisProvider()
+	default:
+		return errors.New("unknown Quota provider type")
+	}
+
+	return s.provider.Initialize(ctx, s.config.Quota.Config)
+}
+
+func (s *QuotaService) IsEnabled() bool {
+	return s.config.Quota != nil
+}
+
+// Returns the limits of the game and of its tenant
+func (s *QuotaService) getLimits(gameId string) (config.QuotaLimits, config.QuotaLimits) {
+	tenant, _ := dbprovider.SplitTenantGameId(gameId)
+	return s.config.Quota.Games[gameId], s.config.Quota.Tenants[tenant]
+}
+
+// Reports whether new entries of the game are limited, so the caller has to find out if the write adds an entry
+func (s *QuotaService) LimitsEntries(gameId string) bool {
+	if !s.IsEnabled() {
+		return false
+	}
+	gameLimits, tenantLimits := s.getLimits(gameId)
+	return gameLimits.MaxEntries != 0 || tenantLimits.MaxEntries != 0
+}
+
+// Counter of a quota taken by a write
+type quotaCounter struct {
+	key string
+	exp int64 // Expiration time of the counter (unix ms)
+}
+
+// Quotas taken by a write before it's made, see ReserveWrite
+type QuotaReservation struct {
+	gameId     string
+	isNewEntry bool
+	writes     []quotaCounter // Counted writes, returned if the write fails
+	pending    []quotaCounter // Entries being added, released once the write is made or fails
+}
+
+// Takes the write from the daily quotas and, if it adds an entry, from the entries quotas of the game and its tenant.
+// Counters are incremented up to their limits atomically, so concurrent writes can't exceed the quotas together.
+// The reservation is completed by CompleteWrite once the write is made or fails (nil - quotas are disabled)
+func (s *QuotaService) ReserveWrite(ctx context.Context, gameId string, isNewEntry bool) (*QuotaReservation, error) {
+	if !s.IsEnabled() {
+		return nil, nil
+	}
+
+	now := (*s.clock).Now().UnixMilli()
+	day := now / quotaDay
+	exp := (day + 1) * quotaDay
+	tenant, _ := dbprovider.SplitTenantGameId(gameId)
+	gameLimits, tenantLimits := s.getLimits(gameId)
+	res := &QuotaReservation{gameId: gameId, isNewEntry: isNewEntry}
+
+	err := s.provider.AddMember(ctx, getTenantGamesKey(tenant), gameId)
+	if err != nil {
+		return nil, err
+	}
+
+	writes := []struct {
+		scope string
+		id    string
+		limit uint32
+	}{
+		{QUOTASCOPE_GAME, gameId, gameLimits.WritesPerDay},
+		{QUOTASCOPE_TENANT, tenant, tenantLimits.WritesPerDay},
+	}
+	for _, w := range writes {
+		counter := quotaCounter{key: getWritesKey(day, w.scope, w.id), exp: exp}
+		_, ok, err := s.provider.Incr(ctx, counter.key, 1, int64(w.limit), counter.exp, now)
+		if err == nil && !ok {
+			err = &QuotaExceededError{Quota: QUOTA_WRITES_PER_DAY, Scope: w.scope, RetryAfter: exp - now}
+		}
+		if err != nil {
+			return nil, errors.Join(err, s.CompleteWrite(ctx, res, false))
+		}
+		res.writes = append(res.writes, counter)
+	}
+
+	if !isNewEntry {
+		return res, nil
+	}
+
+	entries := []struct {
+		scope string
+		id    string
+		limit uint32
+		count func() (uint64, error)
+	}{
+		{QUOTASCOPE_GAME, gameId, gameLimits.MaxEntries, func() (uint64, error) { return s.countEntries(ctx, gameId) }},
+		{QUOTASCOPE_TENANT, tenant, tenantLimits.MaxEntries, func() (uint64, error) { return s.countTenantEntries(ctx, tenant, gameId, now) }},
+	}
+	for _, e := range entries {
+		if e.limit == 0 {
+			continue
+		}
+
+		// the entry is pending before the stored entries are counted, so entries added meanwhile are counted at least once
+		counter := quotaCounter{key: getPendingEntriesKey(e.scope, e.id), exp: now + quotaEntriesInterval}
+		pending, _, err := s.provider.Incr(ctx, counter.key, 1, 0, counter.exp, now)
+		if err != nil {
+			return nil, errors.Join(err, s.CompleteWrite(ctx, res, false))
+		}
+		res.pending = append(res.pending, counter)
+
+		n, err := e.count()
+		if err == nil && n+uint64(pending) > uint64(e.limit) {
+			err = &QuotaExceededError{Quota: QUOTA_MAX_ENTRIES, Scope: e.scope}
+		}
+		if err != nil {
+			return nil, errors.Join(err, s.CompleteWrite(ctx, res, false))
+		}
+	}
+
+	return res, nil
+}
+
+// Completes the reservation of the write: returns the counted write if it isn't made, adds the entry to the counted entries
+// of the tenant if it's made, and releases the pending entries
+func (s *QuotaService) CompleteWrite(ctx context.Context, res *QuotaReservation, made bool) error {
+	if res == nil {
+		return nil
+	}
+
+	now := (*s.clock).Now().UnixMilli()
+	var err error
+
+	if !made {
+		for _, counter := range res.writes {
+			_, _, e := s.provider.Incr(ctx, counter.key, -1, 0, counter.exp, now)
+			err = errors.Join(err, e)
+		}
+	}
+
+	tenant, _ := dbprovider.SplitTenantGameId(res.gameId)
+	if _, tenantLimits := s.getLimits(res.gameId); made && res.isNewEntry && tenantLimits.MaxEntries != 0 {
+		// the entry is added to the counted entries of the tenant, until they are counted again in the DB
+		entriesKey, entriesExp := getTenantEntriesKey(tenant, now)
+		entries, e := s.provider.Get(ctx, []string{entriesKey}, now)
+		if e == nil && entries[0] != 0 {
+			_, _, e = s.provider.Incr(ctx, entriesKey, 1, 0, entriesExp, now)
+		}
+		err = errors.Join(err, e)
+	}
+
+	for _, counter := range res.pending {
+		_, _, e := s.provider.Incr(ctx, counter.key, -1, 0, counter.exp, now)
+		err = errors.Join(err, e)
+	}
+
+	return err
+}
+
+// Returns the number of stored entries of the tenant. Entries of all games of the tenant are counted in the DB once
+// per quotaEntriesInterval and kept in a counter, entries added meanwhile are added to it by CompleteWrite
+func (s *QuotaService) countTenantEntries(ctx context.Context, tenant string, gameId string, now int64) (uint64, error) {
+	entriesKey, entriesExp := getTenantEntriesKey(tenant, now)
+	entries, err := s.provider.Get(ctx, []string{entriesKey}, now)
+	if err != nil {
+		return 0, err
+	}
+	if entries[0] > 0 {
+		return uint64(entries[0]), nil
+	}
+
+	gameIds, err := s.provider.Members(ctx, getTenantGamesKey(tenant))
+	if err != nil {
+		return 0, err
+	}
+	if !slices.Contains(gameIds, gameId) {
+		gameIds = append(gameIds, gameId)
+	}
+
+	var total uint64
+	for _, id := range gameIds {
+		n, err := s.countEntries(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+
+	if total > 0 {
+		// the limit equal to the total keeps the counter from being set twice by instances counting at the same time
+		_, _, err = s.provider.Incr(ctx, entriesKey, int64(total), int64(total), entriesExp, now)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return total, nil
+}
+
+// Returns the usage of the tenant and its games
+func (s *QuotaService) GetUsage(ctx context.Context, tenant string) (*TenantUsage, error) {
+	if !s.IsEnabled() {
+		return nil, ErrQuotaDisabled
+	}
+
+	now := (*s.clock).Now().UnixMilli()
+	day := now / quotaDay
+
+	gameIds, err := s.provider.Members(ctx, getTenantGamesKey(tenant))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(gameIds)+1)
+	for _, gameId := range gameIds {
+		keys = append(keys, getWritesKey(day, QUOTASCOPE_GAME, gameId))
+	}
+	keys = append(keys, getWritesKey(day, QUOTASCOPE_TENANT, tenant))
+	writes, err := s.provider.Get(ctx, keys, now)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantLimits := s.config.Quota.Tenants[tenant]
+	usage := &TenantUsage{
+		Tenant: tenant,
+		QuotaUsage: QuotaUsage{
+			Writes:       writes[len(gameIds)],
+			WritesPerDay: tenantLimits.WritesPerDay,
+			MaxEntries:   tenantLimits.MaxEntries,
+		},
+		Games: make([]GameUsage, 0, len(gameIds)),
+	}
+	for i, gameId := range gameIds {
+		n, err := s.countEntries(ctx, gameId)
+		if err != nil {
+			return nil, err
+		}
+
+		gameLimits := s.config.Quota.Games[gameId]
+		_, tenantGameId := dbprovider.SplitTenantGameId(gameId)
+		usage.Games = append(usage.Games, GameUsage{
+			GameId: tenantGameId,
+			QuotaUsage: QuotaUsage{
+				Writes:       writes[i],
+				Entries:      n,
+				WritesPerDay: gameLimits.WritesPerDay,
+				MaxEntries:   gameLimits.MaxEntries,
+			},
+		})
+		usage.Entries += n
+	}
+
+	return usage, nil
+}
+
+// Returns the number of stored entries of the game (runs for runs boards)
+func (s *QuotaService) countEntries(ctx context.Context, gameId string) (uint64, error) {
+	if s.config.GetBoardConfig(gameId).Type == config.BOARDTYPE_RUNS {
+		return s.dbprovider.CountRuns(ctx, gameId)
+	}
+	return s.dbprovider.Count(ctx, gameId)
+}
+
+func getWritesKey(day int64, scope string, id string) string {
+	return fmt.Sprintf("writes:%d:%s:%s", day, scope, id)
+}
+
+// Returns the key of the counted entries of the tenant in the current interval and the expiration time of the key
+func getTenantEntriesKey(tenant string, now int64) (string, int64) {
+	interval := now / quotaEntriesInterval
+	return fmt.Sprintf("entries:%d:%s", interval, tenant), (interval + 1) * quotaEntriesInterval
+}
+
+// Returns the key of the entries of the game or the tenant that are being added
+func getPendingEntriesKey(scope string, id string) string {
+	return fmt.Sprintf("pending:%s:%s", scope, id)
+}
+
+func getTenantGamesKey(tenant string) string {
+	return "games:" + tenant
+}
+
+func (s *QuotaService) Shutdown(ctx context.Context) error {
+	logger.Debug("Quota service shutdown")
+
+	if s.provider == nil {
+		return nil
+	}
+
+	return s.provider.Shutdown(ctx)
+}
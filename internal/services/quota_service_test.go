@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	cacheprovider "go-leaderboard-server/internal/cache"
+	cache_simple_provider "go-leaderboard-server/internal/cache/simple"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	db_inmemory_provider "go-leaderboard-server/internal/db/inmemory"
+	quota_memory_provider "go-leaderboard-server/internal/quota/memory"
+	"go-leaderboard-server/internal/utils"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Counts entry counts made in the DB and fails puts on demand
+type countingDbProvider struct {
+	dbprovider.IDbProvider
+	counts  int
+	failPut bool
+}
+
+func (p *countingDbProvider) Count(ctx context.Context, gameId string) (uint64, error) {
+	p.counts++
+	return p.IDbProvider.Count(ctx, gameId)
+}
+
+func (p *countingDbProvider) Put(ctx context.Context, gameId string, userId string, userProp dbprovider.UserProperties, rec dbprovider.WriteRecords) error {
+	if p.failPut {
+		return errors.New("put failed")
+	}
+	return p.IDbProvider.Put(ctx, gameId, userId, userProp, rec)
+}
+
+func TestQuotaService(t *testing.T) {
+	writesGameId := "game1"
+	entriesGameId := "game2"
+	runsGameId := "game3"
+	tenantGameId1 := dbprovider.TenantGameId("tenant1", "game1")
+	tenantGameId2 := dbprovider.TenantGameId("tenant1", "game2")
+	now := time.UnixMilli(1000000)
+	dayEnd := time.UnixMilli(quotaDay)
+
+	setupTest := func() (func() error, *LeaderboardService, error) {
+		var clock utils.IClock = &utils.MockClock{}
+		clock.(*utils.MockClock).SetTime(now)
+
+		conf := &config.Config{
+			Db: config.DbConfig{
+				Type:   config.DBTYPE_INMEMORY,
+				Config: &db_inmemory_provider.DbInMemoryProviderConfig{},
+			},
+			Cache: config.CacheConfig{
+				Type: config.CACHETYPE_SIMPLE,
+				Config: &cache_simple_provider.CacheSimpleProviderConfig{
+					CacheProviderBaseConfig: cacheprovider.CacheProviderBaseConfig{Ttl: 1000},
+				},
+			},
+			Boards: map[string]config.BoardConfig{
+				runsGameId: {Type: config.BOARDTYPE_RUNS, RunsPerUser: 2},
+			},
+			Quota: &config.QuotaConfig{
+				Type:   config.QUOTATYPE_MEMORY,
+				Config: &quota_memory_provider.QuotaMemoryProviderConfig{},
+				Games: map[string]config.QuotaLimits{
+					writesGameId:  {WritesPerDay: 2},
+					entriesGameId: {MaxEntries: 2},
+					runsGameId:    {MaxEntries: 3},
+				},
+				Tenants: map[string]config.QuotaLimits{
+					"tenant1": {WritesPerDay: 3, MaxEntries: 2},
+				},
+			},
+		}
+
+		service := NewLeaderboardService(conf)
+		err := service.Initialize(context.Background(), &clock)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		service.quota = NewQuotaService(conf, service.dbprovider)
+		err = service.quota.Initialize(context.Background(), &clock)
+		return func() error {
+			return service.Shutdown(context.Background())
+		}, service, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*LeaderboardService]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	runTest("limit writes per day", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		mockClock := (*service.clock).(*utils.MockClock)
+
+		for i := 0; i < 2; i++ {
+			err := service.PutUserScore(ctx, writesGameId, "user1", dbprovider.UserProperties{Score: 10})
+			require.NoError(t, err)
+		}
+		err := service.PutUserScore(ctx, writesGameId, "user2", dbprovider.UserProperties{Score: 10})
+		var quotaErr *QuotaExceededError
+		require.ErrorAs(t, err, &quotaErr)
+		require.Equal(t, QUOTA_WRITES_PER_DAY, quotaErr.Quota)
+		require.Equal(t, QUOTASCOPE_GAME, quotaErr.Scope)
+		require.Equal(t, dayEnd.Sub(now).Milliseconds(), quotaErr.RetryAfter)
+		require.Equal(t, "quota_writes_per_day", quotaErr.ErrorCode())
+
+		props, err := service.GetUserScore(ctx, writesGameId, "user2")
+		require.NoError(t, err)
+		require.Nil(t, props)
+
+		// deletions are not limited
+		err = service.DeleteUserScore(ctx, writesGameId, "user1")
+		require.NoError(t, err)
+
+		mockClock.SetTime(dayEnd)
+		err = service.PutUserScore(ctx, writesGameId, "user2", dbprovider.UserProperties{Score: 10})
+		require.NoError(t, err)
+	})
+
+	runTest("limit entries", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+
+		for _, userId := range []string{"user1", "user2", "user1"} {
+			err := service.PutUserScore(ctx, entriesGameId, userId, dbprovider.UserProperties{Score: 10})
+			require.NoError(t, err)
+		}
+		err := service.PutUserScore(ctx, entriesGameId, "user3", dbprovider.UserProperties{Score: 10})
+		var quotaErr *QuotaExceededError
+		require.ErrorAs(t, err, &quotaErr)
+		require.Equal(t, QUOTA_MAX_ENTRIES, quotaErr.Quota)
+		require.Zero(t, quotaErr.RetryAfter)
+
+		err = service.DeleteUserScore(ctx, entriesGameId, "user1")
+		require.NoError(t, err)
+		err = service.PutUserScore(ctx, entriesGameId, "user3", dbprovider.UserProperties{Score: 10})
+		require.NoError(t, err)
+	})
+
+	runTest("limit runs", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+
+		for _, runId := range []string{"run1", "run2", "run3", "run1"} {
+			err := service.PutUserRun(ctx, runsGameId, "user1", dbprovider.RunProperties{RunId: runId, Score: 10})
+			require.NoError(t, err)
+		}
+		err := service.PutUserRun(ctx, runsGameId, "user2", dbprovider.RunProperties{RunId: "run1", Score: 10})
+		require.NoError(t, err)
+
+		err = service.PutUserRun(ctx, runsGameId, "user3", dbprovider.RunProperties{RunId: "run1", Score: 10})
+		var quotaErr *QuotaExceededError
+		require.ErrorAs(t, err, &quotaErr)
+		require.Equal(t, QUOTA_MAX_ENTRIES, quotaErr.Quota)
+
+		// the worst run of the user is replaced
+		err = service.PutUserRun(ctx, runsGameId, "user1", dbprovider.RunProperties{RunId: "run4", Score: 20})
+		require.NoError(t, err)
+	})
+
+	runTest("limit tenants", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+
+		err := service.PutUserScore(ctx, tenantGameId1, "user1", dbprovider.UserProperties{Score: 10})
+		require.NoError(t, err)
+		err = service.PutUserScore(ctx, tenantGameId2, "user2", dbprovider.UserProperties{Score: 10})
+		require.NoError(t, err)
+
+		err = service.PutUserScore(ctx, tenantGameId2, "user3", dbprovider.UserProperties{Score: 10})
+		var quotaErr *QuotaExceededError
+		require.ErrorAs(t, err, &quotaErr)
+		require.Equal(t, QUOTA_MAX_ENTRIES, quotaErr.Quota)
+		require.Equal(t, QUOTASCOPE_TENANT, quotaErr.Scope)
+
+		// games of the default tenant are not affected
+		err = service.PutUserScore(ctx, "game4", "user3", dbprovider.UserProperties{Score: 10})
+		require.NoError(t, err)
+
+		err = service.PutUserScore(ctx, tenantGameId1, "user1", dbprovider.UserProperties{Score: 20})
+		require.NoError(t, err)
+		err = service.PutUserScore(ctx, tenantGameId1, "user1", dbprovider.UserProperties{Score: 30})
+		require.ErrorAs(t, err, &quotaErr)
+		require.Equal(t, QUOTA_WRITES_PER_DAY, quotaErr.Quota)
+		require.Equal(t, QUOTASCOPE_TENANT, quotaErr.Scope)
+	})
+
+	runTest("count made writes only", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		db := &countingDbProvider{IDbProvider: service.dbprovider, failPut: true}
+		service.dbprovider = db
+
+		err := service.PutUserScore(ctx, writesGameId, "user1", dbprovider.UserProperties{Score: 10})
+		require.Error(t, err)
+
+		db.failPut = false
+		for i := 0; i < 2; i++ {
+			err = service.PutUserScore(ctx, writesGameId, "user1", dbprovider.UserProperties{Score: 10})
+			require.NoError(t, err)
+		}
+
+		usage, err := service.quota.GetUsage(ctx, "")
+		require.NoError(t, err)
+		require.Equal(t, int64(2), usage.Games[0].Writes)
+	})
+
+	runTest("reserve quotas of concurrent writes", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		db := &countingDbProvider{IDbProvider: service.dbprovider, failPut: true}
+		service.dbprovider = db
+
+		// entries of failed writes are released
+		err := service.PutUserScore(ctx, entriesGameId, "user0", dbprovider.UserProperties{Score: 10})
+		require.Error(t, err)
+		db.failPut = false
+
+		put := func(gameId string) int {
+			var (
+				wg   sync.WaitGroup
+				made atomic.Int32
+			)
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					err := service.PutUserScore(ctx, gameId, fmt.Sprintf("user%d", i+1), dbprovider.UserProperties{Score: 10})
+					if err == nil {
+						made.Add(1)
+					}
+				}()
+			}
+			wg.Wait()
+			return int(made.Load())
+		}
+
+		require.Equal(t, 2, put(writesGameId))
+		require.Equal(t, 2, put(entriesGameId))
+		n, err := service.dbprovider.Count(ctx, entriesGameId)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), n)
+	})
+
+	runTest("count entries of tenants once per interval", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		mockClock := (*service.clock).(*utils.MockClock)
+		db := &countingDbProvider{IDbProvider: service.dbprovider}
+		service.dbprovider = db
+		service.quota.dbprovider = db
+
+		err := service.PutUserScore(ctx, tenantGameId1, "user1", dbprovider.UserProperties{Score: 10})
+		require.NoError(t, err)
+		err = service.PutUserScore(ctx, tenantGameId2, "user2", dbprovider.UserProperties{Score: 10})
+		require.NoError(t, err)
+		counts := db.counts
+
+		// the entry added after the count is added to the counted entries
+		err = service.PutUserScore(ctx, tenantGameId2, "user3", dbprovider.UserProperties{Score: 10})
+		var quotaErr *QuotaExceededError
+		require.ErrorAs(t, err, &quotaErr)
+		require.Equal(t, QUOTASCOPE_TENANT, quotaErr.Scope)
+		require.Equal(t, counts, db.counts)
+
+		// removed entries are counted until the next interval
+		err = service.DeleteUserScore(ctx, tenantGameId1, "user1")
+		require.NoError(t, err)
+		err = service.PutUserScore(ctx, tenantGameId2, "user3", dbprovider.UserProperties{Score: 10})
+		require.ErrorAs(t, err, &quotaErr)
+
+		mockClock.SetTime(now.Add(quotaEntriesInterval * time.Millisecond))
+		err = service.PutUserScore(ctx, tenantGameId2, "user3", dbprovider.UserProperties{Score: 10})
+		require.NoError(t, err)
+		require.Equal(t, counts+2, db.counts)
+	})
+
+	runTest("report usage", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+
+		err := service.PutUserScore(ctx, tenantGameId1, "user1", dbprovider.UserProperties{Score: 10})
+		require.NoError(t, err)
+		err = service.PutUserScore(ctx, tenantGameId1, "user1", dbprovider.UserProperties{Score: 20})
+		require.NoError(t, err)
+		err = service.PutUserScore(ctx, tenantGameId2, "user2", dbprovider.UserProperties{Score: 10})
+		require.NoError(t, err)
+		err = service.PutUserScore(ctx, tenantGameId2, "user3", dbprovider.UserProperties{Score: 10})
+		require.Error(t, err)
+		err = service.PutUserScore(ctx, writesGameId, "user1", dbprovider.UserProperties{Score: 10})
+		require.NoError(t, err)
+
+		usage, err := service.quota.GetUsage(ctx, "tenant1")
+		require.NoError(t, err)
+		require.Equal(t, &TenantUsage{
+			Tenant:     "tenant1",
+			QuotaUsage: QuotaUsage{Writes: 3, Entries: 2, WritesPerDay: 3, MaxEntries: 2},
+			Games: []GameUsage{
+				{GameId: "game1", QuotaUsage: QuotaUsage{Writes: 2, Entries: 1}},
+				{GameId: "game2", QuotaUsage: QuotaUsage{Writes: 1, Entries: 1}},
+			},
+		}, usage)
+
+		usage, err = service.quota.GetUsage(ctx, "")
+		require.NoError(t, err)
+		require.Equal(t, &TenantUsage{
+			QuotaUsage: QuotaUsage{Writes: 1, Entries: 1},
+			Games: []GameUsage{
+				{GameId: "game1", QuotaUsage: QuotaUsage{Writes: 1, Entries: 1, WritesPerDay: 2}},
+			},
+		}, usage)
+	})
+}
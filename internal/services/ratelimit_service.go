@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	log "go-leaderboard-server/internal/logger"
+	ratelimitprovider "go-leaderboard-server/internal/ratelimit"
+	ratelimit_memory_provider "go-leaderboard-server/internal/ratelimit/memory"
+	ratelimit_redis_provider "go-leaderboard-server/internal/ratelimit/redis"
+	"go-leaderboard-server/internal/utils"
+)
+
+var ErrRateLimited = errors.New("too many requests")
+
+// Identities of a request that are limited separately before authentication (empty values are not limited),
+// users are limited once the request is authenticated by TakeUser
+type RateLimitIdentity struct {
+	Ip     string
+	KeyId  string // Id of the authenticated API key (keys that are not authenticated are not limited)
+	Tenant string // Tenant of the API key (empty - default tenant, not limited)
+}
+
+// Limits request rates of route groups by token buckets
+type RateLimitService struct {
+	config   *config.Config
+	clock    *utils.IClock
+	provider ratelimitprovider.IRateLimitProvider
+}
+
+func NewRateLimitService(config *config.Config) *RateLimitService {
+	return &RateLimitService{
+		config: config,
+	}
+}
+
+func (s *RateLimitService) Initialize(ctx context.Context, clock *utils.IClock) error {
+	logger.Debug("Rate limit service initialization")
+
+	s.clock = clock
+
+	if !s.IsEnabled() {
+		return nil
+	}
+
+	switch s.config.RateLimit.Type {
+	case config.RATELIMITTYPE_MEMORY:
+		s.provider = ratelimit_memory_provider.NewRateLimitMemoryProvider()
+	case config.RATELIMITTYPE_REDIS:
+		s.provider = ratelimit_redis_provider.NewRateLimitRedisProvider()
+	default:
+		return errors.New("unknown Rate limit provider type")
+	}
+
+	return s.provider.Initialize(ctx, s.config.RateLimit.Config)
+}
+
+func (s *RateLimitService) IsEnabled() bool {
+	return s.config.RateLimit != nil
+}
+
+// Returns the limits of the route group (nil - not limited)
+func (s *RateLimitService) GetGroupConfig(group string) *config.RateLimitGroupConfig {
+	if !s.IsEnabled() {
+		return nil
+	}
+	groupConf, ok := s.config.RateLimit.Groups[group]
+	if !ok {
+		return nil
+	}
+	return &groupConf
+}
+
+// Limited bucket of an identity of a request
+type rateLimitBucket struct {
+	kind  string
+	value string
+	limit *ratelimitprovider.Limit
+}
+
+// Takes a token of every limited identity of the request except the user.
+// Returns the time until the request can be repeated (ms) and ErrRateLimited if any of the buckets is empty,
+// tokens taken from the other buckets are returned then, so rejected requests don't use up the limits
+func (s *RateLimitService) Take(ctx context.Context, group string, identity RateLimitIdentity) (int64, error) {
+	groupConf := s.GetGroupConfig(group)
+	if groupConf == nil {
+		return 0, nil
+	}
+
+	return s.take(ctx, group, []rateLimitBucket{
+		{"ip", identity.Ip, groupConf.Ip},
+		{"key", identity.KeyId, groupConf.ApiKey},
+		{"tenant", identity.Tenant, groupConf.Tenant},
+	})
+}
+
+// Returns the user the requests are limited as: the subject of the token or the user of the client key (nil - not sent),
+// otherwise the userId of the request params, limited separately for every API key that sends it.
+// Users of different tenants are limited separately
+func RateLimitUser(apiKey *ApiKey, claims *JwtClaims, tenant string, userId string) string {
+	switch {
+	case claims != nil:
+		userId = claims.Subject
+	case apiKey != nil && apiKey.Role == config.ROLE_CLIENT:
+		userId = apiKey.UserId
+	case apiKey != nil && userId != "":
+		userId = apiKey.Id + ":" + userId
+	}
+	if userId != "" && tenant != "" {
+		userId = tenant + dbprovider.TENANT_SEPARATOR + userId
+	}
+	return userId
+}
+
+// Takes a token of the user of the request (see RateLimitUser) once the request is authenticated,
+// the tokens of its other identities are taken by Take before and returned if the user bucket is empty
+func (s *RateLimitService) TakeUser(ctx context.Context, group string, identity RateLimitIdentity, user string) (int64, error) {
+	groupConf := s.GetGroupConfig(group)
+	if groupConf == nil || groupConf.User == nil || user == "" {
+		return 0, nil
+	}
+
+	retryAfter, err := s.take(ctx, group, []rateLimitBucket{{"user", user, groupConf.User}})
+	if err == ErrRateLimited {
+		s.refund(ctx, group, []rateLimitBucket{
+			{"ip", identity.Ip, groupConf.Ip},
+			{"key", identity.KeyId, groupConf.ApiKey},
+			{"tenant", identity.Tenant, groupConf.Tenant},
+		})
+	}
+	return retryAfter, err
+}
+
+// Takes a token of every limited bucket, returns the taken tokens if any of the buckets is empty
+func (s *RateLimitService) take(ctx context.Context, group string, buckets []rateLimitBucket) (int64, error) {
+	now := (*s.clock).Now().UnixMilli()
+
+	for i, b := range buckets {
+		if b.limit == nil || b.value == "" {
+			continue
+		}
+		retryAfter, err := s.provider.Take(ctx, group+":"+b.kind+":"+b.value, *b.limit, now)
+		if err != nil {
+			return 0, err // the request is let through, the taken tokens are kept
+		}
+		if retryAfter == 0 {
+			continue
+		}
+
+		s.refund(ctx, group, buckets[:i])
+		return retryAfter, ErrRateLimited
+	}
+
+	return 0, nil
+}
+
+func (s *RateLimitService) refund(ctx context.Context, group string, buckets []rateLimitBucket) {
+	for _, b := range buckets {
+		if b.limit == nil || b.value == "" {
+			continue
+		}
+		err := s.provider.Refund(ctx, group+":"+b.kind+":"+b.value, *b.limit)
+		if err != nil {
+			logger.Error("Failed to refund rate limit token", log.LogParams{"error": err, "bucket": b.kind})
+		}
+	}
+}
+
+func (s *RateLimitService) Shutdown(ctx context.Context) error {
+	logger.Debug("Rate limit service shutdown")
+
+	if s.provider == nil {
+		return nil
+	}
+
+	return s.provider.Shutdown(ctx)
+}
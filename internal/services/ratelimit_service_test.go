@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	ratelimitprovider "go-leaderboard-server/internal/ratelimit"
+	ratelimit_memory_provider "go-leaderboard-server/internal/ratelimit/memory"
+	"go-leaderboard-server/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitService(t *testing.T) {
+	now := time.UnixMilli(1000000)
+
+	setupTest := func() (func() error, *RateLimitService, error) {
+		var clock utils.IClock = &utils.MockClock{}
+		clock.(*utils.MockClock).SetTime(now)
+
+		conf := &config.Config{
+			RateLimit: &config.RateLimitConfig{
+				Type:   config.RATELIMITTYPE_MEMORY,
+				Config: &ratelimit_memory_provider.RateLimitMemoryProviderConfig{},
+				Groups: map[string]config.RateLimitGroupConfig{
+					config.ROUTEGROUP_LEADERBOARD: {
+						Ip:   &ratelimitprovider.Limit{Burst: 3, Rate: 1},
+						User: &ratelimitprovider.Limit{Burst: 1, Rate: 0.5},
+					},
+					config.ROUTEGROUP_ADMIN: {
+						User:   &ratelimitprovider.Limit{Burst: 1, Rate: 1},
+						Tenant: &ratelimitprovider.Limit{Burst: 2, Rate: 1},
+					},
+				},
+			},
+		}
+
+		service := NewRateLimitService(conf)
+		err := service.Initialize(context.Background(), &clock)
+		return func() error {
+			return service.Shutdown(context.Background())
+		}, service, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*RateLimitService]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	// takes the tokens of a request the way the rate limit middlewares do
+	take := func(service *RateLimitService, group string, identity RateLimitIdentity, user string) (int64, error) {
+		retryAfter, err := service.Take(context.Background(), group, identity)
+		if err != nil {
+			return retryAfter, err
+		}
+		return service.TakeUser(context.Background(), group, identity, user)
+	}
+
+	runTest("limit identities", func(t *testing.T, service *RateLimitService) {
+		ctx := context.Background()
+		mockClock := (*service.clock).(*utils.MockClock)
+
+		require.Nil(t, service.GetGroupConfig("other"))
+		for i := 0; i < 10; i++ {
+			_, err := take(service, "other", RateLimitIdentity{Ip: "10.0.0.1"}, "user1")
+			require.NoError(t, err)
+		}
+		_, err := service.TakeUser(ctx, "other", RateLimitIdentity{}, "user1")
+		require.NoError(t, err)
+
+		_, err = take(service, config.ROUTEGROUP_LEADERBOARD, RateLimitIdentity{Ip: "10.0.0.1"}, "user1")
+		require.NoError(t, err)
+		retryAfter, err := take(service, config.ROUTEGROUP_LEADERBOARD, RateLimitIdentity{Ip: "10.0.0.2"}, "user1")
+		require.ErrorIs(t, err, ErrRateLimited)
+		require.Equal(t, int64(2000), retryAfter)
+
+		_, err = take(service, config.ROUTEGROUP_LEADERBOARD, RateLimitIdentity{Ip: "10.0.0.1"}, "user2")
+		require.NoError(t, err)
+		_, err = take(service, config.ROUTEGROUP_LEADERBOARD, RateLimitIdentity{Ip: "10.0.0.1"}, "user3")
+		require.NoError(t, err)
+		retryAfter, err = take(service, config.ROUTEGROUP_LEADERBOARD, RateLimitIdentity{Ip: "10.0.0.1"}, "user4")
+		require.ErrorIs(t, err, ErrRateLimited)
+		require.Equal(t, int64(1000), retryAfter)
+
+		mockClock.SetTime(now.Add(2 * time.Second))
+		_, err = take(service, config.ROUTEGROUP_LEADERBOARD, RateLimitIdentity{Ip: "10.0.0.2"}, "user1")
+		require.NoError(t, err)
+		_, err = take(service, config.ROUTEGROUP_LEADERBOARD, RateLimitIdentity{Ip: "10.0.0.1"}, "user4")
+		require.NoError(t, err)
+	})
+
+	runTest("limit users by their credentials", func(t *testing.T, service *RateLimitService) {
+		clientKey := &ApiKey{Id: "client1", Role: config.ROLE_CLIENT, UserId: "user1"}
+		serverKey := &ApiKey{Id: "server1", Role: config.ROLE_SERVER}
+		claims := &JwtClaims{Subject: "user2"}
+
+		// userIds of the params don't change the authenticated user
+		require.Equal(t, "user1", RateLimitUser(clientKey, nil, "", "user3"))
+		require.Equal(t, "user2", RateLimitUser(serverKey, claims, "", "user3"))
+		require.Equal(t, "tenant1"+dbprovider.TENANT_SEPARATOR+"user2", RateLimitUser(nil, claims, "tenant1", "user3"))
+
+		// userIds sent by server keys are limited per key
+		require.Equal(t, "server1:user3", RateLimitUser(serverKey, nil, "", "user3"))
+		require.Equal(t, "", RateLimitUser(serverKey, nil, "", ""))
+		require.Equal(t, "user3", RateLimitUser(nil, nil, "", "user3"))
+	})
+
+	runTest("limit tenants", func(t *testing.T, service *RateLimitService) {
+		// the default tenant is not limited
+		for i := 0; i < 10; i++ {
+			_, err := take(service, config.ROUTEGROUP_ADMIN, RateLimitIdentity{KeyId: "key1"}, "")
+			require.NoError(t, err)
+		}
+
+		// users of different tenants are limited separately
+		_, err := take(service, config.ROUTEGROUP_ADMIN, RateLimitIdentity{KeyId: "key2", Tenant: "tenant1"}, RateLimitUser(nil, nil, "tenant1", "user1"))
+		require.NoError(t, err)
+		_, err = take(service, config.ROUTEGROUP_ADMIN, RateLimitIdentity{KeyId: "key3", Tenant: "tenant2"}, RateLimitUser(nil, nil, "tenant2", "user1"))
+		require.NoError(t, err)
+
+		// all keys of the tenant share its limit
+		_, err = take(service, config.ROUTEGROUP_ADMIN, RateLimitIdentity{KeyId: "key4", Tenant: "tenant1"}, "")
+		require.NoError(t, err)
+		retryAfter, err := take(service, config.ROUTEGROUP_ADMIN, RateLimitIdentity{KeyId: "key5", Tenant: "tenant1"}, "")
+		require.ErrorIs(t, err, ErrRateLimited)
+		require.Equal(t, int64(1000), retryAfter)
+	})
+
+	runTest("refund tokens of rejected requests", func(t *testing.T, service *RateLimitService) {
+		_, err := take(service, config.ROUTEGROUP_LEADERBOARD, RateLimitIdentity{Ip: "10.0.0.1"}, "user1")
+		require.NoError(t, err)
+
+		// requests rejected by the user bucket don't use up the IP bucket
+		for i := 0; i < 10; i++ {
+			_, err = take(service, config.ROUTEGROUP_LEADERBOARD, RateLimitIdentity{Ip: "10.0.0.1"}, "user1")
+			require.ErrorIs(t, err, ErrRateLimited)
+		}
+		_, err = take(service, config.ROUTEGROUP_LEADERBOARD, RateLimitIdentity{Ip: "10.0.0.1"}, "user2")
+		require.NoError(t, err)
+		_, err = take(service, config.ROUTEGROUP_LEADERBOARD, RateLimitIdentity{Ip: "10.0.0.1"}, "user3")
+		require.NoError(t, err)
+		_, err = take(service, config.ROUTEGROUP_LEADERBOARD, RateLimitIdentity{Ip: "10.0.0.1"}, "user4")
+		require.ErrorIs(t, err, ErrRateLimited)
+	})
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	"math"
+	"sync"
+)
+
+// Score submission checked by anti-cheat rules
+type ScoreSubmission struct {
+	GameId   string
+	UserId   string
+	Score    dbprovider.UScoreType
+	Duration uint32 // Match duration reported by the client (ms, 0 - not reported)
+	Ts       int64  // Time of the submission (unix ms)
+	// Returns the previous score of the user (nil - no score), loaded on demand
+	GetPrevious func(ctx context.Context) (*dbprovider.UScoreType, error)
+}
+
+type IScoreRule interface {
+	// Rule name, used as an error code
+	Name() string
+	// Returns the reason of the violation (empty string - no violation)
+	Check(ctx context.Context, sub *ScoreSubmission) (string, error)
+}
+
+type RuleViolationError struct {
+	Rule       string
+	Reason     string
+	Quarantine bool // The submission should be held instead of being rejected
+}
+
+func (e *RuleViolationError) Error() string {
+	return fmt.Sprintf("score rejected by rule %s: %s", e.Rule, e.Reason)
+}
+
+func (e *RuleViolationError) ErrorCode() string {
+	return e.Rule
+}
+
+// Builds the rules pipeline of a board
+func NewScoreRules(conf *config.RulesConfig) []IScoreRule {
+	rules := make([]IScoreRule, 0)
+	if conf == nil {
+		return rules
+	}
+
+	if conf.MinScore != nil || conf.MaxScore != nil {
+		rules = append(rules, &scoreRangeRule{min: conf.MinScore, max: conf.MaxScore})
+	}
+	if conf.MinDuration != 0 {
+		rules = append(rules, &minDurationRule{minDuration: conf.MinDuration})
+	}
+	if conf.MaxSubmissions != 0 {
+		rules = append(rules, newSubmissionRateRule(conf.MaxSubmissions))
+	}
+	if conf.MaxDelta != nil {
+		rules = append(rules, &maxDeltaRule{maxDelta: *conf.MaxDelta})
+	}
+
+	return rules
+}
+
+type scoreRangeRule struct {
+	min *float64
+	max *float64
+}
+
+func (r *scoreRangeRule) Name() string {
+	return "score_range"
+}
+
+func (r *scoreRangeRule) Check(ctx context.Context, sub *ScoreSubmission) (string, error) {
+	if r.min != nil && float64(sub.Score) < *r.min {
+		return fmt.Sprintf("score is less than %g", *r.min), nil
+	}
+	if r.max != nil && float64(sub.Score) > *r.max {
+		return fmt.Sprintf("score is greater than %g", *r.max), nil
+	}
+	return "", nil
+}
+
+type minDurationRule struct {
+	minDuration uint32
+}
+
+func (r *minDurationRule) Name() string {
+	return "min_duration"
+}
+
+func (r *minDurationRule) Check(ctx context.Context, sub *ScoreSubmission) (string, error) {
+	if sub.Duration < r.minDuration {
+		return fmt.Sprintf("match duration is less than %d ms", r.minDuration), nil
+	}
+	return "", nil
+}
+
+type maxDeltaRule struct {
+	maxDelta float64
+}
+
+func (r *maxDeltaRule) Name() string {
+	return "max_delta"
+}
+
+func (r *maxDeltaRule) Check(ctx context.Context, sub *ScoreSubmission) (string, error) {
+	prev, err := sub.GetPrevious(ctx)
+	if err != nil || prev == nil {
+		return "", err
+	}
+	if math.Abs(float64(sub.Score-*prev)) > r.maxDelta {
+		return fmt.Sprintf("score differs from the previous one by more than %g", r.maxDelta), nil
+	}
+	return "", nil
+}
+
+// Limits the number of submissions of a user within a sliding minute (in-process, per instance)
+type submissionRateRule struct {
+	maxSubmissions uint32
+	mutex          sync.Mutex
+	submissions    map[string][]int64 // submission times of users within the last minute
+	lastSweep      int64
+}
+
+const submissionRateWindow int64 = 60000 // ms
+
+func newSubmissionRateRule(maxSubmissions uint32) *submissionRateRule {
+	return &submissionRateRule{
+		maxSubmissions: maxSubmissions,
+		submissions:    make(map[string][]int64),
+	}
+}
+
+func (r *submissionRateRule) Name() string {
+	return "rate_limit"
+}
+
+func (r *submissionRateRule) Check(ctx context.Context, sub *ScoreSubmission) (string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	minTs := sub.Ts - submissionRateWindow
+
+	// drop users without recent submissions once per window
+	if sub.Ts-r.lastSweep >= submissionRateWindow {
+		for userId, times := range r.submissions {
+			if len(times) == 0 || times[len(times)-1] <= minTs {
+				delete(r.submissions, userId)
+			}
+		}
+		r.lastSweep = sub.Ts
+	}
+
+	times := r.submissions[sub.UserId]
+	i := 0
+	for i < len(times) && times[i] <= minTs {
+		i++
+	}
+	times = append(times[i:], sub.Ts)
+	r.submissions[sub.UserId] = times
+
+	if len(times) > int(r.maxSubmissions) {
+		return fmt.Sprintf("more than %d submissions per minute", r.maxSubmissions), nil
+	}
+	return "", nil
+}
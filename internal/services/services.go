@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"go-leaderboard-server/internal/config"
 	log "go-leaderboard-server/internal/logger"
 	"go-leaderboard-server/internal/utils"
@@ -11,7 +12,19 @@ import (
 var logger = log.GetLogger()
 
 type Services struct {
-	LeaderboardService *LeaderboardService
+	LeaderboardService  *LeaderboardService
+	MaintenanceService  *MaintenanceService
+	SignatureService    *SignatureService
+	AuthService         *AuthService
+	JwtService          *JwtService
+	RateLimitService    *RateLimitService
+	IdempotencyService  *IdempotencyService
+	AuditService        *AuditService
+	QuotaService        *QuotaService
+	ChangeService       *ChangeService
+	SubscriptionService *SubscriptionService
+	WebhookService      *WebhookService
+	EventService        *EventService
 }
 
 func InitializeServices(ctx context.Context, config *config.Config, clock *utils.IClock, services *Services) error {
@@ -24,8 +37,91 @@ func InitializeServices(ctx context.Context, config *config.Config, clock *utils
 
 	services.LeaderboardService = NewLeaderboardService(config)
 	err = services.LeaderboardService.Initialize(ctxInit, clock)
+	if err != nil {
+		return err
+	}
 
-	return err
+	services.MaintenanceService = NewMaintenanceService(config, services.LeaderboardService.dbprovider)
+	err = services.MaintenanceService.Initialize(ctxInit, clock)
+	if err != nil {
+		return err
+	}
+
+	services.SignatureService = NewSignatureService(config)
+	err = services.SignatureService.Initialize(ctxInit, clock)
+	if err != nil {
+		return err
+	}
+
+	services.AuthService = NewAuthService(config)
+	err = services.AuthService.Initialize(ctxInit, clock)
+	if err != nil {
+		return err
+	}
+
+	services.JwtService = NewJwtService(config)
+	err = services.JwtService.Initialize(ctxInit, clock)
+	if err != nil {
+		return err
+	}
+
+	services.RateLimitService = NewRateLimitService(config)
+	err = services.RateLimitService.Initialize(ctxInit, clock)
+	if err != nil {
+		return err
+	}
+
+	services.IdempotencyService = NewIdempotencyService(config)
+	err = services.IdempotencyService.Initialize(ctxInit, clock)
+	if err != nil {
+		return err
+	}
+	services.SignatureService.idempotency = services.IdempotencyService
+
+	services.AuditService = NewAuditService(config, services.LeaderboardService.dbprovider)
+	err = services.AuditService.Initialize(ctxInit, clock)
+	if err != nil {
+		return err
+	}
+
+	services.QuotaService = NewQuotaService(config, services.LeaderboardService.dbprovider)
+	err = services.QuotaService.Initialize(ctxInit, clock)
+	if err != nil {
+		return err
+	}
+	services.LeaderboardService.quota = services.QuotaService
+
+	services.ChangeService = NewChangeService(config, services.LeaderboardService.dbprovider)
+	err = services.ChangeService.Initialize(ctxInit, clock)
+	if err != nil {
+		return err
+	}
+	services.LeaderboardService.changes = services.ChangeService
+	services.MaintenanceService.changes = services.ChangeService
+
+	services.SubscriptionService = NewSubscriptionService(config, services.LeaderboardService)
+	err = services.SubscriptionService.Initialize(ctxInit, clock)
+	if err != nil {
+		return err
+	}
+	services.LeaderboardService.subscriptions = services.SubscriptionService
+	services.MaintenanceService.subscriptions = services.SubscriptionService
+
+	services.WebhookService = NewWebhookService(config, services.LeaderboardService)
+	err = services.WebhookService.Initialize(ctxInit, clock)
+	if err != nil {
+		return err
+	}
+	services.LeaderboardService.webhooks = services.WebhookService
+
+	services.EventService = NewEventService(config, services.LeaderboardService)
+	err = services.EventService.Initialize(ctxInit, clock)
+	if err != nil {
+		return err
+	}
+	services.LeaderboardService.events = services.EventService
+
+	return nil
 }
 
 func ShutdownServices(ctx context.Context, config *config.Config, services *Services) error {
@@ -36,8 +132,56 @@ func ShutdownServices(ctx context.Context, config *config.Config, services *Serv
 	ctxShutdown, cancelShutdown := utils.GetContextByTimeout(ctx, time.Duration(config.TimeoutServicesShutdown)*time.Millisecond)
 	defer cancelShutdown()
 
+	if services.EventService != nil {
+		err = services.EventService.Shutdown(ctxShutdown)
+	}
+
+	if services.WebhookService != nil {
+		err = errors.Join(err, services.WebhookService.Shutdown(ctxShutdown))
+	}
+
+	if services.SubscriptionService != nil {
+		err = errors.Join(err, services.SubscriptionService.Shutdown(ctxShutdown))
+	}
+
+	if services.ChangeService != nil {
+		err = errors.Join(err, services.ChangeService.Shutdown(ctxShutdown))
+	}
+
+	if services.QuotaService != nil {
+		err = errors.Join(err, services.QuotaService.Shutdown(ctxShutdown))
+	}
+
+	if services.AuditService != nil {
+		err = errors.Join(err, services.AuditService.Shutdown(ctxShutdown))
+	}
+
+	if services.IdempotencyService != nil {
+		err = errors.Join(err, services.IdempotencyService.Shutdown(ctxShutdown))
+	}
+
+	if services.RateLimitService != nil {
+		err = errors.Join(err, services.RateLimitService.Shutdown(ctxShutdown))
+	}
+
+	if services.JwtService != nil {
+		err = errors.Join(err, services.JwtService.Shutdown(ctxShutdown))
+	}
+
+	if services.AuthService != nil {
+		err = errors.Join(err, services.AuthService.Shutdown(ctxShutdown))
+	}
+
+	if services.SignatureService != nil {
+		err = errors.Join(err, services.SignatureService.Shutdown(ctxShutdown))
+	}
+
+	if services.MaintenanceService != nil {
+		err = errors.Join(err, services.MaintenanceService.Shutdown(ctxShutdown))
+	}
+
 	if services.LeaderboardService != nil {
-		err = services.LeaderboardService.Shutdown(ctxShutdown)
+		err = errors.Join(err, services.LeaderboardService.Shutdown(ctxShutdown))
 	}
 
 	return err
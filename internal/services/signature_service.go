@@ -0,0 +1,212 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-leaderboard-server/internal/config"
+	"go-leaderboard-server/internal/utils"
+	"strconv"
+	"sync"
+)
+
+var (
+	ErrSignatureMissing = errors.New("signature is missing")
+	ErrSignatureInvalid = errors.New("signature is invalid")
+	ErrSignatureExpired = errors.New("signature timestamp is out of the allowed window")
+	ErrSignatureReplay  = errors.New("signature nonce has already been used")
+)
+
+const maxNonceLength = 64
+
+// Verifies HMAC signatures of score submissions sent by trusted game servers.
+// The signature is HMAC-SHA256 (hex) of "timestamp\nnonce\ncanonical body" with a secret of the board
+type SignatureService struct {
+	config      *config.Config
+	clock       *utils.IClock
+	idempotency *IdempotencyService // store of used nonces shared by server instances (nil or disabled - nonces are kept in memory)
+	mutex       sync.Mutex
+	nonces      map[string]int64 // used nonces (key - gameId:nonce, value - time they can be forgotten, unix ms)
+	lastSweep   int64
+}
+
+func NewSignatureService(config *config.Config) *SignatureService {
+	return &SignatureService{
+		config: config,
+		nonces: make(map[string]int64),
+	}
+}
+
+func (s *SignatureService) Initialize(ctx context.Context, clock *utils.IClock) error {
+	logger.Debug("Signature service initialization")
+
+	s.clock = clock
+
+	return nil
+}
+
+// Checks whether submissions of the game must be signed
+func (s *SignatureService) IsRequired(gameId string) bool {
+	return len(s.config.GetBoardConfig(gameId).Secrets) > 0
+}
+
+// Verifies the signature of the request body, every nonce is accepted only once within the window
+func (s *SignatureService) Verify(ctx context.Context, gameId string, timestamp string, nonce string, signature string, body []byte) error {
+	err := s.Check(gameId, timestamp, nonce, signature, body)
+	if err != nil {
+		return err
+	}
+	return s.UseNonce(ctx, gameId, timestamp, nonce)
+}
+
+// Verifies the signature of the request body without using up its nonce, see UseNonce
+func (s *SignatureService) Check(gameId string, timestamp string, nonce string, signature string, body []byte) error {
+	if timestamp == "" || nonce == "" || signature == "" {
+		return ErrSignatureMissing
+	}
+	if len(nonce) > maxNonceLength {
+		return ErrSignatureInvalid
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+
+	now := (*s.clock).Now().UnixMilli()
+	window := int64(s.config.SignatureWindow)
+	if ts < now-window || ts > now+window {
+		return ErrSignatureExpired
+	}
+
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+
+	valid := false
+	for _, secret := range s.config.GetBoardConfig(gameId).Secrets {
+		expected, err := sign(secret, timestamp, nonce, body)
+		if err != nil {
+			return ErrSignatureInvalid
+		}
+		if hmac.Equal(sig, expected) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+// Remembers the nonce of a checked request until it leaves the window, returns ErrSignatureReplay if it is already used.
+// Nonces are kept in the idempotency store if it's enabled, so they are shared by all server instances.
+// Only nonces of authentic requests must be used, so they can't be taken by someone else
+func (s *SignatureService) UseNonce(ctx context.Context, gameId string, timestamp string, nonce string) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+
+	now := (*s.clock).Now().UnixMilli()
+	key := gameId + ":" + nonce
+	until := ts + int64(s.config.SignatureWindow)
+
+	used := false
+	if s.idempotency != nil && s.idempotency.IsEnabled() {
+		// Check keeps the timestamp within the window, so the nonce is kept for at least 1 ms
+		claimed, err := s.idempotency.Claim(ctx, "nonce:"+key, uint32(max(until-now, 1)))
+		if err != nil {
+			return err
+		}
+		used = !claimed
+	} else {
+		used = !s.useNonce(key, now, until)
+	}
+	if used {
+		return ErrSignatureReplay
+	}
+
+	return nil
+}
+
+func (s *SignatureService) Shutdown(ctx context.Context) error {
+	logger.Debug("Signature service shutdown")
+
+	/* do nothing */
+
+	return nil
+}
+
+// Remembers the nonce until the specified time, returns false if it is already known
+func (s *SignatureService) useNonce(key string, now int64, until int64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// drop outdated nonces once per window
+	if now-s.lastSweep >= int64(s.config.SignatureWindow) {
+		for k, exp := range s.nonces {
+			if exp < now {
+				delete(s.nonces, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if exp, ok := s.nonces[key]; ok && exp >= now {
+		return false
+	}
+	s.nonces[key] = until
+
+	return true
+}
+
+// Returns the HMAC signature (hex) of the request, as expected by the server
+func Sign(secret string, timestamp string, nonce string, body []byte) (string, error) {
+	mac, err := sign(secret, timestamp, nonce, body)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(mac), nil
+}
+
+func sign(secret string, timestamp string, nonce string, body []byte) ([]byte, error) {
+	canonical, err := CanonicalBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n", timestamp, nonce)
+	mac.Write(canonical)
+	return mac.Sum(nil), nil
+}
+
+// Returns the canonical form of JSON body: object keys sorted, no insignificant whitespace, no HTML escaping
+func CanonicalBody(body []byte) ([]byte, error) {
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber() // keep numbers exactly as they were sent
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	err = encoder.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"go-leaderboard-server/internal/config"
+	idempotency_memory_provider "go-leaderboard-server/internal/idempotency/memory"
+	"go-leaderboard-server/internal/utils"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalBody(t *testing.T) {
+	canonical, err := CanonicalBody([]byte(`{ "userId": "user1", "gameId": "game1",
+		"score": 1500.50, "params": "<a&b>", "extra": { "b": 1, "a": [2, 1] } }`))
+	require.NoError(t, err)
+	require.Equal(t, `{"extra":{"a":[2,1],"b":1},"gameId":"game1","params":"<a&b>","score":1500.50,"userId":"user1"}`,
+		string(canonical))
+
+	_, err = CanonicalBody([]byte(`{ "gameId": `))
+	require.Error(t, err)
+}
+
+func TestSignatureService(t *testing.T) {
+	gameId := "game1"
+	signedGameId := "game2"
+	oldSecret := "old-secret-0123456789"
+	newSecret := "new-secret-0123456789"
+	now := time.UnixMilli(1000000)
+	body := []byte(`{"gameId": "game2", "userId": "user1", "score": 100}`)
+
+	setupTest := func() (func() error, *SignatureService, error) {
+		var clock utils.IClock = &utils.MockClock{}
+		clock.(*utils.MockClock).SetTime(now)
+
+		conf := &config.Config{
+			SignatureWindow: 60000,
+			Boards: map[string]config.BoardConfig{
+				signedGameId: {Secrets: []string{oldSecret, newSecret}},
+			},
+		}
+
+		service := NewSignatureService(conf)
+		err := service.Initialize(context.Background(), &clock)
+		return func() error {
+			return service.Shutdown(context.Background())
+		}, service, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*SignatureService]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	runTest("verify signature", func(t *testing.T, service *SignatureService) {
+		ctx := context.Background()
+		require.False(t, service.IsRequired(gameId))
+		require.True(t, service.IsRequired(signedGameId))
+
+		ts := strconv.FormatInt(now.UnixMilli(), 10)
+		for i, secret := range []string{oldSecret, newSecret} {
+			nonce := "nonce" + strconv.Itoa(i)
+			sig, err := Sign(secret, ts, nonce, body)
+			require.NoError(t, err)
+			err = service.Verify(ctx, signedGameId, ts, nonce, sig, body)
+			require.NoError(t, err)
+		}
+
+		// whitespace and key order don't change the signature
+		sig, err := Sign(newSecret, ts, "nonce2", body)
+		require.NoError(t, err)
+		err = service.Verify(ctx, signedGameId, ts, "nonce2", sig, []byte(`{"score":100,"userId":"user1","gameId":"game2"}`))
+		require.NoError(t, err)
+
+		sig, err = Sign("unknown-secret-0123456789", ts, "nonce3", body)
+		require.NoError(t, err)
+		err = service.Verify(ctx, signedGameId, ts, "nonce3", sig, body)
+		require.ErrorIs(t, err, ErrSignatureInvalid)
+
+		sig, err = Sign(newSecret, ts, "nonce3", body)
+		require.NoError(t, err)
+		err = service.Verify(ctx, signedGameId, ts, "nonce3", sig, []byte(`{"gameId": "game2", "userId": "user1", "score": 1000}`))
+		require.ErrorIs(t, err, ErrSignatureInvalid)
+		err = service.Verify(ctx, signedGameId, ts, "nonce3", "", body)
+		require.ErrorIs(t, err, ErrSignatureMissing)
+	})
+
+	runTest("reject replays", func(t *testing.T, service *SignatureService) {
+		ctx := context.Background()
+		mockClock := (*service.clock).(*utils.MockClock)
+
+		ts := strconv.FormatInt(now.UnixMilli(), 10)
+		sig, err := Sign(newSecret, ts, "nonce1", body)
+		require.NoError(t, err)
+
+		// checks don't use up the nonce
+		err = service.Check(signedGameId, ts, "nonce1", sig, body)
+		require.NoError(t, err)
+		err = service.Verify(ctx, signedGameId, ts, "nonce1", sig, body)
+		require.NoError(t, err)
+		err = service.Verify(ctx, signedGameId, ts, "nonce1", sig, body)
+		require.ErrorIs(t, err, ErrSignatureReplay)
+		err = service.UseNonce(ctx, signedGameId, ts, "nonce1")
+		require.ErrorIs(t, err, ErrSignatureReplay)
+
+		mockClock.SetTime(now.Add(61 * time.Second))
+		err = service.Verify(ctx, signedGameId, ts, "nonce1", sig, body)
+		require.ErrorIs(t, err, ErrSignatureExpired)
+
+		oldTs := strconv.FormatInt(now.Add(-time.Second).UnixMilli(), 10)
+		mockClock.SetTime(now)
+		sig, err = Sign(newSecret, oldTs, "nonce2", body)
+		require.NoError(t, err)
+		err = service.Verify(ctx, signedGameId, oldTs, "nonce2", sig, body)
+		require.NoError(t, err)
+	})
+
+	runTest("share nonces through the idempotency store", func(t *testing.T, service *SignatureService) {
+		ctx := context.Background()
+
+		conf := &config.Config{
+			Idempotency: &config.IdempotencyConfig{
+				Type:   config.IDEMPOTENCYTYPE_MEMORY,
+				Config: &idempotency_memory_provider.IdempotencyMemoryProviderConfig{},
+			},
+		}
+		idempotency := NewIdempotencyService(conf)
+		require.NoError(t, idempotency.Initialize(ctx, service.clock))
+		defer idempotency.Shutdown(ctx)
+
+		// another server instance with the same store
+		other := NewSignatureService(service.config)
+		require.NoError(t, other.Initialize(ctx, service.clock))
+		service.idempotency = idempotency
+		other.idempotency = idempotency
+
+		ts := strconv.FormatInt(now.UnixMilli(), 10)
+		sig, err := Sign(newSecret, ts, "nonce1", body)
+		require.NoError(t, err)
+		err = service.Verify(ctx, signedGameId, ts, "nonce1", sig, body)
+		require.NoError(t, err)
+		err = other.Verify(ctx, signedGameId, ts, "nonce1", sig, body)
+		require.ErrorIs(t, err, ErrSignatureReplay)
+	})
+}
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/utils"
+	"slices"
+	"sync"
+	"time"
+)
+
+var ErrTooManySubscriptions = errors.New("too many subscriptions")
+var ErrSlowConsumer = errors.New("subscriber doesn't keep up with updates")
+var ErrSubscriptionsClosed = errors.New("subscriptions are closed")
+
+const (
+	TOPUPDATE_SNAPSHOT = "snapshot" // Update contains the whole top
+	TOPUPDATE_DIFF     = "diff"     // Update contains only changes since the previous update of the subscription
+)
+
+// Entry of a top sent to subscribers
+type TopEntry struct {
+	Rank   int                   `json:"rank"` // 1-based position in the top
+	UserId string                `json:"userId"`
+	RunId  string                `json:"runId,omitempty"` // runs boards only
+	Score  dbprovider.UScoreType `json:"score"`
+	Name   string                `json:"name,omitempty"`
+	Params string                `json:"params,omitempty"`
+	Ts     int64                 `json:"ts,omitempty"` // Time of the run submission (runs boards only)
+}
+
+// Key of the entry within the top: userId, or userId/runId on runs boards
+func (e *TopEntry) Key() string {
+	if e.RunId == "" {
+		return e.UserId
+	}
+	return e.UserId + "/" + e.RunId
+}
+
+type TopUpdate struct {
+	Type    string     `json:"type"`              // TOPUPDATE_*
+	Seq     uint64     `json:"seq"`               // Number of the update within the subscription, starting from 1
+	Entries []TopEntry `json:"entries"`           // Snapshot - the whole top, diff - new entries and entries with changed rank or data
+	Removed []string   `json:"removed,omitempty"` // Diff - keys of entries that left the top
+}
+
+// Subscription to changes of a top. The first update is always a snapshot
+type TopSubscription struct {
+	Updates <-chan TopUpdate // Closed when the subscription ends, see Err
+
+	service *SubscriptionService
+	gameId  string
+	nTop    uint32
+	diff    bool
+	updates chan TopUpdate
+	mu      sync.Mutex
+	closed  bool
+	err     error
+	seq     uint64     // accessed by the worker of the board only
+	last    []TopEntry // last sent top (nil - nothing sent yet)
+}
+
+// Returns the reason the subscription has ended (nil - it's active or closed by the subscriber)
+func (sub *TopSubscription) Err() error {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	return sub.err
+}
+
+// Ends the subscription, its updates channel is closed
+func (sub *TopSubscription) Close() {
+	sub.service.unsubscribe(sub, nil)
+}
+
+// Queues the update without blocking. Returns false if the queue is full
+func (sub *TopSubscription) send(update TopUpdate) bool {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.closed {
+		return true
+	}
+	select {
+	case sub.updates <- update:
+		return true
+	default:
+		return false
+	}
+}
+
+// Subscriptions of a board with the worker sending them updates
+type topHub struct {
+	gameId  string
+	subs    map[*TopSubscription]struct{}
+	changed chan struct{} // changes are coalesced until the worker reads the top
+	cancel  context.CancelFunc
+}
+
+// Pushes changes of tops to subscribers. Changes are signaled by the write path of the leaderboard service
+// and the maintenance, so only writes made by this server instance are noticed
+type SubscriptionService struct {
+	config      *config.Config
+	leaderboard *LeaderboardService
+	clock       *utils.IClock
+	mu          sync.Mutex
+	hubs        map[string]*topHub // key - gameId
+	nSubs       int
+	closed      bool
+	wg          sync.WaitGroup
+}
+
+func NewSubscriptionService(config *config.Config, leaderboard *LeaderboardService) *SubscriptionService {
+	return &SubscriptionService{
+		config:      config,
+		leaderboard: leaderboard,
+	}
+}
+
+func (s *SubscriptionService) Initialize(ctx context.Context, clock *utils.IClock) error {
+	logger.Debug("Subscription service initialization")
+
+	if s.leaderboard == nil {
+		return errors.New("uninitialized leaderboard service")
+	}
+
+	s.clock = clock
+	s.hubs = make(map[string]*topHub)
+
+	return nil
+}
+
+// Subscribes to the top of the board. In the diff mode only changes are sent after the first snapshot
+func (s *SubscriptionService) Subscribe(gameId string, nTop uint32, diff bool) (*TopSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, ErrSubscriptionsClosed
+	}
+	maxSubs := s.config.Subscriptions.MaxSubscribers
+	if maxSubs != 0 && s.nSubs >= int(maxSubs) {
+		return nil, ErrTooManySubscriptions
+	}
+
+	updates := make(chan TopUpdate, s.config.Subscriptions.BufferSize)
+	sub := &TopSubscription{
+		Updates: updates,
+		service: s,
+		gameId:  gameId,
+		nTop:    nTop,
+		diff:    diff,
+		updates: updates,
+	}
+
+	hub, ok := s.hubs[gameId]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		hub = &topHub{
+			gameId:  gameId,
+			subs:    make(map[*TopSubscription]struct{}),
+			changed: make(chan struct{}, 1),
+			cancel:  cancel,
+		}
+		s.hubs[gameId] = hub
+		s.wg.Add(1)
+		go s.run(ctx, hub)
+	}
+	hub.subs[sub] = struct{}{}
+	s.nSubs++
+
+	signalChange(hub.changed) // the snapshot of the new subscriber
+	return sub, nil
+}
+
+// Signals that the top of the board may have changed
+func (s *SubscriptionService) NotifyChanged(gameId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if hub, ok := s.hubs[gameId]; ok {
+		signalChange(hub.changed)
+	}
+}
+
+func signalChange(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+func (s *SubscriptionService) unsubscribe(sub *TopSubscription, reason error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hub, ok := s.hubs[sub.gameId]
+	if !ok {
+		return
+	}
+	if _, ok = hub.subs[sub]; !ok {
+		return
+	}
+
+	delete(hub.subs, sub)
+	s.nSubs--
+	if len(hub.subs) == 0 {
+		hub.cancel()
+		delete(s.hubs, sub.gameId)
+	}
+
+	sub.mu.Lock()
+	sub.closed = true
+	sub.err = reason
+	close(sub.updates)
+	sub.mu.Unlock()
+}
+
+// Sends updates of the board on its changes, not more often than once per the interval
+func (s *SubscriptionService) run(ctx context.Context, hub *topHub) {
+	defer s.wg.Done()
+
+	interval := time.Duration(s.config.Subscriptions.Interval) * time.Millisecond
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hub.changed:
+		}
+
+		err := s.publish(ctx, hub)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("Failed to publish top updates", log.LogParams{"error": err, "gameId": hub.gameId})
+		}
+
+		if interval > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	}
+}
+
+// Reads the current top (bypassing the cache) and sends each subscriber its changes
+func (s *SubscriptionService) publish(ctx context.Context, hub *topHub) error {
+	s.mu.Lock()
+	subs := make([]*TopSubscription, 0, len(hub.subs))
+	nTop := uint32(0)
+	for sub := range hub.subs {
+		subs = append(subs, sub)
+		nTop = max(nTop, sub.nTop)
+	}
+	s.mu.Unlock()
+
+	if len(subs) == 0 {
+		return nil
+	}
+
+	top, err := s.leaderboard.readTop(ctx, hub.gameId, nTop)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		entries := top[:min(int(sub.nTop), len(top))]
+		update, changed := buildUpdate(sub, entries)
+		if !changed {
+			continue
+		}
+
+		if !sub.send(update) {
+			logger.Warn("Slow subscriber is dropped", log.LogParams{"gameId": hub.gameId})
+			s.unsubscribe(sub, ErrSlowConsumer)
+			continue
+		}
+		sub.last = entries
+	}
+
+	return nil
+}
+
+// Returns the update of the subscriber to the top and whether there is anything to send
+func buildUpdate(sub *TopSubscription, entries []TopEntry) (TopUpdate, bool) {
+	if sub.last == nil || !sub.diff {
+		if sub.last != nil && slices.Equal(sub.last, entries) {
+			return TopUpdate{}, false
+		}
+		sub.seq++
+		return TopUpdate{Type: TOPUPDATE_SNAPSHOT, Seq: sub.seq, Entries: entries}, true
+	}
+
+	prev := make(map[string]TopEntry, len(sub.last))
+	for _, e := range sub.last {
+		prev[e.Key()] = e
+	}
+
+	update := TopUpdate{Type: TOPUPDATE_DIFF, Entries: []TopEntry{}}
+	for _, e := range entries {
+		if p, ok := prev[e.Key()]; !ok || p != e {
+			update.Entries = append(update.Entries, e)
+		}
+		delete(prev, e.Key())
+	}
+	for _, e := range sub.last {
+		if _, ok := prev[e.Key()]; ok {
+			update.Removed = append(update.Removed, e.Key())
+		}
+	}
+
+	if len(update.Entries) == 0 && len(update.Removed) == 0 {
+		return TopUpdate{}, false
+	}
+	sub.seq++
+	update.Seq = sub.seq
+	return update, true
+}
+
+// Ends all subscriptions with ErrSubscriptionsClosed and rejects new ones
+func (s *SubscriptionService) Close() {
+	s.mu.Lock()
+	s.closed = true
+	subs := make([]*TopSubscription, 0, s.nSubs)
+	for _, hub := range s.hubs {
+		for sub := range hub.subs {
+			subs = append(subs, sub)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, sub := range subs {
+		s.unsubscribe(sub, ErrSubscriptionsClosed)
+	}
+}
+
+func (s *SubscriptionService) Shutdown(ctx context.Context) error {
+	logger.Debug("Subscription service shutdown")
+
+	if s.hubs == nil {
+		return nil
+	}
+
+	s.Close()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
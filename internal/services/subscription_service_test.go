@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	cacheprovider "go-leaderboard-server/internal/cache"
+	cache_simple_provider "go-leaderboard-server/internal/cache/simple"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	db_inmemory_provider "go-leaderboard-server/internal/db/inmemory"
+	"go-leaderboard-server/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionService(t *testing.T) {
+	gameId := "game1"
+	runsGameId := "game2"
+
+	setupTest := func() (func() error, *LeaderboardService, error) {
+		var clock utils.IClock = &utils.MockClock{}
+		clock.(*utils.MockClock).SetTime(time.UnixMilli(1000000))
+
+		conf := &config.Config{
+			Db: config.DbConfig{
+				Type:   config.DBTYPE_INMEMORY,
+				Config: &db_inmemory_provider.DbInMemoryProviderConfig{},
+			},
+			Cache: config.CacheConfig{
+				Type: config.CACHETYPE_SIMPLE,
+				Config: &cache_simple_provider.CacheSimpleProviderConfig{
+					CacheProviderBaseConfig: cacheprovider.CacheProviderBaseConfig{Ttl: 1000},
+				},
+			},
+			Boards: map[string]config.BoardConfig{
+				runsGameId: {Type: config.BOARDTYPE_RUNS, RunsPerUser: 2},
+			},
+			Subscriptions: config.SubscriptionsConfig{Interval: 0, Heartbeat: 1000, BufferSize: 2, MaxSubscribers: 3},
+		}
+
+		service := NewLeaderboardService(conf)
+		err := service.Initialize(context.Background(), &clock)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		service.subscriptions = NewSubscriptionService(conf, service)
+		err = service.subscriptions.Initialize(context.Background(), &clock)
+		return func() error {
+			return errors.Join(service.subscriptions.Shutdown(context.Background()), service.Shutdown(context.Background()))
+		}, service, err
+	}
+
+	runTest := func(name string, testFunc utils.TestFcn[*LeaderboardService]) {
+		utils.RunTest(t, name, setupTest, testFunc)
+	}
+
+	receive := func(t *testing.T, sub *TopSubscription) TopUpdate {
+		select {
+		case update, ok := <-sub.Updates:
+			require.True(t, ok)
+			return update
+		case <-time.After(time.Second):
+			require.FailNow(t, "no update")
+			return TopUpdate{}
+		}
+	}
+
+	requireNoUpdate := func(t *testing.T, sub *TopSubscription) {
+		select {
+		case update := <-sub.Updates:
+			require.FailNow(t, "unexpected update", "%+v", update)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	runTest("snapshots", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		require.NoError(t, service.PutUserScore(ctx, gameId, "user1", dbprovider.UserProperties{Score: 10, Name: "John"}))
+
+		sub, err := service.subscriptions.Subscribe(gameId, 2, false)
+		require.NoError(t, err)
+		defer sub.Close()
+
+		require.Equal(t, TopUpdate{Type: TOPUPDATE_SNAPSHOT, Seq: 1, Entries: []TopEntry{
+			{Rank: 1, UserId: "user1", Score: 10, Name: "John"},
+		}}, receive(t, sub))
+
+		require.NoError(t, service.PutUserScore(ctx, gameId, "user2", dbprovider.UserProperties{Score: 20}))
+		require.Equal(t, TopUpdate{Type: TOPUPDATE_SNAPSHOT, Seq: 2, Entries: []TopEntry{
+			{Rank: 1, UserId: "user2", Score: 20},
+			{Rank: 2, UserId: "user1", Score: 10, Name: "John"},
+		}}, receive(t, sub))
+
+		// changes out of the top aren't sent
+		require.NoError(t, service.PutUserScore(ctx, gameId, "user3", dbprovider.UserProperties{Score: 5}))
+		requireNoUpdate(t, sub)
+
+		require.NoError(t, service.SetUserState(ctx, gameId, "user2", dbprovider.USERSTATE_SHADOWBANNED))
+		require.Equal(t, TopUpdate{Type: TOPUPDATE_SNAPSHOT, Seq: 3, Entries: []TopEntry{
+			{Rank: 1, UserId: "user1", Score: 10, Name: "John"},
+			{Rank: 2, UserId: "user3", Score: 5},
+		}}, receive(t, sub))
+
+		// other boards don't affect the subscription
+		require.NoError(t, service.PutUserScore(ctx, "game3", "user1", dbprovider.UserProperties{Score: 100}))
+		requireNoUpdate(t, sub)
+	})
+
+	runTest("diffs", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		require.NoError(t, service.PutUserScore(ctx, gameId, "user1", dbprovider.UserProperties{Score: 10}))
+		require.NoError(t, service.PutUserScore(ctx, gameId, "user2", dbprovider.UserProperties{Score: 5}))
+
+		sub, err := service.subscriptions.Subscribe(gameId, 2, true)
+		require.NoError(t, err)
+		defer sub.Close()
+
+		require.Equal(t, TOPUPDATE_SNAPSHOT, receive(t, sub).Type)
+
+		require.NoError(t, service.PutUserScore(ctx, gameId, "user3", dbprovider.UserProperties{Score: 20}))
+		require.Equal(t, TopUpdate{Type: TOPUPDATE_DIFF, Seq: 2, Entries: []TopEntry{
+			{Rank: 1, UserId: "user3", Score: 20},
+			{Rank: 2, UserId: "user1", Score: 10},
+		}, Removed: []string{"user2"}}, receive(t, sub))
+
+		require.NoError(t, service.PutUserScore(ctx, gameId, "user1", dbprovider.UserProperties{Score: 15}))
+		require.Equal(t, TopUpdate{Type: TOPUPDATE_DIFF, Seq: 3, Entries: []TopEntry{
+			{Rank: 2, UserId: "user1", Score: 15},
+		}}, receive(t, sub))
+
+		require.NoError(t, service.DeleteUserScore(ctx, gameId, "user3"))
+		require.Equal(t, TopUpdate{Type: TOPUPDATE_DIFF, Seq: 4, Entries: []TopEntry{
+			{Rank: 1, UserId: "user1", Score: 15},
+			{Rank: 2, UserId: "user2", Score: 5},
+		}, Removed: []string{"user3"}}, receive(t, sub))
+	})
+
+	runTest("runs", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		sub, err := service.subscriptions.Subscribe(runsGameId, 10, true)
+		require.NoError(t, err)
+		defer sub.Close()
+
+		require.Equal(t, TopUpdate{Type: TOPUPDATE_SNAPSHOT, Seq: 1, Entries: []TopEntry{}}, receive(t, sub))
+
+		require.NoError(t, service.PutUserRun(ctx, runsGameId, "user1", dbprovider.RunProperties{RunId: "run1", Score: 10}))
+		update := receive(t, sub)
+		require.Equal(t, TOPUPDATE_DIFF, update.Type)
+		require.Len(t, update.Entries, 1)
+		require.Equal(t, "user1/run1", update.Entries[0].Key())
+		require.Equal(t, int64(1000000), update.Entries[0].Ts)
+
+		require.NoError(t, service.DeleteUserRuns(ctx, runsGameId, "user1"))
+		require.Equal(t, []string{"user1/run1"}, receive(t, sub).Removed)
+	})
+
+	runTest("slow consumer", func(t *testing.T, service *LeaderboardService) {
+		ctx := context.Background()
+		sub, err := service.subscriptions.Subscribe(gameId, 10, false)
+		require.NoError(t, err)
+		defer sub.Close()
+
+		// the buffer holds 2 updates (the snapshot and the first change), the subscriber is dropped on the next one
+		require.Eventually(t, func() bool { return len(sub.Updates) == 1 }, time.Second, time.Millisecond)
+		require.NoError(t, service.PutUserScore(ctx, gameId, "user1", dbprovider.UserProperties{Score: 1}))
+		require.Eventually(t, func() bool { return len(sub.Updates) == 2 }, time.Second, time.Millisecond)
+		require.NoError(t, service.PutUserScore(ctx, gameId, "user1", dbprovider.UserProperties{Score: 2}))
+
+		require.Eventually(t, func() bool { return sub.Err() != nil }, time.Second, time.Millisecond)
+		require.ErrorIs(t, sub.Err(), ErrSlowConsumer)
+		receive(t, sub)
+		receive(t, sub)
+		_, ok := <-sub.Updates
+		require.False(t, ok)
+	})
+
+	runTest("limits", func(t *testing.T, service *LeaderboardService) {
+		subs := []*TopSubscription{}
+		for i := 0; i < 3; i++ {
+			sub, err := service.subscriptions.Subscribe(gameId, 10, false)
+			require.NoError(t, err)
+			subs = append(subs, sub)
+		}
+		_, err := service.subscriptions.Subscribe(runsGameId, 10, false)
+		require.ErrorIs(t, err, ErrTooManySubscriptions)
+
+		subs[0].Close()
+		subs[0].Close()
+		sub, err := service.subscriptions.Subscribe(runsGameId, 10, false)
+		require.NoError(t, err)
+		subs = append(subs, sub)
+
+		service.subscriptions.Close()
+		for _, sub := range subs[1:] {
+			require.Eventually(t, func() bool { return sub.Err() != nil }, time.Second, time.Millisecond)
+			require.ErrorIs(t, sub.Err(), ErrSubscriptionsClosed)
+		}
+		require.NoError(t, subs[0].Err())
+
+		_, err = service.subscriptions.Subscribe(gameId, 10, false)
+		require.ErrorIs(t, err, ErrSubscriptionsClosed)
+	})
+}
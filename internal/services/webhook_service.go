@@ -0,0 +1,431 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	deadletterprovider "go-leaderboard-server/internal/deadletter"
+	deadletter_memory_provider "go-leaderboard-server/internal/deadletter/memory"
+	deadletter_redis_provider "go-leaderboard-server/internal/deadletter/redis"
+	log "go-leaderboard-server/internal/logger"
+	"go-leaderboard-server/internal/utils"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var ErrWebhooksDisabled = errors.New("webhooks are disabled")
+var ErrWebhookEndpointNotFound = errors.New("webhook endpoint not found")
+var ErrWebhookQueueFull = errors.New("webhook queue is full")
+
+const (
+	HEADER_WEBHOOK_ID        = "X-Webhook-Id"
+	HEADER_WEBHOOK_TIMESTAMP = "X-Webhook-Timestamp"
+	HEADER_WEBHOOK_SIGNATURE = "X-Webhook-Signature"
+)
+
+// Body of a webhook
+type WebhookEvent struct {
+	Id       string                `json:"id"`   // Unique id of the event, the same for all endpoints and retries
+	Type     string                `json:"type"` // WEBHOOKEVENT_*
+	Tenant   string                `json:"tenant,omitempty"`
+	GameId   string                `json:"gameId"`
+	UserId   string                `json:"userId"`
+	Rank     int                   `json:"rank"`     // Rank after the change (0 - out of the watched top)
+	PrevRank int                   `json:"prevRank"` // Rank before the change (0 - out of the watched top)
+	Score    dbprovider.UScoreType `json:"score"`
+	ByUserId string                `json:"byUserId,omitempty"` // User who submitted the score (overtaken events only)
+	Ts       int64                 `json:"ts"`                 // Time of the change (unix ms)
+}
+
+type webhookDelivery struct {
+	id      string
+	tenant  string // Id of tenant of the event, kept with the dead letter
+	payload []byte
+}
+
+type webhookEndpoint struct {
+	config config.WebhookEndpointConfig
+	events map[string]bool // nil - all events
+	games  map[string]bool // nil - all games
+	queue  chan webhookDelivery
+}
+
+func (e *webhookEndpoint) accepts(gameId string, event string) bool {
+	return (e.games == nil || e.games[gameId]) && (e.events == nil || e.events[event])
+}
+
+// Detects changes of tops made by writes and delivers them to the configured endpoints as signed webhooks.
+// Every endpoint has a queue and a worker delivering events in order, retrying with exponential backoff.
+// Events that fail all attempts are kept as dead letters, so they can be replayed
+type WebhookService struct {
+	config      *config.Config
+	leaderboard *LeaderboardService
+	clock       *utils.IClock
+	client      *http.Client
+	provider    deadletterprovider.IDeadLetterProvider
+	endpoints   map[string]*webhookEndpoint // key - endpoint id
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+func NewWebhookService(config *config.Config, leaderboard *LeaderboardService) *WebhookService {
+	return &WebhookService{
+		config:      config,
+		leaderboard: leaderboard,
+	}
+}
+
+func (s *WebhookService) Initialize(ctx context.Context, clock *utils.IClock) error {
+	logger.Debug("Webhook service initialization")
+
+	s.clock = clock
+
+	if !s.IsEnabled() {
+		return nil
+	}
+
+	if s.leaderboard == nil {
+		return errors.New("uninitialized leaderboard service")
+	}
+
+	switch s.config.Webhooks.DeadLetter.Type {
+	case config.DEADLETTERTYPE_MEMORY:
+		s.provider = deadletter_memory_provider.NewDeadLetterMemoryProvider()
+	case config.DEADLETTERTYPE_REDIS:
+		s.provider = deadletter_redis_provider.NewDeadLetterRedisProvider()
+	default:
+		return errors.New("unknown DeadLetter provider type")
+	}
+
+	err := s.provider.Initialize(ctx, s.config.Webhooks.DeadLetter.Config)
+	if err != nil {
+		return err
+	}
+
+	s.client = &http.Client{Timeout: time.Duration(s.config.Webhooks.Timeout) * time.Millisecond}
+	s.endpoints = make(map[string]*webhookEndpoint)
+
+	ctxRun, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	for _, conf := range s.config.Webhooks.Endpoints {
+		endpoint := &webhookEndpoint{
+			config: conf,
+			queue:  make(chan webhookDelivery, s.config.Webhooks.QueueSize),
+		}
+		if len(conf.Events) > 0 {
+			endpoint.events = make(map[string]bool)
+			for _, event := range conf.Events {
+				endpoint.events[event] = true
+			}
+		}
+		if len(conf.Games) > 0 {
+			endpoint.games = make(map[string]bool)
+			for _, gameId := range conf.Games {
+				endpoint.games[gameId] = true
+			}
+		}
+		s.endpoints[conf.Id] = endpoint
+
+		s.wg.Add(1)
+		go s.run(ctxRun, endpoint)
+	}
+
+	return nil
+}
+
+func (s *WebhookService) IsEnabled() bool {
+	return s.config.Webhooks != nil
+}
+
+// Reports whether events of the game are sent to any endpoint
+func (s *WebhookService) Watches(gameId string) bool {
+	for _, endpoint := range s.endpoints {
+		if endpoint.games == nil || endpoint.games[gameId] {
+			return true
+		}
+	}
+	return false
+}
+
+// Sends events of the changes of the top made by a committed write. Overtaken events are detected only for
+// the submitter (empty - the write isn't a submission)
+func (s *WebhookService) publishTransition(ctx context.Context, gameId string, submitter string, transition *topTransition) {
+	topSize := s.config.Webhooks.TopSize
+	events := detectTopEvents(topEntries(transition.before, topSize), topEntries(transition.after, topSize), submitter)
+	tenant, tenantGameId := dbprovider.SplitTenantGameId(gameId)
+	for i := range events {
+		id, err := newRandomId()
+		if err != nil {
+			logger.Error("Failed to create webhook event id", log.LogParams{"error": err, "gameId": gameId})
+			return
+		}
+		events[i].Id = id
+		events[i].Tenant = tenant
+		events[i].GameId = tenantGameId
+		events[i].Ts = (*s.clock).Now().UnixMilli()
+		s.publish(ctx, gameId, events[i])
+	}
+}
+
+// Returns events of users whose place in the top is changed (runs boards rank users by their best runs)
+func detectTopEvents(before []TopEntry, after []TopEntry, submitter string) []WebhookEvent {
+	prevRanks := userRanks(before)
+	ranks := userRanks(after)
+	scores := make(map[string]dbprovider.UScoreType)
+	for _, entries := range [][]TopEntry{before, after} {
+		for i := len(entries) - 1; i >= 0; i-- {
+			scores[entries[i].UserId] = entries[i].Score
+		}
+	}
+
+	events := []WebhookEvent{}
+	for _, e := range after {
+		if ranks[e.UserId] != e.Rank {
+			continue // not the best run of the user
+		}
+		prevRank := prevRanks[e.UserId]
+		if prevRank == 0 {
+			events = append(events, WebhookEvent{Type: config.WEBHOOKEVENT_ENTERED_TOP, UserId: e.UserId, Rank: e.Rank, Score: e.Score})
+		}
+		if e.Rank == 1 && prevRank != 1 {
+			events = append(events, WebhookEvent{Type: config.WEBHOOKEVENT_TOOK_FIRST, UserId: e.UserId, Rank: 1, PrevRank: prevRank, Score: e.Score})
+		}
+	}
+
+	submitterRank, ok := ranks[submitter]
+	if submitter == "" || !ok {
+		return events
+	}
+	submitterPrevRank := prevRanks[submitter]
+	for _, e := range before {
+		if prevRanks[e.UserId] != e.Rank || e.UserId == submitter {
+			continue
+		}
+		if submitterPrevRank != 0 && submitterPrevRank < e.Rank {
+			continue // the submitter was ahead already
+		}
+		rank := ranks[e.UserId]
+		if rank == 0 || rank > submitterRank {
+			events = append(events, WebhookEvent{
+				Type: config.WEBHOOKEVENT_OVERTAKEN, UserId: e.UserId, Rank: rank, PrevRank: e.Rank, Score: scores[e.UserId], ByUserId: submitter,
+			})
+		}
+	}
+
+	return events
+}
+
+// Returns ranks of users in the top (the best entry of the user)
+func userRanks(entries []TopEntry) map[string]int {
+	ranks := make(map[string]int, len(entries))
+	for _, e := range entries {
+		if _, ok := ranks[e.UserId]; !ok {
+			ranks[e.UserId] = e.Rank
+		}
+	}
+	return ranks
+}
+
+// Queues the event for the endpoints accepting it, events that don't fit into a queue become dead letters
+func (s *WebhookService) publish(ctx context.Context, gameId string, event WebhookEvent) {
+	payload, err := json.Marshal(&event)
+	if err != nil {
+		logger.Error("Failed to marshal webhook event", log.LogParams{"error": err, "gameId": gameId})
+		return
+	}
+
+	delivery := webhookDelivery{id: event.Id, tenant: event.Tenant, payload: payload}
+	for _, endpoint := range s.endpoints {
+		if !endpoint.accepts(gameId, event.Type) {
+			continue
+		}
+		select {
+		case endpoint.queue <- delivery:
+		default:
+			s.deadLetter(ctx, endpoint, delivery, 0, ErrWebhookQueueFull)
+		}
+	}
+}
+
+// Delivers queued events of the endpoint until the service is shut down
+func (s *WebhookService) run(ctx context.Context, endpoint *webhookEndpoint) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case delivery := <-endpoint.queue:
+			s.deliver(ctx, endpoint, delivery)
+		}
+	}
+}
+
+func (s *WebhookService) deliver(ctx context.Context, endpoint *webhookEndpoint, delivery webhookDelivery) {
+	var (
+		err      error
+		attempt  uint32
+		delay    = time.Duration(s.config.Webhooks.RetryInitial) * time.Millisecond
+		maxDelay = time.Duration(s.config.Webhooks.RetryMax) * time.Millisecond
+	)
+
+	for attempt = 1; ; attempt++ {
+		err = s.send(ctx, endpoint, delivery)
+		if err == nil {
+			return
+		}
+		logger.Warn("Failed to deliver webhook", log.LogParams{"error": err, "endpoint": endpoint.config.Id, "id": delivery.id,
+			"attempt": int(attempt)})
+		if attempt >= s.config.Webhooks.MaxAttempts || ctx.Err() != nil {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxDelay)
+	}
+
+	s.deadLetter(ctx, endpoint, delivery, attempt, err)
+}
+
+func (s *WebhookService) send(ctx context.Context, endpoint *webhookEndpoint, delivery webhookDelivery) error {
+	timestamp := strconv.FormatInt((*s.clock).Now().UnixMilli(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.config.Url, bytes.NewReader(delivery.payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HEADER_WEBHOOK_ID, delivery.id)
+	req.Header.Set(HEADER_WEBHOOK_TIMESTAMP, timestamp)
+	req.Header.Set(HEADER_WEBHOOK_SIGNATURE, SignWebhook(endpoint.config.Secret, timestamp, delivery.id, delivery.payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Stores the failed delivery as a dead letter (the letter is stored on shutdown as well)
+func (s *WebhookService) deadLetter(ctx context.Context, endpoint *webhookEndpoint, delivery webhookDelivery, attempts uint32, reason error) {
+	err := s.provider.Put(context.WithoutCancel(ctx), endpoint.config.Id, deadletterprovider.DeadLetter{
+		Id:       delivery.id,
+		Tenant:   delivery.tenant,
+		Payload:  string(delivery.payload),
+		Attempts: attempts,
+		Error:    reason.Error(),
+		Ts:       (*s.clock).Now().UnixMilli(),
+	})
+	if err != nil {
+		logger.Error("Failed to store dead letter", log.LogParams{"error": err, "endpoint": endpoint.config.Id, "id": delivery.id,
+			"payload": string(delivery.payload)})
+	}
+}
+
+// Returns up to limit dead letters of the endpoint and the tenant (dbprovider.ALL_TENANTS - of all tenants)
+// in ascending order of time
+func (s *WebhookService) ListDeadLetters(ctx context.Context, endpointId string, tenant string, limit uint32) ([]deadletterprovider.DeadLetter, error) {
+	if !s.IsEnabled() {
+		return nil, ErrWebhooksDisabled
+	}
+	if _, ok := s.endpoints[endpointId]; !ok {
+		return nil, ErrWebhookEndpointNotFound
+	}
+
+	return s.provider.List(ctx, endpointId, tenant, limit)
+}
+
+// Queues dead letters of the endpoint and the tenant (dbprovider.ALL_TENANTS - of all tenants) for delivery again
+// and removes them (empty ids - the oldest letters that fit into the queue). Unknown ids and letters of other tenants
+// are skipped. Returns the number of queued letters
+func (s *WebhookService) ReplayDeadLetters(ctx context.Context, endpointId string, tenant string, ids []string) (int, error) {
+	if !s.IsEnabled() {
+		return 0, ErrWebhooksDisabled
+	}
+	endpoint, ok := s.endpoints[endpointId]
+	if !ok {
+		return 0, ErrWebhookEndpointNotFound
+	}
+
+	var letters []deadletterprovider.DeadLetter
+	if len(ids) == 0 {
+		var err error
+		letters, err = s.provider.List(ctx, endpointId, tenant, s.config.Webhooks.QueueSize)
+		if err != nil {
+			return 0, err
+		}
+	}
+	for _, id := range ids {
+		letter, err := s.provider.Get(ctx, endpointId, id)
+		if err != nil {
+			return 0, err
+		}
+		if letter != nil && (tenant == dbprovider.ALL_TENANTS || letter.Tenant == tenant) {
+			letters = append(letters, *letter)
+		}
+	}
+
+	replayed := 0
+	for _, letter := range letters {
+		select {
+		case endpoint.queue <- webhookDelivery{id: letter.Id, tenant: letter.Tenant, payload: []byte(letter.Payload)}:
+		default:
+			return replayed, ErrWebhookQueueFull
+		}
+		replayed++
+
+		err := s.provider.Delete(ctx, endpointId, letter.Id)
+		if err != nil {
+			return replayed, err
+		}
+	}
+
+	return replayed, nil
+}
+
+func (s *WebhookService) Shutdown(ctx context.Context) error {
+	logger.Debug("Webhook service shutdown")
+
+	if s.provider == nil {
+		return nil
+	}
+
+	s.cancel()
+	s.wg.Wait()
+
+	// undelivered events are kept, so they can be replayed
+	for _, endpoint := range s.endpoints {
+		for len(endpoint.queue) > 0 {
+			s.deadLetter(ctx, endpoint, <-endpoint.queue, 0, errors.New("server shutdown"))
+		}
+	}
+
+	return s.provider.Shutdown(ctx)
+}
+
+// Returns the HMAC-SHA256 signature (hex) of the webhook, as sent in the X-Webhook-Signature header
+func SignWebhook(secret string, timestamp string, id string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n", timestamp, id)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	cacheprovider "go-leaderboard-server/internal/cache"
+	cache_simple_provider "go-leaderboard-server/internal/cache/simple"
+	"go-leaderboard-server/internal/config"
+	dbprovider "go-leaderboard-server/internal/db"
+	db_inmemory_provider "go-leaderboard-server/internal/db/inmemory"
+	deadletter_memory_provider "go-leaderboard-server/internal/deadletter/memory"
+	"go-leaderboard-server/internal/utils"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Receiver of webhooks that checks their signatures, responds with the status set by the test
+type webhookReceiver struct {
+	server *httptest.Server
+	status atomic.Int32
+	mu     sync.Mutex
+	events []WebhookEvent
+	calls  int
+}
+
+func newWebhookReceiver(t *testing.T, secret string) *webhookReceiver {
+	r := &webhookReceiver{}
+	r.status.Store(http.StatusOK)
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		signature := SignWebhook(secret, req.Header.Get(HEADER_WEBHOOK_TIMESTAMP), req.Header.Get(HEADER_WEBHOOK_ID), body)
+		require.Equal(t, signature, req.Header.Get(HEADER_WEBHOOK_SIGNATURE))
+		require.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.calls++
+		status := int(r.status.Load())
+		if status == http.StatusOK {
+			var event WebhookEvent
+			require.NoError(t, json.Unmarshal(body, &event))
+			require.Equal(t, event.Id, req.Header.Get(HEADER_WEBHOOK_ID))
+			r.events = append(r.events, event)
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+// Returns received events without ids and times
+func (r *webhookReceiver) received() []WebhookEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := make([]WebhookEvent, 0, len(r.events))
+	for _, event := range r.events {
+		event.Id = ""
+		event.Ts = 0
+		events = append(events, event)
+	}
+	return events
+}
+
+// Counts reads of tops
+type topCountingProvider struct {
+	dbprovider.IDbProvider
+	tops atomic.Int32
+}
+
+func (p *topCountingProvider) Top(ctx context.Context, gameId string, nTop uint32, opts dbprovider.TopOptions) (dbprovider.TopData, error) {
+	p.tops.Add(1)
+	return p.IDbProvider.Top(ctx, gameId, nTop, opts)
+}
+
+func TestWebhookService(t *testing.T) {
+	newService := func(t *testing.T, endpoints []config.WebhookEndpointConfig) *LeaderboardService {
+		var clock utils.IClock = &utils.MockClock{}
+		clock.(*utils.MockClock).SetTime(time.UnixMilli(1000000))
+
+		conf := &config.Config{
+			Db: config.DbConfig{
+				Type:   config.DBTYPE_INMEMORY,
+				Config: &db_inmemory_provider.DbInMemoryProviderConfig{},
+			},
+			Cache: config.CacheConfig{
+				Type: config.CACHETYPE_SIMPLE,
+				Config: &cache_simple_provider.CacheSimpleProviderConfig{
+					CacheProviderBaseConfig: cacheprovider.CacheProviderBaseConfig{Ttl: 1000},
+				},
+			},
+			Boards: map[string]config.BoardConfig{
+				"runs": {Type: config.BOARDTYPE_RUNS, RunsPerUser: 2},
+			},
+			Webhooks: &config.WebhooksConfig{
+				Endpoints: endpoints, TopSize: 3, MaxAttempts: 3, RetryInitial: 1, RetryMax: 2, Timeout: 1000, QueueSize: 10,
+				DeadLetter: config.DeadLetterConfig{
+					Type:   config.DEADLETTERTYPE_MEMORY,
+					Config: &deadletter_memory_provider.DeadLetterMemoryProviderConfig{},
+				},
+			},
+		}
+
+		service := NewLeaderboardService(conf)
+		require.NoError(t, service.Initialize(context.Background(), &clock))
+		service.webhooks = NewWebhookService(conf, service)
+		require.NoError(t, service.webhooks.Initialize(context.Background(), &clock))
+		t.Cleanup(func() {
+			require.NoError(t, service.webhooks.Shutdown(context.Background()))
+			require.NoError(t, service.Shutdown(context.Background()))
+		})
+		return service
+	}
+
+	t.Run("detect events", func(t *testing.T) {
+		top := func(users ...string) []TopEntry {
+			entries := []TopEntry{}
+			for i, userId := range users {
+				entries = append(entries, TopEntry{Rank: i + 1, UserId: userId, Score: dbprovider.UScoreType(100 - i)})
+			}
+			return entries
+		}
+
+		require.Equal(t, []WebhookEvent{
+			{Type: config.WEBHOOKEVENT_ENTERED_TOP, UserId: "user3", Rank: 1, Score: 100},
+			{Type: config.WEBHOOKEVENT_TOOK_FIRST, UserId: "user3", Rank: 1, Score: 100},
+			{Type: config.WEBHOOKEVENT_OVERTAKEN, UserId: "user1", Rank: 2, PrevRank: 1, Score: 99, ByUserId: "user3"},
+			{Type: config.WEBHOOKEVENT_OVERTAKEN, UserId: "user2", Rank: 3, PrevRank: 2, Score: 98, ByUserId: "user3"},
+		}, detectTopEvents(top("user1", "user2"), top("user3", "user1", "user2"), "user3"))
+
+		// users ahead of the submitter only, including users pushed out of the top
+		require.Equal(t, []WebhookEvent{
+			{Type: config.WEBHOOKEVENT_OVERTAKEN, UserId: "user2", Rank: 0, PrevRank: 2, Score: 99, ByUserId: "user3"},
+		}, detectTopEvents(top("user1", "user2", "user3"), top("user1", "user3"), "user3"))
+
+		// a deletion moves users up
+		require.Equal(t, []WebhookEvent{
+			{Type: config.WEBHOOKEVENT_TOOK_FIRST, UserId: "user2", Rank: 1, PrevRank: 2, Score: 100},
+			{Type: config.WEBHOOKEVENT_ENTERED_TOP, UserId: "user4", Rank: 3, Score: 98},
+		}, detectTopEvents(top("user1", "user2", "user3"), top("user2", "user3", "user4"), ""))
+
+		require.Empty(t, detectTopEvents(top("user1", "user2"), top("user1", "user2"), "user2"))
+	})
+
+	t.Run("deliver signed webhooks", func(t *testing.T) {
+		ctx := context.Background()
+		receiver := newWebhookReceiver(t, "secret1")
+		filtered := newWebhookReceiver(t, "secret2")
+		service := newService(t, []config.WebhookEndpointConfig{
+			{Id: "all", Url: receiver.server.URL, Secret: "secret1"},
+			{Id: "filtered", Url: filtered.server.URL, Secret: "secret2", Events: []string{config.WEBHOOKEVENT_TOOK_FIRST},
+				Games: []string{"studio1/game1"}},
+		})
+
+		require.NoError(t, service.PutUserScore(ctx, "studio1/game1", "user1", dbprovider.UserProperties{Score: 10}))
+		require.NoError(t, service.PutUserScore(ctx, "studio1/game1", "user2", dbprovider.UserProperties{Score: 20}))
+		require.NoError(t, service.PutUserScore(ctx, "game2", "user1", dbprovider.UserProperties{Score: 10}))
+
+		expected := []WebhookEvent{
+			{Type: config.WEBHOOKEVENT_ENTERED_TOP, Tenant: "studio1", GameId: "game1", UserId: "user1", Rank: 1, Score: 10},
+			{Type: config.WEBHOOKEVENT_TOOK_FIRST, Tenant: "studio1", GameId: "game1", UserId: "user1", Rank: 1, Score: 10},
+			{Type: config.WEBHOOKEVENT_ENTERED_TOP, Tenant: "studio1", GameId: "game1", UserId: "user2", Rank: 1, Score: 20},
+			{Type: config.WEBHOOKEVENT_TOOK_FIRST, Tenant: "studio1", GameId: "game1", UserId: "user2", Rank: 1, Score: 20},
+			{Type: config.WEBHOOKEVENT_OVERTAKEN, Tenant: "studio1", GameId: "game1", UserId: "user1", Rank: 2, PrevRank: 1, Score: 10,
+				ByUserId: "user2"},
+			{Type: config.WEBHOOKEVENT_ENTERED_TOP, GameId: "game2", UserId: "user1", Rank: 1, Score: 10},
+			{Type: config.WEBHOOKEVENT_TOOK_FIRST, GameId: "game2", UserId: "user1", Rank: 1, Score: 10},
+		}
+		require.Eventually(t, func() bool { return len(receiver.received()) == len(expected) }, time.Second, time.Millisecond)
+		require.Equal(t, expected, receiver.received())
+
+		require.Eventually(t, func() bool { return len(filtered.received()) == 2 }, time.Second, time.Millisecond)
+		require.Equal(t, []WebhookEvent{expected[1], expected[3]}, filtered.received())
+
+		// runs boards rank users by their best runs
+		require.NoError(t, service.PutUserRun(ctx, "runs", "user1", dbprovider.RunProperties{RunId: "run1", Score: 10}))
+		require.NoError(t, service.PutUserRun(ctx, "runs", "user1", dbprovider.RunProperties{RunId: "run2", Score: 20}))
+		require.Eventually(t, func() bool { return len(receiver.received()) == len(expected)+2 }, time.Second, time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		require.Len(t, receiver.received(), len(expected)+2)
+	})
+
+	t.Run("derive the top after writes", func(t *testing.T) {
+		ctx := context.Background()
+		receiver := newWebhookReceiver(t, "secret1")
+		service := newService(t, []config.WebhookEndpointConfig{
+			{Id: "all", Url: receiver.server.URL, Secret: "secret1"},
+		})
+		provider := &topCountingProvider{IDbProvider: service.dbprovider}
+		service.dbprovider = provider
+
+		for i, userId := range []string{"user1", "user2", "user3", "user4"} {
+			require.NoError(t, service.PutUserScore(ctx, "game1", userId, dbprovider.UserProperties{Score: dbprovider.UScoreType(40 - i*10)}))
+		}
+		require.Equal(t, int32(4), provider.tops.Load()) // the top is read once per write
+		require.Eventually(t, func() bool { return len(receiver.received()) == 4 }, time.Second, time.Millisecond)
+
+		// the user below the top enters it when a user leaves it
+		require.NoError(t, service.DeleteUserScore(ctx, "game1", "user1"))
+		// scores of hidden users don't change the top
+		require.NoError(t, service.SetUserState(ctx, "game1", "user5", dbprovider.USERSTATE_SHADOWBANNED))
+		require.NoError(t, service.PutUserScore(ctx, "game1", "user5", dbprovider.UserProperties{Score: 100}))
+
+		expected := []WebhookEvent{
+			{Type: config.WEBHOOKEVENT_TOOK_FIRST, GameId: "game1", UserId: "user2", Rank: 1, PrevRank: 2, Score: 30},
+			{Type: config.WEBHOOKEVENT_ENTERED_TOP, GameId: "game1", UserId: "user4", Rank: 3, Score: 10},
+		}
+		require.Eventually(t, func() bool { return len(receiver.received()) == 4+len(expected) }, time.Second, time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		require.Equal(t, expected, receiver.received()[4:])
+	})
+
+	t.Run("retries and dead letters", func(t *testing.T) {
+		ctx := context.Background()
+		receiver := newWebhookReceiver(t, "secret1")
+		receiver.status.Store(http.StatusInternalServerError)
+		service := newService(t, []config.WebhookEndpointConfig{
+			{Id: "rewards", Url: receiver.server.URL, Secret: "secret1", Events: []string{config.WEBHOOKEVENT_TOOK_FIRST}},
+		})
+
+		require.NoError(t, service.PutUserScore(ctx, "game1", "user1", dbprovider.UserProperties{Score: 10}))
+
+		var letters []WebhookEvent
+		require.Eventually(t, func() bool {
+			list, err := service.webhooks.ListDeadLetters(ctx, "rewards", dbprovider.ALL_TENANTS, 10)
+			require.NoError(t, err)
+			if len(list) == 0 {
+				return false
+			}
+			require.Equal(t, uint32(3), list[0].Attempts)
+			require.Equal(t, "unexpected status 500", list[0].Error)
+			var event WebhookEvent
+			require.NoError(t, json.Unmarshal([]byte(list[0].Payload), &event))
+			letters = append(letters, event)
+			return true
+		}, time.Second, time.Millisecond)
+		require.Equal(t, 3, receiver.calls)
+		require.Equal(t, "user1", letters[0].UserId)
+
+		// letters of the default tenant aren't available to other tenants
+		list, err := service.webhooks.ListDeadLetters(ctx, "rewards", "studio1", 10)
+		require.NoError(t, err)
+		require.Empty(t, list)
+		list, err = service.webhooks.ListDeadLetters(ctx, "rewards", "", 10)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+
+		receiver.status.Store(http.StatusOK)
+		replayed, err := service.webhooks.ReplayDeadLetters(ctx, "rewards", "studio1", []string{list[0].Id})
+		require.NoError(t, err)
+		require.Equal(t, 0, replayed)
+		replayed, err = service.webhooks.ReplayDeadLetters(ctx, "rewards", dbprovider.ALL_TENANTS, []string{"unknown"})
+		require.NoError(t, err)
+		require.Equal(t, 0, replayed)
+		replayed, err = service.webhooks.ReplayDeadLetters(ctx, "rewards", "", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, replayed)
+
+		require.Eventually(t, func() bool { return len(receiver.received()) == 1 }, time.Second, time.Millisecond)
+		require.Equal(t, letters[0].Type, receiver.received()[0].Type)
+		list, err = service.webhooks.ListDeadLetters(ctx, "rewards", dbprovider.ALL_TENANTS, 10)
+		require.NoError(t, err)
+		require.Empty(t, list)
+
+		_, err = service.webhooks.ReplayDeadLetters(ctx, "unknown", dbprovider.ALL_TENANTS, nil)
+		require.ErrorIs(t, err, ErrWebhookEndpointNotFound)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		service := NewWebhookService(&config.Config{}, nil)
+		require.NoError(t, service.Initialize(context.Background(), nil))
+		require.False(t, service.IsEnabled())
+
+		_, err := service.ListDeadLetters(context.Background(), "rewards", dbprovider.ALL_TENANTS, 10)
+		require.ErrorIs(t, err, ErrWebhooksDisabled)
+		require.NoError(t, service.Shutdown(context.Background()))
+	})
+}
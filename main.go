@@ -13,6 +13,14 @@ import (
 // @contact.name borisprogrm
 // @externalDocs.description OpenAPI
 // @externalDocs.url https://swagger.io/resources/open-api/
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-Api-Key
+// @description API key (required only if authentication is enabled)
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Bearer token of a player: "Bearer {token}" (required only if JWT authentication is enabled)
 func main() {
 	config := config.GetAppConfig()
 